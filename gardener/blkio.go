@@ -0,0 +1,102 @@
+package gardener
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/garden"
+)
+
+// BlockIOKeyPrefix prefixes the properties limiting a container's block I/O.
+// Setting or removing them updates the limits of a running container.
+const BlockIOKeyPrefix = "garden.blkio."
+
+const (
+	// BlockIOWeightKey is the property setting a container's proportion of
+	// block I/O, from 10 to 1000
+	BlockIOWeightKey = BlockIOKeyPrefix + "weight"
+
+	// BlockIOReadBpsKey, BlockIOWriteBpsKey, BlockIOReadIOPSKey and
+	// BlockIOWriteIOPSKey are the properties throttling a container's I/O to
+	// block devices, as comma-separated DEVICE:RATE pairs, where DEVICE is a
+	// path, e.g. /dev/sda, or a major:minor pair, e.g. 8:0
+	BlockIOReadBpsKey   = BlockIOKeyPrefix + "read-bps"
+	BlockIOWriteBpsKey  = BlockIOKeyPrefix + "write-bps"
+	BlockIOReadIOPSKey  = BlockIOKeyPrefix + "read-iops"
+	BlockIOWriteIOPSKey = BlockIOKeyPrefix + "write-iops"
+)
+
+// BlockIOLimits share and throttle a container's I/O to block devices
+type BlockIOLimits struct {
+	Weight    uint16
+	ReadBps   []DeviceRate
+	WriteBps  []DeviceRate
+	ReadIOPS  []DeviceRate
+	WriteIOPS []DeviceRate
+}
+
+// DeviceRate limits the rate of I/O to a block device, given by its path or
+// its major:minor numbers
+type DeviceRate struct {
+	Device string
+	Rate   uint64
+}
+
+// ParseBlockIOLimits returns the block I/O limits set by a container's
+// properties
+func ParseBlockIOLimits(properties garden.Properties) (BlockIOLimits, error) {
+	var limits BlockIOLimits
+
+	if value := properties[BlockIOWeightKey]; value != "" {
+		weight, err := strconv.ParseUint(value, 10, 16)
+		if err != nil || weight < 10 || weight > 1000 {
+			return BlockIOLimits{}, fmt.Errorf("invalid value for %s: %s", BlockIOWeightKey, value)
+		}
+		limits.Weight = uint16(weight)
+	}
+
+	for _, throttle := range []struct {
+		key   string
+		rates *[]DeviceRate
+	}{
+		{BlockIOReadBpsKey, &limits.ReadBps},
+		{BlockIOWriteBpsKey, &limits.WriteBps},
+		{BlockIOReadIOPSKey, &limits.ReadIOPS},
+		{BlockIOWriteIOPSKey, &limits.WriteIOPS},
+	} {
+		rates, err := ParseDeviceRates(properties[throttle.key])
+		if err != nil {
+			return BlockIOLimits{}, fmt.Errorf("invalid value for %s: %s", throttle.key, err)
+		}
+		*throttle.rates = rates
+	}
+
+	return limits, nil
+}
+
+// ParseDeviceRates parses comma-separated DEVICE:RATE pairs, e.g.
+// "/dev/sda:1048576,8:16:1048576"
+func ParseDeviceRates(value string) ([]DeviceRate, error) {
+	var rates []DeviceRate
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		sep := strings.LastIndex(pair, ":")
+		if sep <= 0 {
+			return nil, fmt.Errorf("expected DEVICE:RATE, got '%s'", pair)
+		}
+
+		rate, err := strconv.ParseUint(pair[sep+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate: '%s'", pair[sep+1:])
+		}
+
+		rates = append(rates, DeviceRate{Device: pair[:sep], Rate: rate})
+	}
+
+	return rates, nil
+}
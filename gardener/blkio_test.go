@@ -0,0 +1,74 @@
+package gardener_test
+
+import (
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseBlockIOLimits", func() {
+	It("parses the block I/O properties", func() {
+		limits, err := gardener.ParseBlockIOLimits(garden.Properties{
+			gardener.BlockIOWeightKey:    "500",
+			gardener.BlockIOReadBpsKey:   "/dev/sda:1048576, 8:16:2097152",
+			gardener.BlockIOWriteBpsKey:  "/dev/sda:524288",
+			gardener.BlockIOReadIOPSKey:  "8:0:1000",
+			gardener.BlockIOWriteIOPSKey: "8:0:500",
+			"some-other-property":        "banana",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(limits).To(Equal(gardener.BlockIOLimits{
+			Weight: 500,
+			ReadBps: []gardener.DeviceRate{
+				{Device: "/dev/sda", Rate: 1048576},
+				{Device: "8:16", Rate: 2097152},
+			},
+			WriteBps:  []gardener.DeviceRate{{Device: "/dev/sda", Rate: 524288}},
+			ReadIOPS:  []gardener.DeviceRate{{Device: "8:0", Rate: 1000}},
+			WriteIOPS: []gardener.DeviceRate{{Device: "8:0", Rate: 500}},
+		}))
+	})
+
+	It("returns no limits when no properties are set", func() {
+		Expect(gardener.ParseBlockIOLimits(garden.Properties{})).To(Equal(gardener.BlockIOLimits{}))
+	})
+
+	Context("when the weight is out of range", func() {
+		It("returns an error", func() {
+			_, err := gardener.ParseBlockIOLimits(garden.Properties{gardener.BlockIOWeightKey: "5"})
+			Expect(err).To(MatchError("invalid value for garden.blkio.weight: 5"))
+		})
+	})
+
+	Context("when the weight is not a number", func() {
+		It("returns an error", func() {
+			_, err := gardener.ParseBlockIOLimits(garden.Properties{gardener.BlockIOWeightKey: "heavy"})
+			Expect(err).To(MatchError("invalid value for garden.blkio.weight: heavy"))
+		})
+	})
+
+	Context("when a throttle is invalid", func() {
+		It("returns an error", func() {
+			_, err := gardener.ParseBlockIOLimits(garden.Properties{gardener.BlockIOWriteIOPSKey: "/dev/sda"})
+			Expect(err).To(MatchError("invalid value for garden.blkio.write-iops: expected DEVICE:RATE, got '/dev/sda'"))
+		})
+	})
+})
+
+var _ = Describe("ParseDeviceRates", func() {
+	It("parses devices given by path or by numbers", func() {
+		Expect(gardener.ParseDeviceRates("/dev/sdb:10,253:1:20")).To(Equal([]gardener.DeviceRate{
+			{Device: "/dev/sdb", Rate: 10},
+			{Device: "253:1", Rate: 20},
+		}))
+	})
+
+	Context("when a rate is not a number", func() {
+		It("returns an error", func() {
+			_, err := gardener.ParseDeviceRates("/dev/sdb:fast")
+			Expect(err).To(MatchError("invalid rate: 'fast'"))
+		})
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"code.cloudfoundry.org/garden"
@@ -24,6 +25,26 @@ func (c *container) Handle() string {
 	return c.handle
 }
 
+// checkNetworked returns an error if the container's network mode does not
+// support the given operation
+func (c *container) checkNetworked(operation string) error {
+	if mode, ok := c.propertyManager.Get(c.handle, NetworkModeKey); ok && !hasOwnNetwork(mode) {
+		return fmt.Errorf("%s is not supported in network mode %s", operation, mode)
+	}
+
+	return nil
+}
+
+// networkHandle returns the handle of the container whose network this
+// container uses, which is only different when the network is shared
+func (c *container) networkHandle() string {
+	if owner, ok := c.propertyManager.Get(c.handle, NetworkSharedWithKey); ok && owner != "" {
+		return owner
+	}
+
+	return c.handle
+}
+
 func (c *container) Run(spec garden.ProcessSpec, io garden.ProcessIO) (garden.Process, error) {
 	return c.containerizer.Run(c.logger, c.handle, spec, io)
 }
@@ -62,13 +83,22 @@ func (c *container) Info() (garden.ContainerInfo, error) {
 		return garden.ContainerInfo{}, err
 	}
 
-	properties, err := c.propertyManager.All(c.handle)
+	stored, err := c.propertyManager.All(c.handle)
 	if err != nil {
 		return garden.ContainerInfo{}, err
 	}
 
+	// report the memory options in effect, including the server's defaults
+	properties := garden.Properties{}
+	for name, value := range stored {
+		properties[name] = value
+	}
+	for name, value := range actualContainerSpec.Memory.Properties() {
+		properties[name] = value
+	}
+
 	mappedPorts := []garden.PortMapping{}
-	mappedPortsCfg, _ := c.propertyManager.Get(c.handle, MappedPortsKey)
+	mappedPortsCfg, _ := c.propertyManager.Get(c.networkHandle(), MappedPortsKey)
 
 	state := "active"
 	if actualContainerSpec.Stopped {
@@ -131,15 +161,27 @@ func (c *container) CurrentMemoryLimits() (garden.MemoryLimits, error) {
 }
 
 func (c *container) NetIn(hostPort, containerPort uint32) (uint32, uint32, error) {
-	return c.networker.NetIn(c.logger, c.handle, hostPort, containerPort)
+	if err := c.checkNetworked("NetIn"); err != nil {
+		return 0, 0, err
+	}
+
+	return c.networker.NetIn(c.logger, c.networkHandle(), hostPort, containerPort)
 }
 
 func (c *container) NetOut(netOutRule garden.NetOutRule) error {
-	return c.networker.NetOut(c.logger, c.handle, netOutRule)
+	if err := c.checkNetworked("NetOut"); err != nil {
+		return err
+	}
+
+	return c.networker.NetOut(c.logger, c.networkHandle(), netOutRule)
 }
 
 func (c *container) BulkNetOut(netOutRules []garden.NetOutRule) error {
-	return c.networker.BulkNetOut(c.logger, c.handle, netOutRules)
+	if err := c.checkNetworked("BulkNetOut"); err != nil {
+		return err
+	}
+
+	return c.networker.BulkNetOut(c.logger, c.networkHandle(), netOutRules)
 }
 
 func (c *container) Metrics() (garden.Metrics, error) {
@@ -178,15 +220,62 @@ func (c *container) Property(name string) (string, error) {
 }
 
 func (c *container) SetProperty(name string, value string) error {
+	if isCreateOnly(name) {
+		return fmt.Errorf("property %s can only be set when the container is created", name)
+	}
+
+	if strings.HasPrefix(name, BlockIOKeyPrefix) {
+		if err := c.updateBlockIO(name, &value); err != nil {
+			return err
+		}
+	}
+
 	c.propertyManager.Set(c.handle, name, value)
 	return nil
 }
 
 func (c *container) RemoveProperty(name string) error {
+	if isCreateOnly(name) {
+		return fmt.Errorf("property %s can only be set when the container is created", name)
+	}
+
+	if strings.HasPrefix(name, BlockIOKeyPrefix) {
+		if err := c.updateBlockIO(name, nil); err != nil {
+			return err
+		}
+	}
+
 	c.propertyManager.Remove(c.handle, name)
 	return nil
 }
 
+// updateBlockIO applies the block I/O limits which the container's properties
+// will set once the given property is set, or removed if value is nil
+func (c *container) updateBlockIO(name string, value *string) error {
+	current, err := c.propertyManager.All(c.handle)
+	if err != nil {
+		return err
+	}
+
+	properties := garden.Properties{}
+	for k, v := range current {
+		properties[k] = v
+	}
+
+	if value == nil {
+		delete(properties, name)
+	} else {
+		properties[name] = *value
+	}
+
+	limits, err := ParseBlockIOLimits(properties)
+	if err != nil {
+		return err
+	}
+
+	return c.containerizer.UpdateBlockIO(c.logger, c.handle, limits)
+}
+
 func (c *container) SetGraceTime(t time.Duration) error {
 	c.propertyManager.Set(c.handle, GraceTimeKey, fmt.Sprintf("%d", t))
 	return nil
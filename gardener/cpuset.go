@@ -0,0 +1,126 @@
+package gardener
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/lager"
+)
+
+const (
+	// CPUSetCPUsKey is the property pinning a container to a list of CPUs,
+	// e.g. "0-3,8". The CPUs are not reserved for the container, and may not
+	// include the cores which the server dedicates to containers.
+	CPUSetCPUsKey = "garden.cpuset.cpus"
+
+	// CPUSetMemsKey is the property restricting a container to a list of
+	// memory (NUMA) nodes, e.g. "0"
+	CPUSetMemsKey = "garden.cpuset.mems"
+
+	// DedicatedCoresKey is the property requesting a number of cores which the
+	// server dedicates to the container until it is destroyed
+	DedicatedCoresKey = "garden.cpuset.dedicated-cores"
+)
+
+// CPUSet pins a container to lists of CPUs and memory nodes, given in the
+// kernel's list format, e.g. "0-3,8". Empty lists leave the container
+// unrestricted.
+type CPUSet struct {
+	CPUs string
+	Mems string
+}
+
+// CPUAllocator dedicates cores from a pool to containers
+type CPUAllocator interface {
+	// Allocate dedicates the given number of free cores to the container
+	Allocate(handle string, cores int) (CPUSet, error)
+
+	// Reserve marks the cores of an existing container's CPU set as in use,
+	// e.g. after a restart
+	Reserve(handle string, cpus CPUSet) error
+
+	// Release returns the container's cores, if any, to the pool
+	Release(handle string)
+
+	// Overlaps reports whether a list of CPUs includes any of the pool's
+	// cores, which only containers they are dedicated to may use
+	Overlaps(cpus string) (bool, error)
+}
+
+// cpuSet returns the CPU set requested by a container's properties, allocating
+// its dedicated cores if it requests any
+func (g *Gardener) cpuSet(handle string, properties garden.Properties) (CPUSet, error) {
+	value, ok := properties[DedicatedCoresKey]
+	if !ok {
+		return g.pinnedCPUSet(properties)
+	}
+
+	if properties[CPUSetCPUsKey] != "" {
+		return CPUSet{}, fmt.Errorf("cannot use both %s and %s", CPUSetCPUsKey, DedicatedCoresKey)
+	}
+
+	cores, err := strconv.Atoi(value)
+	if err != nil || cores < 1 {
+		return CPUSet{}, fmt.Errorf("invalid value for %s: %s", DedicatedCoresKey, value)
+	}
+
+	if g.CPUAllocator == nil {
+		return CPUSet{}, errors.New("dedicated cores are not available on this server")
+	}
+
+	return g.CPUAllocator.Allocate(handle, cores)
+}
+
+// pinnedCPUSet returns the CPU set given explicitly by a container's
+// properties, which must leave the dedicated cores to the containers they are
+// dedicated to
+func (g *Gardener) pinnedCPUSet(properties garden.Properties) (CPUSet, error) {
+	cpuSet := CPUSet{CPUs: properties[CPUSetCPUsKey], Mems: properties[CPUSetMemsKey]}
+	if cpuSet.CPUs == "" || g.CPUAllocator == nil {
+		return cpuSet, nil
+	}
+
+	overlaps, err := g.CPUAllocator.Overlaps(cpuSet.CPUs)
+	if err != nil {
+		return CPUSet{}, fmt.Errorf("invalid value for %s: %s", CPUSetCPUsKey, err)
+	}
+
+	if overlaps {
+		return CPUSet{}, fmt.Errorf("invalid value for %s: %s includes cores dedicated to containers", CPUSetCPUsKey, cpuSet.CPUs)
+	}
+
+	return cpuSet, nil
+}
+
+// reserveDedicatedCores marks the cores dedicated to existing containers as in
+// use, so that they are not dedicated to new containers. It returns the
+// containers whose cores could not be reserved, which would otherwise share
+// them with new containers.
+func (g *Gardener) reserveDedicatedCores(log lager.Logger, handles []string) []string {
+	if g.CPUAllocator == nil {
+		return nil
+	}
+
+	var unreserved []string
+	for _, handle := range handles {
+		if _, ok := g.PropertyManager.Get(handle, DedicatedCoresKey); !ok {
+			continue
+		}
+
+		info, err := g.Containerizer.Info(log, handle)
+		if err != nil {
+			log.Error("reserve-dedicated-cores-failed", err, lager.Data{"handle": handle})
+			unreserved = append(unreserved, handle)
+			continue
+		}
+
+		if err := g.CPUAllocator.Reserve(handle, info.CPUSet); err != nil {
+			log.Error("reserve-dedicated-cores-failed", err, lager.Data{"handle": handle})
+			unreserved = append(unreserved, handle)
+		}
+	}
+
+	return unreserved
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"strings"
 	"time"
 
 	"code.cloudfoundry.org/garden"
@@ -21,6 +22,10 @@ import (
 //go:generate counterfeiter . Restorer
 //go:generate counterfeiter . Starter
 //go:generate counterfeiter . BulkStarter
+//go:generate counterfeiter . CPUAllocator
+//go:generate counterfeiter . IDAllocator
+//go:generate counterfeiter . SyscallAuditor
+//go:generate counterfeiter . NetworkSharer
 
 const ContainerIPKey = "garden.network.container-ip"
 const BridgeIPKey = "garden.network.host-ip"
@@ -28,6 +33,126 @@ const ExternalIPKey = "garden.network.external-ip"
 const MappedPortsKey = "garden.network.mapped-ports"
 const GraceTimeKey = "garden.grace-time"
 
+// NetworkSharedWithKey is the property naming the container whose network
+// namespace a container joins instead of being given its own network
+const NetworkSharedWithKey = "garden.network.shared-with"
+
+// NetworkModeKey is the property selecting how a container is networked, as an
+// alternative to giving the mode as the container spec's Network
+const NetworkModeKey = "garden.network.mode"
+
+// NetworkAllowHostAccessKey is the property which, when "true", allows a
+// container to reach the host regardless of the server's --allow-host-access
+const NetworkAllowHostAccessKey = "garden.network.allow-host-access"
+
+// NetworkDenyNetworksKey is the property listing comma-separated CIDRs to which
+// a container's traffic is denied, in addition to the server's --deny-network,
+// even where a NetOut rule would allow it
+const NetworkDenyNetworksKey = "garden.network.deny-networks"
+
+// NetOutHostnamesKey is the property holding a JSON list of NetOut rules
+// whose destinations are given by hostname rather than by IP range
+const NetOutHostnamesKey = "garden.network.netout-hostnames"
+
+// NetworkMaxNewConnectionsPerSecondKey is the property limiting the rate at
+// which a container may open new outbound connections
+const NetworkMaxNewConnectionsPerSecondKey = "garden.network.max-new-connections-per-second"
+
+// NetworkMaxConnectionsKey is the property limiting the number of concurrent
+// outbound connections a container may have open
+const NetworkMaxConnectionsKey = "garden.network.max-connections"
+
+// DefaultExternalIPKey is the property choosing which of the server's external
+// IPs a container's mapped ports are bound to, instead of the server's default
+const DefaultExternalIPKey = "garden.network.default-external-ip"
+
+// NetInExternalIPsKey is the property holding a JSON object from container
+// ports to the external IP to which NetIn binds their mappings, overriding the
+// container's default external IP
+const NetInExternalIPsKey = "garden.network.netin-external-ips"
+
+// NetworkMtuKey is the property requesting an MTU for a container's network
+// interface other than the server's --mtu. It is clamped to the uplink's MTU.
+const NetworkMtuKey = "garden.network.mtu"
+
+// NetworkInterfaceNameKey is the property naming a container's network
+// interface as seen from inside the container, e.g. "eth0"
+const NetworkInterfaceNameKey = "garden.network.interface-name"
+
+// SecurityProfileKey is the property selecting one of the security profiles
+// registered on the server, which adjusts the container's capabilities,
+// seccomp profile and AppArmor profile
+const SecurityProfileKey = "garden.security-profile"
+
+// DevicesKey is the property requesting a comma-separated list of host
+// devices, e.g. "/dev/kvm,/dev/net/tun", to pass through to a privileged
+// container. The server must make each device available for passthrough.
+const DevicesKey = "garden.devices"
+
+// SysctlKeyPrefix prefixes the properties setting namespaced sysctls in a
+// container, e.g. "garden.sysctl.net.core.somaxconn": "1024"
+const SysctlKeyPrefix = "garden.sysctl."
+
+// createOnlyKeys are the properties which configure a container as it is
+// created. Some are read again later, e.g. to find the network a container
+// shares or to repair its firewall, so they may not be changed or removed
+// afterwards.
+var createOnlyKeys = []string{
+	NetworkSharedWithKey,
+	NetworkModeKey,
+	NetworkAllowHostAccessKey,
+	NetworkDenyNetworksKey,
+	NetworkMaxNewConnectionsPerSecondKey,
+	NetworkMaxConnectionsKey,
+	NetworkMtuKey,
+	NetworkInterfaceNameKey,
+	SecurityProfileKey,
+	DevicesKey,
+	CPUSetCPUsKey,
+	CPUSetMemsKey,
+	DedicatedCoresKey,
+	MemoryReservationKey,
+	MemorySwapKey,
+	MemorySwappinessKey,
+	KernelMemoryKey,
+	OOMScoreAdjKey,
+}
+
+func isCreateOnly(name string) bool {
+	if strings.HasPrefix(name, SysctlKeyPrefix) {
+		return true
+	}
+
+	for _, key := range createOnlyKeys {
+		if name == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+const (
+	// NetworkModeNone gives the container a loopback interface only
+	NetworkModeNone = "none"
+	// NetworkModeHost runs a privileged container in the host's network namespace
+	NetworkModeHost = "host"
+	// NetworkModeMacvlan and NetworkModeIpvlan attach the container directly to a host interface
+	NetworkModeMacvlan = "macvlan"
+	NetworkModeIpvlan  = "ipvlan"
+)
+
+// NetworkMode returns the network mode requested by the spec, or the empty
+// string if the container should be given a bridged network
+func NetworkMode(spec garden.ContainerSpec) string {
+	switch spec.Network {
+	case NetworkModeNone, NetworkModeHost, NetworkModeMacvlan, NetworkModeIpvlan:
+		return spec.Network
+	}
+
+	return spec.Properties[NetworkModeKey]
+}
+
 const RawRootFSScheme = "raw"
 
 type SysInfoProvider interface {
@@ -50,6 +175,8 @@ type Containerizer interface {
 
 	Info(log lager.Logger, handle string) (ActualContainerSpec, error)
 	Metrics(log lager.Logger, handle string) (ActualContainerMetrics, error)
+
+	UpdateBlockIO(log lager.Logger, handle string, limits BlockIOLimits) error
 }
 
 type Networker interface {
@@ -62,6 +189,12 @@ type Networker interface {
 	Restore(log lager.Logger, handle string) error
 }
 
+// NetworkSharer configures a container which joins the network namespace of
+// another, e.g. giving it the owner's DNS servers
+type NetworkSharer interface {
+	ShareNetwork(log lager.Logger, owner, handle string, pid int) error
+}
+
 type VolumeCreator interface {
 	Create(log lager.Logger, handle string, spec rootfs_provider.Spec) (string, []string, error)
 	Destroy(log lager.Logger, handle string) error
@@ -94,6 +227,13 @@ type Restorer interface {
 	Restore(logger lager.Logger, handles []string) []string
 }
 
+// SyscallAuditor collects the syscalls made by containers in seccomp audit
+// mode
+type SyscallAuditor interface {
+	// Forget discards the syscalls collected for a destroyed container
+	Forget(handle string)
+}
+
 type UidGeneratorFunc func() string
 
 func (fn UidGeneratorFunc) Generate() string {
@@ -118,6 +258,34 @@ type DesiredContainerSpec struct {
 	Limits garden.Limits
 
 	Env []string
+
+	// Path to an existing network namespace to join, e.g. /proc/<pid>/ns/net
+	NetworkNamespacePath string
+
+	// Container runs in the host's network namespace
+	HostNetwork bool
+
+	// Name of the server's security profile to apply, if any
+	SecurityProfile string
+
+	// Paths of host devices to pass through to the container
+	Devices []string
+
+	// Namespaced sysctls to set in the container
+	Sysctls map[string]string
+
+	// Block I/O weight and throttles
+	BlockIO BlockIOLimits
+
+	// CPUs and memory nodes to pin the container to
+	CPUSet CPUSet
+
+	// Memory options beyond the memory limit
+	Memory MemoryOptions
+
+	// User namespace mappings of the container's own range of host IDs, or
+	// none to use the server's mappings
+	IDMappings IDMappings
 }
 
 type ActualContainerSpec struct {
@@ -144,6 +312,18 @@ type ActualContainerSpec struct {
 
 	// Whether the container is privileged
 	Privileged bool
+
+	// Applied block I/O limits, with devices given by their major:minor numbers
+	BlockIO BlockIOLimits
+
+	// CPUs and memory nodes the container is pinned to
+	CPUSet CPUSet
+
+	// Applied memory options, with the swap in addition to the memory limit
+	Memory MemoryOptions
+
+	// User namespace mappings of unprivileged containers
+	IDMappings IDMappings
 }
 
 type ActualContainerMetrics struct {
@@ -180,6 +360,22 @@ type Gardener struct {
 	MaxContainers uint64
 
 	Restorer Restorer
+
+	// CPUAllocator dedicates cores to containers, or is nil if the server has
+	// no cores to dedicate
+	CPUAllocator CPUAllocator
+
+	// IDAllocator gives unprivileged containers their own ranges of host IDs,
+	// or is nil if they share the server's mappings
+	IDAllocator IDAllocator
+
+	// NetworkSharer configures containers which share the network of another,
+	// or is nil if the Networker leaves them as they are
+	NetworkSharer NetworkSharer
+
+	// SyscallAuditor collects the syscalls audited in containers, or is nil if
+	// no container can be in seccomp audit mode
+	SyscallAuditor SyscallAuditor
 }
 
 // Create creates a container by combining the results of networker.Network,
@@ -227,6 +423,41 @@ func (g *Gardener) Create(spec garden.ContainerSpec) (ctr garden.Container, err
 		return nil, err
 	}
 
+	mode := NetworkMode(spec)
+	if err := validateNetworkMode(mode, spec); err != nil {
+		return nil, err
+	}
+
+	blockIO, err := ParseBlockIOLimits(spec.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	memory, err := ParseMemoryOptions(spec.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	cpuSet, err := g.cpuSet(spec.Handle, spec.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	idMappings, err := g.idMappings(spec.Handle, spec.Privileged)
+	if err != nil {
+		return nil, err
+	}
+
+	var networkNamespacePath string
+	sharedWith := spec.Properties[NetworkSharedWithKey]
+	if sharedWith != "" {
+		sharedWith, networkNamespacePath, err = g.sharedNetworkNamespace(log, sharedWith)
+		if err != nil {
+			return nil, err
+		}
+		spec.Properties[NetworkSharedWithKey] = sharedWith
+	}
+
 	if err := g.VolumeCreator.GC(log); err != nil {
 		log.Error("graph-cleanup-failed", err)
 	}
@@ -259,17 +490,46 @@ func (g *Gardener) Create(spec garden.ContainerSpec) (ctr garden.Container, err
 		BindMounts: spec.BindMounts,
 		Limits:     spec.Limits,
 		Env:        append(env, spec.Env...),
+
+		NetworkNamespacePath: networkNamespacePath,
+		HostNetwork:          mode == NetworkModeHost,
+
+		SecurityProfile: spec.Properties[SecurityProfileKey],
+		Devices:         devices(spec.Properties[DevicesKey]),
+		Sysctls:         sysctls(spec.Properties),
+		BlockIO:         blockIO,
+		CPUSet:          cpuSet,
+		Memory:          memory,
+		IDMappings:      idMappings,
 	}); err != nil {
 		return nil, err
 	}
 
-	actualSpec, err := g.Containerizer.Info(log, spec.Handle)
-	if err != nil {
-		return nil, err
+	if mode != "" {
+		g.PropertyManager.Set(spec.Handle, NetworkModeKey, mode)
 	}
 
-	if err = g.Networker.Network(log, spec, actualSpec.Pid); err != nil {
-		return nil, err
+	if sharedWith != "" {
+		if err := g.copyNetworkProperties(sharedWith, spec.Handle); err != nil {
+			return nil, err
+		}
+
+		if err := g.shareNetwork(log, sharedWith, spec.Handle); err != nil {
+			return nil, err
+		}
+	} else if !hasOwnNetwork(mode) {
+		for _, key := range []string{ContainerIPKey, BridgeIPKey, ExternalIPKey} {
+			g.PropertyManager.Set(spec.Handle, key, "")
+		}
+	} else {
+		actualSpec, err := g.Containerizer.Info(log, spec.Handle)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = g.Networker.Network(log, spec, actualSpec.Pid); err != nil {
+			return nil, err
+		}
 	}
 
 	container, err := g.Lookup(spec.Handle)
@@ -284,6 +544,13 @@ func (g *Gardener) Create(spec garden.ContainerSpec) (ctr garden.Container, err
 	}
 
 	for name, value := range spec.Properties {
+		// the containerizer has already applied the block I/O limits, and
+		// the create-only properties
+		if strings.HasPrefix(name, BlockIOKeyPrefix) || isCreateOnly(name) {
+			g.PropertyManager.Set(spec.Handle, name, value)
+			continue
+		}
+
 		if err := container.SetProperty(name, value); err != nil {
 			return nil, err
 		}
@@ -296,6 +563,128 @@ func (g *Gardener) Create(spec garden.ContainerSpec) (ctr garden.Container, err
 	return container, nil
 }
 
+func devices(property string) []string {
+	var paths []string
+	for _, path := range strings.Split(property, ",") {
+		path = strings.TrimSpace(path)
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+func sysctls(properties garden.Properties) map[string]string {
+	var sysctls map[string]string
+	for key, value := range properties {
+		if !strings.HasPrefix(key, SysctlKeyPrefix) {
+			continue
+		}
+
+		if sysctls == nil {
+			sysctls = map[string]string{}
+		}
+		sysctls[strings.TrimPrefix(key, SysctlKeyPrefix)] = value
+	}
+
+	return sysctls
+}
+
+func validateNetworkMode(mode string, spec garden.ContainerSpec) error {
+	switch mode {
+	case "", NetworkModeNone, NetworkModeMacvlan, NetworkModeIpvlan:
+	case NetworkModeHost:
+		if !spec.Privileged {
+			return errors.New("network mode host is only allowed for privileged containers")
+		}
+	default:
+		return fmt.Errorf("unknown network mode: %s", mode)
+	}
+
+	if mode != "" && spec.Properties[NetworkSharedWithKey] != "" {
+		return fmt.Errorf("cannot share the network of another container in network mode %s", mode)
+	}
+
+	return nil
+}
+
+// hasOwnNetwork returns false if containers in the given network mode are not
+// networked by the Networker
+func hasOwnNetwork(mode string) bool {
+	return mode != NetworkModeNone && mode != NetworkModeHost
+}
+
+// sharedNetworkNamespace returns the handle of the container which owns the
+// network namespace of the given container, along with the path to it
+func (g *Gardener) sharedNetworkNamespace(log lager.Logger, handle string) (string, string, error) {
+	handles, err := g.Containerizer.Handles()
+	if err != nil {
+		return "", "", err
+	}
+
+	if !g.exists(handles, handle) {
+		return "", "", garden.ContainerNotFoundError{Handle: handle}
+	}
+
+	// join the namespace's owner rather than building chains of dependents
+	if owner, ok := g.PropertyManager.Get(handle, NetworkSharedWithKey); ok && owner != "" {
+		handle = owner
+	}
+
+	actualSpec, err := g.Containerizer.Info(log, handle)
+	if err != nil {
+		return "", "", err
+	}
+
+	if actualSpec.Stopped || actualSpec.Pid == 0 {
+		return "", "", fmt.Errorf("cannot share network of container %s: container is not running", handle)
+	}
+
+	return handle, fmt.Sprintf("/proc/%d/ns/net", actualSpec.Pid), nil
+}
+
+func (g *Gardener) copyNetworkProperties(from, to string) error {
+	for _, key := range []string{ContainerIPKey, BridgeIPKey, ExternalIPKey} {
+		value, ok := g.PropertyManager.Get(from, key)
+		if !ok {
+			return fmt.Errorf("cannot share network of container %s: no property found: %s", from, key)
+		}
+
+		g.PropertyManager.Set(to, key, value)
+	}
+
+	return nil
+}
+
+// shareNetwork configures a container which joins the network of its owner,
+// which its Networker did not network
+func (g *Gardener) shareNetwork(log lager.Logger, owner, handle string) error {
+	if g.NetworkSharer == nil {
+		return nil
+	}
+
+	actualSpec, err := g.Containerizer.Info(log, handle)
+	if err != nil {
+		return err
+	}
+
+	return g.NetworkSharer.ShareNetwork(log, owner, handle, actualSpec.Pid)
+}
+
+// networkDependents returns the handles of the containers which share the
+// network namespace of the given container
+func (g *Gardener) networkDependents(handles []string, handle string) []string {
+	var dependents []string
+	for _, h := range handles {
+		if h != handle && g.PropertyManager.MatchesAll(h, garden.Properties{NetworkSharedWithKey: handle}) {
+			dependents = append(dependents, h)
+		}
+	}
+
+	return dependents
+}
+
 func (g *Gardener) Lookup(handle string) (garden.Container, error) {
 	return g.lookup(handle), nil
 }
@@ -326,6 +715,10 @@ func (g *Gardener) Destroy(handle string) error {
 		return garden.ContainerNotFoundError{Handle: handle}
 	}
 
+	if dependents := g.networkDependents(handles, handle); len(dependents) > 0 {
+		return fmt.Errorf("cannot destroy container %s: containers share its network: %s", handle, strings.Join(dependents, ", "))
+	}
+
 	return g.destroy(log, handle)
 }
 
@@ -343,6 +736,18 @@ func (g *Gardener) destroy(log lager.Logger, handle string) error {
 		return err
 	}
 
+	if g.CPUAllocator != nil {
+		g.CPUAllocator.Release(handle)
+	}
+
+	if g.IDAllocator != nil {
+		g.IDAllocator.Release(handle)
+	}
+
+	if g.SyscallAuditor != nil {
+		g.SyscallAuditor.Forget(handle)
+	}
+
 	if err := g.PropertyManager.DestroyKeySpace(handle); err != nil {
 		return err
 	}
@@ -495,7 +900,42 @@ func (g *Gardener) Start() error {
 		return err
 	}
 
-	for _, handle := range g.Restorer.Restore(log, handles) {
+	// containers whose resources cannot be reserved are destroyed, as are
+	// those which cannot be restored, rather than sharing their resources with
+	// new containers
+	unreserved := g.reserveDedicatedCores(log, handles)
+	for _, handle := range g.reserveIDMappings(log, handles) {
+		if !g.exists(unreserved, handle) {
+			unreserved = append(unreserved, handle)
+		}
+	}
+
+	// containers sharing another container's network, or networked without the
+	// Networker, have no network to restore; the former cannot outlive the
+	// container they share it with
+	var owners, dependents []string
+	for _, handle := range handles {
+		if g.exists(unreserved, handle) {
+			continue
+		}
+
+		mode, _ := g.PropertyManager.Get(handle, NetworkModeKey)
+		if owner, ok := g.PropertyManager.Get(handle, NetworkSharedWithKey); ok && owner != "" {
+			dependents = append(dependents, handle)
+		} else if hasOwnNetwork(mode) {
+			owners = append(owners, handle)
+		}
+	}
+
+	failedHandles := append(unreserved, g.Restorer.Restore(log, owners)...)
+	for _, handle := range dependents {
+		owner, _ := g.PropertyManager.Get(handle, NetworkSharedWithKey)
+		if !g.exists(handles, owner) || g.exists(failedHandles, owner) {
+			failedHandles = append(failedHandles, handle)
+		}
+	}
+
+	for _, handle := range failedHandles {
 		destroyLog := log.Session("clean-up-container", lager.Data{"handle": handle})
 		destroyLog.Info("start")
 
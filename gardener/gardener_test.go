@@ -426,6 +426,319 @@ var _ = Describe("Gardener", func() {
 			})
 		})
 
+		Context("when a security profile is specified", func() {
+			It("passes it to the containerizer", func() {
+				_, err := gdnr.Create(garden.ContainerSpec{
+					Properties: garden.Properties{gardener.SecurityProfileKey: "debug"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(containerizer.CreateCallCount()).To(Equal(1))
+				_, spec := containerizer.CreateArgsForCall(0)
+				Expect(spec.SecurityProfile).To(Equal("debug"))
+			})
+		})
+
+		Context("when devices are requested", func() {
+			It("passes them to the containerizer", func() {
+				_, err := gdnr.Create(garden.ContainerSpec{
+					Properties: garden.Properties{gardener.DevicesKey: "/dev/kvm, /dev/net/tun,"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(containerizer.CreateCallCount()).To(Equal(1))
+				_, spec := containerizer.CreateArgsForCall(0)
+				Expect(spec.Devices).To(Equal([]string{"/dev/kvm", "/dev/net/tun"}))
+			})
+		})
+
+		Context("when sysctls are specified", func() {
+			It("passes them to the containerizer", func() {
+				_, err := gdnr.Create(garden.ContainerSpec{
+					Properties: garden.Properties{
+						gardener.SysctlKeyPrefix + "net.core.somaxconn": "1024",
+						gardener.SysctlKeyPrefix + "kernel.shmmax":      "68719476736",
+						"some-other-property":                           "banana",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(containerizer.CreateCallCount()).To(Equal(1))
+				_, spec := containerizer.CreateArgsForCall(0)
+				Expect(spec.Sysctls).To(Equal(map[string]string{
+					"net.core.somaxconn": "1024",
+					"kernel.shmmax":      "68719476736",
+				}))
+			})
+		})
+
+		Context("when block I/O limits are specified", func() {
+			It("passes them to the containerizer", func() {
+				_, err := gdnr.Create(garden.ContainerSpec{
+					Properties: garden.Properties{gardener.BlockIOWeightKey: "200"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(containerizer.CreateCallCount()).To(Equal(1))
+				_, spec := containerizer.CreateArgsForCall(0)
+				Expect(spec.BlockIO).To(Equal(gardener.BlockIOLimits{Weight: 200}))
+			})
+
+			It("stores them without updating the created container", func() {
+				_, err := gdnr.Create(garden.ContainerSpec{
+					Handle:     "banana-handle",
+					Properties: garden.Properties{gardener.BlockIOWeightKey: "200"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(containerizer.UpdateBlockIOCallCount()).To(Equal(0))
+
+				var stored []string
+				for i := 0; i < propertyManager.SetCallCount(); i++ {
+					handle, name, value := propertyManager.SetArgsForCall(i)
+					stored = append(stored, handle+" "+name+"="+value)
+				}
+				Expect(stored).To(ContainElement("banana-handle garden.blkio.weight=200"))
+			})
+
+			Context("when they are invalid", func() {
+				It("returns an error without creating the container", func() {
+					_, err := gdnr.Create(garden.ContainerSpec{
+						Properties: garden.Properties{gardener.BlockIOWriteBpsKey: "/dev/sda"},
+					})
+					Expect(err).To(MatchError(ContainSubstring("invalid value for garden.blkio.write-bps")))
+					Expect(containerizer.CreateCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when the container is pinned to CPUs", func() {
+			It("passes the cpuset to the containerizer", func() {
+				_, err := gdnr.Create(garden.ContainerSpec{
+					Properties: garden.Properties{
+						gardener.CPUSetCPUsKey: "0-3",
+						gardener.CPUSetMemsKey: "0",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, spec := containerizer.CreateArgsForCall(0)
+				Expect(spec.CPUSet).To(Equal(gardener.CPUSet{CPUs: "0-3", Mems: "0"}))
+			})
+
+			Context("when the server dedicates cores to containers", func() {
+				var cpuAllocator *fakes.FakeCPUAllocator
+
+				BeforeEach(func() {
+					cpuAllocator = new(fakes.FakeCPUAllocator)
+					gdnr.CPUAllocator = cpuAllocator
+				})
+
+				It("checks the CPUs against the dedicated cores", func() {
+					_, err := gdnr.Create(garden.ContainerSpec{
+						Properties: garden.Properties{gardener.CPUSetCPUsKey: "0-3"},
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(cpuAllocator.OverlapsCallCount()).To(Equal(1))
+					Expect(cpuAllocator.OverlapsArgsForCall(0)).To(Equal("0-3"))
+					Expect(cpuAllocator.AllocateCallCount()).To(Equal(0))
+				})
+
+				Context("and the CPUs include dedicated cores", func() {
+					It("returns an error without creating the container", func() {
+						cpuAllocator.OverlapsReturns(true, nil)
+
+						_, err := gdnr.Create(garden.ContainerSpec{
+							Properties: garden.Properties{gardener.CPUSetCPUsKey: "0-3"},
+						})
+						Expect(err).To(MatchError("invalid value for garden.cpuset.cpus: 0-3 includes cores dedicated to containers"))
+						Expect(containerizer.CreateCallCount()).To(Equal(0))
+					})
+				})
+
+				Context("and the CPU list is invalid", func() {
+					It("returns an error without creating the container", func() {
+						cpuAllocator.OverlapsReturns(false, errors.New("invalid CPU list"))
+
+						_, err := gdnr.Create(garden.ContainerSpec{
+							Properties: garden.Properties{gardener.CPUSetCPUsKey: "banana"},
+						})
+						Expect(err).To(MatchError("invalid value for garden.cpuset.cpus: invalid CPU list"))
+						Expect(containerizer.CreateCallCount()).To(Equal(0))
+					})
+				})
+			})
+		})
+
+		Context("when dedicated cores are requested", func() {
+			var cpuAllocator *fakes.FakeCPUAllocator
+
+			BeforeEach(func() {
+				cpuAllocator = new(fakes.FakeCPUAllocator)
+				cpuAllocator.AllocateReturns(gardener.CPUSet{CPUs: "4-5", Mems: "1"}, nil)
+				gdnr.CPUAllocator = cpuAllocator
+			})
+
+			create := func(properties garden.Properties) error {
+				_, err := gdnr.Create(garden.ContainerSpec{Handle: "banana-handle", Properties: properties})
+				return err
+			}
+
+			It("pins the container to the allocated cores", func() {
+				Expect(create(garden.Properties{gardener.DedicatedCoresKey: "2"})).To(Succeed())
+
+				Expect(cpuAllocator.AllocateCallCount()).To(Equal(1))
+				handle, cores := cpuAllocator.AllocateArgsForCall(0)
+				Expect(handle).To(Equal("banana-handle"))
+				Expect(cores).To(Equal(2))
+
+				_, spec := containerizer.CreateArgsForCall(0)
+				Expect(spec.CPUSet).To(Equal(gardener.CPUSet{CPUs: "4-5", Mems: "1"}))
+			})
+
+			Context("when the cores cannot be allocated", func() {
+				It("returns the error without creating the container", func() {
+					cpuAllocator.AllocateReturns(gardener.CPUSet{}, errors.New("not enough free cores"))
+
+					Expect(create(garden.Properties{gardener.DedicatedCoresKey: "2"})).To(MatchError("not enough free cores"))
+					Expect(containerizer.CreateCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when creating the container fails", func() {
+				It("releases the cores", func() {
+					containerizer.CreateReturns(errors.New("banana"))
+
+					Expect(create(garden.Properties{gardener.DedicatedCoresKey: "2"})).NotTo(Succeed())
+					Expect(cpuAllocator.ReleaseCallCount()).To(Equal(1))
+					Expect(cpuAllocator.ReleaseArgsForCall(0)).To(Equal("banana-handle"))
+				})
+			})
+
+			Context("when the number of cores is invalid", func() {
+				It("returns an error", func() {
+					Expect(create(garden.Properties{gardener.DedicatedCoresKey: "0"})).To(MatchError("invalid value for garden.cpuset.dedicated-cores: 0"))
+					Expect(cpuAllocator.AllocateCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when CPUs are also given explicitly", func() {
+				It("returns an error", func() {
+					Expect(create(garden.Properties{
+						gardener.DedicatedCoresKey: "2",
+						gardener.CPUSetCPUsKey:     "0-1",
+					})).To(MatchError("cannot use both garden.cpuset.cpus and garden.cpuset.dedicated-cores"))
+				})
+			})
+
+			Context("when the server has no cores to dedicate", func() {
+				It("returns an error", func() {
+					gdnr.CPUAllocator = nil
+
+					Expect(create(garden.Properties{gardener.DedicatedCoresKey: "2"})).To(MatchError("dedicated cores are not available on this server"))
+					Expect(containerizer.CreateCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when containers are given their own ranges of host IDs", func() {
+			var (
+				idAllocator *fakes.FakeIDAllocator
+				mappings    gardener.IDMappings
+			)
+
+			BeforeEach(func() {
+				mappings = gardener.IDMappings{
+					UIDMappings: rootfs_provider.MappingList{{ContainerID: 0, HostID: 100000, Size: 65536}},
+					GIDMappings: rootfs_provider.MappingList{{ContainerID: 0, HostID: 200000, Size: 65536}},
+				}
+
+				idAllocator = new(fakes.FakeIDAllocator)
+				idAllocator.AllocateReturns(mappings, nil)
+				gdnr.IDAllocator = idAllocator
+			})
+
+			It("creates unprivileged containers with the allocated mappings", func() {
+				_, err := gdnr.Create(garden.ContainerSpec{Handle: "banana-handle"})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(idAllocator.AllocateCallCount()).To(Equal(1))
+				Expect(idAllocator.AllocateArgsForCall(0)).To(Equal("banana-handle"))
+
+				_, spec := containerizer.CreateArgsForCall(0)
+				Expect(spec.IDMappings).To(Equal(mappings))
+			})
+
+			It("allocates the mappings before creating the volume", func() {
+				volumeCreator.CreateStub = func(_ lager.Logger, handle string, spec rootfs_provider.Spec) (string, []string, error) {
+					Expect(idAllocator.AllocateCallCount()).To(Equal(1))
+					return "", nil, nil
+				}
+
+				_, err := gdnr.Create(garden.ContainerSpec{Handle: "banana-handle", Image: garden.ImageRef{URI: "docker:///busybox"}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(volumeCreator.CreateCallCount()).To(Equal(1))
+			})
+
+			It("does not allocate mappings to privileged containers", func() {
+				_, err := gdnr.Create(garden.ContainerSpec{Handle: "banana-handle", Privileged: true})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(idAllocator.AllocateCallCount()).To(Equal(0))
+
+				_, spec := containerizer.CreateArgsForCall(0)
+				Expect(spec.IDMappings).To(Equal(gardener.IDMappings{}))
+			})
+
+			Context("when no range is free", func() {
+				It("returns the error without creating the container", func() {
+					idAllocator.AllocateReturns(gardener.IDMappings{}, errors.New("no free id ranges"))
+
+					_, err := gdnr.Create(garden.ContainerSpec{Handle: "banana-handle"})
+					Expect(err).To(MatchError("no free id ranges"))
+					Expect(containerizer.CreateCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when creating the container fails", func() {
+				It("releases the range", func() {
+					containerizer.CreateReturns(errors.New("banana"))
+
+					_, err := gdnr.Create(garden.ContainerSpec{Handle: "banana-handle"})
+					Expect(err).To(HaveOccurred())
+					Expect(idAllocator.ReleaseCallCount()).To(Equal(1))
+					Expect(idAllocator.ReleaseArgsForCall(0)).To(Equal("banana-handle"))
+				})
+			})
+		})
+
+		Context("when memory options are specified", func() {
+			It("passes them to the containerizer", func() {
+				_, err := gdnr.Create(garden.ContainerSpec{
+					Properties: garden.Properties{
+						gardener.MemoryReservationKey: "1024",
+						gardener.MemorySwapKey:        "2048",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, spec := containerizer.CreateArgsForCall(0)
+				Expect(spec.Memory.Reservation).To(BeEquivalentTo(1024))
+				Expect(*spec.Memory.Swap).To(BeEquivalentTo(2048))
+			})
+
+			Context("when they are invalid", func() {
+				It("returns an error without creating the container", func() {
+					_, err := gdnr.Create(garden.ContainerSpec{
+						Properties: garden.Properties{gardener.MemorySwappinessKey: "200"},
+					})
+					Expect(err).To(MatchError("invalid value for garden.memory.swappiness: 200"))
+					Expect(containerizer.CreateCallCount()).To(Equal(0))
+				})
+			})
+		})
+
 		Context("when passed a handle that already exists", func() {
 			var (
 				containerSpec garden.ContainerSpec
@@ -491,6 +804,283 @@ var _ = Describe("Gardener", func() {
 			})
 		})
 
+		Describe("network modes", func() {
+			var spec garden.ContainerSpec
+
+			BeforeEach(func() {
+				spec = garden.ContainerSpec{Handle: "some-container"}
+			})
+
+			setProperties := func() map[string]string {
+				props := map[string]string{}
+				for i := 0; i < propertyManager.SetCallCount(); i++ {
+					_, name, value := propertyManager.SetArgsForCall(i)
+					props[name] = value
+				}
+				return props
+			}
+
+			Context("when the network mode is none", func() {
+				BeforeEach(func() {
+					spec.Network = "none"
+				})
+
+				It("does not configure a network", func() {
+					_, err := gdnr.Create(spec)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(networker.NetworkCallCount()).To(Equal(0))
+				})
+
+				It("keeps the container's own network namespace", func() {
+					_, err := gdnr.Create(spec)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, desiredSpec := containerizer.CreateArgsForCall(0)
+					Expect(desiredSpec.HostNetwork).To(BeFalse())
+				})
+
+				It("records the mode and empty network properties", func() {
+					_, err := gdnr.Create(spec)
+					Expect(err).NotTo(HaveOccurred())
+
+					props := setProperties()
+					Expect(props).To(HaveKeyWithValue(gardener.NetworkModeKey, "none"))
+					Expect(props).To(HaveKeyWithValue(gardener.ContainerIPKey, ""))
+					Expect(props).To(HaveKeyWithValue(gardener.BridgeIPKey, ""))
+					Expect(props).To(HaveKeyWithValue(gardener.ExternalIPKey, ""))
+				})
+			})
+
+			Context("when the network mode is host", func() {
+				BeforeEach(func() {
+					spec.Properties = garden.Properties{gardener.NetworkModeKey: "host"}
+					spec.Privileged = true
+				})
+
+				It("asks the containerizer to use the host network", func() {
+					_, err := gdnr.Create(spec)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, desiredSpec := containerizer.CreateArgsForCall(0)
+					Expect(desiredSpec.HostNetwork).To(BeTrue())
+				})
+
+				It("does not configure a network", func() {
+					_, err := gdnr.Create(spec)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(networker.NetworkCallCount()).To(Equal(0))
+				})
+
+				Context("when the container is not privileged", func() {
+					BeforeEach(func() {
+						spec.Privileged = false
+					})
+
+					It("returns an error without creating the container", func() {
+						_, err := gdnr.Create(spec)
+						Expect(err).To(MatchError("network mode host is only allowed for privileged containers"))
+						Expect(containerizer.CreateCallCount()).To(Equal(0))
+					})
+				})
+			})
+
+			Context("when the network mode is macvlan", func() {
+				BeforeEach(func() {
+					spec.Network = "macvlan"
+				})
+
+				It("asks the networker to configure the network", func() {
+					_, err := gdnr.Create(spec)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(networker.NetworkCallCount()).To(Equal(1))
+					_, networkSpec, _ := networker.NetworkArgsForCall(0)
+					Expect(networkSpec.Network).To(Equal("macvlan"))
+				})
+
+				It("records the mode", func() {
+					_, err := gdnr.Create(spec)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(setProperties()).To(HaveKeyWithValue(gardener.NetworkModeKey, "macvlan"))
+				})
+			})
+
+			Context("when the network mode is unknown", func() {
+				BeforeEach(func() {
+					spec.Properties = garden.Properties{gardener.NetworkModeKey: "banana"}
+				})
+
+				It("returns an error", func() {
+					_, err := gdnr.Create(spec)
+					Expect(err).To(MatchError("unknown network mode: banana"))
+				})
+			})
+
+			Context("when the network is also shared with another container", func() {
+				BeforeEach(func() {
+					spec.Network = "none"
+					spec.Properties = garden.Properties{gardener.NetworkSharedWithKey: "app"}
+				})
+
+				It("returns an error", func() {
+					_, err := gdnr.Create(spec)
+					Expect(err).To(MatchError(ContainSubstring("cannot share the network of another container")))
+				})
+			})
+		})
+
+		Context("when the container shares the network of another container", func() {
+			var (
+				spec      garden.ContainerSpec
+				ownerInfo gardener.ActualContainerSpec
+			)
+
+			BeforeEach(func() {
+				spec = garden.ContainerSpec{
+					Handle:     "sidecar",
+					Properties: garden.Properties{gardener.NetworkSharedWithKey: "app"},
+				}
+
+				ownerInfo = gardener.ActualContainerSpec{Pid: 42}
+				containerizer.HandlesStub = func() ([]string, error) {
+					return []string{"app"}, nil
+				}
+				containerizer.InfoStub = func(_ lager.Logger, handle string) (gardener.ActualContainerSpec, error) {
+					if handle == "app" {
+						return ownerInfo, nil
+					}
+					return gardener.ActualContainerSpec{Pid: 43}, nil
+				}
+				propertyManager.GetStub = func(handle, name string) (string, bool) {
+					if handle == "app" && name != gardener.NetworkSharedWithKey {
+						return "app-" + name, true
+					}
+					return "", false
+				}
+			})
+
+			It("asks the containerizer to join the network namespace of the container", func() {
+				_, err := gdnr.Create(spec)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(containerizer.CreateCallCount()).To(Equal(1))
+				_, desiredSpec := containerizer.CreateArgsForCall(0)
+				Expect(desiredSpec.NetworkNamespacePath).To(Equal("/proc/42/ns/net"))
+			})
+
+			It("does not configure a network", func() {
+				_, err := gdnr.Create(spec)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(networker.NetworkCallCount()).To(Equal(0))
+			})
+
+			It("copies the network properties of the container", func() {
+				_, err := gdnr.Create(spec)
+				Expect(err).NotTo(HaveOccurred())
+
+				props := map[string]string{}
+				for i := 0; i < propertyManager.SetCallCount(); i++ {
+					handle, name, value := propertyManager.SetArgsForCall(i)
+					Expect(handle).To(Equal("sidecar"))
+					props[name] = value
+				}
+
+				Expect(props).To(HaveKeyWithValue(gardener.ContainerIPKey, "app-"+gardener.ContainerIPKey))
+				Expect(props).To(HaveKeyWithValue(gardener.BridgeIPKey, "app-"+gardener.BridgeIPKey))
+				Expect(props).To(HaveKeyWithValue(gardener.ExternalIPKey, "app-"+gardener.ExternalIPKey))
+				Expect(props).To(HaveKeyWithValue(gardener.NetworkSharedWithKey, "app"))
+			})
+
+			Context("when the networker configures containers which share a network", func() {
+				var networkSharer *fakes.FakeNetworkSharer
+
+				BeforeEach(func() {
+					networkSharer = new(fakes.FakeNetworkSharer)
+					gdnr.NetworkSharer = networkSharer
+				})
+
+				It("configures the container from the network of the container it shares", func() {
+					_, err := gdnr.Create(spec)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(networkSharer.ShareNetworkCallCount()).To(Equal(1))
+					_, owner, handle, pid := networkSharer.ShareNetworkArgsForCall(0)
+					Expect(owner).To(Equal("app"))
+					Expect(handle).To(Equal("sidecar"))
+					Expect(pid).To(Equal(43))
+				})
+
+				Context("when configuring the container fails", func() {
+					It("returns the error", func() {
+						networkSharer.ShareNetworkReturns(errors.New("no-resolv-conf"))
+
+						_, err := gdnr.Create(spec)
+						Expect(err).To(MatchError("no-resolv-conf"))
+					})
+				})
+			})
+
+			Context("when the container itself shares the network of another container", func() {
+				BeforeEach(func() {
+					containerizer.HandlesStub = func() ([]string, error) {
+						return []string{"app", "other-sidecar"}, nil
+					}
+					propertyManager.GetStub = func(handle, name string) (string, bool) {
+						if handle == "other-sidecar" && name == gardener.NetworkSharedWithKey {
+							return "app", true
+						}
+						return "", true
+					}
+					spec.Properties[gardener.NetworkSharedWithKey] = "other-sidecar"
+				})
+
+				It("shares the network of the original container", func() {
+					_, err := gdnr.Create(spec)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, desiredSpec := containerizer.CreateArgsForCall(0)
+					Expect(desiredSpec.NetworkNamespacePath).To(Equal("/proc/42/ns/net"))
+
+					var owner string
+					for i := 0; i < propertyManager.SetCallCount(); i++ {
+						_, name, value := propertyManager.SetArgsForCall(i)
+						if name == gardener.NetworkSharedWithKey {
+							owner = value
+						}
+					}
+					Expect(owner).To(Equal("app"))
+				})
+			})
+
+			Context("when the container does not exist", func() {
+				BeforeEach(func() {
+					spec.Properties[gardener.NetworkSharedWithKey] = "nonexistent"
+				})
+
+				It("returns an error without creating the container", func() {
+					_, err := gdnr.Create(spec)
+					Expect(err).To(MatchError(garden.ContainerNotFoundError{Handle: "nonexistent"}))
+					Expect(containerizer.CreateCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when the container is not running", func() {
+				BeforeEach(func() {
+					ownerInfo.Stopped = true
+				})
+
+				It("returns an error without creating the container", func() {
+					_, err := gdnr.Create(spec)
+					Expect(err).To(MatchError(ContainSubstring("container is not running")))
+					Expect(containerizer.CreateCallCount()).To(Equal(0))
+				})
+			})
+		})
+
 		It("sets the container state to created", func() {
 			_, err := gdnr.Create(garden.ContainerSpec{
 				Handle: "something",
@@ -649,6 +1239,42 @@ var _ = Describe("Gardener", func() {
 					Expect(err).To(MatchError("error"))
 				})
 			})
+
+			Context("when the container has no network of its own", func() {
+				BeforeEach(func() {
+					propertyManager.GetStub = func(handle, name string) (string, bool) {
+						if name == gardener.NetworkModeKey {
+							return "none", true
+						}
+						return "", false
+					}
+				})
+
+				It("returns an error", func() {
+					_, _, err := container.NetIn(externalPort, contianerPort)
+					Expect(err).To(MatchError("NetIn is not supported in network mode none"))
+					Expect(networker.NetInCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when the container shares the network of another container", func() {
+				BeforeEach(func() {
+					propertyManager.GetStub = func(handle, name string) (string, bool) {
+						if handle == "banana" && name == gardener.NetworkSharedWithKey {
+							return "app", true
+						}
+						return "", false
+					}
+				})
+
+				It("forwards the ports of the other container", func() {
+					_, _, err := container.NetIn(externalPort, contianerPort)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, actualHandle, _, _ := networker.NetInArgsForCall(0)
+					Expect(actualHandle).To(Equal("app"))
+				})
+			})
 		})
 
 		Describe("NetOut", func() {
@@ -758,6 +1384,176 @@ var _ = Describe("Gardener", func() {
 			containerizer.HandlesReturns([]string{}, errors.New("banana"))
 			Expect(gdnr.Start()).To(MatchError("banana"))
 		})
+
+		Context("when containers have dedicated cores", func() {
+			var cpuAllocator *fakes.FakeCPUAllocator
+
+			BeforeEach(func() {
+				cpuAllocator = new(fakes.FakeCPUAllocator)
+				gdnr.CPUAllocator = cpuAllocator
+
+				propertyManager.GetStub = func(handle, name string) (string, bool) {
+					if handle == "container2" && name == gardener.DedicatedCoresKey {
+						return "2", true
+					}
+					return "", false
+				}
+				containerizer.InfoReturns(gardener.ActualContainerSpec{CPUSet: gardener.CPUSet{CPUs: "4-5", Mems: "1"}}, nil)
+			})
+
+			It("reserves their cores", func() {
+				Expect(gdnr.Start()).To(Succeed())
+
+				Expect(cpuAllocator.ReserveCallCount()).To(Equal(1))
+				handle, cpus := cpuAllocator.ReserveArgsForCall(0)
+				Expect(handle).To(Equal("container2"))
+				Expect(cpus).To(Equal(gardener.CPUSet{CPUs: "4-5", Mems: "1"}))
+			})
+
+			Context("when the cores cannot be reserved", func() {
+				BeforeEach(func() {
+					cpuAllocator.ReserveReturns(errors.New("banana"))
+				})
+
+				It("destroys the container rather than sharing its cores, and carries on starting", func() {
+					Expect(gdnr.Start()).To(Succeed())
+
+					Expect(containerizer.DestroyCallCount()).To(Equal(1))
+					_, handle := containerizer.DestroyArgsForCall(0)
+					Expect(handle).To(Equal("container2"))
+
+					_, handles := restorer.RestoreArgsForCall(0)
+					Expect(handles).To(Equal([]string{"container1"}))
+				})
+			})
+
+			Context("when the container's cores cannot be found", func() {
+				It("destroys the container", func() {
+					containerizer.InfoReturns(gardener.ActualContainerSpec{}, errors.New("banana"))
+
+					Expect(gdnr.Start()).To(Succeed())
+					Expect(containerizer.DestroyCallCount()).To(Equal(1))
+					Expect(cpuAllocator.ReserveCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when containers have their own ranges of host IDs", func() {
+			var (
+				idAllocator *fakes.FakeIDAllocator
+				mappings    gardener.IDMappings
+			)
+
+			BeforeEach(func() {
+				mappings = gardener.IDMappings{
+					UIDMappings: rootfs_provider.MappingList{{ContainerID: 0, HostID: 100000, Size: 65536}},
+					GIDMappings: rootfs_provider.MappingList{{ContainerID: 0, HostID: 100000, Size: 65536}},
+				}
+
+				idAllocator = new(fakes.FakeIDAllocator)
+				gdnr.IDAllocator = idAllocator
+
+				containerizer.InfoStub = func(_ lager.Logger, handle string) (gardener.ActualContainerSpec, error) {
+					if handle == "container2" {
+						return gardener.ActualContainerSpec{IDMappings: mappings}, nil
+					}
+					return gardener.ActualContainerSpec{Privileged: true}, nil
+				}
+			})
+
+			It("reserves the ranges of unprivileged containers", func() {
+				Expect(gdnr.Start()).To(Succeed())
+
+				Expect(idAllocator.ReserveCallCount()).To(Equal(1))
+				handle, reserved := idAllocator.ReserveArgsForCall(0)
+				Expect(handle).To(Equal("container2"))
+				Expect(reserved).To(Equal(mappings))
+			})
+
+			Context("when a range cannot be reserved", func() {
+				BeforeEach(func() {
+					idAllocator.ReserveReturns(errors.New("banana"))
+				})
+
+				It("destroys the container rather than sharing its host IDs, and carries on starting", func() {
+					Expect(gdnr.Start()).To(Succeed())
+
+					Expect(containerizer.DestroyCallCount()).To(Equal(1))
+					_, handle := containerizer.DestroyArgsForCall(0)
+					Expect(handle).To(Equal("container2"))
+
+					_, handles := restorer.RestoreArgsForCall(0)
+					Expect(handles).To(Equal([]string{"container1"}))
+				})
+			})
+
+			Context("when a container's mappings cannot be found", func() {
+				BeforeEach(func() {
+					containerizer.InfoStub = func(_ lager.Logger, handle string) (gardener.ActualContainerSpec, error) {
+						if handle == "container1" {
+							return gardener.ActualContainerSpec{}, errors.New("banana")
+						}
+						return gardener.ActualContainerSpec{IDMappings: mappings}, nil
+					}
+				})
+
+				It("destroys the container, as it may have a range", func() {
+					Expect(gdnr.Start()).To(Succeed())
+
+					Expect(containerizer.DestroyCallCount()).To(Equal(1))
+					_, handle := containerizer.DestroyArgsForCall(0)
+					Expect(handle).To(Equal("container1"))
+				})
+			})
+		})
+
+		Context("when a container is not networked by the networker", func() {
+			BeforeEach(func() {
+				propertyManager.GetStub = func(handle, name string) (string, bool) {
+					if handle == "container1" && name == gardener.NetworkModeKey {
+						return "host", true
+					}
+					return "", false
+				}
+			})
+
+			It("does not restore its network", func() {
+				Expect(gdnr.Start()).To(Succeed())
+				_, handles := restorer.RestoreArgsForCall(0)
+				Expect(handles).To(Equal([]string{"container2"}))
+			})
+		})
+
+		Context("when a container shares the network of another container", func() {
+			BeforeEach(func() {
+				containerizer.HandlesReturns([]string{"container1", "container2", "sidecar"}, nil)
+				propertyManager.GetStub = func(handle, name string) (string, bool) {
+					if handle == "sidecar" && name == gardener.NetworkSharedWithKey {
+						return "container2", true
+					}
+					return "", false
+				}
+			})
+
+			It("does not restore its network", func() {
+				Expect(gdnr.Start()).To(Succeed())
+				_, handles := restorer.RestoreArgsForCall(0)
+				Expect(handles).To(Equal([]string{"container1", "container2"}))
+			})
+
+			Context("when the other container couldn't restore", func() {
+				BeforeEach(func() {
+					restorer.RestoreReturns([]string{"container2"})
+				})
+
+				It("blows up both containers", func() {
+					Expect(gdnr.Start()).To(Succeed())
+					Expect(containerizer.DestroyCallCount()).To(Equal(2))
+					_, handle := containerizer.DestroyArgsForCall(1)
+					Expect(handle).To(Equal("sidecar"))
+				})
+			})
+		})
 	})
 
 	Describe("listing containers", func() {
@@ -879,6 +1675,55 @@ var _ = Describe("Gardener", func() {
 			Expect(handle).To(Equal("some-handle"))
 		})
 
+		It("releases the container's dedicated cores", func() {
+			cpuAllocator := new(fakes.FakeCPUAllocator)
+			gdnr.CPUAllocator = cpuAllocator
+
+			Expect(gdnr.Destroy("some-handle")).To(Succeed())
+			Expect(cpuAllocator.ReleaseCallCount()).To(Equal(1))
+			Expect(cpuAllocator.ReleaseArgsForCall(0)).To(Equal("some-handle"))
+		})
+
+		It("releases the container's range of host IDs", func() {
+			idAllocator := new(fakes.FakeIDAllocator)
+			gdnr.IDAllocator = idAllocator
+
+			Expect(gdnr.Destroy("some-handle")).To(Succeed())
+			Expect(idAllocator.ReleaseCallCount()).To(Equal(1))
+			Expect(idAllocator.ReleaseArgsForCall(0)).To(Equal("some-handle"))
+		})
+
+		It("forgets the syscalls audited in the container", func() {
+			syscallAuditor := new(fakes.FakeSyscallAuditor)
+			gdnr.SyscallAuditor = syscallAuditor
+
+			Expect(gdnr.Destroy("some-handle")).To(Succeed())
+			Expect(syscallAuditor.ForgetCallCount()).To(Equal(1))
+			Expect(syscallAuditor.ForgetArgsForCall(0)).To(Equal("some-handle"))
+		})
+
+		Context("when other containers share the network of the container", func() {
+			BeforeEach(func() {
+				containerizer.HandlesReturns([]string{"some-handle", "sidecar"}, nil)
+				propertyManager.MatchesAllStub = func(handle string, props garden.Properties) bool {
+					return handle == "sidecar" && props[gardener.NetworkSharedWithKey] == "some-handle"
+				}
+			})
+
+			It("returns an error", func() {
+				Expect(gdnr.Destroy("some-handle")).To(MatchError(ContainSubstring("containers share its network: sidecar")))
+			})
+
+			It("does not destroy the container", func() {
+				Expect(gdnr.Destroy("some-handle")).NotTo(Succeed())
+				Expect(containerizer.DestroyCallCount()).To(Equal(0))
+			})
+
+			It("allows the dependent containers to be destroyed", func() {
+				Expect(gdnr.Destroy("sidecar")).To(Succeed())
+			})
+		})
+
 		Context("when containerizer fails to destroy the container", func() {
 			BeforeEach(func() {
 				containerizer.DestroyReturns(errors.New("containerized deletion failed"))
@@ -1080,6 +1925,101 @@ var _ = Describe("Gardener", func() {
 			Expect(handle).To(Equal("some-handle"))
 			Expect(name).To(Equal("name"))
 		})
+
+		It("does not update the block I/O limits when other properties change", func() {
+			Expect(container.SetProperty("name", "value")).To(Succeed())
+			Expect(container.RemoveProperty("name")).To(Succeed())
+			Expect(containerizer.UpdateBlockIOCallCount()).To(Equal(0))
+		})
+
+		Context("when a block I/O property is set", func() {
+			BeforeEach(func() {
+				propertyManager.AllReturns(garden.Properties{
+					gardener.BlockIOWeightKey:  "100",
+					gardener.BlockIOReadBpsKey: "8:0:1024",
+				}, nil)
+			})
+
+			It("updates the container's block I/O limits from its properties", func() {
+				Expect(container.SetProperty(gardener.BlockIOWeightKey, "500")).To(Succeed())
+
+				Expect(containerizer.UpdateBlockIOCallCount()).To(Equal(1))
+				_, handle, limits := containerizer.UpdateBlockIOArgsForCall(0)
+				Expect(handle).To(Equal("some-handle"))
+				Expect(limits).To(Equal(gardener.BlockIOLimits{
+					Weight:  500,
+					ReadBps: []gardener.DeviceRate{{Device: "8:0", Rate: 1024}},
+				}))
+
+				Expect(propertyManager.SetCallCount()).To(Equal(1))
+			})
+
+			It("does not modify the properties before they are set", func() {
+				Expect(container.SetProperty(gardener.BlockIOWeightKey, "500")).To(Succeed())
+
+				properties, err := propertyManager.All("some-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(properties[gardener.BlockIOWeightKey]).To(Equal("100"))
+			})
+
+			Context("when the value is invalid", func() {
+				It("returns an error and does not set the property", func() {
+					Expect(container.SetProperty(gardener.BlockIOWeightKey, "5")).To(MatchError("invalid value for garden.blkio.weight: 5"))
+					Expect(containerizer.UpdateBlockIOCallCount()).To(Equal(0))
+					Expect(propertyManager.SetCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when updating the limits fails", func() {
+				It("returns the error and does not set the property", func() {
+					containerizer.UpdateBlockIOReturns(errors.New("banana"))
+
+					Expect(container.SetProperty(gardener.BlockIOWeightKey, "500")).To(MatchError("banana"))
+					Expect(propertyManager.SetCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when a property is only read on creation", func() {
+			It("cannot be set", func() {
+				for _, name := range []string{
+					gardener.NetworkSharedWithKey,
+					gardener.NetworkModeKey,
+					gardener.NetworkDenyNetworksKey,
+					gardener.DedicatedCoresKey,
+					gardener.SysctlKeyPrefix + "net.core.somaxconn",
+				} {
+					Expect(container.SetProperty(name, "another-handle")).To(MatchError(fmt.Sprintf("property %s can only be set when the container is created", name)))
+				}
+
+				Expect(propertyManager.SetCallCount()).To(Equal(0))
+			})
+
+			It("cannot be removed", func() {
+				for _, name := range []string{gardener.NetworkSharedWithKey, gardener.NetworkModeKey, gardener.SecurityProfileKey} {
+					Expect(container.RemoveProperty(name)).To(MatchError(fmt.Sprintf("property %s can only be set when the container is created", name)))
+				}
+
+				Expect(propertyManager.RemoveCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when a block I/O property is removed", func() {
+			It("updates the container's block I/O limits without it", func() {
+				propertyManager.AllReturns(garden.Properties{
+					gardener.BlockIOWeightKey:  "100",
+					gardener.BlockIOReadBpsKey: "8:0:1024",
+				}, nil)
+
+				Expect(container.RemoveProperty(gardener.BlockIOReadBpsKey)).To(Succeed())
+
+				Expect(containerizer.UpdateBlockIOCallCount()).To(Equal(1))
+				_, _, limits := containerizer.UpdateBlockIOArgsForCall(0)
+				Expect(limits).To(Equal(gardener.BlockIOLimits{Weight: 100}))
+
+				Expect(propertyManager.RemoveCallCount()).To(Equal(1))
+			})
+		})
 	})
 
 	Describe("Info", func() {
@@ -1196,6 +2136,26 @@ var _ = Describe("Gardener", func() {
 			}))
 		})
 
+		It("reports the memory options in effect as properties", func() {
+			propertyManager.AllReturns(garden.Properties{
+				"spider":                     "man",
+				gardener.MemorySwappinessKey: "10",
+			}, nil)
+			swappiness := uint64(60)
+			containerizer.InfoReturns(gardener.ActualContainerSpec{
+				Memory: gardener.MemoryOptions{Reservation: 1024, Swappiness: &swappiness},
+			}, nil)
+
+			info, err := container.Info()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(info.Properties).To(Equal(garden.Properties{
+				"spider":                      "man",
+				gardener.MemoryReservationKey: "1024",
+				gardener.MemorySwappinessKey:  "60",
+			}))
+		})
+
 		Context("when the propertymanager fails to get properties", func() {
 			It("should return the error", func() {
 				propertyManager.AllReturns(garden.Properties{}, errors.New("hey-error"))
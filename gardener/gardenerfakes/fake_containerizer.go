@@ -159,6 +159,19 @@ type FakeContainerizer struct {
 		result1 gardener.ActualContainerMetrics
 		result2 error
 	}
+	UpdateBlockIOStub        func(log lager.Logger, handle string, limits gardener.BlockIOLimits) error
+	updateBlockIOMutex       sync.RWMutex
+	updateBlockIOArgsForCall []struct {
+		log    lager.Logger
+		handle string
+		limits gardener.BlockIOLimits
+	}
+	updateBlockIOReturns struct {
+		result1 error
+	}
+	updateBlockIOReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -718,6 +731,56 @@ func (fake *FakeContainerizer) MetricsReturnsOnCall(i int, result1 gardener.Actu
 	}{result1, result2}
 }
 
+func (fake *FakeContainerizer) UpdateBlockIO(log lager.Logger, handle string, limits gardener.BlockIOLimits) error {
+	fake.updateBlockIOMutex.Lock()
+	ret, specificReturn := fake.updateBlockIOReturnsOnCall[len(fake.updateBlockIOArgsForCall)]
+	fake.updateBlockIOArgsForCall = append(fake.updateBlockIOArgsForCall, struct {
+		log    lager.Logger
+		handle string
+		limits gardener.BlockIOLimits
+	}{log, handle, limits})
+	fake.recordInvocation("UpdateBlockIO", []interface{}{log, handle, limits})
+	fake.updateBlockIOMutex.Unlock()
+	if fake.UpdateBlockIOStub != nil {
+		return fake.UpdateBlockIOStub(log, handle, limits)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.updateBlockIOReturns.result1
+}
+
+func (fake *FakeContainerizer) UpdateBlockIOCallCount() int {
+	fake.updateBlockIOMutex.RLock()
+	defer fake.updateBlockIOMutex.RUnlock()
+	return len(fake.updateBlockIOArgsForCall)
+}
+
+func (fake *FakeContainerizer) UpdateBlockIOArgsForCall(i int) (lager.Logger, string, gardener.BlockIOLimits) {
+	fake.updateBlockIOMutex.RLock()
+	defer fake.updateBlockIOMutex.RUnlock()
+	return fake.updateBlockIOArgsForCall[i].log, fake.updateBlockIOArgsForCall[i].handle, fake.updateBlockIOArgsForCall[i].limits
+}
+
+func (fake *FakeContainerizer) UpdateBlockIOReturns(result1 error) {
+	fake.UpdateBlockIOStub = nil
+	fake.updateBlockIOReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeContainerizer) UpdateBlockIOReturnsOnCall(i int, result1 error) {
+	fake.UpdateBlockIOStub = nil
+	if fake.updateBlockIOReturnsOnCall == nil {
+		fake.updateBlockIOReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateBlockIOReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeContainerizer) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -743,6 +806,8 @@ func (fake *FakeContainerizer) Invocations() map[string][][]interface{} {
 	defer fake.infoMutex.RUnlock()
 	fake.metricsMutex.RLock()
 	defer fake.metricsMutex.RUnlock()
+	fake.updateBlockIOMutex.RLock()
+	defer fake.updateBlockIOMutex.RUnlock()
 	return fake.invocations
 }
 
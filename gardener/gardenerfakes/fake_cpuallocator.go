@@ -0,0 +1,261 @@
+// This file was generated by counterfeiter
+package gardenerfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/gardener"
+)
+
+type FakeCPUAllocator struct {
+	AllocateStub        func(handle string, cores int) (gardener.CPUSet, error)
+	allocateMutex       sync.RWMutex
+	allocateArgsForCall []struct {
+		handle string
+		cores  int
+	}
+	allocateReturns struct {
+		result1 gardener.CPUSet
+		result2 error
+	}
+	allocateReturnsOnCall map[int]struct {
+		result1 gardener.CPUSet
+		result2 error
+	}
+	ReserveStub        func(handle string, cpus gardener.CPUSet) error
+	reserveMutex       sync.RWMutex
+	reserveArgsForCall []struct {
+		handle string
+		cpus   gardener.CPUSet
+	}
+	reserveReturns struct {
+		result1 error
+	}
+	reserveReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ReleaseStub        func(handle string)
+	releaseMutex       sync.RWMutex
+	releaseArgsForCall []struct {
+		handle string
+	}
+	OverlapsStub        func(cpus string) (bool, error)
+	overlapsMutex       sync.RWMutex
+	overlapsArgsForCall []struct {
+		cpus string
+	}
+	overlapsReturns struct {
+		result1 bool
+		result2 error
+	}
+	overlapsReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCPUAllocator) Allocate(handle string, cores int) (gardener.CPUSet, error) {
+	fake.allocateMutex.Lock()
+	ret, specificReturn := fake.allocateReturnsOnCall[len(fake.allocateArgsForCall)]
+	fake.allocateArgsForCall = append(fake.allocateArgsForCall, struct {
+		handle string
+		cores  int
+	}{handle, cores})
+	fake.recordInvocation("Allocate", []interface{}{handle, cores})
+	fake.allocateMutex.Unlock()
+	if fake.AllocateStub != nil {
+		return fake.AllocateStub(handle, cores)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.allocateReturns.result1, fake.allocateReturns.result2
+}
+
+func (fake *FakeCPUAllocator) AllocateCallCount() int {
+	fake.allocateMutex.RLock()
+	defer fake.allocateMutex.RUnlock()
+	return len(fake.allocateArgsForCall)
+}
+
+func (fake *FakeCPUAllocator) AllocateArgsForCall(i int) (string, int) {
+	fake.allocateMutex.RLock()
+	defer fake.allocateMutex.RUnlock()
+	return fake.allocateArgsForCall[i].handle, fake.allocateArgsForCall[i].cores
+}
+
+func (fake *FakeCPUAllocator) AllocateReturns(result1 gardener.CPUSet, result2 error) {
+	fake.AllocateStub = nil
+	fake.allocateReturns = struct {
+		result1 gardener.CPUSet
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCPUAllocator) AllocateReturnsOnCall(i int, result1 gardener.CPUSet, result2 error) {
+	fake.AllocateStub = nil
+	if fake.allocateReturnsOnCall == nil {
+		fake.allocateReturnsOnCall = make(map[int]struct {
+			result1 gardener.CPUSet
+			result2 error
+		})
+	}
+	fake.allocateReturnsOnCall[i] = struct {
+		result1 gardener.CPUSet
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCPUAllocator) Reserve(handle string, cpus gardener.CPUSet) error {
+	fake.reserveMutex.Lock()
+	ret, specificReturn := fake.reserveReturnsOnCall[len(fake.reserveArgsForCall)]
+	fake.reserveArgsForCall = append(fake.reserveArgsForCall, struct {
+		handle string
+		cpus   gardener.CPUSet
+	}{handle, cpus})
+	fake.recordInvocation("Reserve", []interface{}{handle, cpus})
+	fake.reserveMutex.Unlock()
+	if fake.ReserveStub != nil {
+		return fake.ReserveStub(handle, cpus)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.reserveReturns.result1
+}
+
+func (fake *FakeCPUAllocator) ReserveCallCount() int {
+	fake.reserveMutex.RLock()
+	defer fake.reserveMutex.RUnlock()
+	return len(fake.reserveArgsForCall)
+}
+
+func (fake *FakeCPUAllocator) ReserveArgsForCall(i int) (string, gardener.CPUSet) {
+	fake.reserveMutex.RLock()
+	defer fake.reserveMutex.RUnlock()
+	return fake.reserveArgsForCall[i].handle, fake.reserveArgsForCall[i].cpus
+}
+
+func (fake *FakeCPUAllocator) ReserveReturns(result1 error) {
+	fake.ReserveStub = nil
+	fake.reserveReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCPUAllocator) ReserveReturnsOnCall(i int, result1 error) {
+	fake.ReserveStub = nil
+	if fake.reserveReturnsOnCall == nil {
+		fake.reserveReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.reserveReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCPUAllocator) Release(handle string) {
+	fake.releaseMutex.Lock()
+	fake.releaseArgsForCall = append(fake.releaseArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("Release", []interface{}{handle})
+	fake.releaseMutex.Unlock()
+	if fake.ReleaseStub != nil {
+		fake.ReleaseStub(handle)
+	}
+}
+
+func (fake *FakeCPUAllocator) ReleaseCallCount() int {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return len(fake.releaseArgsForCall)
+}
+
+func (fake *FakeCPUAllocator) ReleaseArgsForCall(i int) string {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return fake.releaseArgsForCall[i].handle
+}
+
+func (fake *FakeCPUAllocator) Overlaps(cpus string) (bool, error) {
+	fake.overlapsMutex.Lock()
+	ret, specificReturn := fake.overlapsReturnsOnCall[len(fake.overlapsArgsForCall)]
+	fake.overlapsArgsForCall = append(fake.overlapsArgsForCall, struct {
+		cpus string
+	}{cpus})
+	fake.recordInvocation("Overlaps", []interface{}{cpus})
+	fake.overlapsMutex.Unlock()
+	if fake.OverlapsStub != nil {
+		return fake.OverlapsStub(cpus)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.overlapsReturns.result1, fake.overlapsReturns.result2
+}
+
+func (fake *FakeCPUAllocator) OverlapsCallCount() int {
+	fake.overlapsMutex.RLock()
+	defer fake.overlapsMutex.RUnlock()
+	return len(fake.overlapsArgsForCall)
+}
+
+func (fake *FakeCPUAllocator) OverlapsArgsForCall(i int) string {
+	fake.overlapsMutex.RLock()
+	defer fake.overlapsMutex.RUnlock()
+	return fake.overlapsArgsForCall[i].cpus
+}
+
+func (fake *FakeCPUAllocator) OverlapsReturns(result1 bool, result2 error) {
+	fake.OverlapsStub = nil
+	fake.overlapsReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCPUAllocator) OverlapsReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.OverlapsStub = nil
+	if fake.overlapsReturnsOnCall == nil {
+		fake.overlapsReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.overlapsReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCPUAllocator) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.allocateMutex.RLock()
+	defer fake.allocateMutex.RUnlock()
+	fake.reserveMutex.RLock()
+	defer fake.reserveMutex.RUnlock()
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	fake.overlapsMutex.RLock()
+	defer fake.overlapsMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeCPUAllocator) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ gardener.CPUAllocator = new(FakeCPUAllocator)
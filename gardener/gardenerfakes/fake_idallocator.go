@@ -0,0 +1,193 @@
+// This file was generated by counterfeiter
+package gardenerfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/gardener"
+)
+
+type FakeIDAllocator struct {
+	AllocateStub        func(handle string) (gardener.IDMappings, error)
+	allocateMutex       sync.RWMutex
+	allocateArgsForCall []struct {
+		handle string
+	}
+	allocateReturns struct {
+		result1 gardener.IDMappings
+		result2 error
+	}
+	allocateReturnsOnCall map[int]struct {
+		result1 gardener.IDMappings
+		result2 error
+	}
+	ReserveStub        func(handle string, mappings gardener.IDMappings) error
+	reserveMutex       sync.RWMutex
+	reserveArgsForCall []struct {
+		handle   string
+		mappings gardener.IDMappings
+	}
+	reserveReturns struct {
+		result1 error
+	}
+	reserveReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ReleaseStub        func(handle string)
+	releaseMutex       sync.RWMutex
+	releaseArgsForCall []struct {
+		handle string
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeIDAllocator) Allocate(handle string) (gardener.IDMappings, error) {
+	fake.allocateMutex.Lock()
+	ret, specificReturn := fake.allocateReturnsOnCall[len(fake.allocateArgsForCall)]
+	fake.allocateArgsForCall = append(fake.allocateArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("Allocate", []interface{}{handle})
+	fake.allocateMutex.Unlock()
+	if fake.AllocateStub != nil {
+		return fake.AllocateStub(handle)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.allocateReturns.result1, fake.allocateReturns.result2
+}
+
+func (fake *FakeIDAllocator) AllocateCallCount() int {
+	fake.allocateMutex.RLock()
+	defer fake.allocateMutex.RUnlock()
+	return len(fake.allocateArgsForCall)
+}
+
+func (fake *FakeIDAllocator) AllocateArgsForCall(i int) string {
+	fake.allocateMutex.RLock()
+	defer fake.allocateMutex.RUnlock()
+	return fake.allocateArgsForCall[i].handle
+}
+
+func (fake *FakeIDAllocator) AllocateReturns(result1 gardener.IDMappings, result2 error) {
+	fake.AllocateStub = nil
+	fake.allocateReturns = struct {
+		result1 gardener.IDMappings
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIDAllocator) AllocateReturnsOnCall(i int, result1 gardener.IDMappings, result2 error) {
+	fake.AllocateStub = nil
+	if fake.allocateReturnsOnCall == nil {
+		fake.allocateReturnsOnCall = make(map[int]struct {
+			result1 gardener.IDMappings
+			result2 error
+		})
+	}
+	fake.allocateReturnsOnCall[i] = struct {
+		result1 gardener.IDMappings
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIDAllocator) Reserve(handle string, mappings gardener.IDMappings) error {
+	fake.reserveMutex.Lock()
+	ret, specificReturn := fake.reserveReturnsOnCall[len(fake.reserveArgsForCall)]
+	fake.reserveArgsForCall = append(fake.reserveArgsForCall, struct {
+		handle   string
+		mappings gardener.IDMappings
+	}{handle, mappings})
+	fake.recordInvocation("Reserve", []interface{}{handle, mappings})
+	fake.reserveMutex.Unlock()
+	if fake.ReserveStub != nil {
+		return fake.ReserveStub(handle, mappings)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.reserveReturns.result1
+}
+
+func (fake *FakeIDAllocator) ReserveCallCount() int {
+	fake.reserveMutex.RLock()
+	defer fake.reserveMutex.RUnlock()
+	return len(fake.reserveArgsForCall)
+}
+
+func (fake *FakeIDAllocator) ReserveArgsForCall(i int) (string, gardener.IDMappings) {
+	fake.reserveMutex.RLock()
+	defer fake.reserveMutex.RUnlock()
+	return fake.reserveArgsForCall[i].handle, fake.reserveArgsForCall[i].mappings
+}
+
+func (fake *FakeIDAllocator) ReserveReturns(result1 error) {
+	fake.ReserveStub = nil
+	fake.reserveReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIDAllocator) ReserveReturnsOnCall(i int, result1 error) {
+	fake.ReserveStub = nil
+	if fake.reserveReturnsOnCall == nil {
+		fake.reserveReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.reserveReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIDAllocator) Release(handle string) {
+	fake.releaseMutex.Lock()
+	fake.releaseArgsForCall = append(fake.releaseArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("Release", []interface{}{handle})
+	fake.releaseMutex.Unlock()
+	if fake.ReleaseStub != nil {
+		fake.ReleaseStub(handle)
+	}
+}
+
+func (fake *FakeIDAllocator) ReleaseCallCount() int {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return len(fake.releaseArgsForCall)
+}
+
+func (fake *FakeIDAllocator) ReleaseArgsForCall(i int) string {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return fake.releaseArgsForCall[i].handle
+}
+
+func (fake *FakeIDAllocator) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.allocateMutex.RLock()
+	defer fake.allocateMutex.RUnlock()
+	fake.reserveMutex.RLock()
+	defer fake.reserveMutex.RUnlock()
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeIDAllocator) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ gardener.IDAllocator = new(FakeIDAllocator)
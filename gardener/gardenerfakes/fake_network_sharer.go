@@ -0,0 +1,101 @@
+// This file was generated by counterfeiter
+package gardenerfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/lager"
+)
+
+type FakeNetworkSharer struct {
+	ShareNetworkStub        func(log lager.Logger, owner string, handle string, pid int) error
+	shareNetworkMutex       sync.RWMutex
+	shareNetworkArgsForCall []struct {
+		log    lager.Logger
+		owner  string
+		handle string
+		pid    int
+	}
+	shareNetworkReturns struct {
+		result1 error
+	}
+	shareNetworkReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeNetworkSharer) ShareNetwork(log lager.Logger, owner string, handle string, pid int) error {
+	fake.shareNetworkMutex.Lock()
+	ret, specificReturn := fake.shareNetworkReturnsOnCall[len(fake.shareNetworkArgsForCall)]
+	fake.shareNetworkArgsForCall = append(fake.shareNetworkArgsForCall, struct {
+		log    lager.Logger
+		owner  string
+		handle string
+		pid    int
+	}{log, owner, handle, pid})
+	fake.recordInvocation("ShareNetwork", []interface{}{log, owner, handle, pid})
+	fake.shareNetworkMutex.Unlock()
+	if fake.ShareNetworkStub != nil {
+		return fake.ShareNetworkStub(log, owner, handle, pid)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.shareNetworkReturns.result1
+}
+
+func (fake *FakeNetworkSharer) ShareNetworkCallCount() int {
+	fake.shareNetworkMutex.RLock()
+	defer fake.shareNetworkMutex.RUnlock()
+	return len(fake.shareNetworkArgsForCall)
+}
+
+func (fake *FakeNetworkSharer) ShareNetworkArgsForCall(i int) (lager.Logger, string, string, int) {
+	fake.shareNetworkMutex.RLock()
+	defer fake.shareNetworkMutex.RUnlock()
+	return fake.shareNetworkArgsForCall[i].log, fake.shareNetworkArgsForCall[i].owner, fake.shareNetworkArgsForCall[i].handle, fake.shareNetworkArgsForCall[i].pid
+}
+
+func (fake *FakeNetworkSharer) ShareNetworkReturns(result1 error) {
+	fake.ShareNetworkStub = nil
+	fake.shareNetworkReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkSharer) ShareNetworkReturnsOnCall(i int, result1 error) {
+	fake.ShareNetworkStub = nil
+	if fake.shareNetworkReturnsOnCall == nil {
+		fake.shareNetworkReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.shareNetworkReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkSharer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.shareNetworkMutex.RLock()
+	defer fake.shareNetworkMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeNetworkSharer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ gardener.NetworkSharer = new(FakeNetworkSharer)
@@ -0,0 +1,64 @@
+// This file was generated by counterfeiter
+package gardenerfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/gardener"
+)
+
+type FakeSyscallAuditor struct {
+	ForgetStub        func(handle string)
+	forgetMutex       sync.RWMutex
+	forgetArgsForCall []struct {
+		handle string
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeSyscallAuditor) Forget(handle string) {
+	fake.forgetMutex.Lock()
+	fake.forgetArgsForCall = append(fake.forgetArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("Forget", []interface{}{handle})
+	fake.forgetMutex.Unlock()
+	if fake.ForgetStub != nil {
+		fake.ForgetStub(handle)
+	}
+}
+
+func (fake *FakeSyscallAuditor) ForgetCallCount() int {
+	fake.forgetMutex.RLock()
+	defer fake.forgetMutex.RUnlock()
+	return len(fake.forgetArgsForCall)
+}
+
+func (fake *FakeSyscallAuditor) ForgetArgsForCall(i int) string {
+	fake.forgetMutex.RLock()
+	defer fake.forgetMutex.RUnlock()
+	return fake.forgetArgsForCall[i].handle
+}
+
+func (fake *FakeSyscallAuditor) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.forgetMutex.RLock()
+	defer fake.forgetMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeSyscallAuditor) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ gardener.SyscallAuditor = new(FakeSyscallAuditor)
@@ -0,0 +1,69 @@
+package gardener
+
+import (
+	"code.cloudfoundry.org/garden-shed/rootfs_provider"
+	"code.cloudfoundry.org/lager"
+)
+
+// IDMappings map a container's UIDs and GIDs to host IDs in its user
+// namespace
+type IDMappings struct {
+	UIDMappings rootfs_provider.MappingList
+	GIDMappings rootfs_provider.MappingList
+}
+
+// IDAllocator gives each unprivileged container its own range of host UIDs
+// and GIDs, so that containers do not share host IDs
+type IDAllocator interface {
+	// Allocate returns the mappings of a free range of host IDs to the
+	// container
+	Allocate(handle string) (IDMappings, error)
+
+	// Reserve marks the range of an existing container's mappings as in use,
+	// e.g. after a restart
+	Reserve(handle string, mappings IDMappings) error
+
+	// Release returns the container's range, if any, to the pool
+	Release(handle string)
+}
+
+// idMappings returns the ID mappings of a new container, or none if it uses
+// the server's mappings
+func (g *Gardener) idMappings(handle string, privileged bool) (IDMappings, error) {
+	if privileged || g.IDAllocator == nil {
+		return IDMappings{}, nil
+	}
+
+	return g.IDAllocator.Allocate(handle)
+}
+
+// reserveIDMappings marks the ID ranges of existing containers as in use, so
+// that they are not allocated to new containers. It returns the containers
+// whose ranges could not be reserved, which would otherwise share host IDs
+// with new containers.
+func (g *Gardener) reserveIDMappings(log lager.Logger, handles []string) []string {
+	if g.IDAllocator == nil {
+		return nil
+	}
+
+	var unreserved []string
+	for _, handle := range handles {
+		info, err := g.Containerizer.Info(log, handle)
+		if err != nil {
+			log.Error("reserve-id-mappings-failed", err, lager.Data{"handle": handle})
+			unreserved = append(unreserved, handle)
+			continue
+		}
+
+		if len(info.IDMappings.UIDMappings) == 0 {
+			continue
+		}
+
+		if err := g.IDAllocator.Reserve(handle, info.IDMappings); err != nil {
+			log.Error("reserve-id-mappings-failed", err, lager.Data{"handle": handle})
+			unreserved = append(unreserved, handle)
+		}
+	}
+
+	return unreserved
+}
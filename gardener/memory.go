@@ -0,0 +1,123 @@
+package gardener
+
+import (
+	"fmt"
+	"strconv"
+
+	"code.cloudfoundry.org/garden"
+)
+
+const (
+	// MemoryReservationKey is the property setting a soft limit, in bytes,
+	// which a container's memory is reclaimed down to when the host is short of
+	// memory
+	MemoryReservationKey = "garden.memory.reservation"
+
+	// MemorySwapKey is the property setting how much swap, in bytes, a
+	// container may use in addition to its memory limit
+	MemorySwapKey = "garden.memory.swap"
+
+	// MemorySwappinessKey is the property setting how readily the kernel swaps
+	// out a container's memory, from 0 to 100
+	MemorySwappinessKey = "garden.memory.swappiness"
+
+	// KernelMemoryKey is the property limiting a container's kernel memory, in
+	// bytes
+	KernelMemoryKey = "garden.memory.kernel"
+
+	// OOMScoreAdjKey is the property adjusting how likely a container's
+	// processes are to be killed when the host runs out of memory, from -1000
+	// to 1000. Only privileged containers may lower it below 0.
+	OOMScoreAdjKey = "garden.memory.oom-score-adj"
+)
+
+// MemoryOptions tune a container's memory beyond its memory limit. Nil and
+// zero values leave the kernel's defaults in place.
+type MemoryOptions struct {
+	Reservation uint64
+	Swap        *uint64
+	Swappiness  *uint64
+	Kernel      uint64
+	OOMScoreAdj *int
+}
+
+// ParseMemoryOptions returns the memory options set by a container's
+// properties
+func ParseMemoryOptions(properties garden.Properties) (MemoryOptions, error) {
+	var options MemoryOptions
+	var err error
+
+	if options.Reservation, err = parseBytes(properties, MemoryReservationKey); err != nil {
+		return MemoryOptions{}, err
+	}
+
+	if options.Kernel, err = parseBytes(properties, KernelMemoryKey); err != nil {
+		return MemoryOptions{}, err
+	}
+
+	if value, ok := properties[MemorySwapKey]; ok {
+		swap, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return MemoryOptions{}, invalidValue(MemorySwapKey, value)
+		}
+		options.Swap = &swap
+	}
+
+	if value, ok := properties[MemorySwappinessKey]; ok {
+		swappiness, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || swappiness > 100 {
+			return MemoryOptions{}, invalidValue(MemorySwappinessKey, value)
+		}
+		options.Swappiness = &swappiness
+	}
+
+	if value, ok := properties[OOMScoreAdjKey]; ok {
+		score, err := strconv.Atoi(value)
+		if err != nil || score < -1000 || score > 1000 {
+			return MemoryOptions{}, invalidValue(OOMScoreAdjKey, value)
+		}
+		options.OOMScoreAdj = &score
+	}
+
+	return options, nil
+}
+
+// Properties returns the properties which set the memory options
+func (o MemoryOptions) Properties() garden.Properties {
+	properties := garden.Properties{}
+	if o.Reservation != 0 {
+		properties[MemoryReservationKey] = strconv.FormatUint(o.Reservation, 10)
+	}
+	if o.Swap != nil {
+		properties[MemorySwapKey] = strconv.FormatUint(*o.Swap, 10)
+	}
+	if o.Swappiness != nil {
+		properties[MemorySwappinessKey] = strconv.FormatUint(*o.Swappiness, 10)
+	}
+	if o.Kernel != 0 {
+		properties[KernelMemoryKey] = strconv.FormatUint(o.Kernel, 10)
+	}
+	if o.OOMScoreAdj != nil {
+		properties[OOMScoreAdjKey] = strconv.Itoa(*o.OOMScoreAdj)
+	}
+
+	return properties
+}
+
+func parseBytes(properties garden.Properties, key string) (uint64, error) {
+	value, ok := properties[key]
+	if !ok {
+		return 0, nil
+	}
+
+	bytes, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, invalidValue(key, value)
+	}
+
+	return bytes, nil
+}
+
+func invalidValue(key, value string) error {
+	return fmt.Errorf("invalid value for %s: %s", key, value)
+}
@@ -0,0 +1,60 @@
+package gardener_test
+
+import (
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseMemoryOptions", func() {
+	var properties garden.Properties
+
+	BeforeEach(func() {
+		properties = garden.Properties{
+			gardener.MemoryReservationKey: "1048576",
+			gardener.MemorySwapKey:        "0",
+			gardener.MemorySwappinessKey:  "60",
+			gardener.KernelMemoryKey:      "2097152",
+			gardener.OOMScoreAdjKey:       "-500",
+			"some-other-property":         "banana",
+		}
+	})
+
+	It("parses the memory properties", func() {
+		options, err := gardener.ParseMemoryOptions(properties)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(options.Reservation).To(BeEquivalentTo(1048576))
+		Expect(*options.Swap).To(BeEquivalentTo(0))
+		Expect(*options.Swappiness).To(BeEquivalentTo(60))
+		Expect(options.Kernel).To(BeEquivalentTo(2097152))
+		Expect(*options.OOMScoreAdj).To(Equal(-500))
+	})
+
+	It("round-trips with Properties", func() {
+		options, err := gardener.ParseMemoryOptions(properties)
+		Expect(err).NotTo(HaveOccurred())
+
+		delete(properties, "some-other-property")
+		Expect(options.Properties()).To(Equal(properties))
+	})
+
+	It("returns no options when no properties are set", func() {
+		Expect(gardener.ParseMemoryOptions(garden.Properties{})).To(Equal(gardener.MemoryOptions{}))
+	})
+
+	table.DescribeTable("invalid values",
+		func(key, value string) {
+			_, err := gardener.ParseMemoryOptions(garden.Properties{key: value})
+			Expect(err).To(MatchError("invalid value for " + key + ": " + value))
+		},
+		table.Entry("a reservation which is not a number", gardener.MemoryReservationKey, "lots"),
+		table.Entry("a negative swap", gardener.MemorySwapKey, "-1"),
+		table.Entry("a swappiness above 100", gardener.MemorySwappinessKey, "101"),
+		table.Entry("a kernel memory which is not a number", gardener.KernelMemoryKey, ""),
+		table.Entry("an oom score adjustment below -1000", gardener.OOMScoreAdjKey, "-1001"),
+		table.Entry("an oom score adjustment above 1000", gardener.OOMScoreAdjKey, "1001"),
+	)
+})
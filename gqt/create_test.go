@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
 	"code.cloudfoundry.org/guardian/gqt/runner"
 
 	. "code.cloudfoundry.org/guardian/matchers"
@@ -403,6 +404,27 @@ var _ = Describe("Creating a Container", func() {
 			Expect(err).NotTo(HaveOccurred())
 			checkCPUSharesInContainer(container, client.Pid, 2)
 		})
+
+		It("pins the container to the CPUs in the garden.cpuset.cpus property", func() {
+			container, err := client.Create(garden.ContainerSpec{
+				Properties: garden.Properties{gardener.CPUSetCPUsKey: "0"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			cpuset := readFileContent(fmt.Sprintf("/proc/%d/cpuset", client.Pid))
+			cpuset = strings.TrimLeft(cpuset, "/")
+
+			cpusPath := fmt.Sprintf("%s/cgroups-%d/cpuset/%s/%s/cpuset.cpus", client.Tmpdir,
+				GinkgoParallelNode(), cpuset, container.Handle())
+			Expect(readFileContent(cpusPath)).To(Equal("0"))
+		})
+
+		It("refuses dedicated cores when the server has none to dedicate", func() {
+			_, err := client.Create(garden.ContainerSpec{
+				Properties: garden.Properties{gardener.DedicatedCoresKey: "1"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("dedicated cores are not available")))
+		})
 	})
 
 	Context("when running with an external network plugin", func() {
@@ -0,0 +1,75 @@
+package gqt_test
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gqt/runner"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("gdn explain", func() {
+	var (
+		client       *runner.RunningGarden
+		container    garden.Container
+		debugAddress string
+	)
+
+	BeforeEach(func() {
+		debugPort := 9200 + GinkgoParallelNode()
+		debugAddress = fmt.Sprintf("127.0.0.1:%d", debugPort)
+
+		client = startGarden(
+			"--deny-network", "8.8.8.0/24",
+			"--debug-bind-ip", "127.0.0.1",
+			"--debug-bind-port", fmt.Sprintf("%d", debugPort),
+		)
+
+		var err error
+		container, err = client.Create(garden.ContainerSpec{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(container.NetOut(garden.NetOutRule{
+			Protocol: garden.ProtocolUDP,
+			Networks: []garden.IPRange{garden.IPRangeFromIP(net.ParseIP("8.8.8.8"))},
+			Ports:    []garden.PortRange{garden.PortRangeFromPort(53)},
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(client.DestroyAndStop()).To(Succeed())
+	})
+
+	explain := func(args ...string) *gexec.Session {
+		args = append([]string{"explain", "--debug-address", debugAddress}, args...)
+		session, err := gexec.Start(exec.Command(gardenBin, args...), GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		return session
+	}
+
+	It("explains traffic allowed by a NetOut rule", func() {
+		session := explain("--handle", container.Handle(), "--destination", "8.8.8.8", "--port", "53", "--protocol", "udp")
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session).To(gbytes.Say("allowed: true"))
+		Expect(session).To(gbytes.Say("reason: allowed by a NetOut rule"))
+	})
+
+	It("explains traffic denied by a global deny network", func() {
+		session := explain("--handle", container.Handle(), "--destination", "8.8.8.9", "--port", "53", "--protocol", "udp")
+		Eventually(session).Should(gexec.Exit(0))
+		Expect(session).To(gbytes.Say("allowed: false"))
+		Expect(session).To(gbytes.Say("reason: denied by a global deny network"))
+	})
+
+	It("fails for an unknown container", func() {
+		session := explain("--handle", "not-a-container", "--destination", "8.8.8.8")
+		Eventually(session).Should(gexec.Exit(1))
+		Expect(session.Err).To(gbytes.Say("explaining"))
+	})
+})
@@ -114,6 +114,40 @@ var _ = Describe("Image Plugin", func() {
 				Expect(string(whoamiBytes)).To(ContainSubstring("0 - 0"))
 			})
 
+			Context("when containers are given their own ranges of host IDs", func() {
+				BeforeEach(func() {
+					args = append(args,
+						"--uid-map-start", "1000000",
+						"--uid-map-length", "262144",
+						"--gid-map-start", "2000000",
+						"--gid-map-length", "262144",
+						"--per-container-id-range-size", "65536",
+					)
+				})
+
+				It("passes the container's mappings to the plugin", func() {
+					pluginArgsBytes, err := ioutil.ReadFile(filepath.Join(tmpDir, "args"))
+					Expect(err).ToNot(HaveOccurred())
+
+					pluginArgs := strings.Split(string(pluginArgsBytes), " ")
+					Expect(pluginArgs).To(ContainElement("0:1000000:65536"))
+					Expect(pluginArgs).To(ContainElement("0:2000000:65536"))
+				})
+
+				It("gives another container a different range", func() {
+					otherContainer, err := client.Create(garden.ContainerSpec{})
+					Expect(err).NotTo(HaveOccurred())
+					defer client.Destroy(otherContainer.Handle())
+
+					pluginArgsBytes, err := ioutil.ReadFile(filepath.Join(tmpDir, "args"))
+					Expect(err).ToNot(HaveOccurred())
+
+					pluginArgs := strings.Split(string(pluginArgsBytes), " ")
+					Expect(pluginArgs).To(ContainElement("0:1065536:65536"))
+					Expect(pluginArgs).To(ContainElement("0:2065536:65536"))
+				})
+			})
+
 			Context("when there are env vars", func() {
 				BeforeEach(func() {
 					customImageJsonFile, err := ioutil.TempFile("", "")
@@ -1,9 +1,11 @@
 package gqt_test
 
 import (
+	"fmt"
 	"path"
 
 	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
 	"code.cloudfoundry.org/guardian/gqt/runner"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -71,6 +73,18 @@ var _ = Describe("Info", func() {
 		Expect(portMapping.HostPort).To(Equal(hostPort))
 		Expect(portMapping.ContainerPort).To(Equal(containerPort))
 	})
+
+	It("records the external IP of each port mapping in the properties", func() {
+		hostPort, containerPort, err := container.NetIn(0, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		info, err := container.Info()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(info.Properties[gardener.MappedPortsKey]).To(MatchJSON(fmt.Sprintf(
+			`[{"HostPort":%d,"ContainerPort":%d,"HostIP":%q}]`, hostPort, containerPort, info.ExternalIP,
+		)))
+	})
 })
 
 var _ = Describe("BulkInfo", func() {
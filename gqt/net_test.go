@@ -7,10 +7,13 @@ import (
 	"io"
 	"io/ioutil"
 	"net"
+	"net/http"
+	"os"
 	"os/exec"
 	"path"
 	"regexp"
 	"strings"
+	"syscall"
 
 	"code.cloudfoundry.org/garden"
 	"code.cloudfoundry.org/guardian/gardener"
@@ -41,6 +44,7 @@ var _ = Describe("Networking", func() {
 		args = []string{}
 		containerNetwork = fmt.Sprintf("192.168.%d.0/24", 12+GinkgoParallelNode())
 		containerSpec = garden.ContainerSpec{}
+		extraProperties = garden.Properties{}
 
 		var ips []net.IP
 		Eventually(func() error {
@@ -81,6 +85,12 @@ var _ = Describe("Networking", func() {
 		Expect(buffer).To(gbytes.Say("lo"))
 	})
 
+	It("labels the host's end of the container's veth pair with the container's handle", func() {
+		out, err := exec.Command("ip", "link", "show", hostIfName(container)).CombinedOutput()
+		Expect(err).NotTo(HaveOccurred(), string(out))
+		Expect(string(out)).To(ContainSubstring("alias " + container.Handle()))
+	})
+
 	It("should have a (dynamically assigned) IP address", func() {
 		buffer := gbytes.NewBuffer()
 		proc, err := container.Run(
@@ -273,6 +283,44 @@ var _ = Describe("Networking", func() {
 			Eventually(func() *gexec.Session { return sendRequest(externalIP, actualHostPort).Wait() }).
 				Should(gbytes.Say(fmt.Sprintf("%d", actualContainerPort)))
 		})
+
+		It("allows the container to reach its own mapped port via the external IP", func() {
+			hostPort, containerPort, err := container.NetIn(0, 0)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(listenInContainer(container, containerPort)).To(Succeed())
+
+			externalIP := externalIP(container)
+
+			Eventually(func() error { return checkConnection(container, externalIP, int(hostPort)) }).
+				Should(Succeed())
+		})
+
+		Context("when another container maps a port", func() {
+			var otherContainer garden.Container
+
+			JustBeforeEach(func() {
+				var err error
+				otherContainer, err = client.Create(garden.ContainerSpec{
+					Network: containerNetwork,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				Expect(client.Destroy(otherContainer.Handle())).To(Succeed())
+			})
+
+			It("is reachable from the first container via the external IP", func() {
+				hostPort, containerPort, err := otherContainer.NetIn(0, 0)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(listenInContainer(otherContainer, containerPort)).To(Succeed())
+
+				externalIP := externalIP(otherContainer)
+
+				Eventually(func() error { return checkConnection(container, externalIP, int(hostPort)) }).
+					Should(Succeed())
+			})
+		})
 	})
 
 	Describe("--deny-network flag", func() {
@@ -300,6 +348,126 @@ var _ = Describe("Networking", func() {
 		})
 	})
 
+	Describe("reloading the global policy", func() {
+		var policyFile string
+
+		BeforeEach(func() {
+			dir, err := ioutil.TempDir("", "global-policy")
+			Expect(err).NotTo(HaveOccurred())
+			policyFile = path.Join(dir, "policy.json")
+			Expect(ioutil.WriteFile(policyFile, []byte(`{"deny_networks": ["8.8.8.0/24"]}`), 0600)).To(Succeed())
+
+			args = append(args, "--network-policy-file", policyFile)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(path.Dir(policyFile))).To(Succeed())
+		})
+
+		It("applies the policy file at startup", func() {
+			Expect(checkConnection(container, "8.8.8.8", 53)).To(MatchError("Request failed. Process exited with code 1"))
+			Expect(checkConnection(container, "8.8.4.4", 53)).To(Succeed())
+		})
+
+		It("applies the policy file again on SIGHUP", func() {
+			Expect(ioutil.WriteFile(policyFile, []byte(`{"deny_networks": ["8.8.4.0/24"]}`), 0600)).To(Succeed())
+			Expect(syscall.Kill(client.Pid, syscall.SIGHUP)).To(Succeed())
+
+			Eventually(func() error { return checkConnection(container, "8.8.4.4", 53) }, "10s").Should(HaveOccurred())
+			Expect(checkConnection(container, "8.8.8.8", 53)).To(Succeed())
+		})
+
+		Context("when the debug server is enabled", func() {
+			var debugAddress string
+
+			BeforeEach(func() {
+				debugPort := 9300 + GinkgoParallelNode()
+				debugAddress = fmt.Sprintf("127.0.0.1:%d", debugPort)
+				args = append(args, "--debug-bind-ip", "127.0.0.1", "--debug-bind-port", fmt.Sprintf("%d", debugPort))
+			})
+
+			It("applies a policy PUT to the debug server", func() {
+				req, err := http.NewRequest("PUT", "http://"+debugAddress+"/firewall-policy", strings.NewReader(`{"allow_networks": ["8.8.8.8/32"], "deny_networks": ["8.8.0.0/16"]}`))
+				Expect(err).NotTo(HaveOccurred())
+
+				resp, err := http.DefaultClient.Do(req)
+				Expect(err).NotTo(HaveOccurred())
+				resp.Body.Close()
+				Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+
+				Expect(checkConnection(container, "8.8.8.8", 53)).To(Succeed())
+				Expect(checkConnection(container, "8.8.4.4", 53)).To(MatchError("Request failed. Process exited with code 1"))
+			})
+		})
+
+		It("keeps the containers' own firewall rules", func() {
+			info, err := container.Info()
+			Expect(err).NotTo(HaveOccurred())
+			instanceChain := info.Properties["kawasaki.iptable-prefix"] + "instance-" + info.Properties["kawasaki.iptable-inst"]
+
+			before, err := runIPTables("-t", "filter", "-S", instanceChain)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(syscall.Kill(client.Pid, syscall.SIGHUP)).To(Succeed())
+			Eventually(client).Should(gbytes.Say("reload-global-policy.finished"))
+
+			after, err := runIPTables("-t", "filter", "-S", instanceChain)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(after).To(Equal(before))
+		})
+	})
+
+	Describe("per-container network overrides", func() {
+		Context("when the container denies additional networks", func() {
+			BeforeEach(func() {
+				extraProperties = garden.Properties{
+					gardener.NetworkDenyNetworksKey: "8.8.8.0/24",
+				}
+			})
+
+			It("should deny outbound traffic to IPs in the range", func() {
+				Expect(checkConnection(container, "8.8.8.8", 53)).To(MatchError("Request failed. Process exited with code 1"))
+			})
+
+			It("should allow outbound traffic to IPs outside of the range", func() {
+				Expect(checkConnection(container, "8.8.4.4", 53)).To(Succeed())
+			})
+
+			It("should deny outbound traffic even when a NetOut rule allows it", func() {
+				Expect(container.NetOut(garden.NetOutRule{
+					Protocol: garden.ProtocolTCP,
+					Networks: []garden.IPRange{garden.IPRangeFromIP(net.ParseIP("8.8.8.8"))},
+					Ports:    []garden.PortRange{garden.PortRangeFromPort(53)},
+				})).To(Succeed())
+				Expect(checkConnection(container, "8.8.8.8", 53)).To(MatchError("Request failed. Process exited with code 1"))
+			})
+
+			It("should not affect other containers", func() {
+				otherContainer, err := client.Create(garden.ContainerSpec{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(checkConnection(otherContainer, "8.8.8.8", 53)).To(Succeed())
+			})
+		})
+
+		Context("when the container is allowed to access the host", func() {
+			BeforeEach(func() {
+				extraProperties = garden.Properties{
+					gardener.NetworkAllowHostAccessKey: "true",
+				}
+			})
+
+			It("should be able to reach the host", func() {
+				Expect(checkHostAccess(container)).To(Succeed())
+			})
+
+			It("should not allow other containers to reach the host", func() {
+				otherContainer, err := client.Create(garden.ContainerSpec{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(checkHostAccess(otherContainer)).NotTo(Succeed())
+			})
+		})
+	})
+
 	Describe("NetOut", func() {
 		var (
 			rule garden.NetOutRule
@@ -629,6 +797,42 @@ var _ = Describe("Networking", func() {
 			})
 		})
 
+		Context("when the container requests its own MTU and interface name", func() {
+			BeforeEach(func() {
+				extraProperties = garden.Properties{
+					gardener.NetworkMtuKey:           "1280",
+					gardener.NetworkInterfaceNameKey: "eth0",
+				}
+			})
+
+			It("has an interface with the requested name and MTU", func() {
+				stdout := gbytes.NewBuffer()
+
+				process, err := container.Run(garden.ProcessSpec{
+					User: "alice",
+					Path: "ifconfig",
+					Args: []string{"eth0"},
+				}, garden.ProcessIO{
+					Stdout: stdout,
+					Stderr: GinkgoWriter,
+				})
+				Expect(err).ToNot(HaveOccurred())
+				rc, err := process.Wait()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rc).To(Equal(0))
+
+				Expect(stdout.Contents()).To(ContainSubstring(" MTU:1280 "))
+				Expect(stdout.Contents()).To(ContainSubstring(containerIP(container)))
+			})
+
+			It("gives the host's end of the interface the same MTU", func() {
+				out, err := exec.Command("ifconfig", hostIfName(container)).Output()
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(out).To(ContainSubstring(" MTU:1280 "))
+			})
+		})
+
 		Context("when container mtu is not specified by operator", func() {
 			var outboundIfaceMtu int
 
@@ -778,6 +982,51 @@ var _ = Describe("Networking", func() {
 		})
 	})
 
+	Describe("connection limits", func() {
+		BeforeEach(func() {
+			containerSpec.Handle = fmt.Sprintf("connection-limits-handle-%d", GinkgoParallelNode())
+			extraProperties = garden.Properties{
+				gardener.NetworkMaxNewConnectionsPerSecondKey: "20",
+				gardener.NetworkMaxConnectionsKey:             "2",
+			}
+		})
+
+		It("limits the container's connections", func() {
+			output, err := runIPTables("-t", "filter", "-S")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(output)).To(MatchRegexp(`--hashlimit-above 20/sec.*--comment %s`, containerSpec.Handle))
+			Expect(string(output)).To(MatchRegexp(`--connlimit-above 2 .*--comment %s`, containerSpec.Handle))
+		})
+
+		It("removes the limits when the container is destroyed", func() {
+			Expect(client.Destroy(containerSpec.Handle)).To(Succeed())
+
+			output, err := runIPTables("-t", "filter", "-S")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(output)).NotTo(ContainSubstring("connlimit"))
+		})
+	})
+
+	Describe("network verification", func() {
+		BeforeEach(func() {
+			args = append(args, "--network-verify-interval", "1s")
+		})
+
+		It("re-creates the container's iptables chains when they are flushed", func() {
+			info, err := container.Info()
+			Expect(err).NotTo(HaveOccurred())
+			instanceChain := info.Properties["kawasaki.iptable-prefix"] + "instance-" + info.Properties["kawasaki.iptable-inst"]
+
+			out, err := runIPTables("-t", "filter", "-F", instanceChain)
+			Expect(err).NotTo(HaveOccurred(), string(out))
+
+			Eventually(func() string {
+				out, _ := runIPTables("-t", "filter", "-S", instanceChain)
+				return string(out)
+			}, "10s").Should(ContainSubstring(fmt.Sprintf("-g %s-dns", instanceChain)))
+		})
+	})
+
 	Describe("comments added to iptables rules", func() {
 		BeforeEach(func() {
 			containerSpec.Handle = fmt.Sprintf("iptable-comment-handle-%d", GinkgoParallelNode())
@@ -917,6 +1166,29 @@ func checkConnection(container garden.Container, ip string, port int) error {
 	}
 }
 
+// checkHostAccess pings the host's external IP from the container
+func checkHostAccess(container garden.Container) error {
+	process, err := container.Run(garden.ProcessSpec{
+		User: "root",
+		Path: "ping",
+		Args: []string{"-c", "1", "-w", "2", externalIP(container)},
+	}, garden.ProcessIO{Stdout: GinkgoWriter, Stderr: GinkgoWriter})
+	if err != nil {
+		return err
+	}
+
+	exitCode, err := process.Wait()
+	if err != nil {
+		return err
+	}
+
+	if exitCode != 0 {
+		return fmt.Errorf("Ping failed. Process exited with code %d", exitCode)
+	}
+
+	return nil
+}
+
 func ipAddress(subnet string, index int) string {
 	ip := strings.Split(subnet, "/")[0]
 	pattern := regexp.MustCompile(".[0-9]+$")
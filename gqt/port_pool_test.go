@@ -1,6 +1,7 @@
 package gqt_test
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -130,4 +131,42 @@ var _ = Describe("Port Pool", func() {
 		})
 
 	})
+
+	Context("when there are several ranges and reserved ports", func() {
+		var (
+			client    *runner.RunningGarden
+			rangeBase int
+		)
+
+		BeforeEach(func() {
+			rangeBase = GinkgoParallelNode()*7000 + 500
+			client = startGarden(
+				"--port-pool-start", strconv.Itoa(rangeBase),
+				"--port-pool-size", "2",
+				"--port-pool-range", fmt.Sprintf("%d-%d", rangeBase+100, rangeBase+101),
+				"--port-pool-reserved-port", strconv.Itoa(rangeBase+1),
+			)
+		})
+
+		AfterEach(func() {
+			Expect(client.DestroyAndStop()).To(Succeed())
+		})
+
+		It("hands out the ports of every range, except the reserved ports", func() {
+			container, err := client.Create(garden.ContainerSpec{})
+			Expect(err).NotTo(HaveOccurred())
+
+			var hostPorts []uint32
+			for i := 0; i < 3; i++ {
+				hostPort, _, err := container.NetIn(0, 0)
+				Expect(err).NotTo(HaveOccurred())
+				hostPorts = append(hostPorts, hostPort)
+			}
+
+			Expect(hostPorts).To(Equal([]uint32{uint32(rangeBase), uint32(rangeBase + 100), uint32(rangeBase + 101)}))
+
+			_, _, err = container.NetIn(0, 0)
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
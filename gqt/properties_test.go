@@ -106,6 +106,45 @@ var _ = Describe("Properties", func() {
 		Expect(props).To(HaveKey("kawasaki.mtu"))
 	})
 
+	Context("when setting block I/O limits", func() {
+		It("rejects an invalid weight without storing it", func() {
+			Expect(container.SetProperty(gardener.BlockIOWeightKey, "5")).NotTo(Succeed())
+
+			properties, err := container.Properties()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(properties).NotTo(HaveKey(gardener.BlockIOWeightKey))
+		})
+
+		It("refuses to create containers with invalid limits", func() {
+			_, err := client.Create(garden.ContainerSpec{
+				Properties: garden.Properties{gardener.BlockIOReadBpsKey: "/dev/sda"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("expected DEVICE:RATE")))
+		})
+	})
+
+	Context("when creating a container with memory options", func() {
+		It("reports the options in effect in its info", func() {
+			container, err := client.Create(garden.ContainerSpec{
+				Limits:     garden.Limits{Memory: garden.MemoryLimits{LimitInBytes: 64 * 1024 * 1024}},
+				Properties: garden.Properties{gardener.MemoryReservationKey: "33554432"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			info, err := container.Info()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Properties).To(HaveKeyWithValue(gardener.MemoryReservationKey, "33554432"))
+			Expect(info.Properties).To(HaveKeyWithValue(gardener.MemorySwapKey, "0"))
+		})
+
+		It("refuses to let unprivileged containers lower their oom score", func() {
+			_, err := client.Create(garden.ContainerSpec{
+				Properties: garden.Properties{gardener.OOMScoreAdjKey: "-1000"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("must be privileged to lower its oom score")))
+		})
+	})
+
 	Context("after a server restart", func() {
 		It("can still get the container's properties", func() {
 			beforeProps, err := container.Properties()
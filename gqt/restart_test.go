@@ -46,11 +46,13 @@ var _ = Describe("Surviving Restarts", func() {
 			hostNetInPort    uint32
 			externalIP       string
 			interfacePrefix  string
+			instanceChain    string
 			propertiesDir    string
 			existingProc     garden.Process
 			containerSpec    garden.ContainerSpec
 			restartArgs      []string
 			gracefulShutdown bool
+			whileStopped     func()
 		)
 
 		BeforeEach(func() {
@@ -65,6 +67,7 @@ var _ = Describe("Surviving Restarts", func() {
 
 			restartArgs = []string{}
 			gracefulShutdown = true
+			whileStopped = nil
 		})
 
 		JustBeforeEach(func() {
@@ -85,6 +88,7 @@ var _ = Describe("Surviving Restarts", func() {
 			Expect(err).NotTo(HaveOccurred())
 			externalIP = info.ExternalIP
 			interfacePrefix = info.Properties["kawasaki.iptable-prefix"]
+			instanceChain = interfacePrefix + "instance-" + info.Properties["kawasaki.iptable-inst"]
 
 			out := gbytes.NewBuffer()
 			existingProc, err = container.Run(
@@ -104,6 +108,10 @@ var _ = Describe("Surviving Restarts", func() {
 				Expect(client.Kill()).To(MatchError("exit status 137"))
 			}
 
+			if whileStopped != nil {
+				whileStopped()
+			}
+
 			if len(restartArgs) == 0 {
 				restartArgs = args
 			}
@@ -281,6 +289,30 @@ var _ = Describe("Surviving Restarts", func() {
 				})
 			})
 
+			Context("when the container's iptables chains are flushed while the server is down", func() {
+				BeforeEach(func() {
+					whileStopped = func() {
+						out, err := runIPTables("-t", "nat", "-F", instanceChain)
+						Expect(err).NotTo(HaveOccurred(), string(out))
+						out, err = runIPTables("-t", "filter", "-F", instanceChain)
+						Expect(err).NotTo(HaveOccurred(), string(out))
+					}
+				})
+
+				It("re-creates the container's NetOut rules", func() {
+					Expect(checkConnection(container, "8.8.8.8", 53)).To(Succeed())
+				})
+
+				It("re-creates the container's NetIn rules", func() {
+					Expect(listenInContainer(container, 8080)).To(Succeed())
+
+					Eventually(func() int {
+						session := sendRequest(externalIP, hostNetInPort)
+						return session.Wait().ExitCode()
+					}).Should(Equal(0))
+				})
+			})
+
 			Context("when creating a container after restart", func() {
 				It("should not allocate ports used before restart", func() {
 					secondContainer, err := client.Create(garden.ContainerSpec{})
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,6 +15,7 @@ import (
 	"syscall"
 
 	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
 	"code.cloudfoundry.org/guardian/gqt/runner"
 	"github.com/onsi/gomega/gbytes"
 	"github.com/onsi/gomega/gexec"
@@ -59,7 +61,6 @@ var _ = Describe("rootless containers", func() {
 			"--image-plugin", testImagePluginBin,
 			"--image-plugin-extra-arg", "\"--image-path\"",
 			"--image-plugin-extra-arg", imagePath,
-			"--network-plugin", "/bin/true",
 			"--tag", tag,
 		)
 	})
@@ -159,4 +160,136 @@ var _ = Describe("rootless containers", func() {
 			Expect(process.Wait()).To(Equal(0))
 		})
 	})
+
+	Describe("networking a container", func() {
+		var container garden.Container
+
+		BeforeEach(func() {
+			var err error
+
+			container, err = client.Create(garden.ContainerSpec{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("forwards a mapped host port to the container port", func() {
+			hostPort, containerPort, err := container.NetIn(0, 8080)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = container.Run(garden.ProcessSpec{
+				Path: "sh",
+				Args: []string{"-c", fmt.Sprintf("echo rootlessNetInFTW | nc -l -p %d", containerPort)},
+			}, garden.ProcessIO{Stdout: GinkgoWriter, Stderr: GinkgoWriter})
+			Expect(err).NotTo(HaveOccurred())
+
+			properties, err := container.Properties()
+			Expect(err).NotTo(HaveOccurred())
+			externalIP := properties[gardener.ExternalIPKey]
+
+			// retry because the listener may not start immediately
+			Eventually(func() *gexec.Session {
+				session, err := gexec.Start(exec.Command("nc", "-w5", externalIP, strconv.Itoa(int(hostPort))), GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				return session.Wait()
+			}).Should(gbytes.Say("rootlessNetInFTW"))
+		})
+
+		Describe("connecting out through the container's proxy", func() {
+			var (
+				listener net.Listener
+				hostPort int
+			)
+
+			BeforeEach(func() {
+				var err error
+				listener, err = net.Listen("tcp", "127.0.0.1:0")
+				Expect(err).NotTo(HaveOccurred())
+				hostPort = listener.Addr().(*net.TCPAddr).Port
+
+				go func() {
+					for {
+						conn, err := listener.Accept()
+						if err != nil {
+							return
+						}
+
+						conn.Write([]byte("rootlessNetOutFTW\n"))
+						conn.Close()
+					}
+				}()
+			})
+
+			AfterEach(func() {
+				listener.Close()
+			})
+
+			It("denies connections by default", func() {
+				stdout := connectThroughProxy(container, hostPort)
+				Expect(stdout).NotTo(gbytes.Say("rootlessNetOutFTW"))
+			})
+
+			It("allows connections matching a NetOut rule", func() {
+				Expect(container.NetOut(garden.NetOutRule{
+					Protocol: garden.ProtocolTCP,
+					Networks: []garden.IPRange{garden.IPRangeFromIP(net.ParseIP("127.0.0.1"))},
+					Ports:    []garden.PortRange{garden.PortRangeFromPort(uint16(hostPort))},
+				})).To(Succeed())
+
+				stdout := connectThroughProxy(container, hostPort)
+				Expect(stdout).To(gbytes.Say("rootlessNetOutFTW"))
+			})
+
+			It("points proxy-aware programs at the proxy", func() {
+				stdout := gbytes.NewBuffer()
+				process, err := container.Run(garden.ProcessSpec{
+					Path: "sh",
+					Args: []string{"-c", "echo $ALL_PROXY $all_proxy"},
+				}, garden.ProcessIO{Stdout: stdout, Stderr: GinkgoWriter})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(process.Wait()).To(Equal(0))
+
+				Expect(stdout).To(gbytes.Say("socks5h://127.0.0.1:1080 socks5h://127.0.0.1:1080"))
+			})
+
+			It("does not allow connections which bypass the proxy, even when a NetOut rule allows them", func() {
+				Expect(container.NetOut(garden.NetOutRule{
+					Protocol: garden.ProtocolAll,
+				})).To(Succeed())
+
+				properties, err := container.Properties()
+				Expect(err).NotTo(HaveOccurred())
+
+				stdout := gbytes.NewBuffer()
+				process, err := container.Run(garden.ProcessSpec{
+					Path: "sh",
+					Args: []string{"-c", fmt.Sprintf("nc -w5 %s %d", properties[gardener.ExternalIPKey], hostPort)},
+				}, garden.ProcessIO{Stdout: stdout, Stderr: GinkgoWriter})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(process.Wait()).NotTo(Equal(0))
+				Expect(stdout).NotTo(gbytes.Say("rootlessNetOutFTW"))
+			})
+		})
+	})
 })
+
+// connectThroughProxy asks the SOCKS5 proxy on the container's loopback
+// interface to connect to a port on the host's, returning what is read
+func connectThroughProxy(container garden.Container, port int) *gbytes.Buffer {
+	// no authentication, then CONNECT to 127.0.0.1:port
+	request := []byte{5, 1, 0, 5, 1, 0, 1, 127, 0, 0, 1, byte(port >> 8), byte(port)}
+
+	var escaped string
+	for _, b := range request {
+		escaped += fmt.Sprintf("\\%03o", b)
+	}
+
+	stdout := gbytes.NewBuffer()
+	process, err := container.Run(garden.ProcessSpec{
+		Path: "sh",
+		Args: []string{"-c", fmt.Sprintf("printf '%s' | nc -w5 127.0.0.1 1080", escaped)},
+	}, garden.ProcessIO{Stdout: stdout, Stderr: GinkgoWriter})
+	Expect(err).NotTo(HaveOccurred())
+
+	process.Wait()
+	return stdout
+}
@@ -2,6 +2,9 @@ package gqt_test
 
 import (
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 
 	"code.cloudfoundry.org/garden"
 	"code.cloudfoundry.org/guardian/gqt/runner"
@@ -124,4 +127,245 @@ var _ = Describe("Security", func() {
 			})
 		})
 	})
+
+	Describe("Seccomp", func() {
+		Context("when the --seccomp-profile flag is pointing to a profile", func() {
+			var profileDir string
+
+			BeforeEach(func() {
+				var err error
+				profileDir, err = ioutil.TempDir("", "seccomp")
+				Expect(err).NotTo(HaveOccurred())
+
+				profilePath := filepath.Join(profileDir, "profile.json")
+				Expect(ioutil.WriteFile(profilePath, []byte(`{
+					"defaultAction": "SCMP_ACT_ALLOW",
+					"syscalls": [{"names": ["mkdir", "mkdirat"], "action": "SCMP_ACT_ERRNO"}]
+				}`), 0600)).To(Succeed())
+
+				args = append(args, "--seccomp-profile", profilePath)
+			})
+
+			AfterEach(func() {
+				Expect(os.RemoveAll(profileDir)).To(Succeed())
+			})
+
+			It("should enforce the profile when running processes in unprivileged containers", func() {
+				container, err := client.Create(garden.ContainerSpec{})
+				Expect(err).NotTo(HaveOccurred())
+
+				process, err := container.Run(garden.ProcessSpec{
+					Path: "mkdir",
+					Args: []string{"/tmp/seccomp"},
+				}, garden.ProcessIO{
+					Stdout: GinkgoWriter,
+					Stderr: GinkgoWriter,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(process.Wait()).NotTo(Equal(0))
+			})
+
+			It("should not enforce the profile when running processes in privileged containers", func() {
+				container, err := client.Create(garden.ContainerSpec{
+					Privileged: true,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				process, err := container.Run(garden.ProcessSpec{
+					Path: "mkdir",
+					Args: []string{"/tmp/seccomp"},
+				}, garden.ProcessIO{
+					Stdout: GinkgoWriter,
+					Stderr: GinkgoWriter,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(process.Wait()).To(Equal(0))
+			})
+
+			Context("when the --seccomp-audit flag is set", func() {
+				BeforeEach(func() {
+					args = append(args, "--seccomp-audit")
+				})
+
+				It("should allow and log the syscalls which the profile would deny", func() {
+					container, err := client.Create(garden.ContainerSpec{})
+					Expect(err).NotTo(HaveOccurred())
+
+					process, err := container.Run(garden.ProcessSpec{
+						Path: "mkdir",
+						Args: []string{"/tmp/seccomp"},
+					}, garden.ProcessIO{
+						Stdout: GinkgoWriter,
+						Stderr: GinkgoWriter,
+					})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(process.Wait()).To(Equal(0))
+
+					Eventually(client).Should(gbytes.Say(`seccomp-audit-collector.syscall-audited.*"handle":"` + container.Handle() + `"`))
+				})
+			})
+		})
+
+		Context("when the --seccomp-profile flag is not set", func() {
+			It("should allow unprivileged container processes to make ordinary syscalls", func() {
+				container, err := client.Create(garden.ContainerSpec{})
+				Expect(err).NotTo(HaveOccurred())
+
+				process, err := container.Run(garden.ProcessSpec{
+					Path: "mkdir",
+					Args: []string{"/tmp/seccomp"},
+				}, garden.ProcessIO{
+					Stdout: GinkgoWriter,
+					Stderr: GinkgoWriter,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(process.Wait()).To(Equal(0))
+			})
+		})
+	})
+
+	Describe("Security profiles", func() {
+		var profilesDir string
+
+		BeforeEach(func() {
+			var err error
+			profilesDir, err = ioutil.TempDir("", "security-profiles")
+			Expect(err).NotTo(HaveOccurred())
+
+			profilesPath := filepath.Join(profilesDir, "profiles.json")
+			Expect(ioutil.WriteFile(profilesPath, []byte(`{
+				"profiles": {"no-chown": {"drop_caps": ["CAP_CHOWN"]}},
+				"allow": [{"handles": "allowed-*", "profiles": ["no-chown"]}]
+			}`), 0600)).To(Succeed())
+
+			args = append(args, "--security-profiles", profilesPath)
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(profilesDir)).To(Succeed())
+		})
+
+		It("applies the profile selected by an allowed container", func() {
+			container, err := client.Create(garden.ContainerSpec{
+				Handle:     "allowed-container",
+				Properties: garden.Properties{"garden.security-profile": "no-chown"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			process, err := container.Run(garden.ProcessSpec{
+				Path: "chown",
+				Args: []string{"1000", "/tmp"},
+			}, garden.ProcessIO{
+				Stdout: GinkgoWriter,
+				Stderr: GinkgoWriter,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(process.Wait()).NotTo(Equal(0))
+		})
+
+		It("does not create a container which is not allowed the profile", func() {
+			_, err := client.Create(garden.ContainerSpec{
+				Handle:     "other-container",
+				Properties: garden.Properties{"garden.security-profile": "no-chown"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("container other-container is not allowed to use security profile no-chown")))
+		})
+	})
+
+	Describe("Sysctls", func() {
+		It("sets the sysctls requested by an unprivileged container", func() {
+			container, err := client.Create(garden.ContainerSpec{
+				Properties: garden.Properties{"garden.sysctl.net.core.somaxconn": "1234"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			stdout := gbytes.NewBuffer()
+			process, err := container.Run(garden.ProcessSpec{
+				Path: "cat",
+				Args: []string{"/proc/sys/net/core/somaxconn"},
+			}, garden.ProcessIO{
+				Stdout: stdout,
+				Stderr: GinkgoWriter,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(process.Wait()).To(Equal(0))
+			Expect(stdout).To(gbytes.Say("1234"))
+		})
+
+		It("does not create an unprivileged container which requests a sysctl which is not namespaced", func() {
+			_, err := client.Create(garden.ContainerSpec{
+				Properties: garden.Properties{"garden.sysctl.vm.swappiness": "0"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("sysctl vm.swappiness is not allowed in unprivileged containers")))
+		})
+	})
+
+	Describe("Devices", func() {
+		runInContainer := func(container garden.Container, path string, args ...string) int {
+			process, err := container.Run(garden.ProcessSpec{
+				Path: path,
+				Args: args,
+				User: "root",
+			}, garden.ProcessIO{
+				Stdout: GinkgoWriter,
+				Stderr: GinkgoWriter,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			exitCode, err := process.Wait()
+			Expect(err).NotTo(HaveOccurred())
+			return exitCode
+		}
+
+		Context("when the --allow-device flag is set", func() {
+			BeforeEach(func() {
+				args = append(args, "--allow-device", "/dev/net/tun")
+			})
+
+			It("creates the device in every container", func() {
+				container, err := client.Create(garden.ContainerSpec{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(runInContainer(container, "test", "-c", "/dev/net/tun")).To(Equal(0))
+			})
+		})
+
+		Context("when the --passthrough-device flag is set", func() {
+			BeforeEach(func() {
+				args = append(args, "--passthrough-device", "/dev/net/tun")
+			})
+
+			It("creates the device in privileged containers which request it", func() {
+				container, err := client.Create(garden.ContainerSpec{
+					Privileged: true,
+					Properties: garden.Properties{"garden.devices": "/dev/net/tun"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(runInContainer(container, "test", "-c", "/dev/net/tun")).To(Equal(0))
+			})
+
+			It("does not create the device in containers which do not request it", func() {
+				container, err := client.Create(garden.ContainerSpec{Privileged: true})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(runInContainer(container, "test", "-c", "/dev/net/tun")).NotTo(Equal(0))
+			})
+
+			It("does not create an unprivileged container which requests the device", func() {
+				_, err := client.Create(garden.ContainerSpec{
+					Properties: garden.Properties{"garden.devices": "/dev/net/tun"},
+				})
+				Expect(err).To(MatchError(ContainSubstring("must be privileged to use devices")))
+			})
+
+			It("does not create a container which requests another device", func() {
+				_, err := client.Create(garden.ContainerSpec{
+					Privileged: true,
+					Properties: garden.Properties{"garden.devices": "/dev/kvm"},
+				})
+				Expect(err).To(MatchError(ContainSubstring("device /dev/kvm is not available for passthrough")))
+			})
+		})
+	})
 })
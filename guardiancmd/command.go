@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
 	"code.cloudfoundry.org/idmapper"
@@ -32,8 +35,10 @@ import (
 	"code.cloudfoundry.org/guardian/kawasaki/factory"
 	"code.cloudfoundry.org/guardian/kawasaki/iptables"
 	"code.cloudfoundry.org/guardian/kawasaki/mtu"
+	"code.cloudfoundry.org/guardian/kawasaki/nflog"
 	"code.cloudfoundry.org/guardian/kawasaki/ports"
 	"code.cloudfoundry.org/guardian/kawasaki/subnets"
+	"code.cloudfoundry.org/guardian/kawasaki/userspace"
 	"code.cloudfoundry.org/guardian/logging"
 	"code.cloudfoundry.org/guardian/metrics"
 	"code.cloudfoundry.org/guardian/netplugin"
@@ -41,9 +46,11 @@ import (
 	"code.cloudfoundry.org/guardian/properties"
 	"code.cloudfoundry.org/guardian/rundmc"
 	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
+	"code.cloudfoundry.org/guardian/rundmc/cpuset"
 	"code.cloudfoundry.org/guardian/rundmc/dadoo"
 	"code.cloudfoundry.org/guardian/rundmc/depot"
 	"code.cloudfoundry.org/guardian/rundmc/goci"
+	"code.cloudfoundry.org/guardian/rundmc/idmapping"
 	"code.cloudfoundry.org/guardian/rundmc/preparerootfs"
 	"code.cloudfoundry.org/guardian/rundmc/runrunc"
 	"code.cloudfoundry.org/guardian/rundmc/stopper"
@@ -134,8 +141,11 @@ var PrivilegedContainerNamespaces = []specs.LinuxNamespace{
 }
 
 type GdnCommand struct {
-	SetupCommand  *SetupCommand  `command:"setup"`
-	ServerCommand *ServerCommand `command:"server"`
+	SetupCommand   *SetupCommand   `command:"setup"`
+	ServerCommand  *ServerCommand  `command:"server"`
+	ExplainCommand *ExplainCommand `command:"explain"`
+
+	NetnsSocketsCommand *NetnsSocketsCommand `command:"netns-sockets"`
 }
 
 type ServerCommand struct {
@@ -163,6 +173,18 @@ type ServerCommand struct {
 		DefaultGraceTime           time.Duration `long:"default-grace-time" description:"Default time after which idle containers should expire."`
 		DestroyContainersOnStartup bool          `long:"destroy-containers-on-startup" description:"Clean up all the existing containers on startup."`
 		ApparmorProfile            string        `long:"apparmor" description:"Apparmor profile to use for unprivileged container processes"`
+		SeccompProfile             FileFlag      `long:"seccomp-profile" description:"Seccomp profile, in Docker's JSON format, to use for unprivileged containers instead of the built-in profile. Validated on startup."`
+		SeccompAudit               bool          `long:"seccomp-audit" description:"Log, rather than deny, the syscalls which unprivileged containers' seccomp profile would deny, and report them per container in the logs and on the debug server's /seccomp-audit endpoint. Needs a runc and libseccomp supporting SCMP_ACT_LOG. Not for production use."`
+		AllowedDevices             []string      `long:"allow-device" description:"Host device, e.g. /dev/kvm, to create and allow in every container. Can be specified multiple times."`
+		PassthroughDevices         []string      `long:"passthrough-device" description:"Host device, e.g. /dev/net/tun, which privileged containers may request with the garden.devices property. Can be specified multiple times."`
+		SecurityProfiles           FileFlag      `long:"security-profiles" description:"JSON file of named security profiles, which adjust capabilities, seccomp and AppArmor for the containers selecting them with the garden.security-profile property, and of the container handle patterns allowed to select each profile. Handle patterns are not access control, so profiles which do more than drop capabilities may only be used by privileged containers. Validated on startup."`
+
+		UIDMapStart             uint32 `long:"uid-map-start"               description:"First host UID onto which unprivileged containers' UIDs are mapped, starting with container root. Requires --uid-map-length."`
+		UIDMapLength            uint32 `long:"uid-map-length"              description:"Number of host UIDs onto which unprivileged containers' UIDs are mapped. By default container root maps to the largest host UID and other UIDs to themselves."`
+		GIDMapStart             uint32 `long:"gid-map-start"               description:"First host GID onto which unprivileged containers' GIDs are mapped, starting with container root. Requires --gid-map-length."`
+		GIDMapLength            uint32 `long:"gid-map-length"              description:"Number of host GIDs onto which unprivileged containers' GIDs are mapped."`
+		SubIDUser               string `long:"subid-user"                  description:"User whose first ranges in /etc/subuid and /etc/subgid unprivileged containers' UIDs and GIDs are mapped onto, instead of the --uid-map and --gid-map ranges."`
+		PerContainerIDRangeSize uint32 `long:"per-container-id-range-size" description:"Give each unprivileged container its own range of this many host UIDs and GIDs, carved from the configured ranges, so that containers share no host IDs. Requires an image plugin. 0 maps every container onto the whole ranges."`
 	} `group:"Container Lifecycle"`
 
 	Bin struct {
@@ -174,6 +196,7 @@ type ServerCommand struct {
 		IPTablesRestore FileFlag `long:"iptables-restore-bin"  default:"/sbin/iptables-restore" description:"path to the iptables-restore binary"`
 		Init            FileFlag `long:"init-bin"       description:"Path execute as pid 1 inside each container."`
 		Runc            string   `long:"runc-bin"      default:"runc" description:"Path to the 'runc' binary."`
+		NSEnter         string   `long:"nsenter-bin"   default:"nsenter" description:"Path to the 'nsenter' binary, used to network containers when not running as root."`
 	} `group:"Binary Tools"`
 
 	Graph struct {
@@ -201,17 +224,34 @@ type ServerCommand struct {
 		AllowHostAccess bool       `long:"allow-host-access" description:"Allow network access to the host machine."`
 		DenyNetworks    []CIDRFlag `long:"deny-network"      description:"Network ranges to which traffic from containers will be denied. Can be specified multiple times."`
 		AllowNetworks   []CIDRFlag `long:"allow-network"     description:"Network ranges to which traffic from containers will be allowed. Can be specified multiple times."`
+		PolicyFile      string     `long:"network-policy-file" description:"JSON file holding the global firewall policy, e.g. {\"allow_host_access\": false, \"allow_networks\": [\"10.0.1.0/24\"], \"deny_networks\": [\"10.0.0.0/8\"]}. Overrides --allow-host-access, --allow-network and --deny-network when present. SIGHUP reapplies the file or, when it is absent, those flags."`
 
 		DNSServers           []IPFlag `long:"dns-server" description:"DNS server IP address to use instead of automatically determined servers. Can be specified multiple times."`
 		AdditionalDNSServers []IPFlag `long:"additional-dns-server" description:"DNS server IP address to append to the automatically determined servers. Can be specified multiple times."`
 
-		ExternalIP             IPFlag `long:"external-ip"                     description:"IP address to use to reach container's mapped ports. Autodetected if not specified."`
-		PortPoolStart          uint32 `long:"port-pool-start" default:"60000" description:"Start of the ephemeral port range used for mapped container ports."`
-		PortPoolSize           uint32 `long:"port-pool-size"  default:"5000"  description:"Size of the port pool used for mapped container ports."`
-		PortPoolPropertiesPath string `long:"port-pool-properties-path" description:"Path in which to store port pool properties."`
+		ExternalIP             IPFlag   `long:"external-ip"                     description:"IP address to use to reach container's mapped ports. Autodetected if not specified."`
+		AdditionalExternalIPs  []IPFlag `long:"additional-external-ip" description:"Further IP address of the host which containers may choose to bind their mapped ports to. Can be specified multiple times."`
+		PortPoolStart          uint32   `long:"port-pool-start" default:"60000" description:"Start of the ephemeral port range used for mapped container ports."`
+		PortPoolSize           uint32   `long:"port-pool-size"  default:"5000"  description:"Size of the port pool used for mapped container ports."`
+		PortPoolPropertiesPath string   `long:"port-pool-properties-path" description:"Path in which to store port pool properties."`
+
+		PortPoolRanges        []PortRangeFlag `long:"port-pool-range"         description:"Additional range of ports, e.g. 61000-61999, used for mapped container ports. Can be specified multiple times."`
+		PortPoolReservedPorts []uint16        `long:"port-pool-reserved-port" description:"Port in the port pool ranges which is never used for mapped container ports. Can be specified multiple times."`
 
 		Mtu int `long:"mtu" description:"MTU size for container network interfaces. Defaults to the MTU of the interface used for outbound access by the host."`
 
+		VerifyInterval time.Duration `long:"network-verify-interval" description:"Interval on which containers' network interfaces and iptables chains are verified, re-creating any which are missing. Disabled by default."`
+
+		NetOutLogGroup     uint16 `long:"netout-log-group"      default:"1"   description:"Netfilter log group to which packets matching logged NetOut rules are sent."`
+		NetOutLogRateLimit int    `long:"netout-log-rate-limit" default:"100" description:"Maximum number of logged NetOut packets reported per container per second. Set to 0 for no limit."`
+
+		DirectNetworkInterface string   `long:"direct-network-interface" description:"Host interface to which containers in macvlan or ipvlan network mode are attached. Enables those modes."`
+		DirectNetworkSubnet    CIDRFlag `long:"direct-network-subnet"    description:"Network of the direct network interface. Required when a direct network interface is given."`
+		DirectNetworkRange     CIDRFlag `long:"direct-network-range"     description:"Range within the direct network subnet from which container addresses are allocated. Defaults to the whole subnet."`
+		DirectNetworkGateway   IPFlag   `long:"direct-network-gateway"   description:"Default gateway for containers in macvlan or ipvlan network mode. Defaults to the first address in the direct network subnet."`
+
+		UserspaceProxyPort uint32 `long:"userspace-proxy-port" default:"1080" description:"Port of the SOCKS5 proxy on each container's loopback interface through which it makes outbound TCP connections, when not running as root. Containers' ALL_PROXY variables default to it, and they have no other outbound network access."`
+
 		Plugin          FileFlag `long:"network-plugin"           description:"Path to network plugin binary."`
 		PluginExtraArgs []string `long:"network-plugin-extra-arg" description:"Extra argument to pass to the network plugin. Can be specified multiple times."`
 	} `group:"Container Networking"`
@@ -219,6 +259,20 @@ type ServerCommand struct {
 	Limits struct {
 		CpuQuotaPerShare uint64 `long:"cpu-quota-per-share" default:"0" description:"Maximum number of microseconds each cpu share assigned to a container allows per quota period"`
 		MaxContainers    uint64 `long:"max-containers" default:"0" description:"Maximum number of containers that can be created."`
+
+		BlockIOWeight    uint16   `long:"blkio-weight"     default:"0" description:"Default proportion of block I/O for containers, from 10 to 1000. Containers may override it with the garden.blkio.weight property. 0 leaves it unset."`
+		BlockIOReadBps   []string `long:"blkio-read-bps"   description:"Default read rate limit for containers in bytes per second, as DEVICE:RATE, where DEVICE is a path or major:minor pair. Can be specified multiple times."`
+		BlockIOWriteBps  []string `long:"blkio-write-bps"  description:"Default write rate limit for containers in bytes per second, as DEVICE:RATE. Can be specified multiple times."`
+		BlockIOReadIOPS  []string `long:"blkio-read-iops"  description:"Default read rate limit for containers in operations per second, as DEVICE:RATE. Can be specified multiple times."`
+		BlockIOWriteIOPS []string `long:"blkio-write-iops" description:"Default write rate limit for containers in operations per second, as DEVICE:RATE. Can be specified multiple times."`
+
+		DedicatedCPUs string `long:"dedicated-cpus" description:"CPUs, e.g. 4-15, to dedicate to containers requesting the garden.cpuset.dedicated-cores property. Other containers do not run on them."`
+
+		DefaultMemoryReservation uint64  `long:"default-memory-reservation" default:"0" description:"Default soft memory limit for containers in bytes, which containers may override with the garden.memory.reservation property. 0 leaves it unset."`
+		DefaultMemorySwap        *uint64 `long:"default-memory-swap"                    description:"Default swap for containers in bytes, in addition to their memory limit. Containers may override it with the garden.memory.swap property. Containers have no swap by default."`
+		DefaultMemorySwappiness  *uint64 `long:"default-memory-swappiness"              description:"Default swappiness for containers, from 0 to 100. Containers may override it with the garden.memory.swappiness property."`
+		DefaultKernelMemory      uint64  `long:"default-kernel-memory"      default:"0" description:"Default kernel memory limit for containers in bytes. Containers may override it with the garden.memory.kernel property. 0 leaves it unset."`
+		DefaultOOMScoreAdj       *int    `long:"default-oom-score-adj"                  description:"Default oom score adjustment for containers, from -1000 to 1000. Containers may override it with the garden.memory.oom-score-adj property."`
 	} `group:"Limits"`
 
 	Metrics struct {
@@ -289,13 +343,20 @@ func (cmd *ServerCommand) Execute([]string) error {
 		cmd.Network.AllowHostAccess = true
 	}
 
-	return <-ifrit.Invoke(sigmon.New(cmd)).Wait()
+	return <-ifrit.Invoke(sigmon.New(cmd, syscall.SIGHUP)).Wait()
 }
 
 func runningAsRoot() bool {
 	return os.Geteuid() == 0
 }
 
+// kernelNetworking returns true if containers' networks are kawasaki's
+// bridges and iptables rules, i.e. neither a network plugin's nor, when not
+// running as root, in userspace
+func (cmd *ServerCommand) kernelNetworking() bool {
+	return cmd.Network.Plugin.Path() == "" && runningAsRoot()
+}
+
 func restoreUnversionedAssets(assetsDir string) (string, error) {
 	linuxAssetsDir := filepath.Join(assetsDir, "linux")
 
@@ -333,16 +394,86 @@ func (cmd *ServerCommand) Run(signals <-chan os.Signal, ready chan<- struct{}) e
 		}
 	}
 
-	portPool, err := ports.NewPool(
-		cmd.Network.PortPoolStart,
-		cmd.Network.PortPoolSize,
+	portPoolRanges := []ports.Range{{Start: cmd.Network.PortPoolStart, Size: cmd.Network.PortPoolSize}}
+	for _, r := range cmd.Network.PortPoolRanges {
+		portPoolRanges = append(portPoolRanges, r.Range())
+	}
+
+	var reservedPorts []uint32
+	for _, port := range cmd.Network.PortPoolReservedPorts {
+		reservedPorts = append(reservedPorts, uint32(port))
+	}
+
+	portPool, err := ports.NewPoolFromRanges(
+		portPoolRanges,
+		reservedPorts,
 		portPoolState,
+		cmd.Network.PortPoolPropertiesPath,
 	)
 	if err != nil {
 		return fmt.Errorf("invalid pool range: %s", err)
 	}
 
-	networker, iptablesStarter, err := cmd.wireNetworker(logger, propManager, portPool)
+	configuredIDMappings, idPool, err := cmd.wireIDMappings()
+	if err != nil {
+		logger.Error("failed-to-set-up-id-mappings", err)
+		return err
+	}
+
+	var volumeCreator gardener.VolumeCreator = nil
+	volumeCreator = cmd.wireVolumeCreator(logger, cmd.Graph.Dir, cmd.Docker.InsecureRegistries, cmd.Graph.PersistentImages, configuredIDMappings, idPool)
+
+	seccomp, err := cmd.loadSeccomp(logger)
+	if err != nil {
+		logger.Error("failed-to-load-seccomp-profile", err)
+		return err
+	}
+
+	securityProfiles, err := cmd.loadSecurityProfiles(logger)
+	if err != nil {
+		return err
+	}
+
+	allowedDevices, err := bundlerules.LookupDevices(cmd.Containers.AllowedDevices)
+	if err != nil {
+		logger.Error("failed-to-look-up-allowed-devices", err)
+		return err
+	}
+
+	passthroughDevices, err := bundlerules.LookupDevices(cmd.Containers.PassthroughDevices)
+	if err != nil {
+		logger.Error("failed-to-look-up-passthrough-devices", err)
+		return err
+	}
+
+	blockIODefaults, err := cmd.blockIODefaults()
+	if err != nil {
+		logger.Error("failed-to-parse-block-io-limits", err)
+		return err
+	}
+
+	memoryDefaults, err := cmd.memoryDefaults()
+	if err != nil {
+		logger.Error("failed-to-parse-memory-defaults", err)
+		return err
+	}
+
+	cpuAllocator, sharedCPUs, err := cmd.wireCPUAllocator()
+	if err != nil {
+		logger.Error("failed-to-set-up-dedicated-cpus", err)
+		return err
+	}
+
+	unifiedCgroups, err := isUnifiedCgroupHierarchy()
+	if err != nil {
+		logger.Error("failed-to-detect-cgroup-hierarchy", err)
+		return err
+	}
+	logger.Info("detected-cgroup-hierarchy", lager.Data{"unified": unifiedCgroups})
+
+	containerizer := cmd.wireContainerizer(logger, cmd.Containers.Dir, cmd.Bin.Dadoo.Path(), cmd.Bin.Runc, cmd.Bin.NSTar.Path(), cmd.Bin.Tar.Path(), cmd.Containers.ApparmorProfile, seccomp, securityProfiles, allowedDevices, passthroughDevices, blockIODefaults, sharedCPUs, memoryDefaults, unifiedCgroups, orDefaultIDMappings(configuredIDMappings), propManager)
+
+	networker, iptablesStarter, hostnameRefresher, firewallExplainer, err := cmd.wireNetworker(logger, propManager, portPool, containerizer)
 	if err != nil {
 		logger.Error("failed-to-wire-networker", err)
 		return err
@@ -353,9 +484,6 @@ func (cmd *ServerCommand) Run(signals <-chan os.Signal, ready chan<- struct{}) e
 		restorer = &gardener.NoopRestorer{}
 	}
 
-	var volumeCreator gardener.VolumeCreator = nil
-	volumeCreator = cmd.wireVolumeCreator(logger, cmd.Graph.Dir, cmd.Docker.InsecureRegistries, cmd.Graph.PersistentImages)
-
 	starters := []gardener.Starter{}
 	if !cmd.Server.SkipSetup {
 		starters = append(starters, cmd.wireCgroupsStarter(logger))
@@ -366,20 +494,54 @@ func (cmd *ServerCommand) Run(signals <-chan os.Signal, ready chan<- struct{}) e
 
 	var bulkStarter gardener.BulkStarter = gardener.NewBulkStarter(starters)
 
+	// the saved port allocations may include containers destroyed while the
+	// server was not running
+	if handles, err := containerizer.Handles(); err != nil {
+		logger.Error("failed-to-prune-port-pool", err)
+	} else if err := portPool.Prune(handles); err != nil {
+		logger.Error("failed-to-prune-port-pool", err)
+	}
+
+	// network plugins manage their own kernel state
+	var networkVerifier *kawasaki.PeriodicVerifier
+	if verifier, ok := networker.(kawasaki.NetworkVerifier); ok && cmd.Network.VerifyInterval > 0 {
+		networkVerifier = kawasaki.NewPeriodicVerifier(logger, verifier, containerizer.Handles, cmd.Network.VerifyInterval, clock.NewClock())
+	}
+
+	var netOutLogCollector *nflog.Collector
+	if cmd.kernelNetworking() {
+		netOutLogCollector = cmd.wireNetOutLogCollector(logger, propManager, containerizer.Handles)
+	}
+
+	seccompAuditCollector := cmd.wireSeccompAuditCollector(logger, securityProfiles, containerizer.Handles)
+
 	backend := &gardener.Gardener{
 		UidGenerator:    cmd.wireUidGenerator(),
 		BulkStarter:     bulkStarter,
 		SysInfoProvider: sysinfo.NewProvider(cmd.Containers.Dir),
 		Networker:       networker,
 		VolumeCreator:   volumeCreator,
-		Containerizer:   cmd.wireContainerizer(logger, cmd.Containers.Dir, cmd.Bin.Dadoo.Path(), cmd.Bin.Runc, cmd.Bin.NSTar.Path(), cmd.Bin.Tar.Path(), cmd.Containers.ApparmorProfile, propManager),
+		Containerizer:   containerizer,
 		PropertyManager: propManager,
 		MaxContainers:   cmd.Limits.MaxContainers,
 		Restorer:        restorer,
+		CPUAllocator:    cpuAllocator,
 
 		Logger: logger,
 	}
 
+	if idPool != nil {
+		backend.IDAllocator = idPool
+	}
+
+	if sharer, ok := networker.(gardener.NetworkSharer); ok {
+		backend.NetworkSharer = sharer
+	}
+
+	if seccompAuditCollector != nil {
+		backend.SyscallAuditor = seccompAuditCollector
+	}
+
 	var listenNetwork, listenAddr string
 	if cmd.Server.BindIP != nil {
 		listenNetwork = "tcp"
@@ -393,14 +555,41 @@ func (cmd *ServerCommand) Run(signals <-chan os.Signal, ready chan<- struct{}) e
 
 	cmd.initializeDropsonde(logger)
 
-	metricsProvider := cmd.wireMetricsProvider(logger, cmd.Containers.Dir, cmd.Graph.Dir)
+	metricsProvider := cmd.wireMetricsProvider(logger, cmd.Containers.Dir, cmd.Graph.Dir, portPool)
 
 	metronNotifier := cmd.wireMetronNotifier(logger, metricsProvider)
 	metronNotifier.Start()
 
+	// network plugins manage their own firewall, and userspace networking
+	// enforces the policy in its outbound proxies
+	var policyReloader metrics.GlobalPolicyReloader
+	if cmd.kernelNetworking() {
+		policyReloader, err = cmd.wireGlobalPolicyReloader()
+		if err != nil {
+			return err
+		}
+	} else if reloader, ok := networker.(metrics.GlobalPolicyReloader); ok {
+		policyReloader = reloader
+	}
+
 	if cmd.Server.DebugBindIP != nil {
 		addr := fmt.Sprintf("%s:%d", cmd.Server.DebugBindIP.IP(), cmd.Server.DebugBindPort)
-		metrics.StartDebugServer(addr, reconfigurableSink, metricsProvider)
+		var explainHandler http.Handler
+		if firewallExplainer != nil {
+			explainHandler = metrics.NewExplainHandler(logger, kawasaki.NewExplainer(propManager, firewallExplainer))
+		}
+
+		var policyHandler http.Handler
+		if policyReloader != nil {
+			policyHandler = metrics.NewGlobalPolicyHandler(logger, policyReloader)
+		}
+
+		var seccompAuditHandler http.Handler
+		if seccompAuditCollector != nil {
+			seccompAuditHandler = metrics.NewSeccompAuditHandler(logger, seccompAuditCollector)
+		}
+
+		metrics.StartDebugServer(addr, reconfigurableSink, metricsProvider, explainHandler, policyHandler, seccompAuditHandler)
 	}
 
 	err = gardenServer.Start()
@@ -409,6 +598,22 @@ func (cmd *ServerCommand) Run(signals <-chan os.Signal, ready chan<- struct{}) e
 		return err
 	}
 
+	if netOutLogCollector != nil {
+		netOutLogCollector.Start()
+	}
+
+	if seccompAuditCollector != nil {
+		seccompAuditCollector.Start()
+	}
+
+	if hostnameRefresher != nil {
+		hostnameRefresher.Start()
+	}
+
+	if networkVerifier != nil {
+		networkVerifier.Start()
+	}
+
 	close(ready)
 
 	logger.Info("started", lager.Data{
@@ -416,10 +621,34 @@ func (cmd *ServerCommand) Run(signals <-chan os.Signal, ready chan<- struct{}) e
 		"addr":    listenAddr,
 	})
 
-	<-signals
+	for signal := range signals {
+		if signal != syscall.SIGHUP {
+			break
+		}
+
+		if policyReloader != nil {
+			cmd.reloadGlobalPolicy(logger, policyReloader)
+		}
+	}
 
 	gardenServer.Stop()
 
+	if netOutLogCollector != nil {
+		netOutLogCollector.Stop()
+	}
+
+	if seccompAuditCollector != nil {
+		seccompAuditCollector.Stop()
+	}
+
+	if hostnameRefresher != nil {
+		hostnameRefresher.Stop()
+	}
+
+	if networkVerifier != nil {
+		networkVerifier.Stop()
+	}
+
 	cmd.saveProperties(logger, cmd.Containers.PropertiesPath, propManager)
 
 	portPoolState = portPool.RefreshState()
@@ -438,6 +667,190 @@ func (cmd *ServerCommand) loadProperties(logger lager.Logger, propertiesPath str
 	return propManager, nil
 }
 
+// blockIODefaults returns the block I/O weight and throttles applied to
+// containers which do not set their own
+func (cmd *ServerCommand) blockIODefaults() (gardener.BlockIOLimits, error) {
+	limits := gardener.BlockIOLimits{Weight: cmd.Limits.BlockIOWeight}
+	if limits.Weight != 0 && (limits.Weight < 10 || limits.Weight > 1000) {
+		return gardener.BlockIOLimits{}, fmt.Errorf("invalid --blkio-weight: %d", limits.Weight)
+	}
+
+	for _, throttle := range []struct {
+		flag   string
+		values []string
+		rates  *[]gardener.DeviceRate
+	}{
+		{"--blkio-read-bps", cmd.Limits.BlockIOReadBps, &limits.ReadBps},
+		{"--blkio-write-bps", cmd.Limits.BlockIOWriteBps, &limits.WriteBps},
+		{"--blkio-read-iops", cmd.Limits.BlockIOReadIOPS, &limits.ReadIOPS},
+		{"--blkio-write-iops", cmd.Limits.BlockIOWriteIOPS, &limits.WriteIOPS},
+	} {
+		rates, err := gardener.ParseDeviceRates(strings.Join(throttle.values, ","))
+		if err != nil {
+			return gardener.BlockIOLimits{}, fmt.Errorf("invalid %s: %s", throttle.flag, err)
+		}
+		*throttle.rates = rates
+	}
+
+	return limits, nil
+}
+
+func (cmd *ServerCommand) memoryDefaults() (gardener.MemoryOptions, error) {
+	defaults := gardener.MemoryOptions{
+		Reservation: cmd.Limits.DefaultMemoryReservation,
+		Swap:        cmd.Limits.DefaultMemorySwap,
+		Swappiness:  cmd.Limits.DefaultMemorySwappiness,
+		Kernel:      cmd.Limits.DefaultKernelMemory,
+		OOMScoreAdj: cmd.Limits.DefaultOOMScoreAdj,
+	}
+
+	// validate the defaults as the properties a container would set them with
+	if _, err := gardener.ParseMemoryOptions(defaults.Properties()); err != nil {
+		return gardener.MemoryOptions{}, err
+	}
+
+	return defaults, nil
+}
+
+// wireCPUAllocator returns the allocator of the --dedicated-cpus, if any, along
+// with the remaining CPUs, which other containers share
+func (cmd *ServerCommand) wireCPUAllocator() (gardener.CPUAllocator, string, error) {
+	if cmd.Limits.DedicatedCPUs == "" {
+		return nil, "", nil
+	}
+
+	dedicated, err := cpuset.Parse(cmd.Limits.DedicatedCPUs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	online, err := cpuset.OnlineCPUs("/sys")
+	if err != nil {
+		return nil, "", err
+	}
+
+	if offline := cpuset.Subtract(dedicated, online); len(offline) > 0 {
+		return nil, "", fmt.Errorf("dedicated cpus are not online: %s", cpuset.Format(offline))
+	}
+
+	shared := cpuset.Subtract(online, dedicated)
+	if len(shared) == 0 {
+		return nil, "", errors.New("cannot dedicate every cpu: containers need cpus to share")
+	}
+
+	topology, err := cpuset.LoadTopology("/sys")
+	if err != nil {
+		return nil, "", err
+	}
+
+	return cpuset.NewPool(dedicated, topology), cpuset.Format(shared), nil
+}
+
+// wireIDMappings returns the mappings of unprivileged containers' IDs onto
+// the configured host ranges, or none if no ranges are configured, and the
+// pool of per-container ranges, or nil if containers share the ranges
+func (cmd *ServerCommand) wireIDMappings() (gardener.IDMappings, *idmapping.Pool, error) {
+	uids, gids, err := cmd.idRanges()
+	if err != nil {
+		return gardener.IDMappings{}, nil, err
+	}
+
+	if uids.Length == 0 {
+		if cmd.Containers.PerContainerIDRangeSize > 0 {
+			return gardener.IDMappings{}, nil, errors.New("per-container id ranges need --subid-user or --uid-map-length and --gid-map-length")
+		}
+
+		return gardener.IDMappings{}, nil, nil
+	}
+
+	if !runningAsRoot() {
+		return gardener.IDMappings{}, nil, errors.New("id ranges can only be configured when running as root")
+	}
+
+	mappings := gardener.IDMappings{UIDMappings: uids.Mappings(), GIDMappings: gids.Mappings()}
+	if cmd.Containers.PerContainerIDRangeSize == 0 {
+		return mappings, nil, nil
+	}
+
+	// the built-in graph caches layers translated to a single set of mappings
+	if cmd.Image.Plugin.Path() == "" {
+		return gardener.IDMappings{}, nil, errors.New("per-container id ranges need an image plugin")
+	}
+
+	pool := idmapping.NewPool(uids, gids, cmd.Containers.PerContainerIDRangeSize)
+	if pool.Capacity() == 0 {
+		return gardener.IDMappings{}, nil, fmt.Errorf("id ranges are smaller than --per-container-id-range-size %d", cmd.Containers.PerContainerIDRangeSize)
+	}
+
+	return mappings, pool, nil
+}
+
+// idRanges returns the host UID and GID ranges onto which containers' IDs
+// are mapped, which are empty if none are configured
+func (cmd *ServerCommand) idRanges() (idmapping.Range, idmapping.Range, error) {
+	explicit := cmd.Containers.UIDMapLength > 0 || cmd.Containers.GIDMapLength > 0
+
+	if cmd.Containers.SubIDUser != "" {
+		if explicit {
+			return idmapping.Range{}, idmapping.Range{}, errors.New("cannot use both --subid-user and --uid-map-length or --gid-map-length")
+		}
+
+		uids, err := idmapping.LoadSubIDRange("/etc/subuid", cmd.Containers.SubIDUser)
+		if err != nil {
+			return idmapping.Range{}, idmapping.Range{}, err
+		}
+
+		gids, err := idmapping.LoadSubIDRange("/etc/subgid", cmd.Containers.SubIDUser)
+		if err != nil {
+			return idmapping.Range{}, idmapping.Range{}, err
+		}
+
+		return uids, gids, nil
+	}
+
+	if !explicit {
+		return idmapping.Range{}, idmapping.Range{}, nil
+	}
+
+	uids, err := idmapping.NewRange(cmd.Containers.UIDMapStart, cmd.Containers.UIDMapLength)
+	if err != nil {
+		return idmapping.Range{}, idmapping.Range{}, fmt.Errorf("uid map: %s", err)
+	}
+
+	gids, err := idmapping.NewRange(cmd.Containers.GIDMapStart, cmd.Containers.GIDMapLength)
+	if err != nil {
+		return idmapping.Range{}, idmapping.Range{}, fmt.Errorf("gid map: %s", err)
+	}
+
+	return uids, gids, nil
+}
+
+// orDefaultIDMappings returns the given mappings, or the default mappings of
+// container root onto the largest host ID and of other IDs onto themselves
+func orDefaultIDMappings(mappings gardener.IDMappings) gardener.IDMappings {
+	if len(mappings.UIDMappings) == 0 {
+		return gardener.IDMappings{UIDMappings: idMappings, GIDMappings: idMappings}
+	}
+
+	return mappings
+}
+
+// loadSecurityProfiles returns the security profiles which containers may
+// select, or none if --security-profiles is not given
+func (cmd *ServerCommand) loadSecurityProfiles(logger lager.Logger) (bundlerules.SecurityProfiles, error) {
+	if cmd.Containers.SecurityProfiles == "" {
+		return bundlerules.SecurityProfiles{}, nil
+	}
+
+	securityProfiles, err := bundlerules.LoadSecurityProfiles(cmd.Containers.SecurityProfiles.Path())
+	if err != nil {
+		logger.Error("failed-to-load-security-profiles", err, lager.Data{"path": cmd.Containers.SecurityProfiles.Path()})
+		return bundlerules.SecurityProfiles{}, err
+	}
+
+	return securityProfiles, nil
+}
+
 func (cmd *ServerCommand) saveProperties(logger lager.Logger, propertiesPath string, propManager *properties.Manager) {
 	if propertiesPath != "" {
 		err := properties.Save(propertiesPath, propManager)
@@ -470,10 +883,10 @@ func extractIPs(ipflags []IPFlag) []net.IP {
 	return ips
 }
 
-func (cmd *ServerCommand) wireNetworker(log lager.Logger, propManager kawasaki.ConfigStore, portPool *ports.PortPool) (gardener.Networker, gardener.Starter, error) {
+func (cmd *ServerCommand) wireNetworker(log lager.Logger, propManager kawasaki.ConfigStore, portPool *ports.PortPool, containerizer gardener.Containerizer) (gardener.Networker, gardener.Starter, *kawasaki.HostnameRefresher, kawasaki.FirewallExplainer, error) {
 	externalIP, err := defaultExternalIP(cmd.Network.ExternalIP)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, nil, err
 	}
 
 	dnsServers := extractIPs(cmd.Network.DNSServers)
@@ -495,12 +908,17 @@ func (cmd *ServerCommand) wireNetworker(log lager.Logger, propManager kawasaki.C
 			cmd.Network.Plugin.Path(),
 			cmd.Network.PluginExtraArgs,
 		)
-		return externalNetworker, externalNetworker, nil
+		return externalNetworker, externalNetworker, nil, nil, nil
 	}
 
-	var denyNetworksList []string
-	for _, network := range cmd.Network.DenyNetworks {
-		denyNetworksList = append(denyNetworksList, network.String())
+	if !runningAsRoot() {
+		networker, err := cmd.wireUserspaceNetworker(log, propManager, portPool, externalIP)
+		return networker, networker, nil, nil, err
+	}
+
+	policy, err := cmd.globalPolicy()
+	if err != nil {
+		return nil, nil, nil, nil, err
 	}
 
 	interfacePrefix := fmt.Sprintf("w%s", cmd.Server.Tag)
@@ -512,38 +930,200 @@ func (cmd *ServerCommand) wireNetworker(log lager.Logger, propManager kawasaki.C
 	nonLoggingIptRunner := linux_command_runner.New()
 	ipTables := iptables.New(cmd.Bin.IPTables.Path(), cmd.Bin.IPTablesRestore.Path(), iptRunner, locksmith, chainPrefix)
 	nonLoggingIpTables := iptables.New(cmd.Bin.IPTables.Path(), cmd.Bin.IPTablesRestore.Path(), nonLoggingIptRunner, locksmith, chainPrefix)
-	ipTablesStarter := iptables.NewStarter(nonLoggingIpTables, cmd.Network.AllowHostAccess, interfacePrefix, denyNetworksList, cmd.Containers.DestroyContainersOnStartup, log)
+	externalIPs := append([]net.IP{externalIP}, extractIPs(cmd.Network.AdditionalExternalIPs)...)
+	ipTablesStarter := iptables.NewStarter(nonLoggingIpTables, policy.AllowHostAccess, interfacePrefix, policy.AllowNetworks, policy.DenyNetworks, externalIPs, cmd.Containers.DestroyContainersOnStartup, log)
 	ruleTranslator := iptables.NewRuleTranslator()
+	idChecker := factory.NewIDChecker(nonLoggingIpTables, interfacePrefix)
+
+	// containers may request an MTU of their own, up to that of the uplink
+	uplinkMtu, err := mtu.MTU(externalIP.String())
+	if err != nil {
+		if cmd.Network.Mtu == 0 {
+			return nil, nil, nil, nil, err
+		}
+
+		// the external IP may not be the host's, e.g. behind NAT
+		uplinkMtu = cmd.Network.Mtu
+	}
 
 	containerMtu := cmd.Network.Mtu
 	if containerMtu == 0 {
-		containerMtu, err = mtu.MTU(externalIP.String())
-		if err != nil {
-			return nil, nil, err
-		}
+		containerMtu = uplinkMtu
 	}
 
+	directNetwork, err := cmd.wireDirectNetwork()
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	firewallOpener := iptables.NewFirewallOpener(ruleTranslator, ipTables)
+	hostnameRefresher := kawasaki.NewHostnameRefresher(
+		log,
+		&dns.Resolver{ResolvConfPath: "/etc/resolv.conf", Timeout: 5 * time.Second},
+		firewallOpener,
+		clock.NewClock(),
+	)
+
 	networker := kawasaki.New(
 		kawasaki.SpecParserFunc(kawasaki.ParseSpec),
 		subnets.NewPool(cmd.Network.Pool.CIDR()),
-		kawasaki.NewConfigCreator(idGenerator, interfacePrefix, chainPrefix, externalIP, dnsServers, additionalDNSServers, containerMtu),
+		kawasaki.NewConfigCreator(idGenerator, idChecker, interfacePrefix, chainPrefix, externalIP, dnsServers, additionalDNSServers, containerMtu),
 		propManager,
-		factory.NewDefaultConfigurer(ipTables),
+		factory.NewDefaultConfigurer(ipTables, cmd.Network.NetOutLogGroup),
 		portPool,
 		iptables.NewPortForwarder(ipTables),
-		iptables.NewFirewallOpener(ruleTranslator, ipTables),
+		firewallOpener,
+		directNetwork,
+		hostnameRefresher,
+		externalIPs,
+		uplinkMtu,
+		func(log lager.Logger, handle string) (int, error) {
+			info, err := containerizer.Info(log, handle)
+			return info.Pid, err
+		},
 	)
 
-	return networker, ipTablesStarter, nil
+	return networker, ipTablesStarter, hostnameRefresher, iptables.NewExplainer(nonLoggingIpTables, net.InterfaceAddrs), nil
 }
 
-func (cmd *ServerCommand) wireVolumeCreator(logger lager.Logger, graphRoot string, insecureRegistries, persistentImages []string) gardener.VolumeCreator {
+// wireUserspaceNetworker networks containers without programming the
+// kernel, which a server not running as root cannot do. gdn itself is the
+// helper which serves sockets from containers' network namespaces.
+func (cmd *ServerCommand) wireUserspaceNetworker(log lager.Logger, propManager kawasaki.ConfigStore, portPool *ports.PortPool, externalIP net.IP) (*userspace.Networker, error) {
+	gdnPath, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	// the proxy dials from the host, so enforces the global policy itself
+	policy, err := cmd.globalPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	return userspace.New(
+		log.Session("userspace-networker"),
+		userspace.NewSocketOpener(linux_command_runner.New(), cmd.Bin.NSEnter, gdnPath, "netns-sockets"),
+		propManager,
+		portPool,
+		externalIP,
+		cmd.Network.UserspaceProxyPort,
+		policy,
+		(&net.Dialer{Timeout: 30 * time.Second}).Dial,
+		net.LookupIP,
+		net.InterfaceAddrs,
+	), nil
+}
+
+// globalPolicy returns the policy in the policy file, if there is one, and
+// otherwise the policy given by the command line flags
+func (cmd *ServerCommand) globalPolicy() (kawasaki.GlobalPolicy, error) {
+	if cmd.Network.PolicyFile != "" {
+		policy, err := kawasaki.LoadGlobalPolicy(cmd.Network.PolicyFile)
+		if err == nil || !os.IsNotExist(err) {
+			return policy, err
+		}
+	}
+
+	policy := kawasaki.GlobalPolicy{AllowHostAccess: cmd.Network.AllowHostAccess}
+	for _, network := range cmd.Network.AllowNetworks {
+		policy.AllowNetworks = append(policy.AllowNetworks, network.String())
+	}
+	for _, network := range cmd.Network.DenyNetworks {
+		policy.DenyNetworks = append(policy.DenyNetworks, network.String())
+	}
+
+	return policy, nil
+}
+
+func (cmd *ServerCommand) wireGlobalPolicyReloader() (*iptables.GlobalPolicyReloader, error) {
+	externalIP, err := defaultExternalIP(cmd.Network.ExternalIP)
+	if err != nil {
+		return nil, err
+	}
+
+	chainPrefix := fmt.Sprintf("w-%s-", cmd.Server.Tag)
+	ipTables := iptables.New(cmd.Bin.IPTables.Path(), cmd.Bin.IPTablesRestore.Path(), linux_command_runner.New(), &locksmithpkg.FileSystem{}, chainPrefix)
+	return iptables.NewGlobalPolicyReloader(ipTables, append([]net.IP{externalIP}, extractIPs(cmd.Network.AdditionalExternalIPs)...)), nil
+}
+
+// reloadGlobalPolicy re-applies the policy in the policy file or, without
+// one, the policy given by the command line flags, e.g. replacing one PUT to
+// the debug server
+func (cmd *ServerCommand) reloadGlobalPolicy(logger lager.Logger, reloader metrics.GlobalPolicyReloader) {
+	log := logger.Session("sighup")
+
+	policy, err := cmd.globalPolicy()
+	if err != nil {
+		log.Error("loading-policy-failed", err)
+		return
+	}
+
+	if err := reloader.Reload(log, policy); err != nil {
+		log.Error("reloading-policy-failed", err)
+	}
+}
+
+func (cmd *ServerCommand) wireDirectNetwork() (*kawasaki.DirectNetwork, error) {
+	if cmd.Network.DirectNetworkInterface == "" {
+		return nil, nil
+	}
+
+	subnet := cmd.Network.DirectNetworkSubnet.CIDR()
+	if subnet == nil {
+		return nil, errors.New("--direct-network-subnet is required when --direct-network-interface is given")
+	}
+
+	ipRange := cmd.Network.DirectNetworkRange.CIDR()
+	if ipRange == nil {
+		ipRange = subnet
+	}
+
+	if !subnet.Contains(ipRange.IP) {
+		return nil, fmt.Errorf("direct network range %s is not within subnet %s", ipRange, subnet)
+	}
+
+	gateway := cmd.Network.DirectNetworkGateway.IP()
+	if gateway == nil {
+		gateway = subnets.GatewayIP(subnet)
+	}
+
+	pool := subnets.NewPool(ipRange)
+	if ipRange.Contains(gateway) {
+		// never hand out the gateway's address to a container
+		if err := pool.Remove(ipRange, gateway); err != nil {
+			return nil, err
+		}
+	}
+
+	return &kawasaki.DirectNetwork{
+		Interface: cmd.Network.DirectNetworkInterface,
+		Subnet:    subnet,
+		Gateway:   gateway,
+		Range:     ipRange,
+		Pool:      pool,
+	}, nil
+}
+
+func (cmd *ServerCommand) wireNetOutLogCollector(logger lager.Logger, propManager kawasaki.ConfigStore, handles func() ([]string, error)) *nflog.Collector {
+	socket, err := nflog.Listen(cmd.Network.NetOutLogGroup)
+	if err != nil {
+		logger.Error("failed-to-listen-for-netout-logs", err, lager.Data{"group": cmd.Network.NetOutLogGroup})
+		return nil
+	}
+
+	limiter := nflog.NewRateLimiter(clock.NewClock(), cmd.Network.NetOutLogRateLimit, time.Second)
+	resolver := kawasaki.InstanceHandleResolver{ConfigStore: propManager, Handles: handles}
+	return nflog.NewCollector(logger, socket, resolver, limiter)
+}
+
+func (cmd *ServerCommand) wireVolumeCreator(logger lager.Logger, graphRoot string, insecureRegistries, persistentImages []string, idMappings gardener.IDMappings, idPool *idmapping.Pool) gardener.VolumeCreator {
 	if graphRoot == "" {
 		return gardener.NoopVolumeCreator{}
 	}
 
 	if cmd.Image.Plugin.Path() != "" || cmd.Image.PrivilegedPlugin.Path() != "" {
-		return cmd.wireImagePlugin()
+		return cmd.wireImagePlugin(idMappings, idPool)
 	}
 
 	logger = logger.Session("volume-creator", lager.Data{"graphRoot": graphRoot})
@@ -616,10 +1196,11 @@ func (cmd *ServerCommand) wireVolumeCreator(logger lager.Logger, graphRoot strin
 		Logger: logger,
 	}
 
+	namespacedIDMappings := orDefaultIDMappings(idMappings)
 	rootFSNamespacer := &rootfs_provider.UidNamespacer{
 		Translator: rootfs_provider.NewUidTranslator(
-			idMappings, // uid
-			idMappings, // gid
+			namespacedIDMappings.UIDMappings,
+			namespacedIDMappings.GIDMappings,
 		),
 	}
 
@@ -659,7 +1240,7 @@ func (cmd *ServerCommand) wireVolumeCreator(logger lager.Logger, graphRoot strin
 		ovenCleaner)
 }
 
-func (cmd *ServerCommand) wireImagePlugin() gardener.VolumeCreator {
+func (cmd *ServerCommand) wireImagePlugin(idMappings gardener.IDMappings, idPool *idmapping.Pool) gardener.VolumeCreator {
 	var unprivilegedCommandCreator imageplugin.CommandCreator = &imageplugin.NotImplementedCommandCreator{
 		Err: errors.New("no image_plugin provided"),
 	}
@@ -669,10 +1250,15 @@ func (cmd *ServerCommand) wireImagePlugin() gardener.VolumeCreator {
 	}
 
 	if cmd.Image.Plugin.Path() != "" {
-		unprivilegedCommandCreator = &imageplugin.DefaultCommandCreator{
-			BinPath:   cmd.Image.Plugin.Path(),
-			ExtraArgs: cmd.Image.PluginExtraArgs,
+		defaultCommandCreator := &imageplugin.DefaultCommandCreator{
+			BinPath:    cmd.Image.Plugin.Path(),
+			ExtraArgs:  cmd.Image.PluginExtraArgs,
+			IDMappings: idMappings,
+		}
+		if idPool != nil {
+			defaultCommandCreator.ContainerIDMappings = idPool
 		}
+		unprivilegedCommandCreator = defaultCommandCreator
 	}
 
 	if cmd.Image.PrivilegedPlugin.Path() != "" {
@@ -690,7 +1276,7 @@ func (cmd *ServerCommand) wireImagePlugin() gardener.VolumeCreator {
 	}
 }
 
-func (cmd *ServerCommand) wireContainerizer(log lager.Logger, depotPath, dadooPath, runcPath, nstarPath, tarPath, appArmorProfile string, properties gardener.PropertyManager) *rundmc.Containerizer {
+func (cmd *ServerCommand) wireContainerizer(log lager.Logger, depotPath, dadooPath, runcPath, nstarPath, tarPath, appArmorProfile string, seccomp *specs.LinuxSeccomp, securityProfiles bundlerules.SecurityProfiles, extraDevices, passthroughDevices []specs.LinuxDevice, blockIODefaults gardener.BlockIOLimits, sharedCPUs string, memoryDefaults gardener.MemoryOptions, unifiedCgroups bool, idMappings gardener.IDMappings, properties gardener.PropertyManager) *rundmc.Containerizer {
 	depot := depot.New(depotPath)
 
 	commandRunner := linux_command_runner.New()
@@ -705,6 +1291,14 @@ func (cmd *ServerCommand) wireContainerizer(log lager.Logger, depotPath, dadooPa
 		SleepInterval: time.Millisecond * 100,
 	}
 
+	// containers networked in userspace can only reach other hosts through
+	// their outbound proxy
+	var defaultEnv []string
+	if cmd.Network.Plugin.Path() == "" && !runningAsRoot() {
+		defaultEnv = userspace.ProxyEnv(cmd.Network.UserspaceProxyPort)
+	}
+
+	cgroupPathResolver := stopper.NewRuncStateCgroupPathResolver("/run/runc")
 	runcrunner := runrunc.New(
 		commandRunner,
 		runrunc.NewLogRunner(commandRunner, runrunc.LogDir(os.TempDir()).GenerateLogFile),
@@ -718,6 +1312,7 @@ func (cmd *ServerCommand) wireContainerizer(log lager.Logger, depotPath, dadooPa
 			cmd.wireUidGenerator(),
 			pidFileReader,
 			linux_command_runner.New()),
+		cgroupPathResolver,
 	)
 
 	mounts := []specs.Mount{
@@ -763,6 +1358,10 @@ func (cmd *ServerCommand) wireContainerizer(log lager.Logger, depotPath, dadooPa
 		{Access: &rwm, Type: &character, Major: majorMinor(fuseDevice.Major), Minor: majorMinor(fuseDevice.Minor), Allow: true},
 	}
 
+	for _, device := range extraDevices {
+		allowedDevices = append(allowedDevices, bundlerules.DeviceCgroupRule(device))
+	}
+
 	baseProcess := specs.Process{
 		Capabilities: UnprivilegedMaxCaps,
 		Args:         []string{"/tmp/garden-init"},
@@ -773,13 +1372,13 @@ func (cmd *ServerCommand) wireContainerizer(log lager.Logger, depotPath, dadooPa
 		WithNamespaces(PrivilegedContainerNamespaces...).
 		WithResources(&specs.LinuxResources{Devices: append([]specs.LinuxDeviceCgroup{denyAll}, allowedDevices...)}).
 		WithRootFS(cmd.Containers.DefaultRootFS).
-		WithDevices(fuseDevice).
+		WithDevices(append([]specs.LinuxDevice{fuseDevice}, extraDevices...)...).
 		WithProcess(baseProcess)
 
 	unprivilegedBundle := baseBundle.
 		WithNamespace(goci.UserNamespace).
-		WithUIDMappings(idMappings...).
-		WithGIDMappings(idMappings...).
+		WithUIDMappings(idMappings.UIDMappings...).
+		WithGIDMappings(idMappings.GIDMappings...).
 		WithMounts(unprivilegedMounts...).
 		WithMaskedPaths(defaultMaskedPaths())
 
@@ -795,10 +1394,12 @@ func (cmd *ServerCommand) wireContainerizer(log lager.Logger, depotPath, dadooPa
 	if !runningAsRoot() {
 		unprivilegedBundle = unprivilegedBundle.
 			WithResources(&specs.LinuxResources{}).
-			WithUIDMappings(idMappings[0]).
-			WithGIDMappings(idMappings[0])
+			WithUIDMappings(idMappings.UIDMappings[0]).
+			WithGIDMappings(idMappings.GIDMappings[0])
 	}
 
+	blockIO := bundlerules.BlockIO{Defaults: blockIODefaults}
+
 	template := &rundmc.BundleTemplate{
 		Rules: []rundmc.BundlerRule{
 			bundlerules.Base{
@@ -806,16 +1407,24 @@ func (cmd *ServerCommand) wireContainerizer(log lager.Logger, depotPath, dadooPa
 				UnprivilegedBase: unprivilegedBundle,
 			},
 			bundlerules.RootFS{
-				ContainerRootUID: idMappings.Map(0),
-				ContainerRootGID: idMappings.Map(0),
+				ContainerRootUID: idMappings.UIDMappings.Map(0),
+				ContainerRootGID: idMappings.GIDMappings.Map(0),
 				MkdirChown:       chrootMkdir,
 			},
 			bundlerules.Limits{
 				CpuQuotaPerShare: cmd.Limits.CpuQuotaPerShare,
+				SharedCPUs:       sharedCPUs,
+				MemoryDefaults:   memoryDefaults,
+				UnifiedCgroups:   unifiedCgroups,
 			},
+			blockIO,
 			bundlerules.BindMounts{},
-			bundlerules.Env{},
+			bundlerules.Env{Defaults: defaultEnv},
 			bundlerules.Hostname{},
+			bundlerules.NetworkNamespace{},
+			bundlerules.Sysctls{},
+			securityProfiles,
+			bundlerules.Devices{Passthrough: passthroughDevices},
 		},
 	}
 
@@ -828,17 +1437,26 @@ func (cmd *ServerCommand) wireContainerizer(log lager.Logger, depotPath, dadooPa
 	stateStore := rundmc.NewStateStore(properties)
 
 	nstar := rundmc.NewNstarRunner(nstarPath, tarPath, linux_command_runner.New())
-	stopper := stopper.New(stopper.NewRuncStateCgroupPathResolver("/run/runc"), nil, retrier.New(retrier.ConstantBackoff(10, 1*time.Second), nil))
-	return rundmc.New(depot, template, runcrunner, &goci.BndlLoader{}, nstar, stopper, eventStore, stateStore)
+	stopper := stopper.New(cgroupPathResolver, nil, retrier.New(retrier.ConstantBackoff(10, 1*time.Second), nil))
+	return rundmc.New(depot, template, runcrunner, &goci.BndlLoader{}, nstar, stopper, eventStore, stateStore, blockIO)
 }
 
-func (cmd *ServerCommand) wireMetricsProvider(log lager.Logger, depotPath, graphRoot string) metrics.Metrics {
+func (cmd *ServerCommand) wireMetricsProvider(log lager.Logger, depotPath, graphRoot string, portPool metrics.PortPool) metrics.Metrics {
 	var backingStoresPath string
 	if graphRoot != "" {
 		backingStoresPath = filepath.Join(graphRoot, "backing_stores")
 	}
 
-	return metrics.NewMetrics(log, backingStoresPath, depotPath)
+	// connection limits are only enforced by the built-in networker
+	var limitCounter metrics.ConnectionLimitCounter
+	if cmd.Network.Plugin.Path() == "" {
+		chainPrefix := fmt.Sprintf("w-%s-", cmd.Server.Tag)
+		limitCounter = iptables.NewConnectionLimitCounter(
+			iptables.New(cmd.Bin.IPTables.Path(), cmd.Bin.IPTablesRestore.Path(), linux_command_runner.New(), &locksmithpkg.FileSystem{}, chainPrefix),
+		)
+	}
+
+	return metrics.NewMetrics(log, backingStoresPath, depotPath, portPool, limitCounter)
 }
 
 func (cmd *ServerCommand) wireMetronNotifier(log lager.Logger, metricsProvider metrics.Metrics) *metrics.PeriodicMetronNotifier {
@@ -884,6 +1502,16 @@ func mustStringify(s interface{}, e error) string {
 	return fmt.Sprintf("%s", s)
 }
 
+func isUnifiedCgroupHierarchy() (bool, error) {
+	procSelfCgroup, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		return false, err
+	}
+	defer procSelfCgroup.Close()
+
+	return rundmc.IsUnifiedCgroupHierarchy(procSelfCgroup)
+}
+
 func mustOpen(path string) io.ReadCloser {
 	if r, err := os.Open(path); err != nil {
 		panic(err)
@@ -0,0 +1,58 @@
+package guardiancmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+)
+
+// ExplainCommand asks a running server's debug endpoint whether traffic from a
+// container would be allowed by its firewall rules, and by which rule
+type ExplainCommand struct {
+	DebugAddress string `long:"debug-address" default:"127.0.0.1:17013" description:"Address of the server's debug endpoint, as given by --debug-bind-ip and --debug-bind-port."`
+
+	Handle      string `long:"handle"      required:"true" description:"Handle of the container the traffic is from."`
+	Destination IPFlag `long:"destination" required:"true" description:"Destination IP of the traffic."`
+	Port        uint16 `long:"port"                        description:"Destination port of TCP or UDP traffic."`
+	Protocol    string `long:"protocol"    default:"tcp"   description:"Protocol of the traffic: tcp, udp, icmp (an echo request) or all."`
+}
+
+func (cmd *ExplainCommand) Execute(args []string) error {
+	query := url.Values{}
+	query.Set("handle", cmd.Handle)
+	query.Set("destination", cmd.Destination.IP().String())
+	query.Set("port", strconv.Itoa(int(cmd.Port)))
+	query.Set("protocol", cmd.Protocol)
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/explain?%s", cmd.DebugAddress, query.Encode()))
+	if err != nil {
+		return fmt.Errorf("contacting debug endpoint: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("explaining: %s", body)
+	}
+
+	var explanation kawasaki.Explanation
+	if err := json.NewDecoder(resp.Body).Decode(&explanation); err != nil {
+		return fmt.Errorf("decoding explanation: %s", err)
+	}
+
+	fmt.Fprintf(os.Stdout, "allowed: %t\n", explanation.Allowed)
+	fmt.Fprintf(os.Stdout, "reason: %s\n", explanation.Reason)
+	fmt.Fprintf(os.Stdout, "chain: %s\n", explanation.Chain)
+	if explanation.Rule != "" {
+		fmt.Fprintf(os.Stdout, "rule: %s\n", explanation.Rule)
+	}
+	fmt.Fprintf(os.Stdout, "logged: %t\n", explanation.Logged)
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package guardiancmd
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"code.cloudfoundry.org/guardian/kawasaki/userspace"
+)
+
+// NetnsSocketsCommand is run by the server, when not running as root, in the
+// user and network namespaces of a container. It serves sockets in the
+// container's network namespace to the server, over the connection given as
+// fd 3, until the server closes it.
+type NetnsSocketsCommand struct{}
+
+func (cmd *NetnsSocketsCommand) Execute(args []string) error {
+	conn, err := net.FileConn(os.NewFile(3, "sockets"))
+	if err != nil {
+		return fmt.Errorf("opening connection to server: %s", err)
+	}
+
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return fmt.Errorf("connection to server is not a unix socket: %T", conn)
+	}
+	defer unixConn.Close()
+
+	return userspace.ServeSockets(unixConn)
+}
@@ -0,0 +1,45 @@
+package guardiancmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/guardian/kawasaki/ports"
+)
+
+// PortRangeFlag is an inclusive range of ports, e.g. "61000-61999"
+type PortRangeFlag struct {
+	start uint32
+	end   uint32
+}
+
+func (f *PortRangeFlag) UnmarshalFlag(value string) error {
+	parts := strings.SplitN(value, "-", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid port range: '%s'", value)
+	}
+
+	start, err := strconv.ParseUint(parts[0], 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid port range: '%s'", value)
+	}
+
+	end, err := strconv.ParseUint(parts[1], 10, 16)
+	if err != nil || end < start {
+		return fmt.Errorf("invalid port range: '%s'", value)
+	}
+
+	f.start = uint32(start)
+	f.end = uint32(end)
+
+	return nil
+}
+
+func (f PortRangeFlag) String() string {
+	return fmt.Sprintf("%d-%d", f.start, f.end)
+}
+
+func (f PortRangeFlag) Range() ports.Range {
+	return ports.Range{Start: f.start, Size: f.end - f.start + 1}
+}
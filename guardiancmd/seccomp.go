@@ -1,1597 +1,451 @@
 package guardiancmd
 
-import "github.com/opencontainers/runtime-spec/specs-go"
+import (
+	"runtime"
 
-var seccomp = &specs.LinuxSeccomp{
-	DefaultAction: specs.ActErrno,
-	Architectures: []specs.Arch{
-		specs.ArchX86_64,
-		specs.ArchX86,
-		specs.ArchX32,
-	},
-	Syscalls: []specs.LinuxSyscall{
-		specs.LinuxSyscall{
-			Name:   "accept",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "accept4",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "access",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "alarm",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "bind",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "brk",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "capget",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "capset",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "chdir",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "chmod",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "chown",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "chown32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "clock_getres",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "clock_gettime",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "clock_nanosleep",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "close",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "connect",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "copy_file_range",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "creat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "dup",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "dup2",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "dup3",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "epoll_create",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "epoll_create1",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "epoll_ctl",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "epoll_ctl_old",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "epoll_pwait",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "epoll_wait",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "epoll_wait_old",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "eventfd",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "eventfd2",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "execve",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "execveat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "exit",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "exit_group",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "faccessat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fadvise64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fadvise64_64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fallocate",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fanotify_mark",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fchdir",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fchmod",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fchmodat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fchown",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fchown32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fchownat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fcntl",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fcntl64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fdatasync",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fgetxattr",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "flistxattr",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "flock",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fork",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fremovexattr",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fsetxattr",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fstat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fstat64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fstatat64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fstatfs",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fstatfs64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "fsync",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "ftruncate",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "ftruncate64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "futex",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "futimesat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getcpu",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getcwd",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getdents",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getdents64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getegid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getegid32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "geteuid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "geteuid32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getgid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getgid32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getgroups",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getgroups32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getitimer",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getpeername",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getpgid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getpgrp",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getpid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getppid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getpriority",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getrandom",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getresgid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getresgid32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getresuid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getresuid32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getrlimit",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "get_robust_list",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getrusage",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getsid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getsockname",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getsockopt",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "get_thread_area",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "gettid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "gettimeofday",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getuid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getuid32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "getxattr",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "inotify_add_watch",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "inotify_init",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "inotify_init1",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "inotify_rm_watch",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "io_cancel",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "ioctl",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "io_destroy",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "io_getevents",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "ioprio_get",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "ioprio_set",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "io_setup",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "io_submit",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "ipc",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "kill",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "lchown",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "lchown32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "lgetxattr",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "link",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "linkat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "listen",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "listxattr",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "llistxattr",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "_llseek",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "lremovexattr",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "lseek",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "lsetxattr",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "lstat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "lstat64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "madvise",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "memfd_create",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mincore",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mkdir",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mkdirat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mknod",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mknodat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mlock",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mlock2",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mlockall",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mmap",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mmap2",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mprotect",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mq_getsetattr",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mq_notify",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mq_open",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mq_timedreceive",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mq_timedsend",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mq_unlink",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "mremap",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "msgctl",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "msgget",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "msgrcv",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "msgsnd",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "msync",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "munlock",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "munlockall",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "munmap",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "nanosleep",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "newfstatat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "_newselect",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "open",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "openat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "pause",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "personality",
-			Action: specs.ActAllow,
-			Args: []specs.LinuxSeccompArg{
-				specs.LinuxSeccompArg{
-					Index:    0,
-					Value:    0,
-					ValueTwo: 0,
-					Op:       specs.OpEqualTo,
-				},
-			},
-		},
-		specs.LinuxSyscall{
-			Name:   "personality",
-			Action: specs.ActAllow,
-			Args: []specs.LinuxSeccompArg{
-				specs.LinuxSeccompArg{
-					Index:    0,
-					Value:    8,
-					ValueTwo: 0,
-					Op:       specs.OpEqualTo,
-				},
-			},
-		},
-		specs.LinuxSyscall{
-			Name:   "personality",
-			Action: specs.ActAllow,
-			Args: []specs.LinuxSeccompArg{
-				specs.LinuxSeccompArg{
-					Index:    0,
-					Value:    4294967295,
-					ValueTwo: 0,
-					Op:       specs.OpEqualTo,
-				},
-			},
-		},
-		specs.LinuxSyscall{
-			Name:   "pipe",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "pipe2",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "poll",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "ppoll",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "prctl",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "pread64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "preadv",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "prlimit64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "pselect6",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "pwrite64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "pwritev",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "read",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "readahead",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "readlink",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "readlinkat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "readv",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "recv",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "recvfrom",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "recvmmsg",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "recvmsg",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "remap_file_pages",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "removexattr",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "rename",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "renameat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "renameat2",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "restart_syscall",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "rmdir",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "rt_sigaction",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "rt_sigpending",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "rt_sigprocmask",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "rt_sigqueueinfo",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "rt_sigreturn",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "rt_sigsuspend",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "rt_sigtimedwait",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "rt_tgsigqueueinfo",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sched_getaffinity",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sched_getattr",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sched_getparam",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sched_get_priority_max",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sched_get_priority_min",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sched_getscheduler",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sched_rr_get_interval",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sched_setaffinity",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sched_setattr",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sched_setparam",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sched_setscheduler",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sched_yield",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "seccomp",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "select",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "semctl",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "semget",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "semop",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "semtimedop",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "send",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sendfile",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sendfile64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sendmmsg",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sendmsg",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sendto",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setfsgid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setfsgid32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setfsuid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setfsuid32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setgid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setgid32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setgroups",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setgroups32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setitimer",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setpgid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setpriority",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setregid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setregid32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setresgid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setresgid32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setresuid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setresuid32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setreuid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setreuid32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setrlimit",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "set_robust_list",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setsid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setsockopt",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "set_thread_area",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "set_tid_address",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setuid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setuid32",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "setxattr",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "shmat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "shmctl",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "shmdt",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "shmget",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "shutdown",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sigaltstack",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "signalfd",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "signalfd4",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sigreturn",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "socket",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "socketcall",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "socketpair",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "splice",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "stat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "stat64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "statfs",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "statfs64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "symlink",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "symlinkat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sync",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sync_file_range",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "syncfs",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "sysinfo",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "syslog",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "tee",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "tgkill",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "time",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "timer_create",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "timer_delete",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "timerfd_create",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "timerfd_gettime",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "timerfd_settime",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "timer_getoverrun",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "timer_gettime",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "timer_settime",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "times",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "tkill",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "truncate",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "truncate64",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "ugetrlimit",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "umask",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "uname",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "unlink",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "unlinkat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "utime",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "utimensat",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "utimes",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "vfork",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "vmsplice",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "wait4",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "waitid",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
-		},
-		specs.LinuxSyscall{
-			Name:   "waitpid",
+	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
+	"code.cloudfoundry.org/guardian/rundmc/seccomp"
+	"code.cloudfoundry.org/lager"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// cloneNamespaceFlags are the CLONE_NEW* flags, which unprivileged containers
+// may not pass to clone
+const cloneNamespaceFlags = 2080505856
+
+// defaultSeccompProfile is used for unprivileged containers unless
+// --seccomp-profile is given. Its architectures are those of the server.
+var defaultSeccompProfile = seccomp.Profile{
+	DefaultAction: specs.ActErrno,
+	Syscalls: []seccomp.Syscall{
+		{
+			Names: []string{
+				"accept",
+				"accept4",
+				"access",
+				"alarm",
+				"bind",
+				"brk",
+				"capget",
+				"capset",
+				"chdir",
+				"chmod",
+				"chown",
+				"chown32",
+				"clock_getres",
+				"clock_gettime",
+				"clock_nanosleep",
+				"close",
+				"connect",
+				"copy_file_range",
+				"creat",
+				"dup",
+				"dup2",
+				"dup3",
+				"epoll_create",
+				"epoll_create1",
+				"epoll_ctl",
+				"epoll_ctl_old",
+				"epoll_pwait",
+				"epoll_wait",
+				"epoll_wait_old",
+				"eventfd",
+				"eventfd2",
+				"execve",
+				"execveat",
+				"exit",
+				"exit_group",
+				"faccessat",
+				"fadvise64",
+				"fadvise64_64",
+				"fallocate",
+				"fanotify_mark",
+				"fchdir",
+				"fchmod",
+				"fchmodat",
+				"fchown",
+				"fchown32",
+				"fchownat",
+				"fcntl",
+				"fcntl64",
+				"fdatasync",
+				"fgetxattr",
+				"flistxattr",
+				"flock",
+				"fork",
+				"fremovexattr",
+				"fsetxattr",
+				"fstat",
+				"fstat64",
+				"fstatat64",
+				"fstatfs",
+				"fstatfs64",
+				"fsync",
+				"ftruncate",
+				"ftruncate64",
+				"futex",
+				"futimesat",
+				"getcpu",
+				"getcwd",
+				"getdents",
+				"getdents64",
+				"getegid",
+				"getegid32",
+				"geteuid",
+				"geteuid32",
+				"getgid",
+				"getgid32",
+				"getgroups",
+				"getgroups32",
+				"getitimer",
+				"getpeername",
+				"getpgid",
+				"getpgrp",
+				"getpid",
+				"getppid",
+				"getpriority",
+				"getrandom",
+				"getresgid",
+				"getresgid32",
+				"getresuid",
+				"getresuid32",
+				"getrlimit",
+				"get_robust_list",
+				"getrusage",
+				"getsid",
+				"getsockname",
+				"getsockopt",
+				"get_thread_area",
+				"gettid",
+				"gettimeofday",
+				"getuid",
+				"getuid32",
+				"getxattr",
+				"inotify_add_watch",
+				"inotify_init",
+				"inotify_init1",
+				"inotify_rm_watch",
+				"io_cancel",
+				"ioctl",
+				"io_destroy",
+				"io_getevents",
+				"ioprio_get",
+				"ioprio_set",
+				"io_setup",
+				"io_submit",
+				"ipc",
+				"kill",
+				"lchown",
+				"lchown32",
+				"lgetxattr",
+				"link",
+				"linkat",
+				"listen",
+				"listxattr",
+				"llistxattr",
+				"_llseek",
+				"lremovexattr",
+				"lseek",
+				"lsetxattr",
+				"lstat",
+				"lstat64",
+				"madvise",
+				"memfd_create",
+				"mincore",
+				"mkdir",
+				"mkdirat",
+				"mknod",
+				"mknodat",
+				"mlock",
+				"mlock2",
+				"mlockall",
+				"mmap",
+				"mmap2",
+				"mprotect",
+				"mq_getsetattr",
+				"mq_notify",
+				"mq_open",
+				"mq_timedreceive",
+				"mq_timedsend",
+				"mq_unlink",
+				"mremap",
+				"msgctl",
+				"msgget",
+				"msgrcv",
+				"msgsnd",
+				"msync",
+				"munlock",
+				"munlockall",
+				"munmap",
+				"nanosleep",
+				"newfstatat",
+				"_newselect",
+				"open",
+				"openat",
+				"pause",
+				"pipe",
+				"pipe2",
+				"poll",
+				"ppoll",
+				"prctl",
+				"pread64",
+				"preadv",
+				"prlimit64",
+				"pselect6",
+				"pwrite64",
+				"pwritev",
+				"read",
+				"readahead",
+				"readlink",
+				"readlinkat",
+				"readv",
+				"recv",
+				"recvfrom",
+				"recvmmsg",
+				"recvmsg",
+				"remap_file_pages",
+				"removexattr",
+				"rename",
+				"renameat",
+				"renameat2",
+				"restart_syscall",
+				"rmdir",
+				"rt_sigaction",
+				"rt_sigpending",
+				"rt_sigprocmask",
+				"rt_sigqueueinfo",
+				"rt_sigreturn",
+				"rt_sigsuspend",
+				"rt_sigtimedwait",
+				"rt_tgsigqueueinfo",
+				"sched_getaffinity",
+				"sched_getattr",
+				"sched_getparam",
+				"sched_get_priority_max",
+				"sched_get_priority_min",
+				"sched_getscheduler",
+				"sched_rr_get_interval",
+				"sched_setaffinity",
+				"sched_setattr",
+				"sched_setparam",
+				"sched_setscheduler",
+				"sched_yield",
+				"seccomp",
+				"select",
+				"semctl",
+				"semget",
+				"semop",
+				"semtimedop",
+				"send",
+				"sendfile",
+				"sendfile64",
+				"sendmmsg",
+				"sendmsg",
+				"sendto",
+				"setfsgid",
+				"setfsgid32",
+				"setfsuid",
+				"setfsuid32",
+				"setgid",
+				"setgid32",
+				"setgroups",
+				"setgroups32",
+				"setitimer",
+				"setpgid",
+				"setpriority",
+				"setregid",
+				"setregid32",
+				"setresgid",
+				"setresgid32",
+				"setresuid",
+				"setresuid32",
+				"setreuid",
+				"setreuid32",
+				"setrlimit",
+				"set_robust_list",
+				"setsid",
+				"setsockopt",
+				"set_thread_area",
+				"set_tid_address",
+				"setuid",
+				"setuid32",
+				"setxattr",
+				"shmat",
+				"shmctl",
+				"shmdt",
+				"shmget",
+				"shutdown",
+				"sigaltstack",
+				"signalfd",
+				"signalfd4",
+				"sigreturn",
+				"socket",
+				"socketcall",
+				"socketpair",
+				"splice",
+				"stat",
+				"stat64",
+				"statfs",
+				"statfs64",
+				"symlink",
+				"symlinkat",
+				"sync",
+				"sync_file_range",
+				"syncfs",
+				"sysinfo",
+				"syslog",
+				"tee",
+				"tgkill",
+				"time",
+				"timer_create",
+				"timer_delete",
+				"timerfd_create",
+				"timerfd_gettime",
+				"timerfd_settime",
+				"timer_getoverrun",
+				"timer_gettime",
+				"timer_settime",
+				"times",
+				"tkill",
+				"truncate",
+				"truncate64",
+				"ugetrlimit",
+				"umask",
+				"uname",
+				"unlink",
+				"unlinkat",
+				"utime",
+				"utimensat",
+				"utimes",
+				"vfork",
+				"vmsplice",
+				"wait4",
+				"waitid",
+				"waitpid",
+				"write",
+				"writev",
+				"chroot",
+			},
 			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
 		},
-		specs.LinuxSyscall{
-			Name:   "write",
+		{
+			Names:  []string{"personality"},
 			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
+			Args: []specs.LinuxSeccompArg{
+				{Index: 0, Value: 0, Op: specs.OpEqualTo},
+			},
 		},
-		specs.LinuxSyscall{
-			Name:   "writev",
+		{
+			Names:  []string{"personality"},
 			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
+			Args: []specs.LinuxSeccompArg{
+				{Index: 0, Value: 8, Op: specs.OpEqualTo},
+			},
 		},
-		specs.LinuxSyscall{
-			Name:   "arch_prctl",
+		{
+			Names:  []string{"personality"},
 			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
+			Args: []specs.LinuxSeccompArg{
+				{Index: 0, Value: 4294967295, Op: specs.OpEqualTo},
+			},
 		},
-		specs.LinuxSyscall{
-			Name:   "modify_ldt",
-			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
+		{
+			Names:    []string{"arch_prctl", "modify_ldt"},
+			Action:   specs.ActAllow,
+			Includes: seccomp.Filter{Arches: []string{"amd64", "386"}},
+		},
+		{
+			Names: []string{
+				"arm_fadvise64_64",
+				"arm_sync_file_range",
+				"sync_file_range2",
+				"breakpoint",
+				"cacheflush",
+				"set_tls",
+			},
+			Action:   specs.ActAllow,
+			Includes: seccomp.Filter{Arches: []string{"arm", "arm64"}},
+		},
+		{
+			Names:    []string{"sync_file_range2"},
+			Action:   specs.ActAllow,
+			Includes: seccomp.Filter{Arches: []string{"ppc64le"}},
+		},
+		{
+			Names: []string{
+				"s390_pci_mmio_read",
+				"s390_pci_mmio_write",
+				"s390_runtime_instr",
+			},
+			Action:   specs.ActAllow,
+			Includes: seccomp.Filter{Arches: []string{"s390x"}},
 		},
-		specs.LinuxSyscall{
-			Name:   "chroot",
+		{
+			Names:  []string{"clone"},
 			Action: specs.ActAllow,
-			Args:   []specs.LinuxSeccompArg{},
+			Args: []specs.LinuxSeccompArg{
+				{Index: 0, Value: cloneNamespaceFlags, Op: specs.OpMaskedEqual},
+			},
+			Excludes: seccomp.Filter{Arches: []string{"s390x"}},
 		},
-		specs.LinuxSyscall{
-			Name:   "clone",
+		// the flags are the second argument of clone on s390x
+		{
+			Names:  []string{"clone"},
 			Action: specs.ActAllow,
 			Args: []specs.LinuxSeccompArg{
-				specs.LinuxSeccompArg{
-					Index:    0,
-					Value:    2080505856,
-					ValueTwo: 0,
-					Op:       specs.OpMaskedEqual,
-				},
+				{Index: 1, Value: cloneNamespaceFlags, Op: specs.OpMaskedEqual},
 			},
+			Includes: seccomp.Filter{Arches: []string{"s390x"}},
 		},
 	},
 }
+
+// loadSeccomp returns the seccomp configuration of unprivileged containers on
+// this server, from --seccomp-profile if given or the default profile
+func (cmd *ServerCommand) loadSeccomp(log lager.Logger) (*specs.LinuxSeccomp, error) {
+	profile := defaultSeccompProfile
+	if cmd.Containers.SeccompProfile != "" {
+		var err error
+		profile, err = seccomp.Load(cmd.Containers.SeccompProfile.Path())
+		if err != nil {
+			return nil, err
+		}
+
+		log.Info("loaded-seccomp-profile", lager.Data{"path": cmd.Containers.SeccompProfile.Path()})
+	}
+
+	spec, err := profile.Spec(runtime.GOARCH, UnprivilegedMaxCaps)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Containers.SeccompAudit {
+		log.Info("auditing-seccomp")
+		return seccomp.Audit(spec), nil
+	}
+
+	return spec, nil
+}
+
+// wireSeccompAuditCollector returns a collector of the syscalls logged by
+// containers in seccomp audit mode, or nil if no container can be in audit
+// mode or the audit log cannot be read
+func (cmd *ServerCommand) wireSeccompAuditCollector(logger lager.Logger, securityProfiles bundlerules.SecurityProfiles, handles func() ([]string, error)) *seccomp.AuditCollector {
+	if !cmd.Containers.SeccompAudit && !securityProfiles.AuditsSeccomp() {
+		return nil
+	}
+
+	// reading the audit log needs CAP_AUDIT_READ
+	if !runningAsRoot() {
+		logger.Info("seccomp-audit-log-unavailable-when-rootless")
+		return nil
+	}
+
+	socket, err := seccomp.ListenAudit()
+	if err != nil {
+		logger.Error("failed-to-listen-for-seccomp-audit-logs", err)
+		return nil
+	}
+
+	return seccomp.NewAuditCollector(logger, socket, seccomp.CgroupHandleResolver{ProcRoot: "/proc", Handles: handles})
+}
@@ -10,12 +10,26 @@ import (
 
 	"code.cloudfoundry.org/garden"
 	"code.cloudfoundry.org/garden-shed/rootfs_provider"
+	"code.cloudfoundry.org/guardian/gardener"
 	"code.cloudfoundry.org/lager"
 )
 
+//go:generate counterfeiter . ContainerIDMappings
+type ContainerIDMappings interface {
+	Mappings(handle string) (gardener.IDMappings, bool)
+}
+
 type DefaultCommandCreator struct {
 	BinPath   string
 	ExtraArgs []string
+
+	// IDMappings are passed to the plugin as --uid-mapping and --gid-mapping
+	// arguments, unless the container has its own mappings
+	IDMappings gardener.IDMappings
+
+	// ContainerIDMappings looks up the mappings of containers with their own
+	// range of host IDs, or is nil if containers share the server's mappings
+	ContainerIDMappings ContainerIDMappings
 }
 
 func (cc *DefaultCommandCreator) CreateCommand(log lager.Logger, handle string, spec rootfs_provider.Spec) (*exec.Cmd, error) {
@@ -37,6 +51,21 @@ func (cc *DefaultCommandCreator) CreateCommand(log lager.Logger, handle string,
 		args = append(args, "--password", spec.Password)
 	}
 
+	mappings := cc.IDMappings
+	if cc.ContainerIDMappings != nil {
+		if containerMappings, ok := cc.ContainerIDMappings.Mappings(handle); ok {
+			mappings = containerMappings
+		}
+	}
+
+	for _, mapping := range mappings.UIDMappings {
+		args = append(args, "--uid-mapping", stringifyMapping(mapping))
+	}
+
+	for _, mapping := range mappings.GIDMappings {
+		args = append(args, "--gid-mapping", stringifyMapping(mapping))
+	}
+
 	rootfs := strings.Replace(spec.RootFS.String(), "#", ":", 1)
 
 	args = append(args, rootfs, handle)
@@ -6,27 +6,38 @@ import (
 
 	"code.cloudfoundry.org/garden"
 	"code.cloudfoundry.org/garden-shed/rootfs_provider"
+	"code.cloudfoundry.org/guardian/gardener"
 	"code.cloudfoundry.org/guardian/imageplugin"
+	fakes "code.cloudfoundry.org/guardian/imageplugin/imagepluginfakes"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("DefaultCommandCreator", func() {
 	var (
-		commandCreator *imageplugin.DefaultCommandCreator
-		binPath        string
-		extraArgs      []string
+		commandCreator      *imageplugin.DefaultCommandCreator
+		binPath             string
+		extraArgs           []string
+		idMappings          gardener.IDMappings
+		containerIDMappings *fakes.FakeContainerIDMappings
 	)
 
 	BeforeEach(func() {
 		binPath = "/image-plugin"
 		extraArgs = []string{}
+		idMappings = gardener.IDMappings{}
+		containerIDMappings = nil
 	})
 
 	JustBeforeEach(func() {
 		commandCreator = &imageplugin.DefaultCommandCreator{
-			BinPath:   binPath,
-			ExtraArgs: extraArgs,
+			BinPath:    binPath,
+			ExtraArgs:  extraArgs,
+			IDMappings: idMappings,
+		}
+
+		if containerIDMappings != nil {
+			commandCreator.ContainerIDMappings = containerIDMappings
 		}
 	})
 
@@ -171,6 +182,66 @@ var _ = Describe("DefaultCommandCreator", func() {
 			})
 		})
 
+		It("returns a command without id mappings", func() {
+			Expect(createCmd.Args).NotTo(ContainElement("--uid-mapping"))
+			Expect(createCmd.Args).NotTo(ContainElement("--gid-mapping"))
+		})
+
+		Context("when id mappings are provided", func() {
+			BeforeEach(func() {
+				idMappings = gardener.IDMappings{
+					UIDMappings: rootfs_provider.MappingList{
+						{ContainerID: 0, HostID: 100000, Size: 1},
+						{ContainerID: 1, HostID: 100001, Size: 65535},
+					},
+					GIDMappings: rootfs_provider.MappingList{
+						{ContainerID: 0, HostID: 200000, Size: 65536},
+					},
+				}
+			})
+
+			It("returns a command with the id mappings", func() {
+				Expect(createCmd.Args[2:8]).To(Equal([]string{
+					"--uid-mapping", "0:100000:1",
+					"--uid-mapping", "1:100001:65535",
+					"--gid-mapping", "0:200000:65536",
+				}))
+			})
+
+			Context("and the container has its own id mappings", func() {
+				BeforeEach(func() {
+					containerIDMappings = new(fakes.FakeContainerIDMappings)
+					containerIDMappings.MappingsReturns(gardener.IDMappings{
+						UIDMappings: rootfs_provider.MappingList{{ContainerID: 0, HostID: 300000, Size: 65536}},
+						GIDMappings: rootfs_provider.MappingList{{ContainerID: 0, HostID: 400000, Size: 65536}},
+					}, true)
+				})
+
+				It("looks up the container's mappings", func() {
+					Expect(containerIDMappings.MappingsCallCount()).To(Equal(1))
+					Expect(containerIDMappings.MappingsArgsForCall(0)).To(Equal("test-handle"))
+				})
+
+				It("returns a command with the container's id mappings", func() {
+					Expect(createCmd.Args[2:6]).To(Equal([]string{
+						"--uid-mapping", "0:300000:65536",
+						"--gid-mapping", "0:400000:65536",
+					}))
+				})
+			})
+
+			Context("and the container does not have its own id mappings", func() {
+				BeforeEach(func() {
+					containerIDMappings = new(fakes.FakeContainerIDMappings)
+				})
+
+				It("returns a command with the id mappings", func() {
+					Expect(createCmd.Args).To(ContainElement("0:100000:1"))
+					Expect(createCmd.Args).To(ContainElement("0:200000:65536"))
+				})
+			})
+		})
+
 		It("returns a command that runs as the current user (SysProcAttr.Credential not set)", func() {
 			Expect(createCmd.SysProcAttr).To(BeNil())
 		})
@@ -0,0 +1,100 @@
+// This file was generated by counterfeiter
+package imagepluginfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/imageplugin"
+)
+
+type FakeContainerIDMappings struct {
+	MappingsStub        func(handle string) (gardener.IDMappings, bool)
+	mappingsMutex       sync.RWMutex
+	mappingsArgsForCall []struct {
+		handle string
+	}
+	mappingsReturns struct {
+		result1 gardener.IDMappings
+		result2 bool
+	}
+	mappingsReturnsOnCall map[int]struct {
+		result1 gardener.IDMappings
+		result2 bool
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeContainerIDMappings) Mappings(handle string) (gardener.IDMappings, bool) {
+	fake.mappingsMutex.Lock()
+	ret, specificReturn := fake.mappingsReturnsOnCall[len(fake.mappingsArgsForCall)]
+	fake.mappingsArgsForCall = append(fake.mappingsArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("Mappings", []interface{}{handle})
+	fake.mappingsMutex.Unlock()
+	if fake.MappingsStub != nil {
+		return fake.MappingsStub(handle)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.mappingsReturns.result1, fake.mappingsReturns.result2
+}
+
+func (fake *FakeContainerIDMappings) MappingsCallCount() int {
+	fake.mappingsMutex.RLock()
+	defer fake.mappingsMutex.RUnlock()
+	return len(fake.mappingsArgsForCall)
+}
+
+func (fake *FakeContainerIDMappings) MappingsArgsForCall(i int) string {
+	fake.mappingsMutex.RLock()
+	defer fake.mappingsMutex.RUnlock()
+	return fake.mappingsArgsForCall[i].handle
+}
+
+func (fake *FakeContainerIDMappings) MappingsReturns(result1 gardener.IDMappings, result2 bool) {
+	fake.MappingsStub = nil
+	fake.mappingsReturns = struct {
+		result1 gardener.IDMappings
+		result2 bool
+	}{result1, result2}
+}
+
+func (fake *FakeContainerIDMappings) MappingsReturnsOnCall(i int, result1 gardener.IDMappings, result2 bool) {
+	fake.MappingsStub = nil
+	if fake.mappingsReturnsOnCall == nil {
+		fake.mappingsReturnsOnCall = make(map[int]struct {
+			result1 gardener.IDMappings
+			result2 bool
+		})
+	}
+	fake.mappingsReturnsOnCall[i] = struct {
+		result1 gardener.IDMappings
+		result2 bool
+	}{result1, result2}
+}
+
+func (fake *FakeContainerIDMappings) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.mappingsMutex.RLock()
+	defer fake.mappingsMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeContainerIDMappings) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ imageplugin.ContainerIDMappings = new(FakeContainerIDMappings)
@@ -12,6 +12,9 @@ import (
 const (
 	maxInterfacePrefixLen = 2
 	maxChainPrefixLen     = 16
+
+	// maxIDAttempts bounds the IDs generated while looking for an unused one
+	maxIDAttempts = 10
 )
 
 //go:generate counterfeiter . IDGenerator
@@ -34,10 +37,22 @@ type NetworkConfig struct {
 	Mtu                  int
 	DNSServers           []net.IP
 	AdditionalDNSServers []net.IP
+
+	// Mode is empty for bridged containers, or the direct attachment mode
+	Mode string
+
+	// ContainerIntfName is the name to which the container interface is
+	// renamed inside the container, or empty to keep ContainerIntf
+	ContainerIntfName string
+
+	Overrides NetworkOverrides
+
+	ConnectionLimits ConnectionLimits
 }
 
 type Creator struct {
 	idGenerator          IDGenerator
+	idChecker            IDChecker
 	interfacePrefix      string
 	chainPrefix          string
 	externalIP           net.IP
@@ -46,7 +61,7 @@ type Creator struct {
 	mtu                  int
 }
 
-func NewConfigCreator(idGenerator IDGenerator, interfacePrefix, chainPrefix string, externalIP net.IP, dnsServers, additionalDNSServers []net.IP, mtu int) *Creator {
+func NewConfigCreator(idGenerator IDGenerator, idChecker IDChecker, interfacePrefix, chainPrefix string, externalIP net.IP, dnsServers, additionalDNSServers []net.IP, mtu int) *Creator {
 	if len(interfacePrefix) > maxInterfacePrefixLen {
 		panic("interface prefix is too long")
 	}
@@ -57,6 +72,7 @@ func NewConfigCreator(idGenerator IDGenerator, interfacePrefix, chainPrefix stri
 
 	return &Creator{
 		idGenerator:          idGenerator,
+		idChecker:            idChecker,
 		interfacePrefix:      interfacePrefix,
 		chainPrefix:          chainPrefix,
 		externalIP:           externalIP,
@@ -67,11 +83,15 @@ func NewConfigCreator(idGenerator IDGenerator, interfacePrefix, chainPrefix stri
 }
 
 func (c *Creator) Create(log lager.Logger, handle string, subnet *net.IPNet, ip net.IP) (NetworkConfig, error) {
-	id := c.idGenerator.Generate()
+	id, err := c.generateID(log)
+	if err != nil {
+		return NetworkConfig{}, err
+	}
+
 	return NetworkConfig{
 		ContainerHandle: handle,
-		HostIntf:        fmt.Sprintf("%s%s-0", c.interfacePrefix, id),
-		ContainerIntf:   fmt.Sprintf("%s%s-1", c.interfacePrefix, id),
+		HostIntf:        hostIntfName(c.interfacePrefix, id),
+		ContainerIntf:   containerIntfName(c.interfacePrefix, id),
 
 		BridgeName: fmt.Sprintf("%s%s%s", c.interfacePrefix, "brdg-", hex.EncodeToString(subnet.IP)),
 
@@ -86,3 +106,33 @@ func (c *Creator) Create(log lager.Logger, handle string, subnet *net.IPNet, ip
 		AdditionalDNSServers: c.additionalDNSServers,
 	}, nil
 }
+
+// generateID generates IDs until one is found which no interface or chain on
+// the host is named after
+func (c *Creator) generateID(log lager.Logger) (string, error) {
+	for i := 0; i < maxIDAttempts; i++ {
+		id := c.idGenerator.Generate()
+
+		inUse, err := c.idChecker.InUse(id)
+		if err != nil {
+			log.Error("check-id-failed", err, lager.Data{"id": id})
+			return "", err
+		}
+
+		if !inUse {
+			return id, nil
+		}
+
+		log.Info("id-in-use", lager.Data{"id": id})
+	}
+
+	return "", fmt.Errorf("no unused network ID found after %d attempts", maxIDAttempts)
+}
+
+func hostIntfName(prefix, id string) string {
+	return fmt.Sprintf("%s%s-0", prefix, id)
+}
+
+func containerIntfName(prefix, id string) string {
+	return fmt.Sprintf("%s%s-1", prefix, id)
+}
@@ -1,6 +1,7 @@
 package kawasaki_test
 
 import (
+	"errors"
 	"net"
 
 	"code.cloudfoundry.org/guardian/kawasaki"
@@ -21,6 +22,7 @@ var _ = Describe("ConfigCreator", func() {
 		additionalDNSServers []net.IP
 		logger               lager.Logger
 		idGenerator          *fakes.FakeIDGenerator
+		idChecker            *fakes.FakeIDChecker
 		mtu                  int
 	)
 
@@ -40,21 +42,22 @@ var _ = Describe("ConfigCreator", func() {
 
 		logger = lagertest.NewTestLogger("test")
 		idGenerator = &fakes.FakeIDGenerator{}
+		idChecker = &fakes.FakeIDChecker{}
 
 		mtu = 1234
 
-		creator = kawasaki.NewConfigCreator(idGenerator, "w1", "0123456789abcdef", externalIP, dnsServers, additionalDNSServers, mtu)
+		creator = kawasaki.NewConfigCreator(idGenerator, idChecker, "w1", "0123456789abcdef", externalIP, dnsServers, additionalDNSServers, mtu)
 	})
 
 	It("panics if the interface prefix is longer than 2 characters", func() {
 		Expect(func() {
-			kawasaki.NewConfigCreator(idGenerator, "too-long", "wc", externalIP, dnsServers, additionalDNSServers, mtu)
+			kawasaki.NewConfigCreator(idGenerator, idChecker, "too-long", "wc", externalIP, dnsServers, additionalDNSServers, mtu)
 		}).To(Panic())
 	})
 
 	It("panics if the chain prefix is longer than 16 characters", func() {
 		Expect(func() {
-			kawasaki.NewConfigCreator(idGenerator, "w1", "0123456789abcdefg", externalIP, dnsServers, additionalDNSServers, mtu)
+			kawasaki.NewConfigCreator(idGenerator, idChecker, "w1", "0123456789abcdefg", externalIP, dnsServers, additionalDNSServers, mtu)
 		}).To(Panic())
 	})
 
@@ -110,6 +113,54 @@ var _ = Describe("ConfigCreator", func() {
 		Expect(idGenerator.GenerateCallCount()).To(Equal(1))
 	})
 
+	Context("when an ID is already in use", func() {
+		BeforeEach(func() {
+			ids := []string{"used", "also-used", "unused"}
+			idGenerator.GenerateStub = func() string {
+				id := ids[0]
+				ids = ids[1:]
+				return id
+			}
+
+			idChecker.InUseStub = func(id string) (bool, error) {
+				return id != "unused", nil
+			}
+		})
+
+		It("generates new IDs until one is not in use", func() {
+			config, err := creator.Create(logger, "banana", subnet, ip)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(idGenerator.GenerateCallCount()).To(Equal(3))
+			Expect(config.HostIntf).To(Equal("w1unused-0"))
+			Expect(config.ContainerIntf).To(Equal("w1unused-1"))
+			Expect(config.IPTableInstance).To(Equal("unused"))
+		})
+	})
+
+	Context("when every ID is in use", func() {
+		BeforeEach(func() {
+			idChecker.InUseReturns(true, nil)
+		})
+
+		It("gives up with an error", func() {
+			_, err := creator.Create(logger, "banana", subnet, ip)
+			Expect(err).To(MatchError("no unused network ID found after 10 attempts"))
+			Expect(idGenerator.GenerateCallCount()).To(Equal(10))
+		})
+	})
+
+	Context("when checking an ID fails", func() {
+		BeforeEach(func() {
+			idChecker.InUseReturns(false, errors.New("iptables-failed"))
+		})
+
+		It("returns the error", func() {
+			_, err := creator.Create(logger, "banana", subnet, ip)
+			Expect(err).To(MatchError("iptables-failed"))
+		})
+	})
+
 	It("saves the external ip", func() {
 		config, err := creator.Create(logger, "banana", subnet, ip)
 		Expect(err).NotTo(HaveOccurred())
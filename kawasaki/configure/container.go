@@ -23,11 +23,12 @@ import (
 
 func init() {
 	reexec.Register("configure-container-netns", func() {
-		var netNsPath, containerIntf, containerIPStr, bridgeIPStr, subnetStr string
+		var netNsPath, containerIntf, containerIntfName, containerIPStr, bridgeIPStr, subnetStr string
 		var mtu int
 
 		flag.StringVar(&netNsPath, "netNsPath", "", "netNsPath")
 		flag.StringVar(&containerIntf, "containerIntf", "", "containerIntf")
+		flag.StringVar(&containerIntfName, "containerIntfName", "", "containerIntfName")
 		flag.StringVar(&containerIPStr, "containerIP", "", "containerIP")
 		flag.StringVar(&bridgeIPStr, "bridgeIP", "", "bridgeIP")
 		flag.StringVar(&subnetStr, "subnet", "", "subnet")
@@ -57,10 +58,25 @@ func init() {
 			if err != nil {
 				panic(err)
 			}
+			if !found && containerIntfName != "" {
+				// the interface was renamed when the container was first configured
+				intf, found, err = link.InterfaceByName(containerIntfName)
+				if err != nil {
+					panic(err)
+				}
+			}
 			if !found {
 				return fmt.Errorf("interface `%s` was not found", containerIntf)
 			}
 
+			if containerIntfName != "" && intf.Name != containerIntfName {
+				if err := link.Rename(intf, containerIntfName); err != nil {
+					return fmt.Errorf("renaming interface `%s` to `%s`: %s", containerIntf, containerIntfName, err)
+				}
+
+				intf.Name = containerIntfName
+			}
+
 			if err := link.AddIP(intf, containerIP, subnetIPNet); err != nil {
 				panic(err)
 			}
@@ -104,6 +120,7 @@ func (c *Container) Apply(log lager.Logger, cfg kawasaki.NetworkConfig, pid int)
 	cmd := reexec.Command("configure-container-netns",
 		"-netNsPath", netns.Name(),
 		"-containerIntf", cfg.ContainerIntf,
+		"-containerIntfName", cfg.ContainerIntfName,
 		"-containerIP", cfg.ContainerIP.String(),
 		"-bridgeIP", cfg.BridgeIP.String(),
 		"-subnet", cfg.Subnet.String(),
@@ -109,6 +109,20 @@ var _ = Describe("Container", func() {
 		Expect(linkMTU(netNsName, linkName)).To(Equal(networkConfig.Mtu))
 	})
 
+	Context("when the container interface is to be renamed", func() {
+		BeforeEach(func() {
+			networkConfig.ContainerIntfName = "eth0"
+		})
+
+		It("renames the interface and configures it under its new name", func() {
+			Expect(configurer.Apply(logger, networkConfig, 42)).To(Succeed())
+
+			Expect(linkIP(netNsName, "eth0")).To(Equal(networkConfig.ContainerIP.String()))
+			Expect(linkUp(netNsName, "eth0")).To(BeTrue())
+			Expect(linkMTU(netNsName, "eth0")).To(Equal(networkConfig.Mtu))
+		})
+	})
+
 	Context("when the netns file disappears", func() {
 		BeforeEach(func() {
 			var err error
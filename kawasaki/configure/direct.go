@@ -0,0 +1,67 @@
+package configure
+
+import (
+	"fmt"
+	"net"
+	"os"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/lager"
+)
+
+// Direct attaches a container straight to a host interface with a macvlan or
+// ipvlan interface, rather than via a veth pair and a bridge
+type Direct struct {
+	Sublink interface {
+		Create(mode, parentName, name string) (*net.Interface, error)
+	}
+
+	Link interface {
+		SetNs(intf *net.Interface, fd int) error
+	}
+
+	FileOpener interface {
+		Open(path string) (*os.File, error)
+	}
+}
+
+func (c *Direct) Apply(logger lager.Logger, config kawasaki.NetworkConfig, pid int) error {
+	cLog := logger.Session("configure-direct", lager.Data{
+		"mode":           config.Mode,
+		"parentIface":    config.HostIntf,
+		"containerIface": config.ContainerIntf,
+		"pid":            pid,
+	})
+
+	cLog.Debug("configuring")
+
+	container, err := c.Sublink.Create(config.Mode, config.HostIntf, config.ContainerIntf)
+	if err != nil {
+		cLog.Error("create", err)
+		return &SublinkCreationError{err, config.Mode, config.HostIntf, config.ContainerIntf}
+	}
+
+	netns, err := c.FileOpener.Open(fmt.Sprintf("/proc/%d/ns/net", pid))
+	if err != nil {
+		return err
+	}
+	defer netns.Close()
+
+	if err = c.Link.SetNs(container, int(netns.Fd())); err != nil {
+		return &SetNsFailedError{err, container, netns}
+	}
+
+	return nil
+}
+
+// Repair is a no-op: the interface lives in the container's network namespace,
+// where the host cannot remove it
+func (c *Direct) Repair(logger lager.Logger, config kawasaki.NetworkConfig, pid int) (kawasaki.Repairs, error) {
+	return kawasaki.Repairs{}, nil
+}
+
+// Destroy is a no-op: the interface lives in the container's network
+// namespace and is removed along with it
+func (c *Direct) Destroy(config kawasaki.NetworkConfig) error {
+	return nil
+}
@@ -0,0 +1,113 @@
+package configure_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/guardian/kawasaki/configure"
+	"code.cloudfoundry.org/guardian/kawasaki/devices/fakedevices"
+	"code.cloudfoundry.org/guardian/kawasaki/netns"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Direct", func() {
+	var (
+		sublinkCreator *fakedevices.FakeSublinkCreator
+		linkConfigurer *fakedevices.FakeLink
+		netnsFD        *os.File
+		nsOpener       func(path string) (*os.File, error)
+
+		configurer *configure.Direct
+
+		logger lager.Logger
+		config kawasaki.NetworkConfig
+	)
+
+	BeforeEach(func() {
+		sublinkCreator = &fakedevices.FakeSublinkCreator{}
+		sublinkCreator.CreateReturns.Interface = &net.Interface{Name: "the-container"}
+		linkConfigurer = &fakedevices.FakeLink{AddIPReturns: make(map[string]error)}
+
+		var err error
+		netnsFD, err = ioutil.TempFile("", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		nsOpener = func(path string) (*os.File, error) {
+			return netnsFD, nil
+		}
+
+		logger = lagertest.NewTestLogger("test")
+		config = kawasaki.NetworkConfig{
+			Mode:          "macvlan",
+			HostIntf:      "eth1",
+			ContainerIntf: "container",
+		}
+	})
+
+	JustBeforeEach(func() {
+		configurer = &configure.Direct{
+			Sublink:    sublinkCreator,
+			Link:       linkConfigurer,
+			FileOpener: netns.Opener(nsOpener),
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.Remove(netnsFD.Name())).To(Succeed())
+	})
+
+	Describe("Apply", func() {
+		It("creates an interface of the configured mode on the host interface", func() {
+			Expect(configurer.Apply(logger, config, 42)).To(Succeed())
+
+			Expect(sublinkCreator.CreateCalledWith.Mode).To(Equal("macvlan"))
+			Expect(sublinkCreator.CreateCalledWith.ParentName).To(Equal("eth1"))
+			Expect(sublinkCreator.CreateCalledWith.Name).To(Equal("container"))
+		})
+
+		It("moves the interface in to the container's namespace", func() {
+			expectedNetNsFd := int(netnsFD.Fd()) // record it before Apply closes it
+
+			Expect(configurer.Apply(logger, config, 42)).To(Succeed())
+			Expect(linkConfigurer.SetNsCalledWith.Interface).To(Equal(sublinkCreator.CreateReturns.Interface))
+			Expect(linkConfigurer.SetNsCalledWith.Fd).To(Equal(expectedNetNsFd))
+		})
+
+		Context("when creating the interface fails", func() {
+			It("returns a wrapped error", func() {
+				sublinkCreator.CreateReturns.Err = errors.New("foo bar baz")
+
+				err := configurer.Apply(logger, config, 42)
+				Expect(err).To(MatchError(&configure.SublinkCreationError{Cause: sublinkCreator.CreateReturns.Err, Mode: "macvlan", Parent: "eth1", Name: "container"}))
+			})
+		})
+
+		Context("when opening the netns file descriptor fails", func() {
+			BeforeEach(func() {
+				nsOpener = func(path string) (*os.File, error) {
+					return nil, errors.New("notns")
+				}
+			})
+
+			It("returns the error", func() {
+				Expect(configurer.Apply(logger, config, 42)).To(MatchError("notns"))
+			})
+		})
+
+		Context("when moving the interface into the namespace fails", func() {
+			It("returns a wrapped error", func() {
+				linkConfigurer.SetNsReturns = errors.New("o no")
+
+				err := configurer.Apply(logger, config, 42)
+				Expect(err).To(MatchError(&configure.SetNsFailedError{Cause: linkConfigurer.SetNsReturns, Intf: sublinkCreator.CreateReturns.Interface, Netns: netnsFD}))
+			})
+		})
+	})
+})
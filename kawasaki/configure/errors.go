@@ -27,6 +27,16 @@ func (err MTUError) Error() string {
 	return fmtErr("failed to set interface '%v' mtu to %d", err.Intf, err.MTU, err.Cause)
 }
 
+// SublinkCreationError is returned if creating a macvlan or ipvlan interface fails
+type SublinkCreationError struct {
+	Cause              error
+	Mode, Parent, Name string
+}
+
+func (err SublinkCreationError) Error() string {
+	return fmtErr("failed to create %s interface '%s' on parent '%s': %v", err.Mode, err.Name, err.Parent, err.Cause)
+}
+
 type SetNsFailedError struct {
 	Cause error
 	Intf  *net.Interface
@@ -59,6 +69,16 @@ func (err AddToBridgeError) Error() string {
 	return fmtErr("failed to add slave %s to bridge %s: %v", err.Slave.Name, err.Bridge.Name, err.Cause)
 }
 
+// HairpinError is returned if enabling hairpin mode on a bridge port fails
+type HairpinError struct {
+	Cause error
+	Intf  *net.Interface
+}
+
+func (err HairpinError) Error() string {
+	return fmtErr("failed to enable hairpin mode on %s: %v", err.Intf.Name, err.Cause)
+}
+
 // LinkUpError is returned if brinding an interface up fails
 type LinkUpError struct {
 	Cause error
@@ -17,6 +17,7 @@ type Host struct {
 	Link interface {
 		SetUp(intf *net.Interface) error
 		SetMTU(intf *net.Interface, mtu int) error
+		SetAlias(intf *net.Interface, alias string) error
 		SetNs(intf *net.Interface, fd int) error
 		InterfaceByName(name string) (*net.Interface, bool, error)
 	}
@@ -24,6 +25,7 @@ type Host struct {
 	Bridge interface {
 		Create(bridgeName string, ip net.IP, subnet *net.IPNet) (*net.Interface, error)
 		Add(bridge, slave *net.Interface) error
+		EnableHairpin(slave *net.Interface) error
 		Destroy(bridgeName string) error
 	}
 
@@ -64,6 +66,11 @@ func (c *Host) Apply(logger lager.Logger, config kawasaki.NetworkConfig, pid int
 		return err
 	}
 
+	// lets operators find the container a host interface belongs to
+	if err = c.Link.SetAlias(host, config.ContainerHandle); err != nil {
+		cLog.Error("set-alias", err)
+	}
+
 	netns, err := c.FileOpener.Open(fmt.Sprintf("/proc/%d/ns/net", pid))
 	if err != nil {
 		return err
@@ -78,6 +85,51 @@ func (c *Host) Apply(logger lager.Logger, config kawasaki.NetworkConfig, pid int
 	return nil
 }
 
+// Repair re-creates a container's bridge and veth pair if they no longer exist.
+// The container's end of a re-created pair is moved in to its network
+// namespace, but is left for the container configurer to configure.
+func (c *Host) Repair(logger lager.Logger, config kawasaki.NetworkConfig, pid int) (kawasaki.Repairs, error) {
+	var repairs kawasaki.Repairs
+
+	cLog := logger.Session("repair-host", lager.Data{
+		"bridgeName": config.BridgeName,
+		"hostIface":  config.HostIntf,
+		"pid":        pid,
+	})
+
+	_, bridgeExists, err := c.Link.InterfaceByName(config.BridgeName)
+	if err != nil {
+		return repairs, err
+	}
+
+	host, hostExists, err := c.Link.InterfaceByName(config.HostIntf)
+	if err != nil {
+		return repairs, err
+	}
+
+	if bridgeExists && hostExists {
+		return repairs, nil
+	}
+
+	repairs.Bridge = !bridgeExists
+
+	// the container's interface is the peer of the host's, so neither exists
+	if !hostExists {
+		cLog.Info("recreating-veth-pair")
+		repairs.Veth = true
+		return repairs, c.Apply(cLog, config, pid)
+	}
+
+	// the host interface is detached from a deleted bridge, so is re-attached
+	cLog.Info("recreating-bridge")
+	bridge, err := c.configureBridgeIntf(cLog, config.BridgeName, config.BridgeIP, config.Subnet)
+	if err != nil {
+		return repairs, err
+	}
+
+	return repairs, c.configureHostIntf(cLog, host, bridge, config.Mtu)
+}
+
 func (c *Host) Destroy(config kawasaki.NetworkConfig) error {
 	return c.Bridge.Destroy(config.BridgeName)
 }
@@ -134,6 +186,12 @@ func (c *Host) configureHostIntf(log lager.Logger, intf *net.Interface, bridge *
 		return &AddToBridgeError{err, bridge, intf}
 	}
 
+	log.Debug("enable-hairpin")
+	if err := c.Bridge.EnableHairpin(intf); err != nil {
+		log.Error("enable-hairpin", err)
+		return &HairpinError{err, intf}
+	}
+
 	log.Debug("bring-link-up")
 	if err := c.Link.SetUp(intf); err != nil {
 		log.Error("bring-link-up", err)
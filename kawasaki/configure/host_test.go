@@ -131,6 +131,23 @@ var _ = Describe("Host", func() {
 				})
 			})
 
+			It("should set the container's handle as the alias of the host interface", func() {
+				config.BridgeName = "bridge"
+				config.ContainerHandle = "some-handle"
+				Expect(configurer.Apply(logger, config, 42)).To(Succeed())
+
+				Expect(linkConfigurer.SetAliasCalledWith.Interface).To(Equal(vethCreator.CreateReturns.Host))
+				Expect(linkConfigurer.SetAliasCalledWith.Alias).To(Equal("some-handle"))
+			})
+
+			Context("when setting the alias fails", func() {
+				It("still succeeds, as the alias is only informational", func() {
+					config.BridgeName = "bridge"
+					linkConfigurer.SetAliasReturns = errors.New("o no")
+					Expect(configurer.Apply(logger, config, 42)).To(Succeed())
+				})
+			})
+
 			It("should move the container interface in to the container's namespace", func() {
 				expectedNetNsFd := int(netnsFD.Fd()) // record it before Apply closes it
 				config.BridgeName = "bridge"
@@ -203,6 +220,22 @@ var _ = Describe("Host", func() {
 						Expect(bridger.AddCalledWith.Bridge).To(Equal(existingBridge))
 					})
 
+					It("enables hairpin mode on the host interface", func() {
+						config.BridgeName = "bridge"
+						Expect(configurer.Apply(logger, config, 42)).To(Succeed())
+						Expect(bridger.EnableHairpinCalledWith).To(Equal(vethCreator.CreateReturns.Host))
+					})
+
+					Context("when enabling hairpin mode fails", func() {
+						It("returns a wrapped error", func() {
+							bridger.EnableHairpinReturns = errors.New("no hairpins")
+
+							config.BridgeName = "bridge"
+							err := configurer.Apply(logger, config, 42)
+							Expect(err).To(MatchError(&configure.HairpinError{Cause: bridger.EnableHairpinReturns, Intf: vethCreator.CreateReturns.Host}))
+						})
+					})
+
 					It("brings the host interface up", func() {
 						config.BridgeName = "bridge"
 						Expect(configurer.Apply(logger, config, 42)).To(Succeed())
@@ -230,6 +263,154 @@ var _ = Describe("Host", func() {
 		})
 	})
 
+	Describe("Repair", func() {
+		var (
+			netnsFD *os.File
+
+			existingInterfaces map[string]*net.Interface
+		)
+
+		BeforeEach(func() {
+			var err error
+			netnsFD, err = ioutil.TempFile("", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			nsOpener = func(path string) (*os.File, error) {
+				return netnsFD, nil
+			}
+
+			config.HostIntf = "host"
+			config.ContainerIntf = "container"
+			config.BridgeName = "bridge"
+			config.Mtu = 123
+
+			existingInterfaces = map[string]*net.Interface{
+				"host":   {Name: "host"},
+				"bridge": {Name: "bridge"},
+			}
+
+			linkConfigurer.InterfaceByNameFunc = func(name string) (*net.Interface, bool, error) {
+				intf, ok := existingInterfaces[name]
+				return intf, ok, nil
+			}
+		})
+
+		AfterEach(func() {
+			os.Remove(netnsFD.Name())
+		})
+
+		Context("when the bridge and the host interface exist", func() {
+			It("repairs nothing", func() {
+				repairs, err := configurer.Repair(logger, config, 42)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repairs.Empty()).To(BeTrue())
+
+				Expect(vethCreator.CreateCalledWith.HostIfcName).To(BeEmpty())
+				Expect(bridger.CreateCalledWith.Name).To(BeEmpty())
+				Expect(bridger.AddCalledWith.Bridge).To(BeNil())
+			})
+		})
+
+		Context("when the host interface does not exist", func() {
+			BeforeEach(func() {
+				delete(existingInterfaces, "host")
+				vethCreator.CreateReturns.Host = &net.Interface{Name: "the-host"}
+				vethCreator.CreateReturns.Container = &net.Interface{Name: "the-container"}
+			})
+
+			It("re-creates the virtual ethernet pair", func() {
+				repairs, err := configurer.Repair(logger, config, 42)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repairs).To(Equal(kawasaki.Repairs{Veth: true}))
+
+				Expect(vethCreator.CreateCalledWith.HostIfcName).To(Equal("host"))
+				Expect(vethCreator.CreateCalledWith.ContainerIfcName).To(Equal("container"))
+			})
+
+			It("adds the host interface to the bridge", func() {
+				_, err := configurer.Repair(logger, config, 42)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(bridger.AddCalledWith.Bridge).To(Equal(existingInterfaces["bridge"]))
+				Expect(bridger.AddCalledWith.Slave).To(Equal(vethCreator.CreateReturns.Host))
+			})
+
+			It("moves the container interface in to the container's namespace", func() {
+				expectedNetNsFd := int(netnsFD.Fd())
+
+				_, err := configurer.Repair(logger, config, 42)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(linkConfigurer.SetNsCalledWith.Interface).To(Equal(vethCreator.CreateReturns.Container))
+				Expect(linkConfigurer.SetNsCalledWith.Fd).To(Equal(expectedNetNsFd))
+			})
+
+			Context("and re-creating the pair fails", func() {
+				It("returns a wrapped error", func() {
+					vethCreator.CreateReturns.Err = errors.New("foo bar baz")
+
+					_, err := configurer.Repair(logger, config, 42)
+					Expect(err).To(MatchError(&configure.VethPairCreationError{Cause: vethCreator.CreateReturns.Err, HostIfcName: "host", ContainerIfcName: "container"}))
+				})
+			})
+		})
+
+		Context("when only the bridge does not exist", func() {
+			var createdBridge *net.Interface
+
+			BeforeEach(func() {
+				delete(existingInterfaces, "bridge")
+
+				createdBridge = &net.Interface{Name: "created"}
+				bridger.CreateReturns.Interface = createdBridge
+			})
+
+			It("re-creates the bridge", func() {
+				repairs, err := configurer.Repair(logger, config, 42)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repairs).To(Equal(kawasaki.Repairs{Bridge: true}))
+
+				Expect(bridger.CreateCalledWith.Name).To(Equal("bridge"))
+			})
+
+			It("re-attaches the existing host interface to the bridge", func() {
+				_, err := configurer.Repair(logger, config, 42)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(bridger.AddCalledWith.Bridge).To(Equal(createdBridge))
+				Expect(bridger.AddCalledWith.Slave).To(Equal(existingInterfaces["host"]))
+				Expect(linkConfigurer.SetUpCalledWith).To(ContainElement(existingInterfaces["host"]))
+			})
+
+			It("does not re-create the virtual ethernet pair", func() {
+				_, err := configurer.Repair(logger, config, 42)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(vethCreator.CreateCalledWith.HostIfcName).To(BeEmpty())
+			})
+
+			Context("and re-creating the bridge fails", func() {
+				It("returns the error", func() {
+					bridger.CreateReturns.Error = errors.New("kawasaki!")
+
+					_, err := configurer.Repair(logger, config, 42)
+					Expect(err).To(MatchError("kawasaki!"))
+				})
+			})
+		})
+
+		Context("when looking up an interface fails", func() {
+			It("returns the error", func() {
+				linkConfigurer.InterfaceByNameFunc = func(name string) (*net.Interface, bool, error) {
+					return nil, false, errors.New("no interfaces")
+				}
+
+				_, err := configurer.Repair(logger, config, 42)
+				Expect(err).To(MatchError("no interfaces"))
+			})
+		})
+	})
+
 	Describe("Destroy", func() {
 		It("should destroy the bridge", func() {
 			config.HostIntf = "host"
@@ -1,6 +1,7 @@
 package kawasaki
 
 import (
+	"fmt"
 	"net"
 	"os"
 
@@ -16,6 +17,7 @@ type NetnsExecer interface {
 type configurer struct {
 	dnsResolvConfigurer  DnsResolvConfigurer
 	hostConfigurer       HostConfigurer
+	directConfigurer     HostConfigurer
 	containerConfigurer  ContainerConfigurer
 	instanceChainCreator InstanceChainCreator
 	fileOpener           netns.Opener
@@ -24,12 +26,29 @@ type configurer struct {
 //go:generate counterfeiter . HostConfigurer
 type HostConfigurer interface {
 	Apply(logger lager.Logger, cfg NetworkConfig, pid int) error
+	Repair(logger lager.Logger, cfg NetworkConfig, pid int) (Repairs, error)
 	Destroy(cfg NetworkConfig) error
 }
 
+// Repairs records which of a container's kernel network objects were found
+// to be missing and have been re-created
+type Repairs struct {
+	Bridge        bool
+	Veth          bool
+	IPTablesRules bool
+}
+
+// Empty returns true if nothing needed to be re-created
+func (r Repairs) Empty() bool {
+	return !r.Bridge && !r.Veth && !r.IPTablesRules
+}
+
 //go:generate counterfeiter . InstanceChainCreator
 type InstanceChainCreator interface {
 	Create(logger lager.Logger, handle, instanceChain, bridgeName string, ip net.IP, network *net.IPNet) error
+	CreateOverrides(logger lager.Logger, handle, instanceChain, bridgeName string, ip net.IP, overrides NetworkOverrides) error
+	CreateConnectionLimits(logger lager.Logger, handle, instanceChain, bridgeName string, ip net.IP, limits ConnectionLimits) error
+	Verify(logger lager.Logger, instanceChain string, network *net.IPNet, overrides NetworkOverrides, limits ConnectionLimits) (bool, error)
 	Destroy(logger lager.Logger, instanceChain string) error
 }
 
@@ -43,10 +62,11 @@ type DnsResolvConfigurer interface {
 	Configure(log lager.Logger, cfg NetworkConfig, pid int) error
 }
 
-func NewConfigurer(resolvConfigurer DnsResolvConfigurer, hostConfigurer HostConfigurer, containerConfigurer ContainerConfigurer, instanceChainCreator InstanceChainCreator) *configurer {
+func NewConfigurer(resolvConfigurer DnsResolvConfigurer, hostConfigurer, directConfigurer HostConfigurer, containerConfigurer ContainerConfigurer, instanceChainCreator InstanceChainCreator) *configurer {
 	return &configurer{
 		dnsResolvConfigurer:  resolvConfigurer,
 		hostConfigurer:       hostConfigurer,
+		directConfigurer:     directConfigurer,
 		containerConfigurer:  containerConfigurer,
 		instanceChainCreator: instanceChainCreator,
 	}
@@ -57,17 +77,94 @@ func (c *configurer) Apply(log lager.Logger, cfg NetworkConfig, pid int) error {
 		return err
 	}
 
+	// directly attached containers bypass the host's bridge and firewall
+	if IsDirectMode(cfg.Mode) {
+		if err := c.directConfigurer.Apply(log, cfg, pid); err != nil {
+			return err
+		}
+
+		return c.containerConfigurer.Apply(log, cfg, pid)
+	}
+
 	if err := c.hostConfigurer.Apply(log, cfg, pid); err != nil {
 		return err
 	}
 
-	if err := c.instanceChainCreator.Create(log, cfg.ContainerHandle, cfg.IPTableInstance, cfg.BridgeName, cfg.ContainerIP, cfg.Subnet); err != nil {
+	if err := c.createIPTablesRules(log, cfg); err != nil {
 		return err
 	}
 
 	return c.containerConfigurer.Apply(log, cfg, pid)
 }
 
+// Repair re-creates whichever of a container's interfaces and iptables chains
+// no longer exist. Its interfaces are only verified when its pid is known, i.e.
+// is not 0. Its chains are only re-created if recreateChains is true, e.g. if
+// the rules added to them since creation were recorded, and otherwise missing
+// chains are an error.
+func (c *configurer) Repair(log lager.Logger, cfg NetworkConfig, pid int, recreateChains bool) (Repairs, error) {
+	var repairs Repairs
+
+	// directly attached containers have nothing on the host to lose
+	if IsDirectMode(cfg.Mode) {
+		return repairs, nil
+	}
+
+	if pid != 0 {
+		var err error
+		if repairs, err = c.hostConfigurer.Repair(log, cfg, pid); err != nil {
+			return repairs, err
+		}
+
+		if repairs.Veth {
+			if err := c.containerConfigurer.Apply(log, cfg, pid); err != nil {
+				return repairs, err
+			}
+		}
+	}
+
+	intact, err := c.instanceChainCreator.Verify(log, cfg.IPTableInstance, cfg.Subnet, cfg.Overrides, cfg.ConnectionLimits)
+	if err != nil || intact {
+		return repairs, err
+	}
+
+	if !recreateChains {
+		return repairs, fmt.Errorf("iptables chains of instance %s are missing, and cannot be re-created without losing rules", cfg.IPTableInstance)
+	}
+
+	// remove whatever is left of the chains before re-creating them
+	if err := c.instanceChainCreator.Destroy(log, cfg.IPTableInstance); err != nil {
+		return repairs, err
+	}
+
+	if err := c.createIPTablesRules(log, cfg); err != nil {
+		return repairs, err
+	}
+
+	repairs.IPTablesRules = true
+	return repairs, nil
+}
+
+func (c *configurer) createIPTablesRules(log lager.Logger, cfg NetworkConfig) error {
+	if err := c.instanceChainCreator.Create(log, cfg.ContainerHandle, cfg.IPTableInstance, cfg.BridgeName, cfg.ContainerIP, cfg.Subnet); err != nil {
+		return err
+	}
+
+	if !cfg.Overrides.Empty() {
+		if err := c.instanceChainCreator.CreateOverrides(log, cfg.ContainerHandle, cfg.IPTableInstance, cfg.BridgeName, cfg.ContainerIP, cfg.Overrides); err != nil {
+			return err
+		}
+	}
+
+	if !cfg.ConnectionLimits.Empty() {
+		if err := c.instanceChainCreator.CreateConnectionLimits(log, cfg.ContainerHandle, cfg.IPTableInstance, cfg.BridgeName, cfg.ContainerIP, cfg.ConnectionLimits); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (c *configurer) DestroyBridge(log lager.Logger, cfg NetworkConfig) error {
 	return c.hostConfigurer.Destroy(cfg)
 }
@@ -75,3 +172,9 @@ func (c *configurer) DestroyBridge(log lager.Logger, cfg NetworkConfig) error {
 func (c *configurer) DestroyIPTablesRules(log lager.Logger, cfg NetworkConfig) error {
 	return c.instanceChainCreator.Destroy(log, cfg.IPTableInstance)
 }
+
+// ConfigureDNS writes a container's /etc/hosts and /etc/resolv.conf, e.g. for
+// a container which joins the network of another
+func (c *configurer) ConfigureDNS(log lager.Logger, cfg NetworkConfig, pid int) error {
+	return c.dnsResolvConfigurer.Configure(log, cfg, pid)
+}
@@ -20,6 +20,7 @@ var _ = Describe("Configurer", func() {
 	var (
 		fakeDnsResolvConfigurer  *fakes.FakeDnsResolvConfigurer
 		fakeHostConfigurer       *fakes.FakeHostConfigurer
+		fakeDirectConfigurer     *fakes.FakeHostConfigurer
 		fakeContainerConfigurer  *fakes.FakeContainerConfigurer
 		fakeInstanceChainCreator *fakes.FakeInstanceChainCreator
 
@@ -36,6 +37,7 @@ var _ = Describe("Configurer", func() {
 		fakeDnsResolvConfigurer = new(fakes.FakeDnsResolvConfigurer)
 
 		fakeHostConfigurer = new(fakes.FakeHostConfigurer)
+		fakeDirectConfigurer = new(fakes.FakeHostConfigurer)
 		fakeContainerConfigurer = new(fakes.FakeContainerConfigurer)
 		fakeInstanceChainCreator = new(fakes.FakeInstanceChainCreator)
 
@@ -47,7 +49,7 @@ var _ = Describe("Configurer", func() {
 			return netnsFD, nil
 		}
 
-		configurer = kawasaki.NewConfigurer(fakeDnsResolvConfigurer, fakeHostConfigurer, fakeContainerConfigurer, fakeInstanceChainCreator)
+		configurer = kawasaki.NewConfigurer(fakeDnsResolvConfigurer, fakeHostConfigurer, fakeDirectConfigurer, fakeContainerConfigurer, fakeInstanceChainCreator)
 
 		logger = lagertest.NewTestLogger("test")
 	})
@@ -133,6 +135,113 @@ var _ = Describe("Configurer", func() {
 			})
 		})
 
+		It("does not create overrides when none are requested", func() {
+			Expect(configurer.Apply(logger, kawasaki.NetworkConfig{}, 42)).To(Succeed())
+			Expect(fakeInstanceChainCreator.CreateOverridesCallCount()).To(Equal(0))
+		})
+
+		Context("when network overrides are requested", func() {
+			var cfg kawasaki.NetworkConfig
+
+			BeforeEach(func() {
+				_, denied, _ := net.ParseCIDR("10.0.0.0/8")
+				cfg = kawasaki.NetworkConfig{
+					IPTableInstance: "instance",
+					BridgeName:      "the-bridge-name",
+					ContainerIP:     net.ParseIP("1.2.3.4"),
+					ContainerHandle: "some-handle",
+					Overrides: kawasaki.NetworkOverrides{
+						AllowHostAccess: true,
+						DenyNetworks:    []*net.IPNet{denied},
+					},
+				}
+			})
+
+			It("creates the overrides after the instance chain", func() {
+				fakeInstanceChainCreator.CreateOverridesStub = func(lager.Logger, string, string, string, net.IP, kawasaki.NetworkOverrides) error {
+					Expect(fakeInstanceChainCreator.CreateCallCount()).To(Equal(1))
+					return nil
+				}
+
+				Expect(configurer.Apply(logger, cfg, 42)).To(Succeed())
+				Expect(fakeInstanceChainCreator.CreateOverridesCallCount()).To(Equal(1))
+				_, handle, instanceChain, bridgeName, ip, overrides := fakeInstanceChainCreator.CreateOverridesArgsForCall(0)
+				Expect(handle).To(Equal("some-handle"))
+				Expect(instanceChain).To(Equal("instance"))
+				Expect(bridgeName).To(Equal("the-bridge-name"))
+				Expect(ip).To(Equal(net.ParseIP("1.2.3.4")))
+				Expect(overrides).To(Equal(cfg.Overrides))
+			})
+
+			Context("when creating the overrides fails", func() {
+				BeforeEach(func() {
+					fakeInstanceChainCreator.CreateOverridesReturns(errors.New("no overrides"))
+				})
+
+				It("returns the error", func() {
+					Expect(configurer.Apply(logger, cfg, 42)).To(MatchError("no overrides"))
+				})
+
+				It("does not configure the container", func() {
+					configurer.Apply(logger, cfg, 42)
+					Expect(fakeContainerConfigurer.ApplyCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		It("does not create connection limits when none are requested", func() {
+			Expect(configurer.Apply(logger, kawasaki.NetworkConfig{}, 42)).To(Succeed())
+			Expect(fakeInstanceChainCreator.CreateConnectionLimitsCallCount()).To(Equal(0))
+		})
+
+		Context("when connection limits are requested", func() {
+			var cfg kawasaki.NetworkConfig
+
+			BeforeEach(func() {
+				cfg = kawasaki.NetworkConfig{
+					IPTableInstance: "instance",
+					BridgeName:      "the-bridge-name",
+					ContainerIP:     net.ParseIP("1.2.3.4"),
+					ContainerHandle: "some-handle",
+					ConnectionLimits: kawasaki.ConnectionLimits{
+						NewConnectionsPerSecond: 50,
+						MaxConnections:          200,
+					},
+				}
+			})
+
+			It("creates the connection limits after the instance chain", func() {
+				fakeInstanceChainCreator.CreateConnectionLimitsStub = func(lager.Logger, string, string, string, net.IP, kawasaki.ConnectionLimits) error {
+					Expect(fakeInstanceChainCreator.CreateCallCount()).To(Equal(1))
+					return nil
+				}
+
+				Expect(configurer.Apply(logger, cfg, 42)).To(Succeed())
+				Expect(fakeInstanceChainCreator.CreateConnectionLimitsCallCount()).To(Equal(1))
+				_, handle, instanceChain, bridgeName, ip, limits := fakeInstanceChainCreator.CreateConnectionLimitsArgsForCall(0)
+				Expect(handle).To(Equal("some-handle"))
+				Expect(instanceChain).To(Equal("instance"))
+				Expect(bridgeName).To(Equal("the-bridge-name"))
+				Expect(ip).To(Equal(net.ParseIP("1.2.3.4")))
+				Expect(limits).To(Equal(cfg.ConnectionLimits))
+			})
+
+			Context("when creating the connection limits fails", func() {
+				BeforeEach(func() {
+					fakeInstanceChainCreator.CreateConnectionLimitsReturns(errors.New("no limits"))
+				})
+
+				It("returns the error", func() {
+					Expect(configurer.Apply(logger, cfg, 42)).To(MatchError("no limits"))
+				})
+
+				It("does not configure the container", func() {
+					configurer.Apply(logger, cfg, 42)
+					Expect(fakeContainerConfigurer.ApplyCallCount()).To(Equal(0))
+				})
+			})
+		})
+
 		It("applies the configuration in the container", func() {
 			cfg := kawasaki.NetworkConfig{
 				ContainerIntf: "banana",
@@ -155,6 +264,262 @@ var _ = Describe("Configurer", func() {
 				Expect(configurer.Apply(logger, kawasaki.NetworkConfig{}, 42)).To(MatchError("banana"))
 			})
 		})
+
+		Context("when the container is directly attached", func() {
+			var cfg kawasaki.NetworkConfig
+
+			BeforeEach(func() {
+				cfg = kawasaki.NetworkConfig{
+					ContainerIntf: "banana",
+					Mode:          "macvlan",
+				}
+			})
+
+			It("applies the direct configuration in the host", func() {
+				Expect(configurer.Apply(logger, cfg, 42)).To(Succeed())
+
+				Expect(fakeDirectConfigurer.ApplyCallCount()).To(Equal(1))
+				_, cfgArg, pid := fakeDirectConfigurer.ApplyArgsForCall(0)
+				Expect(cfgArg).To(Equal(cfg))
+				Expect(pid).To(Equal(42))
+
+				Expect(fakeHostConfigurer.ApplyCallCount()).To(Equal(0))
+			})
+
+			It("does not configure IPTables", func() {
+				Expect(configurer.Apply(logger, cfg, 42)).To(Succeed())
+				Expect(fakeInstanceChainCreator.CreateCallCount()).To(Equal(0))
+			})
+
+			It("applies the configuration in the container", func() {
+				Expect(configurer.Apply(logger, cfg, 42)).To(Succeed())
+				Expect(fakeContainerConfigurer.ApplyCallCount()).To(Equal(1))
+			})
+
+			Context("if applying the direct config fails", func() {
+				BeforeEach(func() {
+					fakeDirectConfigurer.ApplyReturns(errors.New("direct-failed"))
+				})
+
+				It("returns the error", func() {
+					Expect(configurer.Apply(logger, cfg, 42)).To(MatchError("direct-failed"))
+				})
+
+				It("does not configure the container", func() {
+					configurer.Apply(logger, cfg, 42)
+					Expect(fakeContainerConfigurer.ApplyCallCount()).To(Equal(0))
+				})
+			})
+		})
+	})
+
+	Describe("Repair", func() {
+		var cfg kawasaki.NetworkConfig
+
+		BeforeEach(func() {
+			cfg = kawasaki.NetworkConfig{
+				ContainerHandle: "h",
+				IPTableInstance: "some-instance",
+				BridgeName:      "some-bridge",
+				ContainerIP:     net.ParseIP("1.2.3.4"),
+				Subnet:          &net.IPNet{IP: net.ParseIP("1.2.3.0"), Mask: net.CIDRMask(30, 32)},
+				ConnectionLimits: kawasaki.ConnectionLimits{
+					MaxConnections: 10,
+				},
+			}
+
+			fakeInstanceChainCreator.VerifyReturns(true, nil)
+		})
+
+		It("repairs the host configuration", func() {
+			_, err := configurer.Repair(logger, cfg, 42, true)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeHostConfigurer.RepairCallCount()).To(Equal(1))
+			_, actualCfg, pid := fakeHostConfigurer.RepairArgsForCall(0)
+			Expect(actualCfg).To(Equal(cfg))
+			Expect(pid).To(Equal(42))
+		})
+
+		It("verifies the instance's iptables chains", func() {
+			_, err := configurer.Repair(logger, cfg, 42, true)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeInstanceChainCreator.VerifyCallCount()).To(Equal(1))
+			_, instance, network, overrides, limits := fakeInstanceChainCreator.VerifyArgsForCall(0)
+			Expect(instance).To(Equal("some-instance"))
+			Expect(network).To(Equal(cfg.Subnet))
+			Expect(overrides).To(Equal(kawasaki.NetworkOverrides{}))
+			Expect(limits).To(Equal(kawasaki.ConnectionLimits{MaxConnections: 10}))
+		})
+
+		Context("when nothing is missing", func() {
+			It("repairs nothing", func() {
+				repairs, err := configurer.Repair(logger, cfg, 42, true)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repairs.Empty()).To(BeTrue())
+
+				Expect(fakeContainerConfigurer.ApplyCallCount()).To(Equal(0))
+				Expect(fakeInstanceChainCreator.DestroyCallCount()).To(Equal(0))
+				Expect(fakeInstanceChainCreator.CreateCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the veth pair is re-created", func() {
+			BeforeEach(func() {
+				fakeHostConfigurer.RepairReturns(kawasaki.Repairs{Veth: true}, nil)
+			})
+
+			It("configures the container's end of the pair", func() {
+				repairs, err := configurer.Repair(logger, cfg, 42, true)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repairs).To(Equal(kawasaki.Repairs{Veth: true}))
+
+				Expect(fakeContainerConfigurer.ApplyCallCount()).To(Equal(1))
+				_, actualCfg, pid := fakeContainerConfigurer.ApplyArgsForCall(0)
+				Expect(actualCfg).To(Equal(cfg))
+				Expect(pid).To(Equal(42))
+			})
+
+			Context("and configuring the container fails", func() {
+				It("returns the error", func() {
+					fakeContainerConfigurer.ApplyReturns(errors.New("oh no"))
+
+					_, err := configurer.Repair(logger, cfg, 42, true)
+					Expect(err).To(MatchError("oh no"))
+				})
+			})
+		})
+
+		Context("when only the bridge is re-created", func() {
+			It("does not configure the container", func() {
+				fakeHostConfigurer.RepairReturns(kawasaki.Repairs{Bridge: true}, nil)
+
+				repairs, err := configurer.Repair(logger, cfg, 42, true)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repairs).To(Equal(kawasaki.Repairs{Bridge: true}))
+
+				Expect(fakeContainerConfigurer.ApplyCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when repairing the host configuration fails", func() {
+			BeforeEach(func() {
+				fakeHostConfigurer.RepairReturns(kawasaki.Repairs{}, errors.New("no bridge for you"))
+			})
+
+			It("returns the error", func() {
+				_, err := configurer.Repair(logger, cfg, 42, true)
+				Expect(err).To(MatchError("no bridge for you"))
+			})
+
+			It("does not verify the iptables chains", func() {
+				configurer.Repair(logger, cfg, 42, true)
+				Expect(fakeInstanceChainCreator.VerifyCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the pid is not known", func() {
+			It("only verifies the iptables chains", func() {
+				_, err := configurer.Repair(logger, cfg, 0, true)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeHostConfigurer.RepairCallCount()).To(Equal(0))
+				Expect(fakeInstanceChainCreator.VerifyCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when the iptables chains are missing", func() {
+			BeforeEach(func() {
+				fakeInstanceChainCreator.VerifyReturns(false, nil)
+			})
+
+			It("removes what is left of them before re-creating them", func() {
+				repairs, err := configurer.Repair(logger, cfg, 42, true)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repairs).To(Equal(kawasaki.Repairs{IPTablesRules: true}))
+
+				Expect(fakeInstanceChainCreator.DestroyCallCount()).To(Equal(1))
+				_, instance := fakeInstanceChainCreator.DestroyArgsForCall(0)
+				Expect(instance).To(Equal("some-instance"))
+
+				Expect(fakeInstanceChainCreator.CreateCallCount()).To(Equal(1))
+				_, handle, instance, bridge, ip, _ := fakeInstanceChainCreator.CreateArgsForCall(0)
+				Expect(handle).To(Equal("h"))
+				Expect(instance).To(Equal("some-instance"))
+				Expect(bridge).To(Equal("some-bridge"))
+				Expect(ip).To(Equal(net.ParseIP("1.2.3.4")))
+			})
+
+			It("re-creates the connection limits", func() {
+				_, err := configurer.Repair(logger, cfg, 42, true)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeInstanceChainCreator.CreateConnectionLimitsCallCount()).To(Equal(1))
+				_, _, _, _, _, limits := fakeInstanceChainCreator.CreateConnectionLimitsArgsForCall(0)
+				Expect(limits).To(Equal(kawasaki.ConnectionLimits{MaxConnections: 10}))
+			})
+
+			It("does not re-create overrides which were not requested", func() {
+				_, err := configurer.Repair(logger, cfg, 42, true)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeInstanceChainCreator.CreateOverridesCallCount()).To(Equal(0))
+			})
+
+			Context("when the chains may not be re-created", func() {
+				It("returns an error, and leaves them alone", func() {
+					_, err := configurer.Repair(logger, cfg, 42, false)
+					Expect(err).To(MatchError("iptables chains of instance some-instance are missing, and cannot be re-created without losing rules"))
+
+					Expect(fakeInstanceChainCreator.DestroyCallCount()).To(Equal(0))
+					Expect(fakeInstanceChainCreator.CreateCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when removing the remains fails", func() {
+				It("returns the error", func() {
+					fakeInstanceChainCreator.DestroyReturns(errors.New("stuck"))
+
+					_, err := configurer.Repair(logger, cfg, 42, true)
+					Expect(err).To(MatchError("stuck"))
+					Expect(fakeInstanceChainCreator.CreateCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when re-creating the chains fails", func() {
+				It("returns the error", func() {
+					fakeInstanceChainCreator.CreateReturns(errors.New("iptables says no"))
+
+					_, err := configurer.Repair(logger, cfg, 42, true)
+					Expect(err).To(MatchError("iptables says no"))
+				})
+			})
+		})
+
+		Context("when verifying the iptables chains fails", func() {
+			It("returns the error", func() {
+				fakeInstanceChainCreator.VerifyReturns(false, errors.New("no forward chain"))
+
+				_, err := configurer.Repair(logger, cfg, 42, true)
+				Expect(err).To(MatchError("no forward chain"))
+				Expect(fakeInstanceChainCreator.CreateCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the container is directly attached", func() {
+			It("repairs nothing", func() {
+				cfg.Mode = "macvlan"
+
+				repairs, err := configurer.Repair(logger, cfg, 42, true)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repairs.Empty()).To(BeTrue())
+
+				Expect(fakeHostConfigurer.RepairCallCount()).To(Equal(0))
+				Expect(fakeDirectConfigurer.RepairCallCount()).To(Equal(0))
+				Expect(fakeInstanceChainCreator.VerifyCallCount()).To(Equal(0))
+			})
+		})
 	})
 
 	Describe("DestroyBridge", func() {
@@ -202,4 +567,27 @@ var _ = Describe("Configurer", func() {
 			})
 		})
 	})
+
+	Describe("ConfigureDNS", func() {
+		It("configures the container's DNS only", func() {
+			cfg := kawasaki.NetworkConfig{ContainerHandle: "sidecar"}
+			Expect(configurer.ConfigureDNS(logger, cfg, 43)).To(Succeed())
+
+			Expect(fakeDnsResolvConfigurer.ConfigureCallCount()).To(Equal(1))
+			_, actualCfg, pid := fakeDnsResolvConfigurer.ConfigureArgsForCall(0)
+			Expect(actualCfg).To(Equal(cfg))
+			Expect(pid).To(Equal(43))
+
+			Expect(fakeHostConfigurer.ApplyCallCount()).To(Equal(0))
+			Expect(fakeContainerConfigurer.ApplyCallCount()).To(Equal(0))
+			Expect(fakeInstanceChainCreator.CreateCallCount()).To(Equal(0))
+		})
+
+		Context("when configuring DNS fails", func() {
+			It("returns the error", func() {
+				fakeDnsResolvConfigurer.ConfigureReturns(errors.New("no-resolv-conf"))
+				Expect(configurer.ConfigureDNS(logger, kawasaki.NetworkConfig{}, 43)).To(MatchError("no-resolv-conf"))
+			})
+		})
+	})
 })
@@ -0,0 +1,46 @@
+package kawasaki
+
+import (
+	"fmt"
+	"strconv"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+)
+
+// ConnectionLimits restrict the outbound connections a container may open.
+// A zero value leaves the corresponding limit unset.
+type ConnectionLimits struct {
+	NewConnectionsPerSecond uint32
+	MaxConnections          uint32
+}
+
+// Empty returns true if no limits are set
+func (l ConnectionLimits) Empty() bool {
+	return l.NewConnectionsPerSecond == 0 && l.MaxConnections == 0
+}
+
+// ParseConnectionLimits reads the connection limits requested by a container's
+// properties
+func ParseConnectionLimits(properties garden.Properties) (ConnectionLimits, error) {
+	var limits ConnectionLimits
+
+	for key, limit := range map[string]*uint32{
+		gardener.NetworkMaxNewConnectionsPerSecondKey: &limits.NewConnectionsPerSecond,
+		gardener.NetworkMaxConnectionsKey:             &limits.MaxConnections,
+	} {
+		value := properties[key]
+		if value == "" {
+			continue
+		}
+
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || parsed == 0 {
+			return ConnectionLimits{}, fmt.Errorf("invalid value for %s: %s", key, value)
+		}
+
+		*limit = uint32(parsed)
+	}
+
+	return limits, nil
+}
@@ -0,0 +1,49 @@
+package kawasaki_test
+
+import (
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/kawasaki"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseConnectionLimits", func() {
+	It("returns empty limits when no properties are given", func() {
+		limits, err := kawasaki.ParseConnectionLimits(garden.Properties{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(limits.Empty()).To(BeTrue())
+	})
+
+	It("parses the limits", func() {
+		limits, err := kawasaki.ParseConnectionLimits(garden.Properties{
+			gardener.NetworkMaxNewConnectionsPerSecondKey: "50",
+			gardener.NetworkMaxConnectionsKey:             "200",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(limits).To(Equal(kawasaki.ConnectionLimits{NewConnectionsPerSecond: 50, MaxConnections: 200}))
+		Expect(limits.Empty()).To(BeFalse())
+	})
+
+	It("allows either limit to be given alone", func() {
+		limits, err := kawasaki.ParseConnectionLimits(garden.Properties{
+			gardener.NetworkMaxConnectionsKey: "200",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(limits).To(Equal(kawasaki.ConnectionLimits{MaxConnections: 200}))
+	})
+
+	It("returns an error when a limit is not a number", func() {
+		_, err := kawasaki.ParseConnectionLimits(garden.Properties{
+			gardener.NetworkMaxNewConnectionsPerSecondKey: "lots",
+		})
+		Expect(err).To(MatchError("invalid value for garden.network.max-new-connections-per-second: lots"))
+	})
+
+	It("returns an error when a limit is zero", func() {
+		_, err := kawasaki.ParseConnectionLimits(garden.Properties{
+			gardener.NetworkMaxConnectionsKey: "0",
+		})
+		Expect(err).To(MatchError("invalid value for garden.network.max-connections: 0"))
+	})
+})
@@ -66,6 +66,20 @@ func (Bridge) Add(bridge, slaveIf *net.Interface) error {
 	return netlink.LinkSetMaster(slave, master.(*netlink.Bridge))
 }
 
+// EnableHairpin allows traffic from a bridge port to be sent back out of the
+// same port, which is needed for a container to reach its own mapped ports
+func (Bridge) EnableHairpin(slaveIf *net.Interface) error {
+	netlinkMu.Lock()
+	defer netlinkMu.Unlock()
+
+	slave, err := netlink.LinkByName(slaveIf.Name)
+	if err != nil {
+		return err
+	}
+
+	return netlink.LinkSetHairpin(slave, true)
+}
+
 func (Bridge) Destroy(bridge string) error {
 	netlinkMu.Lock()
 	defer netlinkMu.Unlock()
@@ -132,6 +132,15 @@ var _ = Describe("Bridge Management", func() {
 		})
 	})
 
+	Describe("EnableHairpin", func() {
+		Context("when the slave does not exist", func() {
+			It("returns the error", func() {
+				slave := &net.Interface{Name: "does not exist"}
+				Expect(b.EnableHairpin(slave)).To(MatchError("Link not found"))
+			})
+		})
+	})
+
 	Describe("Destroy", func() {
 		Context("when the bridge exists", func() {
 			It("deletes it", func() {
@@ -21,6 +21,25 @@ func (f *FaveVethCreator) Create(hostIfcName, containerIfcName string) (*net.Int
 	return f.CreateReturns.Host, f.CreateReturns.Container, f.CreateReturns.Err
 }
 
+type FakeSublinkCreator struct {
+	CreateCalledWith struct {
+		Mode, ParentName, Name string
+	}
+
+	CreateReturns struct {
+		Interface *net.Interface
+		Err       error
+	}
+}
+
+func (f *FakeSublinkCreator) Create(mode, parentName, name string) (*net.Interface, error) {
+	f.CreateCalledWith.Mode = mode
+	f.CreateCalledWith.ParentName = parentName
+	f.CreateCalledWith.Name = name
+
+	return f.CreateReturns.Interface, f.CreateReturns.Err
+}
+
 type InterfaceIPAndSubnet struct {
 	Interface *net.Interface
 	IP        net.IP
@@ -45,6 +64,11 @@ type FakeLink struct {
 		Fd        int
 	}
 
+	SetAliasCalledWith struct {
+		Interface *net.Interface
+		Alias     string
+	}
+
 	SetUpFunc           func(*net.Interface) error
 	InterfaceByNameFunc func(string) (*net.Interface, bool, error)
 
@@ -52,6 +76,7 @@ type FakeLink struct {
 	AddDefaultGWReturns error
 	SetMTUReturns       error
 	SetNsReturns        error
+	SetAliasReturns     error
 	StatisticsReturns   error
 }
 
@@ -87,6 +112,12 @@ func (f *FakeLink) SetNs(intf *net.Interface, fd int) error {
 	return f.SetNsReturns
 }
 
+func (f *FakeLink) SetAlias(intf *net.Interface, alias string) error {
+	f.SetAliasCalledWith.Interface = intf
+	f.SetAliasCalledWith.Alias = alias
+	return f.SetAliasReturns
+}
+
 func (f *FakeLink) InterfaceByName(name string) (*net.Interface, bool, error) {
 	if f.InterfaceByNameFunc != nil {
 		return f.InterfaceByNameFunc(name)
@@ -124,6 +155,9 @@ type FakeBridge struct {
 
 	AddReturns error
 
+	EnableHairpinCalledWith *net.Interface
+	EnableHairpinReturns    error
+
 	DestroyCalledWith []string
 
 	DestroyReturns error
@@ -142,6 +176,11 @@ func (f *FakeBridge) Add(bridge, slave *net.Interface) error {
 	return f.AddReturns
 }
 
+func (f *FakeBridge) EnableHairpin(slave *net.Interface) error {
+	f.EnableHairpinCalledWith = slave
+	return f.EnableHairpinReturns
+}
+
 func (f *FakeBridge) Destroy(bridge string) error {
 	f.DestroyCalledWith = append(f.DestroyCalledWith, bridge)
 	return f.DestroyReturns
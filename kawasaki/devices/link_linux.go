@@ -12,6 +12,9 @@ import (
 	"github.com/vishvananda/netlink"
 )
 
+// maxAliasLen is the longest interface alias Linux allows
+const maxAliasLen = 255
+
 type Link struct {
 }
 
@@ -70,6 +73,28 @@ func (Link) SetMTU(intf *net.Interface, mtu int) error {
 	return errF(netlink.LinkSetMTU(link, mtu))
 }
 
+// Rename renames an interface, which must be down
+func (Link) Rename(intf *net.Interface, name string) error {
+	netlinkMu.Lock()
+	defer netlinkMu.Unlock()
+
+	link, err := netlink.LinkByName(intf.Name)
+	if err != nil {
+		return errF(err)
+	}
+
+	return errF(netlink.LinkSetName(link, name))
+}
+
+// SetAlias sets the description shown alongside an interface by ip link
+func (Link) SetAlias(intf *net.Interface, alias string) error {
+	if len(alias) > maxAliasLen {
+		alias = alias[:maxAliasLen]
+	}
+
+	return ioutil.WriteFile(filepath.Join("/sys/class/net", intf.Name, "ifalias"), []byte(alias), 0644)
+}
+
 func (Link) SetNs(intf *net.Interface, ns int) error {
 	netlinkMu.Lock()
 	defer netlinkMu.Unlock()
@@ -2,9 +2,12 @@ package devices_test
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"code.cloudfoundry.org/guardian/kawasaki/devices"
 	. "github.com/onsi/ginkgo"
@@ -114,6 +117,57 @@ var _ = Describe("Link Management", func() {
 		})
 	})
 
+	Describe("Rename", func() {
+		Context("when the interface does not exist", func() {
+			It("returns an error", func() {
+				Expect(l.Rename(&net.Interface{Name: "something"}, "something-else")).To(MatchError("devices: Link not found"))
+			})
+		})
+
+		Context("when the interface exists", func() {
+			var newName string
+
+			BeforeEach(func() {
+				newName = fmt.Sprintf("gdn-renamed-%d", GinkgoParallelNode())
+			})
+
+			AfterEach(func() {
+				cleanup(newName)
+			})
+
+			It("renames the interface", func() {
+				Expect(l.Rename(intf, newName)).To(Succeed())
+
+				_, err := net.InterfaceByName(newName)
+				Expect(err).ToNot(HaveOccurred())
+				_, err = net.InterfaceByName(name)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("SetAlias", func() {
+		Context("when the interface does not exist", func() {
+			It("returns an error", func() {
+				Expect(l.SetAlias(&net.Interface{Name: "something"}, "some-alias")).NotTo(Succeed())
+			})
+		})
+
+		Context("when the interface exists", func() {
+			It("sets the alias", func() {
+				Expect(l.SetAlias(intf, "some-alias")).To(Succeed())
+
+				Expect(linkAlias(name)).To(Equal("some-alias"))
+			})
+
+			It("truncates aliases which are too long", func() {
+				Expect(l.SetAlias(intf, strings.Repeat("a", 300))).To(Succeed())
+
+				Expect(linkAlias(name)).To(Equal(strings.Repeat("a", 255)))
+			})
+		})
+	})
+
 	Describe("SetNs", func() {
 		var netnsName string
 
@@ -252,3 +306,9 @@ var _ = Describe("Link Management", func() {
 		})
 	})
 })
+
+func linkAlias(name string) string {
+	alias, err := ioutil.ReadFile(filepath.Join("/sys/class/net", name, "ifalias"))
+	Expect(err).ToNot(HaveOccurred())
+	return strings.TrimSpace(string(alias))
+}
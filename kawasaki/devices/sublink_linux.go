@@ -0,0 +1,44 @@
+package devices
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+)
+
+// SublinkCreator creates macvlan and ipvlan interfaces on top of a host interface
+type SublinkCreator struct{}
+
+func (SublinkCreator) Create(mode, parentName, name string) (*net.Interface, error) {
+	netlinkMu.Lock()
+	defer netlinkMu.Unlock()
+
+	parent, err := netlink.LinkByName(parentName)
+	if err != nil {
+		return nil, fmt.Errorf("devices: look up parent interface: %v", err)
+	}
+
+	attrs := netlink.LinkAttrs{Name: name, ParentIndex: parent.Attrs().Index}
+
+	var link netlink.Link
+	switch mode {
+	case "macvlan":
+		link = &netlink.Macvlan{LinkAttrs: attrs, Mode: netlink.MACVLAN_MODE_BRIDGE}
+	case "ipvlan":
+		link = &netlink.IPVlan{LinkAttrs: attrs, Mode: netlink.IPVLAN_MODE_L2}
+	default:
+		return nil, fmt.Errorf("devices: unknown sublink mode: %s", mode)
+	}
+
+	if err := netlink.LinkAdd(link); err != nil {
+		return nil, fmt.Errorf("devices: create %s interface: %v", mode, err)
+	}
+
+	intf, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("devices: look up created %s interface: %v", mode, err)
+	}
+
+	return intf, nil
+}
@@ -0,0 +1,70 @@
+package devices_test
+
+import (
+	"fmt"
+	"net"
+
+	"code.cloudfoundry.org/guardian/kawasaki/devices"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/vishvananda/netlink"
+)
+
+var _ = Describe("Sublink Creation", func() {
+	var (
+		s                   devices.SublinkCreator
+		parentName, subName string
+	)
+
+	BeforeEach(func() {
+		parentName = fmt.Sprintf("doesntexist-p-%d", GinkgoParallelNode())
+		subName = fmt.Sprintf("doesntexist-s-%d", GinkgoParallelNode())
+
+		Expect(netlink.LinkAdd(&netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: parentName}})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(cleanup(subName)).To(Succeed())
+		Expect(cleanup(parentName)).To(Succeed())
+	})
+
+	Context("when the mode is macvlan", func() {
+		It("creates a macvlan interface on the parent", func() {
+			intf, err := s.Create("macvlan", parentName, subName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(intf.Name).To(Equal(subName))
+
+			link, err := netlink.LinkByName(subName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link.Type()).To(Equal("macvlan"))
+		})
+	})
+
+	Context("when the mode is ipvlan", func() {
+		It("creates an ipvlan interface on the parent", func() {
+			_, err := s.Create("ipvlan", parentName, subName)
+			Expect(err).NotTo(HaveOccurred())
+
+			link, err := netlink.LinkByName(subName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link.Type()).To(Equal("ipvlan"))
+		})
+	})
+
+	Context("when the mode is unknown", func() {
+		It("returns an error", func() {
+			_, err := s.Create("banana", parentName, subName)
+			Expect(err).To(MatchError("devices: unknown sublink mode: banana"))
+		})
+	})
+
+	Context("when the parent does not exist", func() {
+		It("returns an error", func() {
+			_, err := s.Create("macvlan", "doesntexist-nope", subName)
+			Expect(err).To(HaveOccurred())
+
+			_, err = net.InterfaceByName(subName)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,39 @@
+package kawasaki
+
+import (
+	"net"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/kawasaki/subnets"
+)
+
+// DirectNetwork describes the host network to which containers in macvlan or
+// ipvlan mode are attached
+type DirectNetwork struct {
+	// Host interface on which container interfaces are created
+	Interface string
+
+	// Network the host interface is attached to
+	Subnet  *net.IPNet
+	Gateway net.IP
+
+	// Range within the subnet from which container IPs are allocated
+	Range *net.IPNet
+	Pool  subnets.Pool
+}
+
+// IsDirectMode returns true if the network mode attaches containers directly
+// to a host interface
+func IsDirectMode(mode string) bool {
+	return mode == gardener.NetworkModeMacvlan || mode == gardener.NetworkModeIpvlan
+}
+
+// rangeSelector always selects the whole direct range, so that container IPs
+// are allocated individually from it
+type rangeSelector struct {
+	*net.IPNet
+}
+
+func (s rangeSelector) SelectSubnet(dynamic *net.IPNet, existing []*net.IPNet) (*net.IPNet, error) {
+	return s.IPNet, nil
+}
@@ -0,0 +1,153 @@
+package dns
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	typeA     = 1
+	typeCNAME = 5
+	classIN   = 1
+
+	headerLen = 12
+
+	// The most pointers followed while reading a compressed name
+	maxPointers = 16
+)
+
+var rcodeNames = map[int]string{
+	0: "NOERROR",
+	1: "FORMERR",
+	2: "SERVFAIL",
+	3: "NXDOMAIN",
+	4: "NOTIMP",
+	5: "REFUSED",
+}
+
+func rcodeName(rcode int) string {
+	if name, ok := rcodeNames[rcode]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("RCODE%d", rcode)
+}
+
+type record struct {
+	Type uint16
+	TTL  uint32
+	Data []byte
+}
+
+type response struct {
+	ID      uint16
+	Rcode   int
+	Answers []record
+}
+
+// addressQuery encodes a recursive query for the A records of a hostname
+func addressQuery(id uint16, hostname string) ([]byte, error) {
+	msg := make([]byte, headerLen, headerLen+len(hostname)+6)
+	binary.BigEndian.PutUint16(msg[0:], id)
+	binary.BigEndian.PutUint16(msg[2:], 0x0100) // recursion desired
+	binary.BigEndian.PutUint16(msg[4:], 1)      // one question
+
+	for _, label := range strings.Split(strings.TrimSuffix(hostname, "."), ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return nil, fmt.Errorf("invalid hostname %s", hostname)
+		}
+
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0)
+
+	msg = append(msg, 0, typeA, 0, classIN)
+	return msg, nil
+}
+
+// parseResponse decodes the header and answer records of a response
+func parseResponse(msg []byte) (response, error) {
+	if len(msg) < headerLen {
+		return response{}, errors.New("short response")
+	}
+
+	resp := response{
+		ID:    binary.BigEndian.Uint16(msg[0:]),
+		Rcode: int(msg[3] & 0x0f),
+	}
+
+	questions := int(binary.BigEndian.Uint16(msg[4:]))
+	answers := int(binary.BigEndian.Uint16(msg[6:]))
+
+	offset := headerLen
+	for i := 0; i < questions; i++ {
+		var err error
+		if offset, err = skipName(msg, offset); err != nil {
+			return response{}, err
+		}
+
+		offset += 4 // type and class
+	}
+
+	for i := 0; i < answers; i++ {
+		var err error
+		if offset, err = skipName(msg, offset); err != nil {
+			return response{}, err
+		}
+
+		if offset+10 > len(msg) {
+			return response{}, errors.New("short answer record")
+		}
+
+		rr := record{
+			Type: binary.BigEndian.Uint16(msg[offset:]),
+			TTL:  binary.BigEndian.Uint32(msg[offset+4:]),
+		}
+		length := int(binary.BigEndian.Uint16(msg[offset+8:]))
+		offset += 10
+
+		if offset+length > len(msg) {
+			return response{}, errors.New("short answer record")
+		}
+
+		rr.Data = msg[offset : offset+length]
+		offset += length
+
+		resp.Answers = append(resp.Answers, rr)
+	}
+
+	return resp, nil
+}
+
+// skipName returns the offset following the, possibly compressed, name at
+// offset
+func skipName(msg []byte, offset int) (int, error) {
+	for pointers := 0; ; {
+		if offset >= len(msg) {
+			return 0, errors.New("short name")
+		}
+
+		length := int(msg[offset])
+		switch {
+		case length == 0:
+			return offset + 1, nil
+		case length&0xc0 == 0xc0:
+			// a pointer ends the name where it appears
+			if offset+2 > len(msg) {
+				return 0, errors.New("short name")
+			}
+
+			pointers++
+			if pointers > maxPointers {
+				return 0, errors.New("too many compression pointers")
+			}
+
+			return offset + 2, nil
+		default:
+			offset += 1 + length
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package dns
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// Resolver looks up the IPv4 addresses of hostnames, along with the time for
+// which they may be cached, by querying nameservers directly
+type Resolver struct {
+	// Nameservers are read from this file when none are given
+	ResolvConfPath string
+
+	// Port on which nameservers are queried, 53 if zero
+	Port int
+
+	Timeout time.Duration
+}
+
+func (r *Resolver) Resolve(log lager.Logger, servers []net.IP, hostname string) ([]net.IP, time.Duration, error) {
+	log = log.Session("resolve", lager.Data{"hostname": hostname, "servers": servers})
+
+	addresses := make([]string, 0, len(servers))
+	for _, server := range servers {
+		addresses = append(addresses, server.String())
+	}
+
+	if len(addresses) == 0 {
+		var err error
+		addresses, err = readNameservers(r.ResolvConfPath)
+		if err != nil {
+			log.Error("reading-resolv-conf", err)
+			return nil, 0, fmt.Errorf("reading nameservers from '%s': %s", r.ResolvConfPath, err)
+		}
+	}
+
+	if len(addresses) == 0 {
+		return nil, 0, errors.New("no nameservers configured")
+	}
+
+	port := r.Port
+	if port == 0 {
+		port = 53
+	}
+
+	var lastErr error
+	for _, address := range addresses {
+		response, err := r.exchange(net.JoinHostPort(address, strconv.Itoa(port)), hostname)
+		if err != nil {
+			log.Error("exchange-failed", err, lager.Data{"server": address})
+			lastErr = err
+			continue
+		}
+
+		if response.Rcode != 0 {
+			lastErr = fmt.Errorf("nameserver %s returned %s", address, rcodeName(response.Rcode))
+			continue
+		}
+
+		ips, ttl := addressRecords(response.Answers)
+		if len(ips) == 0 {
+			lastErr = fmt.Errorf("nameserver %s returned no addresses", address)
+			continue
+		}
+
+		return ips, ttl, nil
+	}
+
+	return nil, 0, lastErr
+}
+
+// exchange sends a query for the A records of hostname to a nameserver over
+// UDP and waits for its response
+func (r *Resolver) exchange(address, hostname string) (response, error) {
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return response{}, err
+	}
+
+	query, err := addressQuery(binary.BigEndian.Uint16(id[:]), hostname)
+	if err != nil {
+		return response{}, err
+	}
+
+	conn, err := net.DialTimeout("udp", address, r.Timeout)
+	if err != nil {
+		return response{}, err
+	}
+	defer conn.Close()
+
+	if r.Timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(r.Timeout)); err != nil {
+			return response{}, err
+		}
+	}
+
+	if _, err := conn.Write(query); err != nil {
+		return response{}, err
+	}
+
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return response{}, err
+		}
+
+		resp, err := parseResponse(buf[:n])
+		if err != nil {
+			return response{}, err
+		}
+
+		// ignore stray responses to earlier queries
+		if resp.ID == binary.BigEndian.Uint16(id[:]) {
+			return resp, nil
+		}
+	}
+}
+
+// readNameservers returns the nameserver addresses listed in a resolv.conf file
+func readNameservers(path string) ([]string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var servers []string
+	for _, line := range strings.Split(string(contents), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			servers = append(servers, fields[1])
+		}
+	}
+
+	return servers, nil
+}
+
+// addressRecords returns the A records of an answer and the lowest TTL of any
+// record, including the CNAME records leading to them
+func addressRecords(answers []record) ([]net.IP, time.Duration) {
+	var ips []net.IP
+	var ttl uint32
+
+	for i, rr := range answers {
+		if i == 0 || rr.TTL < ttl {
+			ttl = rr.TTL
+		}
+
+		if rr.Type == typeA && len(rr.Data) == net.IPv4len {
+			ips = append(ips, net.IP(append([]byte{}, rr.Data...)))
+		}
+	}
+
+	return ips, time.Duration(ttl) * time.Second
+}
@@ -0,0 +1,214 @@
+package dns_test
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/guardian/kawasaki/dns"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// testRecord is an answer record served by the fake nameserver: an A record
+// if it has an address, a CNAME record otherwise
+type testRecord struct {
+	name    string
+	ttl     uint32
+	address string
+	cname   string
+}
+
+func encodeName(name string) []byte {
+	var encoded []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		encoded = append(encoded, byte(len(label)))
+		encoded = append(encoded, label...)
+	}
+
+	return append(encoded, 0)
+}
+
+// reply answers a query, naming records which answer the question by a
+// pointer to it, as nameservers compress their responses
+func reply(query []byte, records map[string][]testRecord) []byte {
+	questionEnd := 12
+	var labels []string
+	for query[questionEnd] != 0 {
+		length := int(query[questionEnd])
+		labels = append(labels, string(query[questionEnd+1:questionEnd+1+length]))
+		questionEnd += 1 + length
+	}
+	questionEnd += 5
+	question := strings.Join(labels, ".") + "."
+
+	answers, ok := records[question]
+
+	msg := make([]byte, 12)
+	copy(msg, query[0:2])
+	binary.BigEndian.PutUint16(msg[2:], 0x8180)
+	if !ok {
+		msg[3] |= 3 // NXDOMAIN
+	}
+	binary.BigEndian.PutUint16(msg[4:], 1)
+	binary.BigEndian.PutUint16(msg[6:], uint16(len(answers)))
+	msg = append(msg, query[12:questionEnd]...)
+
+	for _, answer := range answers {
+		if answer.name == question {
+			msg = append(msg, 0xc0, 12)
+		} else {
+			msg = append(msg, encodeName(answer.name)...)
+		}
+
+		data := encodeName(answer.cname)
+		recordType := byte(5)
+		if answer.address != "" {
+			data = net.ParseIP(answer.address).To4()
+			recordType = 1
+		}
+
+		header := make([]byte, 10)
+		header[1] = recordType
+		header[3] = 1
+		binary.BigEndian.PutUint32(header[4:], answer.ttl)
+		binary.BigEndian.PutUint16(header[8:], uint16(len(data)))
+
+		msg = append(msg, header...)
+		msg = append(msg, data...)
+	}
+
+	return msg
+}
+
+var _ = Describe("Resolver", func() {
+	var (
+		log      lager.Logger
+		conn     net.PacketConn
+		port     int
+		records  map[string][]testRecord
+		resolver *dns.Resolver
+	)
+
+	BeforeEach(func() {
+		log = lagertest.NewTestLogger("test")
+		records = map[string][]testRecord{}
+
+		var err error
+		conn, err = net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		port = conn.LocalAddr().(*net.UDPAddr).Port
+
+		served := records
+		go func() {
+			defer GinkgoRecover()
+
+			buf := make([]byte, 512)
+			for {
+				n, addr, err := conn.ReadFrom(buf)
+				if err != nil {
+					return
+				}
+
+				_, err = conn.WriteTo(reply(buf[:n], served), addr)
+				Expect(err).NotTo(HaveOccurred())
+			}
+		}()
+
+		resolver = &dns.Resolver{
+			ResolvConfPath: "/does/not/exist.conf",
+			Port:           port,
+			Timeout:        time.Second,
+		}
+	})
+
+	AfterEach(func() {
+		Expect(conn.Close()).To(Succeed())
+	})
+
+	It("returns the addresses of the hostname and their TTL", func() {
+		records["example.com."] = []testRecord{
+			{name: "example.com.", ttl: 300, address: "1.2.3.4"},
+			{name: "example.com.", ttl: 60, address: "1.2.3.5"},
+		}
+
+		ips, ttl, err := resolver.Resolve(log, []net.IP{net.ParseIP("127.0.0.1")}, "example.com")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ips).To(HaveLen(2))
+		Expect(ips[0].String()).To(Equal("1.2.3.4"))
+		Expect(ips[1].String()).To(Equal("1.2.3.5"))
+		Expect(ttl).To(Equal(60 * time.Second))
+	})
+
+	It("takes the TTL of CNAME records into account", func() {
+		records["www.example.com."] = []testRecord{
+			{name: "www.example.com.", ttl: 30, cname: "example.com."},
+			{name: "example.com.", ttl: 300, address: "1.2.3.4"},
+		}
+
+		ips, ttl, err := resolver.Resolve(log, []net.IP{net.ParseIP("127.0.0.1")}, "www.example.com")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ips).To(HaveLen(1))
+		Expect(ttl).To(Equal(30 * time.Second))
+	})
+
+	Context("when the hostname does not exist", func() {
+		It("returns an error", func() {
+			_, _, err := resolver.Resolve(log, []net.IP{net.ParseIP("127.0.0.1")}, "missing.example.com")
+			Expect(err).To(MatchError("nameserver 127.0.0.1 returned NXDOMAIN"))
+		})
+	})
+
+	Context("when the hostname has no addresses", func() {
+		It("returns an error", func() {
+			records["empty.example.com."] = []testRecord{}
+
+			_, _, err := resolver.Resolve(log, []net.IP{net.ParseIP("127.0.0.1")}, "empty.example.com")
+			Expect(err).To(MatchError("nameserver 127.0.0.1 returned no addresses"))
+		})
+	})
+
+	Context("when no servers are given", func() {
+		var resolvConfPath string
+
+		BeforeEach(func() {
+			f, err := ioutil.TempFile("", "")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = f.WriteString("nameserver 127.0.0.1\n")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(f.Close()).To(Succeed())
+
+			resolvConfPath = f.Name()
+			resolver.ResolvConfPath = resolvConfPath
+		})
+
+		AfterEach(func() {
+			Expect(os.Remove(resolvConfPath)).To(Succeed())
+		})
+
+		It("uses the nameservers from the resolv.conf file", func() {
+			records["example.com."] = []testRecord{{name: "example.com.", ttl: 300, address: "1.2.3.4"}}
+
+			ips, _, err := resolver.Resolve(log, nil, "example.com")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ips).To(HaveLen(1))
+		})
+
+		Context("and the resolv.conf file does not exist", func() {
+			BeforeEach(func() {
+				resolver.ResolvConfPath = "/does/not/exist.conf"
+			})
+
+			It("returns an error", func() {
+				_, _, err := resolver.Resolve(log, nil, "example.com")
+				Expect(err).To(MatchError(ContainSubstring("reading nameservers from '/does/not/exist.conf'")))
+			})
+		})
+	})
+})
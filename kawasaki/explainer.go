@@ -0,0 +1,88 @@
+package kawasaki
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/lager"
+)
+
+// Traffic describes a new connection from a container, whose fate is to be
+// explained. ICMP traffic is taken to be an echo request.
+type Traffic struct {
+	Destination net.IP
+	Port        uint32
+	Protocol    garden.Protocol
+}
+
+// Explanation reports whether traffic would be allowed, and the rule which
+// decides it. Assumed lists the rules which the traffic was assumed to match,
+// as their options could not be evaluated; if it does not, its fate may differ.
+type Explanation struct {
+	Allowed bool     `json:"allowed"`
+	Chain   string   `json:"chain,omitempty"`
+	Rule    string   `json:"rule,omitempty"`
+	Reason  string   `json:"reason"`
+	Logged  bool     `json:"logged"`
+	Assumed []string `json:"assumed,omitempty"`
+}
+
+// ParseProtocol parses a protocol name as used by iptables, e.g. "tcp"
+func ParseProtocol(name string) (garden.Protocol, error) {
+	switch strings.ToLower(name) {
+	case "all":
+		return garden.ProtocolAll, nil
+	case "tcp":
+		return garden.ProtocolTCP, nil
+	case "udp":
+		return garden.ProtocolUDP, nil
+	case "icmp":
+		return garden.ProtocolICMP, nil
+	default:
+		return 0, fmt.Errorf("invalid protocol: %s", name)
+	}
+}
+
+//go:generate counterfeiter . FirewallExplainer
+
+type FirewallExplainer interface {
+	Explain(log lager.Logger, cfg NetworkConfig, traffic Traffic) (Explanation, error)
+}
+
+// Explainer explains the fate of traffic from a container by consulting its
+// firewall rules, rather than by tracing packets
+type Explainer struct {
+	configStore       ConfigStore
+	firewallExplainer FirewallExplainer
+}
+
+func NewExplainer(configStore ConfigStore, firewallExplainer FirewallExplainer) *Explainer {
+	return &Explainer{
+		configStore:       configStore,
+		firewallExplainer: firewallExplainer,
+	}
+}
+
+func (e *Explainer) Explain(log lager.Logger, handle string, traffic Traffic) (Explanation, error) {
+	log = log.Session("explain", lager.Data{"handle": handle, "destination": traffic.Destination, "port": traffic.Port, "protocol": traffic.Protocol})
+
+	// a container sharing another's network is subject to the owner's rules
+	if owner, ok := e.configStore.Get(handle, gardener.NetworkSharedWithKey); ok && owner != "" {
+		handle = owner
+	}
+
+	cfg, err := load(e.configStore, handle)
+	if err != nil {
+		log.Error("loading-config-failed", err)
+		return Explanation{}, fmt.Errorf("loading %s: %v", handle, err)
+	}
+
+	if IsDirectMode(cfg.Mode) {
+		return Explanation{}, fmt.Errorf("firewall rules are not supported in network mode %s", cfg.Mode)
+	}
+
+	return e.firewallExplainer.Explain(log, cfg, traffic)
+}
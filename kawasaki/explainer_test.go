@@ -0,0 +1,155 @@
+package kawasaki_test
+
+import (
+	"errors"
+	"net"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/kawasaki"
+	fakes "code.cloudfoundry.org/guardian/kawasaki/kawasakifakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseProtocol", func() {
+	It("parses protocol names, ignoring case", func() {
+		for name, protocol := range map[string]garden.Protocol{
+			"all":  garden.ProtocolAll,
+			"TCP":  garden.ProtocolTCP,
+			"udp":  garden.ProtocolUDP,
+			"icmp": garden.ProtocolICMP,
+		} {
+			parsed, err := kawasaki.ParseProtocol(name)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed).To(Equal(protocol))
+		}
+	})
+
+	It("returns an error for an unknown protocol", func() {
+		_, err := kawasaki.ParseProtocol("sctp")
+		Expect(err).To(MatchError("invalid protocol: sctp"))
+	})
+})
+
+var _ = Describe("Explainer", func() {
+	var (
+		fakeConfigStore       *fakes.FakeConfigStore
+		fakeFirewallExplainer *fakes.FakeFirewallExplainer
+		explainer             *kawasaki.Explainer
+		logger                lager.Logger
+		config                map[string]string
+		traffic               kawasaki.Traffic
+	)
+
+	BeforeEach(func() {
+		fakeConfigStore = new(fakes.FakeConfigStore)
+		fakeFirewallExplainer = new(fakes.FakeFirewallExplainer)
+		logger = lagertest.NewTestLogger("test")
+
+		config = map[string]string{
+			gardener.ContainerIPKey:        "10.0.0.2",
+			"kawasaki.host-interface":      "some-host-iface",
+			"kawasaki.container-interface": "some-container-iface",
+			"kawasaki.bridge-interface":    "some-bridge",
+			gardener.BridgeIPKey:           "10.0.0.1",
+			gardener.ExternalIPKey:         "203.0.113.1",
+			"kawasaki.subnet":              "10.0.0.0/30",
+			"kawasaki.iptable-prefix":      "w--",
+			"kawasaki.iptable-inst":        "some-instance",
+			"kawasaki.mtu":                 "1500",
+			"kawasaki.dns-servers":         "8.8.8.8",
+		}
+
+		fakeConfigStore.GetStub = func(handle, name string) (string, bool) {
+			Expect(handle).To(Equal("some-handle"))
+			val, ok := config[name]
+			return val, ok
+		}
+
+		traffic = kawasaki.Traffic{Destination: net.ParseIP("1.2.3.4"), Port: 80, Protocol: garden.ProtocolTCP}
+
+		explainer = kawasaki.NewExplainer(fakeConfigStore, fakeFirewallExplainer)
+	})
+
+	It("explains the traffic using the container's network config", func() {
+		_, err := explainer.Explain(logger, "some-handle", traffic)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeFirewallExplainer.ExplainCallCount()).To(Equal(1))
+		_, cfg, explainedTraffic := fakeFirewallExplainer.ExplainArgsForCall(0)
+		Expect(cfg.IPTableInstance).To(Equal("some-instance"))
+		Expect(cfg.BridgeName).To(Equal("some-bridge"))
+		Expect(cfg.ContainerIP.String()).To(Equal("10.0.0.2"))
+		Expect(explainedTraffic).To(Equal(traffic))
+	})
+
+	It("returns the explanation", func() {
+		fakeFirewallExplainer.ExplainReturns(kawasaki.Explanation{Allowed: true, Reason: "some-reason"}, nil)
+
+		explanation, err := explainer.Explain(logger, "some-handle", traffic)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(explanation).To(Equal(kawasaki.Explanation{Allowed: true, Reason: "some-reason"}))
+	})
+
+	Context("when explaining fails", func() {
+		BeforeEach(func() {
+			fakeFirewallExplainer.ExplainReturns(kawasaki.Explanation{}, errors.New("iptables-failed"))
+		})
+
+		It("returns the error", func() {
+			_, err := explainer.Explain(logger, "some-handle", traffic)
+			Expect(err).To(MatchError("iptables-failed"))
+		})
+	})
+
+	Context("when the container has no network config", func() {
+		BeforeEach(func() {
+			config = map[string]string{}
+		})
+
+		It("returns an error", func() {
+			_, err := explainer.Explain(logger, "some-handle", traffic)
+			Expect(err).To(MatchError(ContainSubstring("loading some-handle")))
+			Expect(fakeFirewallExplainer.ExplainCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when the container shares the network of another container", func() {
+		BeforeEach(func() {
+			fakeConfigStore.GetStub = func(handle, name string) (string, bool) {
+				if handle == "sidecar" {
+					return "some-handle", name == gardener.NetworkSharedWithKey
+				}
+
+				Expect(handle).To(Equal("some-handle"))
+				val, ok := config[name]
+				return val, ok
+			}
+		})
+
+		It("explains the traffic using the network config of that container", func() {
+			_, err := explainer.Explain(logger, "sidecar", traffic)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeFirewallExplainer.ExplainCallCount()).To(Equal(1))
+			_, cfg, _ := fakeFirewallExplainer.ExplainArgsForCall(0)
+			Expect(cfg.IPTableInstance).To(Equal("some-instance"))
+		})
+	})
+
+	Context("when the container uses a direct network mode", func() {
+		BeforeEach(func() {
+			config["kawasaki.mode"] = gardener.NetworkModeMacvlan
+		})
+
+		It("returns an error", func() {
+			_, err := explainer.Explain(logger, "some-handle", traffic)
+			Expect(err).To(MatchError("firewall rules are not supported in network mode macvlan"))
+			Expect(fakeFirewallExplainer.ExplainCallCount()).To(Equal(0))
+		})
+	})
+})
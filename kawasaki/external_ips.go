@@ -0,0 +1,84 @@
+package kawasaki
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+)
+
+// ExternalIPSelection is a container's choice among the server's external IPs
+// for binding its mapped ports
+type ExternalIPSelection struct {
+	// Default replaces the server's default external IP, if not nil
+	Default net.IP
+	// ByContainerPort chooses the external IP of individual mappings
+	ByContainerPort map[uint32]net.IP
+}
+
+// ExternalIP returns the external IP to which a mapping to the container port
+// is bound, given the container's external IP
+func (s ExternalIPSelection) ExternalIP(containerPort uint32, containerExternalIP net.IP) net.IP {
+	if ip, ok := s.ByContainerPort[containerPort]; ok {
+		return ip
+	}
+
+	return containerExternalIP
+}
+
+// ParseExternalIPSelection reads the external IPs requested by a container's
+// properties, which must be among the allowed IPs
+func ParseExternalIPSelection(properties garden.Properties, allowed []net.IP) (ExternalIPSelection, error) {
+	var selection ExternalIPSelection
+
+	if value := properties[gardener.DefaultExternalIPKey]; value != "" {
+		ip, err := parseExternalIP(value, allowed)
+		if err != nil {
+			return ExternalIPSelection{}, fmt.Errorf("invalid value for %s: %s", gardener.DefaultExternalIPKey, err)
+		}
+
+		selection.Default = ip
+	}
+
+	if value := properties[gardener.NetInExternalIPsKey]; value != "" {
+		var byPort map[string]string
+		if err := json.Unmarshal([]byte(value), &byPort); err != nil {
+			return ExternalIPSelection{}, fmt.Errorf("invalid value for %s: %s", gardener.NetInExternalIPsKey, err)
+		}
+
+		selection.ByContainerPort = make(map[uint32]net.IP)
+		for portString, ipString := range byPort {
+			port, err := strconv.ParseUint(portString, 10, 16)
+			if err != nil {
+				return ExternalIPSelection{}, fmt.Errorf("invalid value for %s: invalid port: %s", gardener.NetInExternalIPsKey, portString)
+			}
+
+			ip, err := parseExternalIP(ipString, allowed)
+			if err != nil {
+				return ExternalIPSelection{}, fmt.Errorf("invalid value for %s: %s", gardener.NetInExternalIPsKey, err)
+			}
+
+			selection.ByContainerPort[uint32(port)] = ip
+		}
+	}
+
+	return selection, nil
+}
+
+func parseExternalIP(value string, allowed []net.IP) (net.IP, error) {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP: %s", value)
+	}
+
+	for _, allowedIP := range allowed {
+		if allowedIP.Equal(ip) {
+			return ip, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s is not an external IP of the server", value)
+}
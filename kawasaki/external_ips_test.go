@@ -0,0 +1,71 @@
+package kawasaki_test
+
+import (
+	"net"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/kawasaki"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseExternalIPSelection", func() {
+	var allowed []net.IP
+
+	BeforeEach(func() {
+		allowed = []net.IP{net.ParseIP("203.0.113.1"), net.ParseIP("203.0.113.2")}
+	})
+
+	It("returns an empty selection when no properties are given", func() {
+		selection, err := kawasaki.ParseExternalIPSelection(garden.Properties{}, allowed)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selection.Default).To(BeNil())
+		Expect(selection.ExternalIP(8080, net.ParseIP("203.0.113.1"))).To(Equal(net.ParseIP("203.0.113.1")))
+	})
+
+	It("parses the default external IP", func() {
+		selection, err := kawasaki.ParseExternalIPSelection(garden.Properties{
+			gardener.DefaultExternalIPKey: "203.0.113.2",
+		}, allowed)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selection.Default.String()).To(Equal("203.0.113.2"))
+	})
+
+	It("parses the external IPs by container port", func() {
+		selection, err := kawasaki.ParseExternalIPSelection(garden.Properties{
+			gardener.NetInExternalIPsKey: `{"8080": "203.0.113.2"}`,
+		}, allowed)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selection.ExternalIP(8080, net.ParseIP("203.0.113.1")).String()).To(Equal("203.0.113.2"))
+		Expect(selection.ExternalIP(8081, net.ParseIP("203.0.113.1")).String()).To(Equal("203.0.113.1"))
+	})
+
+	It("returns an error when the default external IP is not an IP", func() {
+		_, err := kawasaki.ParseExternalIPSelection(garden.Properties{
+			gardener.DefaultExternalIPKey: "banana",
+		}, allowed)
+		Expect(err).To(MatchError("invalid value for garden.network.default-external-ip: invalid IP: banana"))
+	})
+
+	It("returns an error when an external IP is not one of the allowed IPs", func() {
+		_, err := kawasaki.ParseExternalIPSelection(garden.Properties{
+			gardener.NetInExternalIPsKey: `{"8080": "10.0.0.1"}`,
+		}, allowed)
+		Expect(err).To(MatchError("invalid value for garden.network.netin-external-ips: 10.0.0.1 is not an external IP of the server"))
+	})
+
+	It("returns an error when the external IPs by container port are not a JSON object", func() {
+		_, err := kawasaki.ParseExternalIPSelection(garden.Properties{
+			gardener.NetInExternalIPsKey: `["203.0.113.2"]`,
+		}, allowed)
+		Expect(err).To(MatchError(ContainSubstring("invalid value for garden.network.netin-external-ips")))
+	})
+
+	It("returns an error when a container port is invalid", func() {
+		_, err := kawasaki.ParseExternalIPSelection(garden.Properties{
+			gardener.NetInExternalIPsKey: `{"http": "203.0.113.2"}`,
+		}, allowed)
+		Expect(err).To(MatchError("invalid value for garden.network.netin-external-ips: invalid port: http"))
+	})
+})
@@ -11,7 +11,7 @@ import (
 	"code.cloudfoundry.org/guardian/kawasaki/netns"
 )
 
-func NewDefaultConfigurer(ipt *iptables.IPTablesController) kawasaki.Configurer {
+func NewDefaultConfigurer(ipt *iptables.IPTablesController, nflogGroup uint16) kawasaki.Configurer {
 	resolvConfigurer := &kawasaki.ResolvConfigurer{
 		HostsFileCompiler:  &dns.HostsFileCompiler{},
 		ResolvFileCompiler: &dns.ResolvFileCompiler{},
@@ -26,6 +26,12 @@ func NewDefaultConfigurer(ipt *iptables.IPTablesController) kawasaki.Configurer
 		FileOpener: netns.Opener(os.Open),
 	}
 
+	directConfigurer := &configure.Direct{
+		Sublink:    &devices.SublinkCreator{},
+		Link:       &devices.Link{},
+		FileOpener: netns.Opener(os.Open),
+	}
+
 	containerConfigurer := &configure.Container{
 		FileOpener: netns.Opener(os.Open),
 	}
@@ -33,7 +39,17 @@ func NewDefaultConfigurer(ipt *iptables.IPTablesController) kawasaki.Configurer
 	return kawasaki.NewConfigurer(
 		resolvConfigurer,
 		hostConfigurer,
+		directConfigurer,
 		containerConfigurer,
-		iptables.NewInstanceChainCreator(ipt),
+		iptables.NewInstanceChainCreator(ipt, nflogGroup),
 	)
 }
+
+// NewIDChecker checks that neither an interface nor an iptables chain on the
+// host is already named after an ID
+func NewIDChecker(ipt *iptables.IPTablesController, interfacePrefix string) kawasaki.IDChecker {
+	return kawasaki.IDCheckers{
+		&kawasaki.InterfaceIDChecker{InterfacePrefix: interfacePrefix, Link: &devices.Link{}},
+		iptables.NewChainIDChecker(ipt),
+	}
+}
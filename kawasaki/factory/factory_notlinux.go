@@ -7,6 +7,10 @@ import (
 	"code.cloudfoundry.org/guardian/kawasaki/iptables"
 )
 
-func NewDefaultConfigurer(ipt *iptables.IPTablesController) kawasaki.Configurer {
+func NewDefaultConfigurer(ipt *iptables.IPTablesController, nflogGroup uint16) kawasaki.Configurer {
+	panic("not supported on this platform")
+}
+
+func NewIDChecker(ipt *iptables.IPTablesController, interfacePrefix string) kawasaki.IDChecker {
 	panic("not supported on this platform")
 }
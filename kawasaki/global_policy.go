@@ -0,0 +1,48 @@
+package kawasaki
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+)
+
+// GlobalPolicy is the firewall policy which the server applies to all
+// containers, as set by --allow-host-access, --allow-network and
+// --deny-network. Allowed networks take precedence over denied ones.
+type GlobalPolicy struct {
+	AllowHostAccess bool     `json:"allow_host_access"`
+	AllowNetworks   []string `json:"allow_networks"`
+	DenyNetworks    []string `json:"deny_networks"`
+}
+
+// Validate returns an error if any of the policy's networks is not a CIDR
+func (p GlobalPolicy) Validate() error {
+	for _, network := range append(append([]string{}, p.AllowNetworks...), p.DenyNetworks...) {
+		if _, _, err := net.ParseCIDR(network); err != nil {
+			return fmt.Errorf("invalid network: %s", network)
+		}
+	}
+
+	return nil
+}
+
+// LoadGlobalPolicy reads a policy from a JSON file, e.g.
+// {"allow_host_access": false, "deny_networks": ["10.0.0.0/8"]}
+func LoadGlobalPolicy(path string) (GlobalPolicy, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return GlobalPolicy{}, err
+	}
+
+	var policy GlobalPolicy
+	if err := json.Unmarshal(contents, &policy); err != nil {
+		return GlobalPolicy{}, fmt.Errorf("parsing global policy %s: %s", path, err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return GlobalPolicy{}, fmt.Errorf("parsing global policy %s: %s", path, err)
+	}
+
+	return policy, nil
+}
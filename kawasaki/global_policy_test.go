@@ -0,0 +1,91 @@
+package kawasaki_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GlobalPolicy", func() {
+	Describe("Validate", func() {
+		It("accepts CIDRs", func() {
+			policy := kawasaki.GlobalPolicy{
+				AllowNetworks: []string{"10.0.1.0/24"},
+				DenyNetworks:  []string{"10.0.0.0/8", "0.0.0.0/0"},
+			}
+
+			Expect(policy.Validate()).To(Succeed())
+		})
+
+		It("rejects an allowed network which is not a CIDR", func() {
+			policy := kawasaki.GlobalPolicy{AllowNetworks: []string{"10.0.1.0"}}
+			Expect(policy.Validate()).To(MatchError("invalid network: 10.0.1.0"))
+		})
+
+		It("rejects a denied network which is not a CIDR", func() {
+			policy := kawasaki.GlobalPolicy{DenyNetworks: []string{"banana"}}
+			Expect(policy.Validate()).To(MatchError("invalid network: banana"))
+		})
+	})
+
+	Describe("LoadGlobalPolicy", func() {
+		var (
+			dir  string
+			path string
+		)
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "global-policy")
+			Expect(err).NotTo(HaveOccurred())
+
+			path = filepath.Join(dir, "policy.json")
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("reads the policy", func() {
+			Expect(ioutil.WriteFile(path, []byte(`{"allow_host_access": true, "allow_networks": ["10.0.1.0/24"], "deny_networks": ["10.0.0.0/8"]}`), 0600)).To(Succeed())
+
+			policy, err := kawasaki.LoadGlobalPolicy(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy).To(Equal(kawasaki.GlobalPolicy{
+				AllowHostAccess: true,
+				AllowNetworks:   []string{"10.0.1.0/24"},
+				DenyNetworks:    []string{"10.0.0.0/8"},
+			}))
+		})
+
+		Context("when the file does not exist", func() {
+			It("returns an error", func() {
+				_, err := kawasaki.LoadGlobalPolicy(path)
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+		})
+
+		Context("when the file is not JSON", func() {
+			It("returns an error", func() {
+				Expect(ioutil.WriteFile(path, []byte("banana"), 0600)).To(Succeed())
+
+				_, err := kawasaki.LoadGlobalPolicy(path)
+				Expect(err).To(MatchError(ContainSubstring("parsing global policy " + path)))
+			})
+		})
+
+		Context("when a network is invalid", func() {
+			It("returns an error", func() {
+				Expect(ioutil.WriteFile(path, []byte(`{"deny_networks": ["banana"]}`), 0600)).To(Succeed())
+
+				_, err := kawasaki.LoadGlobalPolicy(path)
+				Expect(err).To(MatchError(ContainSubstring("invalid network: banana")))
+			})
+		})
+	})
+})
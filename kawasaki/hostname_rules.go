@@ -0,0 +1,300 @@
+package kawasaki
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/lager"
+	"github.com/pivotal-golang/clock"
+)
+
+const (
+	// Resolved addresses are not refreshed more often than this, whatever their TTL
+	minHostnameTTL = 5 * time.Second
+
+	// A failed refresh leaves the previous rules in place and is retried after this
+	hostnameRetryInterval = 30 * time.Second
+
+	// How often the refresher looks for expired resolutions
+	hostnameRefreshInterval = time.Second
+)
+
+// HostnameRule allows traffic to the addresses that a set of hostnames resolve
+// to, as a garden.NetOutRule does for IP ranges
+type HostnameRule struct {
+	Protocol  garden.Protocol    `json:"protocol,omitempty"`
+	Hostnames []string           `json:"hostnames"`
+	Ports     []garden.PortRange `json:"ports,omitempty"`
+	Log       bool               `json:"log,omitempty"`
+}
+
+// ParseHostnameRules reads the JSON list of hostname rules held by a
+// container's NetOut hostnames property
+func ParseHostnameRules(value string) ([]HostnameRule, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var rules []HostnameRule
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return nil, fmt.Errorf("parsing hostname rules: %s", err)
+	}
+
+	for _, rule := range rules {
+		if len(rule.Hostnames) == 0 {
+			return nil, errors.New("parsing hostname rules: a rule has no hostnames")
+		}
+
+		for _, hostname := range rule.Hostnames {
+			if hostname == "" {
+				return nil, errors.New("parsing hostname rules: empty hostname")
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+//go:generate counterfeiter . HostnameResolver
+
+type HostnameResolver interface {
+	// Resolve returns the addresses of a hostname and how long they may be cached
+	Resolve(log lager.Logger, servers []net.IP, hostname string) ([]net.IP, time.Duration, error)
+}
+
+//go:generate counterfeiter . HostnameRules
+
+type HostnameRules interface {
+	Apply(log lager.Logger, cfg NetworkConfig, rules []HostnameRule) error
+	Restore(log lager.Logger, cfg NetworkConfig, rules []HostnameRule)
+	Forget(handle string)
+}
+
+type hostnameEntry struct {
+	cfg      NetworkConfig
+	rules    []HostnameRule
+	resolved map[string][]string
+	expires  time.Time
+}
+
+// HostnameRefresher installs the rules resolved from containers' hostname
+// rules, and replaces them when the hostnames' records expire and change
+type HostnameRefresher struct {
+	logger         lager.Logger
+	resolver       HostnameResolver
+	firewallOpener FirewallOpener
+	clock          clock.Clock
+
+	// held while installing rules, so that refreshes never race with Apply
+	mu      sync.Mutex
+	entries map[string]*hostnameEntry
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewHostnameRefresher(logger lager.Logger, resolver HostnameResolver, firewallOpener FirewallOpener, clock clock.Clock) *HostnameRefresher {
+	return &HostnameRefresher{
+		logger:         logger.Session("hostname-refresher"),
+		resolver:       resolver,
+		firewallOpener: firewallOpener,
+		clock:          clock,
+		entries:        make(map[string]*hostnameEntry),
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+}
+
+// Apply resolves a container's hostname rules and installs the resulting
+// rules in place of any installed previously
+func (r *HostnameRefresher) Apply(log lager.Logger, cfg NetworkConfig, rules []HostnameRule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry := &hostnameEntry{cfg: cfg, rules: rules}
+	resolved, ttl, err := r.resolve(log, entry)
+	if err != nil {
+		return err
+	}
+
+	if err := r.install(log, entry, resolved, ttl); err != nil {
+		return err
+	}
+
+	if len(rules) == 0 {
+		delete(r.entries, cfg.ContainerHandle)
+		return nil
+	}
+
+	r.entries[cfg.ContainerHandle] = entry
+	return nil
+}
+
+// Restore tracks the hostname rules of a container created by a previous
+// server, whose rules are refreshed at the next opportunity
+func (r *HostnameRefresher) Restore(log lager.Logger, cfg NetworkConfig, rules []HostnameRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries[cfg.ContainerHandle] = &hostnameEntry{cfg: cfg, rules: rules}
+}
+
+// Forget stops refreshing a container's hostname rules
+func (r *HostnameRefresher) Forget(handle string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.entries, handle)
+}
+
+func (r *HostnameRefresher) Start() {
+	r.logger.Info("starting")
+	go r.run()
+}
+
+func (r *HostnameRefresher) Stop() {
+	close(r.stop)
+	<-r.done
+}
+
+func (r *HostnameRefresher) run() {
+	defer close(r.done)
+	defer r.logger.Info("finished")
+
+	ticker := r.clock.NewTicker(hostnameRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C():
+			r.refreshExpired()
+		}
+	}
+}
+
+// refreshExpired resolves the hostnames of expired entries without holding the
+// lock, so that slow nameservers do not hold up Apply, Restore and Forget
+func (r *HostnameRefresher) refreshExpired() {
+	now := r.clock.Now()
+
+	for handle, entry := range r.expiredEntries(now) {
+		log := r.logger.Session("refresh", lager.Data{"handle": handle})
+		resolved, ttl, err := r.resolve(log, entry)
+
+		r.mu.Lock()
+		// the container may have been destroyed, or its rules replaced, meanwhile
+		if r.entries[handle] == entry {
+			if err == nil {
+				err = r.install(log, entry, resolved, ttl)
+			}
+
+			if err != nil {
+				log.Error("failed", err)
+				entry.expires = now.Add(hostnameRetryInterval)
+			}
+		}
+		r.mu.Unlock()
+	}
+}
+
+func (r *HostnameRefresher) expiredEntries(now time.Time) map[string]*hostnameEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	expired := make(map[string]*hostnameEntry)
+	for handle, entry := range r.entries {
+		if !now.Before(entry.expires) {
+			expired[handle] = entry
+		}
+	}
+
+	return expired
+}
+
+// resolve returns the addresses of the entry's hostnames, and the time for
+// which they may be cached
+func (r *HostnameRefresher) resolve(log lager.Logger, entry *hostnameEntry) (map[string][]string, time.Duration, error) {
+	servers := append(append([]net.IP{}, entry.cfg.DNSServers...), entry.cfg.AdditionalDNSServers...)
+
+	resolved := make(map[string][]string)
+	ttl := time.Duration(0)
+	for _, rule := range entry.rules {
+		for _, hostname := range rule.Hostnames {
+			if _, ok := resolved[hostname]; ok {
+				continue
+			}
+
+			ips, hostnameTTL, err := r.resolver.Resolve(log, servers, hostname)
+			if err != nil {
+				return nil, 0, fmt.Errorf("resolving %s: %s", hostname, err)
+			}
+
+			var addresses []string
+			for _, ip := range ips {
+				addresses = append(addresses, ip.String())
+			}
+			sort.Strings(addresses)
+			resolved[hostname] = addresses
+
+			if ttl == 0 || hostnameTTL < ttl {
+				ttl = hostnameTTL
+			}
+		}
+	}
+
+	if ttl < minHostnameTTL {
+		ttl = minHostnameTTL
+	}
+
+	return resolved, ttl, nil
+}
+
+// install replaces the entry's installed rules if the addresses have changed.
+// It must be called with the lock held.
+func (r *HostnameRefresher) install(log lager.Logger, entry *hostnameEntry, resolved map[string][]string, ttl time.Duration) error {
+	if entry.resolved == nil || !reflect.DeepEqual(resolved, entry.resolved) {
+		log.Info("replacing-rules", lager.Data{"resolved": resolved})
+		if err := r.firewallOpener.ReplaceDNSRules(log, entry.cfg.IPTableInstance, entry.cfg.ContainerHandle, netOutRules(entry.rules, resolved)); err != nil {
+			return err
+		}
+
+		entry.resolved = resolved
+	}
+
+	entry.expires = r.clock.Now().Add(ttl)
+	return nil
+}
+
+func netOutRules(rules []HostnameRule, resolved map[string][]string) []garden.NetOutRule {
+	var netOutRules []garden.NetOutRule
+	for _, rule := range rules {
+		var networks []garden.IPRange
+		for _, hostname := range rule.Hostnames {
+			for _, address := range resolved[hostname] {
+				networks = append(networks, garden.IPRangeFromIP(net.ParseIP(address)))
+			}
+		}
+
+		if len(networks) == 0 {
+			continue
+		}
+
+		netOutRules = append(netOutRules, garden.NetOutRule{
+			Protocol: rule.Protocol,
+			Networks: networks,
+			Ports:    rule.Ports,
+			Log:      rule.Log,
+		})
+	}
+
+	return netOutRules
+}
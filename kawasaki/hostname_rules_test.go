@@ -0,0 +1,324 @@
+package kawasaki_test
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/kawasaki"
+	fakes "code.cloudfoundry.org/guardian/kawasaki/kawasakifakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/pivotal-golang/clock/fakeclock"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseHostnameRules", func() {
+	It("returns no rules for an empty value", func() {
+		rules, err := kawasaki.ParseHostnameRules("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(BeEmpty())
+	})
+
+	It("parses a JSON list of rules", func() {
+		rules, err := kawasaki.ParseHostnameRules(`[
+			{"protocol": 1, "hostnames": ["example.com"], "ports": [{"start": 80, "end": 443}], "log": true},
+			{"hostnames": ["example.org", "example.net"]}
+		]`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(Equal([]kawasaki.HostnameRule{
+			{
+				Protocol:  garden.ProtocolTCP,
+				Hostnames: []string{"example.com"},
+				Ports:     []garden.PortRange{{Start: 80, End: 443}},
+				Log:       true,
+			},
+			{
+				Hostnames: []string{"example.org", "example.net"},
+			},
+		}))
+	})
+
+	It("returns an error when the value is not JSON", func() {
+		_, err := kawasaki.ParseHostnameRules("example.com")
+		Expect(err).To(MatchError(ContainSubstring("parsing hostname rules")))
+	})
+
+	It("returns an error when a rule has no hostnames", func() {
+		_, err := kawasaki.ParseHostnameRules(`[{"protocol": 1}]`)
+		Expect(err).To(MatchError("parsing hostname rules: a rule has no hostnames"))
+	})
+
+	It("returns an error when a hostname is empty", func() {
+		_, err := kawasaki.ParseHostnameRules(`[{"hostnames": [""]}]`)
+		Expect(err).To(MatchError("parsing hostname rules: empty hostname"))
+	})
+})
+
+var _ = Describe("HostnameRefresher", func() {
+	var (
+		fakeResolver       *fakes.FakeHostnameResolver
+		fakeFirewallOpener *fakes.FakeFirewallOpener
+		fakeClock          *fakeclock.FakeClock
+		logger             lager.Logger
+		refresher          *kawasaki.HostnameRefresher
+
+		cfg   kawasaki.NetworkConfig
+		rules []kawasaki.HostnameRule
+
+		addresses map[string][]net.IP
+		ttl       time.Duration
+	)
+
+	BeforeEach(func() {
+		fakeResolver = new(fakes.FakeHostnameResolver)
+		fakeFirewallOpener = new(fakes.FakeFirewallOpener)
+		fakeClock = fakeclock.NewFakeClock(time.Unix(123, 456))
+		logger = lagertest.NewTestLogger("test")
+
+		refresher = kawasaki.NewHostnameRefresher(logger, fakeResolver, fakeFirewallOpener, fakeClock)
+
+		cfg = kawasaki.NetworkConfig{
+			ContainerHandle:      "some-handle",
+			IPTableInstance:      "some-instance",
+			DNSServers:           []net.IP{net.ParseIP("8.8.8.8")},
+			AdditionalDNSServers: []net.IP{net.ParseIP("8.8.4.4")},
+		}
+
+		rules = []kawasaki.HostnameRule{
+			{
+				Protocol:  garden.ProtocolTCP,
+				Hostnames: []string{"example.com", "example.org"},
+				Ports:     []garden.PortRange{garden.PortRangeFromPort(443)},
+			},
+		}
+
+		addresses = map[string][]net.IP{
+			"example.com": {net.ParseIP("1.2.3.4")},
+			"example.org": {net.ParseIP("5.6.7.8"), net.ParseIP("5.6.7.9")},
+		}
+		ttl = time.Minute
+
+		fakeResolver.ResolveStub = func(_ lager.Logger, _ []net.IP, hostname string) ([]net.IP, time.Duration, error) {
+			ips, ok := addresses[hostname]
+			if !ok {
+				return nil, 0, errors.New("no such host")
+			}
+
+			return ips, ttl, nil
+		}
+	})
+
+	Describe("Apply", func() {
+		It("resolves the hostnames with the container's DNS servers", func() {
+			Expect(refresher.Apply(logger, cfg, rules)).To(Succeed())
+
+			Expect(fakeResolver.ResolveCallCount()).To(Equal(2))
+			_, servers, hostname := fakeResolver.ResolveArgsForCall(0)
+			Expect(servers).To(Equal([]net.IP{net.ParseIP("8.8.8.8"), net.ParseIP("8.8.4.4")}))
+			Expect(hostname).To(Equal("example.com"))
+		})
+
+		It("replaces the DNS rules with rules for the resolved addresses", func() {
+			Expect(refresher.Apply(logger, cfg, rules)).To(Succeed())
+
+			Expect(fakeFirewallOpener.ReplaceDNSRulesCallCount()).To(Equal(1))
+			_, instance, handle, netOutRules := fakeFirewallOpener.ReplaceDNSRulesArgsForCall(0)
+			Expect(instance).To(Equal("some-instance"))
+			Expect(handle).To(Equal("some-handle"))
+			Expect(netOutRules).To(Equal([]garden.NetOutRule{{
+				Protocol: garden.ProtocolTCP,
+				Networks: []garden.IPRange{
+					garden.IPRangeFromIP(net.ParseIP("1.2.3.4")),
+					garden.IPRangeFromIP(net.ParseIP("5.6.7.8")),
+					garden.IPRangeFromIP(net.ParseIP("5.6.7.9")),
+				},
+				Ports: []garden.PortRange{garden.PortRangeFromPort(443)},
+			}}))
+		})
+
+		Context("when a hostname cannot be resolved", func() {
+			BeforeEach(func() {
+				delete(addresses, "example.org")
+			})
+
+			It("returns an error", func() {
+				Expect(refresher.Apply(logger, cfg, rules)).To(MatchError("resolving example.org: no such host"))
+			})
+
+			It("does not replace the DNS rules", func() {
+				refresher.Apply(logger, cfg, rules)
+				Expect(fakeFirewallOpener.ReplaceDNSRulesCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when replacing the DNS rules fails", func() {
+			BeforeEach(func() {
+				fakeFirewallOpener.ReplaceDNSRulesReturns(errors.New("iptables-failed"))
+			})
+
+			It("returns the error", func() {
+				Expect(refresher.Apply(logger, cfg, rules)).To(MatchError("iptables-failed"))
+			})
+		})
+
+		Context("when no rules are given", func() {
+			It("removes any DNS rules", func() {
+				Expect(refresher.Apply(logger, cfg, nil)).To(Succeed())
+
+				Expect(fakeFirewallOpener.ReplaceDNSRulesCallCount()).To(Equal(1))
+				_, _, _, netOutRules := fakeFirewallOpener.ReplaceDNSRulesArgsForCall(0)
+				Expect(netOutRules).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("refreshing", func() {
+		BeforeEach(func() {
+			refresher.Start()
+			Eventually(fakeClock.WatcherCount).Should(Equal(1))
+		})
+
+		AfterEach(func() {
+			refresher.Stop()
+		})
+
+		Context("when the container's rules have been applied", func() {
+			BeforeEach(func() {
+				Expect(refresher.Apply(logger, cfg, rules)).To(Succeed())
+			})
+
+			It("does not resolve the hostnames again before their TTL expires", func() {
+				fakeClock.Increment(59 * time.Second)
+				Consistently(fakeResolver.ResolveCallCount).Should(Equal(2))
+			})
+
+			It("resolves the hostnames again once their TTL expires", func() {
+				fakeClock.Increment(time.Minute)
+				Eventually(fakeResolver.ResolveCallCount).Should(Equal(4))
+			})
+
+			It("does not replace the DNS rules if the addresses are unchanged", func() {
+				fakeClock.Increment(time.Minute)
+				Eventually(fakeResolver.ResolveCallCount).Should(Equal(4))
+				Consistently(fakeFirewallOpener.ReplaceDNSRulesCallCount).Should(Equal(1))
+			})
+
+			Context("when the addresses change", func() {
+				BeforeEach(func() {
+					addresses["example.com"] = []net.IP{net.ParseIP("1.2.3.5")}
+				})
+
+				It("replaces the DNS rules", func() {
+					fakeClock.Increment(time.Minute)
+					Eventually(fakeFirewallOpener.ReplaceDNSRulesCallCount).Should(Equal(2))
+
+					_, _, _, netOutRules := fakeFirewallOpener.ReplaceDNSRulesArgsForCall(1)
+					Expect(netOutRules[0].Networks[0]).To(Equal(garden.IPRangeFromIP(net.ParseIP("1.2.3.5"))))
+				})
+			})
+
+			Context("when resolving fails", func() {
+				BeforeEach(func() {
+					delete(addresses, "example.com")
+				})
+
+				It("keeps the DNS rules and retries later", func() {
+					fakeClock.Increment(time.Minute)
+					Eventually(fakeResolver.ResolveCallCount).Should(Equal(3))
+					Consistently(fakeFirewallOpener.ReplaceDNSRulesCallCount).Should(Equal(1))
+
+					fakeClock.Increment(29 * time.Second)
+					Consistently(fakeResolver.ResolveCallCount).Should(Equal(3))
+
+					fakeClock.Increment(time.Second)
+					Eventually(fakeResolver.ResolveCallCount).Should(Equal(4))
+				})
+			})
+
+			Context("when the TTL is very short", func() {
+				BeforeEach(func() {
+					ttl = 0
+					Expect(refresher.Apply(logger, cfg, rules)).To(Succeed())
+				})
+
+				It("does not resolve the hostnames more often than every 5 seconds", func() {
+					fakeClock.Increment(4 * time.Second)
+					Consistently(fakeResolver.ResolveCallCount).Should(Equal(4))
+
+					fakeClock.Increment(time.Second)
+					Eventually(fakeResolver.ResolveCallCount).Should(Equal(6))
+				})
+			})
+
+			Context("when the container is forgotten", func() {
+				BeforeEach(func() {
+					refresher.Forget("some-handle")
+				})
+
+				It("no longer resolves its hostnames", func() {
+					fakeClock.Increment(time.Minute)
+					Consistently(fakeResolver.ResolveCallCount).Should(Equal(2))
+				})
+			})
+
+			Context("when a nameserver is slow to respond", func() {
+				var release chan struct{}
+
+				BeforeEach(func() {
+					release = make(chan struct{})
+					resolve := fakeResolver.ResolveStub
+					fakeResolver.ResolveStub = func(log lager.Logger, servers []net.IP, hostname string) ([]net.IP, time.Duration, error) {
+						<-release
+						return resolve(log, servers, hostname)
+					}
+
+					addresses["example.com"] = []net.IP{net.ParseIP("1.2.3.5")}
+				})
+
+				AfterEach(func() {
+					close(release)
+				})
+
+				It("does not hold up other operations", func() {
+					fakeClock.Increment(time.Minute)
+					Eventually(fakeResolver.ResolveCallCount).Should(Equal(3))
+
+					forgotten := make(chan struct{})
+					go func() {
+						refresher.Forget("some-handle")
+						close(forgotten)
+					}()
+					Eventually(forgotten).Should(BeClosed())
+				})
+
+				Context("and the container is forgotten meanwhile", func() {
+					It("does not install the resolved rules", func() {
+						fakeClock.Increment(time.Minute)
+						Eventually(fakeResolver.ResolveCallCount).Should(Equal(3))
+
+						refresher.Forget("some-handle")
+						release <- struct{}{}
+						release <- struct{}{}
+
+						Consistently(fakeFirewallOpener.ReplaceDNSRulesCallCount).Should(Equal(1))
+					})
+				})
+			})
+		})
+
+		Context("when the container's rules are restored", func() {
+			BeforeEach(func() {
+				refresher.Restore(logger, cfg, rules)
+			})
+
+			It("resolves the hostnames and installs the rules at the next refresh", func() {
+				fakeClock.Increment(time.Second)
+				Eventually(fakeFirewallOpener.ReplaceDNSRulesCallCount).Should(Equal(1))
+			})
+		})
+	})
+})
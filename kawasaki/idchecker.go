@@ -0,0 +1,53 @@
+package kawasaki
+
+import (
+	"fmt"
+	"net"
+)
+
+//go:generate counterfeiter . IDChecker
+
+// IDChecker reports whether an ID is already used by host state named after
+// it, e.g. left over from an earlier run of the server
+type IDChecker interface {
+	InUse(id string) (bool, error)
+}
+
+// IDCheckers reports an ID as in use when any of its checkers does
+type IDCheckers []IDChecker
+
+func (c IDCheckers) InUse(id string) (bool, error) {
+	for _, checker := range c {
+		inUse, err := checker.InUse(id)
+		if err != nil || inUse {
+			return inUse, err
+		}
+	}
+
+	return false, nil
+}
+
+// InterfaceIDChecker reports an ID as in use when either end of the veth
+// pair named after it exists on the host
+type InterfaceIDChecker struct {
+	InterfacePrefix string
+
+	Link interface {
+		InterfaceByName(name string) (*net.Interface, bool, error)
+	}
+}
+
+func (c *InterfaceIDChecker) InUse(id string) (bool, error) {
+	for _, name := range []string{hostIntfName(c.InterfacePrefix, id), containerIntfName(c.InterfacePrefix, id)} {
+		_, exists, err := c.Link.InterfaceByName(name)
+		if err != nil {
+			return false, fmt.Errorf("checking interface %s: %s", name, err)
+		}
+
+		if exists {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
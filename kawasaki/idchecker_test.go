@@ -0,0 +1,94 @@
+package kawasaki_test
+
+import (
+	"errors"
+	"net"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/guardian/kawasaki/devices/fakedevices"
+	fakes "code.cloudfoundry.org/guardian/kawasaki/kawasakifakes"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IDCheckers", func() {
+	var (
+		checker1, checker2 *fakes.FakeIDChecker
+		checkers           kawasaki.IDCheckers
+	)
+
+	BeforeEach(func() {
+		checker1 = new(fakes.FakeIDChecker)
+		checker2 = new(fakes.FakeIDChecker)
+		checkers = kawasaki.IDCheckers{checker1, checker2}
+	})
+
+	It("reports the ID as unused when no checker reports it as used", func() {
+		Expect(checkers.InUse("some-id")).To(BeFalse())
+		Expect(checker1.InUseArgsForCall(0)).To(Equal("some-id"))
+		Expect(checker2.InUseArgsForCall(0)).To(Equal("some-id"))
+	})
+
+	It("reports the ID as used when any checker does", func() {
+		checker2.InUseReturns(true, nil)
+		Expect(checkers.InUse("some-id")).To(BeTrue())
+	})
+
+	It("returns the first error without running later checkers", func() {
+		checker1.InUseReturns(false, errors.New("failed"))
+
+		_, err := checkers.InUse("some-id")
+		Expect(err).To(MatchError("failed"))
+		Expect(checker2.InUseCallCount()).To(Equal(0))
+	})
+})
+
+var _ = Describe("InterfaceIDChecker", func() {
+	var (
+		link     *fakedevices.FakeLink
+		existing map[string]bool
+		checker  *kawasaki.InterfaceIDChecker
+	)
+
+	BeforeEach(func() {
+		existing = map[string]bool{}
+		link = &fakedevices.FakeLink{
+			InterfaceByNameFunc: func(name string) (*net.Interface, bool, error) {
+				if existing[name] {
+					return &net.Interface{Name: name}, true, nil
+				}
+
+				return nil, false, nil
+			},
+		}
+
+		checker = &kawasaki.InterfaceIDChecker{InterfacePrefix: "w1", Link: link}
+	})
+
+	It("reports the ID as unused when neither end of its veth pair exists", func() {
+		Expect(checker.InUse("some-id")).To(BeFalse())
+	})
+
+	It("reports the ID as used when the host's end exists", func() {
+		existing["w1some-id-0"] = true
+		Expect(checker.InUse("some-id")).To(BeTrue())
+	})
+
+	It("reports the ID as used when the container's end exists", func() {
+		existing["w1some-id-1"] = true
+		Expect(checker.InUse("some-id")).To(BeTrue())
+	})
+
+	Context("when looking up an interface fails", func() {
+		BeforeEach(func() {
+			link.InterfaceByNameFunc = func(name string) (*net.Interface, bool, error) {
+				return nil, false, errors.New("netlink-failed")
+			}
+		})
+
+		It("returns the error", func() {
+			_, err := checker.InUse("some-id")
+			Expect(err).To(MatchError("checking interface w1some-id-0: netlink-failed"))
+		})
+	})
+})
@@ -0,0 +1,26 @@
+package kawasaki
+
+import "fmt"
+
+// InstanceHandleResolver finds the container whose iptables chains are named
+// after an instance ID. The NFLOG rules of a container's chains use the
+// instance ID as their prefix, as handles may be too long for one.
+type InstanceHandleResolver struct {
+	ConfigStore ConfigStore
+	Handles     func() ([]string, error)
+}
+
+func (r InstanceHandleResolver) Handle(instance string) (string, error) {
+	handles, err := r.Handles()
+	if err != nil {
+		return "", err
+	}
+
+	for _, handle := range handles {
+		if value, ok := r.ConfigStore.Get(handle, iptableInstanceKey); ok && value == instance {
+			return handle, nil
+		}
+	}
+
+	return "", fmt.Errorf("no container has iptables instance %s", instance)
+}
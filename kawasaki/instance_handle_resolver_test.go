@@ -0,0 +1,59 @@
+package kawasaki_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	fakes "code.cloudfoundry.org/guardian/kawasaki/kawasakifakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InstanceHandleResolver", func() {
+	var (
+		fakeConfigStore *fakes.FakeConfigStore
+		resolver        kawasaki.InstanceHandleResolver
+	)
+
+	BeforeEach(func() {
+		fakeConfigStore = new(fakes.FakeConfigStore)
+		fakeConfigStore.GetStub = func(handle, name string) (string, bool) {
+			Expect(name).To(Equal("kawasaki.iptable-inst"))
+			instance, ok := map[string]string{
+				"some-handle":    "some-instance",
+				"another-handle": "another-instance",
+			}[handle]
+			return instance, ok
+		}
+
+		resolver = kawasaki.InstanceHandleResolver{
+			ConfigStore: fakeConfigStore,
+			Handles: func() ([]string, error) {
+				return []string{"unnetworked-handle", "some-handle", "another-handle"}, nil
+			},
+		}
+	})
+
+	It("returns the handle of the container with the instance ID", func() {
+		handle, err := resolver.Handle("another-instance")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(handle).To(Equal("another-handle"))
+	})
+
+	Context("when no container has the instance ID", func() {
+		It("returns an error", func() {
+			_, err := resolver.Handle("missing-instance")
+			Expect(err).To(MatchError("no container has iptables instance missing-instance"))
+		})
+	})
+
+	Context("when listing the containers fails", func() {
+		It("returns the error", func() {
+			resolver.Handles = func() ([]string, error) { return nil, errors.New("banana") }
+
+			_, err := resolver.Handle("some-instance")
+			Expect(err).To(MatchError("banana"))
+		})
+	})
+})
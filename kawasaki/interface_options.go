@@ -0,0 +1,73 @@
+package kawasaki
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+)
+
+const (
+	// minMtu is the smallest MTU an IPv4 link may have
+	minMtu = 68
+
+	// maxInterfaceNameLen is the longest interface name Linux allows
+	maxInterfaceNameLen = 15
+)
+
+// InterfaceOptions customise a container's network interface. A zero value
+// leaves the corresponding default in place.
+type InterfaceOptions struct {
+	Mtu  int
+	Name string
+}
+
+// ParseInterfaceOptions reads the interface options requested by a
+// container's properties
+func ParseInterfaceOptions(properties garden.Properties) (InterfaceOptions, error) {
+	var options InterfaceOptions
+
+	if value := properties[gardener.NetworkMtuKey]; value != "" {
+		mtu, err := strconv.Atoi(value)
+		if err != nil || mtu < minMtu {
+			return InterfaceOptions{}, fmt.Errorf("invalid value for %s: %s", gardener.NetworkMtuKey, value)
+		}
+
+		options.Mtu = mtu
+	}
+
+	if value := properties[gardener.NetworkInterfaceNameKey]; value != "" {
+		if !validInterfaceName(value) {
+			return InterfaceOptions{}, fmt.Errorf("invalid value for %s: %s", gardener.NetworkInterfaceNameKey, value)
+		}
+
+		options.Name = value
+	}
+
+	return options, nil
+}
+
+// Apply sets the options on a network config, clamping the MTU to maxMtu
+// unless maxMtu is zero
+func (o InterfaceOptions) Apply(cfg *NetworkConfig, maxMtu int) {
+	if o.Mtu != 0 {
+		cfg.Mtu = o.Mtu
+		if maxMtu != 0 && cfg.Mtu > maxMtu {
+			cfg.Mtu = maxMtu
+		}
+	}
+
+	if o.Name != "" {
+		cfg.ContainerIntfName = o.Name
+	}
+}
+
+func validInterfaceName(name string) bool {
+	if len(name) > maxInterfaceNameLen || name == "." || name == ".." || name == "lo" {
+		return false
+	}
+
+	return !strings.ContainsAny(name, "/: \t\n")
+}
@@ -0,0 +1,87 @@
+package kawasaki_test
+
+import (
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/kawasaki"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InterfaceOptions", func() {
+	Describe("ParseInterfaceOptions", func() {
+		It("returns empty options when no properties are given", func() {
+			options, err := kawasaki.ParseInterfaceOptions(garden.Properties{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(options).To(Equal(kawasaki.InterfaceOptions{}))
+		})
+
+		It("parses the options", func() {
+			options, err := kawasaki.ParseInterfaceOptions(garden.Properties{
+				gardener.NetworkMtuKey:           "1400",
+				gardener.NetworkInterfaceNameKey: "eth0",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(options).To(Equal(kawasaki.InterfaceOptions{Mtu: 1400, Name: "eth0"}))
+		})
+
+		DescribeTable("invalid MTUs",
+			func(mtu string) {
+				_, err := kawasaki.ParseInterfaceOptions(garden.Properties{gardener.NetworkMtuKey: mtu})
+				Expect(err).To(MatchError("invalid value for garden.network.mtu: " + mtu))
+			},
+			Entry("not a number", "big"),
+			Entry("negative", "-1"),
+			Entry("smaller than IPv4 allows", "67"),
+		)
+
+		DescribeTable("invalid interface names",
+			func(name string) {
+				_, err := kawasaki.ParseInterfaceOptions(garden.Properties{gardener.NetworkInterfaceNameKey: name})
+				Expect(err).To(MatchError("invalid value for garden.network.interface-name: " + name))
+			},
+			Entry("too long", "a-very-long-name"),
+			Entry("containing a slash", "eth/0"),
+			Entry("containing a colon", "eth0:1"),
+			Entry("containing whitespace", "eth 0"),
+			Entry("the loopback interface", "lo"),
+			Entry("a relative path", ".."),
+		)
+	})
+
+	Describe("Apply", func() {
+		var cfg kawasaki.NetworkConfig
+
+		BeforeEach(func() {
+			cfg = kawasaki.NetworkConfig{ContainerIntf: "w1abc-1", Mtu: 1500}
+		})
+
+		It("leaves the config alone when the options are empty", func() {
+			kawasaki.InterfaceOptions{}.Apply(&cfg, 9000)
+			Expect(cfg).To(Equal(kawasaki.NetworkConfig{ContainerIntf: "w1abc-1", Mtu: 1500}))
+		})
+
+		It("sets the MTU and interface name", func() {
+			kawasaki.InterfaceOptions{Mtu: 1400, Name: "eth0"}.Apply(&cfg, 9000)
+			Expect(cfg.Mtu).To(Equal(1400))
+			Expect(cfg.ContainerIntf).To(Equal("w1abc-1"))
+			Expect(cfg.ContainerIntfName).To(Equal("eth0"))
+		})
+
+		It("allows an MTU larger than the default up to the maximum", func() {
+			kawasaki.InterfaceOptions{Mtu: 9000}.Apply(&cfg, 9000)
+			Expect(cfg.Mtu).To(Equal(9000))
+		})
+
+		It("clamps the MTU to the maximum", func() {
+			kawasaki.InterfaceOptions{Mtu: 9001}.Apply(&cfg, 9000)
+			Expect(cfg.Mtu).To(Equal(9000))
+		})
+
+		It("does not clamp the MTU when there is no maximum", func() {
+			kawasaki.InterfaceOptions{Mtu: 9001}.Apply(&cfg, 0)
+			Expect(cfg.Mtu).To(Equal(9001))
+		})
+	})
+})
@@ -0,0 +1,61 @@
+package iptables
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ConnectionLimitCounter reads how many packets containers' connection limits
+// have dropped
+type ConnectionLimitCounter struct {
+	iptables *IPTablesController
+}
+
+func NewConnectionLimitCounter(iptables *IPTablesController) *ConnectionLimitCounter {
+	return &ConnectionLimitCounter{
+		iptables: iptables,
+	}
+}
+
+// Drops returns the number of packets dropped by each container's connection
+// limits, by handle. Containers without limits are not included.
+func (c *ConnectionLimitCounter) Drops() (map[string]uint64, error) {
+	out, err := c.iptables.output("count-connection-limit-drops", exec.Command(c.iptables.iptablesBinPath, "--wait", "--table", "filter", "-S", "-v"))
+	if err != nil {
+		return nil, err
+	}
+
+	drops := make(map[string]uint64)
+	for _, line := range strings.Split(string(out), "\n") {
+		fields, err := splitRule(line)
+		if err != nil || len(fields) < 2 || fields[0] != "-A" {
+			continue
+		}
+
+		chain := fields[1]
+		if !strings.HasPrefix(chain, c.iptables.instanceChainPrefix) || !strings.HasSuffix(chain, connectionLimitsChainSuffix) {
+			continue
+		}
+
+		var handle, target string
+		var packets uint64
+		for i := 2; i+1 < len(fields); i++ {
+			switch fields[i] {
+			case "--comment":
+				handle = fields[i+1]
+			case "-j":
+				target = fields[i+1]
+			case "-c":
+				// the counters are printed as -c PACKETS BYTES
+				packets, _ = strconv.ParseUint(fields[i+1], 10, 64)
+			}
+		}
+
+		if target == "DROP" && handle != "" {
+			drops[handle] += packets
+		}
+	}
+
+	return drops, nil
+}
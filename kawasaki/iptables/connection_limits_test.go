@@ -0,0 +1,73 @@
+package iptables_test
+
+import (
+	"errors"
+	"os/exec"
+
+	"code.cloudfoundry.org/guardian/kawasaki/iptables"
+	"github.com/cloudfoundry/gunk/command_runner/fake_command_runner"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConnectionLimitCounter", func() {
+	var (
+		fakeRunner *fake_command_runner.FakeCommandRunner
+		counter    *iptables.ConnectionLimitCounter
+		rules      string
+		listErr    error
+	)
+
+	BeforeEach(func() {
+		fakeRunner = fake_command_runner.New()
+		counter = iptables.NewConnectionLimitCounter(
+			iptables.New("/sbin/iptables", "/sbin/iptables-restore", fakeRunner, NewFakeLocksmith(), "prefix-"),
+		)
+
+		rules = `-P INPUT ACCEPT -c 0 0
+-N prefix-instance-some-id-lim
+-N prefix-instance-other-id-lim
+-A prefix-forward -i some-bridge -s 10.0.0.2/32 -c 1200 80000 -j prefix-instance-some-id-lim
+-A prefix-instance-some-id-lim -m conntrack --ctstate RELATED,ESTABLISHED -c 1000 70000 -m comment --comment some-handle -j RETURN
+-A prefix-instance-some-id-lim -m conntrack --ctstate NEW -m hashlimit --hashlimit-above 50/sec --hashlimit-burst 50 --hashlimit-mode srcip --hashlimit-name some-id -c 12 720 -m comment --comment some-handle -j DROP
+-A prefix-instance-some-id-lim -m conntrack --ctstate NEW -m connlimit --connlimit-above 200 --connlimit-mask 32 --connlimit-saddr -c 3 180 -m comment --comment some-handle -j DROP
+-A prefix-instance-other-id-lim -m conntrack --ctstate NEW -m connlimit --connlimit-above 10 --connlimit-mask 32 --connlimit-saddr -c 0 0 -m comment --comment "other handle" -j DROP
+-A prefix-instance-some-id -s 10.0.0.0/30 -d 10.0.0.0/30 -c 40 2000 -m comment --comment some-handle -j DROP
+`
+		listErr = nil
+
+		fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+			Path: "/sbin/iptables",
+			Args: []string{"--wait", "--table", "filter", "-S", "-v"},
+		}, func(cmd *exec.Cmd) error {
+			if listErr != nil {
+				cmd.Stderr.Write([]byte("iptables failed"))
+				return listErr
+			}
+
+			cmd.Stdout.Write([]byte(rules))
+			return nil
+		})
+	})
+
+	It("sums the packets dropped by each container's connection limits", func() {
+		drops, err := counter.Drops()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(drops).To(Equal(map[string]uint64{
+			"some-handle":  15,
+			"other handle": 0,
+		}))
+	})
+
+	Context("when listing the rules fails", func() {
+		BeforeEach(func() {
+			listErr = errors.New("exit status 1")
+		})
+
+		It("returns an error", func() {
+			_, err := counter.Drops()
+			Expect(err).To(MatchError("iptables: count-connection-limit-drops: iptables failed"))
+		})
+	})
+})
@@ -0,0 +1,627 @@
+package iptables
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/lager"
+)
+
+// Chains jumping to each other more deeply than this are assumed to loop
+const maxExplainDepth = 16
+
+// options which garden's chains do not use are not evaluated, so a rule with
+// one may match: the traffic is assumed to match it, and the explanation says
+// so
+var errUnsupportedOption = errors.New("unsupported option")
+
+// Explainer explains the fate of traffic from a container by reading back the
+// rules of the chains it passes through and evaluating them against the
+// traffic. Traffic to a mapped port is followed through its DNAT to the
+// container it is mapped to; no other NAT is taken into account.
+type Explainer struct {
+	iptables       *IPTablesController
+	interfaceAddrs func() ([]net.Addr, error)
+}
+
+func NewExplainer(iptables *IPTablesController, interfaceAddrs func() ([]net.Addr, error)) *Explainer {
+	return &Explainer{
+		iptables:       iptables,
+		interfaceAddrs: interfaceAddrs,
+	}
+}
+
+func (e *Explainer) Explain(log lager.Logger, cfg kawasaki.NetworkConfig, traffic kawasaki.Traffic) (kawasaki.Explanation, error) {
+	log = log.Session("explain-firewall", lager.Data{"instance": cfg.IPTableInstance})
+
+	w := &walk{
+		iptables:            e.iptables,
+		instanceId:          cfg.IPTableInstance,
+		inInterface:         cfg.BridgeName,
+		source:              cfg.ContainerIP,
+		traffic:             traffic,
+		originalDestination: traffic.Destination,
+		rules:               make(map[string][]explainedRule),
+	}
+
+	// traffic to a mapped port, e.g. through an external IP, is filtered as
+	// traffic to the container it is mapped to
+	nat, err := w.chain("nat", e.iptables.preroutingChain, 0)
+	if err != nil {
+		log.Error("walking-nat-rules-failed", err)
+		return kawasaki.Explanation{}, err
+	}
+	if nat.translated {
+		w.translated = true
+		w.traffic.Destination = nat.rule.toDestination
+		if nat.rule.toPort != 0 {
+			w.traffic.Port = nat.rule.toPort
+		}
+	}
+
+	w.local, err = e.isLocal(w.traffic.Destination)
+	if err != nil {
+		log.Error("listing-local-addresses-failed", err)
+		return kawasaki.Explanation{}, err
+	}
+
+	// traffic to the host is filtered on input, all other traffic on forward
+	start := e.iptables.forwardChain
+	if w.local {
+		start = e.iptables.inputChain
+	}
+
+	v, err := w.chain("filter", start, 0)
+	if err != nil {
+		log.Error("walking-rules-failed", err)
+		return kawasaki.Explanation{}, err
+	}
+
+	explanation := kawasaki.Explanation{
+		// returning from garden's chains leaves the traffic to the built-in
+		// chain's policy, which garden expects to accept it
+		Allowed: v.allowed || v.returned,
+		Chain:   v.chain,
+		Reason:  w.reason(v),
+		Logged:  w.logged,
+		Assumed: w.assumed,
+	}
+	if v.rule != nil {
+		explanation.Rule = v.rule.spec
+	}
+	if len(w.assumed) > 0 {
+		explanation.Reason += ", assuming the traffic matches rules with unsupported options"
+	}
+
+	return explanation, nil
+}
+
+func (e *Explainer) isLocal(ip net.IP) (bool, error) {
+	if ip.IsLoopback() {
+		return true, nil
+	}
+
+	addrs, err := e.interfaceAddrs()
+	if err != nil {
+		return false, err
+	}
+
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// explainedRule is a rule read back from iptables: its SingleFilterRule along
+// with the other matches garden uses to route traffic between chains
+type explainedRule struct {
+	spec   string
+	filter SingleFilterRule
+
+	source              *net.IPNet
+	originalDestination *net.IPNet
+	inInterface         string
+	ctStates            []string
+	localOnly           bool
+	rateLimited         bool
+
+	// each negated option is parsed as a rule of its own, which the traffic
+	// must not match
+	negations []explainedRule
+
+	// the options, negated or not, which are not evaluated
+	unsupported []string
+
+	target     string
+	gotoTarget bool
+
+	toDestination net.IP
+	toPort        uint32
+}
+
+func (r explainedRule) matches(w *walk) bool {
+	if r.source != nil && !r.source.Contains(w.source) {
+		return false
+	}
+
+	if r.originalDestination != nil && !r.originalDestination.Contains(w.originalDestination) {
+		return false
+	}
+
+	if r.inInterface != "" {
+		if strings.HasSuffix(r.inInterface, "+") {
+			if !strings.HasPrefix(w.inInterface, strings.TrimSuffix(r.inInterface, "+")) {
+				return false
+			}
+		} else if r.inInterface != w.inInterface {
+			return false
+		}
+	}
+
+	// the traffic is taken to start a new connection
+	if len(r.ctStates) > 0 && !contains(r.ctStates, "NEW") && !(w.translated && contains(r.ctStates, "DNAT")) {
+		return false
+	}
+
+	if r.localOnly && !w.local {
+		return false
+	}
+
+	// the traffic is taken to be within the container's connection limits
+	if r.rateLimited {
+		return false
+	}
+
+	for _, negation := range r.negations {
+		if negation.matches(w) {
+			return false
+		}
+	}
+
+	return r.filter.Matches(w.traffic)
+}
+
+// parseRule parses a rule in the form printed by iptables -S, understanding
+// only the options used by garden's chains
+func parseRule(spec string) (explainedRule, error) {
+	fields, err := splitRule(spec)
+	if err != nil {
+		return explainedRule{}, err
+	}
+
+	if len(fields) < 2 || fields[0] != "-A" {
+		return explainedRule{}, fmt.Errorf("cannot explain rule '%s'", spec)
+	}
+
+	rule := explainedRule{spec: spec}
+	for i := 2; i < len(fields); i++ {
+		negated := fields[i] == "!"
+		if negated {
+			i++
+			if i >= len(fields) {
+				return explainedRule{}, fmt.Errorf("cannot explain rule '%s': ! has no option", spec)
+			}
+		}
+		option := fields[i]
+
+		value := ""
+		needsValue := option != "--connlimit-saddr" && option != "--connlimit-daddr"
+		hasValue := needsValue && i+1 < len(fields)
+		if hasValue {
+			i++
+			value = fields[i]
+		}
+
+		if !negated {
+			err = rule.parseOption(option, value)
+		} else if option == "-j" || option == "-g" || option == "-m" {
+			err = fmt.Errorf("%s cannot be negated", option)
+		} else {
+			var negation explainedRule
+			err = negation.parseOption(option, value)
+			if err == nil {
+				rule.negations = append(rule.negations, negation)
+			}
+		}
+
+		if err == errUnsupportedOption {
+			// the option may be a flag, followed by the next option
+			if value == "!" || strings.HasPrefix(value, "-") {
+				i--
+				value = ""
+			}
+
+			unsupported := strings.TrimSpace(option + " " + value)
+			if negated {
+				unsupported = "! " + unsupported
+			}
+			rule.unsupported = append(rule.unsupported, unsupported)
+			continue
+		}
+
+		if needsValue && !hasValue {
+			err = fmt.Errorf("%s has no value", option)
+		}
+
+		if err != nil {
+			return explainedRule{}, fmt.Errorf("cannot explain rule '%s': %s", spec, err)
+		}
+	}
+
+	return rule, nil
+}
+
+func (r *explainedRule) parseOption(option, value string) error {
+	var err error
+
+	switch option {
+	case "-m", "--comment", "--reject-with", "--nflog-group", "--nflog-prefix", "--nflog-range", "--nflog-threshold":
+		// match modules are implied by their options, and the others do
+		// not affect whether traffic is allowed
+	case "-p":
+		protocol, err := kawasaki.ParseProtocol(value)
+		if err != nil {
+			return err
+		}
+		r.filter.Protocol = protocol
+	case "-s":
+		r.source, err = parseNetwork(value)
+	case "-d":
+		var network *net.IPNet
+		network, err = parseNetwork(value)
+		if err == nil {
+			r.filter.Networks = &garden.IPRange{Start: network.IP, End: lastIP(network)}
+		}
+	case "--dst-range":
+		r.filter.Networks, err = parseIPRange(value)
+	case "--dport", "--destination-port":
+		r.filter.Ports, err = parsePortRange(value)
+	case "--icmp-type":
+		r.filter.ICMPs, err = parseICMPType(value)
+	case "-i":
+		r.inInterface = value
+	case "--ctstate":
+		r.ctStates = strings.Split(value, ",")
+	case "--ctorigdst":
+		r.originalDestination, err = parseNetwork(value)
+	case "--dst-type":
+		if value != "LOCAL" {
+			return errUnsupportedOption
+		}
+		r.localOnly = true
+	case "--hashlimit-above", "--hashlimit-burst", "--hashlimit-mode", "--hashlimit-name", "--connlimit-above", "--connlimit-mask", "--connlimit-saddr", "--connlimit-daddr":
+		r.rateLimited = true
+	case "--to-destination":
+		r.toDestination, r.toPort, err = parseNATDestination(value)
+	case "-j":
+		r.target = value
+	case "-g":
+		r.target = value
+		r.gotoTarget = true
+	default:
+		err = errUnsupportedOption
+	}
+
+	return err
+}
+
+// splitRule splits a rule into its fields, honouring the double quotes
+// iptables puts around values containing spaces
+func splitRule(spec string) ([]string, error) {
+	var fields []string
+	var field []rune
+	inField, quoted := false, false
+
+	for _, c := range spec {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			inField = true
+		case c == ' ' && !quoted:
+			if inField {
+				fields = append(fields, string(field))
+			}
+			field, inField = nil, false
+		default:
+			field = append(field, c)
+			inField = true
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("cannot explain rule '%s': unterminated quote", spec)
+	}
+
+	if inField {
+		fields = append(fields, string(field))
+	}
+
+	return fields, nil
+}
+
+func parseNetwork(value string) (*net.IPNet, error) {
+	if !strings.Contains(value, "/") {
+		value += "/32"
+	}
+
+	_, network, err := net.ParseCIDR(value)
+	return network, err
+}
+
+// parseNATDestination parses the address a DNAT rule translates to, taking
+// the first port of a range
+func parseNATDestination(value string) (net.IP, uint32, error) {
+	host, ports := value, ""
+	if i := strings.LastIndex(value, ":"); i >= 0 {
+		host, ports = value[:i], value[i+1:]
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, 0, fmt.Errorf("invalid NAT destination %s", value)
+	}
+
+	if ports == "" {
+		return ip, 0, nil
+	}
+
+	portRange, err := parsePortRange(strings.Replace(ports, "-", ":", 1))
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid NAT destination %s", value)
+	}
+
+	return ip, uint32(portRange.Start), nil
+}
+
+func lastIP(network *net.IPNet) net.IP {
+	last := make(net.IP, len(network.IP))
+	for i := range network.IP {
+		last[i] = network.IP[i] | ^network.Mask[i]
+	}
+
+	return last
+}
+
+func parseIPRange(value string) (*garden.IPRange, error) {
+	ends := strings.Split(value, "-")
+	if len(ends) != 2 {
+		return nil, fmt.Errorf("invalid IP range %s", value)
+	}
+
+	start, end := net.ParseIP(ends[0]), net.ParseIP(ends[1])
+	if start == nil || end == nil {
+		return nil, fmt.Errorf("invalid IP range %s", value)
+	}
+
+	return &garden.IPRange{Start: start, End: end}, nil
+}
+
+func parsePortRange(value string) (*garden.PortRange, error) {
+	ends := strings.Split(value, ":")
+	if len(ends) > 2 {
+		return nil, fmt.Errorf("invalid port range %s", value)
+	}
+
+	start, err := strconv.ParseUint(ends[0], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port range %s", value)
+	}
+
+	end := start
+	if len(ends) == 2 {
+		end, err = strconv.ParseUint(ends[1], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port range %s", value)
+		}
+	}
+
+	return &garden.PortRange{Start: uint16(start), End: uint16(end)}, nil
+}
+
+func parseICMPType(value string) (*garden.ICMPControl, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) > 2 {
+		return nil, fmt.Errorf("invalid ICMP type %s", value)
+	}
+
+	icmpType, err := strconv.ParseUint(parts[0], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ICMP type %s", value)
+	}
+
+	control := &garden.ICMPControl{Type: garden.ICMPType(icmpType)}
+	if len(parts) == 2 {
+		icmpCode, err := strconv.ParseUint(parts[1], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ICMP type %s", value)
+		}
+
+		code := garden.ICMPCode(icmpCode)
+		control.Code = &code
+	}
+
+	return control, nil
+}
+
+// verdict is the outcome of traversing a chain: either the traffic is
+// accepted or denied, or it returns to the calling chain. In the nat table
+// the traffic may instead be translated, by the verdict's rule.
+type verdict struct {
+	allowed    bool
+	returned   bool
+	translated bool
+
+	// the chain and rule deciding the verdict; the rule is nil when the
+	// traffic reached the end of the chain
+	chain string
+	rule  *explainedRule
+}
+
+type walk struct {
+	iptables   *IPTablesController
+	instanceId string
+
+	inInterface string
+	source      net.IP
+	traffic     kawasaki.Traffic
+	local       bool
+
+	// traffic translated by DNAT is filtered on its translated destination,
+	// and matched against its original one only by --ctorigdst
+	originalDestination net.IP
+	translated          bool
+
+	// rules are keyed by table and chain, as the nat and filter instance
+	// chains share their names
+	rules  map[string][]explainedRule
+	logged bool
+
+	// the rules with unsupported options which the traffic is assumed to match
+	assumed []string
+}
+
+func (w *walk) chain(table, name string, depth int) (verdict, error) {
+	if depth > maxExplainDepth {
+		return verdict{}, fmt.Errorf("chains nested more than %d deep at %s", maxExplainDepth, name)
+	}
+
+	rules, err := w.rulesOf(table, name)
+	if err != nil {
+		return verdict{}, err
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.matches(w) {
+			continue
+		}
+
+		if len(rule.unsupported) > 0 {
+			w.assume(rule)
+		}
+
+		switch rule.target {
+		case "":
+			continue
+		case "ACCEPT":
+			return verdict{allowed: true, chain: name, rule: rule}, nil
+		case "DROP", "REJECT":
+			return verdict{chain: name, rule: rule}, nil
+		case "RETURN":
+			return verdict{returned: true, chain: name, rule: rule}, nil
+		case "DNAT":
+			return verdict{translated: true, chain: name, rule: rule}, nil
+		case "NFLOG", "LOG":
+			w.logged = true
+			continue
+		}
+
+		v, err := w.chain(table, rule.target, depth+1)
+		if err != nil {
+			return verdict{}, err
+		}
+
+		// a logging chain returns on behalf of the rule which went to it
+		if v.returned && strings.HasSuffix(rule.target, "-log") {
+			v.chain, v.rule = name, rule
+		}
+
+		// returning from a chain reached by goto returns from this chain too
+		if !v.returned || rule.gotoTarget {
+			return v, nil
+		}
+	}
+
+	return verdict{returned: true, chain: name}, nil
+}
+
+func (w *walk) assume(rule *explainedRule) {
+	if !contains(w.assumed, rule.spec) {
+		w.assumed = append(w.assumed, rule.spec)
+	}
+}
+
+func (w *walk) rulesOf(table, chain string) ([]explainedRule, error) {
+	key := table + " " + chain
+	if rules, ok := w.rules[key]; ok {
+		return rules, nil
+	}
+
+	specs, err := w.iptables.listRules(table, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]explainedRule, 0, len(specs))
+	for _, spec := range specs {
+		rule, err := parseRule(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	w.rules[key] = rules
+	return rules, nil
+}
+
+func (w *walk) reason(v verdict) string {
+	allowed := v.allowed || v.returned
+	instanceChain := w.iptables.InstanceChain(w.instanceId)
+
+	switch v.chain {
+	case w.iptables.defaultChain:
+		if v.rule == nil {
+			return "not denied by any global deny network"
+		} else if allowed && v.rule.originalDestination != nil {
+			return "allowed to a mapped port through an external IP"
+		} else if allowed {
+			return "allowed by a global allow network"
+		}
+		return "denied by a global deny network"
+	case instanceChain + overridesChainSuffix:
+		if allowed {
+			return "host access allowed by the container's override"
+		}
+		return "denied by the container's deny network override"
+	case instanceChain:
+		if v.rule != nil && v.rule.source != nil {
+			return "allowed within the container's subnet"
+		} else if allowed {
+			return "allowed by a NetOut rule"
+		}
+	case w.iptables.dnsChain(w.instanceId):
+		if allowed {
+			return "allowed by a NetOut hostname rule"
+		}
+	case w.iptables.inputChain:
+		if allowed {
+			return "host access allowed"
+		}
+		return "host access denied"
+	}
+
+	if allowed {
+		return fmt.Sprintf("allowed by chain %s", v.chain)
+	}
+	return fmt.Sprintf("denied by chain %s", v.chain)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,575 @@
+package iptables_test
+
+import (
+	"errors"
+	"net"
+	"os/exec"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/guardian/kawasaki/iptables"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/cloudfoundry/gunk/command_runner/fake_command_runner"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Explainer", func() {
+	var (
+		fakeRunner *fake_command_runner.FakeCommandRunner
+		explainer  *iptables.Explainer
+		logger     lager.Logger
+		cfg        kawasaki.NetworkConfig
+		chains     map[string][]string
+		natChains  map[string][]string
+		traffic    kawasaki.Traffic
+	)
+
+	BeforeEach(func() {
+		fakeRunner = fake_command_runner.New()
+		logger = lagertest.NewTestLogger("test")
+		traffic = kawasaki.Traffic{}
+
+		interfaceAddrs := func() ([]net.Addr, error) {
+			return []net.Addr{
+				&net.IPNet{IP: net.ParseIP("203.0.113.1"), Mask: net.CIDRMask(24, 32)},
+				&net.IPNet{IP: net.ParseIP("10.0.0.1"), Mask: net.CIDRMask(30, 32)},
+			}, nil
+		}
+
+		explainer = iptables.NewExplainer(
+			iptables.New("/sbin/iptables", "/sbin/iptables-restore", fakeRunner, NewFakeLocksmith(), "prefix-"),
+			interfaceAddrs,
+		)
+
+		cfg = kawasaki.NetworkConfig{
+			ContainerHandle: "some-handle",
+			IPTableInstance: "some-id",
+			BridgeName:      "wbrdg-0a000000",
+			ContainerIP:     net.ParseIP("10.0.0.2"),
+		}
+
+		chains = map[string][]string{
+			"prefix-forward": {
+				"-N prefix-forward",
+				"-A prefix-forward -i eth0 -j ACCEPT",
+				`-A prefix-forward -s 10.0.0.2/32 -i wbrdg-0a000000 -m comment --comment some-handle -g prefix-instance-some-id`,
+				"-A prefix-forward -j DROP",
+			},
+			"prefix-input": {
+				"-N prefix-input",
+				"-A prefix-input -i eth0 -j ACCEPT",
+				"-A prefix-input -m conntrack --ctstate RELATED,ESTABLISHED -j ACCEPT",
+				"-A prefix-input -j REJECT --reject-with icmp-host-prohibited",
+			},
+			"prefix-default": {
+				"-N prefix-default",
+				"-A prefix-default -m conntrack --ctstate RELATED,ESTABLISHED -j ACCEPT",
+				"-A prefix-default -m conntrack --ctstate DNAT --ctorigdst 203.0.113.1 -j ACCEPT",
+				"-A prefix-default -d 10.0.0.0/8 -j REJECT --reject-with icmp-port-unreachable",
+				"-A prefix-default -d 192.168.0.0/16 -j REJECT --reject-with icmp-port-unreachable",
+			},
+			"prefix-instance-some-id": {
+				"-N prefix-instance-some-id",
+				"-A prefix-instance-some-id -p tcp -m iprange --dst-range 10.1.0.0-10.1.0.255 -m tcp --dport 8080:8090 -m comment --comment some-handle -g prefix-instance-some-id-log",
+				"-A prefix-instance-some-id -p tcp -d 10.2.0.5/32 -m tcp --dport 443 -m comment --comment some-handle -j RETURN",
+				"-A prefix-instance-some-id -p icmp -d 10.3.0.0/16 -m icmp --icmp-type 8/0 -m comment --comment some-handle -j RETURN",
+				"-A prefix-instance-some-id -s 10.0.0.0/30 -d 10.0.0.0/30 -m comment --comment some-handle -j ACCEPT",
+				"-A prefix-instance-some-id -m comment --comment some-handle -g prefix-instance-some-id-dns",
+			},
+			"prefix-instance-some-id-dns": {
+				"-N prefix-instance-some-id-dns",
+				"-A prefix-instance-some-id-dns -p udp -d 10.4.0.1/32 -m udp --dport 53 -m comment --comment some-handle -j RETURN",
+				"-A prefix-instance-some-id-dns -m comment --comment some-handle -g prefix-default",
+			},
+			"prefix-instance-some-id-log": {
+				"-N prefix-instance-some-id-log",
+				`-A prefix-instance-some-id-log -m conntrack --ctstate NEW,UNTRACKED,INVALID -m comment --comment some-handle -j NFLOG --nflog-prefix some-handle --nflog-group 1`,
+				"-A prefix-instance-some-id-log -m comment --comment some-handle -j RETURN",
+			},
+		}
+
+		natChains = map[string][]string{
+			"prefix-prerouting": {
+				"-N prefix-prerouting",
+				"-A prefix-prerouting -m comment --comment some-handle -j prefix-instance-some-id",
+				"-A prefix-prerouting -m comment --comment other-handle -j prefix-instance-other-id",
+			},
+			"prefix-instance-some-id": {
+				"-N prefix-instance-some-id",
+			},
+			"prefix-instance-other-id": {
+				"-N prefix-instance-other-id",
+				"-A prefix-instance-other-id -d 203.0.113.1/32 -p tcp -m tcp --dport 60000 -m comment --comment other-handle -j DNAT --to-destination 10.0.0.6:8080",
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		for table, tableChains := range map[string]map[string][]string{"filter": chains, "nat": natChains} {
+			for chain, rules := range tableChains {
+				output := ""
+				for _, rule := range rules {
+					output += rule + "\n"
+				}
+
+				fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "--table", table, "-S", chain},
+				}, func(cmd *exec.Cmd) error {
+					cmd.Stdout.Write([]byte(output))
+					return nil
+				})
+			}
+		}
+	})
+
+	explain := func() kawasaki.Explanation {
+		explanation, err := explainer.Explain(logger, cfg, traffic)
+		Expect(err).NotTo(HaveOccurred())
+		return explanation
+	}
+
+	Context("when the traffic matches a NetOut rule", func() {
+		BeforeEach(func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("10.2.0.5"), Port: 443, Protocol: garden.ProtocolTCP}
+		})
+
+		It("allows it, reporting the rule", func() {
+			Expect(explain()).To(Equal(kawasaki.Explanation{
+				Allowed: true,
+				Chain:   "prefix-instance-some-id",
+				Rule:    "-A prefix-instance-some-id -p tcp -d 10.2.0.5/32 -m tcp --dport 443 -m comment --comment some-handle -j RETURN",
+				Reason:  "allowed by a NetOut rule",
+			}))
+		})
+
+		Context("but not its port", func() {
+			BeforeEach(func() {
+				traffic.Port = 444
+			})
+
+			It("is denied by the global deny networks", func() {
+				explanation := explain()
+				Expect(explanation.Allowed).To(BeFalse())
+				Expect(explanation.Chain).To(Equal("prefix-default"))
+				Expect(explanation.Rule).To(Equal("-A prefix-default -d 10.0.0.0/8 -j REJECT --reject-with icmp-port-unreachable"))
+				Expect(explanation.Reason).To(Equal("denied by a global deny network"))
+			})
+		})
+
+		Context("but not its protocol", func() {
+			BeforeEach(func() {
+				traffic.Protocol = garden.ProtocolUDP
+			})
+
+			It("is denied", func() {
+				Expect(explain().Allowed).To(BeFalse())
+			})
+		})
+	})
+
+	Context("when the traffic matches a logging NetOut rule", func() {
+		BeforeEach(func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("10.1.0.17"), Port: 8085, Protocol: garden.ProtocolTCP}
+		})
+
+		It("allows it, reporting the NetOut rule and that it is logged", func() {
+			Expect(explain()).To(Equal(kawasaki.Explanation{
+				Allowed: true,
+				Chain:   "prefix-instance-some-id",
+				Rule:    "-A prefix-instance-some-id -p tcp -m iprange --dst-range 10.1.0.0-10.1.0.255 -m tcp --dport 8080:8090 -m comment --comment some-handle -g prefix-instance-some-id-log",
+				Reason:  "allowed by a NetOut rule",
+				Logged:  true,
+			}))
+		})
+	})
+
+	Context("when the traffic is an ICMP echo request matching an ICMP rule", func() {
+		BeforeEach(func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("10.3.1.1"), Protocol: garden.ProtocolICMP}
+		})
+
+		It("allows it", func() {
+			explanation := explain()
+			Expect(explanation.Allowed).To(BeTrue())
+			Expect(explanation.Reason).To(Equal("allowed by a NetOut rule"))
+		})
+	})
+
+	Context("when the traffic matches a NetOut hostname rule", func() {
+		BeforeEach(func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("10.4.0.1"), Port: 53, Protocol: garden.ProtocolUDP}
+		})
+
+		It("allows it, reporting the rule", func() {
+			explanation := explain()
+			Expect(explanation.Allowed).To(BeTrue())
+			Expect(explanation.Chain).To(Equal("prefix-instance-some-id-dns"))
+			Expect(explanation.Reason).To(Equal("allowed by a NetOut hostname rule"))
+		})
+	})
+
+	Context("when the traffic is to another container in the same subnet", func() {
+		BeforeEach(func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("10.0.0.3"), Port: 8080, Protocol: garden.ProtocolTCP}
+		})
+
+		It("allows it", func() {
+			explanation := explain()
+			Expect(explanation.Allowed).To(BeTrue())
+			Expect(explanation.Reason).To(Equal("allowed within the container's subnet"))
+		})
+	})
+
+	Context("when the traffic is not to a denied network", func() {
+		BeforeEach(func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("8.8.8.8"), Port: 53, Protocol: garden.ProtocolUDP}
+		})
+
+		It("allows it, having reached the end of the default chain", func() {
+			Expect(explain()).To(Equal(kawasaki.Explanation{
+				Allowed: true,
+				Chain:   "prefix-default",
+				Reason:  "not denied by any global deny network",
+			}))
+		})
+	})
+
+	Context("when the traffic is to another container's mapped port through an external IP", func() {
+		BeforeEach(func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("203.0.113.1"), Port: 60000, Protocol: garden.ProtocolTCP}
+		})
+
+		It("allows it in spite of the global deny networks, as it is forwarded to the container", func() {
+			Expect(explain()).To(Equal(kawasaki.Explanation{
+				Allowed: true,
+				Chain:   "prefix-default",
+				Rule:    "-A prefix-default -m conntrack --ctstate DNAT --ctorigdst 203.0.113.1 -j ACCEPT",
+				Reason:  "allowed to a mapped port through an external IP",
+			}))
+		})
+
+		Context("and a NetOut rule matches the port it is mapped to", func() {
+			BeforeEach(func() {
+				chains["prefix-instance-some-id"] = append([]string{
+					"-A prefix-instance-some-id -p tcp -d 10.0.0.6/32 -m tcp --dport 8080 -m comment --comment some-handle -g prefix-instance-some-id-log",
+				}, chains["prefix-instance-some-id"]...)
+			})
+
+			It("is allowed and logged by the NetOut rule", func() {
+				explanation := explain()
+				Expect(explanation.Allowed).To(BeTrue())
+				Expect(explanation.Reason).To(Equal("allowed by a NetOut rule"))
+				Expect(explanation.Logged).To(BeTrue())
+			})
+		})
+
+		Context("but the port is not mapped", func() {
+			BeforeEach(func() {
+				traffic.Port = 60001
+			})
+
+			It("is explained as host access", func() {
+				explanation := explain()
+				Expect(explanation.Allowed).To(BeFalse())
+				Expect(explanation.Reason).To(Equal("host access denied"))
+			})
+		})
+
+		Context("and the forward chain accepts all DNATed traffic", func() {
+			BeforeEach(func() {
+				chains["prefix-forward"] = append([]string{
+					"-A prefix-forward -m conntrack --ctstate DNAT -j ACCEPT",
+				}, chains["prefix-forward"]...)
+			})
+
+			It("allows it on the forward chain", func() {
+				explanation := explain()
+				Expect(explanation.Allowed).To(BeTrue())
+				Expect(explanation.Chain).To(Equal("prefix-forward"))
+				Expect(explanation.Rule).To(Equal("-A prefix-forward -m conntrack --ctstate DNAT -j ACCEPT"))
+			})
+		})
+	})
+
+	Context("when a rule is negated", func() {
+		BeforeEach(func() {
+			chains["prefix-default"] = []string{
+				"-A prefix-default -m conntrack --ctstate RELATED,ESTABLISHED -j ACCEPT",
+				"-A prefix-default ! -d 10.0.0.0/8 -p tcp -m tcp ! --dport 443 -j REJECT --reject-with icmp-port-unreachable",
+			}
+		})
+
+		It("applies to traffic not matching the negated options", func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("8.8.8.8"), Port: 80, Protocol: garden.ProtocolTCP}
+
+			explanation := explain()
+			Expect(explanation.Allowed).To(BeFalse())
+			Expect(explanation.Rule).To(Equal("-A prefix-default ! -d 10.0.0.0/8 -p tcp -m tcp ! --dport 443 -j REJECT --reject-with icmp-port-unreachable"))
+		})
+
+		It("does not apply to traffic matching a negated option", func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("8.8.8.8"), Port: 443, Protocol: garden.ProtocolTCP}
+			Expect(explain().Allowed).To(BeTrue())
+
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("10.9.9.9"), Port: 80, Protocol: garden.ProtocolTCP}
+			Expect(explain().Allowed).To(BeTrue())
+		})
+	})
+
+	Context("when the container has overrides", func() {
+		BeforeEach(func() {
+			chains["prefix-forward"] = []string{
+				"-A prefix-forward -i eth0 -j ACCEPT",
+				"-A prefix-forward -s 10.0.0.2/32 -i wbrdg-0a000000 -m comment --comment some-handle -j prefix-instance-some-id-ovr",
+				"-A prefix-forward -s 10.0.0.2/32 -i wbrdg-0a000000 -m comment --comment some-handle -g prefix-instance-some-id",
+				"-A prefix-forward -j DROP",
+			}
+			chains["prefix-input"] = append([]string{
+				"-A prefix-input -s 10.0.0.2/32 -i wbrdg-0a000000 -m comment --comment some-handle -j prefix-instance-some-id-ovr",
+			}, chains["prefix-input"]...)
+			chains["prefix-instance-some-id-ovr"] = []string{
+				"-A prefix-instance-some-id-ovr -m conntrack --ctstate RELATED,ESTABLISHED -m comment --comment some-handle -j RETURN",
+				"-A prefix-instance-some-id-ovr -d 8.8.0.0/16 -m comment --comment some-handle -j REJECT --reject-with icmp-port-unreachable",
+				"-A prefix-instance-some-id-ovr -m addrtype --dst-type LOCAL -m comment --comment some-handle -j ACCEPT",
+			}
+		})
+
+		It("denies traffic to the container's denied networks", func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("8.8.8.8"), Port: 53, Protocol: garden.ProtocolUDP}
+
+			explanation := explain()
+			Expect(explanation.Allowed).To(BeFalse())
+			Expect(explanation.Chain).To(Equal("prefix-instance-some-id-ovr"))
+			Expect(explanation.Reason).To(Equal("denied by the container's deny network override"))
+		})
+
+		It("continues to the instance chain for other traffic", func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("10.2.0.5"), Port: 443, Protocol: garden.ProtocolTCP}
+
+			explanation := explain()
+			Expect(explanation.Allowed).To(BeTrue())
+			Expect(explanation.Reason).To(Equal("allowed by a NetOut rule"))
+		})
+
+		It("allows host access", func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("203.0.113.1"), Port: 22, Protocol: garden.ProtocolTCP}
+
+			explanation := explain()
+			Expect(explanation.Allowed).To(BeTrue())
+			Expect(explanation.Reason).To(Equal("host access allowed by the container's override"))
+		})
+
+		Context("and the overrides deny the container a mapped port is forwarded to", func() {
+			BeforeEach(func() {
+				chains["prefix-instance-some-id-ovr"] = append([]string{
+					"-A prefix-instance-some-id-ovr -d 10.0.0.6/32 -m comment --comment some-handle -j REJECT --reject-with icmp-port-unreachable",
+				}, chains["prefix-instance-some-id-ovr"]...)
+			})
+
+			It("denies traffic to the mapped port through an external IP", func() {
+				traffic = kawasaki.Traffic{Destination: net.ParseIP("203.0.113.1"), Port: 60000, Protocol: garden.ProtocolTCP}
+
+				explanation := explain()
+				Expect(explanation.Allowed).To(BeFalse())
+				Expect(explanation.Chain).To(Equal("prefix-instance-some-id-ovr"))
+				Expect(explanation.Reason).To(Equal("denied by the container's deny network override"))
+			})
+		})
+	})
+
+	Context("when the container has connection limits", func() {
+		BeforeEach(func() {
+			chains["prefix-forward"] = []string{
+				"-A prefix-forward -i eth0 -j ACCEPT",
+				"-A prefix-forward -s 10.0.0.2/32 -i wbrdg-0a000000 -m comment --comment some-handle -j prefix-instance-some-id-lim",
+				"-A prefix-forward -s 10.0.0.2/32 -i wbrdg-0a000000 -m comment --comment some-handle -g prefix-instance-some-id",
+				"-A prefix-forward -j DROP",
+			}
+			chains["prefix-instance-some-id-lim"] = []string{
+				"-A prefix-instance-some-id-lim -m conntrack --ctstate RELATED,ESTABLISHED -m comment --comment some-handle -j RETURN",
+				"-A prefix-instance-some-id-lim -m conntrack --ctstate NEW -m hashlimit --hashlimit-above 50/sec --hashlimit-burst 50 --hashlimit-mode srcip --hashlimit-name some-id -m comment --comment some-handle -j DROP",
+				"-A prefix-instance-some-id-lim -m conntrack --ctstate NEW -m connlimit --connlimit-above 200 --connlimit-mask 32 --connlimit-saddr -m comment --comment some-handle -j DROP",
+			}
+		})
+
+		It("explains the traffic as if it were within the limits", func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("10.2.0.5"), Port: 443, Protocol: garden.ProtocolTCP}
+
+			explanation := explain()
+			Expect(explanation.Allowed).To(BeTrue())
+			Expect(explanation.Reason).To(Equal("allowed by a NetOut rule"))
+		})
+
+		Context("and the container has exceeded them", func() {
+			BeforeEach(func() {
+				// as the limits themselves are taken not to be exceeded
+				chains["prefix-instance-some-id-lim"] = append(chains["prefix-instance-some-id-lim"],
+					"-A prefix-instance-some-id-lim -m conntrack --ctstate NEW -m comment --comment some-handle -j DROP",
+				)
+			})
+
+			It("drops traffic to a mapped port through an external IP", func() {
+				traffic = kawasaki.Traffic{Destination: net.ParseIP("203.0.113.1"), Port: 60000, Protocol: garden.ProtocolTCP}
+
+				explanation := explain()
+				Expect(explanation.Allowed).To(BeFalse())
+				Expect(explanation.Chain).To(Equal("prefix-instance-some-id-lim"))
+			})
+		})
+	})
+
+	Context("when the traffic is to the host", func() {
+		BeforeEach(func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("10.0.0.1"), Port: 22, Protocol: garden.ProtocolTCP}
+		})
+
+		It("is explained by the input chain", func() {
+			Expect(explain()).To(Equal(kawasaki.Explanation{
+				Allowed: false,
+				Chain:   "prefix-input",
+				Rule:    "-A prefix-input -j REJECT --reject-with icmp-host-prohibited",
+				Reason:  "host access denied",
+			}))
+		})
+	})
+
+	Context("when a chain cannot be listed", func() {
+		BeforeEach(func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("8.8.8.8"), Port: 53, Protocol: garden.ProtocolUDP}
+			delete(chains, "prefix-default")
+
+			fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+				Path: "/sbin/iptables",
+				Args: []string{"--wait", "--table", "filter", "-S", "prefix-default"},
+			}, func(cmd *exec.Cmd) error {
+				cmd.Stderr.Write([]byte("No chain/target/match by that name."))
+				return errors.New("exit status 1")
+			})
+		})
+
+		It("returns an error", func() {
+			_, err := explainer.Explain(logger, cfg, traffic)
+			Expect(err).To(MatchError("iptables: list-rules: No chain/target/match by that name."))
+		})
+	})
+
+	Context("when a rule has options which cannot be evaluated", func() {
+		BeforeEach(func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("8.8.8.8"), Port: 53, Protocol: garden.ProtocolUDP}
+			chains["prefix-default"] = []string{
+				"-A prefix-default -m mark --mark 0x1 -j REJECT",
+			}
+		})
+
+		It("assumes the traffic matches the rule, and says so", func() {
+			Expect(explain()).To(Equal(kawasaki.Explanation{
+				Allowed: false,
+				Chain:   "prefix-default",
+				Rule:    "-A prefix-default -m mark --mark 0x1 -j REJECT",
+				Reason:  "denied by a global deny network, assuming the traffic matches rules with unsupported options",
+				Assumed: []string{"-A prefix-default -m mark --mark 0x1 -j REJECT"},
+			}))
+		})
+
+		Context("and the options are negated flags", func() {
+			BeforeEach(func() {
+				chains["prefix-default"] = []string{
+					"-A prefix-default -m addrtype ! --src-type LOCAL --limit-iface-out -d 8.8.8.0/24 -j REJECT",
+				}
+			})
+
+			It("evaluates the options which follow them", func() {
+				explanation := explain()
+				Expect(explanation.Allowed).To(BeFalse())
+				Expect(explanation.Assumed).To(ConsistOf("-A prefix-default -m addrtype ! --src-type LOCAL --limit-iface-out -d 8.8.8.0/24 -j REJECT"))
+
+				traffic.Destination = net.ParseIP("8.8.4.4")
+				Expect(explain()).To(Equal(kawasaki.Explanation{
+					Allowed: true,
+					Chain:   "prefix-default",
+					Reason:  "not denied by any global deny network",
+				}))
+			})
+		})
+	})
+
+	Context("when a rule cannot be explained", func() {
+		BeforeEach(func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("8.8.8.8"), Port: 53, Protocol: garden.ProtocolUDP}
+			chains["prefix-default"] = []string{
+				"-A prefix-default -d banana -j REJECT",
+			}
+		})
+
+		It("returns an error", func() {
+			_, err := explainer.Explain(logger, cfg, traffic)
+			Expect(err).To(MatchError(ContainSubstring("cannot explain rule '-A prefix-default -d banana -j REJECT'")))
+		})
+	})
+
+	Context("when listing the local addresses fails", func() {
+		BeforeEach(func() {
+			traffic = kawasaki.Traffic{Destination: net.ParseIP("8.8.8.8"), Port: 53, Protocol: garden.ProtocolUDP}
+			explainer = iptables.NewExplainer(
+				iptables.New("/sbin/iptables", "/sbin/iptables-restore", fakeRunner, NewFakeLocksmith(), "prefix-"),
+				func() ([]net.Addr, error) { return nil, errors.New("no-addresses") },
+			)
+		})
+
+		It("returns the error", func() {
+			_, err := explainer.Explain(logger, cfg, traffic)
+			Expect(err).To(MatchError("no-addresses"))
+		})
+	})
+})
+
+var _ = Describe("SingleFilterRule", func() {
+	Describe("Matches", func() {
+		var rule iptables.SingleFilterRule
+
+		BeforeEach(func() {
+			rule = iptables.SingleFilterRule{
+				Protocol: garden.ProtocolTCP,
+				Networks: &garden.IPRange{Start: net.ParseIP("1.2.3.4"), End: net.ParseIP("1.2.3.8")},
+				Ports:    &garden.PortRange{Start: 80, End: 90},
+			}
+		})
+
+		It("matches traffic within its networks and ports", func() {
+			Expect(rule.Matches(kawasaki.Traffic{Destination: net.ParseIP("1.2.3.5"), Port: 85, Protocol: garden.ProtocolTCP})).To(BeTrue())
+		})
+
+		It("does not match traffic outside its networks", func() {
+			Expect(rule.Matches(kawasaki.Traffic{Destination: net.ParseIP("1.2.3.9"), Port: 85, Protocol: garden.ProtocolTCP})).To(BeFalse())
+		})
+
+		It("does not match traffic outside its ports", func() {
+			Expect(rule.Matches(kawasaki.Traffic{Destination: net.ParseIP("1.2.3.5"), Port: 91, Protocol: garden.ProtocolTCP})).To(BeFalse())
+		})
+
+		It("does not match traffic of another protocol", func() {
+			Expect(rule.Matches(kawasaki.Traffic{Destination: net.ParseIP("1.2.3.5"), Port: 85, Protocol: garden.ProtocolUDP})).To(BeFalse())
+		})
+
+		It("treats a range with only a start as a single address", func() {
+			rule.Networks = &garden.IPRange{Start: net.ParseIP("1.2.3.4")}
+			Expect(rule.Matches(kawasaki.Traffic{Destination: net.ParseIP("1.2.3.4"), Port: 85, Protocol: garden.ProtocolTCP})).To(BeTrue())
+			Expect(rule.Matches(kawasaki.Traffic{Destination: net.ParseIP("1.2.3.5"), Port: 85, Protocol: garden.ProtocolTCP})).To(BeFalse())
+		})
+
+		It("matches rules translated from NetOut rules", func() {
+			rules, err := iptables.NewRuleTranslator().TranslateRule("some-handle", garden.NetOutRule{
+				Protocol: garden.ProtocolAll,
+				Networks: []garden.IPRange{garden.IPRangeFromIP(net.ParseIP("5.6.7.8"))},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rules).To(HaveLen(1))
+
+			Expect(rules[0].(iptables.SingleFilterRule).Matches(kawasaki.Traffic{Destination: net.ParseIP("5.6.7.8"), Protocol: garden.ProtocolICMP})).To(BeTrue())
+		})
+	})
+})
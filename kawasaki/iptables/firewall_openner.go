@@ -68,3 +68,26 @@ func (f *FirewallOpener) BulkOpen(logger lager.Logger, instance, handle string,
 
 	return f.iptables.BulkPrependRules(chain, collatedIPTablesRules)
 }
+
+// ReplaceDNSRules replaces the rules previously opened for the addresses
+// resolved from hostname NetOut rules
+func (f *FirewallOpener) ReplaceDNSRules(logger lager.Logger, instance, handle string, rules []garden.NetOutRule) error {
+	logger = logger.Session("replace-dns-rules", lager.Data{
+		"rules":    rules,
+		"instance": instance,
+	})
+	logger.Debug("started")
+	defer logger.Debug("ending")
+
+	collatedIPTablesRules := []Rule{}
+	for _, rule := range rules {
+		iptablesRules, err := f.ruleTranslator.TranslateRule(handle, rule)
+		if err != nil {
+			return err
+		}
+
+		collatedIPTablesRules = append(collatedIPTablesRules, iptablesRules...)
+	}
+
+	return f.iptables.ReplaceDNSRules(instance, handle, collatedIPTablesRules)
+}
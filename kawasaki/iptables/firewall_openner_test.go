@@ -181,4 +181,54 @@ var _ = Describe("FirewallOpenner", func() {
 			})
 		})
 	})
+
+	Describe("ReplaceDNSRules", func() {
+		var rules []garden.NetOutRule
+
+		BeforeEach(func() {
+			rules = []garden.NetOutRule{
+				garden.NetOutRule{Protocol: garden.ProtocolUDP},
+				garden.NetOutRule{Protocol: garden.ProtocolTCP},
+			}
+
+			fakeRuleTranslator.TranslateRuleStub = func(_ string, gardenRule garden.NetOutRule) ([]iptables.Rule, error) {
+				return []iptables.Rule{iptables.SingleFilterRule{Protocol: gardenRule.Protocol}}, nil
+			}
+		})
+
+		It("replaces the instance's DNS rules with the translated rules", func() {
+			Expect(opener.ReplaceDNSRules(logger, "foo-bar-baz", "some-handle", rules)).To(Succeed())
+
+			Expect(fakeIPTablesController.ReplaceDNSRulesCallCount()).To(Equal(1))
+			instance, handle, iptablesRules := fakeIPTablesController.ReplaceDNSRulesArgsForCall(0)
+			Expect(instance).To(Equal("foo-bar-baz"))
+			Expect(handle).To(Equal("some-handle"))
+			Expect(iptablesRules).To(Equal([]iptables.Rule{
+				iptables.SingleFilterRule{Protocol: garden.ProtocolUDP},
+				iptables.SingleFilterRule{Protocol: garden.ProtocolTCP},
+			}))
+		})
+
+		Context("when translating a rule fails", func() {
+			BeforeEach(func() {
+				fakeRuleTranslator.TranslateRuleStub = nil
+				fakeRuleTranslator.TranslateRuleReturns(nil, errors.New("failed to build rules"))
+			})
+
+			It("returns the error", func() {
+				Expect(opener.ReplaceDNSRules(logger, "foo-bar-baz", "some-handle", rules)).To(MatchError("failed to build rules"))
+				Expect(fakeIPTablesController.ReplaceDNSRulesCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when replacing the rules fails", func() {
+			BeforeEach(func() {
+				fakeIPTablesController.ReplaceDNSRulesReturns(errors.New("i-lost-my-banana"))
+			})
+
+			It("returns the error", func() {
+				Expect(opener.ReplaceDNSRules(logger, "foo-bar-baz", "some-handle", rules)).To(MatchError("i-lost-my-banana"))
+			})
+		})
+	})
 })
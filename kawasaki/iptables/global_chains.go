@@ -2,6 +2,7 @@ package iptables
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 
@@ -51,7 +52,14 @@ const SetupScript = `
 		# Prune garden-forward chain
 		rules=$(${iptables_bin} -w -S ${filter_forward_chain} 2> /dev/null) || true
 		echo "$rules" |
-		grep "\-g ${filter_instance_prefix}" |
+		grep "\-[gj] ${filter_instance_prefix}" |
+		sed -e "s/-A/-D/" -e "s/\s\+\$//" |
+		xargs --no-run-if-empty --max-lines=1 ${iptables_bin} -w
+
+		# Prune jumps to per-instance override chains from filter input chain
+		rules=$(${iptables_bin} -w -S ${filter_input_chain} 2> /dev/null) || true
+		echo "$rules" |
+		grep "\-j ${filter_instance_prefix}" |
 		sed -e "s/-A/-D/" -e "s/\s\+\$//" |
 		xargs --no-run-if-empty --max-lines=1 ${iptables_bin} -w
 
@@ -212,17 +220,21 @@ type Starter struct {
 	allowHostAccess            bool
 	destroyContainersOnStartup bool
 	nicPrefix                  string
+	allowNetworks              []string
 	denyNetworks               []string
+	externalIPs                []net.IP
 	logger                     lager.Logger
 }
 
-func NewStarter(iptables *IPTablesController, allowHostAccess bool, nicPrefix string, denyNetworks []string, destroyContainersOnStartup bool, logger lager.Logger) *Starter {
+func NewStarter(iptables *IPTablesController, allowHostAccess bool, nicPrefix string, allowNetworks, denyNetworks []string, externalIPs []net.IP, destroyContainersOnStartup bool, logger lager.Logger) *Starter {
 	return &Starter{
 		iptables:                   iptables,
 		allowHostAccess:            allowHostAccess,
 		destroyContainersOnStartup: destroyContainersOnStartup,
 		nicPrefix:                  nicPrefix,
+		allowNetworks:              allowNetworks,
 		denyNetworks:               denyNetworks,
+		externalIPs:                externalIPs,
 		logger:                     logger.Session("create-global-iptables-chains"),
 	}
 }
@@ -258,6 +270,24 @@ func (s Starter) Start() error {
 		return err
 	}
 
+	// containers reach each other's mapped ports through the external IPs in
+	// spite of the denied networks, as from outside the host. This chain is
+	// consulted last, so their connection limits, overrides and NetOut rules
+	// still apply.
+	for _, ip := range s.externalIPs {
+		if err := s.iptables.appendRule(s.iptables.defaultChain, netInAcceptRule(ip.String())); err != nil {
+			return err
+		}
+	}
+
+	// allowed networks are accepted before the denied networks are rejected,
+	// so that holes can be punched in a wider denied range
+	for _, n := range s.allowNetworks {
+		if err := s.iptables.appendRule(s.iptables.defaultChain, acceptRule(n)); err != nil {
+			return err
+		}
+	}
+
 	for _, n := range s.denyNetworks {
 		if err := s.iptables.appendRule(s.iptables.defaultChain, rejectRule(n)); err != nil {
 			return err
@@ -3,6 +3,7 @@ package iptables_test
 import (
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 
@@ -17,13 +18,17 @@ import (
 var _ = Describe("Setup", func() {
 	var (
 		fakeRunner                 *fake_command_runner.FakeCommandRunner
+		allowNetworks              []string
 		denyNetworks               []string
+		externalIPs                []net.IP
 		destroyContainersOnStartup bool
 		starter                    *iptables.Starter
 	)
 
 	BeforeEach(func() {
 		fakeRunner = fake_command_runner.New()
+		allowNetworks = nil
+		externalIPs = nil
 		destroyContainersOnStartup = false
 	})
 
@@ -33,7 +38,9 @@ var _ = Describe("Setup", func() {
 			iptables.New("/sbin/iptables", "/sbin/iptables-restore", fakeRunner, fakeLocksmith, "prefix-"),
 			true,
 			"the-nic-prefix",
+			allowNetworks,
 			denyNetworks,
+			externalIPs,
 			destroyContainersOnStartup,
 			lagertest.NewTestLogger("global_chains_test"),
 		)
@@ -106,6 +113,10 @@ var _ = Describe("Setup", func() {
 				itSetsUpGlobalChains()
 			})
 
+			It("does not accept forwarded connections ahead of the containers' own rules", func() {
+				Expect(iptables.SetupScript).NotTo(ContainSubstring("--ctstate DNAT"))
+			})
+
 			Context("when running the setup script fails", func() {
 				BeforeEach(func() {
 					fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
@@ -207,6 +218,56 @@ var _ = Describe("Setup", func() {
 					itRejectsNetwork("8.7.6.5/33")
 				})
 
+				Context("and external IPs are configured", func() {
+					BeforeEach(func() {
+						externalIPs = []net.IP{net.ParseIP("203.0.113.1"), net.ParseIP("203.0.113.2")}
+					})
+
+					It("accepts connections to mapped ports through them before rejecting the denied networks", func() {
+						Expect(starter.Start()).To(Succeed())
+
+						Expect(fakeRunner).To(HaveExecutedSerially(
+							fake_command_runner.CommandSpec{
+								Path: "/sbin/iptables",
+								Args: []string{"-w", "-A", "prefix-default", "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "--jump", "ACCEPT"},
+							},
+							fake_command_runner.CommandSpec{
+								Path: "/sbin/iptables",
+								Args: []string{"-w", "-A", "prefix-default", "-m", "conntrack", "--ctstate", "DNAT", "--ctorigdst", "203.0.113.1", "--jump", "ACCEPT"},
+							},
+							fake_command_runner.CommandSpec{
+								Path: "/sbin/iptables",
+								Args: []string{"-w", "-A", "prefix-default", "-m", "conntrack", "--ctstate", "DNAT", "--ctorigdst", "203.0.113.2", "--jump", "ACCEPT"},
+							},
+							fake_command_runner.CommandSpec{
+								Path: "/sbin/iptables",
+								Args: []string{"-w", "-A", "prefix-default", "--destination", "4.3.2.1/11", "--jump", "REJECT"},
+							},
+						))
+					})
+				})
+
+				Context("and allow networks are configured", func() {
+					BeforeEach(func() {
+						allowNetworks = []string{"4.3.2.0/24"}
+					})
+
+					It("accepts the allowed networks before rejecting the denied networks", func() {
+						Expect(starter.Start()).To(Succeed())
+
+						Expect(fakeRunner).To(HaveExecutedSerially(
+							fake_command_runner.CommandSpec{
+								Path: "/sbin/iptables",
+								Args: []string{"-w", "-A", "prefix-default", "--destination", "4.3.2.0/24", "--jump", "ACCEPT"},
+							},
+							fake_command_runner.CommandSpec{
+								Path: "/sbin/iptables",
+								Args: []string{"-w", "-A", "prefix-default", "--destination", "4.3.2.1/11", "--jump", "REJECT"},
+							},
+						))
+					})
+				})
+
 				Context("when resetting deny networks fail", func() {
 					Context("when flushing the chain fails", func() {
 						BeforeEach(func() {
@@ -0,0 +1,161 @@
+package iptables
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/lager"
+)
+
+// GlobalPolicyReloader rewrites the default and input chains set up by the
+// Starter to match a new global policy, without touching instance chains
+type GlobalPolicyReloader struct {
+	iptables    *IPTablesController
+	externalIPs []net.IP
+	mu          sync.Mutex
+}
+
+func NewGlobalPolicyReloader(iptables *IPTablesController, externalIPs []net.IP) *GlobalPolicyReloader {
+	return &GlobalPolicyReloader{iptables: iptables, externalIPs: externalIPs}
+}
+
+func (r *GlobalPolicyReloader) Reload(logger lager.Logger, policy kawasaki.GlobalPolicy) error {
+	log := logger.Session("reload-global-policy", lager.Data{"policy": policy})
+	log.Info("started")
+	defer log.Info("finished")
+
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before, err := r.rules()
+	if err != nil {
+		log.Error("listing-rules", err)
+		return err
+	}
+
+	in := bytes.NewBuffer([]byte{})
+	in.WriteString("*filter\n")
+	// declaring the chain flushes it within the same transaction
+	in.WriteString(fmt.Sprintf(":%s - [0:0]\n", r.iptables.defaultChain))
+	r.writeRule(in, r.iptables.defaultChain, iptablesFlags{"-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "--jump", "ACCEPT"})
+	for _, ip := range r.externalIPs {
+		r.writeRule(in, r.iptables.defaultChain, netInAcceptRule(ip.String()))
+	}
+	for _, n := range policy.AllowNetworks {
+		r.writeRule(in, r.iptables.defaultChain, acceptRule(n))
+	}
+	for _, n := range policy.DenyNetworks {
+		r.writeRule(in, r.iptables.defaultChain, rejectRule(n))
+	}
+
+	// the input chain also holds rules which are not part of the policy, so
+	// only its final host access rule is replaced
+	for _, rule := range before[r.iptables.inputChain] {
+		if r.isHostAccessRule(rule) {
+			in.WriteString("-D" + strings.TrimPrefix(rule, "-A") + "\n")
+		}
+	}
+	r.writeRule(in, r.iptables.inputChain, hostAccessRule(policy.AllowHostAccess))
+	in.WriteString("COMMIT\n")
+
+	cmd := exec.Command(r.iptables.iptablesRestoreBinPath, "--noflush")
+	cmd.Stdin = in
+	if err := r.iptables.run("reload-global-policy", cmd); err != nil {
+		log.Error("restoring-rules", err)
+		return err
+	}
+
+	after, err := r.rules()
+	if err != nil {
+		log.Error("listing-rules", err)
+		return err
+	}
+
+	added, removed := r.diff(before, after)
+	log.Info("reloaded", lager.Data{"added": added, "removed": removed})
+
+	return nil
+}
+
+func (r *GlobalPolicyReloader) chains() []string {
+	return []string{r.iptables.defaultChain, r.iptables.inputChain}
+}
+
+func (r *GlobalPolicyReloader) rules() (map[string][]string, error) {
+	rules := make(map[string][]string)
+	for _, chain := range r.chains() {
+		chainRules, err := r.iptables.listRules("filter", chain)
+		if err != nil {
+			return nil, err
+		}
+
+		rules[chain] = chainRules
+	}
+
+	return rules, nil
+}
+
+func (r *GlobalPolicyReloader) isHostAccessRule(rule string) bool {
+	for _, allow := range []bool{true, false} {
+		// iptables -S prints -j in place of --jump
+		flags := strings.Replace(strings.Join(hostAccessRule(allow).Flags(r.iptables.inputChain), " "), "--jump", "-j", 1)
+		if rule == fmt.Sprintf("-A %s %s", r.iptables.inputChain, flags) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *GlobalPolicyReloader) writeRule(in *bytes.Buffer, chain string, rule Rule) {
+	in.WriteString(fmt.Sprintf("-A %s ", chain))
+	in.WriteString(strings.Join(rule.Flags(chain), " "))
+	in.WriteString("\n")
+}
+
+func hostAccessRule(allow bool) Rule {
+	if allow {
+		return iptablesFlags{"--jump", "ACCEPT"}
+	}
+
+	return iptablesFlags{"--jump", "REJECT", "--reject-with", "icmp-host-prohibited"}
+}
+
+func (r *GlobalPolicyReloader) diff(before, after map[string][]string) (added, removed []string) {
+	for _, chain := range r.chains() {
+		added = append(added, missingRules(after[chain], before[chain])...)
+		removed = append(removed, missingRules(before[chain], after[chain])...)
+	}
+
+	return added, removed
+}
+
+func missingRules(rules, from []string) []string {
+	var missing []string
+	for _, rule := range rules {
+		if !hasRule(from, rule) {
+			missing = append(missing, rule)
+		}
+	}
+
+	return missing
+}
+
+func hasRule(rules []string, target string) bool {
+	for _, rule := range rules {
+		if rule == target {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,197 @@
+package iptables_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os/exec"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/guardian/kawasaki/iptables"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/cloudfoundry/gunk/command_runner/fake_command_runner"
+	. "github.com/cloudfoundry/gunk/command_runner/fake_command_runner/matchers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GlobalPolicyReloader", func() {
+	var (
+		fakeRunner *fake_command_runner.FakeCommandRunner
+		logger     *lagertest.TestLogger
+		reloader   *iptables.GlobalPolicyReloader
+		policy     kawasaki.GlobalPolicy
+		chains     map[string]string
+		listErr    map[string]string
+		restoreErr string
+		restored   string
+	)
+
+	BeforeEach(func() {
+		fakeRunner = fake_command_runner.New()
+		logger = lagertest.NewTestLogger("global-policy")
+		reloader = iptables.NewGlobalPolicyReloader(
+			iptables.New("/sbin/iptables", "/sbin/iptables-restore", fakeRunner, NewFakeLocksmith(), "prefix-"),
+			[]net.IP{net.ParseIP("203.0.113.1")},
+		)
+
+		policy = kawasaki.GlobalPolicy{
+			AllowHostAccess: true,
+			AllowNetworks:   []string{"10.0.1.0/24"},
+			DenyNetworks:    []string{"10.0.0.0/8"},
+		}
+
+		chains = map[string]string{
+			"prefix-default": `-N prefix-default
+-A prefix-default -m conntrack --ctstate RELATED,ESTABLISHED -j ACCEPT
+-A prefix-default -m conntrack --ctstate DNAT --ctorigdst 203.0.113.1 -j ACCEPT
+-A prefix-default -d 192.168.0.0/16 -j REJECT --reject-with icmp-port-unreachable
+`,
+			"prefix-input": `-N prefix-input
+-A prefix-input -i eth0 -j ACCEPT
+-A prefix-input -m conntrack --ctstate RELATED,ESTABLISHED -j ACCEPT
+-A prefix-input -j REJECT --reject-with icmp-host-prohibited
+`,
+		}
+
+		listErr = map[string]string{}
+		for chain := range chains {
+			chain := chain
+			fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+				Path: "/sbin/iptables",
+				Args: []string{"--wait", "--table", "filter", "-S", chain},
+			}, func(cmd *exec.Cmd) error {
+				if msg, ok := listErr[chain]; ok {
+					cmd.Stderr.Write([]byte(msg))
+					return errors.New("exit status 1")
+				}
+
+				cmd.Stdout.Write([]byte(chains[chain]))
+				return nil
+			})
+		}
+
+		restored = ""
+		restoreErr = ""
+		fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+			Path: "/sbin/iptables-restore",
+			Args: []string{"--noflush"},
+		}, func(cmd *exec.Cmd) error {
+			if restoreErr != "" {
+				cmd.Stderr.Write([]byte(restoreErr))
+				return errors.New("exit status 1")
+			}
+
+			in, err := ioutil.ReadAll(cmd.Stdin)
+			Expect(err).NotTo(HaveOccurred())
+			restored = string(in)
+
+			chains["prefix-default"] = `-N prefix-default
+-A prefix-default -m conntrack --ctstate RELATED,ESTABLISHED -j ACCEPT
+-A prefix-default -m conntrack --ctstate DNAT --ctorigdst 203.0.113.1 -j ACCEPT
+-A prefix-default -d 10.0.1.0/24 -j ACCEPT
+-A prefix-default -d 10.0.0.0/8 -j REJECT --reject-with icmp-port-unreachable
+`
+			chains["prefix-input"] = `-N prefix-input
+-A prefix-input -i eth0 -j ACCEPT
+-A prefix-input -m conntrack --ctstate RELATED,ESTABLISHED -j ACCEPT
+-A prefix-input -j ACCEPT
+`
+			return nil
+		})
+	})
+
+	It("atomically rewrites the default chain and the input chain's host access rule", func() {
+		Expect(reloader.Reload(logger, policy)).To(Succeed())
+
+		Expect(restored).To(Equal(`*filter
+:prefix-default - [0:0]
+-A prefix-default -m conntrack --ctstate ESTABLISHED,RELATED --jump ACCEPT
+-A prefix-default -m conntrack --ctstate DNAT --ctorigdst 203.0.113.1 --jump ACCEPT
+-A prefix-default --destination 10.0.1.0/24 --jump ACCEPT
+-A prefix-default --destination 10.0.0.0/8 --jump REJECT
+-D prefix-input -j REJECT --reject-with icmp-host-prohibited
+-A prefix-input --jump ACCEPT
+COMMIT
+`))
+	})
+
+	It("does not touch any other chain", func() {
+		Expect(reloader.Reload(logger, policy)).To(Succeed())
+
+		for _, cmd := range fakeRunner.ExecutedCommands() {
+			Expect(cmd.Args).NotTo(ContainElement(ContainSubstring("instance")))
+		}
+	})
+
+	It("logs the rules which were added and removed", func() {
+		Expect(reloader.Reload(logger, policy)).To(Succeed())
+
+		var data map[string]interface{}
+		for _, log := range logger.Logs() {
+			if log.Message == "global-policy.reload-global-policy.reloaded" {
+				data = log.Data
+			}
+		}
+
+		Expect(data).NotTo(BeNil())
+		Expect(data["added"]).To(ConsistOf(
+			"-A prefix-default -d 10.0.1.0/24 -j ACCEPT",
+			"-A prefix-default -d 10.0.0.0/8 -j REJECT --reject-with icmp-port-unreachable",
+			"-A prefix-input -j ACCEPT",
+		))
+		Expect(data["removed"]).To(ConsistOf(
+			"-A prefix-default -d 192.168.0.0/16 -j REJECT --reject-with icmp-port-unreachable",
+			"-A prefix-input -j REJECT --reject-with icmp-host-prohibited",
+		))
+	})
+
+	Context("when host access is denied", func() {
+		BeforeEach(func() {
+			policy.AllowHostAccess = false
+			chains["prefix-input"] = `-N prefix-input
+-A prefix-input -j ACCEPT
+`
+		})
+
+		It("replaces the accept rule with a reject rule", func() {
+			Expect(reloader.Reload(logger, policy)).To(Succeed())
+
+			Expect(restored).To(ContainSubstring("-D prefix-input -j ACCEPT\n-A prefix-input --jump REJECT --reject-with icmp-host-prohibited\n"))
+		})
+	})
+
+	Context("when the policy is invalid", func() {
+		BeforeEach(func() {
+			policy.DenyNetworks = []string{"banana"}
+		})
+
+		It("returns an error without running iptables", func() {
+			Expect(reloader.Reload(logger, policy)).To(MatchError("invalid network: banana"))
+			Expect(fakeRunner.ExecutedCommands()).To(BeEmpty())
+		})
+	})
+
+	Context("when listing the rules fails", func() {
+		BeforeEach(func() {
+			listErr["prefix-input"] = "No chain/target/match by that name."
+		})
+
+		It("returns the error without changing the rules", func() {
+			Expect(reloader.Reload(logger, policy)).To(MatchError(ContainSubstring("No chain/target/match by that name.")))
+			Expect(fakeRunner).NotTo(HaveExecutedSerially(fake_command_runner.CommandSpec{
+				Path: "/sbin/iptables-restore",
+			}))
+		})
+	})
+
+	Context("when restoring the rules fails", func() {
+		BeforeEach(func() {
+			restoreErr = "iptables-restore: line 3 failed"
+		})
+
+		It("returns the error", func() {
+			Expect(reloader.Reload(logger, policy)).To(MatchError(ContainSubstring("line 3 failed")))
+		})
+	})
+})
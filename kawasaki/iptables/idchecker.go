@@ -0,0 +1,22 @@
+package iptables
+
+// ChainIDChecker reports an ID as in use when an instance chain named after
+// it exists in the filter or nat table
+type ChainIDChecker struct {
+	iptables *IPTablesController
+}
+
+func NewChainIDChecker(iptables *IPTablesController) *ChainIDChecker {
+	return &ChainIDChecker{iptables: iptables}
+}
+
+func (c *ChainIDChecker) InUse(id string) (bool, error) {
+	for _, table := range []string{"filter", "nat"} {
+		exists, err := c.iptables.chainExists(table, c.iptables.InstanceChain(id))
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+
+	return false, nil
+}
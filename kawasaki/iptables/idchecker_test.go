@@ -0,0 +1,77 @@
+package iptables_test
+
+import (
+	"errors"
+	"os/exec"
+
+	"code.cloudfoundry.org/guardian/kawasaki/iptables"
+	"github.com/cloudfoundry/gunk/command_runner/fake_command_runner"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ChainIDChecker", func() {
+	var (
+		fakeRunner *fake_command_runner.FakeCommandRunner
+		chains     map[string]string
+		listErr    string
+		checker    *iptables.ChainIDChecker
+	)
+
+	BeforeEach(func() {
+		fakeRunner = fake_command_runner.New()
+		chains = map[string]string{}
+		listErr = ""
+
+		for _, table := range []string{"filter", "nat"} {
+			table := table
+			fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+				Path: "/sbin/iptables",
+				Args: []string{"--wait", "--table", table, "-S", "prefix-instance-some-id"},
+			}, func(cmd *exec.Cmd) error {
+				if listErr != "" {
+					cmd.Stderr.Write([]byte(listErr))
+					return errors.New("exit status 4")
+				}
+
+				rules, ok := chains[table]
+				if !ok {
+					cmd.Stderr.Write([]byte("iptables: No chain/target/match by that name."))
+					return errors.New("exit status 1")
+				}
+
+				cmd.Stdout.Write([]byte(rules))
+				return nil
+			})
+		}
+
+		checker = iptables.NewChainIDChecker(
+			iptables.New("/sbin/iptables", "/sbin/iptables-restore", fakeRunner, NewFakeLocksmith(), "prefix-"),
+		)
+	})
+
+	It("reports the ID as unused when no instance chain is named after it", func() {
+		Expect(checker.InUse("some-id")).To(BeFalse())
+	})
+
+	It("reports the ID as used when the filter instance chain exists", func() {
+		chains["filter"] = "-N prefix-instance-some-id\n"
+		Expect(checker.InUse("some-id")).To(BeTrue())
+	})
+
+	It("reports the ID as used when the nat instance chain exists", func() {
+		chains["nat"] = "-N prefix-instance-some-id\n"
+		Expect(checker.InUse("some-id")).To(BeTrue())
+	})
+
+	Context("when listing a chain fails for another reason", func() {
+		BeforeEach(func() {
+			listErr = "Another app is currently holding the xtables lock."
+		})
+
+		It("returns the error", func() {
+			_, err := checker.InUse("some-id")
+			Expect(err).To(MatchError(ContainSubstring("xtables lock")))
+		})
+	})
+})
@@ -4,17 +4,28 @@ import (
 	"fmt"
 	"net"
 	"os/exec"
+	"strings"
 
+	"code.cloudfoundry.org/guardian/kawasaki"
 	"code.cloudfoundry.org/lager"
 )
 
+// The suffix of per-instance override chains, kept short since iptables limits
+// chain names to 28 characters
+const overridesChainSuffix = "-ovr"
+
+// The suffix of per-instance connection limit chains
+const connectionLimitsChainSuffix = "-lim"
+
 type InstanceChainCreator struct {
-	iptables *IPTablesController
+	iptables   *IPTablesController
+	nflogGroup uint16
 }
 
-func NewInstanceChainCreator(iptables *IPTablesController) *InstanceChainCreator {
+func NewInstanceChainCreator(iptables *IPTablesController, nflogGroup uint16) *InstanceChainCreator {
 	return &InstanceChainCreator{
-		iptables: iptables,
+		iptables:   iptables,
+		nflogGroup: nflogGroup,
 	}
 }
 
@@ -41,6 +52,17 @@ func (cc *InstanceChainCreator) Create(logger lager.Logger, handle, instanceId,
 		return err
 	}
 
+	// Masquerade hairpin traffic, i.e. containers reaching their own mapped
+	// ports, so that replies are routed back through the bridge
+	cmd = exec.Command("sh", "-c", fmt.Sprintf(
+		`(%s --wait --table nat -S %s | grep "\-j MASQUERADE\b" | grep -q -F -- "-s %s -d %s -m conntrack --ctstate DNAT") || %s --wait --table nat -A %s --source %s --destination %s -m conntrack --ctstate DNAT --jump MASQUERADE -m comment --comment %s`,
+		cc.iptables.iptablesBinPath, cc.iptables.postroutingChain, network.String(), network.String(), cc.iptables.iptablesBinPath, cc.iptables.postroutingChain,
+		network.String(), network.String(), handle,
+	))
+	if err := cc.iptables.run("create-instance-chains", cmd); err != nil {
+		return err
+	}
+
 	// Create filter instance chain
 	if err := cc.iptables.CreateChain("filter", instanceChain); err != nil {
 		return err
@@ -52,8 +74,20 @@ func (cc *InstanceChainCreator) Create(logger lager.Logger, handle, instanceId,
 		return err
 	}
 
-	// Otherwise, use the default filter chain
-	cmd = exec.Command(cc.iptables.iptablesBinPath, "--wait", "-A", instanceChain, "--goto", cc.iptables.defaultChain, "-m", "comment", "--comment", handle)
+	// Create DNS chain, which holds the rules resolved from hostname NetOut
+	// rules and otherwise uses the default filter chain
+	dnsChain := cc.iptables.dnsChain(instanceId)
+	if err := cc.iptables.CreateChain("filter", dnsChain); err != nil {
+		return err
+	}
+
+	cmd = exec.Command(cc.iptables.iptablesBinPath, "--wait", "-A", dnsChain, "--goto", cc.iptables.defaultChain, "-m", "comment", "--comment", handle)
+	if err := cc.iptables.run("create-instance-chains", cmd); err != nil {
+		return err
+	}
+
+	// Otherwise, use the DNS chain
+	cmd = exec.Command(cc.iptables.iptablesBinPath, "--wait", "-A", instanceChain, "--goto", dnsChain, "-m", "comment", "--comment", handle)
 	if err := cc.iptables.run("create-instance-chains", cmd); err != nil {
 		return err
 	}
@@ -76,12 +110,10 @@ func (cc *InstanceChainCreator) createLoggingChain(logger lager.Logger, handle,
 		return err
 	}
 
-	logPrefix := handle
-	if len(logPrefix) > 29 {
-		logPrefix = logPrefix[0:29]
-	}
-
-	cmd := exec.Command(cc.iptables.iptablesBinPath, "--wait", "-A", loggingChain, "-m", "conntrack", "--ctstate", "NEW,UNTRACKED,INVALID", "--protocol", "tcp", "--jump", "LOG", "--log-prefix", logPrefix, "-m", "comment", "--comment", handle)
+	// The prefix is used by the nflog collector to attribute packets to
+	// containers. Handles may not fit in an NFLOG prefix (63 bytes), but
+	// instance IDs fit in a chain name, so are short enough and unique.
+	cmd := exec.Command(cc.iptables.iptablesBinPath, "--wait", "-A", loggingChain, "-m", "conntrack", "--ctstate", "NEW,UNTRACKED,INVALID", "--jump", "NFLOG", "--nflog-group", fmt.Sprintf("%d", cc.nflogGroup), "--nflog-prefix", instanceId, "-m", "comment", "--comment", handle)
 	if err := cc.iptables.run("create-instance-chains", cmd); err != nil {
 		return err
 	}
@@ -94,6 +126,187 @@ func (cc *InstanceChainCreator) createLoggingChain(logger lager.Logger, handle,
 	return nil
 }
 
+// CreateOverrides creates a chain holding the container's exceptions to the
+// global host access and deny network settings. It is consulted for traffic
+// from the container ahead of the instance chain, so that denied networks
+// cannot be opened by NetOut rules.
+func (cc *InstanceChainCreator) CreateOverrides(logger lager.Logger, handle, instanceId, bridgeName string, ip net.IP, overrides kawasaki.NetworkOverrides) error {
+	overridesChain := cc.iptables.InstanceChain(instanceId) + overridesChainSuffix
+
+	if err := cc.iptables.CreateChain("filter", overridesChain); err != nil {
+		return err
+	}
+
+	// Leave established connections, e.g. replies to NetIn traffic, to the instance chain
+	cmd := exec.Command(cc.iptables.iptablesBinPath, "--wait", "-A", overridesChain, "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "--jump", "RETURN", "-m", "comment", "--comment", handle)
+	if err := cc.iptables.run("create-overrides-chain", cmd); err != nil {
+		return err
+	}
+
+	for _, network := range overrides.DenyNetworks {
+		cmd = exec.Command(cc.iptables.iptablesBinPath, "--wait", "-A", overridesChain, "--destination", network.String(), "--jump", "REJECT", "-m", "comment", "--comment", handle)
+		if err := cc.iptables.run("create-overrides-chain", cmd); err != nil {
+			return err
+		}
+	}
+
+	// Only matches traffic which reaches the chain from the input chain
+	if overrides.AllowHostAccess {
+		cmd = exec.Command(cc.iptables.iptablesBinPath, "--wait", "-A", overridesChain, "-m", "addrtype", "--dst-type", "LOCAL", "--jump", "ACCEPT", "-m", "comment", "--comment", handle)
+		if err := cc.iptables.run("create-overrides-chain", cmd); err != nil {
+			return err
+		}
+	}
+
+	// Bind overrides chain to filter forward chain, ahead of the instance chain
+	cmd = exec.Command(cc.iptables.iptablesBinPath, "--wait", "-I", cc.iptables.forwardChain, "2", "--in-interface", bridgeName, "--source", ip.String(), "--jump", overridesChain, "-m", "comment", "--comment", handle)
+	if err := cc.iptables.run("create-overrides-chain", cmd); err != nil {
+		return err
+	}
+
+	// Bind overrides chain to filter input chain, ahead of its host access rule
+	cmd = exec.Command(cc.iptables.iptablesBinPath, "--wait", "-I", cc.iptables.inputChain, "1", "--in-interface", bridgeName, "--source", ip.String(), "--jump", overridesChain, "-m", "comment", "--comment", handle)
+	return cc.iptables.run("create-overrides-chain", cmd)
+}
+
+// CreateConnectionLimits creates a chain dropping new connections from the
+// container beyond its limits. It is consulted for forwarded traffic from the
+// container ahead of the overrides and instance chains.
+func (cc *InstanceChainCreator) CreateConnectionLimits(logger lager.Logger, handle, instanceId, bridgeName string, ip net.IP, limits kawasaki.ConnectionLimits) error {
+	limitsChain := cc.iptables.InstanceChain(instanceId) + connectionLimitsChainSuffix
+
+	if err := cc.iptables.CreateChain("filter", limitsChain); err != nil {
+		return err
+	}
+
+	cmd := exec.Command(cc.iptables.iptablesBinPath, "--wait", "-A", limitsChain, "-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "--jump", "RETURN", "-m", "comment", "--comment", handle)
+	if err := cc.iptables.run("create-connection-limits-chain", cmd); err != nil {
+		return err
+	}
+
+	// The hashlimit table is named after the instance, which is short enough
+	// for the kernel's limit and unique to the container
+	if limits.NewConnectionsPerSecond > 0 {
+		rate := fmt.Sprintf("%d", limits.NewConnectionsPerSecond)
+		cmd = exec.Command(cc.iptables.iptablesBinPath, "--wait", "-A", limitsChain, "-m", "conntrack", "--ctstate", "NEW",
+			"-m", "hashlimit", "--hashlimit-above", rate+"/sec", "--hashlimit-burst", rate, "--hashlimit-mode", "srcip", "--hashlimit-name", instanceId,
+			"--jump", "DROP", "-m", "comment", "--comment", handle)
+		if err := cc.iptables.run("create-connection-limits-chain", cmd); err != nil {
+			return err
+		}
+	}
+
+	if limits.MaxConnections > 0 {
+		cmd = exec.Command(cc.iptables.iptablesBinPath, "--wait", "-A", limitsChain, "-m", "conntrack", "--ctstate", "NEW",
+			"-m", "connlimit", "--connlimit-above", fmt.Sprintf("%d", limits.MaxConnections), "--connlimit-mask", "32",
+			"--jump", "DROP", "-m", "comment", "--comment", handle)
+		if err := cc.iptables.run("create-connection-limits-chain", cmd); err != nil {
+			return err
+		}
+	}
+
+	// Bind connection limits chain to filter forward chain, ahead of the others
+	cmd = exec.Command(cc.iptables.iptablesBinPath, "--wait", "-I", cc.iptables.forwardChain, "2", "--in-interface", bridgeName, "--source", ip.String(), "--jump", limitsChain, "-m", "comment", "--comment", handle)
+	return cc.iptables.run("create-connection-limits-chain", cmd)
+}
+
+// Verify returns false if an instance's chains no longer exist, or are no
+// longer bound to the nat prerouting and filter forward and input chains, or
+// if the masquerade rules of its network are missing, e.g. because the host's
+// firewall was reloaded. The overrides and connection limits chains are only
+// expected if the container has overrides or connection limits.
+func (cc *InstanceChainCreator) Verify(logger lager.Logger, instanceId string, network *net.IPNet, overrides kawasaki.NetworkOverrides, limits kawasaki.ConnectionLimits) (bool, error) {
+	instanceChain := cc.iptables.InstanceChain(instanceId)
+	overridesChain := instanceChain + overridesChainSuffix
+	limitsChain := instanceChain + connectionLimitsChainSuffix
+
+	prerouting, err := cc.iptables.listRules("nat", cc.iptables.preroutingChain)
+	if err != nil {
+		return false, err
+	}
+
+	if !containsRule(prerouting, "-j "+instanceChain) {
+		return false, nil
+	}
+
+	postrouting, err := cc.iptables.listRules("nat", cc.iptables.postroutingChain)
+	if err != nil {
+		return false, err
+	}
+
+	// outbound and hairpin traffic of the network, as created
+	for _, match := range []string{
+		fmt.Sprintf("-s %s ! -d %s ", network, network),
+		fmt.Sprintf("-s %s -d %s -m conntrack --ctstate DNAT ", network, network),
+	} {
+		if !containsMasquerade(postrouting, match) {
+			return false, nil
+		}
+	}
+
+	forward, err := cc.iptables.listRules("filter", cc.iptables.forwardChain)
+	if err != nil {
+		return false, err
+	}
+
+	if !containsRule(forward, "-g "+instanceChain) {
+		return false, nil
+	}
+
+	chains := []string{instanceChain, cc.iptables.dnsChain(instanceId), fmt.Sprintf("%s-log", instanceChain)}
+
+	if !overrides.Empty() {
+		input, err := cc.iptables.listRules("filter", cc.iptables.inputChain)
+		if err != nil {
+			return false, err
+		}
+
+		if !containsRule(forward, "-j "+overridesChain) || !containsRule(input, "-j "+overridesChain) {
+			return false, nil
+		}
+
+		chains = append(chains, overridesChain)
+	}
+
+	if !limits.Empty() {
+		if !containsRule(forward, "-j "+limitsChain) {
+			return false, nil
+		}
+
+		chains = append(chains, limitsChain)
+	}
+
+	// a flushed chain still exists, but is missing at least its final rule
+	for _, chain := range chains {
+		rules, err := cc.iptables.listRules("filter", chain)
+		if err != nil || len(rules) == 0 {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func containsMasquerade(rules []string, match string) bool {
+	for _, rule := range rules {
+		if strings.Contains(rule, match) && strings.HasSuffix(rule, "-j MASQUERADE") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsRule(rules []string, target string) bool {
+	for _, rule := range rules {
+		if strings.Contains(rule, target+" ") || strings.HasSuffix(rule, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (cc *InstanceChainCreator) Destroy(logger lager.Logger, instanceId string) error {
 	instanceChain := cc.iptables.InstanceChain(instanceId)
 
@@ -125,12 +338,45 @@ func (cc *InstanceChainCreator) Destroy(logger lager.Logger, instanceId string)
 		return err
 	}
 
+	// Prune input and forward chains of the overrides chain, if any
+	overridesChain := instanceChain + overridesChainSuffix
+	for _, chain := range []string{cc.iptables.inputChain, cc.iptables.forwardChain} {
+		cmd = exec.Command("sh", "-c", fmt.Sprintf(
+			`%s --wait -S %s 2> /dev/null | grep "\-j %s\b" | sed -e "s/-A/-D/" | xargs --no-run-if-empty --max-lines=1 %s --wait`,
+			cc.iptables.iptablesBinPath, chain, overridesChain, cc.iptables.iptablesBinPath,
+		))
+		if err := cc.iptables.run("prune-overrides-chain", cmd); err != nil {
+			return err
+		}
+	}
+
+	// Prune forward chain of the connection limits chain, if any
+	limitsChain := instanceChain + connectionLimitsChainSuffix
+	cmd = exec.Command("sh", "-c", fmt.Sprintf(
+		`%s --wait -S %s 2> /dev/null | grep "\-j %s\b" | sed -e "s/-A/-D/" | xargs --no-run-if-empty --max-lines=1 %s --wait`,
+		cc.iptables.iptablesBinPath, cc.iptables.forwardChain, limitsChain, cc.iptables.iptablesBinPath,
+	))
+	if err := cc.iptables.run("prune-connection-limits-chain", cmd); err != nil {
+		return err
+	}
+
+	// Flush and delete the overrides and connection limits chains
+	cc.iptables.FlushChain("filter", overridesChain)
+	cc.iptables.DeleteChain("filter", overridesChain)
+	cc.iptables.FlushChain("filter", limitsChain)
+	cc.iptables.DeleteChain("filter", limitsChain)
+
 	// Flush instance chain
 	cc.iptables.FlushChain("filter", instanceChain)
 
 	// delete instance chain
 	cc.iptables.DeleteChain("filter", instanceChain)
 
+	// delete the DNS chain, once the instance chain no longer refers to it
+	instanceDNSChain := cc.iptables.dnsChain(instanceId)
+	cc.iptables.FlushChain("filter", instanceDNSChain)
+	cc.iptables.DeleteChain("filter", instanceDNSChain)
+
 	// delete the logging chain
 	instanceLoggingChain := fmt.Sprintf("%s-log", instanceChain)
 	cc.iptables.FlushChain("filter", instanceLoggingChain)
@@ -6,6 +6,7 @@ import (
 	"net"
 	"os/exec"
 
+	"code.cloudfoundry.org/guardian/kawasaki"
 	"code.cloudfoundry.org/guardian/kawasaki/iptables"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
@@ -34,7 +35,7 @@ var _ = Describe("Create", func() {
 		fakeRunner = fake_command_runner.New()
 		logger = lagertest.NewTestLogger("test")
 
-		handle = "some-handle-that-is-longer-than-29-characters-long"
+		handle = "some-handle-that-is-longer-than-63-characters-so-it-does-not-fit-in-a-log-prefix"
 		bridgeName = "some-bridge"
 		ip, network, err = net.ParseCIDR("1.2.3.4/28")
 		Expect(err).NotTo(HaveOccurred())
@@ -42,6 +43,7 @@ var _ = Describe("Create", func() {
 		fakeLocksmith := NewFakeLocksmith()
 		creator = iptables.NewInstanceChainCreator(
 			iptables.New("/sbin/iptables", "/sbin/iptables-restore", fakeRunner, fakeLocksmith, "prefix-"),
+			5,
 		)
 	})
 
@@ -68,6 +70,14 @@ var _ = Describe("Create", func() {
 						network.String(), network.String(), handle,
 					)},
 				},
+				{
+					Path: "sh",
+					Args: []string{"-c", fmt.Sprintf(
+						`(/sbin/iptables --wait --table nat -S %s | grep "\-j MASQUERADE\b" | grep -q -F -- "-s %s -d %s -m conntrack --ctstate DNAT") || /sbin/iptables --wait --table nat -A %s --source %s --destination %s -m conntrack --ctstate DNAT --jump MASQUERADE -m comment --comment %s`,
+						"prefix-postrouting", network.String(), network.String(), "prefix-postrouting",
+						network.String(), network.String(), handle,
+					)},
+				},
 				{
 					Path: "/sbin/iptables",
 					Args: []string{"--wait", "--table", "filter", "-N", "prefix-instance-some-id"},
@@ -81,11 +91,22 @@ var _ = Describe("Create", func() {
 				},
 				{
 					Path: "/sbin/iptables",
-					Args: []string{"--wait", "-A", "prefix-instance-some-id",
+					Args: []string{"--wait", "--table", "filter", "-N", "prefix-instance-some-id-dns"},
+				},
+				{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "-A", "prefix-instance-some-id-dns",
 						"--goto", "prefix-default",
 						"-m", "comment", "--comment", handle,
 					},
 				},
+				{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "-A", "prefix-instance-some-id",
+						"--goto", "prefix-instance-some-id-dns",
+						"-m", "comment", "--comment", handle,
+					},
+				},
 				{
 					Path: "/sbin/iptables",
 					Args: []string{"--wait", "-I", "prefix-forward", "2", "--in-interface", bridgeName,
@@ -100,7 +121,7 @@ var _ = Describe("Create", func() {
 				{
 					Path: "/sbin/iptables",
 					Args: []string{"--wait", "-A", "prefix-instance-some-id-log", "-m", "conntrack", "--ctstate", "NEW,UNTRACKED,INVALID",
-						"--protocol", "tcp", "--jump", "LOG", "--log-prefix", "some-handle-that-is-longer-th",
+						"--jump", "NFLOG", "--nflog-group", "5", "--nflog-prefix", "some-id",
 						"-m", "comment", "--comment", handle,
 					},
 				},
@@ -130,12 +151,389 @@ var _ = Describe("Create", func() {
 			Entry("create nat instance chain", 0, "iptables: create-instance-chains: iptables failed"),
 			Entry("bind nat instance chain to nat prerouting chain", 1, "iptables: create-instance-chains: iptables failed"),
 			Entry("enable NAT for traffic coming from containers", 2, "iptables: create-instance-chains: iptables failed"),
-			Entry("create logging instance chain", 7, "iptables: create-instance-chains: iptables failed"),
-			Entry("append logging to instance chain", 8, "iptables: create-instance-chains: iptables failed"),
-			Entry("return from logging instance chain", 9, "iptables: create-instance-chains: iptables failed"),
+			Entry("enable NAT for hairpin traffic", 3, "iptables: create-instance-chains: iptables failed"),
+			Entry("create DNS instance chain", 6, "iptables: create-instance-chains: iptables failed"),
+			Entry("fall back to the default chain from the DNS chain", 7, "iptables: create-instance-chains: iptables failed"),
+			Entry("use the DNS chain from the instance chain", 8, "iptables: create-instance-chains: iptables failed"),
+			Entry("create logging instance chain", 10, "iptables: create-instance-chains: iptables failed"),
+			Entry("append logging to instance chain", 11, "iptables: create-instance-chains: iptables failed"),
+			Entry("return from logging instance chain", 12, "iptables: create-instance-chains: iptables failed"),
+		)
+	})
+
+	Describe("Overrides Creation", func() {
+		var (
+			overrides kawasaki.NetworkOverrides
+			specs     []fake_command_runner.CommandSpec
+		)
+
+		BeforeEach(func() {
+			_, deniedNetwork, err := net.ParseCIDR("10.0.0.0/8")
+			Expect(err).NotTo(HaveOccurred())
+
+			overrides = kawasaki.NetworkOverrides{
+				AllowHostAccess: true,
+				DenyNetworks:    []*net.IPNet{deniedNetwork},
+			}
+
+			specs = []fake_command_runner.CommandSpec{
+				{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "--table", "filter", "-N", "prefix-instance-some-id-ovr"},
+				},
+				{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "-A", "prefix-instance-some-id-ovr",
+						"-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "--jump", "RETURN",
+						"-m", "comment", "--comment", handle,
+					},
+				},
+				{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "-A", "prefix-instance-some-id-ovr",
+						"--destination", "10.0.0.0/8", "--jump", "REJECT",
+						"-m", "comment", "--comment", handle,
+					},
+				},
+				{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "-A", "prefix-instance-some-id-ovr",
+						"-m", "addrtype", "--dst-type", "LOCAL", "--jump", "ACCEPT",
+						"-m", "comment", "--comment", handle,
+					},
+				},
+				{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "-I", "prefix-forward", "2", "--in-interface", bridgeName,
+						"--source", ip.String(), "--jump", "prefix-instance-some-id-ovr",
+						"-m", "comment", "--comment", handle,
+					},
+				},
+				{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "-I", "prefix-input", "1", "--in-interface", bridgeName,
+						"--source", ip.String(), "--jump", "prefix-instance-some-id-ovr",
+						"-m", "comment", "--comment", handle,
+					},
+				},
+			}
+		})
+
+		It("should set up the overrides chain", func() {
+			Expect(creator.CreateOverrides(logger, handle, "some-id", bridgeName, ip, overrides)).To(Succeed())
+			Expect(fakeRunner).To(HaveExecutedSerially(specs...))
+		})
+
+		Context("when host access is not allowed", func() {
+			BeforeEach(func() {
+				overrides.AllowHostAccess = false
+			})
+
+			It("does not accept traffic to the host", func() {
+				Expect(creator.CreateOverrides(logger, handle, "some-id", bridgeName, ip, overrides)).To(Succeed())
+				Expect(fakeRunner).NotTo(HaveExecutedSerially(specs[3]))
+				Expect(fakeRunner).To(HaveExecutedSerially(specs[4], specs[5]))
+			})
+		})
+
+		DescribeTable("iptables failures",
+			func(specIndex int) {
+				fakeRunner.WhenRunning(specs[specIndex], func(cmd *exec.Cmd) error {
+					cmd.Stderr.Write([]byte("iptables failed"))
+					return errors.New("Exit status blah")
+				})
+
+				Expect(creator.CreateOverrides(logger, handle, "some-id", bridgeName, ip, overrides)).To(MatchError(ContainSubstring("iptables failed")))
+			},
+			Entry("create overrides chain", 0),
+			Entry("return established connections", 1),
+			Entry("reject denied networks", 2),
+			Entry("accept host traffic", 3),
+			Entry("bind overrides chain to forward chain", 4),
+			Entry("bind overrides chain to input chain", 5),
 		)
 	})
 
+	Describe("Connection Limits Creation", func() {
+		var (
+			limits kawasaki.ConnectionLimits
+			specs  []fake_command_runner.CommandSpec
+		)
+
+		BeforeEach(func() {
+			limits = kawasaki.ConnectionLimits{
+				NewConnectionsPerSecond: 50,
+				MaxConnections:          200,
+			}
+
+			specs = []fake_command_runner.CommandSpec{
+				{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "--table", "filter", "-N", "prefix-instance-some-id-lim"},
+				},
+				{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "-A", "prefix-instance-some-id-lim",
+						"-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "--jump", "RETURN",
+						"-m", "comment", "--comment", handle,
+					},
+				},
+				{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "-A", "prefix-instance-some-id-lim",
+						"-m", "conntrack", "--ctstate", "NEW",
+						"-m", "hashlimit", "--hashlimit-above", "50/sec", "--hashlimit-burst", "50", "--hashlimit-mode", "srcip", "--hashlimit-name", "some-id",
+						"--jump", "DROP", "-m", "comment", "--comment", handle,
+					},
+				},
+				{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "-A", "prefix-instance-some-id-lim",
+						"-m", "conntrack", "--ctstate", "NEW",
+						"-m", "connlimit", "--connlimit-above", "200", "--connlimit-mask", "32",
+						"--jump", "DROP", "-m", "comment", "--comment", handle,
+					},
+				},
+				{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "-I", "prefix-forward", "2", "--in-interface", bridgeName,
+						"--source", ip.String(), "--jump", "prefix-instance-some-id-lim",
+						"-m", "comment", "--comment", handle,
+					},
+				},
+			}
+		})
+
+		It("should set up the connection limits chain", func() {
+			Expect(creator.CreateConnectionLimits(logger, handle, "some-id", bridgeName, ip, limits)).To(Succeed())
+			Expect(fakeRunner).To(HaveExecutedSerially(specs...))
+		})
+
+		Context("when only the number of connections is limited", func() {
+			BeforeEach(func() {
+				limits.NewConnectionsPerSecond = 0
+			})
+
+			It("does not limit the rate of new connections", func() {
+				Expect(creator.CreateConnectionLimits(logger, handle, "some-id", bridgeName, ip, limits)).To(Succeed())
+				Expect(fakeRunner).NotTo(HaveExecutedSerially(specs[2]))
+				Expect(fakeRunner).To(HaveExecutedSerially(specs[3], specs[4]))
+			})
+		})
+
+		Context("when only the rate of new connections is limited", func() {
+			BeforeEach(func() {
+				limits.MaxConnections = 0
+			})
+
+			It("does not limit the number of connections", func() {
+				Expect(creator.CreateConnectionLimits(logger, handle, "some-id", bridgeName, ip, limits)).To(Succeed())
+				Expect(fakeRunner).NotTo(HaveExecutedSerially(specs[3]))
+				Expect(fakeRunner).To(HaveExecutedSerially(specs[2], specs[4]))
+			})
+		})
+
+		DescribeTable("iptables failures",
+			func(specIndex int) {
+				fakeRunner.WhenRunning(specs[specIndex], func(cmd *exec.Cmd) error {
+					cmd.Stderr.Write([]byte("iptables failed"))
+					return errors.New("Exit status blah")
+				})
+
+				Expect(creator.CreateConnectionLimits(logger, handle, "some-id", bridgeName, ip, limits)).To(MatchError(ContainSubstring("iptables failed")))
+			},
+			Entry("create connection limits chain", 0),
+			Entry("return established connections", 1),
+			Entry("limit the rate of new connections", 2),
+			Entry("limit the number of connections", 3),
+			Entry("bind connection limits chain to forward chain", 4),
+		)
+	})
+
+	Describe("Verification", func() {
+		var (
+			chains    map[string]string
+			overrides kawasaki.NetworkOverrides
+			limits    kawasaki.ConnectionLimits
+		)
+
+		verify := func(instanceId string) (bool, error) {
+			return creator.Verify(logger, instanceId, network, overrides, limits)
+		}
+
+		BeforeEach(func() {
+			overrides = kawasaki.NetworkOverrides{}
+			limits = kawasaki.ConnectionLimits{}
+			chains = map[string]string{
+				"prefix-prerouting": "-N prefix-prerouting\n-A prefix-prerouting -j prefix-instance-some-id -m comment --comment some-handle\n",
+				"prefix-postrouting": "-N prefix-postrouting\n" +
+					"-A prefix-postrouting -s 1.2.3.0/28 ! -d 1.2.3.0/28 -m comment --comment some-handle -j MASQUERADE\n" +
+					"-A prefix-postrouting -s 1.2.3.0/28 -d 1.2.3.0/28 -m conntrack --ctstate DNAT -m comment --comment some-handle -j MASQUERADE\n",
+				"prefix-forward": "-N prefix-forward\n" +
+					"-A prefix-forward -i some-bridge -s 1.2.3.4/32 -m comment --comment some-handle -g prefix-instance-some-id\n" +
+					"-A prefix-forward -j prefix-default\n",
+				"prefix-instance-some-id":     "-N prefix-instance-some-id\n-A prefix-instance-some-id -m comment --comment some-handle -g prefix-instance-some-id-dns\n",
+				"prefix-instance-some-id-dns": "-N prefix-instance-some-id-dns\n-A prefix-instance-some-id-dns -m comment --comment some-handle -g prefix-default\n",
+				"prefix-instance-some-id-log": "-N prefix-instance-some-id-log\n-A prefix-instance-some-id-log -m comment --comment some-handle -j RETURN\n",
+			}
+		})
+
+		JustBeforeEach(func() {
+			for chain := range chains {
+				table := "filter"
+				if chain == "prefix-prerouting" || chain == "prefix-postrouting" {
+					table = "nat"
+				}
+
+				chain := chain
+				fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "--table", table, "-S", chain},
+				}, func(cmd *exec.Cmd) error {
+					cmd.Stdout.Write([]byte(chains[chain]))
+					return nil
+				})
+			}
+		})
+
+		It("returns true when the instance chains exist and are bound", func() {
+			Expect(verify("some-id")).To(BeTrue())
+		})
+
+		It("does not mistake another instance's chains for the instance's", func() {
+			Expect(verify("some-i")).To(BeFalse())
+		})
+
+		Context("when the nat instance chain is no longer bound", func() {
+			BeforeEach(func() {
+				chains["prefix-prerouting"] = "-N prefix-prerouting\n"
+			})
+
+			It("returns false", func() {
+				Expect(verify("some-id")).To(BeFalse())
+			})
+		})
+
+		Context("when the filter instance chain is no longer bound", func() {
+			BeforeEach(func() {
+				chains["prefix-forward"] = "-N prefix-forward\n-A prefix-forward -j prefix-default\n"
+			})
+
+			It("returns false", func() {
+				Expect(verify("some-id")).To(BeFalse())
+			})
+		})
+
+		DescribeTable("when a masquerade rule of the network is missing",
+			func(rule string) {
+				chains["prefix-postrouting"] = "-N prefix-postrouting\n" + rule
+				Expect(verify("some-id")).To(BeFalse())
+			},
+			Entry("outbound", "-A prefix-postrouting -s 1.2.3.0/28 -d 1.2.3.0/28 -m conntrack --ctstate DNAT -m comment --comment some-handle -j MASQUERADE\n"),
+			Entry("hairpin", "-A prefix-postrouting -s 1.2.3.0/28 ! -d 1.2.3.0/28 -m comment --comment some-handle -j MASQUERADE\n"),
+		)
+
+		DescribeTable("when an instance chain has been flushed",
+			func(chain string) {
+				chains[chain] = fmt.Sprintf("-N %s\n", chain)
+				Expect(verify("some-id")).To(BeFalse())
+			},
+			Entry("instance chain", "prefix-instance-some-id"),
+			Entry("DNS chain", "prefix-instance-some-id-dns"),
+			Entry("logging chain", "prefix-instance-some-id-log"),
+		)
+
+		Context("when an instance chain has been deleted", func() {
+			BeforeEach(func() {
+				delete(chains, "prefix-instance-some-id-log")
+				fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "--table", "filter", "-S", "prefix-instance-some-id-log"},
+				}, func(cmd *exec.Cmd) error {
+					cmd.Stderr.Write([]byte("No chain/target/match by that name."))
+					return errors.New("exit status 1")
+				})
+			})
+
+			It("returns false", func() {
+				Expect(verify("some-id")).To(BeFalse())
+			})
+		})
+
+		Context("when the container has overrides", func() {
+			BeforeEach(func() {
+				overrides = kawasaki.NetworkOverrides{AllowHostAccess: true}
+				chains["prefix-forward"] = "-N prefix-forward\n" +
+					"-A prefix-forward -i some-bridge -s 1.2.3.4/32 -m comment --comment some-handle -j prefix-instance-some-id-ovr\n" +
+					"-A prefix-forward -i some-bridge -s 1.2.3.4/32 -m comment --comment some-handle -g prefix-instance-some-id\n" +
+					"-A prefix-forward -j prefix-default\n"
+				chains["prefix-input"] = "-N prefix-input\n" +
+					"-A prefix-input -i some-bridge -s 1.2.3.4/32 -m comment --comment some-handle -j prefix-instance-some-id-ovr\n"
+				chains["prefix-instance-some-id-ovr"] = "-N prefix-instance-some-id-ovr\n" +
+					"-A prefix-instance-some-id-ovr -m conntrack --ctstate RELATED,ESTABLISHED -m comment --comment some-handle -j RETURN\n"
+			})
+
+			It("returns true when the overrides chain exists and is bound", func() {
+				Expect(verify("some-id")).To(BeTrue())
+			})
+
+			It("returns false when the overrides chain has been flushed", func() {
+				chains["prefix-instance-some-id-ovr"] = "-N prefix-instance-some-id-ovr\n"
+				Expect(verify("some-id")).To(BeFalse())
+			})
+
+			It("returns false when the overrides chain is no longer bound to the input chain", func() {
+				chains["prefix-input"] = "-N prefix-input\n"
+				Expect(verify("some-id")).To(BeFalse())
+			})
+		})
+
+		Context("when the container has connection limits", func() {
+			BeforeEach(func() {
+				limits = kawasaki.ConnectionLimits{MaxConnections: 10}
+				chains["prefix-forward"] = "-N prefix-forward\n" +
+					"-A prefix-forward -i some-bridge -s 1.2.3.4/32 -m comment --comment some-handle -j prefix-instance-some-id-lim\n" +
+					"-A prefix-forward -i some-bridge -s 1.2.3.4/32 -m comment --comment some-handle -g prefix-instance-some-id\n" +
+					"-A prefix-forward -j prefix-default\n"
+				chains["prefix-instance-some-id-lim"] = "-N prefix-instance-some-id-lim\n" +
+					"-A prefix-instance-some-id-lim -m conntrack --ctstate RELATED,ESTABLISHED -m comment --comment some-handle -j RETURN\n"
+			})
+
+			It("returns true when the connection limits chain exists and is bound", func() {
+				Expect(verify("some-id")).To(BeTrue())
+			})
+
+			It("returns false when the connection limits chain has been flushed", func() {
+				chains["prefix-instance-some-id-lim"] = "-N prefix-instance-some-id-lim\n"
+				Expect(verify("some-id")).To(BeFalse())
+			})
+
+			It("returns false when the connection limits chain is no longer bound", func() {
+				chains["prefix-forward"] = "-N prefix-forward\n" +
+					"-A prefix-forward -i some-bridge -s 1.2.3.4/32 -m comment --comment some-handle -g prefix-instance-some-id\n"
+				Expect(verify("some-id")).To(BeFalse())
+			})
+		})
+
+		Context("when the prerouting chain cannot be listed", func() {
+			BeforeEach(func() {
+				delete(chains, "prefix-prerouting")
+				fakeRunner.WhenRunning(fake_command_runner.CommandSpec{
+					Path: "/sbin/iptables",
+					Args: []string{"--wait", "--table", "nat", "-S", "prefix-prerouting"},
+				}, func(cmd *exec.Cmd) error {
+					cmd.Stderr.Write([]byte("No chain/target/match by that name."))
+					return errors.New("exit status 1")
+				})
+			})
+
+			It("returns an error", func() {
+				_, err := verify("some-id")
+				Expect(err).To(MatchError("iptables: list-rules: No chain/target/match by that name."))
+			})
+		})
+	})
+
 	Describe("ContainerTeardown", func() {
 		var specs []fake_command_runner.CommandSpec
 
@@ -169,6 +567,43 @@ var _ = Describe("Create", func() {
 						"prefix-forward", "prefix-instance-some-id",
 					)},
 				},
+				{
+					Path: "sh",
+					Args: []string{"-c", fmt.Sprintf(
+						`/sbin/iptables --wait -S %s 2> /dev/null | grep "\-j %s\b" | sed -e "s/-A/-D/" | xargs --no-run-if-empty --max-lines=1 /sbin/iptables --wait`,
+						"prefix-input", "prefix-instance-some-id-ovr",
+					)},
+				},
+				{
+					Path: "sh",
+					Args: []string{"-c", fmt.Sprintf(
+						`/sbin/iptables --wait -S %s 2> /dev/null | grep "\-j %s\b" | sed -e "s/-A/-D/" | xargs --no-run-if-empty --max-lines=1 /sbin/iptables --wait`,
+						"prefix-forward", "prefix-instance-some-id-ovr",
+					)},
+				},
+				{
+					Path: "sh",
+					Args: []string{"-c", fmt.Sprintf(
+						`/sbin/iptables --wait -S %s 2> /dev/null | grep "\-j %s\b" | sed -e "s/-A/-D/" | xargs --no-run-if-empty --max-lines=1 /sbin/iptables --wait`,
+						"prefix-forward", "prefix-instance-some-id-lim",
+					)},
+				},
+				{
+					Path: "sh",
+					Args: []string{"-c", fmt.Sprintf("/sbin/iptables --wait --table filter -F %s 2> /dev/null || true", "prefix-instance-some-id-ovr")},
+				},
+				{
+					Path: "sh",
+					Args: []string{"-c", fmt.Sprintf("/sbin/iptables --wait --table filter -X %s 2> /dev/null || true", "prefix-instance-some-id-ovr")},
+				},
+				{
+					Path: "sh",
+					Args: []string{"-c", fmt.Sprintf("/sbin/iptables --wait --table filter -F %s 2> /dev/null || true", "prefix-instance-some-id-lim")},
+				},
+				{
+					Path: "sh",
+					Args: []string{"-c", fmt.Sprintf("/sbin/iptables --wait --table filter -X %s 2> /dev/null || true", "prefix-instance-some-id-lim")},
+				},
 				{
 					Path: "sh",
 					Args: []string{"-c", fmt.Sprintf("/sbin/iptables --wait --table filter -F %s 2> /dev/null || true", "prefix-instance-some-id")},
@@ -177,6 +612,14 @@ var _ = Describe("Create", func() {
 					Path: "sh",
 					Args: []string{"-c", fmt.Sprintf("/sbin/iptables --wait --table filter -X %s 2> /dev/null || true", "prefix-instance-some-id")},
 				},
+				{
+					Path: "sh",
+					Args: []string{"-c", fmt.Sprintf("/sbin/iptables --wait --table filter -F %s 2> /dev/null || true", "prefix-instance-some-id-dns")},
+				},
+				{
+					Path: "sh",
+					Args: []string{"-c", fmt.Sprintf("/sbin/iptables --wait --table filter -X %s 2> /dev/null || true", "prefix-instance-some-id-dns")},
+				},
 				{
 					Path: "sh",
 					Args: []string{"-c", fmt.Sprintf("/sbin/iptables --wait --table filter -F %s 2> /dev/null || true", "prefix-instance-some-id-log")},
@@ -30,6 +30,7 @@ type IPTables interface {
 	DeleteChainReferences(table, targetChain, referencedChain string) error
 	PrependRule(chain string, rule Rule) error
 	BulkPrependRules(chain string, rules []Rule) error
+	ReplaceDNSRules(instanceId, handle string, rules []Rule) error
 	InstanceChain(instanceId string) string
 }
 
@@ -113,15 +114,61 @@ func (iptables *IPTablesController) BulkPrependRules(chain string, rules []Rule)
 	return iptables.run("bulk-prepend-rules", cmd)
 }
 
+// ReplaceDNSRules atomically replaces the rules resolved from an instance's
+// hostname NetOut rules, which are evaluated after its other NetOut rules
+func (iptables *IPTablesController) ReplaceDNSRules(instanceId, handle string, rules []Rule) error {
+	instanceChain := iptables.InstanceChain(instanceId)
+	dnsChain := iptables.dnsChain(instanceId)
+
+	in := bytes.NewBuffer([]byte{})
+	in.WriteString("*filter\n")
+	// declaring the chain flushes it within the same transaction
+	in.WriteString(fmt.Sprintf(":%s - [0:0]\n", dnsChain))
+	for _, r := range rules {
+		in.WriteString(fmt.Sprintf("-A %s ", dnsChain))
+		// the rules act on behalf of the instance chain, e.g. when logging
+		in.WriteString(strings.Join(r.Flags(instanceChain), " "))
+		in.WriteString("\n")
+	}
+	in.WriteString(fmt.Sprintf("-A %s --goto %s -m comment --comment %s\n", dnsChain, iptables.defaultChain, handle))
+	in.WriteString("COMMIT\n")
+
+	cmd := exec.Command(iptables.iptablesRestoreBinPath, "--noflush")
+	cmd.Stdin = in
+
+	return iptables.run("replace-dns-rules", cmd)
+}
+
 func (iptables *IPTablesController) InstanceChain(instanceId string) string {
 	return iptables.instanceChainPrefix + instanceId
 }
 
-func (iptables *IPTablesController) run(action string, cmd *exec.Cmd) (err error) {
+func (iptables *IPTablesController) dnsChain(instanceId string) string {
+	return iptables.InstanceChain(instanceId) + "-dns"
+}
+
+func (iptables *IPTablesController) run(action string, cmd *exec.Cmd) error {
 	var buff bytes.Buffer
 	cmd.Stdout = &buff
 	cmd.Stderr = &buff
 
+	return iptables.runLocked(action, cmd, &buff)
+}
+
+// output runs the command and returns what it writes to stdout
+func (iptables *IPTablesController) output(action string, cmd *exec.Cmd) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := iptables.runLocked(action, cmd, &stderr); err != nil {
+		return nil, err
+	}
+
+	return stdout.Bytes(), nil
+}
+
+func (iptables *IPTablesController) runLocked(action string, cmd *exec.Cmd, errOutput *bytes.Buffer) (err error) {
 	u, err := iptables.locksmith.Lock(LockKey)
 	if err != nil {
 		return err
@@ -138,12 +185,42 @@ func (iptables *IPTablesController) run(action string, cmd *exec.Cmd) (err error
 	}()
 
 	if err := iptables.runner.Run(cmd); err != nil {
-		return fmt.Errorf("iptables: %s: %s", action, buff.String())
+		return fmt.Errorf("iptables: %s: %s", action, errOutput.String())
 	}
 
 	return nil
 }
 
+// listRules returns the rules of a chain, in the form printed by iptables -S
+func (iptables *IPTablesController) listRules(table, chain string) ([]string, error) {
+	out, err := iptables.output("list-rules", exec.Command(iptables.iptablesBinPath, "--wait", "--table", table, "-S", chain))
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "-A ") {
+			rules = append(rules, line)
+		}
+	}
+
+	return rules, nil
+}
+
+// chainExists distinguishes a missing chain from a failure to list it
+func (iptables *IPTablesController) chainExists(table, chain string) (bool, error) {
+	if _, err := iptables.listRules(table, chain); err != nil {
+		if strings.Contains(err.Error(), "No chain/target/match by that name") {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
 func (iptables *IPTablesController) appendRule(chain string, rule Rule) error {
 	return iptables.run("append-rule", exec.Command(iptables.iptablesBinPath, append([]string{"-w", "-A", chain}, rule.Flags(chain)...)...))
 }
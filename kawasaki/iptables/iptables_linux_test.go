@@ -142,6 +142,43 @@ var _ = Describe("IPTables controller", func() {
 		})
 	})
 
+	Describe("ReplaceDNSRules", func() {
+		var dnsChain string
+
+		BeforeEach(func() {
+			dnsChain = prefix + "instance-some-id-dns"
+
+			fakeRule := new(fakes.FakeRule)
+			fakeRule.FlagsReturns([]string{"--protocol", "udp"})
+
+			Expect(iptablesController.CreateChain("filter", prefix+"default")).To(Succeed())
+			Expect(iptablesController.CreateChain("filter", dnsChain)).To(Succeed())
+			Expect(iptablesController.ReplaceDNSRules("some-id", "some-handle", []iptables.Rule{fakeRule})).To(Succeed())
+		})
+
+		It("replaces the rules in the DNS chain, falling back to the default chain", func() {
+			fakeRule := new(fakes.FakeRule)
+			fakeRule.FlagsReturns([]string{"--protocol", "tcp"})
+
+			Expect(iptablesController.ReplaceDNSRules("some-id", "some-handle", []iptables.Rule{fakeRule})).To(Succeed())
+
+			buff := gbytes.NewBuffer()
+			sess, err := gexec.Start(wrapCmdInNs(netnsName, exec.Command("iptables", "-S", dnsChain)), buff, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(sess).Should(gexec.Exit(0))
+			Expect(buff).To(gbytes.Say(fmt.Sprintf("-A %s -p tcp\n-A %s -m comment --comment some-handle -g %sdefault", dnsChain, dnsChain, prefix)))
+			Expect(string(buff.Contents())).NotTo(ContainSubstring("-p udp"))
+		})
+
+		It("builds the rules' flags for the instance chain", func() {
+			fakeRule := new(fakes.FakeRule)
+			fakeRule.FlagsReturns([]string{})
+
+			Expect(iptablesController.ReplaceDNSRules("some-id", "some-handle", []iptables.Rule{fakeRule})).To(Succeed())
+			Expect(fakeRule.FlagsArgsForCall(0)).To(Equal(prefix + "instance-some-id"))
+		})
+	})
+
 	Describe("DeleteChain", func() {
 		BeforeEach(func() {
 			Expect(iptablesController.CreateChain("filter", "test-chain")).To(Succeed())
@@ -8,7 +8,7 @@ import (
 )
 
 type FakeIPTables struct {
-	CreateChainStub        func(table, chain string) error
+	CreateChainStub        func(table string, chain string) error
 	createChainMutex       sync.RWMutex
 	createChainArgsForCall []struct {
 		table string
@@ -20,7 +20,7 @@ type FakeIPTables struct {
 	createChainReturnsOnCall map[int]struct {
 		result1 error
 	}
-	DeleteChainStub        func(table, chain string) error
+	DeleteChainStub        func(table string, chain string) error
 	deleteChainMutex       sync.RWMutex
 	deleteChainArgsForCall []struct {
 		table string
@@ -32,7 +32,7 @@ type FakeIPTables struct {
 	deleteChainReturnsOnCall map[int]struct {
 		result1 error
 	}
-	FlushChainStub        func(table, chain string) error
+	FlushChainStub        func(table string, chain string) error
 	flushChainMutex       sync.RWMutex
 	flushChainArgsForCall []struct {
 		table string
@@ -44,7 +44,7 @@ type FakeIPTables struct {
 	flushChainReturnsOnCall map[int]struct {
 		result1 error
 	}
-	DeleteChainReferencesStub        func(table, targetChain, referencedChain string) error
+	DeleteChainReferencesStub        func(table string, targetChain string, referencedChain string) error
 	deleteChainReferencesMutex       sync.RWMutex
 	deleteChainReferencesArgsForCall []struct {
 		table           string
@@ -81,6 +81,19 @@ type FakeIPTables struct {
 	bulkPrependRulesReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ReplaceDNSRulesStub        func(instanceId string, handle string, rules []iptables.Rule) error
+	replaceDNSRulesMutex       sync.RWMutex
+	replaceDNSRulesArgsForCall []struct {
+		instanceId string
+		handle     string
+		rules      []iptables.Rule
+	}
+	replaceDNSRulesReturns struct {
+		result1 error
+	}
+	replaceDNSRulesReturnsOnCall map[int]struct {
+		result1 error
+	}
 	InstanceChainStub        func(instanceId string) string
 	instanceChainMutex       sync.RWMutex
 	instanceChainArgsForCall []struct {
@@ -396,6 +409,61 @@ func (fake *FakeIPTables) BulkPrependRulesReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIPTables) ReplaceDNSRules(instanceId string, handle string, rules []iptables.Rule) error {
+	var rulesCopy []iptables.Rule
+	if rules != nil {
+		rulesCopy = make([]iptables.Rule, len(rules))
+		copy(rulesCopy, rules)
+	}
+	fake.replaceDNSRulesMutex.Lock()
+	ret, specificReturn := fake.replaceDNSRulesReturnsOnCall[len(fake.replaceDNSRulesArgsForCall)]
+	fake.replaceDNSRulesArgsForCall = append(fake.replaceDNSRulesArgsForCall, struct {
+		instanceId string
+		handle     string
+		rules      []iptables.Rule
+	}{instanceId, handle, rulesCopy})
+	fake.recordInvocation("ReplaceDNSRules", []interface{}{instanceId, handle, rulesCopy})
+	fake.replaceDNSRulesMutex.Unlock()
+	if fake.ReplaceDNSRulesStub != nil {
+		return fake.ReplaceDNSRulesStub(instanceId, handle, rules)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.replaceDNSRulesReturns.result1
+}
+
+func (fake *FakeIPTables) ReplaceDNSRulesCallCount() int {
+	fake.replaceDNSRulesMutex.RLock()
+	defer fake.replaceDNSRulesMutex.RUnlock()
+	return len(fake.replaceDNSRulesArgsForCall)
+}
+
+func (fake *FakeIPTables) ReplaceDNSRulesArgsForCall(i int) (string, string, []iptables.Rule) {
+	fake.replaceDNSRulesMutex.RLock()
+	defer fake.replaceDNSRulesMutex.RUnlock()
+	return fake.replaceDNSRulesArgsForCall[i].instanceId, fake.replaceDNSRulesArgsForCall[i].handle, fake.replaceDNSRulesArgsForCall[i].rules
+}
+
+func (fake *FakeIPTables) ReplaceDNSRulesReturns(result1 error) {
+	fake.ReplaceDNSRulesStub = nil
+	fake.replaceDNSRulesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIPTables) ReplaceDNSRulesReturnsOnCall(i int, result1 error) {
+	fake.ReplaceDNSRulesStub = nil
+	if fake.replaceDNSRulesReturnsOnCall == nil {
+		fake.replaceDNSRulesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.replaceDNSRulesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIPTables) InstanceChain(instanceId string) string {
 	fake.instanceChainMutex.Lock()
 	ret, specificReturn := fake.instanceChainReturnsOnCall[len(fake.instanceChainArgsForCall)]
@@ -459,6 +527,8 @@ func (fake *FakeIPTables) Invocations() map[string][][]interface{} {
 	defer fake.prependRuleMutex.RUnlock()
 	fake.bulkPrependRulesMutex.RLock()
 	defer fake.bulkPrependRulesMutex.RUnlock()
+	fake.replaceDNSRulesMutex.RLock()
+	defer fake.replaceDNSRulesMutex.RUnlock()
 	fake.instanceChainMutex.RLock()
 	defer fake.instanceChainMutex.RUnlock()
 	return fake.invocations
@@ -1,9 +1,12 @@
 package iptables
 
 import (
+	"bytes"
 	"fmt"
+	"net"
 
 	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/kawasaki"
 )
 
 type iptablesFlags []string
@@ -24,6 +27,13 @@ func natRule(destination string, destinationPort uint32, containerIP string, con
 	})
 }
 
+func acceptRule(destination string) Rule {
+	return iptablesFlags([]string{
+		"--destination", destination,
+		"--jump", "ACCEPT",
+	})
+}
+
 func rejectRule(destination string) Rule {
 	return iptablesFlags([]string{
 		"--destination", destination,
@@ -31,6 +41,17 @@ func rejectRule(destination string) Rule {
 	})
 }
 
+// netInAcceptRule accepts new connections to the mapped ports of containers
+// made through one of the server's external IPs, as from outside the host
+func netInAcceptRule(externalIP string) Rule {
+	return iptablesFlags([]string{
+		"-m", "conntrack",
+		"--ctstate", "DNAT",
+		"--ctorigdst", externalIP,
+		"--jump", "ACCEPT",
+	})
+}
+
 type SingleFilterRule struct {
 	Protocol garden.Protocol
 	Networks *garden.IPRange
@@ -82,3 +103,52 @@ func (r SingleFilterRule) Flags(chain string) (params []string) {
 
 	return params
 }
+
+// Matches reports whether traffic meets the rule's protocol, network, port and
+// ICMP criteria
+func (r SingleFilterRule) Matches(traffic kawasaki.Traffic) bool {
+	if r.Protocol != garden.ProtocolAll && r.Protocol != traffic.Protocol {
+		return false
+	}
+
+	if r.Networks != nil && !inIPRange(*r.Networks, traffic.Destination) {
+		return false
+	}
+
+	if r.Ports != nil {
+		if !allowsPort(traffic.Protocol) || traffic.Port < uint32(r.Ports.Start) || traffic.Port > uint32(r.Ports.End) {
+			return false
+		}
+	}
+
+	if r.ICMPs != nil {
+		// ICMP traffic is taken to be an echo request
+		if traffic.Protocol != garden.ProtocolICMP || r.ICMPs.Type != 8 || (r.ICMPs.Code != nil && *r.ICMPs.Code != 0) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func inIPRange(ipRange garden.IPRange, ip net.IP) bool {
+	ip = ip.To16()
+	if ip == nil {
+		return false
+	}
+
+	// a range with only one end is a single address, as in Flags
+	start, end := ipRange.Start, ipRange.End
+	if start == nil {
+		start = end
+	}
+	if end == nil {
+		end = start
+	}
+
+	if start == nil {
+		return true
+	}
+
+	return bytes.Compare(ip, start.To16()) >= 0 && bytes.Compare(ip, end.To16()) <= 0
+}
@@ -22,6 +22,22 @@ type FakeConfigurer struct {
 	applyReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RepairStub        func(log lager.Logger, cfg kawasaki.NetworkConfig, pid int, recreateChains bool) (kawasaki.Repairs, error)
+	repairMutex       sync.RWMutex
+	repairArgsForCall []struct {
+		log            lager.Logger
+		cfg            kawasaki.NetworkConfig
+		pid            int
+		recreateChains bool
+	}
+	repairReturns struct {
+		result1 kawasaki.Repairs
+		result2 error
+	}
+	repairReturnsOnCall map[int]struct {
+		result1 kawasaki.Repairs
+		result2 error
+	}
 	DestroyBridgeStub        func(log lager.Logger, cfg kawasaki.NetworkConfig) error
 	destroyBridgeMutex       sync.RWMutex
 	destroyBridgeArgsForCall []struct {
@@ -46,6 +62,19 @@ type FakeConfigurer struct {
 	destroyIPTablesRulesReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ConfigureDNSStub        func(log lager.Logger, cfg kawasaki.NetworkConfig, pid int) error
+	configureDNSMutex       sync.RWMutex
+	configureDNSArgsForCall []struct {
+		log lager.Logger
+		cfg kawasaki.NetworkConfig
+		pid int
+	}
+	configureDNSReturns struct {
+		result1 error
+	}
+	configureDNSReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -100,6 +129,60 @@ func (fake *FakeConfigurer) ApplyReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeConfigurer) Repair(log lager.Logger, cfg kawasaki.NetworkConfig, pid int, recreateChains bool) (kawasaki.Repairs, error) {
+	fake.repairMutex.Lock()
+	ret, specificReturn := fake.repairReturnsOnCall[len(fake.repairArgsForCall)]
+	fake.repairArgsForCall = append(fake.repairArgsForCall, struct {
+		log            lager.Logger
+		cfg            kawasaki.NetworkConfig
+		pid            int
+		recreateChains bool
+	}{log, cfg, pid, recreateChains})
+	fake.recordInvocation("Repair", []interface{}{log, cfg, pid, recreateChains})
+	fake.repairMutex.Unlock()
+	if fake.RepairStub != nil {
+		return fake.RepairStub(log, cfg, pid, recreateChains)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.repairReturns.result1, fake.repairReturns.result2
+}
+
+func (fake *FakeConfigurer) RepairCallCount() int {
+	fake.repairMutex.RLock()
+	defer fake.repairMutex.RUnlock()
+	return len(fake.repairArgsForCall)
+}
+
+func (fake *FakeConfigurer) RepairArgsForCall(i int) (lager.Logger, kawasaki.NetworkConfig, int, bool) {
+	fake.repairMutex.RLock()
+	defer fake.repairMutex.RUnlock()
+	return fake.repairArgsForCall[i].log, fake.repairArgsForCall[i].cfg, fake.repairArgsForCall[i].pid, fake.repairArgsForCall[i].recreateChains
+}
+
+func (fake *FakeConfigurer) RepairReturns(result1 kawasaki.Repairs, result2 error) {
+	fake.RepairStub = nil
+	fake.repairReturns = struct {
+		result1 kawasaki.Repairs
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeConfigurer) RepairReturnsOnCall(i int, result1 kawasaki.Repairs, result2 error) {
+	fake.RepairStub = nil
+	if fake.repairReturnsOnCall == nil {
+		fake.repairReturnsOnCall = make(map[int]struct {
+			result1 kawasaki.Repairs
+			result2 error
+		})
+	}
+	fake.repairReturnsOnCall[i] = struct {
+		result1 kawasaki.Repairs
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeConfigurer) DestroyBridge(log lager.Logger, cfg kawasaki.NetworkConfig) error {
 	fake.destroyBridgeMutex.Lock()
 	ret, specificReturn := fake.destroyBridgeReturnsOnCall[len(fake.destroyBridgeArgsForCall)]
@@ -198,15 +281,69 @@ func (fake *FakeConfigurer) DestroyIPTablesRulesReturnsOnCall(i int, result1 err
 	}{result1}
 }
 
+func (fake *FakeConfigurer) ConfigureDNS(log lager.Logger, cfg kawasaki.NetworkConfig, pid int) error {
+	fake.configureDNSMutex.Lock()
+	ret, specificReturn := fake.configureDNSReturnsOnCall[len(fake.configureDNSArgsForCall)]
+	fake.configureDNSArgsForCall = append(fake.configureDNSArgsForCall, struct {
+		log lager.Logger
+		cfg kawasaki.NetworkConfig
+		pid int
+	}{log, cfg, pid})
+	fake.recordInvocation("ConfigureDNS", []interface{}{log, cfg, pid})
+	fake.configureDNSMutex.Unlock()
+	if fake.ConfigureDNSStub != nil {
+		return fake.ConfigureDNSStub(log, cfg, pid)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.configureDNSReturns.result1
+}
+
+func (fake *FakeConfigurer) ConfigureDNSCallCount() int {
+	fake.configureDNSMutex.RLock()
+	defer fake.configureDNSMutex.RUnlock()
+	return len(fake.configureDNSArgsForCall)
+}
+
+func (fake *FakeConfigurer) ConfigureDNSArgsForCall(i int) (lager.Logger, kawasaki.NetworkConfig, int) {
+	fake.configureDNSMutex.RLock()
+	defer fake.configureDNSMutex.RUnlock()
+	return fake.configureDNSArgsForCall[i].log, fake.configureDNSArgsForCall[i].cfg, fake.configureDNSArgsForCall[i].pid
+}
+
+func (fake *FakeConfigurer) ConfigureDNSReturns(result1 error) {
+	fake.ConfigureDNSStub = nil
+	fake.configureDNSReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeConfigurer) ConfigureDNSReturnsOnCall(i int, result1 error) {
+	fake.ConfigureDNSStub = nil
+	if fake.configureDNSReturnsOnCall == nil {
+		fake.configureDNSReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.configureDNSReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeConfigurer) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.applyMutex.RLock()
 	defer fake.applyMutex.RUnlock()
+	fake.repairMutex.RLock()
+	defer fake.repairMutex.RUnlock()
 	fake.destroyBridgeMutex.RLock()
 	defer fake.destroyBridgeMutex.RUnlock()
 	fake.destroyIPTablesRulesMutex.RLock()
 	defer fake.destroyIPTablesRulesMutex.RUnlock()
+	fake.configureDNSMutex.RLock()
+	defer fake.configureDNSMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -0,0 +1,104 @@
+// This file was generated by counterfeiter
+package kawasakifakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/lager"
+)
+
+type FakeFirewallExplainer struct {
+	ExplainStub        func(log lager.Logger, cfg kawasaki.NetworkConfig, traffic kawasaki.Traffic) (kawasaki.Explanation, error)
+	explainMutex       sync.RWMutex
+	explainArgsForCall []struct {
+		log     lager.Logger
+		cfg     kawasaki.NetworkConfig
+		traffic kawasaki.Traffic
+	}
+	explainReturns struct {
+		result1 kawasaki.Explanation
+		result2 error
+	}
+	explainReturnsOnCall map[int]struct {
+		result1 kawasaki.Explanation
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeFirewallExplainer) Explain(log lager.Logger, cfg kawasaki.NetworkConfig, traffic kawasaki.Traffic) (kawasaki.Explanation, error) {
+	fake.explainMutex.Lock()
+	ret, specificReturn := fake.explainReturnsOnCall[len(fake.explainArgsForCall)]
+	fake.explainArgsForCall = append(fake.explainArgsForCall, struct {
+		log     lager.Logger
+		cfg     kawasaki.NetworkConfig
+		traffic kawasaki.Traffic
+	}{log, cfg, traffic})
+	fake.recordInvocation("Explain", []interface{}{log, cfg, traffic})
+	fake.explainMutex.Unlock()
+	if fake.ExplainStub != nil {
+		return fake.ExplainStub(log, cfg, traffic)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.explainReturns.result1, fake.explainReturns.result2
+}
+
+func (fake *FakeFirewallExplainer) ExplainCallCount() int {
+	fake.explainMutex.RLock()
+	defer fake.explainMutex.RUnlock()
+	return len(fake.explainArgsForCall)
+}
+
+func (fake *FakeFirewallExplainer) ExplainArgsForCall(i int) (lager.Logger, kawasaki.NetworkConfig, kawasaki.Traffic) {
+	fake.explainMutex.RLock()
+	defer fake.explainMutex.RUnlock()
+	return fake.explainArgsForCall[i].log, fake.explainArgsForCall[i].cfg, fake.explainArgsForCall[i].traffic
+}
+
+func (fake *FakeFirewallExplainer) ExplainReturns(result1 kawasaki.Explanation, result2 error) {
+	fake.ExplainStub = nil
+	fake.explainReturns = struct {
+		result1 kawasaki.Explanation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFirewallExplainer) ExplainReturnsOnCall(i int, result1 kawasaki.Explanation, result2 error) {
+	fake.ExplainStub = nil
+	if fake.explainReturnsOnCall == nil {
+		fake.explainReturnsOnCall = make(map[int]struct {
+			result1 kawasaki.Explanation
+			result2 error
+		})
+	}
+	fake.explainReturnsOnCall[i] = struct {
+		result1 kawasaki.Explanation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeFirewallExplainer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.explainMutex.RLock()
+	defer fake.explainMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeFirewallExplainer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ kawasaki.FirewallExplainer = new(FakeFirewallExplainer)
@@ -10,7 +10,7 @@ import (
 )
 
 type FakeFirewallOpener struct {
-	OpenStub        func(log lager.Logger, instance, handle string, rule garden.NetOutRule) error
+	OpenStub        func(log lager.Logger, instance string, handle string, rule garden.NetOutRule) error
 	openMutex       sync.RWMutex
 	openArgsForCall []struct {
 		log      lager.Logger
@@ -24,7 +24,7 @@ type FakeFirewallOpener struct {
 	openReturnsOnCall map[int]struct {
 		result1 error
 	}
-	BulkOpenStub        func(log lager.Logger, instance, handle string, rule []garden.NetOutRule) error
+	BulkOpenStub        func(log lager.Logger, instance string, handle string, rule []garden.NetOutRule) error
 	bulkOpenMutex       sync.RWMutex
 	bulkOpenArgsForCall []struct {
 		log      lager.Logger
@@ -38,6 +38,20 @@ type FakeFirewallOpener struct {
 	bulkOpenReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ReplaceDNSRulesStub        func(log lager.Logger, instance string, handle string, rules []garden.NetOutRule) error
+	replaceDNSRulesMutex       sync.RWMutex
+	replaceDNSRulesArgsForCall []struct {
+		log      lager.Logger
+		instance string
+		handle   string
+		rules    []garden.NetOutRule
+	}
+	replaceDNSRulesReturns struct {
+		result1 error
+	}
+	replaceDNSRulesReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -149,6 +163,62 @@ func (fake *FakeFirewallOpener) BulkOpenReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeFirewallOpener) ReplaceDNSRules(log lager.Logger, instance string, handle string, rules []garden.NetOutRule) error {
+	var rulesCopy []garden.NetOutRule
+	if rules != nil {
+		rulesCopy = make([]garden.NetOutRule, len(rules))
+		copy(rulesCopy, rules)
+	}
+	fake.replaceDNSRulesMutex.Lock()
+	ret, specificReturn := fake.replaceDNSRulesReturnsOnCall[len(fake.replaceDNSRulesArgsForCall)]
+	fake.replaceDNSRulesArgsForCall = append(fake.replaceDNSRulesArgsForCall, struct {
+		log      lager.Logger
+		instance string
+		handle   string
+		rules    []garden.NetOutRule
+	}{log, instance, handle, rulesCopy})
+	fake.recordInvocation("ReplaceDNSRules", []interface{}{log, instance, handle, rulesCopy})
+	fake.replaceDNSRulesMutex.Unlock()
+	if fake.ReplaceDNSRulesStub != nil {
+		return fake.ReplaceDNSRulesStub(log, instance, handle, rules)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.replaceDNSRulesReturns.result1
+}
+
+func (fake *FakeFirewallOpener) ReplaceDNSRulesCallCount() int {
+	fake.replaceDNSRulesMutex.RLock()
+	defer fake.replaceDNSRulesMutex.RUnlock()
+	return len(fake.replaceDNSRulesArgsForCall)
+}
+
+func (fake *FakeFirewallOpener) ReplaceDNSRulesArgsForCall(i int) (lager.Logger, string, string, []garden.NetOutRule) {
+	fake.replaceDNSRulesMutex.RLock()
+	defer fake.replaceDNSRulesMutex.RUnlock()
+	return fake.replaceDNSRulesArgsForCall[i].log, fake.replaceDNSRulesArgsForCall[i].instance, fake.replaceDNSRulesArgsForCall[i].handle, fake.replaceDNSRulesArgsForCall[i].rules
+}
+
+func (fake *FakeFirewallOpener) ReplaceDNSRulesReturns(result1 error) {
+	fake.ReplaceDNSRulesStub = nil
+	fake.replaceDNSRulesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeFirewallOpener) ReplaceDNSRulesReturnsOnCall(i int, result1 error) {
+	fake.ReplaceDNSRulesStub = nil
+	if fake.replaceDNSRulesReturnsOnCall == nil {
+		fake.replaceDNSRulesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.replaceDNSRulesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeFirewallOpener) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -156,6 +226,8 @@ func (fake *FakeFirewallOpener) Invocations() map[string][][]interface{} {
 	defer fake.openMutex.RUnlock()
 	fake.bulkOpenMutex.RLock()
 	defer fake.bulkOpenMutex.RUnlock()
+	fake.replaceDNSRulesMutex.RLock()
+	defer fake.replaceDNSRulesMutex.RUnlock()
 	return fake.invocations
 }
 
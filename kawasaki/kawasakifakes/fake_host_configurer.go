@@ -22,6 +22,21 @@ type FakeHostConfigurer struct {
 	applyReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RepairStub        func(logger lager.Logger, cfg kawasaki.NetworkConfig, pid int) (kawasaki.Repairs, error)
+	repairMutex       sync.RWMutex
+	repairArgsForCall []struct {
+		logger lager.Logger
+		cfg    kawasaki.NetworkConfig
+		pid    int
+	}
+	repairReturns struct {
+		result1 kawasaki.Repairs
+		result2 error
+	}
+	repairReturnsOnCall map[int]struct {
+		result1 kawasaki.Repairs
+		result2 error
+	}
 	DestroyStub        func(cfg kawasaki.NetworkConfig) error
 	destroyMutex       sync.RWMutex
 	destroyArgsForCall []struct {
@@ -87,6 +102,59 @@ func (fake *FakeHostConfigurer) ApplyReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeHostConfigurer) Repair(logger lager.Logger, cfg kawasaki.NetworkConfig, pid int) (kawasaki.Repairs, error) {
+	fake.repairMutex.Lock()
+	ret, specificReturn := fake.repairReturnsOnCall[len(fake.repairArgsForCall)]
+	fake.repairArgsForCall = append(fake.repairArgsForCall, struct {
+		logger lager.Logger
+		cfg    kawasaki.NetworkConfig
+		pid    int
+	}{logger, cfg, pid})
+	fake.recordInvocation("Repair", []interface{}{logger, cfg, pid})
+	fake.repairMutex.Unlock()
+	if fake.RepairStub != nil {
+		return fake.RepairStub(logger, cfg, pid)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.repairReturns.result1, fake.repairReturns.result2
+}
+
+func (fake *FakeHostConfigurer) RepairCallCount() int {
+	fake.repairMutex.RLock()
+	defer fake.repairMutex.RUnlock()
+	return len(fake.repairArgsForCall)
+}
+
+func (fake *FakeHostConfigurer) RepairArgsForCall(i int) (lager.Logger, kawasaki.NetworkConfig, int) {
+	fake.repairMutex.RLock()
+	defer fake.repairMutex.RUnlock()
+	return fake.repairArgsForCall[i].logger, fake.repairArgsForCall[i].cfg, fake.repairArgsForCall[i].pid
+}
+
+func (fake *FakeHostConfigurer) RepairReturns(result1 kawasaki.Repairs, result2 error) {
+	fake.RepairStub = nil
+	fake.repairReturns = struct {
+		result1 kawasaki.Repairs
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHostConfigurer) RepairReturnsOnCall(i int, result1 kawasaki.Repairs, result2 error) {
+	fake.RepairStub = nil
+	if fake.repairReturnsOnCall == nil {
+		fake.repairReturnsOnCall = make(map[int]struct {
+			result1 kawasaki.Repairs
+			result2 error
+		})
+	}
+	fake.repairReturnsOnCall[i] = struct {
+		result1 kawasaki.Repairs
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeHostConfigurer) Destroy(cfg kawasaki.NetworkConfig) error {
 	fake.destroyMutex.Lock()
 	ret, specificReturn := fake.destroyReturnsOnCall[len(fake.destroyArgsForCall)]
@@ -140,6 +208,8 @@ func (fake *FakeHostConfigurer) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.applyMutex.RLock()
 	defer fake.applyMutex.RUnlock()
+	fake.repairMutex.RLock()
+	defer fake.repairMutex.RUnlock()
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
 	return fake.invocations
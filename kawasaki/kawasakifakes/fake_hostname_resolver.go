@@ -0,0 +1,116 @@
+// This file was generated by counterfeiter
+package kawasakifakes
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/lager"
+)
+
+type FakeHostnameResolver struct {
+	ResolveStub        func(log lager.Logger, servers []net.IP, hostname string) ([]net.IP, time.Duration, error)
+	resolveMutex       sync.RWMutex
+	resolveArgsForCall []struct {
+		log      lager.Logger
+		servers  []net.IP
+		hostname string
+	}
+	resolveReturns struct {
+		result1 []net.IP
+		result2 time.Duration
+		result3 error
+	}
+	resolveReturnsOnCall map[int]struct {
+		result1 []net.IP
+		result2 time.Duration
+		result3 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeHostnameResolver) Resolve(log lager.Logger, servers []net.IP, hostname string) ([]net.IP, time.Duration, error) {
+	var serversCopy []net.IP
+	if servers != nil {
+		serversCopy = make([]net.IP, len(servers))
+		copy(serversCopy, servers)
+	}
+	fake.resolveMutex.Lock()
+	ret, specificReturn := fake.resolveReturnsOnCall[len(fake.resolveArgsForCall)]
+	fake.resolveArgsForCall = append(fake.resolveArgsForCall, struct {
+		log      lager.Logger
+		servers  []net.IP
+		hostname string
+	}{log, serversCopy, hostname})
+	fake.recordInvocation("Resolve", []interface{}{log, serversCopy, hostname})
+	fake.resolveMutex.Unlock()
+	if fake.ResolveStub != nil {
+		return fake.ResolveStub(log, servers, hostname)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fake.resolveReturns.result1, fake.resolveReturns.result2, fake.resolveReturns.result3
+}
+
+func (fake *FakeHostnameResolver) ResolveCallCount() int {
+	fake.resolveMutex.RLock()
+	defer fake.resolveMutex.RUnlock()
+	return len(fake.resolveArgsForCall)
+}
+
+func (fake *FakeHostnameResolver) ResolveArgsForCall(i int) (lager.Logger, []net.IP, string) {
+	fake.resolveMutex.RLock()
+	defer fake.resolveMutex.RUnlock()
+	return fake.resolveArgsForCall[i].log, fake.resolveArgsForCall[i].servers, fake.resolveArgsForCall[i].hostname
+}
+
+func (fake *FakeHostnameResolver) ResolveReturns(result1 []net.IP, result2 time.Duration, result3 error) {
+	fake.ResolveStub = nil
+	fake.resolveReturns = struct {
+		result1 []net.IP
+		result2 time.Duration
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeHostnameResolver) ResolveReturnsOnCall(i int, result1 []net.IP, result2 time.Duration, result3 error) {
+	fake.ResolveStub = nil
+	if fake.resolveReturnsOnCall == nil {
+		fake.resolveReturnsOnCall = make(map[int]struct {
+			result1 []net.IP
+			result2 time.Duration
+			result3 error
+		})
+	}
+	fake.resolveReturnsOnCall[i] = struct {
+		result1 []net.IP
+		result2 time.Duration
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeHostnameResolver) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.resolveMutex.RLock()
+	defer fake.resolveMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeHostnameResolver) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ kawasaki.HostnameResolver = new(FakeHostnameResolver)
@@ -0,0 +1,175 @@
+// This file was generated by counterfeiter
+package kawasakifakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/lager"
+)
+
+type FakeHostnameRules struct {
+	ApplyStub        func(log lager.Logger, cfg kawasaki.NetworkConfig, rules []kawasaki.HostnameRule) error
+	applyMutex       sync.RWMutex
+	applyArgsForCall []struct {
+		log   lager.Logger
+		cfg   kawasaki.NetworkConfig
+		rules []kawasaki.HostnameRule
+	}
+	applyReturns struct {
+		result1 error
+	}
+	applyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RestoreStub        func(log lager.Logger, cfg kawasaki.NetworkConfig, rules []kawasaki.HostnameRule)
+	restoreMutex       sync.RWMutex
+	restoreArgsForCall []struct {
+		log   lager.Logger
+		cfg   kawasaki.NetworkConfig
+		rules []kawasaki.HostnameRule
+	}
+	ForgetStub        func(handle string)
+	forgetMutex       sync.RWMutex
+	forgetArgsForCall []struct {
+		handle string
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeHostnameRules) Apply(log lager.Logger, cfg kawasaki.NetworkConfig, rules []kawasaki.HostnameRule) error {
+	var rulesCopy []kawasaki.HostnameRule
+	if rules != nil {
+		rulesCopy = make([]kawasaki.HostnameRule, len(rules))
+		copy(rulesCopy, rules)
+	}
+	fake.applyMutex.Lock()
+	ret, specificReturn := fake.applyReturnsOnCall[len(fake.applyArgsForCall)]
+	fake.applyArgsForCall = append(fake.applyArgsForCall, struct {
+		log   lager.Logger
+		cfg   kawasaki.NetworkConfig
+		rules []kawasaki.HostnameRule
+	}{log, cfg, rulesCopy})
+	fake.recordInvocation("Apply", []interface{}{log, cfg, rulesCopy})
+	fake.applyMutex.Unlock()
+	if fake.ApplyStub != nil {
+		return fake.ApplyStub(log, cfg, rules)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.applyReturns.result1
+}
+
+func (fake *FakeHostnameRules) ApplyCallCount() int {
+	fake.applyMutex.RLock()
+	defer fake.applyMutex.RUnlock()
+	return len(fake.applyArgsForCall)
+}
+
+func (fake *FakeHostnameRules) ApplyArgsForCall(i int) (lager.Logger, kawasaki.NetworkConfig, []kawasaki.HostnameRule) {
+	fake.applyMutex.RLock()
+	defer fake.applyMutex.RUnlock()
+	return fake.applyArgsForCall[i].log, fake.applyArgsForCall[i].cfg, fake.applyArgsForCall[i].rules
+}
+
+func (fake *FakeHostnameRules) ApplyReturns(result1 error) {
+	fake.ApplyStub = nil
+	fake.applyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeHostnameRules) ApplyReturnsOnCall(i int, result1 error) {
+	fake.ApplyStub = nil
+	if fake.applyReturnsOnCall == nil {
+		fake.applyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.applyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeHostnameRules) Restore(log lager.Logger, cfg kawasaki.NetworkConfig, rules []kawasaki.HostnameRule) {
+	var rulesCopy []kawasaki.HostnameRule
+	if rules != nil {
+		rulesCopy = make([]kawasaki.HostnameRule, len(rules))
+		copy(rulesCopy, rules)
+	}
+	fake.restoreMutex.Lock()
+	fake.restoreArgsForCall = append(fake.restoreArgsForCall, struct {
+		log   lager.Logger
+		cfg   kawasaki.NetworkConfig
+		rules []kawasaki.HostnameRule
+	}{log, cfg, rulesCopy})
+	fake.recordInvocation("Restore", []interface{}{log, cfg, rulesCopy})
+	fake.restoreMutex.Unlock()
+	if fake.RestoreStub != nil {
+		fake.RestoreStub(log, cfg, rules)
+	}
+}
+
+func (fake *FakeHostnameRules) RestoreCallCount() int {
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	return len(fake.restoreArgsForCall)
+}
+
+func (fake *FakeHostnameRules) RestoreArgsForCall(i int) (lager.Logger, kawasaki.NetworkConfig, []kawasaki.HostnameRule) {
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	return fake.restoreArgsForCall[i].log, fake.restoreArgsForCall[i].cfg, fake.restoreArgsForCall[i].rules
+}
+
+func (fake *FakeHostnameRules) Forget(handle string) {
+	fake.forgetMutex.Lock()
+	fake.forgetArgsForCall = append(fake.forgetArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("Forget", []interface{}{handle})
+	fake.forgetMutex.Unlock()
+	if fake.ForgetStub != nil {
+		fake.ForgetStub(handle)
+	}
+}
+
+func (fake *FakeHostnameRules) ForgetCallCount() int {
+	fake.forgetMutex.RLock()
+	defer fake.forgetMutex.RUnlock()
+	return len(fake.forgetArgsForCall)
+}
+
+func (fake *FakeHostnameRules) ForgetArgsForCall(i int) string {
+	fake.forgetMutex.RLock()
+	defer fake.forgetMutex.RUnlock()
+	return fake.forgetArgsForCall[i].handle
+}
+
+func (fake *FakeHostnameRules) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.applyMutex.RLock()
+	defer fake.applyMutex.RUnlock()
+	fake.restoreMutex.RLock()
+	defer fake.restoreMutex.RUnlock()
+	fake.forgetMutex.RLock()
+	defer fake.forgetMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeHostnameRules) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ kawasaki.HostnameRules = new(FakeHostnameRules)
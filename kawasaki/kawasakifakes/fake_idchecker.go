@@ -0,0 +1,99 @@
+// This file was generated by counterfeiter
+package kawasakifakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+)
+
+type FakeIDChecker struct {
+	InUseStub        func(id string) (bool, error)
+	inUseMutex       sync.RWMutex
+	inUseArgsForCall []struct {
+		id string
+	}
+	inUseReturns struct {
+		result1 bool
+		result2 error
+	}
+	inUseReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeIDChecker) InUse(id string) (bool, error) {
+	fake.inUseMutex.Lock()
+	ret, specificReturn := fake.inUseReturnsOnCall[len(fake.inUseArgsForCall)]
+	fake.inUseArgsForCall = append(fake.inUseArgsForCall, struct {
+		id string
+	}{id})
+	fake.recordInvocation("InUse", []interface{}{id})
+	fake.inUseMutex.Unlock()
+	if fake.InUseStub != nil {
+		return fake.InUseStub(id)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.inUseReturns.result1, fake.inUseReturns.result2
+}
+
+func (fake *FakeIDChecker) InUseCallCount() int {
+	fake.inUseMutex.RLock()
+	defer fake.inUseMutex.RUnlock()
+	return len(fake.inUseArgsForCall)
+}
+
+func (fake *FakeIDChecker) InUseArgsForCall(i int) string {
+	fake.inUseMutex.RLock()
+	defer fake.inUseMutex.RUnlock()
+	return fake.inUseArgsForCall[i].id
+}
+
+func (fake *FakeIDChecker) InUseReturns(result1 bool, result2 error) {
+	fake.InUseStub = nil
+	fake.inUseReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIDChecker) InUseReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.InUseStub = nil
+	if fake.inUseReturnsOnCall == nil {
+		fake.inUseReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.inUseReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeIDChecker) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.inUseMutex.RLock()
+	defer fake.inUseMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeIDChecker) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ kawasaki.IDChecker = new(FakeIDChecker)
@@ -10,7 +10,7 @@ import (
 )
 
 type FakeInstanceChainCreator struct {
-	CreateStub        func(logger lager.Logger, handle, instanceChain, bridgeName string, ip net.IP, network *net.IPNet) error
+	CreateStub        func(logger lager.Logger, handle string, instanceChain string, bridgeName string, ip net.IP, network *net.IPNet) error
 	createMutex       sync.RWMutex
 	createArgsForCall []struct {
 		logger        lager.Logger
@@ -26,6 +26,55 @@ type FakeInstanceChainCreator struct {
 	createReturnsOnCall map[int]struct {
 		result1 error
 	}
+	CreateOverridesStub        func(logger lager.Logger, handle string, instanceChain string, bridgeName string, ip net.IP, overrides kawasaki.NetworkOverrides) error
+	createOverridesMutex       sync.RWMutex
+	createOverridesArgsForCall []struct {
+		logger        lager.Logger
+		handle        string
+		instanceChain string
+		bridgeName    string
+		ip            net.IP
+		overrides     kawasaki.NetworkOverrides
+	}
+	createOverridesReturns struct {
+		result1 error
+	}
+	createOverridesReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CreateConnectionLimitsStub        func(logger lager.Logger, handle string, instanceChain string, bridgeName string, ip net.IP, limits kawasaki.ConnectionLimits) error
+	createConnectionLimitsMutex       sync.RWMutex
+	createConnectionLimitsArgsForCall []struct {
+		logger        lager.Logger
+		handle        string
+		instanceChain string
+		bridgeName    string
+		ip            net.IP
+		limits        kawasaki.ConnectionLimits
+	}
+	createConnectionLimitsReturns struct {
+		result1 error
+	}
+	createConnectionLimitsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	VerifyStub        func(logger lager.Logger, instanceChain string, network *net.IPNet, overrides kawasaki.NetworkOverrides, limits kawasaki.ConnectionLimits) (bool, error)
+	verifyMutex       sync.RWMutex
+	verifyArgsForCall []struct {
+		logger        lager.Logger
+		instanceChain string
+		network       *net.IPNet
+		overrides     kawasaki.NetworkOverrides
+		limits        kawasaki.ConnectionLimits
+	}
+	verifyReturns struct {
+		result1 bool
+		result2 error
+	}
+	verifyReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	DestroyStub        func(logger lager.Logger, instanceChain string) error
 	destroyMutex       sync.RWMutex
 	destroyArgsForCall []struct {
@@ -95,6 +144,167 @@ func (fake *FakeInstanceChainCreator) CreateReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *FakeInstanceChainCreator) CreateOverrides(logger lager.Logger, handle string, instanceChain string, bridgeName string, ip net.IP, overrides kawasaki.NetworkOverrides) error {
+	fake.createOverridesMutex.Lock()
+	ret, specificReturn := fake.createOverridesReturnsOnCall[len(fake.createOverridesArgsForCall)]
+	fake.createOverridesArgsForCall = append(fake.createOverridesArgsForCall, struct {
+		logger        lager.Logger
+		handle        string
+		instanceChain string
+		bridgeName    string
+		ip            net.IP
+		overrides     kawasaki.NetworkOverrides
+	}{logger, handle, instanceChain, bridgeName, ip, overrides})
+	fake.recordInvocation("CreateOverrides", []interface{}{logger, handle, instanceChain, bridgeName, ip, overrides})
+	fake.createOverridesMutex.Unlock()
+	if fake.CreateOverridesStub != nil {
+		return fake.CreateOverridesStub(logger, handle, instanceChain, bridgeName, ip, overrides)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.createOverridesReturns.result1
+}
+
+func (fake *FakeInstanceChainCreator) CreateOverridesCallCount() int {
+	fake.createOverridesMutex.RLock()
+	defer fake.createOverridesMutex.RUnlock()
+	return len(fake.createOverridesArgsForCall)
+}
+
+func (fake *FakeInstanceChainCreator) CreateOverridesArgsForCall(i int) (lager.Logger, string, string, string, net.IP, kawasaki.NetworkOverrides) {
+	fake.createOverridesMutex.RLock()
+	defer fake.createOverridesMutex.RUnlock()
+	return fake.createOverridesArgsForCall[i].logger, fake.createOverridesArgsForCall[i].handle, fake.createOverridesArgsForCall[i].instanceChain, fake.createOverridesArgsForCall[i].bridgeName, fake.createOverridesArgsForCall[i].ip, fake.createOverridesArgsForCall[i].overrides
+}
+
+func (fake *FakeInstanceChainCreator) CreateOverridesReturns(result1 error) {
+	fake.CreateOverridesStub = nil
+	fake.createOverridesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeInstanceChainCreator) CreateOverridesReturnsOnCall(i int, result1 error) {
+	fake.CreateOverridesStub = nil
+	if fake.createOverridesReturnsOnCall == nil {
+		fake.createOverridesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createOverridesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeInstanceChainCreator) CreateConnectionLimits(logger lager.Logger, handle string, instanceChain string, bridgeName string, ip net.IP, limits kawasaki.ConnectionLimits) error {
+	fake.createConnectionLimitsMutex.Lock()
+	ret, specificReturn := fake.createConnectionLimitsReturnsOnCall[len(fake.createConnectionLimitsArgsForCall)]
+	fake.createConnectionLimitsArgsForCall = append(fake.createConnectionLimitsArgsForCall, struct {
+		logger        lager.Logger
+		handle        string
+		instanceChain string
+		bridgeName    string
+		ip            net.IP
+		limits        kawasaki.ConnectionLimits
+	}{logger, handle, instanceChain, bridgeName, ip, limits})
+	fake.recordInvocation("CreateConnectionLimits", []interface{}{logger, handle, instanceChain, bridgeName, ip, limits})
+	fake.createConnectionLimitsMutex.Unlock()
+	if fake.CreateConnectionLimitsStub != nil {
+		return fake.CreateConnectionLimitsStub(logger, handle, instanceChain, bridgeName, ip, limits)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.createConnectionLimitsReturns.result1
+}
+
+func (fake *FakeInstanceChainCreator) CreateConnectionLimitsCallCount() int {
+	fake.createConnectionLimitsMutex.RLock()
+	defer fake.createConnectionLimitsMutex.RUnlock()
+	return len(fake.createConnectionLimitsArgsForCall)
+}
+
+func (fake *FakeInstanceChainCreator) CreateConnectionLimitsArgsForCall(i int) (lager.Logger, string, string, string, net.IP, kawasaki.ConnectionLimits) {
+	fake.createConnectionLimitsMutex.RLock()
+	defer fake.createConnectionLimitsMutex.RUnlock()
+	return fake.createConnectionLimitsArgsForCall[i].logger, fake.createConnectionLimitsArgsForCall[i].handle, fake.createConnectionLimitsArgsForCall[i].instanceChain, fake.createConnectionLimitsArgsForCall[i].bridgeName, fake.createConnectionLimitsArgsForCall[i].ip, fake.createConnectionLimitsArgsForCall[i].limits
+}
+
+func (fake *FakeInstanceChainCreator) CreateConnectionLimitsReturns(result1 error) {
+	fake.CreateConnectionLimitsStub = nil
+	fake.createConnectionLimitsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeInstanceChainCreator) CreateConnectionLimitsReturnsOnCall(i int, result1 error) {
+	fake.CreateConnectionLimitsStub = nil
+	if fake.createConnectionLimitsReturnsOnCall == nil {
+		fake.createConnectionLimitsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createConnectionLimitsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeInstanceChainCreator) Verify(logger lager.Logger, instanceChain string, network *net.IPNet, overrides kawasaki.NetworkOverrides, limits kawasaki.ConnectionLimits) (bool, error) {
+	fake.verifyMutex.Lock()
+	ret, specificReturn := fake.verifyReturnsOnCall[len(fake.verifyArgsForCall)]
+	fake.verifyArgsForCall = append(fake.verifyArgsForCall, struct {
+		logger        lager.Logger
+		instanceChain string
+		network       *net.IPNet
+		overrides     kawasaki.NetworkOverrides
+		limits        kawasaki.ConnectionLimits
+	}{logger, instanceChain, network, overrides, limits})
+	fake.recordInvocation("Verify", []interface{}{logger, instanceChain, network, overrides, limits})
+	fake.verifyMutex.Unlock()
+	if fake.VerifyStub != nil {
+		return fake.VerifyStub(logger, instanceChain, network, overrides, limits)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.verifyReturns.result1, fake.verifyReturns.result2
+}
+
+func (fake *FakeInstanceChainCreator) VerifyCallCount() int {
+	fake.verifyMutex.RLock()
+	defer fake.verifyMutex.RUnlock()
+	return len(fake.verifyArgsForCall)
+}
+
+func (fake *FakeInstanceChainCreator) VerifyArgsForCall(i int) (lager.Logger, string, *net.IPNet, kawasaki.NetworkOverrides, kawasaki.ConnectionLimits) {
+	fake.verifyMutex.RLock()
+	defer fake.verifyMutex.RUnlock()
+	return fake.verifyArgsForCall[i].logger, fake.verifyArgsForCall[i].instanceChain, fake.verifyArgsForCall[i].network, fake.verifyArgsForCall[i].overrides, fake.verifyArgsForCall[i].limits
+}
+
+func (fake *FakeInstanceChainCreator) VerifyReturns(result1 bool, result2 error) {
+	fake.VerifyStub = nil
+	fake.verifyReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInstanceChainCreator) VerifyReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.VerifyStub = nil
+	if fake.verifyReturnsOnCall == nil {
+		fake.verifyReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.verifyReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeInstanceChainCreator) Destroy(logger lager.Logger, instanceChain string) error {
 	fake.destroyMutex.Lock()
 	ret, specificReturn := fake.destroyReturnsOnCall[len(fake.destroyArgsForCall)]
@@ -149,6 +359,12 @@ func (fake *FakeInstanceChainCreator) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.createMutex.RLock()
 	defer fake.createMutex.RUnlock()
+	fake.createOverridesMutex.RLock()
+	defer fake.createOverridesMutex.RUnlock()
+	fake.createConnectionLimitsMutex.RLock()
+	defer fake.createConnectionLimitsMutex.RUnlock()
+	fake.verifyMutex.RLock()
+	defer fake.verifyMutex.RUnlock()
 	fake.destroyMutex.RLock()
 	defer fake.destroyMutex.RUnlock()
 	return fake.invocations
@@ -0,0 +1,97 @@
+// This file was generated by counterfeiter
+package kawasakifakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/lager"
+)
+
+type FakeNetworkVerifier struct {
+	VerifyStub        func(log lager.Logger, handle string) error
+	verifyMutex       sync.RWMutex
+	verifyArgsForCall []struct {
+		log    lager.Logger
+		handle string
+	}
+	verifyReturns struct {
+		result1 error
+	}
+	verifyReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeNetworkVerifier) Verify(log lager.Logger, handle string) error {
+	fake.verifyMutex.Lock()
+	ret, specificReturn := fake.verifyReturnsOnCall[len(fake.verifyArgsForCall)]
+	fake.verifyArgsForCall = append(fake.verifyArgsForCall, struct {
+		log    lager.Logger
+		handle string
+	}{log, handle})
+	fake.recordInvocation("Verify", []interface{}{log, handle})
+	fake.verifyMutex.Unlock()
+	if fake.VerifyStub != nil {
+		return fake.VerifyStub(log, handle)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.verifyReturns.result1
+}
+
+func (fake *FakeNetworkVerifier) VerifyCallCount() int {
+	fake.verifyMutex.RLock()
+	defer fake.verifyMutex.RUnlock()
+	return len(fake.verifyArgsForCall)
+}
+
+func (fake *FakeNetworkVerifier) VerifyArgsForCall(i int) (lager.Logger, string) {
+	fake.verifyMutex.RLock()
+	defer fake.verifyMutex.RUnlock()
+	return fake.verifyArgsForCall[i].log, fake.verifyArgsForCall[i].handle
+}
+
+func (fake *FakeNetworkVerifier) VerifyReturns(result1 error) {
+	fake.VerifyStub = nil
+	fake.verifyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkVerifier) VerifyReturnsOnCall(i int, result1 error) {
+	fake.VerifyStub = nil
+	if fake.verifyReturnsOnCall == nil {
+		fake.verifyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.verifyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkVerifier) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.verifyMutex.RLock()
+	defer fake.verifyMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeNetworkVerifier) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ kawasaki.NetworkVerifier = new(FakeNetworkVerifier)
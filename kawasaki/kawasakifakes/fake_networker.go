@@ -44,7 +44,7 @@ type FakeNetworker struct {
 	destroyReturnsOnCall map[int]struct {
 		result1 error
 	}
-	NetInStub        func(log lager.Logger, handle string, externalPort, containerPort uint32) (uint32, uint32, error)
+	NetInStub        func(log lager.Logger, handle string, externalPort uint32, containerPort uint32) (uint32, uint32, error)
 	netInMutex       sync.RWMutex
 	netInArgsForCall []struct {
 		log           lager.Logger
@@ -100,6 +100,18 @@ type FakeNetworker struct {
 	restoreReturnsOnCall map[int]struct {
 		result1 error
 	}
+	VerifyStub        func(log lager.Logger, handle string) error
+	verifyMutex       sync.RWMutex
+	verifyArgsForCall []struct {
+		log    lager.Logger
+		handle string
+	}
+	verifyReturns struct {
+		result1 error
+	}
+	verifyReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -454,6 +466,55 @@ func (fake *FakeNetworker) RestoreReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeNetworker) Verify(log lager.Logger, handle string) error {
+	fake.verifyMutex.Lock()
+	ret, specificReturn := fake.verifyReturnsOnCall[len(fake.verifyArgsForCall)]
+	fake.verifyArgsForCall = append(fake.verifyArgsForCall, struct {
+		log    lager.Logger
+		handle string
+	}{log, handle})
+	fake.recordInvocation("Verify", []interface{}{log, handle})
+	fake.verifyMutex.Unlock()
+	if fake.VerifyStub != nil {
+		return fake.VerifyStub(log, handle)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.verifyReturns.result1
+}
+
+func (fake *FakeNetworker) VerifyCallCount() int {
+	fake.verifyMutex.RLock()
+	defer fake.verifyMutex.RUnlock()
+	return len(fake.verifyArgsForCall)
+}
+
+func (fake *FakeNetworker) VerifyArgsForCall(i int) (lager.Logger, string) {
+	fake.verifyMutex.RLock()
+	defer fake.verifyMutex.RUnlock()
+	return fake.verifyArgsForCall[i].log, fake.verifyArgsForCall[i].handle
+}
+
+func (fake *FakeNetworker) VerifyReturns(result1 error) {
+	fake.VerifyStub = nil
+	fake.verifyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworker) VerifyReturnsOnCall(i int, result1 error) {
+	fake.VerifyStub = nil
+	if fake.verifyReturnsOnCall == nil {
+		fake.verifyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.verifyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeNetworker) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -471,6 +532,8 @@ func (fake *FakeNetworker) Invocations() map[string][][]interface{} {
 	defer fake.bulkNetOutMutex.RUnlock()
 	fake.restoreMutex.RLock()
 	defer fake.restoreMutex.RUnlock()
+	fake.verifyMutex.RLock()
+	defer fake.verifyMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -8,10 +8,12 @@ import (
 )
 
 type FakePortPool struct {
-	AcquireStub        func() (uint32, error)
+	AcquireStub        func(handle string) (uint32, error)
 	acquireMutex       sync.RWMutex
-	acquireArgsForCall []struct{}
-	acquireReturns     struct {
+	acquireArgsForCall []struct {
+		handle string
+	}
+	acquireReturns struct {
 		result1 uint32
 		result2 error
 	}
@@ -19,15 +21,21 @@ type FakePortPool struct {
 		result1 uint32
 		result2 error
 	}
-	ReleaseStub        func(uint32)
+	ReleaseStub        func(port uint32)
 	releaseMutex       sync.RWMutex
 	releaseArgsForCall []struct {
-		arg1 uint32
+		port uint32
+	}
+	ReleaseAllStub        func(handle string)
+	releaseAllMutex       sync.RWMutex
+	releaseAllArgsForCall []struct {
+		handle string
 	}
-	RemoveStub        func(uint32) error
+	RemoveStub        func(handle string, port uint32) error
 	removeMutex       sync.RWMutex
 	removeArgsForCall []struct {
-		arg1 uint32
+		handle string
+		port   uint32
 	}
 	removeReturns struct {
 		result1 error
@@ -39,14 +47,16 @@ type FakePortPool struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakePortPool) Acquire() (uint32, error) {
+func (fake *FakePortPool) Acquire(handle string) (uint32, error) {
 	fake.acquireMutex.Lock()
 	ret, specificReturn := fake.acquireReturnsOnCall[len(fake.acquireArgsForCall)]
-	fake.acquireArgsForCall = append(fake.acquireArgsForCall, struct{}{})
-	fake.recordInvocation("Acquire", []interface{}{})
+	fake.acquireArgsForCall = append(fake.acquireArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("Acquire", []interface{}{handle})
 	fake.acquireMutex.Unlock()
 	if fake.AcquireStub != nil {
-		return fake.AcquireStub()
+		return fake.AcquireStub(handle)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -60,6 +70,12 @@ func (fake *FakePortPool) AcquireCallCount() int {
 	return len(fake.acquireArgsForCall)
 }
 
+func (fake *FakePortPool) AcquireArgsForCall(i int) string {
+	fake.acquireMutex.RLock()
+	defer fake.acquireMutex.RUnlock()
+	return fake.acquireArgsForCall[i].handle
+}
+
 func (fake *FakePortPool) AcquireReturns(result1 uint32, result2 error) {
 	fake.AcquireStub = nil
 	fake.acquireReturns = struct {
@@ -82,15 +98,15 @@ func (fake *FakePortPool) AcquireReturnsOnCall(i int, result1 uint32, result2 er
 	}{result1, result2}
 }
 
-func (fake *FakePortPool) Release(arg1 uint32) {
+func (fake *FakePortPool) Release(port uint32) {
 	fake.releaseMutex.Lock()
 	fake.releaseArgsForCall = append(fake.releaseArgsForCall, struct {
-		arg1 uint32
-	}{arg1})
-	fake.recordInvocation("Release", []interface{}{arg1})
+		port uint32
+	}{port})
+	fake.recordInvocation("Release", []interface{}{port})
 	fake.releaseMutex.Unlock()
 	if fake.ReleaseStub != nil {
-		fake.ReleaseStub(arg1)
+		fake.ReleaseStub(port)
 	}
 }
 
@@ -103,19 +119,44 @@ func (fake *FakePortPool) ReleaseCallCount() int {
 func (fake *FakePortPool) ReleaseArgsForCall(i int) uint32 {
 	fake.releaseMutex.RLock()
 	defer fake.releaseMutex.RUnlock()
-	return fake.releaseArgsForCall[i].arg1
+	return fake.releaseArgsForCall[i].port
+}
+
+func (fake *FakePortPool) ReleaseAll(handle string) {
+	fake.releaseAllMutex.Lock()
+	fake.releaseAllArgsForCall = append(fake.releaseAllArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("ReleaseAll", []interface{}{handle})
+	fake.releaseAllMutex.Unlock()
+	if fake.ReleaseAllStub != nil {
+		fake.ReleaseAllStub(handle)
+	}
+}
+
+func (fake *FakePortPool) ReleaseAllCallCount() int {
+	fake.releaseAllMutex.RLock()
+	defer fake.releaseAllMutex.RUnlock()
+	return len(fake.releaseAllArgsForCall)
+}
+
+func (fake *FakePortPool) ReleaseAllArgsForCall(i int) string {
+	fake.releaseAllMutex.RLock()
+	defer fake.releaseAllMutex.RUnlock()
+	return fake.releaseAllArgsForCall[i].handle
 }
 
-func (fake *FakePortPool) Remove(arg1 uint32) error {
+func (fake *FakePortPool) Remove(handle string, port uint32) error {
 	fake.removeMutex.Lock()
 	ret, specificReturn := fake.removeReturnsOnCall[len(fake.removeArgsForCall)]
 	fake.removeArgsForCall = append(fake.removeArgsForCall, struct {
-		arg1 uint32
-	}{arg1})
-	fake.recordInvocation("Remove", []interface{}{arg1})
+		handle string
+		port   uint32
+	}{handle, port})
+	fake.recordInvocation("Remove", []interface{}{handle, port})
 	fake.removeMutex.Unlock()
 	if fake.RemoveStub != nil {
-		return fake.RemoveStub(arg1)
+		return fake.RemoveStub(handle, port)
 	}
 	if specificReturn {
 		return ret.result1
@@ -129,10 +170,10 @@ func (fake *FakePortPool) RemoveCallCount() int {
 	return len(fake.removeArgsForCall)
 }
 
-func (fake *FakePortPool) RemoveArgsForCall(i int) uint32 {
+func (fake *FakePortPool) RemoveArgsForCall(i int) (string, uint32) {
 	fake.removeMutex.RLock()
 	defer fake.removeMutex.RUnlock()
-	return fake.removeArgsForCall[i].arg1
+	return fake.removeArgsForCall[i].handle, fake.removeArgsForCall[i].port
 }
 
 func (fake *FakePortPool) RemoveReturns(result1 error) {
@@ -161,6 +202,8 @@ func (fake *FakePortPool) Invocations() map[string][][]interface{} {
 	defer fake.acquireMutex.RUnlock()
 	fake.releaseMutex.RLock()
 	defer fake.releaseMutex.RUnlock()
+	fake.releaseAllMutex.RLock()
+	defer fake.releaseAllMutex.RUnlock()
 	fake.removeMutex.RLock()
 	defer fake.removeMutex.RUnlock()
 	return fake.invocations
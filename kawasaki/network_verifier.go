@@ -0,0 +1,81 @@
+package kawasaki
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/pivotal-golang/clock"
+)
+
+//go:generate counterfeiter . NetworkVerifier
+
+type NetworkVerifier interface {
+	Verify(log lager.Logger, handle string) error
+}
+
+// PeriodicVerifier verifies the networks of all containers on an interval, so
+// that interfaces and iptables chains removed while the server is running,
+// e.g. by a reload of the host's firewall, are re-created
+type PeriodicVerifier struct {
+	logger   lager.Logger
+	verifier NetworkVerifier
+	handles  func() ([]string, error)
+	interval time.Duration
+	clock    clock.Clock
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewPeriodicVerifier(logger lager.Logger, verifier NetworkVerifier, handles func() ([]string, error), interval time.Duration, clock clock.Clock) *PeriodicVerifier {
+	return &PeriodicVerifier{
+		logger:   logger.Session("network-verifier"),
+		verifier: verifier,
+		handles:  handles,
+		interval: interval,
+		clock:    clock,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (v *PeriodicVerifier) Start() {
+	v.logger.Info("starting", lager.Data{"interval": v.interval.String()})
+	go v.run()
+}
+
+func (v *PeriodicVerifier) Stop() {
+	close(v.stop)
+	<-v.done
+}
+
+func (v *PeriodicVerifier) run() {
+	defer close(v.done)
+	defer v.logger.Info("finished")
+
+	ticker := v.clock.NewTicker(v.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-v.stop:
+			return
+		case <-ticker.C():
+			v.verifyAll()
+		}
+	}
+}
+
+func (v *PeriodicVerifier) verifyAll() {
+	handles, err := v.handles()
+	if err != nil {
+		v.logger.Error("listing-handles-failed", err)
+		return
+	}
+
+	for _, handle := range handles {
+		if err := v.verifier.Verify(v.logger, handle); err != nil {
+			v.logger.Error("verify-failed", err, lager.Data{"handle": handle})
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package kawasaki_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	fakes "code.cloudfoundry.org/guardian/kawasaki/kawasakifakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/pivotal-golang/clock/fakeclock"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("PeriodicVerifier", func() {
+	var (
+		fakeVerifier *fakes.FakeNetworkVerifier
+		fakeClock    *fakeclock.FakeClock
+		logger       *lagertest.TestLogger
+
+		handles    []string
+		handlesErr error
+
+		verifier *kawasaki.PeriodicVerifier
+	)
+
+	BeforeEach(func() {
+		fakeVerifier = new(fakes.FakeNetworkVerifier)
+		fakeClock = fakeclock.NewFakeClock(time.Unix(123, 456))
+		logger = lagertest.NewTestLogger("test")
+
+		handles = []string{"handle-1", "handle-2"}
+		handlesErr = nil
+	})
+
+	JustBeforeEach(func() {
+		verifier = kawasaki.NewPeriodicVerifier(logger, fakeVerifier, func() ([]string, error) {
+			return handles, handlesErr
+		}, time.Minute, fakeClock)
+
+		verifier.Start()
+		Eventually(fakeClock.WatcherCount).Should(Equal(1))
+	})
+
+	AfterEach(func() {
+		verifier.Stop()
+	})
+
+	It("does not verify anything before the interval elapses", func() {
+		fakeClock.Increment(59 * time.Second)
+		Consistently(fakeVerifier.VerifyCallCount).Should(Equal(0))
+	})
+
+	It("verifies each container's network on every interval", func() {
+		fakeClock.Increment(time.Minute)
+		Eventually(fakeVerifier.VerifyCallCount).Should(Equal(2))
+
+		_, handle := fakeVerifier.VerifyArgsForCall(0)
+		Expect(handle).To(Equal("handle-1"))
+		_, handle = fakeVerifier.VerifyArgsForCall(1)
+		Expect(handle).To(Equal("handle-2"))
+
+		fakeClock.Increment(time.Minute)
+		Eventually(fakeVerifier.VerifyCallCount).Should(Equal(4))
+	})
+
+	Context("when verifying a container's network fails", func() {
+		BeforeEach(func() {
+			fakeVerifier.VerifyStub = func(_ lager.Logger, handle string) error {
+				if handle == "handle-1" {
+					return errors.New("no-bridge")
+				}
+
+				return nil
+			}
+		})
+
+		It("logs the failure and carries on with the other containers", func() {
+			fakeClock.Increment(time.Minute)
+			Eventually(fakeVerifier.VerifyCallCount).Should(Equal(2))
+			Eventually(logger).Should(gbytes.Say("verify-failed"))
+		})
+	})
+
+	Context("when listing the containers fails", func() {
+		BeforeEach(func() {
+			handlesErr = errors.New("no-depot")
+		})
+
+		It("logs the failure and tries again on the next interval", func() {
+			fakeClock.Increment(time.Minute)
+			Eventually(logger).Should(gbytes.Say("listing-handles-failed"))
+			Expect(fakeVerifier.VerifyCallCount()).To(Equal(0))
+
+			handlesErr = nil
+			fakeClock.Increment(time.Minute)
+			Eventually(fakeVerifier.VerifyCallCount).Should(Equal(2))
+		})
+	})
+})
@@ -6,6 +6,7 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"sync"
 
 	"code.cloudfoundry.org/garden"
 	"code.cloudfoundry.org/guardian/gardener"
@@ -21,12 +22,21 @@ const externalIpKey = gardener.ExternalIPKey
 // kawasaki-specific state properties
 const hostIntfKey = "kawasaki.host-interface"
 const containerIntfKey = "kawasaki.container-interface"
+const containerIntfNameKey = "kawasaki.container-interface-name"
 const bridgeIntfKey = "kawasaki.bridge-interface"
 const subnetKey = "kawasaki.subnet"
 const iptablePrefixKey = "kawasaki.iptable-prefix"
 const iptableInstanceKey = "kawasaki.iptable-inst"
 const mtuKey = "kawasaki.mtu"
 const dnsServerKey = "kawasaki.dns-servers"
+const additionalDNSServerKey = "kawasaki.additional-dns-servers"
+const modeKey = "kawasaki.mode"
+const pidKey = "kawasaki.pid"
+const netOutRulesKey = "kawasaki.netout-rules"
+
+// unrecordedRulesKey marks containers networked before their pid and NetOut
+// rules were recorded, whose iptables chains cannot be re-created
+const unrecordedRulesKey = "kawasaki.unrecorded-rules"
 
 //go:generate counterfeiter . SpecParser
 
@@ -44,8 +54,10 @@ type ConfigCreator interface {
 
 type Configurer interface {
 	Apply(log lager.Logger, cfg NetworkConfig, pid int) error
+	Repair(log lager.Logger, cfg NetworkConfig, pid int, recreateChains bool) (Repairs, error)
 	DestroyBridge(log lager.Logger, cfg NetworkConfig) error
 	DestroyIPTablesRules(log lager.Logger, cfg NetworkConfig) error
+	ConfigureDNS(log lager.Logger, cfg NetworkConfig, pid int) error
 }
 
 //go:generate counterfeiter . ConfigStore
@@ -58,9 +70,10 @@ type ConfigStore interface {
 //go:generate counterfeiter . PortPool
 
 type PortPool interface {
-	Acquire() (uint32, error)
-	Release(uint32)
-	Remove(uint32) error
+	Acquire(handle string) (uint32, error)
+	Release(port uint32)
+	ReleaseAll(handle string)
+	Remove(handle string, port uint32) error
 }
 
 //go:generate counterfeiter . PortForwarder
@@ -83,8 +96,12 @@ type PortForwarderSpec struct {
 type FirewallOpener interface {
 	Open(log lager.Logger, instance, handle string, rule garden.NetOutRule) error
 	BulkOpen(log lager.Logger, instance, handle string, rule []garden.NetOutRule) error
+	ReplaceDNSRules(log lager.Logger, instance, handle string, rules []garden.NetOutRule) error
 }
 
+// PidLookup returns the pid of a container's init process
+type PidLookup func(log lager.Logger, handle string) (int, error)
+
 //go:generate counterfeiter . Networker
 
 type Networker interface {
@@ -95,6 +112,7 @@ type Networker interface {
 	NetOut(log lager.Logger, handle string, rule garden.NetOutRule) error
 	BulkNetOut(log lager.Logger, handle string, rules []garden.NetOutRule) error
 	Restore(log lager.Logger, handle string) error
+	Verify(log lager.Logger, handle string) error
 }
 
 type networker struct {
@@ -106,6 +124,16 @@ type networker struct {
 	portPool       PortPool
 	firewallOpener FirewallOpener
 	configurer     Configurer
+	direct         *DirectNetwork
+	hostnameRules  HostnameRules
+	externalIPs    []net.IP
+	maxMtu         int
+	containerPid   PidLookup
+
+	// held while verifying, and while destroying a container or changing its
+	// rules, so that a repair neither races with destruction nor misses rules
+	// added while it re-creates the container's chains
+	verifyMu sync.Mutex
 }
 
 func New(
@@ -117,6 +145,11 @@ func New(
 	portPool PortPool,
 	portForwarder PortForwarder,
 	firewallOpener FirewallOpener,
+	direct *DirectNetwork,
+	hostnameRules HostnameRules,
+	externalIPs []net.IP,
+	maxMtu int,
+	containerPid PidLookup,
 ) *networker {
 	return &networker{
 		specParser:    specParser,
@@ -129,6 +162,16 @@ func New(
 		portPool:      portPool,
 
 		firewallOpener: firewallOpener,
+
+		direct: direct,
+
+		hostnameRules: hostnameRules,
+
+		externalIPs: externalIPs,
+
+		maxMtu: maxMtu,
+
+		containerPid: containerPid,
 	}
 }
 
@@ -141,6 +184,56 @@ func (n *networker) Network(log lager.Logger, containerSpec garden.ContainerSpec
 	log.Info("started")
 	defer log.Info("finished")
 
+	overrides, err := ParseOverrides(containerSpec.Properties)
+	if err != nil {
+		log.Error("parse-overrides-failed", err)
+		return err
+	}
+
+	hostnameRules, err := ParseHostnameRules(containerSpec.Properties[gardener.NetOutHostnamesKey])
+	if err != nil {
+		log.Error("parse-hostname-rules-failed", err)
+		return err
+	}
+
+	externalIPs, err := ParseExternalIPSelection(containerSpec.Properties, n.externalIPs)
+	if err != nil {
+		log.Error("parse-external-ips-failed", err)
+		return err
+	}
+
+	connectionLimits, err := ParseConnectionLimits(containerSpec.Properties)
+	if err != nil {
+		log.Error("parse-connection-limits-failed", err)
+		return err
+	}
+
+	interfaceOptions, err := ParseInterfaceOptions(containerSpec.Properties)
+	if err != nil {
+		log.Error("parse-interface-options-failed", err)
+		return err
+	}
+
+	if mode := gardener.NetworkMode(containerSpec); IsDirectMode(mode) {
+		if !overrides.Empty() {
+			return fmt.Errorf("network overrides are not supported in network mode %s", mode)
+		}
+
+		if externalIPs.Default != nil || len(externalIPs.ByContainerPort) > 0 {
+			return fmt.Errorf("external IPs are not supported in network mode %s", mode)
+		}
+
+		if !connectionLimits.Empty() {
+			return fmt.Errorf("connection limits are not supported in network mode %s", mode)
+		}
+
+		if len(hostnameRules) > 0 {
+			return fmt.Errorf("NetOut hostnames are not supported in network mode %s", mode)
+		}
+
+		return n.networkDirect(log, containerSpec, mode, interfaceOptions, pid)
+	}
+
 	subnetReq, ipReq, err := n.specParser.Parse(log, containerSpec.Network)
 	if err != nil {
 		log.Error("parse-failed", err)
@@ -158,6 +251,12 @@ func (n *networker) Network(log lager.Logger, containerSpec garden.ContainerSpec
 		log.Error("create-config-failed", err)
 		return fmt.Errorf("create network config: %s", err)
 	}
+	config.Overrides = overrides
+	config.ConnectionLimits = connectionLimits
+	interfaceOptions.Apply(&config, n.maxMtu)
+	if externalIPs.Default != nil {
+		config.ExternalIP = externalIPs.Default
+	}
 	log.Info("config-create", lager.Data{"config": config})
 
 	if err := save(n.configStore, containerSpec.Handle, config); err != nil {
@@ -169,7 +268,7 @@ func (n *networker) Network(log lager.Logger, containerSpec garden.ContainerSpec
 	}
 
 	for _, netIn := range containerSpec.NetIn {
-		if _, _, err := n.NetIn(log, containerSpec.Handle, netIn.HostPort, netIn.ContainerPort); err != nil {
+		if _, _, err := n.netIn(log, containerSpec.Handle, config, externalIPs, netIn.HostPort, netIn.ContainerPort); err != nil {
 			return err
 		}
 	}
@@ -178,22 +277,113 @@ func (n *networker) Network(log lager.Logger, containerSpec garden.ContainerSpec
 		return err
 	}
 
+	if len(hostnameRules) > 0 {
+		if err := n.hostnameRules.Apply(log, config, hostnameRules); err != nil {
+			log.Error("apply-hostname-rules-failed", err)
+			return err
+		}
+	}
+
+	// recorded last, so that only fully configured networks are verified
+	n.configStore.Set(containerSpec.Handle, pidKey, strconv.Itoa(pid))
+
+	return nil
+}
+
+func (n *networker) networkDirect(log lager.Logger, containerSpec garden.ContainerSpec, mode string, interfaceOptions InterfaceOptions, pid int) error {
+	if n.direct == nil {
+		return fmt.Errorf("network mode %s is not enabled", mode)
+	}
+
+	if len(containerSpec.NetIn) > 0 || len(containerSpec.NetOut) > 0 {
+		return fmt.Errorf("NetIn and NetOut are not supported in network mode %s", mode)
+	}
+
+	_, ip, err := n.direct.Pool.Acquire(log, rangeSelector{n.direct.Range}, subnets.DynamicIPSelector)
+	if err != nil {
+		log.Error("acquire-failed", err)
+		return err
+	}
+
+	config, err := n.configCreator.Create(log, containerSpec.Handle, n.direct.Subnet, ip)
+	if err != nil {
+		log.Error("create-config-failed", err)
+		return fmt.Errorf("create network config: %s", err)
+	}
+
+	// the container is reachable on the host's network without NAT and
+	// without a bridge
+	config.Mode = mode
+	config.HostIntf = n.direct.Interface
+	config.BridgeName = ""
+	config.BridgeIP = n.direct.Gateway
+	config.ExternalIP = ip
+	interfaceOptions.Apply(&config, n.maxMtu)
+	log.Info("config-create", lager.Data{"config": config})
+
+	if err := save(n.configStore, containerSpec.Handle, config); err != nil {
+		return err
+	}
+
+	if err := n.configurer.Apply(log, config, pid); err != nil {
+		return err
+	}
+
+	n.configStore.Set(containerSpec.Handle, pidKey, strconv.Itoa(pid))
+
 	return nil
 }
 
+// ShareNetwork configures the DNS of a container which joins the network of
+// another, which owns it, from the owner's network config
+func (n *networker) ShareNetwork(log lager.Logger, owner, handle string, pid int) error {
+	log = log.Session("share-network", lager.Data{"owner": owner, "handle": handle})
+
+	log.Info("started")
+	defer log.Info("finished")
+
+	cfg, err := load(n.configStore, owner)
+	if err != nil {
+		log.Error("loading-config-failed", err)
+		return fmt.Errorf("loading %s: %v", owner, err)
+	}
+	cfg.ContainerHandle = handle
+
+	return n.configurer.ConfigureDNS(log, cfg, pid)
+}
+
 // Capacity returns the number of subnets this network can host
 func (n *networker) Capacity() uint64 {
 	return uint64(n.subnetPool.Capacity())
 }
 
 func (n *networker) NetIn(log lager.Logger, handle string, externalPort, containerPort uint32) (uint32, uint32, error) {
+	n.verifyMu.Lock()
+	defer n.verifyMu.Unlock()
+
 	cfg, err := load(n.configStore, handle)
 	if err != nil {
 		return 0, 0, err
 	}
 
+	if IsDirectMode(cfg.Mode) {
+		return 0, 0, fmt.Errorf("NetIn is not supported in network mode %s", cfg.Mode)
+	}
+
+	// the container's default external IP is already in its config
+	netInExternalIPs, _ := n.configStore.Get(handle, gardener.NetInExternalIPsKey)
+	externalIPs, err := ParseExternalIPSelection(garden.Properties{gardener.NetInExternalIPsKey: netInExternalIPs}, n.externalIPs)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return n.netIn(log, handle, cfg, externalIPs, externalPort, containerPort)
+}
+
+func (n *networker) netIn(log lager.Logger, handle string, cfg NetworkConfig, externalIPs ExternalIPSelection, externalPort, containerPort uint32) (uint32, uint32, error) {
 	if externalPort == 0 {
-		externalPort, err = n.portPool.Acquire()
+		var err error
+		externalPort, err = n.portPool.Acquire(handle)
 		if err != nil {
 			return 0, 0, err
 		}
@@ -203,20 +393,21 @@ func (n *networker) NetIn(log lager.Logger, handle string, externalPort, contain
 		containerPort = externalPort
 	}
 
-	err = n.portForwarder.Forward(PortForwarderSpec{
+	externalIP := externalIPs.ExternalIP(containerPort, cfg.ExternalIP)
+
+	if err := n.portForwarder.Forward(PortForwarderSpec{
 		InstanceID:  cfg.IPTableInstance,
 		Handle:      handle,
 		FromPort:    externalPort,
 		ToPort:      containerPort,
 		ContainerIP: cfg.ContainerIP,
-		ExternalIP:  cfg.ExternalIP,
-	})
-
-	if err != nil {
+		ExternalIP:  externalIP,
+	}); err != nil {
 		return 0, 0, err
 	}
 
-	if err := AddPortMapping(log, n.configStore, handle, garden.PortMapping{
+	if err := AddPortMapping(log, n.configStore, handle, PortMapping{
+		HostIP:        externalIP.String(),
 		HostPort:      externalPort,
 		ContainerPort: containerPort,
 	}); err != nil {
@@ -227,30 +418,82 @@ func (n *networker) NetIn(log lager.Logger, handle string, externalPort, contain
 }
 
 func (n *networker) NetOut(log lager.Logger, handle string, rule garden.NetOutRule) error {
+	n.verifyMu.Lock()
+	defer n.verifyMu.Unlock()
+
 	cfg, err := load(n.configStore, handle)
 	if err != nil {
 		return err
 	}
 
-	return n.firewallOpener.Open(log, cfg.IPTableInstance, handle, rule)
+	if IsDirectMode(cfg.Mode) {
+		return fmt.Errorf("NetOut is not supported in network mode %s", cfg.Mode)
+	}
+
+	if err := n.firewallOpener.Open(log, cfg.IPTableInstance, handle, rule); err != nil {
+		return err
+	}
+
+	return addNetOutRules(n.configStore, handle, []garden.NetOutRule{rule})
 }
 
 func (n *networker) BulkNetOut(log lager.Logger, handle string, rules []garden.NetOutRule) error {
+	n.verifyMu.Lock()
+	defer n.verifyMu.Unlock()
+
 	cfg, err := load(n.configStore, handle)
 	if err != nil {
 		return err
 	}
 
-	return n.firewallOpener.BulkOpen(log, cfg.IPTableInstance, handle, rules)
+	if IsDirectMode(cfg.Mode) {
+		return fmt.Errorf("NetOut is not supported in network mode %s", cfg.Mode)
+	}
+
+	if err := n.firewallOpener.BulkOpen(log, cfg.IPTableInstance, handle, rules); err != nil {
+		return err
+	}
+
+	if err := addNetOutRules(n.configStore, handle, rules); err != nil {
+		return err
+	}
+
+	// the hostname rules property, if set since creation, is applied alongside
+	value, ok := n.configStore.Get(handle, gardener.NetOutHostnamesKey)
+	if !ok {
+		return nil
+	}
+
+	hostnameRules, err := ParseHostnameRules(value)
+	if err != nil {
+		return err
+	}
+
+	return n.hostnameRules.Apply(log, cfg, hostnameRules)
 }
 
 func (n *networker) Destroy(log lager.Logger, handle string) error {
+	n.verifyMu.Lock()
+	defer n.verifyMu.Unlock()
+
+	// the network is no longer verified, even if destroying it fails part way
+	if _, ok := n.configStore.Get(handle, pidKey); ok {
+		n.configStore.Set(handle, pidKey, "")
+	}
+
 	cfg, err := load(n.configStore, handle)
 	if err != nil {
 		log.Error("no-properties-for-container-skipping-destroy-network", err)
+		n.portPool.ReleaseAll(handle)
 		return nil
 	}
 
+	if IsDirectMode(cfg.Mode) {
+		return n.destroyDirect(log, cfg)
+	}
+
+	n.hostnameRules.Forget(handle)
+
 	if err := n.configurer.DestroyIPTablesRules(log, cfg); err != nil {
 		return err
 	}
@@ -260,16 +503,7 @@ func (n *networker) Destroy(log lager.Logger, handle string) error {
 		return err
 	}
 
-	if ports, ok := n.configStore.Get(handle, gardener.MappedPortsKey); ok {
-		mappings, err := portsFromJson(ports)
-		if err != nil {
-			return err
-		}
-
-		for _, m := range mappings {
-			n.portPool.Release(m.HostPort)
-		}
-	}
+	n.portPool.ReleaseAll(handle)
 
 	err = n.subnetPool.RunIfFree(cfg.Subnet, func() error {
 		return n.configurer.DestroyBridge(log, cfg)
@@ -278,37 +512,230 @@ func (n *networker) Destroy(log lager.Logger, handle string) error {
 	return err
 }
 
+func (n *networker) destroyDirect(log lager.Logger, cfg NetworkConfig) error {
+	if n.direct == nil {
+		return fmt.Errorf("network mode %s is not enabled", cfg.Mode)
+	}
+
+	if err := n.direct.Pool.Release(n.direct.Range, cfg.ContainerIP); err != nil && err != subnets.ErrReleasedUnallocatedSubnet {
+		log.Error("release-failed", err)
+		return err
+	}
+
+	return nil
+}
+
 func (n *networker) Restore(log lager.Logger, handle string) error {
 	networkConfig, err := load(n.configStore, handle)
 	if err != nil {
 		return fmt.Errorf("loading %s: %v", handle, err)
 	}
 
+	if IsDirectMode(networkConfig.Mode) {
+		if n.direct == nil {
+			return fmt.Errorf("network mode %s is not enabled", networkConfig.Mode)
+		}
+
+		if err := n.direct.Pool.Remove(n.direct.Range, networkConfig.ContainerIP); err != nil {
+			return fmt.Errorf("direct pool removing %s: %v", handle, err)
+		}
+
+		return nil
+	}
+
 	err = n.subnetPool.Remove(networkConfig.Subnet, networkConfig.ContainerIP)
 	if err != nil {
 		return fmt.Errorf("subnet pool removing %s: %v", handle, err)
 	}
 
+	if value, ok := n.configStore.Get(handle, gardener.NetOutHostnamesKey); ok {
+		hostnameRules, err := ParseHostnameRules(value)
+		if err != nil {
+			return fmt.Errorf("parsing hostname rules %s: %v", handle, err)
+		}
+
+		n.hostnameRules.Restore(log, networkConfig, hostnameRules)
+	}
+
+	if _, ok := n.configStore.Get(handle, pidKey); !ok {
+		n.recordPid(log, handle)
+	}
+
+	// the kernel's network objects may have been removed while the server was
+	// down, but the container has been running without them already so is
+	// not failed if they cannot be re-created
+	pid, _ := n.pid(handle)
+	if err := n.repair(log, handle, networkConfig, pid); err != nil {
+		log.Error("repair-failed", err, lager.Data{"handle": handle})
+	}
+
 	currentMappingsJson, ok := n.configStore.Get(handle, gardener.MappedPortsKey)
 	if !ok {
 		return nil
 	}
 
+	// the port pool persists its own allocations, so a bad mapping is only
+	// worth logging rather than failing the whole restore
 	currentMappings, err := portsFromJson(currentMappingsJson)
 	if err != nil {
-		return fmt.Errorf("unmarshaling port mappings %s: %v", handle, err)
+		log.Error("parsing-port-mappings-failed", err, lager.Data{"handle": handle})
+		return nil
 	}
 
 	for _, mapping := range currentMappings {
-		if err = n.portPool.Remove(mapping.HostPort); err != nil {
-			return fmt.Errorf("port pool removing %s: %v", handle, err)
+		if err = n.portPool.Remove(handle, mapping.HostPort); err != nil {
+			log.Error("port-pool-remove-failed", err, lager.Data{"handle": handle, "port": mapping.HostPort})
+		}
+	}
+
+	return nil
+}
+
+// Verify re-creates whichever of a container's interfaces and iptables chains
+// no longer exist, e.g. because the host's firewall was reloaded. Containers
+// whose network is not fully configured are skipped.
+func (n *networker) Verify(log lager.Logger, handle string) error {
+	n.verifyMu.Lock()
+	defer n.verifyMu.Unlock()
+
+	pid, ok := n.pid(handle)
+	if !ok {
+		return nil
+	}
+
+	cfg, err := load(n.configStore, handle)
+	if err != nil {
+		return fmt.Errorf("loading %s: %v", handle, err)
+	}
+
+	return n.repair(log, handle, cfg, pid)
+}
+
+// recordPid records the pid of a container networked before pids were
+// recorded, so that its network is verified. The rules of its NetOut calls
+// were not recorded either, so its iptables chains are marked as impossible
+// to re-create.
+func (n *networker) recordPid(log lager.Logger, handle string) {
+	n.configStore.Set(handle, unrecordedRulesKey, "true")
+
+	pid, err := n.containerPid(log, handle)
+	if err != nil {
+		log.Error("looking-up-pid-failed", err, lager.Data{"handle": handle})
+		return
+	}
+
+	n.configStore.Set(handle, pidKey, strconv.Itoa(pid))
+}
+
+func (n *networker) pid(handle string) (int, bool) {
+	value, ok := n.configStore.Get(handle, pidKey)
+	if !ok {
+		return 0, false
+	}
+
+	pid, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return pid, true
+}
+
+// repair re-creates a container's missing kernel network objects and, if its
+// iptables chains were re-created, re-installs the rules of its NetIn and
+// NetOut calls. The chains of containers whose NetOut rules were not recorded
+// are not re-created, as the rules would be lost.
+func (n *networker) repair(log lager.Logger, handle string, cfg NetworkConfig, pid int) error {
+	log = log.Session("repair", lager.Data{"handle": handle})
+
+	properties := garden.Properties{}
+	for _, key := range []string{
+		gardener.NetworkAllowHostAccessKey,
+		gardener.NetworkDenyNetworksKey,
+		gardener.NetworkMaxNewConnectionsPerSecondKey,
+		gardener.NetworkMaxConnectionsKey,
+	} {
+		if value, ok := n.configStore.Get(handle, key); ok {
+			properties[key] = value
+		}
+	}
+
+	var err error
+	cfg.ContainerHandle = handle
+	if cfg.Overrides, err = ParseOverrides(properties); err != nil {
+		return err
+	}
+
+	if cfg.ConnectionLimits, err = ParseConnectionLimits(properties); err != nil {
+		return err
+	}
+
+	_, unrecorded := n.configStore.Get(handle, unrecordedRulesKey)
+	repairs, err := n.configurer.Repair(log, cfg, pid, !unrecorded)
+	if err != nil {
+		return err
+	}
+
+	if repairs.Empty() {
+		return nil
+	}
+
+	log.Info("repaired", lager.Data{"repairs": repairs})
+
+	if !repairs.IPTablesRules {
+		return nil
+	}
+
+	if value, ok := n.configStore.Get(handle, gardener.MappedPortsKey); ok {
+		mappings, err := portsFromJson(value)
+		if err != nil {
+			return err
+		}
+
+		for _, mapping := range mappings {
+			// mappings recorded before their external IP was, used the default
+			externalIP := net.ParseIP(mapping.HostIP)
+			if externalIP == nil {
+				externalIP = cfg.ExternalIP
+			}
+
+			if err := n.portForwarder.Forward(PortForwarderSpec{
+				InstanceID:  cfg.IPTableInstance,
+				Handle:      handle,
+				FromPort:    mapping.HostPort,
+				ToPort:      mapping.ContainerPort,
+				ContainerIP: cfg.ContainerIP,
+				ExternalIP:  externalIP,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if value, ok := n.configStore.Get(handle, netOutRulesKey); ok {
+		var rules []garden.NetOutRule
+		if err := json.Unmarshal([]byte(value), &rules); err != nil {
+			return err
+		}
+
+		if err := n.firewallOpener.BulkOpen(log, cfg.IPTableInstance, handle, rules); err != nil {
+			return err
+		}
+	}
+
+	if value, ok := n.configStore.Get(handle, gardener.NetOutHostnamesKey); ok {
+		hostnameRules, err := ParseHostnameRules(value)
+		if err != nil {
+			return err
 		}
+
+		return n.hostnameRules.Apply(log, cfg, hostnameRules)
 	}
 
 	return nil
 }
 
-func AddPortMapping(logger lager.Logger, configStore ConfigStore, handle string, newMapping garden.PortMapping) error {
+func AddPortMapping(logger lager.Logger, configStore ConfigStore, handle string, newMapping PortMapping) error {
 	var currentMappings portMappingList
 	if currentMappingsJson, ok := configStore.Get(handle, gardener.MappedPortsKey); ok {
 		var err error
@@ -323,6 +750,39 @@ func AddPortMapping(logger lager.Logger, configStore ConfigStore, handle string,
 	return nil
 }
 
+// PortMappings returns the port mappings recorded for a container, if any
+func PortMappings(configStore ConfigStore, handle string) ([]PortMapping, error) {
+	currentMappingsJson, ok := configStore.Get(handle, gardener.MappedPortsKey)
+	if !ok {
+		return nil, nil
+	}
+
+	return portsFromJson(currentMappingsJson)
+}
+
+// addNetOutRules records the NetOut rules opened for a container, so that they
+// can be re-installed if its iptables chains are lost
+func addNetOutRules(configStore ConfigStore, handle string, newRules []garden.NetOutRule) error {
+	if len(newRules) == 0 {
+		return nil
+	}
+
+	var rules []garden.NetOutRule
+	if value, ok := configStore.Get(handle, netOutRulesKey); ok {
+		if err := json.Unmarshal([]byte(value), &rules); err != nil {
+			return err
+		}
+	}
+
+	b, err := json.Marshal(append(rules, newRules...))
+	if err != nil {
+		return err
+	}
+
+	configStore.Set(handle, netOutRulesKey, string(b))
+	return nil
+}
+
 func getAll(config ConfigStore, handle string, key ...string) (vals []string, err error) {
 	for _, k := range key {
 		v, ok := config.Get(handle, k)
@@ -355,6 +815,23 @@ func save(config ConfigStore, handle string, netConfig NetworkConfig) error {
 
 	config.Set(handle, dnsServerKey, strings.Join(dnsServers, ", "))
 
+	if len(netConfig.AdditionalDNSServers) > 0 {
+		var additionalDNSServers []string
+		for _, dnsServer := range netConfig.AdditionalDNSServers {
+			additionalDNSServers = append(additionalDNSServers, dnsServer.String())
+		}
+
+		config.Set(handle, additionalDNSServerKey, strings.Join(additionalDNSServers, ", "))
+	}
+
+	if netConfig.Mode != "" {
+		config.Set(handle, modeKey, netConfig.Mode)
+	}
+
+	if netConfig.ContainerIntfName != "" {
+		config.Set(handle, containerIntfNameKey, netConfig.ContainerIntfName)
+	}
+
 	return nil
 }
 
@@ -382,20 +859,22 @@ func load(config ConfigStore, handle string) (NetworkConfig, error) {
 		return NetworkConfig{}, err
 	}
 
-	var dnsServers []net.IP
-	for _, dnsServerName := range strings.Split(vals[10], ",") {
-		dnsServerName = strings.TrimSpace(dnsServerName)
-		if dnsServerName == "" {
-			continue
-		}
-		ip := net.ParseIP(dnsServerName)
-		if ip == nil {
-			return NetworkConfig{}, fmt.Errorf("Failed to parse DNS server IP address %s", dnsServerName)
-		}
-		dnsServers = append(dnsServers, ip)
+	dnsServers, err := parseDNSServers(vals[10])
+	if err != nil {
+		return NetworkConfig{}, err
+	}
+
+	additional, _ := config.Get(handle, additionalDNSServerKey)
+	additionalDNSServers, err := parseDNSServers(additional)
+	if err != nil {
+		return NetworkConfig{}, err
 	}
 
+	mode, _ := config.Get(handle, modeKey)
+	containerIntfName, _ := config.Get(handle, containerIntfNameKey)
+
 	return NetworkConfig{
+		Mode:            mode,
 		HostIntf:        vals[0],
 		ContainerIntf:   vals[1],
 		BridgeName:      vals[2],
@@ -407,10 +886,38 @@ func load(config ConfigStore, handle string) (NetworkConfig, error) {
 		IPTableInstance: vals[7],
 		Mtu:             mtu,
 		DNSServers:      dnsServers,
+
+		AdditionalDNSServers: additionalDNSServers,
+		ContainerIntfName:    containerIntfName,
 	}, nil
 }
 
-type portMappingList []garden.PortMapping
+func parseDNSServers(value string) ([]net.IP, error) {
+	var dnsServers []net.IP
+	for _, dnsServerName := range strings.Split(value, ",") {
+		dnsServerName = strings.TrimSpace(dnsServerName)
+		if dnsServerName == "" {
+			continue
+		}
+		ip := net.ParseIP(dnsServerName)
+		if ip == nil {
+			return nil, fmt.Errorf("Failed to parse DNS server IP address %s", dnsServerName)
+		}
+		dnsServers = append(dnsServers, ip)
+	}
+
+	return dnsServers, nil
+}
+
+// PortMapping is a garden.PortMapping which also records the external IP to
+// which the host port is bound
+type PortMapping struct {
+	HostPort      uint32
+	ContainerPort uint32
+	HostIP        string `json:",omitempty"`
+}
+
+type portMappingList []PortMapping
 
 func (l portMappingList) toJson() string {
 	b, err := json.Marshal(l)
@@ -13,6 +13,7 @@ import (
 	fakes "code.cloudfoundry.org/guardian/kawasaki/kawasakifakes"
 	"code.cloudfoundry.org/guardian/kawasaki/subnets"
 	"code.cloudfoundry.org/guardian/kawasaki/subnets/fake_subnet_pool"
+	"code.cloudfoundry.org/guardian/properties"
 	"code.cloudfoundry.org/lager"
 	"code.cloudfoundry.org/lager/lagertest"
 	. "github.com/onsi/ginkgo"
@@ -29,11 +30,19 @@ var _ = Describe("Networker", func() {
 		fakePortPool       *fakes.FakePortPool
 		fakeFirewallOpener *fakes.FakeFirewallOpener
 		fakeConfigurer     *fakes.FakeConfigurer
+		fakeHostnameRules  *fakes.FakeHostnameRules
+		fakeDirectPool     *fake_subnet_pool.FakePool
+		directNetwork      *kawasaki.DirectNetwork
 		containerSpec      garden.ContainerSpec
 		networker          kawasaki.Networker
 		logger             lager.Logger
 		networkConfig      kawasaki.NetworkConfig
 		config             map[string]string
+
+		// the pid which the containerizer reports for a container
+		containerPid       int
+		containerPidErr    error
+		lookupContainerPid kawasaki.PidLookup
 	)
 
 	BeforeEach(func() {
@@ -45,6 +54,25 @@ var _ = Describe("Networker", func() {
 		fakePortPool = new(fakes.FakePortPool)
 		fakeFirewallOpener = new(fakes.FakeFirewallOpener)
 		fakeConfigurer = new(fakes.FakeConfigurer)
+		fakeHostnameRules = new(fakes.FakeHostnameRules)
+		fakeDirectPool = new(fake_subnet_pool.FakePool)
+
+		containerPid, containerPidErr = 0, nil
+		lookupContainerPid = func(_ lager.Logger, handle string) (int, error) {
+			return containerPid, containerPidErr
+		}
+
+		_, directSubnet, err := net.ParseCIDR("10.0.0.0/16")
+		Expect(err).NotTo(HaveOccurred())
+		_, directRange, err := net.ParseCIDR("10.0.5.0/24")
+		Expect(err).NotTo(HaveOccurred())
+		directNetwork = &kawasaki.DirectNetwork{
+			Interface: "eth1",
+			Subnet:    directSubnet,
+			Gateway:   net.ParseIP("10.0.0.1"),
+			Range:     directRange,
+			Pool:      fakeDirectPool,
+		}
 
 		containerSpec = garden.ContainerSpec{
 			Handle:  "some-handle",
@@ -83,6 +111,11 @@ var _ = Describe("Networker", func() {
 			fakePortPool,
 			fakePortForwarder,
 			fakeFirewallOpener,
+			directNetwork,
+			fakeHostnameRules,
+			[]net.IP{net.ParseIP("128.128.90.90"), net.ParseIP("128.128.90.91"), net.ParseIP("128.128.90.92")},
+			1500,
+			lookupContainerPid,
 		)
 
 		ip, subnet, err := net.ParseCIDR("123.123.123.12/24")
@@ -208,11 +241,242 @@ var _ = Describe("Networker", func() {
 			Expect(pid).To(Equal(42))
 		})
 
+		Context("when network overrides are given as properties", func() {
+			BeforeEach(func() {
+				containerSpec.Properties = garden.Properties{
+					gardener.NetworkAllowHostAccessKey: "true",
+					gardener.NetworkDenyNetworksKey:    "10.0.0.0/8, 192.168.0.0/16",
+				}
+			})
+
+			It("applies the configuration with the overrides", func() {
+				Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+				Expect(fakeConfigurer.ApplyCallCount()).To(Equal(1))
+				_, actualNetConfig, _ := fakeConfigurer.ApplyArgsForCall(0)
+				Expect(actualNetConfig.Overrides.AllowHostAccess).To(BeTrue())
+				Expect(actualNetConfig.Overrides.DenyNetworks).To(HaveLen(2))
+				Expect(actualNetConfig.Overrides.DenyNetworks[0].String()).To(Equal("10.0.0.0/8"))
+				Expect(actualNetConfig.Overrides.DenyNetworks[1].String()).To(Equal("192.168.0.0/16"))
+			})
+
+			Context("when the overrides are invalid", func() {
+				BeforeEach(func() {
+					containerSpec.Properties[gardener.NetworkDenyNetworksKey] = "not-a-cidr"
+				})
+
+				It("returns an error before acquiring an IP", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(MatchError(ContainSubstring("invalid network")))
+					Expect(fakeSubnetPool.AcquireCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when external IPs are given as properties", func() {
+			BeforeEach(func() {
+				containerSpec.Properties = garden.Properties{
+					gardener.DefaultExternalIPKey: "128.128.90.91",
+					gardener.NetInExternalIPsKey:  `{"8081": "128.128.90.92"}`,
+				}
+			})
+
+			It("uses the default external IP as the container's external IP", func() {
+				Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+				_, actualNetConfig, _ := fakeConfigurer.ApplyArgsForCall(0)
+				Expect(actualNetConfig.ExternalIP.String()).To(Equal("128.128.90.91"))
+			})
+
+			It("binds the spec's NetIn mappings to the chosen external IPs", func() {
+				Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+				Expect(fakePortForwarder.ForwardCallCount()).To(Equal(2))
+				Expect(fakePortForwarder.ForwardArgsForCall(0).ExternalIP.String()).To(Equal("128.128.90.91"))
+				Expect(fakePortForwarder.ForwardArgsForCall(1).ExternalIP.String()).To(Equal("128.128.90.92"))
+			})
+
+			Context("when an external IP is not one of the server's", func() {
+				BeforeEach(func() {
+					containerSpec.Properties[gardener.DefaultExternalIPKey] = "1.2.3.4"
+				})
+
+				It("returns an error before acquiring an IP", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(MatchError("invalid value for garden.network.default-external-ip: 1.2.3.4 is not an external IP of the server"))
+					Expect(fakeSubnetPool.AcquireCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when connection limits are given as properties", func() {
+			BeforeEach(func() {
+				containerSpec.Properties = garden.Properties{
+					gardener.NetworkMaxNewConnectionsPerSecondKey: "50",
+					gardener.NetworkMaxConnectionsKey:             "200",
+				}
+			})
+
+			It("applies the configuration with the connection limits", func() {
+				Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+				_, actualNetConfig, _ := fakeConfigurer.ApplyArgsForCall(0)
+				Expect(actualNetConfig.ConnectionLimits).To(Equal(kawasaki.ConnectionLimits{
+					NewConnectionsPerSecond: 50,
+					MaxConnections:          200,
+				}))
+			})
+
+			Context("when the connection limits are invalid", func() {
+				BeforeEach(func() {
+					containerSpec.Properties[gardener.NetworkMaxConnectionsKey] = "lots"
+				})
+
+				It("returns an error before acquiring an IP", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(MatchError("invalid value for garden.network.max-connections: lots"))
+					Expect(fakeSubnetPool.AcquireCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		Context("when interface options are given as properties", func() {
+			BeforeEach(func() {
+				containerSpec.Properties = garden.Properties{
+					gardener.NetworkMtuKey:           "1400",
+					gardener.NetworkInterfaceNameKey: "eth0",
+				}
+			})
+
+			It("applies the configuration with the interface options", func() {
+				Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+				_, actualNetConfig, _ := fakeConfigurer.ApplyArgsForCall(0)
+				Expect(actualNetConfig.Mtu).To(Equal(1400))
+				Expect(actualNetConfig.ContainerIntf).To(Equal(networkConfig.ContainerIntf))
+				Expect(actualNetConfig.ContainerIntfName).To(Equal("eth0"))
+			})
+
+			It("stores the interface options", func() {
+				config := make(map[string]string)
+				fakeConfigStore.SetStub = func(handle, name, value string) {
+					config[name] = value
+				}
+
+				Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+				Expect(config["kawasaki.mtu"]).To(Equal("1400"))
+				Expect(config["kawasaki.container-interface-name"]).To(Equal("eth0"))
+			})
+
+			Context("when the MTU is larger than the uplink's", func() {
+				BeforeEach(func() {
+					containerSpec.Properties[gardener.NetworkMtuKey] = "9000"
+				})
+
+				It("clamps the MTU to the uplink's", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+					_, actualNetConfig, _ := fakeConfigurer.ApplyArgsForCall(0)
+					Expect(actualNetConfig.Mtu).To(Equal(1500))
+				})
+			})
+
+			Context("when the interface options are invalid", func() {
+				BeforeEach(func() {
+					containerSpec.Properties[gardener.NetworkInterfaceNameKey] = "a/b"
+				})
+
+				It("returns an error before acquiring an IP", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(MatchError("invalid value for garden.network.interface-name: a/b"))
+					Expect(fakeSubnetPool.AcquireCallCount()).To(Equal(0))
+				})
+			})
+		})
+
+		It("does not apply hostname rules when none are given", func() {
+			Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+			Expect(fakeHostnameRules.ApplyCallCount()).To(Equal(0))
+		})
+
+		Context("when NetOut hostnames are given as a property", func() {
+			BeforeEach(func() {
+				containerSpec.Properties = garden.Properties{
+					gardener.NetOutHostnamesKey: `[{"protocol":1,"hostnames":["example.com"],"ports":[{"start":443,"end":443}]}]`,
+				}
+			})
+
+			It("applies the hostname rules with the container's config", func() {
+				Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+				Expect(fakeHostnameRules.ApplyCallCount()).To(Equal(1))
+				_, cfg, rules := fakeHostnameRules.ApplyArgsForCall(0)
+				Expect(cfg.IPTableInstance).To(Equal(networkConfig.IPTableInstance))
+				Expect(rules).To(Equal([]kawasaki.HostnameRule{{
+					Protocol:  garden.ProtocolTCP,
+					Hostnames: []string{"example.com"},
+					Ports:     []garden.PortRange{garden.PortRangeFromPort(443)},
+				}}))
+			})
+
+			Context("when applying the hostname rules fails", func() {
+				BeforeEach(func() {
+					fakeHostnameRules.ApplyReturns(errors.New("no-such-host"))
+				})
+
+				It("returns the error", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(MatchError("no-such-host"))
+				})
+			})
+
+			Context("when the property is invalid", func() {
+				BeforeEach(func() {
+					containerSpec.Properties[gardener.NetOutHostnamesKey] = "not-json"
+				})
+
+				It("returns an error before acquiring an IP", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(MatchError(ContainSubstring("parsing hostname rules")))
+					Expect(fakeSubnetPool.AcquireCallCount()).To(Equal(0))
+				})
+			})
+		})
+
 		Context("when the configurer fails to apply the config", func() {
 			It("errors", func() {
 				fakeConfigurer.ApplyReturns(errors.New("wont-apply"))
 				Expect(networker.Network(logger, containerSpec, 42)).To(MatchError("wont-apply"))
 			})
+
+			It("does not store the pid", func() {
+				fakeConfigurer.ApplyReturns(errors.New("wont-apply"))
+				networker.Network(logger, containerSpec, 42)
+
+				for i := 0; i < fakeConfigStore.SetCallCount(); i++ {
+					_, name, _ := fakeConfigStore.SetArgsForCall(i)
+					Expect(name).NotTo(Equal("kawasaki.pid"))
+				}
+			})
+		})
+
+		It("stores the pid once the network is configured", func() {
+			Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+			lastCall := fakeConfigStore.SetCallCount() - 1
+			handle, name, value := fakeConfigStore.SetArgsForCall(lastCall)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(name).To(Equal("kawasaki.pid"))
+			Expect(value).To(Equal("42"))
+		})
+
+		It("records the NetOut rules", func() {
+			stored := make(map[string]string)
+			fakeConfigStore.SetStub = func(handle, name, value string) {
+				stored[name] = value
+			}
+
+			Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+			var rules []garden.NetOutRule
+			Expect(json.Unmarshal([]byte(stored["kawasaki.netout-rules"]), &rules)).To(Succeed())
+			Expect(rules).To(HaveLen(2))
+			Expect(rules[0].Networks).To(Equal(containerSpec.NetOut[0].Networks))
+			Expect(rules[1].Networks).To(Equal(containerSpec.NetOut[1].Networks))
 		})
 
 		It("forwards any NetIn configuration via the port forwarder", func() {
@@ -301,34 +565,39 @@ var _ = Describe("Networker", func() {
 			})
 		})
 
-		Describe("releasing ports", func() {
-			Context("when there are no ports stored in the config", func() {
-				BeforeEach(func() {
-					delete(config, gardener.MappedPortsKey)
-				})
+		It("stops the network being verified", func() {
+			config["kawasaki.pid"] = "42"
 
-				It("does nothing when no ports are stored in the config", func() {
-					Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
-					Expect(fakePortPool.ReleaseCallCount()).To(Equal(0))
-				})
-			})
+			Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
 
-			It("destroys any ports named in the config", func() {
-				config[gardener.MappedPortsKey] = `[{"HostPort": 123}, {"HostPort": 456}]`
+			Expect(fakeConfigStore.SetCallCount()).To(Equal(1))
+			_, name, value := fakeConfigStore.SetArgsForCall(0)
+			Expect(name).To(Equal("kawasaki.pid"))
+			Expect(value).To(BeEmpty())
+		})
 
-				Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
-				Expect(fakePortPool.ReleaseCallCount()).To(Equal(2))
-				Expect(fakePortPool.ReleaseArgsForCall(0)).To(BeEquivalentTo(123))
-				Expect(fakePortPool.ReleaseArgsForCall(1)).To(BeEquivalentTo(456))
-			})
+		It("releases the container's ports", func() {
+			Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
+			Expect(fakePortPool.ReleaseAllCallCount()).To(Equal(1))
+			Expect(fakePortPool.ReleaseAllArgsForCall(0)).To(Equal("some-handle"))
+		})
 
-			It("returns an error if the ports property is not valid JSON", func() {
-				config[gardener.MappedPortsKey] = `potato`
-				Expect(networker.Destroy(logger, "some-handle")).To(MatchError(ContainSubstring("invalid")))
+		Context("when the store does not contain the properties for the container and it holds ports", func() {
+			It("still releases the ports", func() {
+				config = nil
+				Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
+				Expect(fakePortPool.ReleaseAllCallCount()).To(Equal(1))
+				Expect(fakePortPool.ReleaseAllArgsForCall(0)).To(Equal("some-handle"))
 			})
 		})
 
 		Describe("destroying network configuration", func() {
+			It("stops refreshing the container's hostname rules", func() {
+				Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
+				Expect(fakeHostnameRules.ForgetCallCount()).To(Equal(1))
+				Expect(fakeHostnameRules.ForgetArgsForCall(0)).To(Equal("some-handle"))
+			})
+
 			It("destroys iptables rules", func() {
 				Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
 				Expect(fakeConfigurer.DestroyIPTablesRulesCallCount()).To(Equal(1))
@@ -397,6 +666,32 @@ var _ = Describe("Networker", func() {
 			Expect(handleArg).To(Equal("some-handle"))
 			Expect(ruleArg).To(Equal(rule))
 		})
+
+		It("records the rule after any recorded previously", func() {
+			config["kawasaki.netout-rules"] = `[{"protocol":1}]`
+
+			Expect(networker.NetOut(logger, "some-handle", garden.NetOutRule{Protocol: garden.ProtocolICMP})).To(Succeed())
+
+			Expect(fakeConfigStore.SetCallCount()).To(Equal(1))
+			handle, name, value := fakeConfigStore.SetArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(name).To(Equal("kawasaki.netout-rules"))
+
+			var rules []garden.NetOutRule
+			Expect(json.Unmarshal([]byte(value), &rules)).To(Succeed())
+			Expect(rules).To(HaveLen(2))
+			Expect(rules[0].Protocol).To(Equal(garden.ProtocolTCP))
+			Expect(rules[1].Protocol).To(Equal(garden.ProtocolICMP))
+		})
+
+		Context("when opening the rule fails", func() {
+			It("does not record the rule", func() {
+				fakeFirewallOpener.OpenReturns(errors.New("potato"))
+
+				networker.NetOut(logger, "some-handle", garden.NetOutRule{Protocol: garden.ProtocolICMP})
+				Expect(fakeConfigStore.SetCallCount()).To(Equal(0))
+			})
+		})
 	})
 
 	Describe("BulkNetOut", func() {
@@ -414,6 +709,78 @@ var _ = Describe("Networker", func() {
 			Expect(handleArg).To(Equal("some-handle"))
 			Expect(rulesArg).To(Equal(rules))
 		})
+
+		It("records the rules", func() {
+			rules := []garden.NetOutRule{
+				{Protocol: garden.ProtocolICMP},
+				{Protocol: garden.ProtocolUDP},
+			}
+
+			Expect(networker.BulkNetOut(logger, "some-handle", rules)).To(Succeed())
+
+			Expect(fakeConfigStore.SetCallCount()).To(Equal(1))
+			_, name, value := fakeConfigStore.SetArgsForCall(0)
+			Expect(name).To(Equal("kawasaki.netout-rules"))
+
+			var recorded []garden.NetOutRule
+			Expect(json.Unmarshal([]byte(value), &recorded)).To(Succeed())
+			Expect(recorded).To(Equal(rules))
+		})
+
+		It("does not record an empty list of rules", func() {
+			Expect(networker.BulkNetOut(logger, "some-handle", nil)).To(Succeed())
+			Expect(fakeConfigStore.SetCallCount()).To(Equal(0))
+		})
+
+		It("does not apply hostname rules when the property is not set", func() {
+			Expect(networker.BulkNetOut(logger, "some-handle", nil)).To(Succeed())
+			Expect(fakeHostnameRules.ApplyCallCount()).To(Equal(0))
+		})
+
+		Context("when the NetOut hostnames property is set", func() {
+			BeforeEach(func() {
+				config[gardener.NetOutHostnamesKey] = `[{"hostnames":["example.com","example.org"]}]`
+			})
+
+			It("applies the hostname rules", func() {
+				Expect(networker.BulkNetOut(logger, "some-handle", nil)).To(Succeed())
+
+				Expect(fakeHostnameRules.ApplyCallCount()).To(Equal(1))
+				_, cfg, rules := fakeHostnameRules.ApplyArgsForCall(0)
+				Expect(cfg.IPTableInstance).To(Equal(networkConfig.IPTableInstance))
+				Expect(cfg.DNSServers).To(Equal(networkConfig.DNSServers))
+				Expect(rules).To(Equal([]kawasaki.HostnameRule{{Hostnames: []string{"example.com", "example.org"}}}))
+			})
+
+			It("passes the container's additional DNS servers", func() {
+				config["kawasaki.additional-dns-servers"] = "1.1.1.1"
+
+				Expect(networker.BulkNetOut(logger, "some-handle", nil)).To(Succeed())
+				_, cfg, _ := fakeHostnameRules.ApplyArgsForCall(0)
+				Expect(cfg.AdditionalDNSServers).To(Equal([]net.IP{net.ParseIP("1.1.1.1")}))
+			})
+
+			Context("when applying the hostname rules fails", func() {
+				BeforeEach(func() {
+					fakeHostnameRules.ApplyReturns(errors.New("no-such-host"))
+				})
+
+				It("returns the error", func() {
+					Expect(networker.BulkNetOut(logger, "some-handle", nil)).To(MatchError("no-such-host"))
+				})
+			})
+
+			Context("when opening the rules fails", func() {
+				BeforeEach(func() {
+					fakeFirewallOpener.BulkOpenReturns(errors.New("potato"))
+				})
+
+				It("does not apply the hostname rules", func() {
+					Expect(networker.BulkNetOut(logger, "some-handle", nil)).To(MatchError("potato"))
+					Expect(fakeHostnameRules.ApplyCallCount()).To(Equal(0))
+				})
+			})
+		})
 	})
 
 	Describe("NetIn", func() {
@@ -456,6 +823,7 @@ var _ = Describe("Networker", func() {
 				Expect(actualContainerPort).To(Equal(containerPort))
 
 				Expect(fakePortPool.AcquireCallCount()).To(Equal(1))
+				Expect(fakePortPool.AcquireArgsForCall(0)).To(Equal(handle))
 				Expect(fakePortForwarder.ForwardCallCount()).To(Equal(1))
 				spec := fakePortForwarder.ForwardArgsForCall(0)
 
@@ -504,7 +872,7 @@ var _ = Describe("Networker", func() {
 			actualHandle, actualName, actualValue := fakeConfigStore.SetArgsForCall(0)
 			Expect(actualHandle).To(Equal(handle))
 			Expect(actualName).To(Equal(gardener.MappedPortsKey))
-			Expect(actualValue).To(Equal(`[{"HostPort":60000,"ContainerPort":8080},{"HostPort":123,"ContainerPort":456}]`))
+			Expect(actualValue).To(Equal(`[{"HostPort":60000,"ContainerPort":8080},{"HostPort":123,"ContainerPort":456,"HostIP":"128.128.90.90"}]`))
 		})
 
 		It("stores a list of port mappings in ConfigStore", func() {
@@ -519,7 +887,43 @@ var _ = Describe("Networker", func() {
 			Expect(fakeConfigStore.SetCallCount()).To(Equal(2))
 
 			_, _, actualValue := fakeConfigStore.SetArgsForCall(1)
-			Expect(actualValue).To(Equal(`[{"HostPort":123,"ContainerPort":456},{"HostPort":654,"ContainerPort":987}]`))
+			Expect(actualValue).To(Equal(`[{"HostPort":123,"ContainerPort":456},{"HostPort":654,"ContainerPort":987,"HostIP":"128.128.90.90"}]`))
+		})
+
+		Context("when the container chooses external IPs for its mappings", func() {
+			BeforeEach(func() {
+				config[gardener.NetInExternalIPsKey] = `{"456": "128.128.90.92"}`
+			})
+
+			It("binds the mapping of a chosen container port to its external IP", func() {
+				_, _, err := networker.NetIn(logger, handle, externalPort, containerPort)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakePortForwarder.ForwardArgsForCall(0).ExternalIP.String()).To(Equal("128.128.90.92"))
+
+				_, _, actualValue := fakeConfigStore.SetArgsForCall(0)
+				Expect(actualValue).To(ContainSubstring(`{"HostPort":123,"ContainerPort":456,"HostIP":"128.128.90.92"}`))
+			})
+
+			It("binds other mappings to the container's external IP", func() {
+				_, _, err := networker.NetIn(logger, handle, externalPort, 789)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakePortForwarder.ForwardArgsForCall(0).ExternalIP).To(Equal(networkConfig.ExternalIP))
+			})
+
+			Context("when the chosen external IP is not one of the server's", func() {
+				BeforeEach(func() {
+					config[gardener.NetInExternalIPsKey] = `{"456": "1.2.3.4"}`
+				})
+
+				It("returns an error without acquiring a port", func() {
+					_, _, err := networker.NetIn(logger, handle, 0, containerPort)
+					Expect(err).To(MatchError(ContainSubstring("1.2.3.4 is not an external IP of the server")))
+					Expect(fakePortPool.AcquireCallCount()).To(Equal(0))
+					Expect(fakePortForwarder.ForwardCallCount()).To(Equal(0))
+				})
+			})
 		})
 
 		Context("when the PortForwarder fails", func() {
@@ -563,10 +967,36 @@ var _ = Describe("Networker", func() {
 		It("removes the port from port mapping list", func() {
 			Expect(networker.Restore(logger, "some-handle")).To(Succeed())
 			Expect(fakePortPool.RemoveCallCount()).To(Equal(1))
-			calledPort := fakePortPool.RemoveArgsForCall(0)
+			calledHandle, calledPort := fakePortPool.RemoveArgsForCall(0)
+			Expect(calledHandle).To(Equal("some-handle"))
 			Expect(calledPort).To(BeEquivalentTo(60000))
 		})
 
+		Context("when the NetOut hostnames property is set", func() {
+			BeforeEach(func() {
+				config[gardener.NetOutHostnamesKey] = `[{"hostnames":["example.com"]}]`
+			})
+
+			It("tracks the hostname rules for refreshing", func() {
+				Expect(networker.Restore(logger, "some-handle")).To(Succeed())
+
+				Expect(fakeHostnameRules.RestoreCallCount()).To(Equal(1))
+				_, cfg, rules := fakeHostnameRules.RestoreArgsForCall(0)
+				Expect(cfg.IPTableInstance).To(Equal(networkConfig.IPTableInstance))
+				Expect(rules).To(Equal([]kawasaki.HostnameRule{{Hostnames: []string{"example.com"}}}))
+			})
+
+			Context("when the property is invalid", func() {
+				BeforeEach(func() {
+					config[gardener.NetOutHostnamesKey] = "not-json"
+				})
+
+				It("returns an appropriate error", func() {
+					Expect(networker.Restore(logger, "some-handle")).To(MatchError(ContainSubstring("parsing hostname rules some-handle")))
+				})
+			})
+		})
+
 		Context("when the config couldn't be loaded", func() {
 			It("returns an appropriate error", func() {
 				config = nil
@@ -574,6 +1004,80 @@ var _ = Describe("Networker", func() {
 			})
 		})
 
+		It("repairs the container's network", func() {
+			config["kawasaki.pid"] = "42"
+
+			Expect(networker.Restore(logger, "some-handle")).To(Succeed())
+
+			Expect(fakeConfigurer.RepairCallCount()).To(Equal(1))
+			_, cfg, pid, recreateChains := fakeConfigurer.RepairArgsForCall(0)
+			Expect(cfg.ContainerHandle).To(Equal("some-handle"))
+			Expect(cfg.IPTableInstance).To(Equal(networkConfig.IPTableInstance))
+			Expect(pid).To(Equal(42))
+			Expect(recreateChains).To(BeTrue())
+		})
+
+		Context("when the pid was not recorded, as the container was networked before pids were", func() {
+			BeforeEach(func() {
+				containerPid = 43
+				fakeConfigStore.SetStub = func(handle, name, value string) {
+					config[name] = value
+				}
+			})
+
+			It("repairs the container's network using the containerizer's pid", func() {
+				Expect(networker.Restore(logger, "some-handle")).To(Succeed())
+
+				Expect(fakeConfigurer.RepairCallCount()).To(Equal(1))
+				_, _, pid, _ := fakeConfigurer.RepairArgsForCall(0)
+				Expect(pid).To(Equal(43))
+			})
+
+			It("records the pid, so that the container's network is verified", func() {
+				Expect(networker.Restore(logger, "some-handle")).To(Succeed())
+				Expect(config["kawasaki.pid"]).To(Equal("43"))
+
+				Expect(networker.Verify(logger, "some-handle")).To(Succeed())
+				Expect(fakeConfigurer.RepairCallCount()).To(Equal(2))
+			})
+
+			It("does not re-create the container's iptables chains, which would lose its unrecorded NetOut rules", func() {
+				Expect(networker.Restore(logger, "some-handle")).To(Succeed())
+				Expect(networker.Verify(logger, "some-handle")).To(Succeed())
+
+				_, _, _, recreateChains := fakeConfigurer.RepairArgsForCall(0)
+				Expect(recreateChains).To(BeFalse())
+				_, _, _, recreateChains = fakeConfigurer.RepairArgsForCall(1)
+				Expect(recreateChains).To(BeFalse())
+			})
+
+			Context("and the containerizer cannot find the pid", func() {
+				BeforeEach(func() {
+					containerPidErr = errors.New("no-such-container")
+				})
+
+				It("still verifies the container's iptables chains", func() {
+					Expect(networker.Restore(logger, "some-handle")).To(Succeed())
+
+					Expect(fakeConfigurer.RepairCallCount()).To(Equal(1))
+					_, _, pid, recreateChains := fakeConfigurer.RepairArgsForCall(0)
+					Expect(pid).To(Equal(0))
+					Expect(recreateChains).To(BeFalse())
+				})
+			})
+		})
+
+		Context("when repairing the network fails", func() {
+			BeforeEach(func() {
+				fakeConfigurer.RepairReturns(kawasaki.Repairs{}, errors.New("no-bridge"))
+			})
+
+			It("does not fail the restore", func() {
+				Expect(networker.Restore(logger, "some-handle")).To(Succeed())
+				Expect(fakePortPool.RemoveCallCount()).To(Equal(1))
+			})
+		})
+
 		Context("when removing the IP from the subnet pool errors", func() {
 			BeforeEach(func() {
 				fakeSubnetPool.RemoveReturns(errors.New("failed-to-remove-from-subnet-pool"))
@@ -598,18 +1102,461 @@ var _ = Describe("Networker", func() {
 				config[gardener.MappedPortsKey] = "not-json"
 			})
 
-			It("returns an appropriate erorr", func() {
-				Expect(networker.Restore(logger, "some-handle")).To(MatchError("unmarshaling port mappings some-handle: invalid character 'o' in literal null (expecting 'u')"))
+			It("does not fail the restore", func() {
+				Expect(networker.Restore(logger, "some-handle")).To(Succeed())
+				Expect(fakePortPool.RemoveCallCount()).To(Equal(0))
 			})
 		})
 
 		Context("when removing the port from the port pool errors", func() {
 			BeforeEach(func() {
+				config[gardener.MappedPortsKey] = `[{"HostPort": 60000}, {"HostPort": 60001}]`
 				fakePortPool.RemoveReturns(errors.New("failed-to-remove-from-port-pool"))
 			})
+
+			It("carries on removing the remaining ports without failing the restore", func() {
+				Expect(networker.Restore(logger, "some-handle")).To(Succeed())
+				Expect(fakePortPool.RemoveCallCount()).To(Equal(2))
+			})
+		})
+	})
+
+	Describe("ShareNetwork", func() {
+		var sharer gardener.NetworkSharer
+
+		BeforeEach(func() {
+			sharer = networker.(gardener.NetworkSharer)
+		})
+
+		It("configures the container's DNS from the network config of the owner", func() {
+			Expect(sharer.ShareNetwork(logger, "some-handle", "sidecar", 43)).To(Succeed())
+
+			Expect(fakeConfigurer.ConfigureDNSCallCount()).To(Equal(1))
+			_, cfg, pid := fakeConfigurer.ConfigureDNSArgsForCall(0)
+			Expect(cfg.ContainerHandle).To(Equal("sidecar"))
+			Expect(cfg.ContainerIP).To(Equal(networkConfig.ContainerIP))
+			Expect(cfg.BridgeIP).To(Equal(networkConfig.BridgeIP))
+			Expect(cfg.DNSServers).To(Equal(networkConfig.DNSServers))
+			Expect(pid).To(Equal(43))
+		})
+
+		Context("when the owner has no network config", func() {
+			BeforeEach(func() {
+				config = map[string]string{}
+			})
+
+			It("returns an error", func() {
+				Expect(sharer.ShareNetwork(logger, "some-handle", "sidecar", 43)).To(MatchError(ContainSubstring("loading some-handle")))
+				Expect(fakeConfigurer.ConfigureDNSCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when configuring DNS fails", func() {
+			It("returns the error", func() {
+				fakeConfigurer.ConfigureDNSReturns(errors.New("no-resolv-conf"))
+				Expect(sharer.ShareNetwork(logger, "some-handle", "sidecar", 43)).To(MatchError("no-resolv-conf"))
+			})
+		})
+	})
+
+	Describe("Verify", func() {
+		BeforeEach(func() {
+			config["kawasaki.pid"] = "42"
+		})
+
+		It("repairs the container's network", func() {
+			Expect(networker.Verify(logger, "some-handle")).To(Succeed())
+
+			Expect(fakeConfigurer.RepairCallCount()).To(Equal(1))
+			_, cfg, pid, recreateChains := fakeConfigurer.RepairArgsForCall(0)
+			Expect(cfg.ContainerHandle).To(Equal("some-handle"))
+			Expect(cfg.ContainerIP).To(Equal(networkConfig.ContainerIP))
+			Expect(pid).To(Equal(42))
+			Expect(recreateChains).To(BeTrue())
+		})
+
+		It("passes the container's overrides and connection limits", func() {
+			config[gardener.NetworkAllowHostAccessKey] = "true"
+			config[gardener.NetworkMaxConnectionsKey] = "10"
+
+			Expect(networker.Verify(logger, "some-handle")).To(Succeed())
+
+			_, cfg, _, _ := fakeConfigurer.RepairArgsForCall(0)
+			Expect(cfg.Overrides).To(Equal(kawasaki.NetworkOverrides{AllowHostAccess: true}))
+			Expect(cfg.ConnectionLimits).To(Equal(kawasaki.ConnectionLimits{MaxConnections: 10}))
+		})
+
+		It("passes the container's interface name", func() {
+			config["kawasaki.container-interface-name"] = "eth0"
+
+			Expect(networker.Verify(logger, "some-handle")).To(Succeed())
+
+			_, cfg, _, _ := fakeConfigurer.RepairArgsForCall(0)
+			Expect(cfg.ContainerIntf).To(Equal(networkConfig.ContainerIntf))
+			Expect(cfg.ContainerIntfName).To(Equal("eth0"))
+		})
+
+		Context("when repairing the network fails", func() {
+			It("returns the error", func() {
+				fakeConfigurer.RepairReturns(kawasaki.Repairs{}, errors.New("no-bridge"))
+				Expect(networker.Verify(logger, "some-handle")).To(MatchError("no-bridge"))
+			})
+		})
+
+		Context("when only interfaces are re-created", func() {
+			It("does not re-install any rules", func() {
+				fakeConfigurer.RepairReturns(kawasaki.Repairs{Veth: true}, nil)
+
+				Expect(networker.Verify(logger, "some-handle")).To(Succeed())
+				Expect(fakePortForwarder.ForwardCallCount()).To(Equal(0))
+				Expect(fakeFirewallOpener.BulkOpenCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the iptables chains are re-created", func() {
+			BeforeEach(func() {
+				config[gardener.MappedPortsKey] = `[{"HostPort":60000,"ContainerPort":8080},{"HostPort":60001,"ContainerPort":8081,"HostIP":"128.128.90.92"}]`
+				config["kawasaki.netout-rules"] = `[{"protocol":1},{"protocol":3}]`
+				config[gardener.NetOutHostnamesKey] = `[{"hostnames":["example.com"]}]`
+
+				fakeConfigurer.RepairReturns(kawasaki.Repairs{IPTablesRules: true}, nil)
+			})
+
+			It("re-forwards the container's mapped ports", func() {
+				Expect(networker.Verify(logger, "some-handle")).To(Succeed())
+
+				Expect(fakePortForwarder.ForwardCallCount()).To(Equal(2))
+				Expect(fakePortForwarder.ForwardArgsForCall(0)).To(Equal(kawasaki.PortForwarderSpec{
+					InstanceID:  networkConfig.IPTableInstance,
+					Handle:      "some-handle",
+					FromPort:    60000,
+					ToPort:      8080,
+					ContainerIP: networkConfig.ContainerIP,
+					ExternalIP:  networkConfig.ExternalIP,
+				}))
+				Expect(fakePortForwarder.ForwardArgsForCall(1).ExternalIP.String()).To(Equal("128.128.90.92"))
+			})
+
+			It("re-opens the container's NetOut rules", func() {
+				Expect(networker.Verify(logger, "some-handle")).To(Succeed())
+
+				Expect(fakeFirewallOpener.BulkOpenCallCount()).To(Equal(1))
+				_, instance, handle, rules := fakeFirewallOpener.BulkOpenArgsForCall(0)
+				Expect(instance).To(Equal(networkConfig.IPTableInstance))
+				Expect(handle).To(Equal("some-handle"))
+				Expect(rules).To(Equal([]garden.NetOutRule{{Protocol: garden.ProtocolTCP}, {Protocol: garden.ProtocolICMP}}))
+			})
+
+			It("re-applies the container's hostname rules", func() {
+				Expect(networker.Verify(logger, "some-handle")).To(Succeed())
+
+				Expect(fakeHostnameRules.ApplyCallCount()).To(Equal(1))
+				_, _, rules := fakeHostnameRules.ApplyArgsForCall(0)
+				Expect(rules).To(Equal([]kawasaki.HostnameRule{{Hostnames: []string{"example.com"}}}))
+			})
+
+			It("does not open NetOut rules until the repair has finished", func() {
+				repairing := make(chan struct{})
+				release := make(chan struct{})
+				fakeConfigurer.RepairStub = func(lager.Logger, kawasaki.NetworkConfig, int, bool) (kawasaki.Repairs, error) {
+					close(repairing)
+					<-release
+					return kawasaki.Repairs{IPTablesRules: true}, nil
+				}
+
+				verified := make(chan error)
+				go func() {
+					verified <- networker.Verify(logger, "some-handle")
+				}()
+				Eventually(repairing).Should(BeClosed())
+
+				netOut := make(chan error)
+				go func() {
+					netOut <- networker.NetOut(logger, "some-handle", garden.NetOutRule{Protocol: garden.ProtocolUDP})
+				}()
+				Consistently(fakeFirewallOpener.OpenCallCount).Should(Equal(0))
+
+				close(release)
+				Eventually(verified).Should(Receive(BeNil()))
+				Eventually(netOut).Should(Receive(BeNil()))
+				Expect(fakeFirewallOpener.OpenCallCount()).To(Equal(1))
+			})
+
+			Context("when re-forwarding a port fails", func() {
+				It("returns the error", func() {
+					fakePortForwarder.ForwardReturns(errors.New("no-nat"))
+					Expect(networker.Verify(logger, "some-handle")).To(MatchError("no-nat"))
+				})
+			})
+
+			Context("when re-opening the NetOut rules fails", func() {
+				It("returns the error", func() {
+					fakeFirewallOpener.BulkOpenReturns(errors.New("potato"))
+					Expect(networker.Verify(logger, "some-handle")).To(MatchError("potato"))
+				})
+			})
+		})
+
+		Context("when the pid was not recorded", func() {
+			BeforeEach(func() {
+				delete(config, "kawasaki.pid")
+			})
+
+			It("skips the container, whose network may still be being configured", func() {
+				Expect(networker.Verify(logger, "some-handle")).To(Succeed())
+				Expect(fakeConfigurer.RepairCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the container has been destroyed", func() {
+			BeforeEach(func() {
+				config["kawasaki.pid"] = ""
+			})
+
+			It("skips the container", func() {
+				Expect(networker.Verify(logger, "some-handle")).To(Succeed())
+				Expect(fakeConfigurer.RepairCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the config couldn't be loaded", func() {
 			It("returns an appropriate error", func() {
-				Expect(networker.Restore(logger, "some-handle")).To(MatchError("port pool removing some-handle: failed-to-remove-from-port-pool"))
+				delete(config, "kawasaki.subnet")
+				Expect(networker.Verify(logger, "some-handle")).To(MatchError(ContainSubstring("loading some-handle")))
+			})
+		})
+	})
+
+	Describe("direct network modes", func() {
+		BeforeEach(func() {
+			containerSpec.Network = "macvlan"
+			containerSpec.NetIn = nil
+			containerSpec.NetOut = nil
+
+			fakeDirectPool.AcquireReturns(directNetwork.Range, net.ParseIP("10.0.5.2"), nil)
+		})
+
+		Describe("Network", func() {
+			It("acquires an IP from the direct range", func() {
+				Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+				Expect(fakeDirectPool.AcquireCallCount()).To(Equal(1))
+				_, subnetSelector, ipSelector := fakeDirectPool.AcquireArgsForCall(0)
+				Expect(subnetSelector.SelectSubnet(nil, nil)).To(Equal(directNetwork.Range))
+				Expect(ipSelector).To(Equal(subnets.DynamicIPSelector))
+
+				Expect(fakeSubnetPool.AcquireCallCount()).To(Equal(0))
+			})
+
+			It("creates the config in the direct subnet", func() {
+				Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+				Expect(fakeConfigCreator.CreateCallCount()).To(Equal(1))
+				_, handle, subnet, ip := fakeConfigCreator.CreateArgsForCall(0)
+				Expect(handle).To(Equal("some-handle"))
+				Expect(subnet).To(Equal(directNetwork.Subnet))
+				Expect(ip).To(Equal(net.ParseIP("10.0.5.2")))
+			})
+
+			It("applies a config attached to the host interface", func() {
+				Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+				Expect(fakeConfigurer.ApplyCallCount()).To(Equal(1))
+				_, cfg, pid := fakeConfigurer.ApplyArgsForCall(0)
+				Expect(pid).To(Equal(42))
+				Expect(cfg.Mode).To(Equal("macvlan"))
+				Expect(cfg.HostIntf).To(Equal("eth1"))
+				Expect(cfg.BridgeName).To(BeEmpty())
+				Expect(cfg.BridgeIP).To(Equal(directNetwork.Gateway))
+				Expect(cfg.ExternalIP).To(Equal(net.ParseIP("10.0.5.2")))
+			})
+
+			It("stores the mode", func() {
+				Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+				var stored string
+				for i := 0; i < fakeConfigStore.SetCallCount(); i++ {
+					_, name, value := fakeConfigStore.SetArgsForCall(i)
+					if name == "kawasaki.mode" {
+						stored = value
+					}
+				}
+				Expect(stored).To(Equal("macvlan"))
+			})
+
+			Context("when the mode is given as a property", func() {
+				BeforeEach(func() {
+					containerSpec.Network = ""
+					containerSpec.Properties = garden.Properties{gardener.NetworkModeKey: "ipvlan"}
+				})
+
+				It("uses the mode", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+					_, cfg, _ := fakeConfigurer.ApplyArgsForCall(0)
+					Expect(cfg.Mode).To(Equal("ipvlan"))
+				})
+			})
+
+			Context("when NetIn or NetOut rules are given", func() {
+				BeforeEach(func() {
+					containerSpec.NetIn = []garden.NetIn{{HostPort: 9999, ContainerPort: 8080}}
+				})
+
+				It("returns an error", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(MatchError("NetIn and NetOut are not supported in network mode macvlan"))
+				})
 			})
+
+			Context("when interface options are given", func() {
+				BeforeEach(func() {
+					containerSpec.Properties = garden.Properties{
+						gardener.NetworkMtuKey:           "1400",
+						gardener.NetworkInterfaceNameKey: "eth0",
+					}
+				})
+
+				It("applies them", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(Succeed())
+
+					_, cfg, _ := fakeConfigurer.ApplyArgsForCall(0)
+					Expect(cfg.Mtu).To(Equal(1400))
+					Expect(cfg.ContainerIntfName).To(Equal("eth0"))
+				})
+			})
+
+			Context("when connection limits are given", func() {
+				BeforeEach(func() {
+					containerSpec.Properties = garden.Properties{gardener.NetworkMaxConnectionsKey: "200"}
+				})
+
+				It("returns an error", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(MatchError("connection limits are not supported in network mode macvlan"))
+				})
+			})
+
+			Context("when external IPs are given", func() {
+				BeforeEach(func() {
+					containerSpec.Properties = garden.Properties{gardener.DefaultExternalIPKey: "128.128.90.91"}
+				})
+
+				It("returns an error", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(MatchError("external IPs are not supported in network mode macvlan"))
+				})
+			})
+
+			Context("when network overrides are given", func() {
+				BeforeEach(func() {
+					containerSpec.Properties = garden.Properties{gardener.NetworkAllowHostAccessKey: "true"}
+				})
+
+				It("returns an error", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(MatchError("network overrides are not supported in network mode macvlan"))
+				})
+			})
+
+			Context("when acquiring an IP fails", func() {
+				BeforeEach(func() {
+					fakeDirectPool.AcquireReturns(nil, nil, errors.New("full"))
+				})
+
+				It("returns the error", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(MatchError("full"))
+				})
+			})
+
+			Context("when no direct network is configured", func() {
+				BeforeEach(func() {
+					networker = kawasaki.New(
+						fakeSpecParser,
+						fakeSubnetPool,
+						fakeConfigCreator,
+						fakeConfigStore,
+						fakeConfigurer,
+						fakePortPool,
+						fakePortForwarder,
+						fakeFirewallOpener,
+						nil,
+						fakeHostnameRules,
+						nil,
+						1500,
+						lookupContainerPid,
+					)
+				})
+
+				It("returns an error", func() {
+					Expect(networker.Network(logger, containerSpec, 42)).To(MatchError("network mode macvlan is not enabled"))
+				})
+			})
+		})
+
+		Context("when the container is directly attached", func() {
+			BeforeEach(func() {
+				config["kawasaki.mode"] = "macvlan"
+				config[gardener.ContainerIPKey] = "10.0.5.2"
+			})
+
+			It("releases the IP to the direct pool on Destroy", func() {
+				Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
+
+				Expect(fakeDirectPool.ReleaseCallCount()).To(Equal(1))
+				subnet, ip := fakeDirectPool.ReleaseArgsForCall(0)
+				Expect(subnet).To(Equal(directNetwork.Range))
+				Expect(ip).To(Equal(net.ParseIP("10.0.5.2")))
+
+				Expect(fakeSubnetPool.ReleaseCallCount()).To(Equal(0))
+				Expect(fakeConfigurer.DestroyIPTablesRulesCallCount()).To(Equal(0))
+				Expect(fakeConfigurer.DestroyBridgeCallCount()).To(Equal(0))
+			})
+
+			It("removes the IP from the direct pool on Restore", func() {
+				Expect(networker.Restore(logger, "some-handle")).To(Succeed())
+
+				Expect(fakeDirectPool.RemoveCallCount()).To(Equal(1))
+				subnet, ip := fakeDirectPool.RemoveArgsForCall(0)
+				Expect(subnet).To(Equal(directNetwork.Range))
+				Expect(ip).To(Equal(net.ParseIP("10.0.5.2")))
+			})
+
+			It("does not support NetIn", func() {
+				_, _, err := networker.NetIn(logger, "some-handle", 0, 8080)
+				Expect(err).To(MatchError("NetIn is not supported in network mode macvlan"))
+			})
+
+			It("does not support NetOut", func() {
+				Expect(networker.NetOut(logger, "some-handle", garden.NetOutRule{})).To(MatchError("NetOut is not supported in network mode macvlan"))
+				Expect(networker.BulkNetOut(logger, "some-handle", nil)).To(MatchError("NetOut is not supported in network mode macvlan"))
+			})
+		})
+	})
+})
+
+var _ = Describe("PortMappings", func() {
+	var configStore kawasaki.ConfigStore
+
+	BeforeEach(func() {
+		configStore = properties.NewManager()
+	})
+
+	It("returns the port mappings added for the container", func() {
+		mapping := kawasaki.PortMapping{HostIP: "1.2.3.4", HostPort: 60000, ContainerPort: 8080}
+		Expect(kawasaki.AddPortMapping(lagertest.NewTestLogger("test"), configStore, "some-handle", mapping)).To(Succeed())
+
+		Expect(kawasaki.PortMappings(configStore, "some-handle")).To(ConsistOf(mapping))
+	})
+
+	Context("when no port mappings were added", func() {
+		It("returns none", func() {
+			Expect(kawasaki.PortMappings(configStore, "some-handle")).To(BeEmpty())
+		})
+	})
+
+	Context("when the recorded port mappings are invalid", func() {
+		It("returns an error", func() {
+			configStore.Set("some-handle", gardener.MappedPortsKey, "banana")
+
+			_, err := kawasaki.PortMappings(configStore, "some-handle")
+			Expect(err).To(HaveOccurred())
 		})
 	})
 })
@@ -0,0 +1,120 @@
+package nflog
+
+import (
+	"code.cloudfoundry.org/lager"
+)
+
+//go:generate counterfeiter . Source
+
+type Source interface {
+	Next() (Event, error)
+	Close() error
+}
+
+//go:generate counterfeiter . HandleResolver
+
+// HandleResolver finds the container whose NetOut rules log packets with a
+// prefix
+type HandleResolver interface {
+	Handle(prefix string) (string, error)
+}
+
+// the number of prefixes whose containers are remembered, so that the
+// containers logging many packets are not resolved every time
+const maxCachedPrefixes = 1024
+
+// Collector emits a structured log line for every packet logged by a
+// container's NetOut rules, subject to a per-container rate limit
+type Collector struct {
+	source   Source
+	resolver HandleResolver
+	limiter  *RateLimiter
+	logger   lager.Logger
+
+	// only used by run
+	handles map[string]string
+
+	done chan struct{}
+}
+
+func NewCollector(logger lager.Logger, source Source, resolver HandleResolver, limiter *RateLimiter) *Collector {
+	return &Collector{
+		source:   source,
+		resolver: resolver,
+		limiter:  limiter,
+		logger:   logger.Session("nflog-collector"),
+		handles:  make(map[string]string),
+		done:     make(chan struct{}),
+	}
+}
+
+func (c *Collector) Start() {
+	c.logger.Info("starting")
+	go c.run()
+}
+
+// Stop closes the source, and waits for the collector to finish
+func (c *Collector) Stop() error {
+	err := c.source.Close()
+	<-c.done
+	return err
+}
+
+func (c *Collector) run() {
+	defer close(c.done)
+	defer c.logger.Info("finished")
+
+	for {
+		event, err := c.source.Next()
+		if err != nil {
+			c.logger.Error("reading-event-failed", err)
+			return
+		}
+
+		allowed, dropped := c.limiter.Allow(event.Prefix)
+		if !allowed && dropped == 0 {
+			continue
+		}
+
+		handle := c.handle(event.Prefix)
+		if dropped > 0 {
+			c.logger.Info("egress-events-dropped", lager.Data{
+				"handle":  handle,
+				"dropped": dropped,
+			})
+		}
+
+		if !allowed {
+			continue
+		}
+
+		c.logger.Info("egress", lager.Data{
+			"handle":      handle,
+			"source":      event.Source.String(),
+			"destination": event.Destination.String(),
+			"port":        event.Port,
+			"protocol":    event.Protocol,
+		})
+	}
+}
+
+func (c *Collector) handle(prefix string) string {
+	if handle, ok := c.handles[prefix]; ok {
+		return handle
+	}
+
+	handle, err := c.resolver.Handle(prefix)
+	if err != nil {
+		// containers networked before their rules were prefixed with the
+		// instance ID are prefixed with their handle, cut to fit
+		c.logger.Debug("resolving-handle-failed", lager.Data{"prefix": prefix, "error": err.Error()})
+		return prefix
+	}
+
+	if len(c.handles) >= maxCachedPrefixes {
+		c.handles = make(map[string]string)
+	}
+	c.handles[prefix] = handle
+
+	return handle
+}
@@ -0,0 +1,142 @@
+package nflog_test
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"code.cloudfoundry.org/guardian/kawasaki/nflog"
+	"code.cloudfoundry.org/guardian/kawasaki/nflog/nflogfakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/pivotal-golang/clock/fakeclock"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Collector", func() {
+	var (
+		fakeSource   *nflogfakes.FakeSource
+		fakeResolver *nflogfakes.FakeHandleResolver
+		logger       *lagertest.TestLogger
+		limit        int
+		collector    *nflog.Collector
+		event        nflog.Event
+	)
+
+	BeforeEach(func() {
+		fakeSource = new(nflogfakes.FakeSource)
+		fakeResolver = new(nflogfakes.FakeHandleResolver)
+		fakeResolver.HandleReturns("some-handle", nil)
+		logger = lagertest.NewTestLogger("test")
+		limit = 0
+
+		event = nflog.Event{
+			Prefix:      "some-instance",
+			Source:      net.ParseIP("10.254.0.2"),
+			Destination: net.ParseIP("8.8.8.8"),
+			Port:        53,
+			Protocol:    "tcp",
+		}
+
+		events := 3
+		fakeSource.NextStub = func() (nflog.Event, error) {
+			if events == 0 {
+				return nflog.Event{}, errors.New("closed")
+			}
+			events--
+			return event, nil
+		}
+	})
+
+	JustBeforeEach(func() {
+		limiter := nflog.NewRateLimiter(fakeclock.NewFakeClock(time.Unix(123, 456)), limit, time.Second)
+		collector = nflog.NewCollector(logger, fakeSource, fakeResolver, limiter)
+		collector.Start()
+	})
+
+	egressLogs := func() []lager.LogFormat {
+		var logs []lager.LogFormat
+		for _, log := range logger.Logs() {
+			if log.Message == "test.nflog-collector.egress" {
+				logs = append(logs, log)
+			}
+		}
+		return logs
+	}
+
+	Context("when the source fails", func() {
+		JustBeforeEach(func() {
+			Eventually(logger.LogMessages).Should(ContainElement("test.nflog-collector.finished"))
+		})
+
+		It("logs an egress event for every packet", func() {
+			Expect(egressLogs()).To(HaveLen(3))
+			data := egressLogs()[0].Data
+			Expect(data["handle"]).To(Equal("some-handle"))
+			Expect(data["source"]).To(Equal("10.254.0.2"))
+			Expect(data["destination"]).To(Equal("8.8.8.8"))
+			Expect(data["port"]).To(Equal(float64(53)))
+			Expect(data["protocol"]).To(Equal("tcp"))
+		})
+
+		It("resolves the handle from the prefix once", func() {
+			Expect(fakeResolver.HandleCallCount()).To(Equal(1))
+			Expect(fakeResolver.HandleArgsForCall(0)).To(Equal("some-instance"))
+		})
+
+		It("logs the error", func() {
+			Expect(logger.LogMessages()).To(ContainElement("test.nflog-collector.reading-event-failed"))
+		})
+
+		Context("when the handle cannot be resolved", func() {
+			BeforeEach(func() {
+				fakeResolver.HandleReturns("", errors.New("banana"))
+			})
+
+			It("logs the prefix as the handle", func() {
+				Expect(egressLogs()).To(HaveLen(3))
+				Expect(egressLogs()[0].Data["handle"]).To(Equal("some-instance"))
+			})
+		})
+
+		Context("when the rate limit is exceeded", func() {
+			BeforeEach(func() {
+				limit = 1
+			})
+
+			It("only logs up to the limit", func() {
+				Expect(egressLogs()).To(HaveLen(1))
+			})
+		})
+	})
+
+	Describe("Stop", func() {
+		var closed chan struct{}
+
+		BeforeEach(func() {
+			closed = make(chan struct{})
+			fakeSource.NextStub = func() (nflog.Event, error) {
+				<-closed
+				return nflog.Event{}, errors.New("closed")
+			}
+			fakeSource.CloseStub = func() error {
+				close(closed)
+				return nil
+			}
+		})
+
+		It("closes the source", func() {
+			Expect(collector.Stop()).To(Succeed())
+			Expect(fakeSource.CloseCallCount()).To(Equal(1))
+		})
+
+		It("waits for the collector to finish reading the source", func() {
+			Consistently(logger.LogMessages).ShouldNot(ContainElement("test.nflog-collector.finished"))
+
+			Expect(collector.Stop()).To(Succeed())
+			Expect(logger.LogMessages()).To(ContainElement("test.nflog-collector.finished"))
+		})
+	})
+})
@@ -0,0 +1,99 @@
+// This file was generated by counterfeiter
+package nflogfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/kawasaki/nflog"
+)
+
+type FakeHandleResolver struct {
+	HandleStub        func(prefix string) (string, error)
+	handleMutex       sync.RWMutex
+	handleArgsForCall []struct {
+		prefix string
+	}
+	handleReturns struct {
+		result1 string
+		result2 error
+	}
+	handleReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeHandleResolver) Handle(prefix string) (string, error) {
+	fake.handleMutex.Lock()
+	ret, specificReturn := fake.handleReturnsOnCall[len(fake.handleArgsForCall)]
+	fake.handleArgsForCall = append(fake.handleArgsForCall, struct {
+		prefix string
+	}{prefix})
+	fake.recordInvocation("Handle", []interface{}{prefix})
+	fake.handleMutex.Unlock()
+	if fake.HandleStub != nil {
+		return fake.HandleStub(prefix)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.handleReturns.result1, fake.handleReturns.result2
+}
+
+func (fake *FakeHandleResolver) HandleCallCount() int {
+	fake.handleMutex.RLock()
+	defer fake.handleMutex.RUnlock()
+	return len(fake.handleArgsForCall)
+}
+
+func (fake *FakeHandleResolver) HandleArgsForCall(i int) string {
+	fake.handleMutex.RLock()
+	defer fake.handleMutex.RUnlock()
+	return fake.handleArgsForCall[i].prefix
+}
+
+func (fake *FakeHandleResolver) HandleReturns(result1 string, result2 error) {
+	fake.HandleStub = nil
+	fake.handleReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHandleResolver) HandleReturnsOnCall(i int, result1 string, result2 error) {
+	fake.HandleStub = nil
+	if fake.handleReturnsOnCall == nil {
+		fake.handleReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.handleReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeHandleResolver) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.handleMutex.RLock()
+	defer fake.handleMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeHandleResolver) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ nflog.HandleResolver = new(FakeHandleResolver)
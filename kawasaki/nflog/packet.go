@@ -0,0 +1,118 @@
+package nflog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"unsafe"
+)
+
+// netfilter log attribute types, see linux/netfilter/nfnetlink_log.h
+const (
+	nfulaPayload = 9
+	nfulaPrefix  = 10
+
+	nlaTypeMask = 0x3fff
+	nfgenmsgLen = 4
+)
+
+// netlink headers and attributes are in host byte order
+var nativeEndian binary.ByteOrder = binary.LittleEndian
+
+func init() {
+	i := uint16(1)
+	if (*[2]byte)(unsafe.Pointer(&i))[0] == 0 {
+		nativeEndian = binary.BigEndian
+	}
+}
+
+var protocolNames = map[uint8]string{
+	1:  "icmp",
+	6:  "tcp",
+	17: "udp",
+}
+
+// Event describes a single packet logged by a NetOut rule. The prefix of the
+// rule identifies the container, see HandleResolver.
+type Event struct {
+	Prefix      string
+	Source      net.IP
+	Destination net.IP
+	Port        uint16
+	Protocol    string
+}
+
+// ParsePacket parses the payload of an NFULNL_MSG_PACKET netlink message
+// (starting at the nfgenmsg header) into an Event
+func ParsePacket(msg []byte) (Event, error) {
+	if len(msg) < nfgenmsgLen {
+		return Event{}, errors.New("nflog: message too short")
+	}
+
+	var (
+		event   Event
+		payload []byte
+	)
+
+	attrs := msg[nfgenmsgLen:]
+	for len(attrs) >= 4 {
+		attrLen := int(nativeEndian.Uint16(attrs[0:2]))
+		attrType := nativeEndian.Uint16(attrs[2:4]) & nlaTypeMask
+		if attrLen < 4 || attrLen > len(attrs) {
+			return Event{}, fmt.Errorf("nflog: invalid attribute length %d", attrLen)
+		}
+
+		value := attrs[4:attrLen]
+		switch attrType {
+		case nfulaPrefix:
+			event.Prefix = strings.TrimRight(string(value), "\x00")
+		case nfulaPayload:
+			payload = value
+		}
+
+		aligned := (attrLen + 3) &^ 3
+		if aligned > len(attrs) {
+			break
+		}
+		attrs = attrs[aligned:]
+	}
+
+	if payload == nil {
+		return Event{}, errors.New("nflog: message has no payload")
+	}
+
+	if err := parseIPv4(payload, &event); err != nil {
+		return Event{}, err
+	}
+
+	return event, nil
+}
+
+func parseIPv4(packet []byte, event *Event) error {
+	if len(packet) < 20 || packet[0]>>4 != 4 {
+		return errors.New("nflog: payload is not an IPv4 packet")
+	}
+
+	headerLen := int(packet[0]&0x0f) * 4
+	if headerLen < 20 || headerLen > len(packet) {
+		return fmt.Errorf("nflog: invalid IPv4 header length %d", headerLen)
+	}
+
+	proto := packet[9]
+	event.Source = net.IP(append([]byte{}, packet[12:16]...))
+	event.Destination = net.IP(append([]byte{}, packet[16:20]...))
+
+	event.Protocol = protocolNames[proto]
+	if event.Protocol == "" {
+		event.Protocol = fmt.Sprintf("%d", proto)
+	}
+
+	// TCP and UDP both carry the destination port at offset 2 of their header
+	if (proto == 6 || proto == 17) && len(packet) >= headerLen+4 {
+		event.Port = binary.BigEndian.Uint16(packet[headerLen+2 : headerLen+4])
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package nflog_test
+
+import (
+	"encoding/binary"
+	"net"
+
+	"code.cloudfoundry.org/guardian/kawasaki/nflog"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParsePacket", func() {
+	var (
+		prefix  string
+		payload []byte
+	)
+
+	BeforeEach(func() {
+		prefix = "some-instance"
+		payload = ipv4Packet(6, net.ParseIP("10.254.0.2"), net.ParseIP("8.8.8.8"), 53)
+	})
+
+	message := func() []byte {
+		msg := []byte{2, 0, 0, 1} // nfgenmsg
+		msg = append(msg, attribute(10, append([]byte(prefix), 0))...)
+		msg = append(msg, attribute(9, payload)...)
+		return msg
+	}
+
+	It("extracts the prefix", func() {
+		event, err := nflog.ParsePacket(message())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.Prefix).To(Equal("some-instance"))
+	})
+
+	It("extracts the addresses, port and protocol from the payload", func() {
+		event, err := nflog.ParsePacket(message())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.Source.String()).To(Equal("10.254.0.2"))
+		Expect(event.Destination.String()).To(Equal("8.8.8.8"))
+		Expect(event.Port).To(BeEquivalentTo(53))
+		Expect(event.Protocol).To(Equal("tcp"))
+	})
+
+	Context("when the packet is UDP", func() {
+		BeforeEach(func() {
+			payload = ipv4Packet(17, net.ParseIP("10.254.0.2"), net.ParseIP("8.8.4.4"), 123)
+		})
+
+		It("extracts the port", func() {
+			event, err := nflog.ParsePacket(message())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(event.Port).To(BeEquivalentTo(123))
+			Expect(event.Protocol).To(Equal("udp"))
+		})
+	})
+
+	Context("when the packet is ICMP", func() {
+		BeforeEach(func() {
+			payload = ipv4Packet(1, net.ParseIP("10.254.0.2"), net.ParseIP("8.8.4.4"), 0)
+		})
+
+		It("does not report a port", func() {
+			event, err := nflog.ParsePacket(message())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(event.Port).To(BeZero())
+			Expect(event.Protocol).To(Equal("icmp"))
+		})
+	})
+
+	Context("when the payload is not IPv4", func() {
+		BeforeEach(func() {
+			payload = make([]byte, 40)
+			payload[0] = 0x60
+		})
+
+		It("returns an error", func() {
+			_, err := nflog.ParsePacket(message())
+			Expect(err).To(MatchError(ContainSubstring("not an IPv4 packet")))
+		})
+	})
+
+	Context("when there is no payload", func() {
+		It("returns an error", func() {
+			msg := []byte{2, 0, 0, 1}
+			msg = append(msg, attribute(10, []byte("some-instance\x00"))...)
+
+			_, err := nflog.ParsePacket(msg)
+			Expect(err).To(MatchError("nflog: message has no payload"))
+		})
+	})
+
+	Context("when an attribute length is invalid", func() {
+		It("returns an error", func() {
+			msg := []byte{2, 0, 0, 1, 0xff, 0, 10, 0}
+
+			_, err := nflog.ParsePacket(msg)
+			Expect(err).To(MatchError(ContainSubstring("invalid attribute length")))
+		})
+	})
+})
+
+func attribute(attrType uint16, value []byte) []byte {
+	length := 4 + len(value)
+	b := make([]byte, (length+3)&^3)
+	binary.LittleEndian.PutUint16(b[0:2], uint16(length))
+	binary.LittleEndian.PutUint16(b[2:4], attrType)
+	copy(b[4:], value)
+	return b
+}
+
+func ipv4Packet(protocol byte, src, dst net.IP, dstPort uint16) []byte {
+	packet := make([]byte, 28)
+	packet[0] = 0x45
+	packet[9] = protocol
+	copy(packet[12:16], src.To4())
+	copy(packet[16:20], dst.To4())
+	binary.BigEndian.PutUint16(packet[20:22], 40000)
+	binary.BigEndian.PutUint16(packet[22:24], dstPort)
+	return packet
+}
@@ -0,0 +1,83 @@
+package nflog
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/clock"
+)
+
+// windows are only pruned once there are more of them than this
+const maxIdleWindows = 1024
+
+// RateLimiter allows a fixed number of events per key in each interval
+type RateLimiter struct {
+	clock    clock.Clock
+	limit    int
+	interval time.Duration
+
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+type window struct {
+	start   time.Time
+	count   int
+	dropped int
+}
+
+// NewRateLimiter creates a RateLimiter. A limit of zero disables limiting.
+func NewRateLimiter(clock clock.Clock, limit int, interval time.Duration) *RateLimiter {
+	return &RateLimiter{
+		clock:    clock,
+		limit:    limit,
+		interval: interval,
+		windows:  make(map[string]*window),
+	}
+}
+
+// Allow reports whether an event for the given key may be emitted. When a new
+// interval starts it also returns the number of events dropped in the
+// previous one, so that suppression can be reported.
+func (r *RateLimiter) Allow(key string) (bool, int) {
+	if r.limit <= 0 {
+		return true, 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+
+	w, ok := r.windows[key]
+	if !ok || now.Sub(w.start) >= r.interval {
+		dropped := 0
+		if ok {
+			dropped = w.dropped
+		}
+
+		r.prune(now)
+		r.windows[key] = &window{start: now, count: 1}
+		return true, dropped
+	}
+
+	if w.count < r.limit {
+		w.count++
+		return true, 0
+	}
+
+	w.dropped++
+	return false, 0
+}
+
+func (r *RateLimiter) prune(now time.Time) {
+	if len(r.windows) < maxIdleWindows {
+		return
+	}
+
+	for key, w := range r.windows {
+		if now.Sub(w.start) >= r.interval {
+			delete(r.windows, key)
+		}
+	}
+}
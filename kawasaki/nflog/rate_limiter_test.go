@@ -0,0 +1,73 @@
+package nflog_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/guardian/kawasaki/nflog"
+	"github.com/pivotal-golang/clock/fakeclock"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateLimiter", func() {
+	var (
+		fakeClock *fakeclock.FakeClock
+		limiter   *nflog.RateLimiter
+	)
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Unix(123, 456))
+		limiter = nflog.NewRateLimiter(fakeClock, 2, time.Second)
+	})
+
+	It("allows events up to the limit in an interval", func() {
+		allowed, _ := limiter.Allow("some-handle")
+		Expect(allowed).To(BeTrue())
+		allowed, _ = limiter.Allow("some-handle")
+		Expect(allowed).To(BeTrue())
+		allowed, _ = limiter.Allow("some-handle")
+		Expect(allowed).To(BeFalse())
+	})
+
+	It("limits each key separately", func() {
+		limiter.Allow("some-handle")
+		limiter.Allow("some-handle")
+
+		allowed, _ := limiter.Allow("other-handle")
+		Expect(allowed).To(BeTrue())
+	})
+
+	Context("when the interval has passed", func() {
+		BeforeEach(func() {
+			limiter.Allow("some-handle")
+			limiter.Allow("some-handle")
+			limiter.Allow("some-handle")
+			limiter.Allow("some-handle")
+			fakeClock.Increment(time.Second)
+		})
+
+		It("allows events again", func() {
+			allowed, _ := limiter.Allow("some-handle")
+			Expect(allowed).To(BeTrue())
+		})
+
+		It("reports how many events were dropped in the previous interval", func() {
+			_, dropped := limiter.Allow("some-handle")
+			Expect(dropped).To(Equal(2))
+		})
+	})
+
+	Context("when the limit is zero", func() {
+		BeforeEach(func() {
+			limiter = nflog.NewRateLimiter(fakeClock, 0, time.Second)
+		})
+
+		It("allows every event", func() {
+			for i := 0; i < 100; i++ {
+				allowed, _ := limiter.Allow("some-handle")
+				Expect(allowed).To(BeTrue())
+			}
+		})
+	})
+})
@@ -0,0 +1,190 @@
+package nflog
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// see linux/netfilter/nfnetlink.h and linux/netfilter/nfnetlink_log.h
+const (
+	netlinkNetfilter = 12
+
+	nfnlSubsysULog   = 4
+	nfulnlMsgPacket  = 0
+	nfulnlMsgConfig  = 1
+	nfulaCfgCmd      = 1
+	nfulaCfgMode     = 2
+	nfulnlCfgCmdBind = 1
+	nfulnlCopyPacket = 2
+
+	// enough to capture the IP header (including options) and the L4 ports
+	copyRange = 128
+)
+
+// Socket reads packets logged to a netfilter log group. The socket is
+// non-blocking and waits in the runtime's poller, so that closing it
+// unblocks a pending Next.
+type Socket struct {
+	file    *os.File
+	conn    syscall.RawConn
+	buf     []byte
+	pending []syscall.NetlinkMessage
+}
+
+// Listen binds a netlink socket to the given netfilter log group
+func Listen(group uint16) (*Socket, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW, netlinkNetfilter)
+	if err != nil {
+		return nil, fmt.Errorf("nflog: create socket: %s", err)
+	}
+
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("nflog: bind socket: %s", err)
+	}
+
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("nflog: set non-blocking: %s", err)
+	}
+
+	file := os.NewFile(uintptr(fd), "nflog")
+	conn, err := file.SyscallConn()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("nflog: %s", err)
+	}
+
+	s := &Socket{file: file, conn: conn, buf: make([]byte, syscall.Getpagesize()*16)}
+
+	if err := s.configure(group, attr(nfulaCfgCmd, []byte{nfulnlCfgCmdBind, 0, 0, 0})); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("nflog: bind group %d: %s", group, err)
+	}
+
+	mode := make([]byte, 8)
+	binary.BigEndian.PutUint32(mode[0:4], copyRange)
+	mode[4] = nfulnlCopyPacket
+	if err := s.configure(group, attr(nfulaCfgMode, mode[:6])); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("nflog: set copy mode: %s", err)
+	}
+
+	return s, nil
+}
+
+func (s *Socket) Next() (Event, error) {
+	for {
+		for len(s.pending) > 0 {
+			msg := s.pending[0]
+			s.pending = s.pending[1:]
+
+			if msg.Header.Type != nfnlSubsysULog<<8|nfulnlMsgPacket {
+				continue
+			}
+
+			event, err := ParsePacket(msg.Data)
+			if err != nil {
+				// not every logged packet is one we can describe, e.g. IPv6
+				continue
+			}
+
+			return event, nil
+		}
+
+		n, err := s.recv()
+		if err == syscall.ENOBUFS || err == syscall.EINTR {
+			// the kernel dropped messages because we were too slow, carry on
+			continue
+		}
+		if err != nil {
+			return Event{}, fmt.Errorf("nflog: receive: %s", err)
+		}
+
+		msgs, err := syscall.ParseNetlinkMessage(s.buf[:n])
+		if err != nil {
+			return Event{}, fmt.Errorf("nflog: parse: %s", err)
+		}
+		s.pending = msgs
+	}
+}
+
+func (s *Socket) Close() error {
+	return s.file.Close()
+}
+
+// recv reads the next datagram into the buffer, waiting until there is one
+// or the socket is closed
+func (s *Socket) recv() (int, error) {
+	var (
+		n       int
+		recvErr error
+	)
+
+	if err := s.conn.Read(func(fd uintptr) bool {
+		n, _, recvErr = syscall.Recvfrom(int(fd), s.buf, 0)
+		return recvErr != syscall.EAGAIN
+	}); err != nil {
+		return 0, err
+	}
+
+	return n, recvErr
+}
+
+func (s *Socket) configure(group uint16, attrs ...[]byte) error {
+	payload := make([]byte, nfgenmsgLen)
+	payload[0] = syscall.AF_UNSPEC
+	binary.BigEndian.PutUint16(payload[2:4], group)
+	for _, a := range attrs {
+		payload = append(payload, a...)
+	}
+
+	msg := make([]byte, syscall.NLMSG_HDRLEN, syscall.NLMSG_HDRLEN+len(payload))
+	nativeEndian.PutUint32(msg[0:4], uint32(syscall.NLMSG_HDRLEN+len(payload)))
+	nativeEndian.PutUint16(msg[4:6], nfnlSubsysULog<<8|nfulnlMsgConfig)
+	nativeEndian.PutUint16(msg[6:8], syscall.NLM_F_REQUEST|syscall.NLM_F_ACK)
+	nativeEndian.PutUint32(msg[8:12], 1)
+	msg = append(msg, payload...)
+
+	var sendErr error
+	if err := s.conn.Write(func(fd uintptr) bool {
+		sendErr = syscall.Sendto(int(fd), msg, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK})
+		return sendErr != syscall.EAGAIN
+	}); err != nil {
+		return err
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+
+	n, err := s.recv()
+	if err != nil {
+		return err
+	}
+
+	replies, err := syscall.ParseNetlinkMessage(s.buf[:n])
+	if err != nil {
+		return err
+	}
+
+	for _, reply := range replies {
+		if reply.Header.Type == syscall.NLMSG_ERROR && len(reply.Data) >= 4 {
+			if errno := int32(nativeEndian.Uint32(reply.Data[0:4])); errno != 0 {
+				return syscall.Errno(-errno)
+			}
+		}
+	}
+
+	return nil
+}
+
+func attr(attrType uint16, value []byte) []byte {
+	length := 4 + len(value)
+	b := make([]byte, (length+3)&^3)
+	nativeEndian.PutUint16(b[0:2], uint16(length))
+	nativeEndian.PutUint16(b[2:4], attrType)
+	copy(b[4:], value)
+	return b
+}
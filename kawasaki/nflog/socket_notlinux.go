@@ -0,0 +1,19 @@
+// +build !linux
+
+package nflog
+
+import "errors"
+
+type Socket struct{}
+
+func Listen(group uint16) (*Socket, error) {
+	return nil, errors.New("nflog: not supported on this platform")
+}
+
+func (s *Socket) Next() (Event, error) {
+	return Event{}, errors.New("nflog: not supported on this platform")
+}
+
+func (s *Socket) Close() error {
+	return nil
+}
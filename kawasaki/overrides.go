@@ -0,0 +1,52 @@
+package kawasaki
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+)
+
+// NetworkOverrides are per-container exceptions to the server's
+// --allow-host-access and --deny-network settings
+type NetworkOverrides struct {
+	AllowHostAccess bool
+	DenyNetworks    []*net.IPNet
+}
+
+// Empty returns true if the overrides leave the server's settings unchanged
+func (o NetworkOverrides) Empty() bool {
+	return !o.AllowHostAccess && len(o.DenyNetworks) == 0
+}
+
+// ParseOverrides reads the network overrides requested by a container's
+// properties
+func ParseOverrides(properties garden.Properties) (NetworkOverrides, error) {
+	var overrides NetworkOverrides
+
+	switch properties[gardener.NetworkAllowHostAccessKey] {
+	case "", "false":
+	case "true":
+		overrides.AllowHostAccess = true
+	default:
+		return NetworkOverrides{}, fmt.Errorf("invalid value for %s: %s", gardener.NetworkAllowHostAccessKey, properties[gardener.NetworkAllowHostAccessKey])
+	}
+
+	for _, cidr := range strings.Split(properties[gardener.NetworkDenyNetworksKey], ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return NetworkOverrides{}, fmt.Errorf("invalid network in %s: %s", gardener.NetworkDenyNetworksKey, err)
+		}
+
+		overrides.DenyNetworks = append(overrides.DenyNetworks, network)
+	}
+
+	return overrides, nil
+}
@@ -0,0 +1,58 @@
+package kawasaki_test
+
+import (
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/kawasaki"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseOverrides", func() {
+	It("returns empty overrides when no properties are given", func() {
+		overrides, err := kawasaki.ParseOverrides(garden.Properties{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overrides.Empty()).To(BeTrue())
+	})
+
+	It("allows host access when the property is true", func() {
+		overrides, err := kawasaki.ParseOverrides(garden.Properties{
+			gardener.NetworkAllowHostAccessKey: "true",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overrides.AllowHostAccess).To(BeTrue())
+		Expect(overrides.Empty()).To(BeFalse())
+	})
+
+	It("does not allow host access when the property is false", func() {
+		overrides, err := kawasaki.ParseOverrides(garden.Properties{
+			gardener.NetworkAllowHostAccessKey: "false",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overrides.AllowHostAccess).To(BeFalse())
+	})
+
+	It("returns an error when the host access property is not a boolean", func() {
+		_, err := kawasaki.ParseOverrides(garden.Properties{
+			gardener.NetworkAllowHostAccessKey: "sometimes",
+		})
+		Expect(err).To(MatchError("invalid value for garden.network.allow-host-access: sometimes"))
+	})
+
+	It("parses the comma-separated denied networks", func() {
+		overrides, err := kawasaki.ParseOverrides(garden.Properties{
+			gardener.NetworkDenyNetworksKey: "10.0.0.0/8, 192.168.1.1/24,",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(overrides.DenyNetworks).To(HaveLen(2))
+		Expect(overrides.DenyNetworks[0].String()).To(Equal("10.0.0.0/8"))
+		Expect(overrides.DenyNetworks[1].String()).To(Equal("192.168.1.0/24"))
+	})
+
+	It("returns an error when a denied network is not a CIDR", func() {
+		_, err := kawasaki.ParseOverrides(garden.Properties{
+			gardener.NetworkDenyNetworksKey: "10.0.0.0/8,banana",
+		})
+		Expect(err).To(MatchError(ContainSubstring("invalid network in garden.network.deny-networks")))
+	})
+})
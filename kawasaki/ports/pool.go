@@ -2,17 +2,28 @@ package ports
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 )
 
+// Range is a contiguous range of ports, starting at Start
+type Range struct {
+	Start uint32
+	Size  uint32
+}
+
+func (r Range) contains(port uint32) bool {
+	return port >= r.Start && port < r.Start+r.Size
+}
+
 type PortPool struct {
-	start uint32
-	size  uint32
+	ranges    []Range
+	reserved  map[uint32]bool
+	statePath string
 
 	pool      []uint32
+	allocated map[uint32]string
 	poolMutex sync.Mutex
-
-	state State
 }
 
 type PoolExhaustedError struct{}
@@ -30,35 +41,65 @@ func (e PortTakenError) Error() string {
 }
 
 func NewPool(start, size uint32, state State) (*PortPool, error) {
-	if start+size > 65535 {
-		return nil, fmt.Errorf("port_pool: New: invalid port range: startL %d, size: %d", start, size)
+	return NewPoolFromRanges([]Range{{Start: start, Size: size}}, nil, state, "")
+}
+
+// NewPoolFromRanges creates a pool handing out the ports of the given ranges,
+// except for the reserved ports. If statePath is not empty, the pool's state
+// is saved there whenever ports are allocated or released, so that the
+// allocations survive a crash.
+func NewPoolFromRanges(ranges []Range, reserved []uint32, state State, statePath string) (*PortPool, error) {
+	var size uint32
+	for i, r := range ranges {
+		if r.Start+r.Size > 65535 {
+			return nil, fmt.Errorf("port_pool: New: invalid port range: startL %d, size: %d", r.Start, r.Size)
+		}
+
+		for _, other := range ranges[:i] {
+			if r.Start < other.Start+other.Size && other.Start < r.Start+r.Size {
+				return nil, fmt.Errorf("port_pool: New: overlapping port ranges: start %d, size %d and start %d, size %d", other.Start, other.Size, r.Start, r.Size)
+			}
+		}
+
+		size += r.Size
 	}
 
 	if state.Offset >= size {
 		state.Offset = 0
 	}
 
-	pool := make([]uint32, size)
+	p := &PortPool{
+		ranges:    ranges,
+		reserved:  make(map[uint32]bool),
+		statePath: statePath,
+		allocated: make(map[uint32]string),
+	}
 
-	i := 0
-	for port := start + state.Offset; port < start+size; port++ {
-		pool[i] = port
-		i += 1
+	for _, port := range reserved {
+		p.reserved[port] = true
 	}
-	for port := start; port < start+state.Offset; port++ {
-		pool[i] = port
-		i += 1
+
+	for handle, ports := range state.Allocations {
+		for _, port := range ports {
+			p.allocated[port] = handle
+		}
 	}
 
-	return &PortPool{
-		start: start,
-		size:  size,
+	all := p.allPorts()
+	p.pool = make([]uint32, 0, len(all))
+	rotated := append(append([]uint32{}, all[state.Offset:]...), all[:state.Offset]...)
+	for _, port := range rotated {
+		if _, ok := p.allocated[port]; ok || p.reserved[port] {
+			continue
+		}
+
+		p.pool = append(p.pool, port)
+	}
 
-		pool: pool,
-	}, nil
+	return p, nil
 }
 
-func (p *PortPool) Acquire() (uint32, error) {
+func (p *PortPool) Acquire(handle string) (uint32, error) {
 	p.poolMutex.Lock()
 	defer p.poolMutex.Unlock()
 
@@ -67,44 +108,128 @@ func (p *PortPool) Acquire() (uint32, error) {
 	}
 
 	port := p.pool[0]
-
 	p.pool = p.pool[1:]
+	p.allocated[port] = handle
+
+	if err := p.persist(); err != nil {
+		delete(p.allocated, port)
+		p.pool = append([]uint32{port}, p.pool...)
+		return 0, err
+	}
 
 	return port, nil
 }
 
-func (p *PortPool) Remove(port uint32) error {
-	idx := 0
-	found := false
-
+// Remove allocates a specific port to a container. Ports which the pool does
+// not hand out, and ports already allocated to the container, are ignored.
+func (p *PortPool) Remove(handle string, port uint32) error {
 	p.poolMutex.Lock()
 	defer p.poolMutex.Unlock()
 
+	if owner, ok := p.allocated[port]; ok {
+		if owner == handle {
+			return nil
+		}
+
+		return PortTakenError{port}
+	}
+
+	if !p.inRanges(port) || p.reserved[port] {
+		return nil
+	}
+
+	idx := -1
 	for i, existingPort := range p.pool {
 		if existingPort == port {
 			idx = i
-			found = true
 			break
 		}
 	}
 
-	if !found {
+	if idx == -1 {
 		return PortTakenError{port}
 	}
 
 	p.pool = append(p.pool[:idx], p.pool[idx+1:]...)
+	p.allocated[port] = handle
+
+	if err := p.persist(); err != nil {
+		delete(p.allocated, port)
+		p.pool = append(p.pool, port)
+		return err
+	}
 
 	return nil
 }
 
 func (p *PortPool) Release(port uint32) {
-	if port < p.start || port >= p.start+p.size {
-		return
+	p.poolMutex.Lock()
+	defer p.poolMutex.Unlock()
+
+	p.release(port)
+
+	// a failure to persist leaves the port allocated in the saved state, to be
+	// corrected by the next successful save
+	p.persist()
+}
+
+// ReleaseAll releases all the ports allocated to a container
+func (p *PortPool) ReleaseAll(handle string) {
+	p.poolMutex.Lock()
+	defer p.poolMutex.Unlock()
+
+	var ports []uint32
+	for port, owner := range p.allocated {
+		if owner == handle {
+			ports = append(ports, port)
+		}
+	}
+	sort.Sort(portList(ports))
+
+	for _, port := range ports {
+		p.release(port)
 	}
 
+	p.persist()
+}
+
+// Prune releases the ports allocated to containers other than the given ones,
+// e.g. to containers destroyed while the server was not running
+func (p *PortPool) Prune(handles []string) error {
 	p.poolMutex.Lock()
 	defer p.poolMutex.Unlock()
 
+	existing := make(map[string]bool)
+	for _, handle := range handles {
+		existing[handle] = true
+	}
+
+	var ports []uint32
+	for port, owner := range p.allocated {
+		if !existing[owner] {
+			ports = append(ports, port)
+		}
+	}
+
+	if len(ports) == 0 {
+		return nil
+	}
+
+	sort.Sort(portList(ports))
+	for _, port := range ports {
+		p.release(port)
+	}
+
+	return p.persist()
+}
+
+func (p *PortPool) release(port uint32) {
+	delete(p.allocated, port)
+
+	if !p.inRanges(port) || p.reserved[port] {
+		return
+	}
+
 	for _, existingPort := range p.pool {
 		if existingPort == port {
 			return
@@ -114,11 +239,102 @@ func (p *PortPool) Release(port uint32) {
 	p.pool = append(p.pool, port)
 }
 
+// Capacity returns the number of ports the pool can hand out
+func (p *PortPool) Capacity() int {
+	capacity := 0
+	for _, r := range p.ranges {
+		capacity += int(r.Size)
+	}
+
+	for port := range p.reserved {
+		if p.inRanges(port) {
+			capacity--
+		}
+	}
+
+	return capacity
+}
+
+// Allocated returns the number of ports handed out by the pool
+func (p *PortPool) Allocated() int {
+	p.poolMutex.Lock()
+	defer p.poolMutex.Unlock()
+
+	return p.Capacity() - len(p.pool)
+}
+
 func (p *PortPool) RefreshState() State {
-	if len(p.pool) == 0 {
-		p.state.Offset = 0
-	} else {
-		p.state.Offset = p.pool[0] - p.start
+	p.poolMutex.Lock()
+	defer p.poolMutex.Unlock()
+
+	return p.currentState()
+}
+
+func (p *PortPool) currentState() State {
+	state := State{}
+	if len(p.pool) > 0 {
+		state.Offset = p.position(p.pool[0])
 	}
-	return p.state
+
+	if len(p.allocated) > 0 {
+		state.Allocations = make(map[string][]uint32)
+		for port, handle := range p.allocated {
+			state.Allocations[handle] = append(state.Allocations[handle], port)
+		}
+
+		for _, ports := range state.Allocations {
+			sort.Sort(portList(ports))
+		}
+	}
+
+	return state
+}
+
+func (p *PortPool) persist() error {
+	if p.statePath == "" {
+		return nil
+	}
+
+	return SaveState(p.statePath, p.currentState())
+}
+
+func (p *PortPool) allPorts() []uint32 {
+	var ports []uint32
+	for _, r := range p.ranges {
+		for port := r.Start; port < r.Start+r.Size; port++ {
+			ports = append(ports, port)
+		}
+	}
+
+	return ports
 }
+
+// position returns the index of a port among the ports of all ranges
+func (p *PortPool) position(port uint32) uint32 {
+	var offset uint32
+	for _, r := range p.ranges {
+		if r.contains(port) {
+			return offset + port - r.Start
+		}
+
+		offset += r.Size
+	}
+
+	return 0
+}
+
+func (p *PortPool) inRanges(port uint32) bool {
+	for _, r := range p.ranges {
+		if r.contains(port) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type portList []uint32
+
+func (l portList) Len() int           { return len(l) }
+func (l portList) Less(i, j int) bool { return l[i] < l[j] }
+func (l portList) Swap(i, j int)      { l[i], l[j] = l[j], l[i] }
@@ -1,6 +1,10 @@
 package ports_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	"code.cloudfoundry.org/guardian/kawasaki/ports"
 
 	. "github.com/onsi/ginkgo"
@@ -28,10 +32,10 @@ var _ = Describe("Port pool", func() {
 			pool, err := ports.NewPool(10000, 5, initialState)
 			Expect(err).ToNot(HaveOccurred())
 
-			port1, err := pool.Acquire()
+			port1, err := pool.Acquire("some-handle")
 			Expect(err).ToNot(HaveOccurred())
 
-			port2, err := pool.Acquire()
+			port2, err := pool.Acquire("some-handle")
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(port1).To(Equal(uint32(10000)))
@@ -44,11 +48,11 @@ var _ = Describe("Port pool", func() {
 				Expect(err).ToNot(HaveOccurred())
 
 				for i := 0; i < 5; i++ {
-					_, err := pool.Acquire()
+					_, err := pool.Acquire("some-handle")
 					Expect(err).ToNot(HaveOccurred())
 				}
 
-				_, err = pool.Acquire()
+				_, err = pool.Acquire("some-handle")
 				Expect(err).To(HaveOccurred())
 			})
 		})
@@ -59,10 +63,10 @@ var _ = Describe("Port pool", func() {
 				pool, err := ports.NewPool(10000, 5, initialState)
 				Expect(err).ToNot(HaveOccurred())
 
-				port1, err := pool.Acquire()
+				port1, err := pool.Acquire("some-handle")
 				Expect(err).ToNot(HaveOccurred())
 
-				port2, err := pool.Acquire()
+				port2, err := pool.Acquire("some-handle")
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(port1).To(Equal(uint32(10002)))
@@ -74,7 +78,7 @@ var _ = Describe("Port pool", func() {
 					pool, err := ports.NewPool(10000, 5, initialState)
 					Expect(err).ToNot(HaveOccurred())
 
-					port, err := pool.Acquire()
+					port, err := pool.Acquire("some-handle")
 					Expect(err).ToNot(HaveOccurred())
 					Expect(port).To(Equal(uint32(10000)))
 				})
@@ -88,12 +92,12 @@ var _ = Describe("Port pool", func() {
 				pool, err := ports.NewPool(startPort, 5, initialState)
 				Expect(err).ToNot(HaveOccurred())
 
-				port, err := pool.Acquire()
+				port, err := pool.Acquire("some-handle")
 				Expect(port).To(Equal(uint32(10004)))
 				Expect(err).ToNot(HaveOccurred())
 
 				for i := uint32(0); i < portOffset; i++ {
-					port, err := pool.Acquire()
+					port, err := pool.Acquire("some-handle")
 					Expect(err).ToNot(HaveOccurred())
 					Expect(port).To(Equal(startPort + i))
 				}
@@ -106,14 +110,14 @@ var _ = Describe("Port pool", func() {
 			pool, err := ports.NewPool(10000, 2, initialState)
 			Expect(err).ToNot(HaveOccurred())
 
-			err = pool.Remove(10000)
+			err = pool.Remove("some-handle", 10000)
 			Expect(err).ToNot(HaveOccurred())
 
-			port, err := pool.Acquire()
+			port, err := pool.Acquire("some-handle")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(port).To(Equal(uint32(10001)))
 
-			_, err = pool.Acquire()
+			_, err = pool.Acquire("some-handle")
 			Expect(err).To(HaveOccurred())
 		})
 
@@ -122,10 +126,10 @@ var _ = Describe("Port pool", func() {
 				pool, err := ports.NewPool(10000, 2, initialState)
 				Expect(err).ToNot(HaveOccurred())
 
-				port, err := pool.Acquire()
+				port, err := pool.Acquire("some-handle")
 				Expect(err).ToNot(HaveOccurred())
 
-				err = pool.Remove(port)
+				err = pool.Remove("another-handle", port)
 				Expect(err).To(Equal(ports.PortTakenError{Port: port}))
 			})
 		})
@@ -136,17 +140,17 @@ var _ = Describe("Port pool", func() {
 			pool, err := ports.NewPool(10000, 2, initialState)
 			Expect(err).ToNot(HaveOccurred())
 
-			port1, err := pool.Acquire()
+			port1, err := pool.Acquire("some-handle")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(port1).To(Equal(uint32(10000)))
 
 			pool.Release(port1)
 
-			port2, err := pool.Acquire()
+			port2, err := pool.Acquire("some-handle")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(port2).To(Equal(uint32(10001)))
 
-			nextPort, err := pool.Acquire()
+			nextPort, err := pool.Acquire("some-handle")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(nextPort).To(Equal(uint32(10000)))
 		})
@@ -158,7 +162,7 @@ var _ = Describe("Port pool", func() {
 
 				pool.Release(20000)
 
-				_, err = pool.Acquire()
+				_, err = pool.Acquire("some-handle")
 				Expect(err).To(HaveOccurred())
 			})
 		})
@@ -168,22 +172,22 @@ var _ = Describe("Port pool", func() {
 				pool, err := ports.NewPool(10000, 2, initialState)
 				Expect(err).ToNot(HaveOccurred())
 
-				port1, err := pool.Acquire()
+				port1, err := pool.Acquire("some-handle")
 				Expect(err).ToNot(HaveOccurred())
 				Expect(port1).To(Equal(uint32(10000)))
 
 				pool.Release(port1)
 				pool.Release(port1)
 
-				port2, err := pool.Acquire()
+				port2, err := pool.Acquire("some-handle")
 				Expect(err).ToNot(HaveOccurred())
 				Expect(port2).ToNot(Equal(port1))
 
-				port3, err := pool.Acquire()
+				port3, err := pool.Acquire("some-handle")
 				Expect(err).ToNot(HaveOccurred())
 				Expect(port3).To(Equal(port1))
 
-				_, err = pool.Acquire()
+				_, err = pool.Acquire("some-handle")
 				Expect(err).To(HaveOccurred())
 			})
 		})
@@ -194,7 +198,7 @@ var _ = Describe("Port pool", func() {
 			pool, err := ports.NewPool(10000, 5, initialState)
 			Expect(err).ToNot(HaveOccurred())
 
-			_, err = pool.Acquire()
+			_, err = pool.Acquire("some-handle")
 			Expect(err).NotTo(HaveOccurred())
 
 			newState := pool.RefreshState()
@@ -206,7 +210,7 @@ var _ = Describe("Port pool", func() {
 				pool, err := ports.NewPool(10000, 1, initialState)
 				Expect(err).ToNot(HaveOccurred())
 
-				_, err = pool.Acquire()
+				_, err = pool.Acquire("some-handle")
 				Expect(err).NotTo(HaveOccurred())
 
 				newState := pool.RefreshState()
@@ -214,4 +218,305 @@ var _ = Describe("Port pool", func() {
 			})
 		})
 	})
+
+	Describe("removing a port already allocated", func() {
+		It("succeeds if the port is allocated to the same container", func() {
+			pool, err := ports.NewPool(10000, 2, initialState)
+			Expect(err).ToNot(HaveOccurred())
+
+			port, err := pool.Acquire("some-handle")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(pool.Remove("some-handle", port)).To(Succeed())
+		})
+
+		It("ignores ports the pool does not hand out", func() {
+			pool, err := ports.NewPool(10000, 2, initialState)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(pool.Remove("some-handle", 80)).To(Succeed())
+			Expect(pool.Remove("another-handle", 80)).To(Succeed())
+		})
+	})
+
+	Describe("releasing all of a container's ports", func() {
+		It("places the container's ports back at the end of the pool", func() {
+			pool, err := ports.NewPool(10000, 3, initialState)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = pool.Acquire("some-handle")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = pool.Acquire("another-handle")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = pool.Acquire("some-handle")
+			Expect(err).ToNot(HaveOccurred())
+
+			pool.ReleaseAll("some-handle")
+
+			port, err := pool.Acquire("third-handle")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(port).To(Equal(uint32(10000)))
+
+			port, err = pool.Acquire("third-handle")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(port).To(Equal(uint32(10002)))
+
+			_, err = pool.Acquire("third-handle")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("multiple ranges", func() {
+		It("hands out the ports of each range in turn", func() {
+			pool, err := ports.NewPoolFromRanges([]ports.Range{{Start: 10000, Size: 2}, {Start: 20000, Size: 2}}, nil, initialState, "")
+			Expect(err).ToNot(HaveOccurred())
+
+			var acquired []uint32
+			for i := 0; i < 4; i++ {
+				port, err := pool.Acquire("some-handle")
+				Expect(err).ToNot(HaveOccurred())
+				acquired = append(acquired, port)
+			}
+
+			Expect(acquired).To(Equal([]uint32{10000, 10001, 20000, 20001}))
+
+			_, err = pool.Acquire("some-handle")
+			Expect(err).To(MatchError(ports.PoolExhaustedError{}))
+		})
+
+		It("honours an offset across the ranges", func() {
+			initialState.Offset = 3
+			pool, err := ports.NewPoolFromRanges([]ports.Range{{Start: 10000, Size: 2}, {Start: 20000, Size: 2}}, nil, initialState, "")
+			Expect(err).ToNot(HaveOccurred())
+
+			port, err := pool.Acquire("some-handle")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(port).To(Equal(uint32(20001)))
+
+			Expect(pool.RefreshState().Offset).To(BeNumerically("==", 0))
+		})
+
+		It("does not release ports outside of the ranges back into the pool", func() {
+			pool, err := ports.NewPoolFromRanges([]ports.Range{{Start: 10000, Size: 1}, {Start: 20000, Size: 1}}, nil, initialState, "")
+			Expect(err).ToNot(HaveOccurred())
+
+			pool.Release(15000)
+			Expect(pool.Capacity()).To(Equal(2))
+			Expect(pool.Allocated()).To(Equal(0))
+		})
+
+		Context("when the ranges overlap", func() {
+			It("returns an error", func() {
+				_, err := ports.NewPoolFromRanges([]ports.Range{{Start: 10000, Size: 10}, {Start: 10005, Size: 10}}, nil, initialState, "")
+				Expect(err).To(MatchError(ContainSubstring("overlapping port ranges")))
+			})
+		})
+
+		Context("when a range exceeds the Linux limit", func() {
+			It("returns an error", func() {
+				_, err := ports.NewPoolFromRanges([]ports.Range{{Start: 10000, Size: 10}, {Start: 61001, Size: 5000}}, nil, initialState, "")
+				Expect(err).To(MatchError(ContainSubstring("invalid port range")))
+			})
+		})
+	})
+
+	Describe("reserved ports", func() {
+		var pool *ports.PortPool
+
+		BeforeEach(func() {
+			var err error
+			pool, err = ports.NewPoolFromRanges([]ports.Range{{Start: 10000, Size: 3}}, []uint32{10001, 30000}, initialState, "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("never hands them out", func() {
+			port1, err := pool.Acquire("some-handle")
+			Expect(err).ToNot(HaveOccurred())
+			port2, err := pool.Acquire("some-handle")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect([]uint32{port1, port2}).To(Equal([]uint32{10000, 10002}))
+
+			_, err = pool.Acquire("some-handle")
+			Expect(err).To(MatchError(ports.PoolExhaustedError{}))
+		})
+
+		It("does not release them into the pool", func() {
+			pool.Release(10001)
+			Expect(pool.Allocated()).To(Equal(0))
+			Expect(pool.Capacity()).To(Equal(2))
+		})
+
+		It("allows them to be removed, e.g. when mapped explicitly", func() {
+			Expect(pool.Remove("some-handle", 10001)).To(Succeed())
+			Expect(pool.Allocated()).To(Equal(0))
+		})
+
+		It("does not count them towards the capacity", func() {
+			Expect(pool.Capacity()).To(Equal(2))
+		})
+	})
+
+	Describe("allocations", func() {
+		It("are reported by handle in the state", func() {
+			pool, err := ports.NewPool(10000, 5, initialState)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = pool.Acquire("some-handle")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = pool.Acquire("another-handle")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(pool.Remove("some-handle", 10004)).To(Succeed())
+
+			Expect(pool.RefreshState().Allocations).To(Equal(map[string][]uint32{
+				"some-handle":    {10000, 10004},
+				"another-handle": {10001},
+			}))
+			Expect(pool.Allocated()).To(Equal(3))
+		})
+
+		It("are no longer reported once released", func() {
+			pool, err := ports.NewPool(10000, 5, initialState)
+			Expect(err).ToNot(HaveOccurred())
+
+			port, err := pool.Acquire("some-handle")
+			Expect(err).ToNot(HaveOccurred())
+			pool.Release(port)
+
+			Expect(pool.RefreshState().Allocations).To(BeEmpty())
+			Expect(pool.Allocated()).To(Equal(0))
+		})
+
+		Context("when the state has allocations", func() {
+			var pool *ports.PortPool
+
+			BeforeEach(func() {
+				initialState.Allocations = map[string][]uint32{
+					"some-handle":    {10000, 10002},
+					"another-handle": {10001},
+				}
+
+				var err error
+				pool, err = ports.NewPool(10000, 5, initialState)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("does not hand out the allocated ports", func() {
+				port, err := pool.Acquire("third-handle")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(port).To(Equal(uint32(10003)))
+			})
+
+			It("allows the allocated ports to be removed again by the same container", func() {
+				Expect(pool.Remove("some-handle", 10002)).To(Succeed())
+			})
+
+			It("does not allow the allocated ports to be removed by another container", func() {
+				Expect(pool.Remove("third-handle", 10002)).To(Equal(ports.PortTakenError{Port: 10002}))
+			})
+
+			It("releases the allocated ports by handle", func() {
+				pool.ReleaseAll("some-handle")
+
+				Expect(pool.RefreshState().Allocations).To(Equal(map[string][]uint32{
+					"another-handle": {10001},
+				}))
+			})
+
+			It("prunes the allocations of containers which no longer exist", func() {
+				Expect(pool.Prune([]string{"another-handle", "third-handle"})).To(Succeed())
+
+				Expect(pool.RefreshState().Allocations).To(Equal(map[string][]uint32{
+					"another-handle": {10001},
+				}))
+				Expect(pool.Allocated()).To(Equal(1))
+			})
+		})
+	})
+
+	Describe("persistence", func() {
+		var (
+			tmpDir    string
+			statePath string
+			pool      *ports.PortPool
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "")
+			Expect(err).NotTo(HaveOccurred())
+			statePath = filepath.Join(tmpDir, "ports.json")
+
+			pool, err = ports.NewPoolFromRanges([]ports.Range{{Start: 10000, Size: 5}}, nil, initialState, statePath)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(tmpDir)).To(Succeed())
+		})
+
+		It("saves the state when a port is acquired", func() {
+			_, err := pool.Acquire("some-handle")
+			Expect(err).ToNot(HaveOccurred())
+
+			state, err := ports.LoadState(statePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Allocations).To(Equal(map[string][]uint32{"some-handle": {10000}}))
+			Expect(state.Offset).To(BeNumerically("==", 1))
+		})
+
+		It("saves the state when a port is removed", func() {
+			Expect(pool.Remove("some-handle", 10003)).To(Succeed())
+
+			state, err := ports.LoadState(statePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Allocations).To(Equal(map[string][]uint32{"some-handle": {10003}}))
+		})
+
+		It("saves the state when ports are released", func() {
+			port, err := pool.Acquire("some-handle")
+			Expect(err).ToNot(HaveOccurred())
+			pool.Release(port)
+
+			state, err := ports.LoadState(statePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Allocations).To(BeEmpty())
+
+			_, err = pool.Acquire("some-handle")
+			Expect(err).ToNot(HaveOccurred())
+			pool.ReleaseAll("some-handle")
+
+			state, err = ports.LoadState(statePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Allocations).To(BeEmpty())
+		})
+
+		It("saves the state when allocations are pruned", func() {
+			_, err := pool.Acquire("some-handle")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(pool.Prune(nil)).To(Succeed())
+
+			state, err := ports.LoadState(statePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Allocations).To(BeEmpty())
+		})
+
+		Context("when the state cannot be saved", func() {
+			BeforeEach(func() {
+				Expect(os.RemoveAll(tmpDir)).To(Succeed())
+			})
+
+			It("does not acquire the port", func() {
+				_, err := pool.Acquire("some-handle")
+				Expect(err).To(MatchError(ContainSubstring("creating state file")))
+				Expect(pool.Allocated()).To(Equal(0))
+			})
+
+			It("does not remove the port", func() {
+				Expect(pool.Remove("some-handle", 10003)).To(MatchError(ContainSubstring("creating state file")))
+				Expect(pool.Allocated()).To(Equal(0))
+			})
+		})
+	})
 })
@@ -3,11 +3,16 @@ package ports
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 )
 
 type State struct {
 	Offset uint32 `json:"offset"`
+
+	// Allocations holds the ports allocated to each container, by handle
+	Allocations map[string][]uint32 `json:"allocations,omitempty"`
 }
 
 type StateFileNotFoundError struct {
@@ -41,13 +46,48 @@ func LoadState(filePath string) (State, error) {
 	return state, nil
 }
 
+// SaveState writes the state to a temporary file and renames it into place,
+// so that the file is never left partially written. Both the file and its
+// directory are synced, so that the state survives a crash of the host.
 func SaveState(filePath string, state State) error {
-	stateFile, err := os.Create(filePath)
+	stateFile, err := ioutil.TempFile(filepath.Dir(filePath), filepath.Base(filePath))
 	if err != nil {
 		return fmt.Errorf("creating state file: %s", err)
 	}
-	defer stateFile.Close()
+	defer os.Remove(stateFile.Name())
+
+	if err := json.NewEncoder(stateFile).Encode(state); err != nil {
+		stateFile.Close()
+		return fmt.Errorf("writing state file: %s", err)
+	}
+
+	if err := stateFile.Sync(); err != nil {
+		stateFile.Close()
+		return fmt.Errorf("syncing state file: %s", err)
+	}
+
+	if err := stateFile.Close(); err != nil {
+		return fmt.Errorf("writing state file: %s", err)
+	}
+
+	if err := os.Rename(stateFile.Name(), filePath); err != nil {
+		return fmt.Errorf("renaming state file: %s", err)
+	}
+
+	return syncDir(filepath.Dir(filePath))
+}
+
+// syncDir syncs a directory, persisting the renaming of a file in it
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("syncing state file directory: %s", err)
+	}
+	defer dir.Close()
+
+	if err := dir.Sync(); err != nil {
+		return fmt.Errorf("syncing state file directory: %s", err)
+	}
 
-	json.NewEncoder(stateFile).Encode(state)
 	return nil
 }
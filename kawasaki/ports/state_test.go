@@ -40,6 +40,18 @@ var _ = Describe("State", func() {
 			Expect(portPoolState.Offset).To(BeNumerically("==", 10))
 		})
 
+		It("should parse the allocations", func() {
+			Expect(ioutil.WriteFile(filePath, []byte(`{
+				"offset": 10,
+				"allocations": {"some-handle": [60000, 60002]}
+			}`), 0660)).To(Succeed())
+
+			portPoolState, err := ports.LoadState(filePath)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(portPoolState.Allocations).To(Equal(map[string][]uint32{"some-handle": {60000, 60002}}))
+		})
+
 		Context("when the file does not exist", func() {
 			It("should return a wrapped error", func() {
 				_, err := ports.LoadState("/path/to/not/existing/banana")
@@ -73,6 +85,27 @@ var _ = Describe("State", func() {
 			Expect(string(contents)).To(ContainSubstring("\"offset\":10"))
 		})
 
+		It("should round-trip the allocations", func() {
+			state := ports.State{
+				Offset:      10,
+				Allocations: map[string][]uint32{"some-handle": {60000, 60002}},
+			}
+
+			Expect(ports.SaveState(filePath, state)).To(Succeed())
+
+			loaded, err := ports.LoadState(filePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded).To(Equal(state))
+		})
+
+		It("should not leave temporary files behind", func() {
+			Expect(ports.SaveState(filePath, ports.State{Offset: 10})).To(Succeed())
+
+			files, err := ioutil.ReadDir(tmpDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(HaveLen(1))
+		})
+
 		Context("when file can not be created", func() {
 			It("should return a sensible error", func() {
 				state := ports.State{
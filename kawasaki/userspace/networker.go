@@ -0,0 +1,409 @@
+package userspace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/lager"
+)
+
+// userspace-specific state properties
+const pidKey = "userspace.pid"
+const netOutRulesKey = "userspace.netout-rules"
+
+// Networker networks containers without programming the host's kernel, for
+// servers which are not running as root. Containers have only a loopback
+// interface. NetIn is served by TCP and UDP proxies listening on the host
+// port, and outbound TCP connections are made through a SOCKS5 proxy on the
+// container's loopback interface, which enforces the global policy and the
+// container's NetOut rules. Containers cannot send UDP or ICMP out, and have
+// no DNS server, so only programs which use the proxy (see ProxyEnv) can
+// reach other hosts.
+type Networker struct {
+	log          lager.Logger
+	socketOpener SocketOpener
+	configStore  kawasaki.ConfigStore
+	portPool     kawasaki.PortPool
+	externalIP   net.IP
+	proxyPort    uint32
+	firewall     *hostFirewall
+	dial         Dialer
+	resolve      Resolver
+
+	mu         sync.Mutex
+	containers map[string]*container
+}
+
+func New(
+	log lager.Logger,
+	socketOpener SocketOpener,
+	configStore kawasaki.ConfigStore,
+	portPool kawasaki.PortPool,
+	externalIP net.IP,
+	proxyPort uint32,
+	policy kawasaki.GlobalPolicy,
+	dial Dialer,
+	resolve Resolver,
+	interfaceAddrs InterfaceAddrs,
+) *Networker {
+	return &Networker{
+		log:          log,
+		socketOpener: socketOpener,
+		configStore:  configStore,
+		portPool:     portPool,
+		externalIP:   externalIP,
+		proxyPort:    proxyPort,
+		firewall:     &hostFirewall{policy: policy, interfaceAddrs: interfaceAddrs},
+		dial:         dial,
+		resolve:      resolve,
+
+		containers: make(map[string]*container),
+	}
+}
+
+// container is the userspace network of a running container
+type container struct {
+	sockets Sockets
+	rules   *netOutRules
+
+	mu      sync.Mutex
+	proxies []io.Closer
+}
+
+func (c *container) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, proxy := range c.proxies {
+		proxy.Close()
+	}
+
+	c.sockets.Close()
+}
+
+// stop closes some of the container's proxies, e.g. when a NetIn fails
+func (c *container) stop(proxies []io.Closer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var running []io.Closer
+	for _, proxy := range c.proxies {
+		if !containsCloser(proxies, proxy) {
+			running = append(running, proxy)
+		}
+	}
+	c.proxies = running
+
+	for _, proxy := range proxies {
+		proxy.Close()
+	}
+}
+
+func containsCloser(closers []io.Closer, closer io.Closer) bool {
+	for _, c := range closers {
+		if c == closer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ProxyEnv is the environment which points proxy-aware programs in a
+// container at its outbound proxy. The socks5h scheme has the proxy resolve
+// host names, as the container cannot.
+func ProxyEnv(proxyPort uint32) []string {
+	proxy := fmt.Sprintf("socks5h://127.0.0.1:%d", proxyPort)
+	return []string{"ALL_PROXY=" + proxy, "all_proxy=" + proxy}
+}
+
+func (n *Networker) Start() error { return nil }
+
+func (n *Networker) Network(log lager.Logger, containerSpec garden.ContainerSpec, pid int) error {
+	log = log.Session("network", lager.Data{
+		"handle": containerSpec.Handle,
+		"spec":   containerSpec.Network,
+	})
+
+	log.Info("started")
+	defer log.Info("finished")
+
+	if containerSpec.Network != "" {
+		return errors.New("network specs are not supported by userspace networking")
+	}
+
+	n.configStore.Set(containerSpec.Handle, gardener.ExternalIPKey, n.externalIP.String())
+	n.configStore.Set(containerSpec.Handle, pidKey, strconv.Itoa(pid))
+
+	c, err := n.start(log, containerSpec.Handle, pid)
+	if err != nil {
+		return err
+	}
+
+	for _, netIn := range containerSpec.NetIn {
+		if _, _, err := n.netIn(log, containerSpec.Handle, c, netIn.HostPort, netIn.ContainerPort); err != nil {
+			return err
+		}
+	}
+
+	return n.BulkNetOut(log, containerSpec.Handle, containerSpec.NetOut)
+}
+
+// start opens the container's network namespace and starts its outbound proxy
+func (n *Networker) start(log lager.Logger, handle string, pid int) (*container, error) {
+	sockets, err := n.socketOpener.Open(log, pid)
+	if err != nil {
+		log.Error("open-sockets-failed", err)
+		return nil, err
+	}
+
+	listener, err := sockets.ListenTCP(n.proxyPort)
+	if err != nil {
+		sockets.Close()
+		log.Error("listen-failed", err)
+		return nil, err
+	}
+
+	c := &container{sockets: sockets, rules: &netOutRules{}}
+	proxy := &socksProxy{
+		log:      n.log.Session("proxy", lager.Data{"handle": handle}),
+		listener: listener,
+		firewall: n.firewall,
+		rules:    c.rules,
+		dial:     n.dial,
+		resolve:  n.resolve,
+	}
+	c.proxies = append(c.proxies, proxy)
+	go proxy.serve()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if existing, ok := n.containers[handle]; ok {
+		existing.close()
+	}
+	n.containers[handle] = c
+
+	return c, nil
+}
+
+func (n *Networker) container(handle string) (*container, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	c, ok := n.containers[handle]
+	if !ok {
+		return nil, fmt.Errorf("no network for container %s", handle)
+	}
+
+	return c, nil
+}
+
+// Capacity is unlimited, as containers do not share any addresses
+func (n *Networker) Capacity() uint64 {
+	return math.MaxUint64
+}
+
+func (n *Networker) NetIn(log lager.Logger, handle string, hostPort, containerPort uint32) (uint32, uint32, error) {
+	c, err := n.container(handle)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return n.netIn(log, handle, c, hostPort, containerPort)
+}
+
+func (n *Networker) netIn(log lager.Logger, handle string, c *container, hostPort, containerPort uint32) (uint32, uint32, error) {
+	// a port acquired here must go back to the pool if the NetIn fails
+	acquired := hostPort == 0
+	if acquired {
+		var err error
+		hostPort, err = n.portPool.Acquire(handle)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if containerPort == 0 {
+		containerPort = hostPort
+	}
+
+	proxies, err := n.forward(handle, c, hostPort, containerPort)
+	if err != nil {
+		log.Error("forward-failed", err, lager.Data{"host-port": hostPort, "container-port": containerPort})
+		if acquired {
+			n.portPool.Release(hostPort)
+		}
+		return 0, 0, err
+	}
+
+	if err := kawasaki.AddPortMapping(log, n.configStore, handle, kawasaki.PortMapping{
+		HostIP:        n.externalIP.String(),
+		HostPort:      hostPort,
+		ContainerPort: containerPort,
+	}); err != nil {
+		c.stop(proxies)
+		if acquired {
+			n.portPool.Release(hostPort)
+		}
+		return 0, 0, err
+	}
+
+	return hostPort, containerPort, nil
+}
+
+// forward starts proxying TCP connections and UDP datagrams to a host port,
+// on all of the host's addresses, to the container port. It returns the
+// proxies, which are closed with the container.
+func (n *Networker) forward(handle string, c *container, hostPort, containerPort uint32) ([]io.Closer, error) {
+	address := fmt.Sprintf(":%d", hostPort)
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	packetConn, err := net.ListenPacket("udp", address)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	log := n.log.Session("netin", lager.Data{"handle": handle, "host-port": hostPort, "container-port": containerPort})
+	tcp := &tcpProxy{
+		log:      log.Session("tcp"),
+		listener: listener,
+		dial:     func() (net.Conn, error) { return c.sockets.DialTCP(containerPort) },
+	}
+	udp := &udpProxy{
+		log:      log.Session("udp"),
+		conn:     packetConn,
+		dial:     func() (net.Conn, error) { return c.sockets.DialUDP(containerPort) },
+		sessions: make(map[string]net.Conn),
+	}
+
+	c.mu.Lock()
+	c.proxies = append(c.proxies, tcp, udp)
+	c.mu.Unlock()
+
+	go tcp.serve()
+	go udp.serve()
+
+	return []io.Closer{tcp, udp}, nil
+}
+
+func (n *Networker) NetOut(log lager.Logger, handle string, rule garden.NetOutRule) error {
+	return n.BulkNetOut(log, handle, []garden.NetOutRule{rule})
+}
+
+func (n *Networker) BulkNetOut(log lager.Logger, handle string, rules []garden.NetOutRule) error {
+	c, err := n.container(handle)
+	if err != nil {
+		return err
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	c.rules.add(rules)
+
+	var recorded []garden.NetOutRule
+	if value, ok := n.configStore.Get(handle, netOutRulesKey); ok {
+		if err := json.Unmarshal([]byte(value), &recorded); err != nil {
+			return err
+		}
+	}
+
+	b, err := json.Marshal(append(recorded, rules...))
+	if err != nil {
+		return err
+	}
+
+	n.configStore.Set(handle, netOutRulesKey, string(b))
+	return nil
+}
+
+func (n *Networker) Destroy(log lager.Logger, handle string) error {
+	n.mu.Lock()
+	c, ok := n.containers[handle]
+	delete(n.containers, handle)
+	n.mu.Unlock()
+
+	if ok {
+		c.close()
+	}
+
+	n.portPool.ReleaseAll(handle)
+	return nil
+}
+
+// Reload replaces the global policy which the containers' outbound proxies
+// enforce, for connections made from then on
+func (n *Networker) Reload(logger lager.Logger, policy kawasaki.GlobalPolicy) error {
+	log := logger.Session("reload-global-policy", lager.Data{"policy": policy})
+	log.Info("started")
+	defer log.Info("finished")
+
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	n.firewall.setPolicy(policy)
+	return nil
+}
+
+// Restore re-opens the network namespace of a container which was running
+// while the server was down, and restarts its proxies
+func (n *Networker) Restore(log lager.Logger, handle string) error {
+	value, ok := n.configStore.Get(handle, pidKey)
+	if !ok {
+		return fmt.Errorf("loading %s: property not found: %s", handle, pidKey)
+	}
+
+	pid, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("loading %s: %v", handle, err)
+	}
+
+	c, err := n.start(log, handle, pid)
+	if err != nil {
+		return fmt.Errorf("opening network of %s: %v", handle, err)
+	}
+
+	mappings, err := kawasaki.PortMappings(n.configStore, handle)
+	if err != nil {
+		return fmt.Errorf("parsing port mappings of %s: %v", handle, err)
+	}
+
+	for _, mapping := range mappings {
+		// the port pool persists its own allocations, so this is only logged
+		if err := n.portPool.Remove(handle, mapping.HostPort); err != nil {
+			log.Error("port-pool-remove-failed", err, lager.Data{"handle": handle, "port": mapping.HostPort})
+		}
+
+		if _, err := n.forward(handle, c, mapping.HostPort, mapping.ContainerPort); err != nil {
+			return fmt.Errorf("forwarding port %d of %s: %v", mapping.HostPort, handle, err)
+		}
+	}
+
+	if value, ok := n.configStore.Get(handle, netOutRulesKey); ok {
+		var rules []garden.NetOutRule
+		if err := json.Unmarshal([]byte(value), &rules); err != nil {
+			return fmt.Errorf("parsing NetOut rules of %s: %v", handle, err)
+		}
+
+		c.rules.add(rules)
+	}
+
+	return nil
+}
@@ -0,0 +1,809 @@
+package userspace_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/kawasaki"
+	fakes "code.cloudfoundry.org/guardian/kawasaki/kawasakifakes"
+	"code.cloudfoundry.org/guardian/kawasaki/userspace"
+	"code.cloudfoundry.org/guardian/kawasaki/userspace/userspacefakes"
+	"code.cloudfoundry.org/guardian/properties"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Networker", func() {
+	var (
+		logger           *lagertest.TestLogger
+		fakeSocketOpener *userspacefakes.FakeSocketOpener
+		fakeSockets      *userspacefakes.FakeSockets
+		fakePortPool     *fakes.FakePortPool
+		configStore      kawasaki.ConfigStore
+		resolved         map[string][]net.IP
+		policy           kawasaki.GlobalPolicy
+		interfaceAddrs   func() ([]net.Addr, error)
+
+		// the addresses the outbound proxy has dialled, on behalf of the
+		// container
+		dialed chan string
+
+		// stand in for servers listening in the container
+		containerTCPServer net.Listener
+		containerUDPServer net.PacketConn
+
+		// the listener of the container's outbound proxy
+		proxyListener net.Listener
+
+		networker *userspace.Networker
+		spec      garden.ContainerSpec
+	)
+
+	newNetworker := func() *userspace.Networker {
+		// outbound connections to any address reach the container's echo
+		// server, which stands in for a server outside the host
+		tcpAddr := containerTCPServer.Addr().String()
+		dial := func(network, address string) (net.Conn, error) {
+			dialed <- address
+			return net.Dial(network, tcpAddr)
+		}
+
+		return userspace.New(
+			logger,
+			fakeSocketOpener,
+			configStore,
+			fakePortPool,
+			net.ParseIP("1.2.3.4"),
+			1080,
+			policy,
+			dial,
+			func(host string) ([]net.IP, error) {
+				ips, ok := resolved[host]
+				if !ok {
+					return nil, errors.New("no such host")
+				}
+				return ips, nil
+			},
+			interfaceAddrs,
+		)
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		logger = lagertest.NewTestLogger("test")
+		configStore = properties.NewManager()
+		resolved = map[string][]net.IP{}
+		policy = kawasaki.GlobalPolicy{}
+		dialed = make(chan string, 10)
+		interfaceAddrs = func() ([]net.Addr, error) {
+			return []net.Addr{&net.IPNet{IP: net.ParseIP("203.0.113.1"), Mask: net.CIDRMask(24, 32)}}, nil
+		}
+
+		containerTCPServer, err = net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		go serveTCPEcho(containerTCPServer)
+
+		containerUDPServer, err = net.ListenPacket("udp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		go serveUDPEcho(containerUDPServer)
+
+		// proxies may still be dialling after their spec has finished
+		tcpAddr, udpAddr := containerTCPServer.Addr().String(), containerUDPServer.LocalAddr().String()
+
+		fakeSockets = new(userspacefakes.FakeSockets)
+		fakeSockets.DialTCPStub = func(port uint32) (net.Conn, error) {
+			return net.Dial("tcp", tcpAddr)
+		}
+		fakeSockets.DialUDPStub = func(port uint32) (net.Conn, error) {
+			return net.Dial("udp", udpAddr)
+		}
+		fakeSockets.ListenTCPStub = func(port uint32) (net.Listener, error) {
+			var err error
+			proxyListener, err = net.Listen("tcp", "127.0.0.1:0")
+			return proxyListener, err
+		}
+
+		fakeSocketOpener = new(userspacefakes.FakeSocketOpener)
+		fakeSocketOpener.OpenReturns(fakeSockets, nil)
+
+		fakePortPool = new(fakes.FakePortPool)
+		fakePortPool.AcquireStub = func(string) (uint32, error) {
+			return freePort(), nil
+		}
+
+		networker = newNetworker()
+
+		spec = garden.ContainerSpec{Handle: "some-handle"}
+	})
+
+	AfterEach(func() {
+		networker.Destroy(logger, "some-handle")
+		containerTCPServer.Close()
+		containerUDPServer.Close()
+	})
+
+	Describe("Network", func() {
+		It("opens the network namespace of the container's process", func() {
+			Expect(networker.Network(logger, spec, 42)).To(Succeed())
+
+			Expect(fakeSocketOpener.OpenCallCount()).To(Equal(1))
+			_, pid := fakeSocketOpener.OpenArgsForCall(0)
+			Expect(pid).To(Equal(42))
+		})
+
+		It("records the external IP", func() {
+			Expect(networker.Network(logger, spec, 42)).To(Succeed())
+
+			externalIP, ok := configStore.Get("some-handle", gardener.ExternalIPKey)
+			Expect(ok).To(BeTrue())
+			Expect(externalIP).To(Equal("1.2.3.4"))
+		})
+
+		It("starts the outbound proxy on the proxy port of the container's loopback interface", func() {
+			Expect(networker.Network(logger, spec, 42)).To(Succeed())
+
+			Expect(fakeSockets.ListenTCPCallCount()).To(Equal(1))
+			Expect(fakeSockets.ListenTCPArgsForCall(0)).To(Equal(uint32(1080)))
+		})
+
+		It("forwards the ports in the spec", func() {
+			hostPort := freePort()
+			spec.NetIn = []garden.NetIn{{HostPort: hostPort, ContainerPort: 8080}}
+
+			Expect(networker.Network(logger, spec, 42)).To(Succeed())
+
+			Expect(roundTripTCP(hostPort, "hello")).To(Equal("hello"))
+			Expect(fakeSockets.DialTCPArgsForCall(0)).To(Equal(uint32(8080)))
+		})
+
+		It("applies the NetOut rules in the spec", func() {
+			spec.NetOut = []garden.NetOutRule{{Protocol: garden.ProtocolTCP}}
+
+			Expect(networker.Network(logger, spec, 42)).To(Succeed())
+
+			conn, reply := socksConnect(proxyListener.Addr().String(), "192.0.2.10:8080")
+			Expect(reply).To(Equal(byte(0x00)))
+			conn.Close()
+		})
+
+		Context("when the spec requests a subnet", func() {
+			It("returns an error", func() {
+				spec.Network = "10.0.0.0/24"
+
+				Expect(networker.Network(logger, spec, 42)).To(MatchError(ContainSubstring("not supported")))
+				Expect(fakeSocketOpener.OpenCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the network namespace cannot be opened", func() {
+			It("returns the error", func() {
+				fakeSocketOpener.OpenReturns(nil, errors.New("banana"))
+
+				Expect(networker.Network(logger, spec, 42)).To(MatchError("banana"))
+			})
+		})
+
+		Context("when the outbound proxy cannot listen", func() {
+			BeforeEach(func() {
+				fakeSockets.ListenTCPStub = nil
+				fakeSockets.ListenTCPReturns(nil, errors.New("banana"))
+			})
+
+			It("returns the error", func() {
+				Expect(networker.Network(logger, spec, 42)).To(MatchError("banana"))
+			})
+
+			It("closes the network namespace", func() {
+				networker.Network(logger, spec, 42)
+
+				Expect(fakeSockets.CloseCallCount()).To(Equal(1))
+			})
+		})
+	})
+
+	Describe("NetIn", func() {
+		BeforeEach(func() {
+			Expect(networker.Network(logger, spec, 42)).To(Succeed())
+		})
+
+		It("forwards TCP connections to the host port to the container port", func() {
+			hostPort := freePort()
+
+			externalPort, containerPort, err := networker.NetIn(logger, "some-handle", hostPort, 8080)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(externalPort).To(Equal(hostPort))
+			Expect(containerPort).To(Equal(uint32(8080)))
+
+			Expect(roundTripTCP(hostPort, "hello")).To(Equal("hello"))
+			Expect(roundTripTCP(hostPort, "again")).To(Equal("again"))
+
+			Expect(fakeSockets.DialTCPCallCount()).To(Equal(2))
+			Expect(fakeSockets.DialTCPArgsForCall(0)).To(Equal(uint32(8080)))
+		})
+
+		It("forwards UDP datagrams to the host port to the container port, and returns the replies", func() {
+			hostPort := freePort()
+
+			_, _, err := networker.NetIn(logger, "some-handle", hostPort, 8080)
+			Expect(err).NotTo(HaveOccurred())
+
+			conn, err := net.Dial("udp", fmt.Sprintf("127.0.0.1:%d", hostPort))
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			for _, msg := range []string{"hello", "again"} {
+				_, err = conn.Write([]byte(msg))
+				Expect(err).NotTo(HaveOccurred())
+
+				buf := make([]byte, 64)
+				conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+				n, err := conn.Read(buf)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(buf[:n])).To(Equal(msg))
+			}
+
+			By("using one socket per client")
+			Expect(fakeSockets.DialUDPCallCount()).To(Equal(1))
+			Expect(fakeSockets.DialUDPArgsForCall(0)).To(Equal(uint32(8080)))
+		})
+
+		It("records the port mapping", func() {
+			hostPort := freePort()
+
+			_, _, err := networker.NetIn(logger, "some-handle", hostPort, 8080)
+			Expect(err).NotTo(HaveOccurred())
+
+			mappings, err := kawasaki.PortMappings(configStore, "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mappings).To(ConsistOf(kawasaki.PortMapping{HostIP: "1.2.3.4", HostPort: hostPort, ContainerPort: 8080}))
+		})
+
+		Context("when the host port is 0", func() {
+			It("acquires a port from the pool", func() {
+				externalPort, _, err := networker.NetIn(logger, "some-handle", 0, 8080)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakePortPool.AcquireCallCount()).To(Equal(1))
+				Expect(fakePortPool.AcquireArgsForCall(0)).To(Equal("some-handle"))
+				Expect(roundTripTCP(externalPort, "hello")).To(Equal("hello"))
+			})
+
+			Context("and the pool is exhausted", func() {
+				It("returns the error", func() {
+					fakePortPool.AcquireStub = nil
+					fakePortPool.AcquireReturns(0, errors.New("banana"))
+
+					_, _, err := networker.NetIn(logger, "some-handle", 0, 8080)
+					Expect(err).To(MatchError("banana"))
+				})
+			})
+
+			Context("and the port cannot be forwarded", func() {
+				It("releases the port", func() {
+					listener, err := net.Listen("tcp", ":0")
+					Expect(err).NotTo(HaveOccurred())
+					defer listener.Close()
+
+					hostPort := uint32(listener.Addr().(*net.TCPAddr).Port)
+					fakePortPool.AcquireStub = nil
+					fakePortPool.AcquireReturns(hostPort, nil)
+
+					_, _, err = networker.NetIn(logger, "some-handle", 0, 8080)
+					Expect(err).To(HaveOccurred())
+
+					Expect(fakePortPool.ReleaseCallCount()).To(Equal(1))
+					Expect(fakePortPool.ReleaseArgsForCall(0)).To(Equal(hostPort))
+				})
+			})
+
+			Context("and the port mapping cannot be recorded", func() {
+				var hostPort uint32
+
+				BeforeEach(func() {
+					hostPort = freePort()
+					fakePortPool.AcquireStub = nil
+					fakePortPool.AcquireReturns(hostPort, nil)
+
+					configStore.Set("some-handle", gardener.MappedPortsKey, "banana")
+				})
+
+				It("returns an error", func() {
+					_, _, err := networker.NetIn(logger, "some-handle", 0, 8080)
+					Expect(err).To(HaveOccurred())
+				})
+
+				It("releases the port", func() {
+					networker.NetIn(logger, "some-handle", 0, 8080)
+
+					Expect(fakePortPool.ReleaseCallCount()).To(Equal(1))
+					Expect(fakePortPool.ReleaseArgsForCall(0)).To(Equal(hostPort))
+				})
+
+				It("stops forwarding the port", func() {
+					networker.NetIn(logger, "some-handle", 0, 8080)
+
+					listener, err := net.Listen("tcp", fmt.Sprintf(":%d", hostPort))
+					Expect(err).NotTo(HaveOccurred())
+					listener.Close()
+
+					packetConn, err := net.ListenPacket("udp", fmt.Sprintf(":%d", hostPort))
+					Expect(err).NotTo(HaveOccurred())
+					packetConn.Close()
+				})
+			})
+		})
+
+		Context("when the container port is 0", func() {
+			It("uses the host port", func() {
+				hostPort := freePort()
+
+				_, containerPort, err := networker.NetIn(logger, "some-handle", hostPort, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(containerPort).To(Equal(hostPort))
+			})
+		})
+
+		Context("when the host port is in use", func() {
+			It("returns an error", func() {
+				listener, err := net.Listen("tcp", ":0")
+				Expect(err).NotTo(HaveOccurred())
+				defer listener.Close()
+
+				_, port, err := net.SplitHostPort(listener.Addr().String())
+				Expect(err).NotTo(HaveOccurred())
+				hostPort, err := strconv.Atoi(port)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, err = networker.NetIn(logger, "some-handle", uint32(hostPort), 8080)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("does not release the port, which the pool did not allocate", func() {
+				listener, err := net.Listen("tcp", ":0")
+				Expect(err).NotTo(HaveOccurred())
+				defer listener.Close()
+
+				_, _, err = networker.NetIn(logger, "some-handle", uint32(listener.Addr().(*net.TCPAddr).Port), 8080)
+				Expect(err).To(HaveOccurred())
+				Expect(fakePortPool.ReleaseCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the container has no network", func() {
+			It("returns an error", func() {
+				_, _, err := networker.NetIn(logger, "another-handle", 0, 8080)
+				Expect(err).To(MatchError("no network for container another-handle"))
+			})
+		})
+	})
+
+	Describe("NetOut", func() {
+		var destination string
+
+		BeforeEach(func() {
+			destination = "192.0.2.10:8080"
+		})
+
+		// the networker is made afresh so that contexts may change the policy
+		JustBeforeEach(func() {
+			networker = newNetworker()
+			Expect(networker.Network(logger, spec, 42)).To(Succeed())
+		})
+
+		It("denies connections through the proxy by default", func() {
+			_, reply := socksConnect(proxyListener.Addr().String(), destination)
+			Expect(reply).To(Equal(byte(0x02)))
+			Expect(dialed).NotTo(Receive())
+		})
+
+		It("allows connections through the proxy which match a rule", func() {
+			Expect(networker.NetOut(logger, "some-handle", garden.NetOutRule{
+				Protocol: garden.ProtocolTCP,
+				Networks: []garden.IPRange{garden.IPRangeFromIP(net.ParseIP("192.0.2.10"))},
+				Ports:    []garden.PortRange{garden.PortRangeFromPort(8080)},
+			})).To(Succeed())
+
+			conn, reply := socksConnect(proxyListener.Addr().String(), destination)
+			Expect(reply).To(Equal(byte(0x00)))
+			defer conn.Close()
+
+			_, err := conn.Write([]byte("hello\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bufio.NewReader(conn).ReadString('\n')).To(Equal("hello\n"))
+			Expect(dialed).To(Receive(Equal("192.0.2.10:8080")))
+		})
+
+		It("denies connections which only match a rule's network", func() {
+			Expect(networker.NetOut(logger, "some-handle", garden.NetOutRule{
+				Protocol: garden.ProtocolAll,
+				Networks: []garden.IPRange{garden.IPRangeFromIP(net.ParseIP("192.0.2.10"))},
+				Ports:    []garden.PortRange{garden.PortRangeFromPort(1)},
+			})).To(Succeed())
+
+			_, reply := socksConnect(proxyListener.Addr().String(), destination)
+			Expect(reply).To(Equal(byte(0x02)))
+		})
+
+		It("denies TCP connections which only match a UDP rule", func() {
+			Expect(networker.NetOut(logger, "some-handle", garden.NetOutRule{
+				Protocol: garden.ProtocolUDP,
+			})).To(Succeed())
+
+			_, reply := socksConnect(proxyListener.Addr().String(), destination)
+			Expect(reply).To(Equal(byte(0x02)))
+		})
+
+		It("resolves host names on the host, and connects to an allowed address", func() {
+			resolved["example.com"] = []net.IP{net.ParseIP("198.51.100.1"), net.ParseIP("192.0.2.10")}
+
+			Expect(networker.NetOut(logger, "some-handle", garden.NetOutRule{
+				Networks: []garden.IPRange{{Start: net.ParseIP("192.0.2.0"), End: net.ParseIP("192.0.2.255")}},
+			})).To(Succeed())
+
+			conn, reply := socksConnect(proxyListener.Addr().String(), "example.com:8080")
+			Expect(reply).To(Equal(byte(0x00)))
+			conn.Close()
+			Expect(dialed).To(Receive(Equal("192.0.2.10:8080")))
+		})
+
+		Context("when a rule allows all connections", func() {
+			JustBeforeEach(func() {
+				Expect(networker.NetOut(logger, "some-handle", garden.NetOutRule{})).To(Succeed())
+			})
+
+			It("denies connections to the host's loopback interface", func() {
+				_, reply := socksConnect(proxyListener.Addr().String(), "127.0.0.1:8080")
+				Expect(reply).To(Equal(byte(0x02)))
+				Expect(dialed).NotTo(Receive())
+			})
+
+			It("denies connections to the host's addresses", func() {
+				_, reply := socksConnect(proxyListener.Addr().String(), "203.0.113.1:8080")
+				Expect(reply).To(Equal(byte(0x02)))
+				Expect(dialed).NotTo(Receive())
+			})
+
+			It("denies connections to host names resolving only to the host", func() {
+				resolved["localhost"] = []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("203.0.113.1")}
+
+				_, reply := socksConnect(proxyListener.Addr().String(), "localhost:8080")
+				Expect(reply).To(Equal(byte(0x02)))
+				Expect(dialed).NotTo(Receive())
+			})
+
+			Context("and host access is allowed", func() {
+				BeforeEach(func() {
+					policy.AllowHostAccess = true
+				})
+
+				It("allows connections to the host's addresses", func() {
+					conn, reply := socksConnect(proxyListener.Addr().String(), "203.0.113.1:8080")
+					Expect(reply).To(Equal(byte(0x00)))
+					conn.Close()
+				})
+
+				It("still denies connections to the host's loopback interface", func() {
+					_, reply := socksConnect(proxyListener.Addr().String(), "127.0.0.1:8080")
+					Expect(reply).To(Equal(byte(0x02)))
+				})
+			})
+
+			Context("and the global policy denies networks", func() {
+				BeforeEach(func() {
+					policy.DenyNetworks = []string{"192.0.2.0/24"}
+					policy.AllowNetworks = []string{"192.0.2.128/25"}
+				})
+
+				It("denies connections to the denied networks", func() {
+					_, reply := socksConnect(proxyListener.Addr().String(), destination)
+					Expect(reply).To(Equal(byte(0x02)))
+					Expect(dialed).NotTo(Receive())
+				})
+
+				It("allows connections to the allowed networks within them", func() {
+					conn, reply := socksConnect(proxyListener.Addr().String(), "192.0.2.200:8080")
+					Expect(reply).To(Equal(byte(0x00)))
+					conn.Close()
+				})
+
+				It("allows connections to other networks", func() {
+					conn, reply := socksConnect(proxyListener.Addr().String(), "198.51.100.1:8080")
+					Expect(reply).To(Equal(byte(0x00)))
+					conn.Close()
+				})
+			})
+
+			Context("when the host's addresses cannot be listed", func() {
+				BeforeEach(func() {
+					interfaceAddrs = func() ([]net.Addr, error) {
+						return nil, errors.New("no-addresses")
+					}
+				})
+
+				It("fails the connection", func() {
+					_, reply := socksConnect(proxyListener.Addr().String(), destination)
+					Expect(reply).To(Equal(byte(0x01)))
+				})
+			})
+		})
+
+		It("records the rules", func() {
+			rule := garden.NetOutRule{Protocol: garden.ProtocolTCP}
+			Expect(networker.NetOut(logger, "some-handle", rule)).To(Succeed())
+			Expect(networker.BulkNetOut(logger, "some-handle", []garden.NetOutRule{rule, rule})).To(Succeed())
+
+			value, ok := configStore.Get("some-handle", "userspace.netout-rules")
+			Expect(ok).To(BeTrue())
+
+			var rules []garden.NetOutRule
+			Expect(json.Unmarshal([]byte(value), &rules)).To(Succeed())
+			Expect(rules).To(HaveLen(3))
+		})
+
+		Context("when the container has no network", func() {
+			It("returns an error", func() {
+				Expect(networker.NetOut(logger, "another-handle", garden.NetOutRule{})).To(MatchError("no network for container another-handle"))
+			})
+		})
+	})
+
+	Describe("Destroy", func() {
+		var hostPort uint32
+
+		BeforeEach(func() {
+			hostPort = freePort()
+			spec.NetIn = []garden.NetIn{{HostPort: hostPort, ContainerPort: 8080}}
+			Expect(networker.Network(logger, spec, 42)).To(Succeed())
+		})
+
+		It("stops forwarding the container's ports", func() {
+			Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
+
+			_, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", hostPort))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("stops the outbound proxy", func() {
+			Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
+
+			_, err := net.Dial("tcp", proxyListener.Addr().String())
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("closes the network namespace", func() {
+			Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
+
+			Expect(fakeSockets.CloseCallCount()).To(Equal(1))
+		})
+
+		It("releases the container's ports", func() {
+			Expect(networker.Destroy(logger, "some-handle")).To(Succeed())
+
+			Expect(fakePortPool.ReleaseAllCallCount()).To(Equal(1))
+			Expect(fakePortPool.ReleaseAllArgsForCall(0)).To(Equal("some-handle"))
+		})
+
+		Context("when the container has no network", func() {
+			It("succeeds", func() {
+				Expect(networker.Destroy(logger, "another-handle")).To(Succeed())
+			})
+		})
+	})
+
+	Describe("Reload", func() {
+		BeforeEach(func() {
+			Expect(networker.Network(logger, spec, 42)).To(Succeed())
+			Expect(networker.NetOut(logger, "some-handle", garden.NetOutRule{})).To(Succeed())
+		})
+
+		It("applies the new policy to the running proxies", func() {
+			Expect(networker.Reload(logger, kawasaki.GlobalPolicy{DenyNetworks: []string{"192.0.2.0/24"}})).To(Succeed())
+
+			_, reply := socksConnect(proxyListener.Addr().String(), "192.0.2.10:8080")
+			Expect(reply).To(Equal(byte(0x02)))
+			Expect(dialed).NotTo(Receive())
+
+			conn, reply := socksConnect(proxyListener.Addr().String(), "198.51.100.1:8080")
+			Expect(reply).To(Equal(byte(0x00)))
+			conn.Close()
+		})
+
+		Context("when the policy is invalid", func() {
+			It("returns an error, and keeps the old policy", func() {
+				Expect(networker.Reload(logger, kawasaki.GlobalPolicy{DenyNetworks: []string{"banana"}})).To(MatchError("invalid network: banana"))
+
+				conn, reply := socksConnect(proxyListener.Addr().String(), "192.0.2.10:8080")
+				Expect(reply).To(Equal(byte(0x00)))
+				conn.Close()
+			})
+		})
+	})
+
+	Describe("Restore", func() {
+		var (
+			restored *userspace.Networker
+			hostPort uint32
+		)
+
+		BeforeEach(func() {
+			hostPort = freePort()
+			spec.NetIn = []garden.NetIn{{HostPort: hostPort, ContainerPort: 8080}}
+			spec.NetOut = []garden.NetOutRule{{Protocol: garden.ProtocolTCP}}
+			Expect(networker.Network(logger, spec, 42)).To(Succeed())
+
+			// as if the server had been restarted, with the container's
+			// properties persisted
+			networker.Destroy(logger, "some-handle")
+			restored = newNetworker()
+		})
+
+		AfterEach(func() {
+			restored.Destroy(logger, "some-handle")
+		})
+
+		It("re-opens the network namespace of the container's process", func() {
+			Expect(restored.Restore(logger, "some-handle")).To(Succeed())
+
+			Expect(fakeSocketOpener.OpenCallCount()).To(Equal(2))
+			_, pid := fakeSocketOpener.OpenArgsForCall(1)
+			Expect(pid).To(Equal(42))
+		})
+
+		It("forwards the container's ports again", func() {
+			Expect(restored.Restore(logger, "some-handle")).To(Succeed())
+
+			Expect(roundTripTCP(hostPort, "hello")).To(Equal("hello"))
+		})
+
+		It("removes the container's ports from the port pool", func() {
+			Expect(restored.Restore(logger, "some-handle")).To(Succeed())
+
+			Expect(fakePortPool.RemoveCallCount()).To(Equal(1))
+			handle, port := fakePortPool.RemoveArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(port).To(Equal(hostPort))
+		})
+
+		It("applies the container's NetOut rules again", func() {
+			Expect(restored.Restore(logger, "some-handle")).To(Succeed())
+
+			conn, reply := socksConnect(proxyListener.Addr().String(), "192.0.2.10:8080")
+			Expect(reply).To(Equal(byte(0x00)))
+			conn.Close()
+		})
+
+		Context("when the container's pid was not recorded", func() {
+			It("returns an error", func() {
+				Expect(restored.Restore(logger, "another-handle")).To(MatchError(ContainSubstring("property not found")))
+			})
+		})
+
+		Context("when the network namespace cannot be opened", func() {
+			It("returns an error", func() {
+				fakeSocketOpener.OpenReturns(nil, errors.New("banana"))
+
+				Expect(restored.Restore(logger, "some-handle")).To(MatchError(ContainSubstring("banana")))
+			})
+		})
+	})
+
+	Describe("Capacity", func() {
+		It("is unlimited", func() {
+			Expect(networker.Capacity()).To(BeNumerically(">", 1<<32))
+		})
+	})
+
+	Describe("ProxyEnv", func() {
+		It("points proxy-aware programs at the proxy port, which resolves host names", func() {
+			Expect(userspace.ProxyEnv(1080)).To(Equal([]string{
+				"ALL_PROXY=socks5h://127.0.0.1:1080",
+				"all_proxy=socks5h://127.0.0.1:1080",
+			}))
+		})
+	})
+})
+
+func serveTCPEcho(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer conn.Close()
+			io.Copy(conn, conn)
+		}()
+	}
+}
+
+func serveUDPEcho(conn net.PacketConn) {
+	buf := make([]byte, 1024)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		conn.WriteTo(buf[:n], addr)
+	}
+}
+
+func freePort() uint32 {
+	listener, err := net.Listen("tcp", ":0")
+	Expect(err).NotTo(HaveOccurred())
+	defer listener.Close()
+
+	return uint32(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// roundTripTCP sends msg to the host port and returns what is sent back
+func roundTripTCP(hostPort uint32, msg string) (string, error) {
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", hostPort))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(msg)); err != nil {
+		return "", err
+	}
+	conn.(*net.TCPConn).CloseWrite()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	b, err := ioutil.ReadAll(conn)
+	return string(b), err
+}
+
+// socksConnect asks the SOCKS5 proxy at proxyAddr to connect to destination,
+// returning the connection and the proxy's reply
+func socksConnect(proxyAddr, destination string) (net.Conn, byte) {
+	conn, err := net.Dial("tcp", proxyAddr)
+	Expect(err).NotTo(HaveOccurred())
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	_, err = conn.Write([]byte{5, 1, 0})
+	Expect(err).NotTo(HaveOccurred())
+
+	method := make([]byte, 2)
+	_, err = io.ReadFull(conn, method)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(method).To(Equal([]byte{5, 0}))
+
+	host, port, err := net.SplitHostPort(destination)
+	Expect(err).NotTo(HaveOccurred())
+	portNum, err := strconv.Atoi(port)
+	Expect(err).NotTo(HaveOccurred())
+
+	request := []byte{5, 1, 0}
+	if ip := net.ParseIP(host).To4(); ip != nil {
+		request = append(append(request, 1), ip...)
+	} else {
+		request = append(append(request, 3, byte(len(host))), host...)
+	}
+	request = append(request, 0, 0)
+	binary.BigEndian.PutUint16(request[len(request)-2:], uint16(portNum))
+
+	_, err = conn.Write(request)
+	Expect(err).NotTo(HaveOccurred())
+
+	reply := make([]byte, 10)
+	_, err = io.ReadFull(conn, reply)
+	Expect(err).NotTo(HaveOccurred())
+
+	conn.SetDeadline(time.Time{})
+	return conn, reply[1]
+}
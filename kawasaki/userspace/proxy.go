@@ -0,0 +1,166 @@
+package userspace
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// UDPSessionTimeout is how long a UDP client may be idle before the socket
+// forwarding its datagrams into the container is closed
+const UDPSessionTimeout = time.Minute
+
+// maxDatagramLen is the largest UDP payload forwarded
+const maxDatagramLen = 65535
+
+// tcpProxy forwards each connection accepted by a listener to a port on a
+// container's loopback interface
+type tcpProxy struct {
+	log      lager.Logger
+	listener net.Listener
+	dial     func() (net.Conn, error)
+}
+
+func (p *tcpProxy) serve() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go p.forward(conn)
+	}
+}
+
+func (p *tcpProxy) forward(conn net.Conn) {
+	defer conn.Close()
+
+	upstream, err := p.dial()
+	if err != nil {
+		p.log.Error("dial-failed", err)
+		return
+	}
+	defer upstream.Close()
+
+	splice(conn, upstream)
+}
+
+func (p *tcpProxy) Close() error {
+	return p.listener.Close()
+}
+
+// splice copies between two connections in both directions until both have
+// been closed for writing, half closing each as the other reaches EOF
+func splice(a, b net.Conn) {
+	done := make(chan struct{})
+	go func() {
+		copyAndCloseWrite(a, b)
+		close(done)
+	}()
+
+	copyAndCloseWrite(b, a)
+	<-done
+}
+
+func copyAndCloseWrite(dst, src net.Conn) {
+	io.Copy(dst, src)
+
+	if c, ok := dst.(interface {
+		CloseWrite() error
+	}); ok {
+		c.CloseWrite()
+	} else {
+		dst.Close()
+	}
+}
+
+// udpProxy forwards datagrams received on a host port to a port on a
+// container's loopback interface, through a socket per client so that
+// replies are returned to the client they are for
+type udpProxy struct {
+	log  lager.Logger
+	conn net.PacketConn
+	dial func() (net.Conn, error)
+
+	mu       sync.Mutex
+	sessions map[string]net.Conn
+}
+
+func (p *udpProxy) serve() {
+	buf := make([]byte, maxDatagramLen)
+	for {
+		n, addr, err := p.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		upstream, err := p.session(addr)
+		if err != nil {
+			p.log.Error("dial-failed", err)
+			continue
+		}
+
+		// the session is kept for as long as either side is sending
+		upstream.SetReadDeadline(time.Now().Add(UDPSessionTimeout))
+		upstream.Write(buf[:n])
+	}
+}
+
+func (p *udpProxy) session(addr net.Addr) (net.Conn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if upstream, ok := p.sessions[addr.String()]; ok {
+		return upstream, nil
+	}
+
+	upstream, err := p.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	p.sessions[addr.String()] = upstream
+	go p.reply(addr, upstream)
+
+	return upstream, nil
+}
+
+// reply returns the container's replies to a client until the client has
+// been idle for the session timeout
+func (p *udpProxy) reply(addr net.Addr, upstream net.Conn) {
+	defer func() {
+		p.mu.Lock()
+		delete(p.sessions, addr.String())
+		p.mu.Unlock()
+
+		upstream.Close()
+	}()
+
+	buf := make([]byte, maxDatagramLen)
+	for {
+		n, err := upstream.Read(buf)
+		if err != nil {
+			return
+		}
+
+		upstream.SetReadDeadline(time.Now().Add(UDPSessionTimeout))
+		if _, err := p.conn.WriteTo(buf[:n], addr); err != nil {
+			return
+		}
+	}
+}
+
+func (p *udpProxy) Close() error {
+	err := p.conn.Close()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, upstream := range p.sessions {
+		upstream.Close()
+	}
+
+	return err
+}
@@ -0,0 +1,38 @@
+package userspace
+
+import (
+	"net"
+
+	"code.cloudfoundry.org/lager"
+)
+
+// requests made of a socket helper, each of which is answered with a new
+// socket of the kind requested
+const (
+	requestTCPSocket byte = 't'
+	requestUDPSocket byte = 'u'
+)
+
+// statuses of a socket helper's replies; an error is followed by its message
+const (
+	replyOK byte = iota
+	replyError
+)
+
+//go:generate counterfeiter . SocketOpener
+
+// SocketOpener gives access to the network namespace of a container's process
+type SocketOpener interface {
+	Open(log lager.Logger, pid int) (Sockets, error)
+}
+
+//go:generate counterfeiter . Sockets
+
+// Sockets creates sockets in a container's network namespace, bound to or
+// connected to its loopback interface
+type Sockets interface {
+	DialTCP(port uint32) (net.Conn, error)
+	DialUDP(port uint32) (net.Conn, error)
+	ListenTCP(port uint32) (net.Listener, error)
+	Close() error
+}
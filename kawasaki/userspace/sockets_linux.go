@@ -0,0 +1,311 @@
+package userspace
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"syscall"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/cloudfoundry/gunk/command_runner"
+	"github.com/vishvananda/netlink"
+)
+
+// maxReplyLen is the longest reply, i.e. error message, a helper sends
+const maxReplyLen = 1024
+
+var loopback = [4]byte{127, 0, 0, 1}
+
+// SocketClient requests sockets from a helper, running ServeSockets in a
+// container's network namespace, at the other end of a unix seqpacket
+// connection
+type SocketClient struct {
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+// NewSockets waits for the helper at the other end of conn to be ready to
+// serve sockets
+func NewSockets(conn *net.UnixConn) (*SocketClient, error) {
+	if _, err := readReply(conn); err != nil {
+		return nil, err
+	}
+
+	return &SocketClient{conn: conn}, nil
+}
+
+func (c *SocketClient) DialTCP(port uint32) (net.Conn, error) {
+	fd, err := c.socket(requestTCPSocket)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect(fd, port)
+}
+
+func (c *SocketClient) DialUDP(port uint32) (net.Conn, error) {
+	fd, err := c.socket(requestUDPSocket)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect(fd, port)
+}
+
+func (c *SocketClient) ListenTCP(port uint32) (net.Listener, error) {
+	fd, err := c.socket(requestTCPSocket)
+	if err != nil {
+		return nil, err
+	}
+
+	file := os.NewFile(uintptr(fd), "listener")
+	defer file.Close()
+
+	if err := syscall.SetsockoptInt(fd, syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1); err != nil {
+		return nil, fmt.Errorf("set SO_REUSEADDR: %s", err)
+	}
+
+	if err := syscall.Bind(fd, &syscall.SockaddrInet4{Port: int(port), Addr: loopback}); err != nil {
+		return nil, fmt.Errorf("bind 127.0.0.1:%d: %s", port, err)
+	}
+
+	if err := syscall.Listen(fd, syscall.SOMAXCONN); err != nil {
+		return nil, fmt.Errorf("listen 127.0.0.1:%d: %s", port, err)
+	}
+
+	return net.FileListener(file)
+}
+
+// Close closes the connection to the helper, which then exits
+func (c *SocketClient) Close() error {
+	return c.conn.Close()
+}
+
+// socket requests a new socket from the helper. Requests are serialized, so
+// that each reply is read by its own request.
+func (c *SocketClient) socket(kind byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.conn.Write([]byte{kind}); err != nil {
+		return -1, fmt.Errorf("requesting socket: %s", err)
+	}
+
+	return readReply(c.conn)
+}
+
+// readReply reads a reply from a helper, returning the socket it carries, if
+// any, or -1
+func readReply(conn *net.UnixConn) (int, error) {
+	buf := make([]byte, maxReplyLen)
+	oob := make([]byte, syscall.CmsgSpace(4))
+
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err == io.EOF || (err == nil && n == 0) {
+		return -1, errors.New("socket helper exited")
+	}
+	if err != nil {
+		return -1, fmt.Errorf("reading from socket helper: %s", err)
+	}
+
+	if buf[0] != replyOK {
+		return -1, errors.New(string(buf[1:n]))
+	}
+
+	if oobn == 0 {
+		return -1, nil
+	}
+
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		return -1, fmt.Errorf("parsing socket control message: %v", err)
+	}
+
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		return -1, fmt.Errorf("parsing unix rights: %v", err)
+	}
+
+	syscall.CloseOnExec(fds[0])
+	return fds[0], nil
+}
+
+func connect(fd int, port uint32) (net.Conn, error) {
+	file := os.NewFile(uintptr(fd), "conn")
+	defer file.Close()
+
+	if err := syscall.Connect(fd, &syscall.SockaddrInet4{Port: int(port), Addr: loopback}); err != nil {
+		return nil, fmt.Errorf("connect 127.0.0.1:%d: %s", port, err)
+	}
+
+	return net.FileConn(file)
+}
+
+// ServeSockets brings up the loopback interface of the current network
+// namespace and then, until conn is closed, answers each request read from it
+// with a new socket in the namespace
+func ServeSockets(conn *net.UnixConn) error {
+	if err := setLoopbackUp(); err != nil {
+		writeReply(conn, -1, err)
+		return err
+	}
+
+	if err := writeReply(conn, -1, nil); err != nil {
+		return err
+	}
+
+	request := make([]byte, 1)
+	for {
+		if _, err := conn.Read(request); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		fd, err := newSocket(request[0])
+		if err := writeReply(conn, fd, err); err != nil {
+			return err
+		}
+
+		if fd >= 0 {
+			syscall.Close(fd)
+		}
+	}
+}
+
+func setLoopbackUp() error {
+	link, err := netlink.LinkByName("lo")
+	if err != nil {
+		return fmt.Errorf("find loopback interface: %s", err)
+	}
+
+	if link.Attrs().Flags&net.FlagUp != 0 {
+		return nil
+	}
+
+	if err := netlink.LinkSetUp(link); err != nil {
+		return fmt.Errorf("set loopback interface up: %s", err)
+	}
+
+	return nil
+}
+
+func newSocket(kind byte) (int, error) {
+	switch kind {
+	case requestTCPSocket:
+		return syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM|syscall.SOCK_CLOEXEC, 0)
+	case requestUDPSocket:
+		return syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	default:
+		return -1, fmt.Errorf("unknown socket request: %q", kind)
+	}
+}
+
+func writeReply(conn *net.UnixConn, fd int, err error) error {
+	if err != nil {
+		msg := append([]byte{replyError}, err.Error()...)
+		if len(msg) > maxReplyLen {
+			msg = msg[:maxReplyLen]
+		}
+
+		_, err = conn.Write(msg)
+		return err
+	}
+
+	var oob []byte
+	if fd >= 0 {
+		oob = syscall.UnixRights(fd)
+	}
+
+	_, _, err = conn.WriteMsgUnix([]byte{replyOK}, oob, nil)
+	return err
+}
+
+type nsenterSocketOpener struct {
+	commandRunner command_runner.CommandRunner
+	nsenterPath   string
+	helperPath    string
+	helperArgs    []string
+}
+
+// NewSocketOpener returns a SocketOpener which runs the helper with nsenter
+// in the user and network namespaces of a container's process. The helper is
+// given its end of the connection as fd 3, and must call ServeSockets on it.
+// gdn cannot join the namespaces itself, as a multithreaded process cannot
+// join a user namespace.
+func NewSocketOpener(commandRunner command_runner.CommandRunner, nsenterPath, helperPath string, helperArgs ...string) SocketOpener {
+	return &nsenterSocketOpener{
+		commandRunner: commandRunner,
+		nsenterPath:   nsenterPath,
+		helperPath:    helperPath,
+		helperArgs:    helperArgs,
+	}
+}
+
+func (o *nsenterSocketOpener) Open(log lager.Logger, pid int) (Sockets, error) {
+	log = log.Session("open-sockets", lager.Data{"pid": pid})
+
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("socketpair: %s", err)
+	}
+
+	parent := os.NewFile(uintptr(fds[0]), "sockets")
+	child := os.NewFile(uintptr(fds[1]), "sockets-helper")
+
+	conn, err := net.FileConn(parent)
+	parent.Close()
+	if err != nil {
+		child.Close()
+		return nil, err
+	}
+
+	args := append([]string{
+		"--target", strconv.Itoa(pid),
+		"--user", "--net", "--preserve-credentials",
+		"--", o.helperPath,
+	}, o.helperArgs...)
+
+	stderr := &bytes.Buffer{}
+	cmd := exec.Command(o.nsenterPath, args...)
+	cmd.ExtraFiles = []*os.File{child}
+	cmd.Stderr = stderr
+
+	err = o.commandRunner.Start(cmd)
+	// only the helper holds its end, so that reads see it exit
+	child.Close()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("starting socket helper: %s", err)
+	}
+
+	client, err := NewSockets(conn.(*net.UnixConn))
+	if err != nil {
+		conn.Close()
+		o.commandRunner.Wait(cmd)
+		log.Error("socket-helper-failed", err, lager.Data{"stderr": stderr.String()})
+		return nil, fmt.Errorf("socket helper: %s", err)
+	}
+
+	return &helperSockets{SocketClient: client, cmd: cmd, commandRunner: o.commandRunner}, nil
+}
+
+// helperSockets is a SocketClient whose helper is reaped on Close
+type helperSockets struct {
+	*SocketClient
+
+	cmd           *exec.Cmd
+	commandRunner command_runner.CommandRunner
+}
+
+func (s *helperSockets) Close() error {
+	err := s.SocketClient.Close()
+	s.commandRunner.Wait(s.cmd) // avoid zombie
+	return err
+}
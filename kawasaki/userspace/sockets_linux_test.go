@@ -0,0 +1,193 @@
+package userspace_test
+
+import (
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"code.cloudfoundry.org/guardian/kawasaki/userspace"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/cloudfoundry/gunk/command_runner/fake_command_runner"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sockets", func() {
+	var (
+		clientConn *net.UnixConn
+		served     chan error
+		sockets    *userspace.SocketClient
+	)
+
+	BeforeEach(func() {
+		var helperConn *net.UnixConn
+		clientConn, helperConn = unixPacketPair()
+
+		served = make(chan error, 1)
+		go func(served chan<- error) {
+			defer GinkgoRecover()
+			defer helperConn.Close()
+			served <- userspace.ServeSockets(helperConn)
+		}(served)
+
+		var err error
+		sockets, err = userspace.NewSockets(clientConn)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		sockets.Close()
+	})
+
+	Describe("DialTCP", func() {
+		It("connects to the port on the loopback interface", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer listener.Close()
+
+			conn, err := sockets.DialTCP(uint32(listener.Addr().(*net.TCPAddr).Port))
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			accepted, err := listener.Accept()
+			Expect(err).NotTo(HaveOccurred())
+			defer accepted.Close()
+
+			Expect(accepted.RemoteAddr().String()).To(Equal(conn.LocalAddr().String()))
+		})
+
+		Context("when nothing is listening on the port", func() {
+			It("returns an error", func() {
+				port := freePort()
+
+				_, err := sockets.DialTCP(port)
+				Expect(err).To(MatchError(ContainSubstring("connect 127.0.0.1:" + strconv.Itoa(int(port)))))
+			})
+		})
+	})
+
+	Describe("DialUDP", func() {
+		It("sends datagrams to the port on the loopback interface", func() {
+			server, err := net.ListenPacket("udp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			defer server.Close()
+
+			conn, err := sockets.DialUDP(uint32(server.LocalAddr().(*net.UDPAddr).Port))
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			_, err = conn.Write([]byte("hello"))
+			Expect(err).NotTo(HaveOccurred())
+
+			buf := make([]byte, 64)
+			n, _, err := server.ReadFrom(buf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(buf[:n])).To(Equal("hello"))
+		})
+	})
+
+	Describe("ListenTCP", func() {
+		It("listens on the port of the loopback interface", func() {
+			port := freePort()
+
+			listener, err := sockets.ListenTCP(port)
+			Expect(err).NotTo(HaveOccurred())
+			defer listener.Close()
+
+			Expect(listener.Addr().String()).To(Equal("127.0.0.1:" + strconv.Itoa(int(port))))
+
+			conn, err := net.Dial("tcp", listener.Addr().String())
+			Expect(err).NotTo(HaveOccurred())
+			defer conn.Close()
+
+			accepted, err := listener.Accept()
+			Expect(err).NotTo(HaveOccurred())
+			accepted.Close()
+		})
+	})
+
+	Describe("Close", func() {
+		It("stops the helper", func() {
+			Expect(sockets.Close()).To(Succeed())
+
+			Eventually(served).Should(Receive(BeNil()))
+		})
+	})
+})
+
+var _ = Describe("SocketOpener", func() {
+	var (
+		fakeCommandRunner *fake_command_runner.FakeCommandRunner
+		opener            userspace.SocketOpener
+	)
+
+	BeforeEach(func() {
+		fakeCommandRunner = fake_command_runner.New()
+		opener = userspace.NewSocketOpener(fakeCommandRunner, "/path/to/nsenter", "/path/to/gdn", "netns-sockets")
+	})
+
+	It("runs the helper in the user and network namespaces of the process", func() {
+		opener.Open(lagertest.NewTestLogger("test"), 42)
+
+		Expect(fakeCommandRunner.StartedCommands()).To(HaveLen(1))
+		cmd := fakeCommandRunner.StartedCommands()[0]
+		Expect(cmd.Args).To(Equal([]string{
+			"/path/to/nsenter",
+			"--target", "42",
+			"--user", "--net", "--preserve-credentials",
+			"--", "/path/to/gdn", "netns-sockets",
+		}))
+		Expect(cmd.ExtraFiles).To(HaveLen(1))
+	})
+
+	Context("when the helper exits without serving", func() {
+		It("returns an error", func() {
+			_, err := opener.Open(lagertest.NewTestLogger("test"), 42)
+			Expect(err).To(MatchError("socket helper: socket helper exited"))
+		})
+
+		It("waits for the helper, to avoid a zombie", func() {
+			opener.Open(lagertest.NewTestLogger("test"), 42)
+
+			Expect(fakeCommandRunner.WaitedCommands()).To(ConsistOf(fakeCommandRunner.StartedCommands()))
+		})
+	})
+
+	Context("when the helper cannot be started", func() {
+		It("returns an error", func() {
+			opener = userspace.NewSocketOpener(failingStarter{fakeCommandRunner}, "/path/to/nsenter", "/path/to/gdn")
+
+			_, err := opener.Open(lagertest.NewTestLogger("test"), 42)
+			Expect(err).To(MatchError("starting socket helper: banana"))
+		})
+	})
+})
+
+type failingStarter struct {
+	*fake_command_runner.FakeCommandRunner
+}
+
+func (failingStarter) Start(*exec.Cmd) error {
+	return errors.New("banana")
+}
+
+func unixPacketPair() (*net.UnixConn, *net.UnixConn) {
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_SEQPACKET|syscall.SOCK_CLOEXEC, 0)
+	Expect(err).NotTo(HaveOccurred())
+
+	var conns []*net.UnixConn
+	for _, fd := range fds {
+		file := os.NewFile(uintptr(fd), "sockets")
+		conn, err := net.FileConn(file)
+		Expect(err).NotTo(HaveOccurred())
+		file.Close()
+
+		conns = append(conns, conn.(*net.UnixConn))
+	}
+
+	return conns[0], conns[1]
+}
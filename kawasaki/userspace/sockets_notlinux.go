@@ -0,0 +1,17 @@
+// +build !linux
+
+package userspace
+
+import (
+	"net"
+
+	"github.com/cloudfoundry/gunk/command_runner"
+)
+
+func ServeSockets(conn *net.UnixConn) error {
+	panic("not supported on this platform")
+}
+
+func NewSocketOpener(commandRunner command_runner.CommandRunner, nsenterPath, helperPath string, helperArgs ...string) SocketOpener {
+	panic("not supported on this platform")
+}
@@ -0,0 +1,376 @@
+package userspace
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/lager"
+)
+
+// HandshakeTimeout is how long a client of a container's proxy has to say
+// where it wants to connect to
+const HandshakeTimeout = 30 * time.Second
+
+// SOCKS5 protocol values, see RFC 1928
+const (
+	socksVersion = 5
+
+	socksMethodNoAuth       = 0x00
+	socksMethodNoAcceptable = 0xff
+
+	socksCommandConnect = 0x01
+
+	socksAddrIPv4   = 0x01
+	socksAddrDomain = 0x03
+	socksAddrIPv6   = 0x04
+
+	socksReplySucceeded           = 0x00
+	socksReplyGeneralFailure      = 0x01
+	socksReplyNotAllowed          = 0x02
+	socksReplyHostUnreachable     = 0x04
+	socksReplyCommandNotSupported = 0x07
+	socksReplyAddressNotSupported = 0x08
+)
+
+// Dialer makes a container's outbound connections from the host
+type Dialer func(network, address string) (net.Conn, error)
+
+// Resolver looks up the addresses of a host name on the host
+type Resolver func(host string) ([]net.IP, error)
+
+// InterfaceAddrs lists the addresses of the host's interfaces
+type InterfaceAddrs func() ([]net.Addr, error)
+
+// socksProxy is a SOCKS5 proxy, listening on a container's loopback
+// interface, through which the container makes the outbound TCP connections
+// the global policy and its NetOut rules allow. Host names are resolved on
+// the host.
+type socksProxy struct {
+	log      lager.Logger
+	listener net.Listener
+	firewall *hostFirewall
+	rules    *netOutRules
+	dial     Dialer
+	resolve  Resolver
+}
+
+func (p *socksProxy) serve() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go p.handle(conn)
+	}
+}
+
+func (p *socksProxy) Close() error {
+	return p.listener.Close()
+}
+
+func (p *socksProxy) handle(conn net.Conn) {
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(HandshakeTimeout))
+
+	if !p.negotiate(conn) {
+		return
+	}
+
+	host, port, reply := readConnectRequest(conn)
+	if reply != socksReplySucceeded {
+		writeSocksReply(conn, reply)
+		return
+	}
+
+	ip, reply := p.destination(host, port)
+	if reply != socksReplySucceeded {
+		p.log.Info("connection-denied", lager.Data{"host": host, "port": port})
+		writeSocksReply(conn, reply)
+		return
+	}
+
+	address := net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))
+	upstream, err := p.dial("tcp", address)
+	if err != nil {
+		p.log.Error("dial-failed", err, lager.Data{"address": address})
+		writeSocksReply(conn, socksReplyHostUnreachable)
+		return
+	}
+	defer upstream.Close()
+
+	if err := writeSocksReply(conn, socksReplySucceeded); err != nil {
+		return
+	}
+
+	conn.SetDeadline(time.Time{})
+	splice(conn, upstream)
+}
+
+// negotiate agrees with the client that it need not authenticate, as only
+// the container can reach its loopback interface
+func (p *socksProxy) negotiate(conn net.Conn) bool {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil || header[0] != socksVersion {
+		return false
+	}
+
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return false
+	}
+
+	if bytes.IndexByte(methods, socksMethodNoAuth) < 0 {
+		conn.Write([]byte{socksVersion, socksMethodNoAcceptable})
+		return false
+	}
+
+	_, err := conn.Write([]byte{socksVersion, socksMethodNoAuth})
+	return err == nil
+}
+
+// destination returns the first of the addresses of host to which
+// connections on port are allowed, first by the global policy and then by
+// the container's NetOut rules
+func (p *socksProxy) destination(host string, port uint16) (net.IP, byte) {
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		var err error
+		if ips, err = p.resolve(host); err != nil {
+			p.log.Error("resolve-failed", err, lager.Data{"host": host})
+			return nil, socksReplyHostUnreachable
+		}
+	}
+
+	for _, ip := range ips {
+		allowed, err := p.firewall.allows(ip)
+		if err != nil {
+			p.log.Error("checking-global-policy-failed", err, lager.Data{"ip": ip.String()})
+			return nil, socksReplyGeneralFailure
+		}
+		if !allowed {
+			continue
+		}
+
+		if rule, ok := p.rules.allows(ip, port); ok {
+			if rule.Log {
+				p.log.Info("connection-allowed", lager.Data{"host": host, "ip": ip.String(), "port": port})
+			}
+
+			return ip, socksReplySucceeded
+		}
+	}
+
+	return nil, socksReplyNotAllowed
+}
+
+// readConnectRequest reads the host and port a client wants to connect to.
+// If the request cannot be served, the reply to send the client is returned.
+func readConnectRequest(conn net.Conn) (string, uint16, byte) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil || header[0] != socksVersion {
+		return "", 0, socksReplyGeneralFailure
+	}
+
+	if header[1] != socksCommandConnect {
+		return "", 0, socksReplyCommandNotSupported
+	}
+
+	var host string
+	switch header[3] {
+	case socksAddrIPv4, socksAddrIPv6:
+		addr := make([]byte, net.IPv4len)
+		if header[3] == socksAddrIPv6 {
+			addr = make([]byte, net.IPv6len)
+		}
+
+		if _, err := io.ReadFull(conn, addr); err != nil {
+			return "", 0, socksReplyGeneralFailure
+		}
+
+		host = net.IP(addr).String()
+	case socksAddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return "", 0, socksReplyGeneralFailure
+		}
+
+		domain := make([]byte, length[0])
+		if _, err := io.ReadFull(conn, domain); err != nil {
+			return "", 0, socksReplyGeneralFailure
+		}
+
+		host = string(domain)
+	default:
+		return "", 0, socksReplyAddressNotSupported
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return "", 0, socksReplyGeneralFailure
+	}
+
+	return host, binary.BigEndian.Uint16(port), socksReplySucceeded
+}
+
+// writeSocksReply replies to a connect request. The bound address is not
+// meaningful to the container, so is always given as 0.0.0.0:0.
+func writeSocksReply(conn net.Conn, reply byte) error {
+	_, err := conn.Write([]byte{socksVersion, reply, 0, socksAddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+// hostFirewall denies containers the destinations which the global policy
+// denies them under kernel networking, as the proxy dials from the host
+// rather than from the container's network namespace: the host's loopback
+// interface, which a container could never reach, the host's own addresses
+// unless host access is allowed, and the deny networks, less the allow
+// networks. The policy can be replaced while proxies are running.
+type hostFirewall struct {
+	mu             sync.RWMutex
+	policy         kawasaki.GlobalPolicy
+	interfaceAddrs InterfaceAddrs
+}
+
+func (f *hostFirewall) setPolicy(policy kawasaki.GlobalPolicy) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.policy = policy
+}
+
+func (f *hostFirewall) allows(ip net.IP) (bool, error) {
+	if ip.IsLoopback() || ip.IsUnspecified() {
+		return false, nil
+	}
+
+	f.mu.RLock()
+	policy := f.policy
+	f.mu.RUnlock()
+
+	if !policy.AllowHostAccess {
+		local, err := f.isLocal(ip)
+		if err != nil || local {
+			return false, err
+		}
+	}
+
+	allowed, err := inNetworks(policy.AllowNetworks, ip)
+	if err != nil || allowed {
+		return allowed, err
+	}
+
+	denied, err := inNetworks(policy.DenyNetworks, ip)
+	return !denied, err
+}
+
+func (f *hostFirewall) isLocal(ip net.IP) (bool, error) {
+	addrs, err := f.interfaceAddrs()
+	if err != nil {
+		return false, err
+	}
+
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func inNetworks(networks []string, ip net.IP) (bool, error) {
+	for _, network := range networks {
+		_, ipNet, err := net.ParseCIDR(network)
+		if err != nil {
+			return false, err
+		}
+
+		if ipNet.Contains(ip) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// netOutRules are the NetOut rules of a container, of which only the TCP
+// rules have an effect, as only TCP connections are proxied
+type netOutRules struct {
+	mu    sync.RWMutex
+	rules []garden.NetOutRule
+}
+
+func (r *netOutRules) add(rules []garden.NetOutRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules = append(r.rules, rules...)
+}
+
+// allows returns the first rule which allows TCP connections to ip and port
+func (r *netOutRules) allows(ip net.IP, port uint16) (garden.NetOutRule, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rule := range r.rules {
+		if rule.Protocol != garden.ProtocolAll && rule.Protocol != garden.ProtocolTCP {
+			continue
+		}
+
+		if matchesNetworks(rule.Networks, ip) && matchesPorts(rule.Ports, port) {
+			return rule, true
+		}
+	}
+
+	return garden.NetOutRule{}, false
+}
+
+func matchesNetworks(networks []garden.IPRange, ip net.IP) bool {
+	if len(networks) == 0 {
+		return true
+	}
+
+	for _, network := range networks {
+		start, end := network.Start, network.End
+		if end == nil {
+			end = start
+		}
+		if start == nil {
+			start = net.IPv4zero
+		}
+
+		if bytes.Compare(ip.To16(), start.To16()) >= 0 && bytes.Compare(ip.To16(), end.To16()) <= 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesPorts(ports []garden.PortRange, port uint16) bool {
+	if len(ports) == 0 {
+		return true
+	}
+
+	for _, portRange := range ports {
+		end := portRange.End
+		if end == 0 {
+			end = portRange.Start
+		}
+
+		if port >= portRange.Start && port <= end {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,13 @@
+package userspace_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestUserspace(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Userspace Suite")
+}
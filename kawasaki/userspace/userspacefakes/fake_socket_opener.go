@@ -0,0 +1,102 @@
+// This file was generated by counterfeiter
+package userspacefakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/kawasaki/userspace"
+	"code.cloudfoundry.org/lager"
+)
+
+type FakeSocketOpener struct {
+	OpenStub        func(log lager.Logger, pid int) (userspace.Sockets, error)
+	openMutex       sync.RWMutex
+	openArgsForCall []struct {
+		log lager.Logger
+		pid int
+	}
+	openReturns struct {
+		result1 userspace.Sockets
+		result2 error
+	}
+	openReturnsOnCall map[int]struct {
+		result1 userspace.Sockets
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeSocketOpener) Open(log lager.Logger, pid int) (userspace.Sockets, error) {
+	fake.openMutex.Lock()
+	ret, specificReturn := fake.openReturnsOnCall[len(fake.openArgsForCall)]
+	fake.openArgsForCall = append(fake.openArgsForCall, struct {
+		log lager.Logger
+		pid int
+	}{log, pid})
+	fake.recordInvocation("Open", []interface{}{log, pid})
+	fake.openMutex.Unlock()
+	if fake.OpenStub != nil {
+		return fake.OpenStub(log, pid)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.openReturns.result1, fake.openReturns.result2
+}
+
+func (fake *FakeSocketOpener) OpenCallCount() int {
+	fake.openMutex.RLock()
+	defer fake.openMutex.RUnlock()
+	return len(fake.openArgsForCall)
+}
+
+func (fake *FakeSocketOpener) OpenArgsForCall(i int) (lager.Logger, int) {
+	fake.openMutex.RLock()
+	defer fake.openMutex.RUnlock()
+	return fake.openArgsForCall[i].log, fake.openArgsForCall[i].pid
+}
+
+func (fake *FakeSocketOpener) OpenReturns(result1 userspace.Sockets, result2 error) {
+	fake.OpenStub = nil
+	fake.openReturns = struct {
+		result1 userspace.Sockets
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSocketOpener) OpenReturnsOnCall(i int, result1 userspace.Sockets, result2 error) {
+	fake.OpenStub = nil
+	if fake.openReturnsOnCall == nil {
+		fake.openReturnsOnCall = make(map[int]struct {
+			result1 userspace.Sockets
+			result2 error
+		})
+	}
+	fake.openReturnsOnCall[i] = struct {
+		result1 userspace.Sockets
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSocketOpener) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.openMutex.RLock()
+	defer fake.openMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeSocketOpener) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ userspace.SocketOpener = new(FakeSocketOpener)
@@ -0,0 +1,283 @@
+// This file was generated by counterfeiter
+package userspacefakes
+
+import (
+	"net"
+	"sync"
+
+	"code.cloudfoundry.org/guardian/kawasaki/userspace"
+)
+
+type FakeSockets struct {
+	DialTCPStub        func(port uint32) (net.Conn, error)
+	dialTCPMutex       sync.RWMutex
+	dialTCPArgsForCall []struct {
+		port uint32
+	}
+	dialTCPReturns struct {
+		result1 net.Conn
+		result2 error
+	}
+	dialTCPReturnsOnCall map[int]struct {
+		result1 net.Conn
+		result2 error
+	}
+	DialUDPStub        func(port uint32) (net.Conn, error)
+	dialUDPMutex       sync.RWMutex
+	dialUDPArgsForCall []struct {
+		port uint32
+	}
+	dialUDPReturns struct {
+		result1 net.Conn
+		result2 error
+	}
+	dialUDPReturnsOnCall map[int]struct {
+		result1 net.Conn
+		result2 error
+	}
+	ListenTCPStub        func(port uint32) (net.Listener, error)
+	listenTCPMutex       sync.RWMutex
+	listenTCPArgsForCall []struct {
+		port uint32
+	}
+	listenTCPReturns struct {
+		result1 net.Listener
+		result2 error
+	}
+	listenTCPReturnsOnCall map[int]struct {
+		result1 net.Listener
+		result2 error
+	}
+	CloseStub        func() error
+	closeMutex       sync.RWMutex
+	closeArgsForCall []struct{}
+	closeReturns     struct {
+		result1 error
+	}
+	closeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeSockets) DialTCP(port uint32) (net.Conn, error) {
+	fake.dialTCPMutex.Lock()
+	ret, specificReturn := fake.dialTCPReturnsOnCall[len(fake.dialTCPArgsForCall)]
+	fake.dialTCPArgsForCall = append(fake.dialTCPArgsForCall, struct {
+		port uint32
+	}{port})
+	fake.recordInvocation("DialTCP", []interface{}{port})
+	fake.dialTCPMutex.Unlock()
+	if fake.DialTCPStub != nil {
+		return fake.DialTCPStub(port)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.dialTCPReturns.result1, fake.dialTCPReturns.result2
+}
+
+func (fake *FakeSockets) DialTCPCallCount() int {
+	fake.dialTCPMutex.RLock()
+	defer fake.dialTCPMutex.RUnlock()
+	return len(fake.dialTCPArgsForCall)
+}
+
+func (fake *FakeSockets) DialTCPArgsForCall(i int) uint32 {
+	fake.dialTCPMutex.RLock()
+	defer fake.dialTCPMutex.RUnlock()
+	return fake.dialTCPArgsForCall[i].port
+}
+
+func (fake *FakeSockets) DialTCPReturns(result1 net.Conn, result2 error) {
+	fake.DialTCPStub = nil
+	fake.dialTCPReturns = struct {
+		result1 net.Conn
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSockets) DialTCPReturnsOnCall(i int, result1 net.Conn, result2 error) {
+	fake.DialTCPStub = nil
+	if fake.dialTCPReturnsOnCall == nil {
+		fake.dialTCPReturnsOnCall = make(map[int]struct {
+			result1 net.Conn
+			result2 error
+		})
+	}
+	fake.dialTCPReturnsOnCall[i] = struct {
+		result1 net.Conn
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSockets) DialUDP(port uint32) (net.Conn, error) {
+	fake.dialUDPMutex.Lock()
+	ret, specificReturn := fake.dialUDPReturnsOnCall[len(fake.dialUDPArgsForCall)]
+	fake.dialUDPArgsForCall = append(fake.dialUDPArgsForCall, struct {
+		port uint32
+	}{port})
+	fake.recordInvocation("DialUDP", []interface{}{port})
+	fake.dialUDPMutex.Unlock()
+	if fake.DialUDPStub != nil {
+		return fake.DialUDPStub(port)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.dialUDPReturns.result1, fake.dialUDPReturns.result2
+}
+
+func (fake *FakeSockets) DialUDPCallCount() int {
+	fake.dialUDPMutex.RLock()
+	defer fake.dialUDPMutex.RUnlock()
+	return len(fake.dialUDPArgsForCall)
+}
+
+func (fake *FakeSockets) DialUDPArgsForCall(i int) uint32 {
+	fake.dialUDPMutex.RLock()
+	defer fake.dialUDPMutex.RUnlock()
+	return fake.dialUDPArgsForCall[i].port
+}
+
+func (fake *FakeSockets) DialUDPReturns(result1 net.Conn, result2 error) {
+	fake.DialUDPStub = nil
+	fake.dialUDPReturns = struct {
+		result1 net.Conn
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSockets) DialUDPReturnsOnCall(i int, result1 net.Conn, result2 error) {
+	fake.DialUDPStub = nil
+	if fake.dialUDPReturnsOnCall == nil {
+		fake.dialUDPReturnsOnCall = make(map[int]struct {
+			result1 net.Conn
+			result2 error
+		})
+	}
+	fake.dialUDPReturnsOnCall[i] = struct {
+		result1 net.Conn
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSockets) ListenTCP(port uint32) (net.Listener, error) {
+	fake.listenTCPMutex.Lock()
+	ret, specificReturn := fake.listenTCPReturnsOnCall[len(fake.listenTCPArgsForCall)]
+	fake.listenTCPArgsForCall = append(fake.listenTCPArgsForCall, struct {
+		port uint32
+	}{port})
+	fake.recordInvocation("ListenTCP", []interface{}{port})
+	fake.listenTCPMutex.Unlock()
+	if fake.ListenTCPStub != nil {
+		return fake.ListenTCPStub(port)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.listenTCPReturns.result1, fake.listenTCPReturns.result2
+}
+
+func (fake *FakeSockets) ListenTCPCallCount() int {
+	fake.listenTCPMutex.RLock()
+	defer fake.listenTCPMutex.RUnlock()
+	return len(fake.listenTCPArgsForCall)
+}
+
+func (fake *FakeSockets) ListenTCPArgsForCall(i int) uint32 {
+	fake.listenTCPMutex.RLock()
+	defer fake.listenTCPMutex.RUnlock()
+	return fake.listenTCPArgsForCall[i].port
+}
+
+func (fake *FakeSockets) ListenTCPReturns(result1 net.Listener, result2 error) {
+	fake.ListenTCPStub = nil
+	fake.listenTCPReturns = struct {
+		result1 net.Listener
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSockets) ListenTCPReturnsOnCall(i int, result1 net.Listener, result2 error) {
+	fake.ListenTCPStub = nil
+	if fake.listenTCPReturnsOnCall == nil {
+		fake.listenTCPReturnsOnCall = make(map[int]struct {
+			result1 net.Listener
+			result2 error
+		})
+	}
+	fake.listenTCPReturnsOnCall[i] = struct {
+		result1 net.Listener
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSockets) Close() error {
+	fake.closeMutex.Lock()
+	ret, specificReturn := fake.closeReturnsOnCall[len(fake.closeArgsForCall)]
+	fake.closeArgsForCall = append(fake.closeArgsForCall, struct{}{})
+	fake.recordInvocation("Close", []interface{}{})
+	fake.closeMutex.Unlock()
+	if fake.CloseStub != nil {
+		return fake.CloseStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.closeReturns.result1
+}
+
+func (fake *FakeSockets) CloseCallCount() int {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return len(fake.closeArgsForCall)
+}
+
+func (fake *FakeSockets) CloseReturns(result1 error) {
+	fake.CloseStub = nil
+	fake.closeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSockets) CloseReturnsOnCall(i int, result1 error) {
+	fake.CloseStub = nil
+	if fake.closeReturnsOnCall == nil {
+		fake.closeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.closeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSockets) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.dialTCPMutex.RLock()
+	defer fake.dialTCPMutex.RUnlock()
+	fake.dialUDPMutex.RLock()
+	defer fake.dialUDPMutex.RUnlock()
+	fake.listenTCPMutex.RLock()
+	defer fake.listenTCPMutex.RUnlock()
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeSockets) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ userspace.Sockets = new(FakeSockets)
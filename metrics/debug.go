@@ -12,7 +12,7 @@ import (
 	"github.com/tedsuo/ifrit/http_server"
 )
 
-func StartDebugServer(address string, sink *lager.ReconfigurableSink, metrics Metrics) (ifrit.Process, error) {
+func StartDebugServer(address string, sink *lager.ReconfigurableSink, metrics Metrics, explainHandler, policyHandler, seccompAuditHandler http.Handler) (ifrit.Process, error) {
 	expvar.Publish("numCPUS", expvar.Func(func() interface{} {
 		return metrics.NumCPU()
 	}))
@@ -33,7 +33,15 @@ func StartDebugServer(address string, sink *lager.ReconfigurableSink, metrics Me
 		return metrics.DepotDirs()
 	}))
 
-	server := http_server.New(address, handler(sink))
+	expvar.Publish("portPoolUtilisation", expvar.Func(func() interface{} {
+		return metrics.PortPoolUtilisation()
+	}))
+
+	expvar.Publish("connectionLimitDrops", expvar.Func(func() interface{} {
+		return metrics.ConnectionLimitDrops()
+	}))
+
+	server := http_server.New(address, handler(sink, explainHandler, policyHandler, seccompAuditHandler))
 	p := ifrit.Invoke(server)
 	select {
 	case <-p.Ready():
@@ -43,13 +51,25 @@ func StartDebugServer(address string, sink *lager.ReconfigurableSink, metrics Me
 	return p, nil
 }
 
-func handler(sink *lager.ReconfigurableSink) http.Handler {
+func handler(sink *lager.ReconfigurableSink, explainHandler, policyHandler, seccompAuditHandler http.Handler) http.Handler {
 	pprofHandler := debugserver.Handler(sink)
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if strings.HasPrefix(r.URL.Path, "/debug/vars") {
 			http.DefaultServeMux.ServeHTTP(w, r)
 			return
 		}
+		if r.URL.Path == "/explain" && explainHandler != nil {
+			explainHandler.ServeHTTP(w, r)
+			return
+		}
+		if r.URL.Path == "/firewall-policy" && policyHandler != nil {
+			policyHandler.ServeHTTP(w, r)
+			return
+		}
+		if r.URL.Path == "/seccomp-audit" && seccompAuditHandler != nil {
+			seccompAuditHandler.ServeHTTP(w, r)
+			return
+		}
 		pprofHandler.ServeHTTP(w, r)
 	})
 }
@@ -29,9 +29,11 @@ var _ = Describe("Debug", func() {
 		fakeMetrics.LoopDevicesReturns(33)
 		fakeMetrics.BackingStoresReturns(12)
 		fakeMetrics.DepotDirsReturns(3)
+		fakeMetrics.PortPoolUtilisationReturns(42)
+		fakeMetrics.ConnectionLimitDropsReturns(map[string]uint64{"some-handle": 7})
 
 		sink := lager.NewReconfigurableSink(lager.NewWriterSink(GinkgoWriter, lager.DEBUG), lager.DEBUG)
-		serverProc, err = metrics.StartDebugServer("127.0.0.1:5123", sink, fakeMetrics)
+		serverProc, err = metrics.StartDebugServer("127.0.0.1:5123", sink, fakeMetrics, nil, nil, nil)
 		Expect(err).ToNot(HaveOccurred())
 	})
 
@@ -51,5 +53,7 @@ var _ = Describe("Debug", func() {
 		Expect(expvar.Get("depotDirs").String()).To(Equal("3"))
 		Expect(expvar.Get("numCPUS").String()).To(Equal("11"))
 		Expect(expvar.Get("numGoRoutines").String()).To(Equal("888"))
+		Expect(expvar.Get("portPoolUtilisation").String()).To(Equal("42"))
+		Expect(expvar.Get("connectionLimitDrops").String()).To(MatchJSON(`{"some-handle": 7}`))
 	})
 })
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/lager"
+)
+
+//go:generate counterfeiter . Explainer
+
+type Explainer interface {
+	Explain(log lager.Logger, handle string, traffic kawasaki.Traffic) (kawasaki.Explanation, error)
+}
+
+// NewExplainHandler serves explanations of whether traffic from a container
+// would be allowed, e.g. /explain?handle=h&destination=1.2.3.4&port=80&protocol=tcp
+func NewExplainHandler(logger lager.Logger, explainer Explainer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log := logger.Session("explain")
+
+		query := r.URL.Query()
+		handle := query.Get("handle")
+		if handle == "" {
+			http.Error(w, "handle is required", http.StatusBadRequest)
+			return
+		}
+
+		traffic, err := parseTraffic(query.Get("destination"), query.Get("port"), query.Get("protocol"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		explanation, err := explainer.Explain(log, handle, traffic)
+		if err != nil {
+			log.Error("explain-failed", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(explanation)
+	})
+}
+
+func parseTraffic(destination, port, protocol string) (kawasaki.Traffic, error) {
+	var traffic kawasaki.Traffic
+
+	traffic.Destination = net.ParseIP(destination)
+	if traffic.Destination == nil {
+		return kawasaki.Traffic{}, fmt.Errorf("invalid destination: '%s'", destination)
+	}
+
+	if protocol == "" {
+		protocol = "tcp"
+	}
+
+	var err error
+	traffic.Protocol, err = kawasaki.ParseProtocol(protocol)
+	if err != nil {
+		return kawasaki.Traffic{}, err
+	}
+
+	if port != "" {
+		parsed, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			return kawasaki.Traffic{}, fmt.Errorf("invalid port: '%s'", port)
+		}
+		traffic.Port = uint32(parsed)
+	}
+
+	return traffic, nil
+}
@@ -0,0 +1,110 @@
+package metrics_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/garden"
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/guardian/metrics"
+	fakes "code.cloudfoundry.org/guardian/metrics/metricsfakes"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExplainHandler", func() {
+	var (
+		fakeExplainer *fakes.FakeExplainer
+		handler       http.Handler
+		recorder      *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		fakeExplainer = new(fakes.FakeExplainer)
+		fakeExplainer.ExplainReturns(kawasaki.Explanation{
+			Allowed: true,
+			Chain:   "some-chain",
+			Rule:    "some-rule",
+			Reason:  "some-reason",
+		}, nil)
+
+		handler = metrics.NewExplainHandler(lagertest.NewTestLogger("test"), fakeExplainer)
+		recorder = httptest.NewRecorder()
+	})
+
+	serve := func(url string) {
+		req, err := http.NewRequest("GET", url, nil)
+		Expect(err).NotTo(HaveOccurred())
+		handler.ServeHTTP(recorder, req)
+	}
+
+	It("explains the traffic described by the query", func() {
+		serve("/explain?handle=some-handle&destination=1.2.3.4&port=443&protocol=udp")
+
+		Expect(fakeExplainer.ExplainCallCount()).To(Equal(1))
+		_, handle, traffic := fakeExplainer.ExplainArgsForCall(0)
+		Expect(handle).To(Equal("some-handle"))
+		Expect(traffic).To(Equal(kawasaki.Traffic{
+			Destination: net.ParseIP("1.2.3.4"),
+			Port:        443,
+			Protocol:    garden.ProtocolUDP,
+		}))
+	})
+
+	It("responds with the explanation as JSON", func() {
+		serve("/explain?handle=some-handle&destination=1.2.3.4&port=443")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		var explanation kawasaki.Explanation
+		Expect(json.NewDecoder(recorder.Body).Decode(&explanation)).To(Succeed())
+		Expect(explanation).To(Equal(kawasaki.Explanation{
+			Allowed: true,
+			Chain:   "some-chain",
+			Rule:    "some-rule",
+			Reason:  "some-reason",
+		}))
+	})
+
+	It("defaults to TCP", func() {
+		serve("/explain?handle=some-handle&destination=1.2.3.4&port=443")
+
+		_, _, traffic := fakeExplainer.ExplainArgsForCall(0)
+		Expect(traffic.Protocol).To(Equal(garden.ProtocolTCP))
+	})
+
+	DescribeBadRequest := func(description, url, message string) {
+		Context(description, func() {
+			It("responds with a bad request", func() {
+				serve(url)
+
+				Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+				Expect(recorder.Body.String()).To(ContainSubstring(message))
+				Expect(fakeExplainer.ExplainCallCount()).To(Equal(0))
+			})
+		})
+	}
+
+	DescribeBadRequest("when the handle is missing", "/explain?destination=1.2.3.4", "handle is required")
+	DescribeBadRequest("when the destination is invalid", "/explain?handle=some-handle&destination=banana", "invalid destination: 'banana'")
+	DescribeBadRequest("when the port is invalid", "/explain?handle=some-handle&destination=1.2.3.4&port=70000", "invalid port: '70000'")
+	DescribeBadRequest("when the protocol is invalid", "/explain?handle=some-handle&destination=1.2.3.4&protocol=sctp", "invalid protocol: sctp")
+
+	Context("when explaining fails", func() {
+		BeforeEach(func() {
+			fakeExplainer.ExplainReturns(kawasaki.Explanation{}, errors.New("no-such-container"))
+		})
+
+		It("responds with the error", func() {
+			serve("/explain?handle=some-handle&destination=1.2.3.4")
+
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(recorder.Body.String()).To(ContainSubstring("no-such-container"))
+		})
+	})
+})
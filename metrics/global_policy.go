@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/lager"
+)
+
+//go:generate counterfeiter . GlobalPolicyReloader
+
+type GlobalPolicyReloader interface {
+	Reload(log lager.Logger, policy kawasaki.GlobalPolicy) error
+}
+
+// NewGlobalPolicyHandler replaces the global firewall policy with the one
+// PUT as JSON, e.g. {"allow_host_access": false, "deny_networks": ["10.0.0.0/8"]}
+func NewGlobalPolicyHandler(logger lager.Logger, reloader GlobalPolicyReloader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log := logger.Session("global-policy")
+
+		if r.Method != "PUT" {
+			w.Header().Set("Allow", "PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var policy kawasaki.GlobalPolicy
+		if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := policy.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := reloader.Reload(log, policy); err != nil {
+			log.Error("reload-failed", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,84 @@
+package metrics_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/guardian/metrics"
+	fakes "code.cloudfoundry.org/guardian/metrics/metricsfakes"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GlobalPolicyHandler", func() {
+	var (
+		fakeReloader *fakes.FakeGlobalPolicyReloader
+		handler      http.Handler
+		recorder     *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		fakeReloader = new(fakes.FakeGlobalPolicyReloader)
+		handler = metrics.NewGlobalPolicyHandler(lagertest.NewTestLogger("test"), fakeReloader)
+		recorder = httptest.NewRecorder()
+	})
+
+	serve := func(method, body string) {
+		req, err := http.NewRequest(method, "/firewall-policy", strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		handler.ServeHTTP(recorder, req)
+	}
+
+	It("reloads the policy in the body", func() {
+		serve("PUT", `{"allow_host_access": true, "allow_networks": ["10.0.1.0/24"], "deny_networks": ["10.0.0.0/8"]}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		Expect(fakeReloader.ReloadCallCount()).To(Equal(1))
+		_, policy := fakeReloader.ReloadArgsForCall(0)
+		Expect(policy).To(Equal(kawasaki.GlobalPolicy{
+			AllowHostAccess: true,
+			AllowNetworks:   []string{"10.0.1.0/24"},
+			DenyNetworks:    []string{"10.0.0.0/8"},
+		}))
+	})
+
+	It("only accepts PUT", func() {
+		serve("GET", "")
+
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(fakeReloader.ReloadCallCount()).To(Equal(0))
+	})
+
+	It("rejects a body which is not JSON", func() {
+		serve("PUT", "banana")
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(fakeReloader.ReloadCallCount()).To(Equal(0))
+	})
+
+	It("rejects an invalid network", func() {
+		serve("PUT", `{"deny_networks": ["banana"]}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(recorder.Body.String()).To(ContainSubstring("invalid network: banana"))
+		Expect(fakeReloader.ReloadCallCount()).To(Equal(0))
+	})
+
+	Context("when reloading fails", func() {
+		BeforeEach(func() {
+			fakeReloader.ReloadReturns(errors.New("iptables-restore failed"))
+		})
+
+		It("responds with an internal server error", func() {
+			serve("PUT", `{"deny_networks": ["10.0.0.0/8"]}`)
+
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+			Expect(recorder.Body.String()).To(ContainSubstring("iptables-restore failed"))
+		})
+	})
+})
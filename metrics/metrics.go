@@ -18,18 +18,37 @@ type Metrics interface {
 	LoopDevices() int
 	BackingStores() int
 	DepotDirs() int
+	PortPoolUtilisation() int
+	ConnectionLimitDrops() map[string]uint64
+}
+
+//go:generate counterfeiter . ConnectionLimitCounter
+
+type ConnectionLimitCounter interface {
+	Drops() (map[string]uint64, error)
+}
+
+//go:generate counterfeiter . PortPool
+
+type PortPool interface {
+	Allocated() int
+	Capacity() int
 }
 
 type metrics struct {
 	backingStoresPath string
 	depotPath         string
+	portPool          PortPool
+	limitCounter      ConnectionLimitCounter
 	logger            lager.Logger
 }
 
-func NewMetrics(logger lager.Logger, backingStoresPath, depotPath string) Metrics {
+func NewMetrics(logger lager.Logger, backingStoresPath, depotPath string, portPool PortPool, limitCounter ConnectionLimitCounter) Metrics {
 	return &metrics{
 		backingStoresPath: backingStoresPath,
 		depotPath:         depotPath,
+		portPool:          portPool,
+		limitCounter:      limitCounter,
 		logger:            logger.Session("metrics"),
 	}
 }
@@ -75,3 +94,34 @@ func (m *metrics) DepotDirs() int {
 
 	return len(entries)
 }
+
+// PortPoolUtilisation returns the percentage of the port pool which is
+// allocated to containers
+func (m *metrics) PortPoolUtilisation() int {
+	if m.portPool == nil {
+		return -1
+	}
+
+	capacity := m.portPool.Capacity()
+	if capacity == 0 {
+		return -1
+	}
+
+	return m.portPool.Allocated() * 100 / capacity
+}
+
+// ConnectionLimitDrops returns the number of packets dropped by containers'
+// connection limits, by handle
+func (m *metrics) ConnectionLimitDrops() map[string]uint64 {
+	if m.limitCounter == nil {
+		return map[string]uint64{}
+	}
+
+	drops, err := m.limitCounter.Drops()
+	if err != nil {
+		m.logger.Error("cannot-get-connection-limit-drops", err)
+		return map[string]uint64{}
+	}
+
+	return drops
+}
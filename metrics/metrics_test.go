@@ -1,12 +1,14 @@
 package metrics_test
 
 import (
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"runtime"
 
 	"code.cloudfoundry.org/guardian/metrics"
+	fakes "code.cloudfoundry.org/guardian/metrics/metricsfakes"
 	"code.cloudfoundry.org/lager/lagertest"
 
 	. "github.com/onsi/ginkgo"
@@ -18,6 +20,8 @@ var _ = Describe("Metrics", func() {
 		logger           *lagertest.TestLogger
 		backingStorePath string
 		depotPath        string
+		fakePortPool     *fakes.FakePortPool
+		fakeLimitCounter *fakes.FakeConnectionLimitCounter
 
 		m metrics.Metrics
 	)
@@ -42,7 +46,12 @@ var _ = Describe("Metrics", func() {
 
 		Expect(err).ToNot(HaveOccurred())
 		logger = lagertest.NewTestLogger("test")
-		m = metrics.NewMetrics(logger, backingStorePath, depotPath)
+		fakePortPool = new(fakes.FakePortPool)
+		fakePortPool.CapacityReturns(200)
+		fakePortPool.AllocatedReturns(50)
+		fakeLimitCounter = new(fakes.FakeConnectionLimitCounter)
+		fakeLimitCounter.DropsReturns(map[string]uint64{"some-handle": 7}, nil)
+		m = metrics.NewMetrics(logger, backingStorePath, depotPath, fakePortPool, fakeLimitCounter)
 	})
 
 	AfterEach(func() {
@@ -58,9 +67,45 @@ var _ = Describe("Metrics", func() {
 		Expect(m.DepotDirs()).To(Equal(3))
 	})
 
+	It("should report the percentage of the port pool which is allocated", func() {
+		Expect(m.PortPoolUtilisation()).To(Equal(25))
+	})
+
+	Context("when the port pool has no capacity", func() {
+		It("reports PortPoolUtilisation as -1", func() {
+			fakePortPool.CapacityReturns(0)
+			Expect(m.PortPoolUtilisation()).To(Equal(-1))
+		})
+	})
+
+	It("should report the packets dropped by connection limits", func() {
+		Expect(m.ConnectionLimitDrops()).To(Equal(map[string]uint64{"some-handle": 7}))
+	})
+
+	Context("when the connection limit drops cannot be counted", func() {
+		It("reports no drops", func() {
+			fakeLimitCounter.DropsReturns(nil, errors.New("iptables-failed"))
+			Expect(m.ConnectionLimitDrops()).To(BeEmpty())
+		})
+	})
+
+	Context("when there is no connection limit counter", func() {
+		It("reports no drops", func() {
+			m := metrics.NewMetrics(logger, backingStorePath, depotPath, fakePortPool, nil)
+			Expect(m.ConnectionLimitDrops()).To(BeEmpty())
+		})
+	})
+
+	Context("when there is no port pool", func() {
+		It("reports PortPoolUtilisation as -1", func() {
+			m := metrics.NewMetrics(logger, backingStorePath, depotPath, nil, fakeLimitCounter)
+			Expect(m.PortPoolUtilisation()).To(Equal(-1))
+		})
+	})
+
 	Context("when the backing store path is empty", func() {
 		It("reports BackingStores as -1 without doing any funny business", func() {
-			m := metrics.NewMetrics(logger, "", depotPath)
+			m := metrics.NewMetrics(logger, "", depotPath, fakePortPool, fakeLimitCounter)
 			Expect(m.BackingStores()).To(Equal(-1))
 
 			Expect(logger.LogMessages()).To(BeEmpty())
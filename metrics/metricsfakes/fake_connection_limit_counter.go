@@ -0,0 +1,89 @@
+// This file was generated by counterfeiter
+package metricsfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/metrics"
+)
+
+type FakeConnectionLimitCounter struct {
+	DropsStub        func() (map[string]uint64, error)
+	dropsMutex       sync.RWMutex
+	dropsArgsForCall []struct{}
+	dropsReturns     struct {
+		result1 map[string]uint64
+		result2 error
+	}
+	dropsReturnsOnCall map[int]struct {
+		result1 map[string]uint64
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeConnectionLimitCounter) Drops() (map[string]uint64, error) {
+	fake.dropsMutex.Lock()
+	ret, specificReturn := fake.dropsReturnsOnCall[len(fake.dropsArgsForCall)]
+	fake.dropsArgsForCall = append(fake.dropsArgsForCall, struct{}{})
+	fake.recordInvocation("Drops", []interface{}{})
+	fake.dropsMutex.Unlock()
+	if fake.DropsStub != nil {
+		return fake.DropsStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.dropsReturns.result1, fake.dropsReturns.result2
+}
+
+func (fake *FakeConnectionLimitCounter) DropsCallCount() int {
+	fake.dropsMutex.RLock()
+	defer fake.dropsMutex.RUnlock()
+	return len(fake.dropsArgsForCall)
+}
+
+func (fake *FakeConnectionLimitCounter) DropsReturns(result1 map[string]uint64, result2 error) {
+	fake.DropsStub = nil
+	fake.dropsReturns = struct {
+		result1 map[string]uint64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeConnectionLimitCounter) DropsReturnsOnCall(i int, result1 map[string]uint64, result2 error) {
+	fake.DropsStub = nil
+	if fake.dropsReturnsOnCall == nil {
+		fake.dropsReturnsOnCall = make(map[int]struct {
+			result1 map[string]uint64
+			result2 error
+		})
+	}
+	fake.dropsReturnsOnCall[i] = struct {
+		result1 map[string]uint64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeConnectionLimitCounter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.dropsMutex.RLock()
+	defer fake.dropsMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeConnectionLimitCounter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ metrics.ConnectionLimitCounter = new(FakeConnectionLimitCounter)
@@ -0,0 +1,105 @@
+// This file was generated by counterfeiter
+package metricsfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/guardian/metrics"
+	"code.cloudfoundry.org/lager"
+)
+
+type FakeExplainer struct {
+	ExplainStub        func(log lager.Logger, handle string, traffic kawasaki.Traffic) (kawasaki.Explanation, error)
+	explainMutex       sync.RWMutex
+	explainArgsForCall []struct {
+		log     lager.Logger
+		handle  string
+		traffic kawasaki.Traffic
+	}
+	explainReturns struct {
+		result1 kawasaki.Explanation
+		result2 error
+	}
+	explainReturnsOnCall map[int]struct {
+		result1 kawasaki.Explanation
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeExplainer) Explain(log lager.Logger, handle string, traffic kawasaki.Traffic) (kawasaki.Explanation, error) {
+	fake.explainMutex.Lock()
+	ret, specificReturn := fake.explainReturnsOnCall[len(fake.explainArgsForCall)]
+	fake.explainArgsForCall = append(fake.explainArgsForCall, struct {
+		log     lager.Logger
+		handle  string
+		traffic kawasaki.Traffic
+	}{log, handle, traffic})
+	fake.recordInvocation("Explain", []interface{}{log, handle, traffic})
+	fake.explainMutex.Unlock()
+	if fake.ExplainStub != nil {
+		return fake.ExplainStub(log, handle, traffic)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.explainReturns.result1, fake.explainReturns.result2
+}
+
+func (fake *FakeExplainer) ExplainCallCount() int {
+	fake.explainMutex.RLock()
+	defer fake.explainMutex.RUnlock()
+	return len(fake.explainArgsForCall)
+}
+
+func (fake *FakeExplainer) ExplainArgsForCall(i int) (lager.Logger, string, kawasaki.Traffic) {
+	fake.explainMutex.RLock()
+	defer fake.explainMutex.RUnlock()
+	return fake.explainArgsForCall[i].log, fake.explainArgsForCall[i].handle, fake.explainArgsForCall[i].traffic
+}
+
+func (fake *FakeExplainer) ExplainReturns(result1 kawasaki.Explanation, result2 error) {
+	fake.ExplainStub = nil
+	fake.explainReturns = struct {
+		result1 kawasaki.Explanation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExplainer) ExplainReturnsOnCall(i int, result1 kawasaki.Explanation, result2 error) {
+	fake.ExplainStub = nil
+	if fake.explainReturnsOnCall == nil {
+		fake.explainReturnsOnCall = make(map[int]struct {
+			result1 kawasaki.Explanation
+			result2 error
+		})
+	}
+	fake.explainReturnsOnCall[i] = struct {
+		result1 kawasaki.Explanation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeExplainer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.explainMutex.RLock()
+	defer fake.explainMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeExplainer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ metrics.Explainer = new(FakeExplainer)
@@ -0,0 +1,98 @@
+// This file was generated by counterfeiter
+package metricsfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/kawasaki"
+	"code.cloudfoundry.org/guardian/metrics"
+	"code.cloudfoundry.org/lager"
+)
+
+type FakeGlobalPolicyReloader struct {
+	ReloadStub        func(log lager.Logger, policy kawasaki.GlobalPolicy) error
+	reloadMutex       sync.RWMutex
+	reloadArgsForCall []struct {
+		log    lager.Logger
+		policy kawasaki.GlobalPolicy
+	}
+	reloadReturns struct {
+		result1 error
+	}
+	reloadReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeGlobalPolicyReloader) Reload(log lager.Logger, policy kawasaki.GlobalPolicy) error {
+	fake.reloadMutex.Lock()
+	ret, specificReturn := fake.reloadReturnsOnCall[len(fake.reloadArgsForCall)]
+	fake.reloadArgsForCall = append(fake.reloadArgsForCall, struct {
+		log    lager.Logger
+		policy kawasaki.GlobalPolicy
+	}{log, policy})
+	fake.recordInvocation("Reload", []interface{}{log, policy})
+	fake.reloadMutex.Unlock()
+	if fake.ReloadStub != nil {
+		return fake.ReloadStub(log, policy)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.reloadReturns.result1
+}
+
+func (fake *FakeGlobalPolicyReloader) ReloadCallCount() int {
+	fake.reloadMutex.RLock()
+	defer fake.reloadMutex.RUnlock()
+	return len(fake.reloadArgsForCall)
+}
+
+func (fake *FakeGlobalPolicyReloader) ReloadArgsForCall(i int) (lager.Logger, kawasaki.GlobalPolicy) {
+	fake.reloadMutex.RLock()
+	defer fake.reloadMutex.RUnlock()
+	return fake.reloadArgsForCall[i].log, fake.reloadArgsForCall[i].policy
+}
+
+func (fake *FakeGlobalPolicyReloader) ReloadReturns(result1 error) {
+	fake.ReloadStub = nil
+	fake.reloadReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGlobalPolicyReloader) ReloadReturnsOnCall(i int, result1 error) {
+	fake.ReloadStub = nil
+	if fake.reloadReturnsOnCall == nil {
+		fake.reloadReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.reloadReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeGlobalPolicyReloader) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.reloadMutex.RLock()
+	defer fake.reloadMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeGlobalPolicyReloader) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ metrics.GlobalPolicyReloader = new(FakeGlobalPolicyReloader)
@@ -53,6 +53,24 @@ type FakeMetrics struct {
 	depotDirsReturnsOnCall map[int]struct {
 		result1 int
 	}
+	PortPoolUtilisationStub        func() int
+	portPoolUtilisationMutex       sync.RWMutex
+	portPoolUtilisationArgsForCall []struct{}
+	portPoolUtilisationReturns     struct {
+		result1 int
+	}
+	portPoolUtilisationReturnsOnCall map[int]struct {
+		result1 int
+	}
+	ConnectionLimitDropsStub        func() map[string]uint64
+	connectionLimitDropsMutex       sync.RWMutex
+	connectionLimitDropsArgsForCall []struct{}
+	connectionLimitDropsReturns     struct {
+		result1 map[string]uint64
+	}
+	connectionLimitDropsReturnsOnCall map[int]struct {
+		result1 map[string]uint64
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -257,6 +275,86 @@ func (fake *FakeMetrics) DepotDirsReturnsOnCall(i int, result1 int) {
 	}{result1}
 }
 
+func (fake *FakeMetrics) PortPoolUtilisation() int {
+	fake.portPoolUtilisationMutex.Lock()
+	ret, specificReturn := fake.portPoolUtilisationReturnsOnCall[len(fake.portPoolUtilisationArgsForCall)]
+	fake.portPoolUtilisationArgsForCall = append(fake.portPoolUtilisationArgsForCall, struct{}{})
+	fake.recordInvocation("PortPoolUtilisation", []interface{}{})
+	fake.portPoolUtilisationMutex.Unlock()
+	if fake.PortPoolUtilisationStub != nil {
+		return fake.PortPoolUtilisationStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.portPoolUtilisationReturns.result1
+}
+
+func (fake *FakeMetrics) PortPoolUtilisationCallCount() int {
+	fake.portPoolUtilisationMutex.RLock()
+	defer fake.portPoolUtilisationMutex.RUnlock()
+	return len(fake.portPoolUtilisationArgsForCall)
+}
+
+func (fake *FakeMetrics) PortPoolUtilisationReturns(result1 int) {
+	fake.PortPoolUtilisationStub = nil
+	fake.portPoolUtilisationReturns = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakeMetrics) PortPoolUtilisationReturnsOnCall(i int, result1 int) {
+	fake.PortPoolUtilisationStub = nil
+	if fake.portPoolUtilisationReturnsOnCall == nil {
+		fake.portPoolUtilisationReturnsOnCall = make(map[int]struct {
+			result1 int
+		})
+	}
+	fake.portPoolUtilisationReturnsOnCall[i] = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakeMetrics) ConnectionLimitDrops() map[string]uint64 {
+	fake.connectionLimitDropsMutex.Lock()
+	ret, specificReturn := fake.connectionLimitDropsReturnsOnCall[len(fake.connectionLimitDropsArgsForCall)]
+	fake.connectionLimitDropsArgsForCall = append(fake.connectionLimitDropsArgsForCall, struct{}{})
+	fake.recordInvocation("ConnectionLimitDrops", []interface{}{})
+	fake.connectionLimitDropsMutex.Unlock()
+	if fake.ConnectionLimitDropsStub != nil {
+		return fake.ConnectionLimitDropsStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.connectionLimitDropsReturns.result1
+}
+
+func (fake *FakeMetrics) ConnectionLimitDropsCallCount() int {
+	fake.connectionLimitDropsMutex.RLock()
+	defer fake.connectionLimitDropsMutex.RUnlock()
+	return len(fake.connectionLimitDropsArgsForCall)
+}
+
+func (fake *FakeMetrics) ConnectionLimitDropsReturns(result1 map[string]uint64) {
+	fake.ConnectionLimitDropsStub = nil
+	fake.connectionLimitDropsReturns = struct {
+		result1 map[string]uint64
+	}{result1}
+}
+
+func (fake *FakeMetrics) ConnectionLimitDropsReturnsOnCall(i int, result1 map[string]uint64) {
+	fake.ConnectionLimitDropsStub = nil
+	if fake.connectionLimitDropsReturnsOnCall == nil {
+		fake.connectionLimitDropsReturnsOnCall = make(map[int]struct {
+			result1 map[string]uint64
+		})
+	}
+	fake.connectionLimitDropsReturnsOnCall[i] = struct {
+		result1 map[string]uint64
+	}{result1}
+}
+
 func (fake *FakeMetrics) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -270,6 +368,10 @@ func (fake *FakeMetrics) Invocations() map[string][][]interface{} {
 	defer fake.backingStoresMutex.RUnlock()
 	fake.depotDirsMutex.RLock()
 	defer fake.depotDirsMutex.RUnlock()
+	fake.portPoolUtilisationMutex.RLock()
+	defer fake.portPoolUtilisationMutex.RUnlock()
+	fake.connectionLimitDropsMutex.RLock()
+	defer fake.connectionLimitDropsMutex.RUnlock()
 	return fake.invocations
 }
 
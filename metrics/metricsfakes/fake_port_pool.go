@@ -0,0 +1,135 @@
+// This file was generated by counterfeiter
+package metricsfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/metrics"
+)
+
+type FakePortPool struct {
+	AllocatedStub        func() int
+	allocatedMutex       sync.RWMutex
+	allocatedArgsForCall []struct{}
+	allocatedReturns     struct {
+		result1 int
+	}
+	allocatedReturnsOnCall map[int]struct {
+		result1 int
+	}
+	CapacityStub        func() int
+	capacityMutex       sync.RWMutex
+	capacityArgsForCall []struct{}
+	capacityReturns     struct {
+		result1 int
+	}
+	capacityReturnsOnCall map[int]struct {
+		result1 int
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakePortPool) Allocated() int {
+	fake.allocatedMutex.Lock()
+	ret, specificReturn := fake.allocatedReturnsOnCall[len(fake.allocatedArgsForCall)]
+	fake.allocatedArgsForCall = append(fake.allocatedArgsForCall, struct{}{})
+	fake.recordInvocation("Allocated", []interface{}{})
+	fake.allocatedMutex.Unlock()
+	if fake.AllocatedStub != nil {
+		return fake.AllocatedStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.allocatedReturns.result1
+}
+
+func (fake *FakePortPool) AllocatedCallCount() int {
+	fake.allocatedMutex.RLock()
+	defer fake.allocatedMutex.RUnlock()
+	return len(fake.allocatedArgsForCall)
+}
+
+func (fake *FakePortPool) AllocatedReturns(result1 int) {
+	fake.AllocatedStub = nil
+	fake.allocatedReturns = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakePortPool) AllocatedReturnsOnCall(i int, result1 int) {
+	fake.AllocatedStub = nil
+	if fake.allocatedReturnsOnCall == nil {
+		fake.allocatedReturnsOnCall = make(map[int]struct {
+			result1 int
+		})
+	}
+	fake.allocatedReturnsOnCall[i] = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakePortPool) Capacity() int {
+	fake.capacityMutex.Lock()
+	ret, specificReturn := fake.capacityReturnsOnCall[len(fake.capacityArgsForCall)]
+	fake.capacityArgsForCall = append(fake.capacityArgsForCall, struct{}{})
+	fake.recordInvocation("Capacity", []interface{}{})
+	fake.capacityMutex.Unlock()
+	if fake.CapacityStub != nil {
+		return fake.CapacityStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.capacityReturns.result1
+}
+
+func (fake *FakePortPool) CapacityCallCount() int {
+	fake.capacityMutex.RLock()
+	defer fake.capacityMutex.RUnlock()
+	return len(fake.capacityArgsForCall)
+}
+
+func (fake *FakePortPool) CapacityReturns(result1 int) {
+	fake.CapacityStub = nil
+	fake.capacityReturns = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakePortPool) CapacityReturnsOnCall(i int, result1 int) {
+	fake.CapacityStub = nil
+	if fake.capacityReturnsOnCall == nil {
+		fake.capacityReturnsOnCall = make(map[int]struct {
+			result1 int
+		})
+	}
+	fake.capacityReturnsOnCall[i] = struct {
+		result1 int
+	}{result1}
+}
+
+func (fake *FakePortPool) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.allocatedMutex.RLock()
+	defer fake.allocatedMutex.RUnlock()
+	fake.capacityMutex.RLock()
+	defer fake.capacityMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakePortPool) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ metrics.PortPool = new(FakePortPool)
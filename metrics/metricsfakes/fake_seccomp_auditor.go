@@ -0,0 +1,146 @@
+// This file was generated by counterfeiter
+package metricsfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/metrics"
+	"code.cloudfoundry.org/guardian/rundmc/seccomp"
+)
+
+type FakeSeccompAuditor struct {
+	AuditedStub        func(handle string) []seccomp.AuditedSyscall
+	auditedMutex       sync.RWMutex
+	auditedArgsForCall []struct {
+		handle string
+	}
+	auditedReturns struct {
+		result1 []seccomp.AuditedSyscall
+	}
+	auditedReturnsOnCall map[int]struct {
+		result1 []seccomp.AuditedSyscall
+	}
+	HandlesStub        func() []string
+	handlesMutex       sync.RWMutex
+	handlesArgsForCall []struct{}
+	handlesReturns     struct {
+		result1 []string
+	}
+	handlesReturnsOnCall map[int]struct {
+		result1 []string
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeSeccompAuditor) Audited(handle string) []seccomp.AuditedSyscall {
+	fake.auditedMutex.Lock()
+	ret, specificReturn := fake.auditedReturnsOnCall[len(fake.auditedArgsForCall)]
+	fake.auditedArgsForCall = append(fake.auditedArgsForCall, struct {
+		handle string
+	}{handle})
+	fake.recordInvocation("Audited", []interface{}{handle})
+	fake.auditedMutex.Unlock()
+	if fake.AuditedStub != nil {
+		return fake.AuditedStub(handle)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.auditedReturns.result1
+}
+
+func (fake *FakeSeccompAuditor) AuditedCallCount() int {
+	fake.auditedMutex.RLock()
+	defer fake.auditedMutex.RUnlock()
+	return len(fake.auditedArgsForCall)
+}
+
+func (fake *FakeSeccompAuditor) AuditedArgsForCall(i int) string {
+	fake.auditedMutex.RLock()
+	defer fake.auditedMutex.RUnlock()
+	return fake.auditedArgsForCall[i].handle
+}
+
+func (fake *FakeSeccompAuditor) AuditedReturns(result1 []seccomp.AuditedSyscall) {
+	fake.AuditedStub = nil
+	fake.auditedReturns = struct {
+		result1 []seccomp.AuditedSyscall
+	}{result1}
+}
+
+func (fake *FakeSeccompAuditor) AuditedReturnsOnCall(i int, result1 []seccomp.AuditedSyscall) {
+	fake.AuditedStub = nil
+	if fake.auditedReturnsOnCall == nil {
+		fake.auditedReturnsOnCall = make(map[int]struct {
+			result1 []seccomp.AuditedSyscall
+		})
+	}
+	fake.auditedReturnsOnCall[i] = struct {
+		result1 []seccomp.AuditedSyscall
+	}{result1}
+}
+
+func (fake *FakeSeccompAuditor) Handles() []string {
+	fake.handlesMutex.Lock()
+	ret, specificReturn := fake.handlesReturnsOnCall[len(fake.handlesArgsForCall)]
+	fake.handlesArgsForCall = append(fake.handlesArgsForCall, struct{}{})
+	fake.recordInvocation("Handles", []interface{}{})
+	fake.handlesMutex.Unlock()
+	if fake.HandlesStub != nil {
+		return fake.HandlesStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.handlesReturns.result1
+}
+
+func (fake *FakeSeccompAuditor) HandlesCallCount() int {
+	fake.handlesMutex.RLock()
+	defer fake.handlesMutex.RUnlock()
+	return len(fake.handlesArgsForCall)
+}
+
+func (fake *FakeSeccompAuditor) HandlesReturns(result1 []string) {
+	fake.HandlesStub = nil
+	fake.handlesReturns = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeSeccompAuditor) HandlesReturnsOnCall(i int, result1 []string) {
+	fake.HandlesStub = nil
+	if fake.handlesReturnsOnCall == nil {
+		fake.handlesReturnsOnCall = make(map[int]struct {
+			result1 []string
+		})
+	}
+	fake.handlesReturnsOnCall[i] = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeSeccompAuditor) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.auditedMutex.RLock()
+	defer fake.auditedMutex.RUnlock()
+	fake.handlesMutex.RLock()
+	defer fake.handlesMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeSeccompAuditor) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ metrics.SeccompAuditor = new(FakeSeccompAuditor)
@@ -25,6 +25,9 @@ const (
 	backingStores = Metric("BackingStores")
 	depotDirs     = Metric("DepotDirs")
 
+	portPoolUtilisation  = Metric("PortPoolUtilisation")
+	connectionLimitDrops = Metric("ConnectionLimitDrops")
+
 	metricsReportingDuration = Duration("MetricsReporting")
 )
 
@@ -72,6 +75,13 @@ func (notifier PeriodicMetronNotifier) Start() {
 				loopDevices.Send(notifier.metrics.LoopDevices())
 				backingStores.Send(notifier.metrics.BackingStores())
 				depotDirs.Send(notifier.metrics.DepotDirs())
+				portPoolUtilisation.Send(notifier.metrics.PortPoolUtilisation())
+
+				var drops uint64
+				for _, containerDrops := range notifier.metrics.ConnectionLimitDrops() {
+					drops += containerDrops
+				}
+				connectionLimitDrops.Send(int(drops))
 
 				finishedAt := notifier.Clock.Now()
 				metricsReportingDuration.Send(finishedAt.Sub(startedAt))
@@ -32,6 +32,8 @@ var _ = Describe("PeriodicMetronNotifier", func() {
 		fakeMetrics.LoopDevicesReturns(33)
 		fakeMetrics.BackingStoresReturns(12)
 		fakeMetrics.DepotDirsReturns(3)
+		fakeMetrics.PortPoolUtilisationReturns(42)
+		fakeMetrics.ConnectionLimitDropsReturns(map[string]uint64{"some-handle": 7, "other-handle": 5})
 
 		fakeClock = fakeclock.NewFakeClock(time.Unix(123, 456))
 
@@ -77,6 +79,20 @@ var _ = Describe("PeriodicMetronNotifier", func() {
 				Value: 3,
 				Unit:  "Metric",
 			}))
+
+			Eventually(func() fake.Metric {
+				return sender.GetValue("PortPoolUtilisation")
+			}).Should(Equal(fake.Metric{
+				Value: 42,
+				Unit:  "Metric",
+			}))
+
+			Eventually(func() fake.Metric {
+				return sender.GetValue("ConnectionLimitDrops")
+			}).Should(Equal(fake.Metric{
+				Value: 12,
+				Unit:  "Metric",
+			}))
 		})
 	})
 })
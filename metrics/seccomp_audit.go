@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/guardian/rundmc/seccomp"
+	"code.cloudfoundry.org/lager"
+)
+
+//go:generate counterfeiter . SeccompAuditor
+
+type SeccompAuditor interface {
+	Audited(handle string) []seccomp.AuditedSyscall
+	Handles() []string
+}
+
+// NewSeccompAuditHandler serves the syscalls which containers in seccomp audit
+// mode made and would otherwise have been denied, either of one container,
+// e.g. /seccomp-audit?handle=h, or of every container, by handle
+func NewSeccompAuditHandler(logger lager.Logger, auditor SeccompAuditor) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log := logger.Session("seccomp-audit")
+
+		var audited interface{}
+		if handle := r.URL.Query().Get("handle"); handle != "" {
+			audited = auditor.Audited(handle)
+		} else {
+			byHandle := map[string][]seccomp.AuditedSyscall{}
+			for _, handle := range auditor.Handles() {
+				byHandle[handle] = auditor.Audited(handle)
+			}
+			audited = byHandle
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(audited); err != nil {
+			log.Error("encoding-failed", err)
+		}
+	})
+}
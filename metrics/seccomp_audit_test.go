@@ -0,0 +1,68 @@
+package metrics_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"code.cloudfoundry.org/guardian/metrics"
+	fakes "code.cloudfoundry.org/guardian/metrics/metricsfakes"
+	"code.cloudfoundry.org/guardian/rundmc/seccomp"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SeccompAuditHandler", func() {
+	var (
+		fakeAuditor *fakes.FakeSeccompAuditor
+		handler     http.Handler
+		recorder    *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		fakeAuditor = new(fakes.FakeSeccompAuditor)
+		fakeAuditor.HandlesReturns([]string{"handle-a", "handle-b"})
+		fakeAuditor.AuditedStub = func(handle string) []seccomp.AuditedSyscall {
+			return []seccomp.AuditedSyscall{{Arch: "x86_64", Syscall: 83, Exe: "/bin/" + handle, Count: 2}}
+		}
+
+		handler = metrics.NewSeccompAuditHandler(lagertest.NewTestLogger("test"), fakeAuditor)
+		recorder = httptest.NewRecorder()
+	})
+
+	serve := func(url string) {
+		req, err := http.NewRequest("GET", url, nil)
+		Expect(err).NotTo(HaveOccurred())
+		handler.ServeHTTP(recorder, req)
+	}
+
+	It("responds with the syscalls audited in the container as JSON", func() {
+		serve("/seccomp-audit?handle=handle-a")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(fakeAuditor.AuditedArgsForCall(0)).To(Equal("handle-a"))
+
+		var audited []seccomp.AuditedSyscall
+		Expect(json.NewDecoder(recorder.Body).Decode(&audited)).To(Succeed())
+		Expect(audited).To(Equal([]seccomp.AuditedSyscall{
+			{Arch: "x86_64", Syscall: 83, Exe: "/bin/handle-a", Count: 2},
+		}))
+	})
+
+	Context("when no handle is given", func() {
+		It("responds with the syscalls audited in every container, by handle", func() {
+			serve("/seccomp-audit")
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+
+			var audited map[string][]seccomp.AuditedSyscall
+			Expect(json.NewDecoder(recorder.Body).Decode(&audited)).To(Succeed())
+			Expect(audited).To(Equal(map[string][]seccomp.AuditedSyscall{
+				"handle-a": {{Arch: "x86_64", Syscall: 83, Exe: "/bin/handle-a", Count: 2}},
+				"handle-b": {{Arch: "x86_64", Syscall: 83, Exe: "/bin/handle-b", Count: 2}},
+			}))
+		})
+	})
+})
@@ -99,25 +99,34 @@ func (p *externalBinaryNetworker) Network(log lager.Logger, containerSpec garden
 		p.configStore.Set(containerSpec.Handle, k, v)
 	}
 
-	containerIP, ok := p.configStore.Get(containerSpec.Handle, gardener.ContainerIPKey)
-	if ok {
-		log.Info("external-binary-write-dns-to-config", lager.Data{
-			"dnsServers": p.dnsServers,
-		})
-		cfg := kawasaki.NetworkConfig{
-			ContainerIP:     net.ParseIP(containerIP),
-			BridgeIP:        net.ParseIP(containerIP),
-			ContainerHandle: containerSpec.Handle,
-			DNSServers:      p.dnsServers,
-		}
+	return p.configureDNS(log, containerSpec.Handle, containerSpec.Handle, pid)
+}
 
-		err = p.resolvConfigurer.Configure(log, cfg, pid)
-		if err != nil {
-			return err
-		}
+// ShareNetwork configures the DNS of a container which joins the network of
+// another, which owns it, as the plugin's output for the owner asks
+func (p *externalBinaryNetworker) ShareNetwork(log lager.Logger, owner, handle string, pid int) error {
+	return p.configureDNS(log, owner, handle, pid)
+}
+
+// configureDNS writes a container's /etc/hosts and /etc/resolv.conf if the
+// plugin gave the container whose network it uses an IP
+func (p *externalBinaryNetworker) configureDNS(log lager.Logger, owner, handle string, pid int) error {
+	containerIP, ok := p.configStore.Get(owner, gardener.ContainerIPKey)
+	if !ok {
+		return nil
 	}
 
-	return nil
+	log.Info("external-binary-write-dns-to-config", lager.Data{
+		"dnsServers": p.dnsServers,
+	})
+	cfg := kawasaki.NetworkConfig{
+		ContainerIP:     net.ParseIP(containerIP),
+		BridgeIP:        net.ParseIP(containerIP),
+		ContainerHandle: handle,
+		DNSServers:      p.dnsServers,
+	}
+
+	return p.resolvConfigurer.Configure(log, cfg, pid)
 }
 
 func (p *externalBinaryNetworker) Destroy(log lager.Logger, handle string) error {
@@ -163,7 +172,8 @@ func (p *externalBinaryNetworker) NetIn(log lager.Logger, handle string, hostPor
 		return 0, 0, err
 	}
 
-	err = kawasaki.AddPortMapping(log, p.configStore, handle, garden.PortMapping{
+	err = kawasaki.AddPortMapping(log, p.configStore, handle, kawasaki.PortMapping{
+		HostIP:        p.externalIP.String(),
 		HostPort:      outputs.HostPort,
 		ContainerPort: outputs.ContainerPort,
 	})
@@ -292,6 +292,41 @@ var _ = Describe("ExternalNetworker", func() {
 		})
 	})
 
+	Describe("ShareNetwork", func() {
+		var sharer gardener.NetworkSharer
+
+		BeforeEach(func() {
+			sharer = plugin.(gardener.NetworkSharer)
+		})
+
+		Context("when the external plugin returned a containerIP for the owner", func() {
+			BeforeEach(func() {
+				configStore.Set("some-handle", gardener.ContainerIPKey, "10.255.1.2")
+			})
+
+			It("configures DNS inside the container which shares its network", func() {
+				Expect(sharer.ShareNetwork(logger, "some-handle", "sidecar", 43)).To(Succeed())
+
+				Expect(resolvConfigurer.ConfigureCallCount()).To(Equal(1))
+				_, cfg, pid := resolvConfigurer.ConfigureArgsForCall(0)
+				Expect(pid).To(Equal(43))
+				Expect(cfg).To(Equal(kawasaki.NetworkConfig{
+					ContainerIP:     net.ParseIP("10.255.1.2"),
+					BridgeIP:        net.ParseIP("10.255.1.2"),
+					ContainerHandle: "sidecar",
+					DNSServers:      []net.IP{net.ParseIP("8.8.8.8"), net.ParseIP("9.9.9.9")},
+				}))
+			})
+		})
+
+		Context("when the external plugin returned no containerIP for the owner", func() {
+			It("does not configure DNS", func() {
+				Expect(sharer.ShareNetwork(logger, "some-handle", "sidecar", 43)).To(Succeed())
+				Expect(resolvConfigurer.ConfigureCallCount()).To(Equal(0))
+			})
+		})
+	})
+
 	Describe("Destroy", func() {
 		It("executes the external plugin with the correct args", func() {
 			Expect(plugin.Destroy(logger, "my-handle")).To(Succeed())
@@ -360,8 +395,9 @@ var _ = Describe("ExternalNetworker", func() {
 
 			portMapping, ok := configStore.Get(handle, gardener.MappedPortsKey)
 			Expect(ok).To(BeTrue())
-			Expect(portMapping).To(MatchJSON(mustMarshalJSON([]garden.PortMapping{
+			Expect(portMapping).To(MatchJSON(mustMarshalJSON([]kawasaki.PortMapping{
 				{
+					HostIP:        "1.2.3.4",
 					HostPort:      1234,
 					ContainerPort: 5555,
 				},
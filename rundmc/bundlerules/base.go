@@ -11,18 +11,23 @@ type Base struct {
 	UnprivilegedBase goci.Bndl
 }
 
-func (r Base) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) goci.Bndl {
+func (r Base) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+	base := r.UnprivilegedBase
 	if spec.Privileged {
-		copiedBndl, err := copystructure.Copy(r.PrivilegedBase)
-		if err != nil {
-			panic(err)
-		}
-		return copiedBndl.(goci.Bndl)
-	} else {
-		copiedBndl, err := copystructure.Copy(r.UnprivilegedBase)
-		if err != nil {
-			panic(err)
-		}
-		return copiedBndl.(goci.Bndl)
+		base = r.PrivilegedBase
 	}
+
+	copiedBndl, err := copystructure.Copy(base)
+	if err != nil {
+		return goci.Bndl{}, err
+	}
+
+	bndl = copiedBndl.(goci.Bndl)
+
+	// containers with their own range of host IDs replace the server's mappings
+	if mappings := spec.IDMappings; !spec.Privileged && len(mappings.UIDMappings) > 0 {
+		bndl = bndl.WithUIDMappings(mappings.UIDMappings...).WithGIDMappings(mappings.GIDMappings...)
+	}
+
+	return bndl, nil
 }
@@ -5,6 +5,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/opencontainers/runtime-spec/specs-go"
 
+	"code.cloudfoundry.org/garden-shed/rootfs_provider"
 	"code.cloudfoundry.org/guardian/gardener"
 	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
 	"code.cloudfoundry.org/guardian/rundmc/goci"
@@ -30,17 +31,19 @@ var _ = Describe("Base", func() {
 
 	Context("when it is privileged", func() {
 		It("should use the correct base", func() {
-			retBndl := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
+			retBndl, err := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
 				Privileged: true,
 			})
+			Expect(err).NotTo(HaveOccurred())
 
 			Expect(retBndl).To(Equal(privilegeBndl))
 		})
 
 		It("returns a copy of the original Bndl data structure", func() {
-			retBndl := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
+			retBndl, err := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
 				Privileged: true,
 			})
+			Expect(err).NotTo(HaveOccurred())
 
 			// Spec.Linux.Resources is a pointer
 			Expect(retBndl.Spec.Linux.Resources.DisableOOMKiller).NotTo(BeIdenticalTo(privilegeBndl.Spec.Linux.Resources.DisableOOMKiller))
@@ -49,20 +52,41 @@ var _ = Describe("Base", func() {
 
 	Context("when it is not privileged", func() {
 		It("should use the correct base", func() {
-			retBndl := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
+			retBndl, err := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
 				Privileged: false,
 			})
+			Expect(err).NotTo(HaveOccurred())
 
 			Expect(retBndl).To(Equal(unprivilegeBndl))
 		})
 
 		It("returns a copy of the original Bndl data structure", func() {
-			retBndl := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
+			retBndl, err := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
 				Privileged: false,
 			})
+			Expect(err).NotTo(HaveOccurred())
 
 			// Spec.Linux.Resources is a pointer
 			Expect(retBndl.Spec.Linux.Resources.DisableOOMKiller).NotTo(BeIdenticalTo(unprivilegeBndl.Spec.Linux.Resources.DisableOOMKiller))
 		})
+
+		Context("when the container has its own range of host IDs", func() {
+			It("uses the container's id mappings", func() {
+				uidMapping := specs.LinuxIDMapping{ContainerID: 0, HostID: 100000, Size: 65536}
+				gidMapping := specs.LinuxIDMapping{ContainerID: 0, HostID: 200000, Size: 65536}
+				rule.UnprivilegedBase = unprivilegeBndl.WithUIDMappings(specs.LinuxIDMapping{ContainerID: 0, HostID: 1, Size: 1})
+
+				retBndl, err := rule.Apply(goci.Bndl{}, gardener.DesiredContainerSpec{
+					IDMappings: gardener.IDMappings{
+						UIDMappings: rootfs_provider.MappingList{uidMapping},
+						GIDMappings: rootfs_provider.MappingList{gidMapping},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(retBndl.UIDMappings()).To(ConsistOf(uidMapping))
+				Expect(retBndl.GIDMappings()).To(ConsistOf(gidMapping))
+			})
+		})
 	})
 })
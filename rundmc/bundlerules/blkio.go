@@ -0,0 +1,124 @@
+package bundlerules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// BlockIO shares and throttles a container's I/O to block devices, using the
+// limits set by its properties where given and the server's defaults
+// otherwise
+type BlockIO struct {
+	Defaults gardener.BlockIOLimits
+}
+
+func (b BlockIO) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+	limits := b.merge(spec.BlockIO)
+	if isEmpty(limits) && bndl.BlockIO() == nil {
+		return bndl, nil
+	}
+
+	var blockIO specs.LinuxBlockIO
+	if limits.Weight != 0 {
+		weight := limits.Weight
+		blockIO.Weight = &weight
+	}
+
+	var err error
+	if blockIO.ThrottleReadBpsDevice, err = throttleDevices(limits.ReadBps); err != nil {
+		return goci.Bndl{}, err
+	}
+	if blockIO.ThrottleWriteBpsDevice, err = throttleDevices(limits.WriteBps); err != nil {
+		return goci.Bndl{}, err
+	}
+	if blockIO.ThrottleReadIOPSDevice, err = throttleDevices(limits.ReadIOPS); err != nil {
+		return goci.Bndl{}, err
+	}
+	if blockIO.ThrottleWriteIOPSDevice, err = throttleDevices(limits.WriteIOPS); err != nil {
+		return goci.Bndl{}, err
+	}
+
+	return bndl.WithBlockIO(blockIO), nil
+}
+
+func (b BlockIO) merge(limits gardener.BlockIOLimits) gardener.BlockIOLimits {
+	if limits.Weight == 0 {
+		limits.Weight = b.Defaults.Weight
+	}
+	if len(limits.ReadBps) == 0 {
+		limits.ReadBps = b.Defaults.ReadBps
+	}
+	if len(limits.WriteBps) == 0 {
+		limits.WriteBps = b.Defaults.WriteBps
+	}
+	if len(limits.ReadIOPS) == 0 {
+		limits.ReadIOPS = b.Defaults.ReadIOPS
+	}
+	if len(limits.WriteIOPS) == 0 {
+		limits.WriteIOPS = b.Defaults.WriteIOPS
+	}
+
+	return limits
+}
+
+func isEmpty(limits gardener.BlockIOLimits) bool {
+	return limits.Weight == 0 &&
+		len(limits.ReadBps) == 0 && len(limits.WriteBps) == 0 &&
+		len(limits.ReadIOPS) == 0 && len(limits.WriteIOPS) == 0
+}
+
+func throttleDevices(rates []gardener.DeviceRate) ([]specs.LinuxThrottleDevice, error) {
+	var devices []specs.LinuxThrottleDevice
+	for _, rate := range rates {
+		major, minor, err := blockDeviceNumbers(rate.Device)
+		if err != nil {
+			return nil, err
+		}
+
+		device := specs.LinuxThrottleDevice{Rate: rate.Rate}
+		device.Major, device.Minor = major, minor
+		devices = append(devices, device)
+	}
+
+	return devices, nil
+}
+
+// blockDeviceNumbers returns the major and minor numbers of a block device
+// given either by its path, e.g. /dev/sda, or by the numbers themselves,
+// e.g. 8:0
+func blockDeviceNumbers(device string) (int64, int64, error) {
+	if !strings.HasPrefix(device, "/") {
+		numbers := strings.Split(device, ":")
+		if len(numbers) != 2 {
+			return 0, 0, fmt.Errorf("invalid block device: '%s'", device)
+		}
+
+		major, err := strconv.ParseInt(numbers[0], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid block device: '%s'", device)
+		}
+
+		minor, err := strconv.ParseInt(numbers[1], 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid block device: '%s'", device)
+		}
+
+		return major, minor, nil
+	}
+
+	info, err := LookupDevice(device)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if info.Type != "b" {
+		return 0, 0, fmt.Errorf("%s is not a block device", device)
+	}
+
+	return info.Major, info.Minor, nil
+}
@@ -0,0 +1,137 @@
+package bundlerules_test
+
+import (
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var _ = Describe("BlockIO", func() {
+	var (
+		rule bundlerules.BlockIO
+		bndl goci.Bndl
+		spec gardener.DesiredContainerSpec
+	)
+
+	throttle := func(major, minor int64, rate uint64) specs.LinuxThrottleDevice {
+		device := specs.LinuxThrottleDevice{Rate: rate}
+		device.Major, device.Minor = major, minor
+		return device
+	}
+
+	BeforeEach(func() {
+		rule = bundlerules.BlockIO{}
+		bndl = goci.Bundle()
+		spec = gardener.DesiredContainerSpec{
+			Handle: "some-handle",
+			BlockIO: gardener.BlockIOLimits{
+				Weight:    500,
+				ReadBps:   []gardener.DeviceRate{{Device: "8:0", Rate: 1048576}},
+				WriteBps:  []gardener.DeviceRate{{Device: "8:0", Rate: 524288}},
+				ReadIOPS:  []gardener.DeviceRate{{Device: "8:16", Rate: 100}},
+				WriteIOPS: []gardener.DeviceRate{{Device: "8:16", Rate: 50}, {Device: "8:0", Rate: 10}},
+			},
+		}
+	})
+
+	It("sets the requested weight", func() {
+		newBndl, err := rule.Apply(bndl, spec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*newBndl.BlockIO().Weight).To(BeEquivalentTo(500))
+	})
+
+	It("sets the requested throttles", func() {
+		newBndl, err := rule.Apply(bndl, spec)
+		Expect(err).NotTo(HaveOccurred())
+
+		blockIO := newBndl.BlockIO()
+		Expect(blockIO.ThrottleReadBpsDevice).To(Equal([]specs.LinuxThrottleDevice{throttle(8, 0, 1048576)}))
+		Expect(blockIO.ThrottleWriteBpsDevice).To(Equal([]specs.LinuxThrottleDevice{throttle(8, 0, 524288)}))
+		Expect(blockIO.ThrottleReadIOPSDevice).To(Equal([]specs.LinuxThrottleDevice{throttle(8, 16, 100)}))
+		Expect(blockIO.ThrottleWriteIOPSDevice).To(Equal([]specs.LinuxThrottleDevice{
+			throttle(8, 16, 50),
+			throttle(8, 0, 10),
+		}))
+	})
+
+	It("does not modify the original bundle", func() {
+		_, err := rule.Apply(bndl, spec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bndl.BlockIO()).To(BeNil())
+	})
+
+	Context("when the server has default limits", func() {
+		BeforeEach(func() {
+			rule.Defaults = gardener.BlockIOLimits{
+				Weight:   100,
+				ReadBps:  []gardener.DeviceRate{{Device: "8:0", Rate: 2048}},
+				ReadIOPS: []gardener.DeviceRate{{Device: "8:0", Rate: 20}},
+			}
+			spec.BlockIO = gardener.BlockIOLimits{
+				ReadBps: []gardener.DeviceRate{{Device: "8:16", Rate: 4096}},
+			}
+		})
+
+		It("uses the defaults for the limits the container does not set", func() {
+			newBndl, err := rule.Apply(bndl, spec)
+			Expect(err).NotTo(HaveOccurred())
+
+			blockIO := newBndl.BlockIO()
+			Expect(*blockIO.Weight).To(BeEquivalentTo(100))
+			Expect(blockIO.ThrottleReadIOPSDevice).To(Equal([]specs.LinuxThrottleDevice{throttle(8, 0, 20)}))
+			Expect(blockIO.ThrottleWriteBpsDevice).To(BeEmpty())
+		})
+
+		It("uses the container's limits where it sets them", func() {
+			newBndl, err := rule.Apply(bndl, spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newBndl.BlockIO().ThrottleReadBpsDevice).To(Equal([]specs.LinuxThrottleDevice{throttle(8, 16, 4096)}))
+		})
+	})
+
+	Context("when no limits are set", func() {
+		BeforeEach(func() {
+			spec.BlockIO = gardener.BlockIOLimits{}
+		})
+
+		It("returns the bundle unchanged", func() {
+			newBndl, err := rule.Apply(bndl, spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newBndl).To(Equal(bndl))
+		})
+
+		Context("and the bundle already has limits", func() {
+			BeforeEach(func() {
+				weight := uint16(500)
+				bndl = bndl.WithBlockIO(specs.LinuxBlockIO{Weight: &weight})
+			})
+
+			It("removes them", func() {
+				newBndl, err := rule.Apply(bndl, spec)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(newBndl.BlockIO()).To(Equal(&specs.LinuxBlockIO{}))
+			})
+		})
+	})
+
+	Context("when a device is not a valid major:minor pair", func() {
+		It("returns an error", func() {
+			spec.BlockIO.ReadBps = []gardener.DeviceRate{{Device: "sda", Rate: 1}}
+
+			_, err := rule.Apply(bndl, spec)
+			Expect(err).To(MatchError("invalid block device: 'sda'"))
+		})
+	})
+
+	Context("when a device path cannot be looked up", func() {
+		It("returns an error", func() {
+			spec.BlockIO.WriteIOPS = []gardener.DeviceRate{{Device: "/dev/not-a-device", Rate: 1}}
+
+			_, err := rule.Apply(bndl, spec)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,76 @@
+package bundlerules
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// Devices passes through the host devices which a privileged container
+// requests with the garden.devices property, if the server makes them
+// available for passthrough
+type Devices struct {
+	Passthrough []specs.LinuxDevice
+}
+
+func (d Devices) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+	if len(spec.Devices) == 0 {
+		return bndl, nil
+	}
+
+	if !spec.Privileged {
+		return goci.Bndl{}, fmt.Errorf("container %s must be privileged to use devices", spec.Handle)
+	}
+
+	devices := append([]specs.LinuxDevice{}, bndl.Devices()...)
+	var rules []specs.LinuxDeviceCgroup
+	for _, path := range spec.Devices {
+		device, ok := d.find(path)
+		if !ok {
+			return goci.Bndl{}, fmt.Errorf("device %s is not available for passthrough", path)
+		}
+
+		devices = append(devices, device)
+		rules = append(rules, DeviceCgroupRule(device))
+	}
+
+	return bndl.WithDevices(devices...).WithDeviceCgroups(rules...), nil
+}
+
+func (d Devices) find(path string) (specs.LinuxDevice, bool) {
+	for _, device := range d.Passthrough {
+		if device.Path == path {
+			return device, true
+		}
+	}
+
+	return specs.LinuxDevice{}, false
+}
+
+// DeviceCgroupRule returns the device cgroup rule allowing a container to
+// read, write and create the given device
+func DeviceCgroupRule(device specs.LinuxDevice) specs.LinuxDeviceCgroup {
+	rwm := "rwm"
+	kind := device.Type
+	major, minor := device.Major, device.Minor
+
+	return specs.LinuxDeviceCgroup{Allow: true, Type: &kind, Major: &major, Minor: &minor, Access: &rwm}
+}
+
+// LookupDevices describes the given host devices, e.g. /dev/kvm, for creation
+// in containers
+func LookupDevices(paths []string) ([]specs.LinuxDevice, error) {
+	devices := []specs.LinuxDevice{}
+	for _, path := range paths {
+		device, err := LookupDevice(path)
+		if err != nil {
+			return nil, err
+		}
+
+		devices = append(devices, device)
+	}
+
+	return devices, nil
+}
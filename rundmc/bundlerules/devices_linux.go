@@ -0,0 +1,43 @@
+package bundlerules
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// LookupDevice describes a host device, e.g. /dev/kvm, for creation in
+// containers with the same type, numbers, mode and owner
+func LookupDevice(path string) (specs.LinuxDevice, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return specs.LinuxDevice{}, fmt.Errorf("looking up device: %s", err)
+	}
+
+	var kind string
+	switch {
+	case info.Mode()&os.ModeCharDevice != 0:
+		kind = "c"
+	case info.Mode()&os.ModeDevice != 0:
+		kind = "b"
+	default:
+		return specs.LinuxDevice{}, fmt.Errorf("looking up device: %s is not a device", path)
+	}
+
+	stat := info.Sys().(*syscall.Stat_t)
+	rdev := uint64(stat.Rdev)
+	mode := info.Mode().Perm()
+	uid, gid := stat.Uid, stat.Gid
+
+	return specs.LinuxDevice{
+		Path:     path,
+		Type:     kind,
+		Major:    int64((rdev>>8)&0xfff | (rdev>>32)&^0xfff),
+		Minor:    int64(rdev&0xff | (rdev>>12)&^0xff),
+		FileMode: &mode,
+		UID:      &uid,
+		GID:      &gid,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package bundlerules_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LookupDevice", func() {
+	It("describes the host device", func() {
+		device, err := bundlerules.LookupDevice("/dev/null")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(device.Path).To(Equal("/dev/null"))
+		Expect(device.Type).To(Equal("c"))
+		Expect(device.Major).To(BeEquivalentTo(1))
+		Expect(device.Minor).To(BeEquivalentTo(3))
+		Expect(*device.FileMode).To(Equal(os.FileMode(0666)))
+		Expect(*device.UID).To(BeEquivalentTo(0))
+		Expect(*device.GID).To(BeEquivalentTo(0))
+	})
+
+	Context("when the device does not exist", func() {
+		It("returns an error", func() {
+			_, err := bundlerules.LookupDevice("/dev/not-a-device")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when the path is not a device", func() {
+		It("returns an error", func() {
+			file, err := ioutil.TempFile("", "not-a-device")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Remove(file.Name())
+			file.Close()
+
+			_, err = bundlerules.LookupDevice(file.Name())
+			Expect(err).To(MatchError("looking up device: " + file.Name() + " is not a device"))
+		})
+	})
+
+	Describe("LookupDevices", func() {
+		It("describes each device", func() {
+			devices, err := bundlerules.LookupDevices([]string{"/dev/null", "/dev/zero"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(devices).To(HaveLen(2))
+			Expect(devices[1].Minor).To(BeEquivalentTo(5))
+		})
+
+		Context("when a device cannot be looked up", func() {
+			It("returns an error", func() {
+				_, err := bundlerules.LookupDevices([]string{"/dev/null", "/dev/not-a-device"})
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
+
+var _ = Describe("BlockIO device paths", func() {
+	It("returns an error for devices which are not block devices", func() {
+		spec := gardener.DesiredContainerSpec{
+			BlockIO: gardener.BlockIOLimits{
+				ReadBps: []gardener.DeviceRate{{Device: "/dev/null", Rate: 1}},
+			},
+		}
+
+		_, err := bundlerules.BlockIO{}.Apply(goci.Bundle(), spec)
+		Expect(err).To(MatchError("/dev/null is not a block device"))
+	})
+})
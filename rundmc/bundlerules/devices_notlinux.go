@@ -0,0 +1,13 @@
+// +build !linux
+
+package bundlerules
+
+import (
+	"errors"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+func LookupDevice(path string) (specs.LinuxDevice, error) {
+	return specs.LinuxDevice{}, errors.New("looking up device: not supported on this platform")
+}
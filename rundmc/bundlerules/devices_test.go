@@ -0,0 +1,100 @@
+package bundlerules_test
+
+import (
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var _ = Describe("Devices", func() {
+	var (
+		rule bundlerules.Devices
+		bndl goci.Bndl
+		spec gardener.DesiredContainerSpec
+
+		fuse, kvm, tun specs.LinuxDevice
+		denyAll        specs.LinuxDeviceCgroup
+	)
+
+	BeforeEach(func() {
+		fuse = specs.LinuxDevice{Path: "/dev/fuse", Type: "c", Major: 10, Minor: 229}
+		kvm = specs.LinuxDevice{Path: "/dev/kvm", Type: "c", Major: 10, Minor: 232}
+		tun = specs.LinuxDevice{Path: "/dev/net/tun", Type: "c", Major: 10, Minor: 200}
+
+		rwm := "rwm"
+		denyAll = specs.LinuxDeviceCgroup{Allow: false, Access: &rwm}
+
+		rule = bundlerules.Devices{Passthrough: []specs.LinuxDevice{kvm, tun}}
+		bndl = goci.Bundle().
+			WithDevices(fuse).
+			WithResources(&specs.LinuxResources{Devices: []specs.LinuxDeviceCgroup{denyAll}})
+		spec = gardener.DesiredContainerSpec{Handle: "some-handle", Privileged: true, Devices: []string{"/dev/net/tun", "/dev/kvm"}}
+	})
+
+	It("adds the requested devices", func() {
+		newBndl, err := rule.Apply(bndl, spec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newBndl.Devices()).To(Equal([]specs.LinuxDevice{fuse, tun, kvm}))
+	})
+
+	It("allows the requested devices in the device cgroup", func() {
+		newBndl, err := rule.Apply(bndl, spec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newBndl.DeviceCgroups()).To(Equal([]specs.LinuxDeviceCgroup{
+			denyAll,
+			bundlerules.DeviceCgroupRule(tun),
+			bundlerules.DeviceCgroupRule(kvm),
+		}))
+	})
+
+	It("does not modify the original bundle", func() {
+		_, err := rule.Apply(bndl, spec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bndl.Devices()).To(Equal([]specs.LinuxDevice{fuse}))
+		Expect(bndl.DeviceCgroups()).To(Equal([]specs.LinuxDeviceCgroup{denyAll}))
+	})
+
+	Context("when no devices are requested", func() {
+		It("returns the bundle unchanged", func() {
+			spec.Devices = nil
+			spec.Privileged = false
+
+			newBndl, err := rule.Apply(bndl, spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newBndl).To(Equal(bndl))
+		})
+	})
+
+	Context("when the container is not privileged", func() {
+		It("returns an error", func() {
+			spec.Privileged = false
+
+			_, err := rule.Apply(bndl, spec)
+			Expect(err).To(MatchError("container some-handle must be privileged to use devices"))
+		})
+	})
+
+	Context("when a requested device is not available for passthrough", func() {
+		It("returns an error", func() {
+			spec.Devices = []string{"/dev/kvm", "/dev/sda"}
+
+			_, err := rule.Apply(bndl, spec)
+			Expect(err).To(MatchError("device /dev/sda is not available for passthrough"))
+		})
+	})
+
+	Describe("DeviceCgroupRule", func() {
+		It("allows reading, writing and creating the device", func() {
+			rule := bundlerules.DeviceCgroupRule(kvm)
+
+			Expect(rule.Allow).To(BeTrue())
+			Expect(*rule.Type).To(Equal("c"))
+			Expect(*rule.Major).To(BeEquivalentTo(10))
+			Expect(*rule.Minor).To(BeEquivalentTo(232))
+			Expect(*rule.Access).To(Equal("rwm"))
+		})
+	})
+})
@@ -1,15 +1,37 @@
 package bundlerules
 
 import (
+	"strings"
+
 	"code.cloudfoundry.org/guardian/gardener"
 	"code.cloudfoundry.org/guardian/rundmc/goci"
 )
 
 type Env struct {
+	// Defaults are set in containers whose environment does not set them,
+	// e.g. to point proxy-aware programs at the server's outbound proxy
+	Defaults []string
 }
 
-func (r Env) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) goci.Bndl {
+func (r Env) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+	env := append([]string{}, spec.Env...)
+	for _, envVar := range r.Defaults {
+		if !hasEnvVar(env, strings.SplitN(envVar, "=", 2)[0]) {
+			env = append(env, envVar)
+		}
+	}
+
 	process := bndl.Process()
-	process.Env = spec.Env
-	return bndl.WithProcess(process)
+	process.Env = env
+	return bndl.WithProcess(process), nil
+}
+
+func hasEnvVar(env []string, name string) bool {
+	for _, envVar := range env {
+		if strings.HasPrefix(envVar, name+"=") {
+			return true
+		}
+	}
+
+	return false
 }
@@ -14,14 +14,19 @@ var _ = Describe("Env Rule", func() {
 		rule    bundlerules.Env
 	)
 
-	JustBeforeEach(func() {
+	BeforeEach(func() {
 		rule = bundlerules.Env{}
-		newBndl = rule.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		newBndl, err = rule.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
 			Env: []string{
 				"TEST=banana",
 				"CONTAINER_NAME=hello",
 			},
 		})
+		Expect(err).NotTo(HaveOccurred())
 	})
 
 	It("sets the environment onto the bundle process", func() {
@@ -29,4 +34,16 @@ var _ = Describe("Env Rule", func() {
 			"TEST=banana", "CONTAINER_NAME=hello",
 		}))
 	})
+
+	Context("when there are default variables", func() {
+		BeforeEach(func() {
+			rule.Defaults = []string{"ALL_PROXY=socks5h://127.0.0.1:1080", "TEST=apple"}
+		})
+
+		It("adds those which the environment does not set", func() {
+			Expect(newBndl.Spec.Process.Env).To(Equal([]string{
+				"TEST=banana", "CONTAINER_NAME=hello", "ALL_PROXY=socks5h://127.0.0.1:1080",
+			}))
+		})
+	})
 })
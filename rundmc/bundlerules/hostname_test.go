@@ -12,18 +12,20 @@ import (
 
 var _ = Describe("Hostname", func() {
 	It("sets the correct hostname in the bundle", func() {
-		newBndl := bundlerules.Hostname{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+		newBndl, err := bundlerules.Hostname{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
 			Hostname: "banana",
 		})
+		Expect(err).NotTo(HaveOccurred())
 
 		Expect(newBndl.Hostname()).To(Equal("banana"))
 	})
 
 	Context("when the hostname is longer than 49 characters", func() {
 		It("should use the last 49 characters of it", func() {
-			newBndl := bundlerules.Hostname{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+			newBndl, err := bundlerules.Hostname{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
 				Hostname: strings.Repeat("banana", 9),
 			})
+			Expect(err).NotTo(HaveOccurred())
 
 			Expect(newBndl.Hostname()).To(Equal("a" + strings.Repeat("banana", 8)))
 		})
@@ -1,7 +1,10 @@
 package bundlerules
 
 import (
+	"fmt"
+
 	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/cpuset"
 	"code.cloudfoundry.org/guardian/rundmc/goci"
 	"github.com/opencontainers/runtime-spec/specs-go"
 )
@@ -11,11 +14,47 @@ var MinCpuQuota uint64 = 1000
 
 type Limits struct {
 	CpuQuotaPerShare uint64
+
+	// SharedCPUs are the CPUs which containers not pinned to CPUs run on, or
+	// empty to run them on any CPU
+	SharedCPUs string
+
+	// MemoryDefaults are the memory options of containers which do not set
+	// their own
+	MemoryDefaults gardener.MemoryOptions
+
+	// UnifiedCgroups is true when the host uses the unified (v2) cgroup
+	// hierarchy, which has no kernel memory limit or swappiness
+	UnifiedCgroups bool
 }
 
-func (l Limits) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) goci.Bndl {
+func (l Limits) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+	if score := spec.Memory.OOMScoreAdj; score != nil && *score < 0 && !spec.Privileged {
+		return goci.Bndl{}, fmt.Errorf("container %s must be privileged to lower its oom score", spec.Handle)
+	}
+
+	memory := l.memoryOptions(spec.Memory)
 	limit := uint64(spec.Limits.Memory.LimitInBytes)
-	bndl = bndl.WithMemoryLimit(specs.LinuxMemory{Limit: &limit, Swap: &limit})
+	swap := limit
+	if memory.Swap != nil && limit > 0 {
+		swap = limit + *memory.Swap
+	}
+
+	memorySpec := specs.LinuxMemory{Limit: &limit, Swap: &swap}
+	if memory.Reservation != 0 {
+		memorySpec.Reservation = &memory.Reservation
+	}
+	if !l.UnifiedCgroups {
+		memorySpec.Swappiness = memory.Swappiness
+		if memory.Kernel != 0 {
+			memorySpec.Kernel = &memory.Kernel
+		}
+	}
+	bndl = bndl.WithMemoryLimit(memorySpec)
+
+	if memory.OOMScoreAdj != nil {
+		bndl = bndl.WithOOMScoreAdj(*memory.OOMScoreAdj)
+	}
 
 	shares := uint64(spec.Limits.CPU.LimitInShares)
 	cpuSpec := specs.LinuxCPU{Shares: &shares}
@@ -28,8 +67,51 @@ func (l Limits) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) goci.B
 		}
 		cpuSpec.Quota = &quota
 	}
+
+	cpuSpec.Cpus, cpuSpec.Mems = l.SharedCPUs, spec.CPUSet.Mems
+	if spec.CPUSet.CPUs != "" {
+		cpuSpec.Cpus = spec.CPUSet.CPUs
+	}
+
+	for _, list := range []string{cpuSpec.Cpus, cpuSpec.Mems} {
+		if _, err := cpuset.Parse(list); err != nil {
+			return goci.Bndl{}, fmt.Errorf("container %s: %s", spec.Handle, err)
+		}
+	}
 	bndl = bndl.WithCPUShares(cpuSpec)
 
 	pids := int64(spec.Limits.Pid.Max)
-	return bndl.WithPidLimit(specs.LinuxPids{Limit: pids})
+	return bndl.WithPidLimit(specs.LinuxPids{Limit: pids}), nil
+}
+
+// memoryOptions returns the container's memory options, using the defaults for
+// those it does not set. The options are copied, so that containers do not
+// share them.
+func (l Limits) memoryOptions(options gardener.MemoryOptions) gardener.MemoryOptions {
+	if options.Reservation == 0 {
+		options.Reservation = l.MemoryDefaults.Reservation
+	}
+	if options.Swap == nil {
+		options.Swap = l.MemoryDefaults.Swap
+	}
+	if options.Swappiness == nil {
+		options.Swappiness = l.MemoryDefaults.Swappiness
+	}
+	if options.Kernel == 0 {
+		options.Kernel = l.MemoryDefaults.Kernel
+	}
+	if options.OOMScoreAdj == nil {
+		options.OOMScoreAdj = l.MemoryDefaults.OOMScoreAdj
+	}
+
+	if options.Swap != nil {
+		swap := *options.Swap
+		options.Swap = &swap
+	}
+	if options.Swappiness != nil {
+		swappiness := *options.Swappiness
+		options.Swappiness = &swappiness
+	}
+
+	return options
 }
@@ -13,22 +13,166 @@ import (
 
 var _ = Describe("LimitsRule", func() {
 	It("sets the correct memory limit in bundle resources", func() {
-		newBndl := bundlerules.Limits{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+		newBndl, err := bundlerules.Limits{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
 			Limits: garden.Limits{
 				Memory: garden.MemoryLimits{LimitInBytes: 4096},
 			},
 		})
+		Expect(err).NotTo(HaveOccurred())
 
 		Expect(*(newBndl.Resources().Memory.Limit)).To(BeNumerically("==", 4096))
 		Expect(*(newBndl.Resources().Memory.Swap)).To(BeNumerically("==", 4096))
 	})
 
+	Describe("memory options", func() {
+		var (
+			limits bundlerules.Limits
+			spec   gardener.DesiredContainerSpec
+		)
+
+		BeforeEach(func() {
+			limits = bundlerules.Limits{}
+			swap, swappiness, score := uint64(1024), uint64(10), 500
+			spec = gardener.DesiredContainerSpec{
+				Handle: "some-handle",
+				Limits: garden.Limits{
+					Memory: garden.MemoryLimits{LimitInBytes: 4096},
+				},
+				Memory: gardener.MemoryOptions{
+					Reservation: 2048,
+					Swap:        &swap,
+					Swappiness:  &swappiness,
+					Kernel:      512,
+					OOMScoreAdj: &score,
+				},
+			}
+		})
+
+		It("sets them in bundle resources", func() {
+			newBndl, err := limits.Apply(goci.Bundle(), spec)
+			Expect(err).NotTo(HaveOccurred())
+
+			memory := newBndl.Resources().Memory
+			Expect(*memory.Limit).To(BeEquivalentTo(4096))
+			Expect(*memory.Reservation).To(BeEquivalentTo(2048))
+			Expect(*memory.Swappiness).To(BeEquivalentTo(10))
+			Expect(*memory.Kernel).To(BeEquivalentTo(512))
+			Expect(*newBndl.OOMScoreAdj()).To(Equal(500))
+		})
+
+		It("allows the swap in addition to the memory limit", func() {
+			newBndl, err := limits.Apply(goci.Bundle(), spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*newBndl.Resources().Memory.Swap).To(BeEquivalentTo(5120))
+		})
+
+		Context("when the container has no memory limit", func() {
+			It("does not limit its swap", func() {
+				spec.Limits.Memory.LimitInBytes = 0
+
+				newBndl, err := limits.Apply(goci.Bundle(), spec)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(*newBndl.Resources().Memory.Swap).To(BeEquivalentTo(0))
+			})
+		})
+
+		Context("when the server has defaults", func() {
+			BeforeEach(func() {
+				swap, score := uint64(8192), -100
+				limits.MemoryDefaults = gardener.MemoryOptions{Reservation: 1024, Swap: &swap, OOMScoreAdj: &score}
+				spec.Memory = gardener.MemoryOptions{Reservation: 3072}
+			})
+
+			It("uses them for the options the container does not set", func() {
+				newBndl, err := limits.Apply(goci.Bundle(), spec)
+				Expect(err).NotTo(HaveOccurred())
+
+				memory := newBndl.Resources().Memory
+				Expect(*memory.Reservation).To(BeEquivalentTo(3072))
+				Expect(*memory.Swap).To(BeEquivalentTo(4096 + 8192))
+				Expect(memory.Swappiness).To(BeNil())
+				Expect(memory.Kernel).To(BeNil())
+				Expect(*newBndl.OOMScoreAdj()).To(Equal(-100))
+			})
+
+			It("does not share them between containers", func() {
+				newBndl, err := limits.Apply(goci.Bundle(), spec)
+				Expect(err).NotTo(HaveOccurred())
+
+				*newBndl.Resources().Memory.Swap = 0
+				Expect(*limits.MemoryDefaults.Swap).To(BeEquivalentTo(8192))
+			})
+		})
+
+		Context("when the host uses the unified cgroup hierarchy", func() {
+			BeforeEach(func() {
+				limits.UnifiedCgroups = true
+			})
+
+			It("does not set the kernel memory limit or swappiness", func() {
+				newBndl, err := limits.Apply(goci.Bundle(), spec)
+				Expect(err).NotTo(HaveOccurred())
+
+				memory := newBndl.Resources().Memory
+				Expect(memory.Kernel).To(BeNil())
+				Expect(memory.Swappiness).To(BeNil())
+			})
+
+			It("sets the other memory options", func() {
+				newBndl, err := limits.Apply(goci.Bundle(), spec)
+				Expect(err).NotTo(HaveOccurred())
+
+				memory := newBndl.Resources().Memory
+				Expect(*memory.Limit).To(BeEquivalentTo(4096))
+				Expect(*memory.Swap).To(BeEquivalentTo(5120))
+				Expect(*memory.Reservation).To(BeEquivalentTo(2048))
+				Expect(*newBndl.OOMScoreAdj()).To(Equal(500))
+			})
+		})
+
+		Context("when the container lowers its oom score", func() {
+			BeforeEach(func() {
+				score := -500
+				spec.Memory.OOMScoreAdj = &score
+			})
+
+			It("returns an error for unprivileged containers", func() {
+				_, err := limits.Apply(goci.Bundle(), spec)
+				Expect(err).To(MatchError("container some-handle must be privileged to lower its oom score"))
+			})
+
+			It("allows privileged containers", func() {
+				spec.Privileged = true
+
+				newBndl, err := limits.Apply(goci.Bundle(), spec)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(*newBndl.OOMScoreAdj()).To(Equal(-500))
+			})
+		})
+
+		Context("when no memory options are set", func() {
+			It("disables swap and leaves the oom score alone", func() {
+				newBndl, err := limits.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+					Limits: garden.Limits{
+						Memory: garden.MemoryLimits{LimitInBytes: 4096},
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(*newBndl.Resources().Memory.Swap).To(BeEquivalentTo(4096))
+				Expect(newBndl.Resources().Memory.Reservation).To(BeNil())
+				Expect(newBndl.OOMScoreAdj()).To(BeNil())
+			})
+		})
+	})
+
 	It("sets the correct CPU limit in bundle resources", func() {
-		newBndl := bundlerules.Limits{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+		newBndl, err := bundlerules.Limits{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
 			Limits: garden.Limits{
 				CPU: garden.CPULimits{LimitInShares: 1},
 			},
 		})
+		Expect(err).NotTo(HaveOccurred())
 
 		Expect(*(newBndl.Resources().CPU.Shares)).To(BeNumerically("==", 1))
 		Expect(newBndl.Resources().CPU.Period).To(BeNil())
@@ -41,11 +185,12 @@ var _ = Describe("LimitsRule", func() {
 			limits := bundlerules.Limits{
 				CpuQuotaPerShare: quotaPerShare,
 			}
-			newBndl := limits.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+			newBndl, err := limits.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
 				Limits: garden.Limits{
 					CPU: garden.CPULimits{LimitInShares: limitInShares},
 				},
 			})
+			Expect(err).NotTo(HaveOccurred())
 
 			Expect(*(newBndl.Resources().CPU.Period)).To(BeNumerically("==", 100000))
 			Expect(*(newBndl.Resources().CPU.Quota)).To(BeNumerically("==", limitInShares*quotaPerShare))
@@ -57,11 +202,12 @@ var _ = Describe("LimitsRule", func() {
 			limits := bundlerules.Limits{
 				CpuQuotaPerShare: 1,
 			}
-			newBndl := limits.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+			newBndl, err := limits.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
 				Limits: garden.Limits{
 					CPU: garden.CPULimits{LimitInShares: 1},
 				},
 			})
+			Expect(err).NotTo(HaveOccurred())
 
 			Expect(*(newBndl.Resources().CPU.Quota)).To(BeNumerically("==", 1000))
 		})
@@ -72,11 +218,12 @@ var _ = Describe("LimitsRule", func() {
 			limits := bundlerules.Limits{
 				CpuQuotaPerShare: 0,
 			}
-			newBndl := limits.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+			newBndl, err := limits.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
 				Limits: garden.Limits{
 					CPU: garden.CPULimits{LimitInShares: 1},
 				},
 			})
+			Expect(err).NotTo(HaveOccurred())
 
 			Expect(*(newBndl.Resources().CPU.Shares)).To(BeNumerically("==", 1))
 			Expect(newBndl.Resources().CPU.Period).To(BeNil())
@@ -89,7 +236,8 @@ var _ = Describe("LimitsRule", func() {
 			limits := bundlerules.Limits{
 				CpuQuotaPerShare: 5,
 			}
-			newBndl := limits.Apply(goci.Bundle(), gardener.DesiredContainerSpec{})
+			newBndl, err := limits.Apply(goci.Bundle(), gardener.DesiredContainerSpec{})
+			Expect(err).NotTo(HaveOccurred())
 
 			Expect(*(newBndl.Resources().CPU.Shares)).To(BeNumerically("==", 0))
 			Expect(newBndl.Resources().CPU.Period).To(BeNil())
@@ -97,12 +245,64 @@ var _ = Describe("LimitsRule", func() {
 		})
 	})
 
+	Context("when the container is pinned to CPUs", func() {
+		It("sets the cpuset in bundle resources", func() {
+			newBndl, err := bundlerules.Limits{SharedCPUs: "0-1"}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+				CPUSet: gardener.CPUSet{CPUs: "2-3", Mems: "1"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(newBndl.Resources().CPU.Cpus).To(Equal("2-3"))
+			Expect(newBndl.Resources().CPU.Mems).To(Equal("1"))
+		})
+
+		Context("when the CPU list is invalid", func() {
+			It("returns an error", func() {
+				_, err := bundlerules.Limits{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+					Handle: "some-handle",
+					CPUSet: gardener.CPUSet{CPUs: "all"},
+				})
+				Expect(err).To(MatchError("container some-handle: invalid cpu list: 'all'"))
+			})
+		})
+
+		Context("when the memory node list is invalid", func() {
+			It("returns an error", func() {
+				_, err := bundlerules.Limits{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+					Handle: "some-handle",
+					CPUSet: gardener.CPUSet{Mems: "1-0"},
+				})
+				Expect(err).To(MatchError("container some-handle: invalid cpu list: '1-0'"))
+			})
+		})
+	})
+
+	Context("when the container is not pinned to CPUs", func() {
+		It("runs it on the shared CPUs", func() {
+			newBndl, err := bundlerules.Limits{SharedCPUs: "0-1"}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(newBndl.Resources().CPU.Cpus).To(Equal("0-1"))
+			Expect(newBndl.Resources().CPU.Mems).To(BeEmpty())
+		})
+
+		Context("when there are no shared CPUs", func() {
+			It("does not restrict the CPUs", func() {
+				newBndl, err := bundlerules.Limits{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(newBndl.Resources().CPU.Cpus).To(BeEmpty())
+			})
+		})
+	})
+
 	It("sets the correct PID limit in bundle resources", func() {
-		newBndl := bundlerules.Limits{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+		newBndl, err := bundlerules.Limits{}.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
 			Limits: garden.Limits{
 				Pid: garden.PidLimits{Max: 1},
 			},
 		})
+		Expect(err).NotTo(HaveOccurred())
 
 		Expect(newBndl.Resources().Pids.Limit).To(BeNumerically("==", 1))
 	})
@@ -115,11 +315,12 @@ var _ = Describe("LimitsRule", func() {
 			},
 		)
 
-		newBndl := bundlerules.Limits{}.Apply(bndl, gardener.DesiredContainerSpec{
+		newBndl, err := bundlerules.Limits{}.Apply(bndl, gardener.DesiredContainerSpec{
 			Limits: garden.Limits{
 				Memory: garden.MemoryLimits{LimitInBytes: 4096},
 			},
 		})
+		Expect(err).NotTo(HaveOccurred())
 
 		Expect(*(newBndl.Resources().Memory.Limit)).To(BeNumerically("==", 4096))
 		Expect(newBndl.Resources().Devices).To(Equal(bndl.Resources().Devices))
@@ -0,0 +1,34 @@
+package bundlerules
+
+import (
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+type NetworkNamespace struct {
+}
+
+func (n NetworkNamespace) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+	if spec.HostNetwork {
+		var namespaces []specs.LinuxNamespace
+		for _, ns := range bndl.Namespaces() {
+			if ns.Type != specs.NetworkNamespace {
+				namespaces = append(namespaces, ns)
+			}
+		}
+
+		return bndl.WithNamespaces(namespaces...), nil
+	}
+
+	if spec.NetworkNamespacePath == "" {
+		return bndl, nil
+	}
+
+	// copy the namespaces so that the base bundle's slice is not modified in place
+	namespaces := goci.NamespaceSlice(append([]specs.LinuxNamespace{}, bndl.Namespaces()...))
+	return bndl.WithNamespaces(namespaces.Set(specs.LinuxNamespace{
+		Type: specs.NetworkNamespace,
+		Path: spec.NetworkNamespacePath,
+	})...), nil
+}
@@ -0,0 +1,59 @@
+package bundlerules_test
+
+import (
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var _ = Describe("NetworkNamespace", func() {
+	var bndl goci.Bndl
+
+	BeforeEach(func() {
+		bndl = goci.Bundle().WithNamespaces(goci.NetworkNamespace, goci.PIDNamespace)
+	})
+
+	It("leaves the network namespace alone when no path is given", func() {
+		newBndl, err := bundlerules.NetworkNamespace{}.Apply(bndl, gardener.DesiredContainerSpec{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(newBndl.Namespaces()).To(ConsistOf(goci.NetworkNamespace, goci.PIDNamespace))
+	})
+
+	Context("when a network namespace path is given", func() {
+		var newBndl goci.Bndl
+
+		BeforeEach(func() {
+			var err error
+			newBndl, err = bundlerules.NetworkNamespace{}.Apply(bndl, gardener.DesiredContainerSpec{
+				NetworkNamespacePath: "/proc/42/ns/net",
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("joins the given network namespace", func() {
+			Expect(newBndl.Namespaces()).To(ConsistOf(
+				specs.LinuxNamespace{Type: specs.NetworkNamespace, Path: "/proc/42/ns/net"},
+				goci.PIDNamespace,
+			))
+		})
+
+		It("does not modify the original bundle", func() {
+			Expect(bndl.Namespaces()).To(ConsistOf(goci.NetworkNamespace, goci.PIDNamespace))
+		})
+	})
+
+	Context("when the container uses the host network", func() {
+		It("removes the network namespace", func() {
+			newBndl, err := bundlerules.NetworkNamespace{}.Apply(bndl, gardener.DesiredContainerSpec{
+				HostNetwork: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(newBndl.Namespaces()).To(ConsistOf(goci.PIDNamespace))
+		})
+	})
+})
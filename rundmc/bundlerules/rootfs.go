@@ -20,13 +20,18 @@ type RootFS struct {
 	MkdirChown MkdirChowner
 }
 
-func (r RootFS) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) goci.Bndl {
+func (r RootFS) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
 	var uid, gid int
 	if !spec.Privileged {
 		uid = r.ContainerRootUID
 		gid = r.ContainerRootGID
 	}
 
+	if mappings := spec.IDMappings; !spec.Privileged && len(mappings.UIDMappings) > 0 {
+		uid = mappings.UIDMappings.Map(0)
+		gid = mappings.GIDMappings.Map(0)
+	}
+
 	r.MkdirChown.MkdirAs(
 		spec.RootFSPath, uid, gid, 0755, true,
 		".pivot_root",
@@ -38,7 +43,7 @@ func (r RootFS) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) goci.B
 		"tmp",
 	)
 
-	return bndl.WithRootFS(spec.RootFSPath)
+	return bndl.WithRootFS(spec.RootFSPath), nil
 }
 
 type ChrootMkdir struct {
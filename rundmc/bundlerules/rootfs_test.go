@@ -11,6 +11,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"code.cloudfoundry.org/garden-shed/rootfs_provider"
 	"code.cloudfoundry.org/guardian/gardener"
 	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
 	"code.cloudfoundry.org/guardian/rundmc/goci"
@@ -24,10 +25,12 @@ var _ = Describe("RootFS", func() {
 		rootfsPath     string
 		returnedBundle goci.Bndl
 		privileged     bool
+		idMappings     gardener.IDMappings
 	)
 
 	BeforeEach(func() {
 		privileged = false
+		idMappings = gardener.IDMappings{}
 	})
 
 	JustBeforeEach(func() {
@@ -54,10 +57,13 @@ var _ = Describe("RootFS", func() {
 			},
 		}
 
-		returnedBundle = rule.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
+		var err error
+		returnedBundle, err = rule.Apply(goci.Bundle(), gardener.DesiredContainerSpec{
 			RootFSPath: rootfsPath,
 			Privileged: privileged,
+			IDMappings: idMappings,
 		})
+		Expect(err).NotTo(HaveOccurred())
 	})
 
 	AfterEach(func() {
@@ -163,6 +169,33 @@ var _ = Describe("RootFS", func() {
 						},
 					}))
 			})
+
+			Context("when the container has its own range of host IDs", func() {
+				BeforeEach(func() {
+					idMappings = gardener.IDMappings{
+						UIDMappings: rootfs_provider.MappingList{{ContainerID: 0, HostID: 100000, Size: 65536}},
+						GIDMappings: rootfs_provider.MappingList{{ContainerID: 0, HostID: 200000, Size: 65536}},
+					}
+				})
+
+				It("creates the directories as the container's root user", func() {
+					Expect(commandRunner).To(HaveExecutedSerially(
+						fake_command_runner.CommandSpec{
+							Path: "reexeced-thing",
+							Args: []string{
+								"-rootfsPath", rootfsPath,
+								"-uid", "100000",
+								"-gid", "200000",
+								"-recreate", "true",
+								"-perm", "755",
+								".pivot_root",
+								"dev",
+								"proc",
+								"sys",
+							},
+						}))
+				})
+			})
 		})
 	})
 })
@@ -0,0 +1,208 @@
+package bundlerules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"runtime"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	"code.cloudfoundry.org/guardian/rundmc/seccomp"
+)
+
+// SecurityProfile adjusts the security settings of the containers which
+// select it with the garden.security-profile property
+type SecurityProfile struct {
+	AddCaps  []string `json:"add_caps"`
+	DropCaps []string `json:"drop_caps"`
+
+	// SeccompProfilePath is the path to a seccomp profile in Docker's format,
+	// relative to the security profiles file. It is loaded into Seccomp.
+	SeccompProfilePath string           `json:"seccomp_profile"`
+	Seccomp            *seccomp.Profile `json:"-"`
+
+	// SeccompAudit logs, rather than denies, the syscalls which the
+	// container's seccomp profile would deny, so that they can be discovered
+	SeccompAudit bool `json:"seccomp_audit"`
+
+	ApparmorProfile string `json:"apparmor_profile"`
+}
+
+// relaxesConfinement returns true unless the profile only drops
+// capabilities. A replacement seccomp or AppArmor profile may be looser than
+// the default, and auditing seccomp denies no syscalls at all.
+func (p SecurityProfile) relaxesConfinement() bool {
+	return len(p.AddCaps) > 0 || p.Seccomp != nil || p.SeccompProfilePath != "" || p.SeccompAudit || p.ApparmorProfile != ""
+}
+
+// SecurityProfileGrant allows the containers whose handles match a glob, e.g.
+// "build-*", to select the named profiles. Garden does not authenticate its
+// clients, and clients choose the handles of their containers, so a grant
+// only keeps profiles to the containers they are meant for and is not access
+// control. For this reason a profile which may relax a container's
+// confinement may only be used by privileged containers, whose clients are
+// already trusted with more.
+type SecurityProfileGrant struct {
+	Handles  string   `json:"handles"`
+	Profiles []string `json:"profiles"`
+}
+
+// SecurityProfiles applies the security profile selected by a container, if
+// it is allowed to select it
+type SecurityProfiles struct {
+	Profiles map[string]SecurityProfile `json:"profiles"`
+	Allow    []SecurityProfileGrant     `json:"allow"`
+}
+
+// LoadSecurityProfiles reads security profiles and the grants allowing
+// containers to use them from a JSON file, e.g.
+// {"profiles": {"debug": {"add_caps": ["CAP_SYS_PTRACE"]}}, "allow": [{"handles": "debug-*", "profiles": ["debug"]}]}
+func LoadSecurityProfiles(profilesPath string) (SecurityProfiles, error) {
+	contents, err := ioutil.ReadFile(profilesPath)
+	if err != nil {
+		return SecurityProfiles{}, err
+	}
+
+	var profiles SecurityProfiles
+	if err := json.Unmarshal(contents, &profiles); err != nil {
+		return SecurityProfiles{}, fmt.Errorf("parsing security profiles %s: %s", profilesPath, err)
+	}
+
+	for name, profile := range profiles.Profiles {
+		if profile.SeccompProfilePath == "" {
+			continue
+		}
+
+		seccompPath := profile.SeccompProfilePath
+		if !filepath.IsAbs(seccompPath) {
+			seccompPath = filepath.Join(filepath.Dir(profilesPath), seccompPath)
+		}
+
+		seccompProfile, err := seccomp.Load(seccompPath)
+		if err != nil {
+			return SecurityProfiles{}, fmt.Errorf("loading security profile %s: %s", name, err)
+		}
+
+		profile.Seccomp = &seccompProfile
+		profiles.Profiles[name] = profile
+	}
+
+	if err := profiles.Validate(); err != nil {
+		return SecurityProfiles{}, fmt.Errorf("parsing security profiles %s: %s", profilesPath, err)
+	}
+
+	return profiles, nil
+}
+
+// Validate returns an error if a grant has an invalid handle glob or names a
+// profile which does not exist
+func (r SecurityProfiles) Validate() error {
+	for _, grant := range r.Allow {
+		if _, err := path.Match(grant.Handles, ""); err != nil {
+			return fmt.Errorf("invalid handle pattern: %s", grant.Handles)
+		}
+
+		for _, name := range grant.Profiles {
+			if _, ok := r.Profiles[name]; !ok {
+				return fmt.Errorf("unknown security profile: %s", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r SecurityProfiles) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+	name := spec.SecurityProfile
+	if name == "" {
+		return bndl, nil
+	}
+
+	profile, ok := r.Profiles[name]
+	if !ok {
+		return goci.Bndl{}, fmt.Errorf("unknown security profile: %s", name)
+	}
+
+	if !r.allowed(spec.Handle, name) {
+		return goci.Bndl{}, fmt.Errorf("container %s is not allowed to use security profile %s", spec.Handle, name)
+	}
+
+	if profile.relaxesConfinement() && !spec.Privileged {
+		return goci.Bndl{}, fmt.Errorf("security profile %s may relax confinement, so only privileged containers may use it", name)
+	}
+
+	caps := adjustCaps(bndl.Capabilities(), profile.AddCaps, profile.DropCaps)
+	bndl = bndl.WithCapabilities(caps...)
+
+	if profile.Seccomp != nil {
+		seccompSpec, err := profile.Seccomp.Spec(runtime.GOARCH, caps)
+		if err != nil {
+			return goci.Bndl{}, err
+		}
+
+		bndl = bndl.WithSeccomp(seccompSpec)
+	}
+
+	if profile.SeccompAudit && bndl.Seccomp() != nil {
+		bndl = bndl.WithSeccomp(seccomp.Audit(bndl.Seccomp()))
+	}
+
+	if profile.ApparmorProfile != "" {
+		bndl = bndl.WithApparmorProfile(profile.ApparmorProfile)
+	}
+
+	return bndl, nil
+}
+
+// AuditsSeccomp returns true if any profile puts seccomp in audit mode
+func (r SecurityProfiles) AuditsSeccomp() bool {
+	for _, profile := range r.Profiles {
+		if profile.SeccompAudit {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r SecurityProfiles) allowed(handle, name string) bool {
+	for _, grant := range r.Allow {
+		if matched, _ := path.Match(grant.Handles, handle); !matched {
+			continue
+		}
+
+		for _, allowed := range grant.Profiles {
+			if allowed == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// adjustCaps returns a new slice, so that the base bundle's capabilities are
+// not modified in place
+func adjustCaps(caps, add, drop []string) []string {
+	adjusted := []string{}
+	for _, c := range append(append([]string{}, caps...), add...) {
+		if !containsString(drop, c) && !containsString(adjusted, c) {
+			adjusted = append(adjusted, c)
+		}
+	}
+
+	return adjusted
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
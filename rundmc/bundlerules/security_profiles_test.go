@@ -0,0 +1,312 @@
+package bundlerules_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	"code.cloudfoundry.org/guardian/rundmc/seccomp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var _ = Describe("SecurityProfiles", func() {
+	var (
+		rule bundlerules.SecurityProfiles
+		bndl goci.Bndl
+		spec gardener.DesiredContainerSpec
+	)
+
+	BeforeEach(func() {
+		rule = bundlerules.SecurityProfiles{
+			Profiles: map[string]bundlerules.SecurityProfile{
+				"debug": {
+					AddCaps:         []string{"CAP_SYS_PTRACE"},
+					DropCaps:        []string{"CAP_NET_RAW"},
+					ApparmorProfile: "garden-debug",
+					Seccomp: &seccomp.Profile{
+						DefaultAction: specs.ActErrno,
+						Syscalls: []seccomp.Syscall{
+							{Names: []string{"ptrace"}, Action: specs.ActAllow, Includes: seccomp.Filter{Caps: []string{"CAP_SYS_PTRACE"}}},
+						},
+					},
+				},
+				"minimal": {
+					DropCaps: []string{"CAP_CHOWN", "CAP_NET_RAW"},
+				},
+				"audit": {
+					SeccompAudit: true,
+				},
+			},
+			Allow: []bundlerules.SecurityProfileGrant{
+				{Handles: "debug-*", Profiles: []string{"debug", "minimal", "audit"}},
+				{Handles: "*", Profiles: []string{"minimal"}},
+			},
+		}
+
+		bndl = goci.Bundle().
+			WithCapabilities("CAP_CHOWN", "CAP_NET_RAW", "CAP_KILL").
+			WithApparmorProfile("garden-default").
+			WithSeccomp(&specs.LinuxSeccomp{DefaultAction: specs.ActErrno})
+
+		spec = gardener.DesiredContainerSpec{Handle: "debug-banana", SecurityProfile: "debug", Privileged: true}
+	})
+
+	Context("when the container does not select a profile", func() {
+		It("returns the bundle unchanged", func() {
+			spec.SecurityProfile = ""
+
+			newBndl, err := rule.Apply(bndl, spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newBndl).To(Equal(bndl))
+		})
+	})
+
+	It("adds and drops capabilities", func() {
+		newBndl, err := rule.Apply(bndl, spec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newBndl.Capabilities()).To(Equal([]string{"CAP_CHOWN", "CAP_KILL", "CAP_SYS_PTRACE"}))
+	})
+
+	It("does not modify the capabilities of the original bundle", func() {
+		_, err := rule.Apply(bndl, spec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bndl.Capabilities()).To(Equal([]string{"CAP_CHOWN", "CAP_NET_RAW", "CAP_KILL"}))
+	})
+
+	It("sets the AppArmor profile", func() {
+		newBndl, err := rule.Apply(bndl, spec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newBndl.ApparmorProfile()).To(Equal("garden-debug"))
+	})
+
+	It("sets the seccomp profile, resolved for the container's capabilities", func() {
+		newBndl, err := rule.Apply(bndl, spec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newBndl.Seccomp().Syscalls).To(Equal([]specs.LinuxSyscall{
+			{Name: "ptrace", Action: specs.ActAllow, Args: []specs.LinuxSeccompArg{}},
+		}))
+	})
+
+	Context("when the profile does not set seccomp or AppArmor profiles", func() {
+		BeforeEach(func() {
+			spec.SecurityProfile = "minimal"
+		})
+
+		It("keeps those of the bundle", func() {
+			newBndl, err := rule.Apply(bndl, spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newBndl.Capabilities()).To(Equal([]string{"CAP_KILL"}))
+			Expect(newBndl.ApparmorProfile()).To(Equal("garden-default"))
+			Expect(newBndl.Seccomp()).To(Equal(bndl.Seccomp()))
+		})
+	})
+
+	Context("when the profile audits seccomp", func() {
+		BeforeEach(func() {
+			spec.SecurityProfile = "audit"
+		})
+
+		It("logs the syscalls which the bundle's seccomp profile would deny", func() {
+			newBndl, err := rule.Apply(bndl, spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newBndl.Seccomp().DefaultAction).To(Equal(seccomp.ActLog))
+		})
+
+		It("does not modify the seccomp profile of the original bundle", func() {
+			_, err := rule.Apply(bndl, spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bndl.Seccomp().DefaultAction).To(Equal(specs.ActErrno))
+		})
+
+		Context("when the bundle has no seccomp profile", func() {
+			It("leaves it without one", func() {
+				newBndl, err := rule.Apply(goci.Bundle(), spec)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(newBndl.Seccomp()).To(BeNil())
+			})
+		})
+	})
+
+	Describe("AuditsSeccomp", func() {
+		It("returns true when a profile audits seccomp", func() {
+			Expect(rule.AuditsSeccomp()).To(BeTrue())
+		})
+
+		It("returns false when no profile audits seccomp", func() {
+			delete(rule.Profiles, "audit")
+			Expect(rule.AuditsSeccomp()).To(BeFalse())
+		})
+	})
+
+	Context("when the profile does not exist", func() {
+		It("returns an error", func() {
+			spec.SecurityProfile = "banana"
+
+			_, err := rule.Apply(bndl, spec)
+			Expect(err).To(MatchError("unknown security profile: banana"))
+		})
+	})
+
+	Context("when the container's handle is not allowed the profile", func() {
+		It("returns an error", func() {
+			spec.Handle = "build-banana"
+
+			_, err := rule.Apply(bndl, spec)
+			Expect(err).To(MatchError("container build-banana is not allowed to use security profile debug"))
+		})
+
+		It("allows profiles granted to the handle", func() {
+			spec.Handle = "build-banana"
+			spec.SecurityProfile = "minimal"
+
+			_, err := rule.Apply(bndl, spec)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("when the container is unprivileged", func() {
+		BeforeEach(func() {
+			spec.Privileged = false
+		})
+
+		It("does not allow a profile which adds capabilities, since handles do not authenticate clients", func() {
+			rule.Profiles["debug"] = bundlerules.SecurityProfile{AddCaps: []string{"CAP_SYS_PTRACE"}}
+
+			_, err := rule.Apply(bndl, spec)
+			Expect(err).To(MatchError("security profile debug may relax confinement, so only privileged containers may use it"))
+		})
+
+		It("does not allow a profile which replaces the seccomp profile", func() {
+			rule.Profiles["debug"] = bundlerules.SecurityProfile{Seccomp: &seccomp.Profile{DefaultAction: specs.ActAllow}}
+
+			_, err := rule.Apply(bndl, spec)
+			Expect(err).To(MatchError("security profile debug may relax confinement, so only privileged containers may use it"))
+		})
+
+		It("does not allow a profile which audits seccomp", func() {
+			spec.SecurityProfile = "audit"
+
+			_, err := rule.Apply(bndl, spec)
+			Expect(err).To(MatchError("security profile audit may relax confinement, so only privileged containers may use it"))
+		})
+
+		It("does not allow a profile which replaces the AppArmor profile", func() {
+			rule.Profiles["debug"] = bundlerules.SecurityProfile{ApparmorProfile: "unconfined"}
+
+			_, err := rule.Apply(bndl, spec)
+			Expect(err).To(MatchError("security profile debug may relax confinement, so only privileged containers may use it"))
+		})
+
+		It("allows profiles which only drop capabilities", func() {
+			spec.SecurityProfile = "minimal"
+
+			_, err := rule.Apply(bndl, spec)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("Validate", func() {
+		It("accepts valid grants", func() {
+			Expect(rule.Validate()).To(Succeed())
+		})
+
+		Context("when a grant names an unknown profile", func() {
+			It("returns an error", func() {
+				rule.Allow = append(rule.Allow, bundlerules.SecurityProfileGrant{Handles: "*", Profiles: []string{"banana"}})
+				Expect(rule.Validate()).To(MatchError("unknown security profile: banana"))
+			})
+		})
+
+		Context("when a grant's handle pattern is invalid", func() {
+			It("returns an error", func() {
+				rule.Allow = append(rule.Allow, bundlerules.SecurityProfileGrant{Handles: "[", Profiles: []string{"debug"}})
+				Expect(rule.Validate()).To(MatchError("invalid handle pattern: ["))
+			})
+		})
+	})
+
+	Describe("LoadSecurityProfiles", func() {
+		var (
+			tmpDir       string
+			profilesPath string
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "security-profiles")
+			Expect(err).NotTo(HaveOccurred())
+
+			profilesPath = filepath.Join(tmpDir, "profiles.json")
+			Expect(ioutil.WriteFile(filepath.Join(tmpDir, "debug-seccomp.json"), []byte(`{
+				"defaultAction": "SCMP_ACT_ERRNO",
+				"syscalls": [{"names": ["ptrace"], "action": "SCMP_ACT_ALLOW"}]
+			}`), 0600)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(tmpDir)).To(Succeed())
+		})
+
+		It("loads the profiles, grants and seccomp profiles relative to the file", func() {
+			Expect(ioutil.WriteFile(profilesPath, []byte(`{
+				"profiles": {
+					"debug": {
+						"add_caps": ["CAP_SYS_PTRACE"],
+						"drop_caps": ["CAP_NET_RAW"],
+						"seccomp_profile": "debug-seccomp.json",
+						"apparmor_profile": "garden-debug"
+					}
+				},
+				"allow": [{"handles": "debug-*", "profiles": ["debug"]}]
+			}`), 0600)).To(Succeed())
+
+			profiles, err := bundlerules.LoadSecurityProfiles(profilesPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(profiles.Allow).To(Equal([]bundlerules.SecurityProfileGrant{
+				{Handles: "debug-*", Profiles: []string{"debug"}},
+			}))
+
+			debug := profiles.Profiles["debug"]
+			Expect(debug.AddCaps).To(Equal([]string{"CAP_SYS_PTRACE"}))
+			Expect(debug.DropCaps).To(Equal([]string{"CAP_NET_RAW"}))
+			Expect(debug.ApparmorProfile).To(Equal("garden-debug"))
+			Expect(debug.Seccomp).To(Equal(&seccomp.Profile{
+				DefaultAction: specs.ActErrno,
+				Syscalls:      []seccomp.Syscall{{Names: []string{"ptrace"}, Action: specs.ActAllow}},
+			}))
+		})
+
+		Context("when a seccomp profile is invalid", func() {
+			It("returns an error", func() {
+				Expect(ioutil.WriteFile(profilesPath, []byte(`{"profiles": {"debug": {"seccomp_profile": "missing.json"}}}`), 0600)).To(Succeed())
+
+				_, err := bundlerules.LoadSecurityProfiles(profilesPath)
+				Expect(err).To(MatchError(ContainSubstring("loading security profile debug")))
+			})
+		})
+
+		Context("when the file is not valid JSON", func() {
+			It("returns an error", func() {
+				Expect(ioutil.WriteFile(profilesPath, []byte("{"), 0600)).To(Succeed())
+
+				_, err := bundlerules.LoadSecurityProfiles(profilesPath)
+				Expect(err).To(MatchError(ContainSubstring("parsing security profiles " + profilesPath)))
+			})
+		})
+
+		Context("when a grant is invalid", func() {
+			It("returns an error", func() {
+				Expect(ioutil.WriteFile(profilesPath, []byte(`{"allow": [{"handles": "*", "profiles": ["banana"]}]}`), 0600)).To(Succeed())
+
+				_, err := bundlerules.LoadSecurityProfiles(profilesPath)
+				Expect(err).To(MatchError("parsing security profiles " + profilesPath + ": unknown security profile: banana"))
+			})
+		})
+	})
+})
@@ -0,0 +1,64 @@
+package bundlerules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+)
+
+// UnprivilegedSysctlPrefixes are the prefixes of the sysctls which
+// unprivileged containers may set. They are namespaced by the container's
+// network or IPC namespace, so do not affect the host or other containers.
+var UnprivilegedSysctlPrefixes = []string{
+	"net.",
+	"kernel.shm",
+	"kernel.msg",
+	"fs.mqueue.",
+}
+
+// Sysctls sets the namespaced sysctls requested by a container with
+// garden.sysctl.* properties
+type Sysctls struct {
+}
+
+func (s Sysctls) Apply(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+	if len(spec.Sysctls) == 0 {
+		return bndl, nil
+	}
+
+	names := []string{}
+	for name := range spec.Sysctls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !spec.Privileged && !hasAnyPrefix(name, UnprivilegedSysctlPrefixes) {
+			return goci.Bndl{}, fmt.Errorf("sysctl %s is not allowed in unprivileged containers", name)
+		}
+
+		// neither the host's network namespace nor that of the container
+		// whose network is shared is the container's own to tune
+		if spec.HostNetwork && strings.HasPrefix(name, "net.") {
+			return goci.Bndl{}, fmt.Errorf("sysctl %s is not allowed in containers using the host network", name)
+		}
+		if spec.NetworkNamespacePath != "" && strings.HasPrefix(name, "net.") {
+			return goci.Bndl{}, fmt.Errorf("sysctl %s is not allowed in containers sharing another's network", name)
+		}
+	}
+
+	return bndl.WithSysctls(spec.Sysctls), nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
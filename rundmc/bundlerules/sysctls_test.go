@@ -0,0 +1,109 @@
+package bundlerules_test
+
+import (
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/bundlerules"
+	"code.cloudfoundry.org/guardian/rundmc/goci"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sysctls", func() {
+	var (
+		bndl goci.Bndl
+		spec gardener.DesiredContainerSpec
+	)
+
+	BeforeEach(func() {
+		bndl = goci.Bundle().WithSysctls(map[string]string{"net.ipv4.ip_forward": "0"})
+		spec = gardener.DesiredContainerSpec{
+			Handle: "some-handle",
+			Sysctls: map[string]string{
+				"net.core.somaxconn":           "1024",
+				"net.ipv4.ip_local_port_range": "1024 65000",
+			},
+		}
+	})
+
+	It("adds the requested sysctls to the bundle", func() {
+		newBndl, err := bundlerules.Sysctls{}.Apply(bndl, spec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newBndl.Sysctls()).To(Equal(map[string]string{
+			"net.ipv4.ip_forward":          "0",
+			"net.core.somaxconn":           "1024",
+			"net.ipv4.ip_local_port_range": "1024 65000",
+		}))
+	})
+
+	Context("when no sysctls are requested", func() {
+		It("returns the bundle unchanged", func() {
+			spec.Sysctls = nil
+
+			newBndl, err := bundlerules.Sysctls{}.Apply(bndl, spec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newBndl).To(Equal(bndl))
+		})
+	})
+
+	table.DescribeTable("sysctls allowed in unprivileged containers",
+		func(name string) {
+			spec.Sysctls = map[string]string{name: "1"}
+
+			_, err := bundlerules.Sysctls{}.Apply(bndl, spec)
+			Expect(err).NotTo(HaveOccurred())
+		},
+		table.Entry("network", "net.ipv4.tcp_keepalive_time"),
+		table.Entry("shared memory", "kernel.shmmax"),
+		table.Entry("message queues", "kernel.msgmax"),
+		table.Entry("POSIX message queues", "fs.mqueue.msg_max"),
+	)
+
+	Context("when an unprivileged container requests a sysctl which is not allowed", func() {
+		BeforeEach(func() {
+			spec.Sysctls["vm.swappiness"] = "0"
+		})
+
+		It("returns an error", func() {
+			_, err := bundlerules.Sysctls{}.Apply(bndl, spec)
+			Expect(err).To(MatchError("sysctl vm.swappiness is not allowed in unprivileged containers"))
+		})
+
+		Context("when the container is privileged", func() {
+			It("allows it", func() {
+				spec.Privileged = true
+
+				newBndl, err := bundlerules.Sysctls{}.Apply(bndl, spec)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(newBndl.Sysctls()).To(HaveKeyWithValue("vm.swappiness", "0"))
+			})
+		})
+	})
+
+	Context("when the container uses the host network", func() {
+		It("returns an error for network sysctls", func() {
+			spec.Privileged = true
+			spec.HostNetwork = true
+
+			_, err := bundlerules.Sysctls{}.Apply(bndl, spec)
+			Expect(err).To(MatchError("sysctl net.core.somaxconn is not allowed in containers using the host network"))
+		})
+	})
+
+	Context("when the container shares another container's network", func() {
+		It("returns an error for network sysctls", func() {
+			spec.NetworkNamespacePath = "/proc/42/ns/net"
+
+			_, err := bundlerules.Sysctls{}.Apply(bndl, spec)
+			Expect(err).To(MatchError("sysctl net.core.somaxconn is not allowed in containers sharing another's network"))
+		})
+
+		It("allows other sysctls", func() {
+			spec.NetworkNamespacePath = "/proc/42/ns/net"
+			spec.Sysctls = map[string]string{"kernel.shmmax": "1024"}
+
+			_, err := bundlerules.Sysctls{}.Apply(bndl, spec)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -31,7 +31,7 @@ type Depot interface {
 }
 
 type BundleGenerator interface {
-	Generate(spec gardener.DesiredContainerSpec) goci.Bndl
+	Generate(spec gardener.DesiredContainerSpec) (goci.Bndl, error)
 }
 
 type BundleLoader interface {
@@ -47,6 +47,7 @@ type OCIRuntime interface {
 	State(log lager.Logger, id string) (runrunc.State, error)
 	Stats(log lager.Logger, id string) (gardener.ActualContainerMetrics, error)
 	WatchEvents(log lager.Logger, id string, eventsNotifier runrunc.EventsNotifier) error
+	UpdateResources(log lager.Logger, id string, resources specs.LinuxResources) error
 }
 
 type NstarRunner interface {
@@ -78,9 +79,13 @@ type Containerizer struct {
 	nstar   NstarRunner
 	events  EventStore
 	states  StateStore
+
+	// blockIO applies block I/O limits to a bundle, both on creation and
+	// when the limits of a running container are updated
+	blockIO BundlerRule
 }
 
-func New(depot Depot, bundler BundleGenerator, runtime OCIRuntime, loader BundleLoader, nstarRunner NstarRunner, stopper Stopper, events EventStore, states StateStore) *Containerizer {
+func New(depot Depot, bundler BundleGenerator, runtime OCIRuntime, loader BundleLoader, nstarRunner NstarRunner, stopper Stopper, events EventStore, states StateStore, blockIO BundlerRule) *Containerizer {
 	return &Containerizer{
 		depot:   depot,
 		bundler: bundler,
@@ -90,6 +95,7 @@ func New(depot Depot, bundler BundleGenerator, runtime OCIRuntime, loader Bundle
 		stopper: stopper,
 		events:  events,
 		states:  states,
+		blockIO: blockIO,
 	}
 }
 
@@ -100,7 +106,13 @@ func (c *Containerizer) Create(log lager.Logger, spec gardener.DesiredContainerS
 	log.Info("start")
 	defer log.Info("finished")
 
-	if err := c.depot.Create(log, spec.Handle, c.bundler.Generate(spec)); err != nil {
+	bundle, err := c.bundler.Generate(spec)
+	if err != nil {
+		log.Error("generate-bundle-failed", err)
+		return err
+	}
+
+	if err := c.depot.Create(log, spec.Handle, bundle); err != nil {
 		log.Error("depot-create-failed", err)
 		return err
 	}
@@ -292,9 +304,148 @@ func (c *Containerizer) Info(log lager.Logger, handle string) (gardener.ActualCo
 			},
 		},
 		Privileged: privileged,
+		BlockIO:    blockIOLimits(bundle.BlockIO()),
+		CPUSet: gardener.CPUSet{
+			CPUs: bundle.Resources().CPU.Cpus,
+			Mems: bundle.Resources().CPU.Mems,
+		},
+		Memory: memoryOptions(bundle.Resources()),
+		IDMappings: gardener.IDMappings{
+			UIDMappings: bundle.UIDMappings(),
+			GIDMappings: bundle.GIDMappings(),
+		},
 	}, nil
 }
 
+// UpdateBlockIO applies new block I/O limits to a running container, and
+// saves them in its bundle once they have taken effect
+func (c *Containerizer) UpdateBlockIO(log lager.Logger, handle string, limits gardener.BlockIOLimits) error {
+	log = log.Session("update-block-io", lager.Data{"handle": handle})
+
+	log.Info("start")
+	defer log.Info("finished")
+
+	bundlePath, err := c.depot.Lookup(log, handle)
+	if err != nil {
+		log.Error("lookup-failed", err)
+		return err
+	}
+
+	bundle, err := c.loader.Load(bundlePath)
+	if err != nil {
+		log.Error("load-bundle-failed", err)
+		return err
+	}
+
+	previous := bundle.BlockIO()
+	bundle, err = c.blockIO.Apply(bundle, gardener.DesiredContainerSpec{Handle: handle, BlockIO: limits})
+	if err != nil {
+		log.Error("apply-limits-failed", err)
+		return err
+	}
+
+	if err := c.runtime.UpdateResources(log, handle, specs.LinuxResources{BlockIO: blockIOUpdate(previous, bundle.BlockIO())}); err != nil {
+		log.Error("update-resources-failed", err)
+		return err
+	}
+
+	return bundle.Save(bundlePath)
+}
+
+// blockIOUpdate returns the block I/O resources which change a container's
+// from previous to current, giving devices which are no longer throttled a
+// rate of 0 to remove their throttles
+func blockIOUpdate(previous, current *specs.LinuxBlockIO) *specs.LinuxBlockIO {
+	if previous == nil {
+		return current
+	}
+
+	var update specs.LinuxBlockIO
+	if current != nil {
+		update = *current
+	}
+
+	update.ThrottleReadBpsDevice = withRemovedThrottles(previous.ThrottleReadBpsDevice, update.ThrottleReadBpsDevice)
+	update.ThrottleWriteBpsDevice = withRemovedThrottles(previous.ThrottleWriteBpsDevice, update.ThrottleWriteBpsDevice)
+	update.ThrottleReadIOPSDevice = withRemovedThrottles(previous.ThrottleReadIOPSDevice, update.ThrottleReadIOPSDevice)
+	update.ThrottleWriteIOPSDevice = withRemovedThrottles(previous.ThrottleWriteIOPSDevice, update.ThrottleWriteIOPSDevice)
+
+	return &update
+}
+
+func withRemovedThrottles(previous, current []specs.LinuxThrottleDevice) []specs.LinuxThrottleDevice {
+	var throttles []specs.LinuxThrottleDevice
+	throttles = append(throttles, current...)
+
+	for _, device := range previous {
+		removed := true
+		for _, other := range current {
+			if other.Major == device.Major && other.Minor == device.Minor {
+				removed = false
+				break
+			}
+		}
+
+		if removed {
+			device.Rate = 0
+			throttles = append(throttles, device)
+		}
+	}
+
+	return throttles
+}
+
+func memoryOptions(resources *specs.LinuxResources) gardener.MemoryOptions {
+	options := gardener.MemoryOptions{
+		Swappiness:  resources.Memory.Swappiness,
+		OOMScoreAdj: resources.OOMScoreAdj,
+	}
+
+	memory := resources.Memory
+	if memory.Reservation != nil {
+		options.Reservation = *memory.Reservation
+	}
+	if memory.Kernel != nil {
+		options.Kernel = *memory.Kernel
+	}
+	if memory.Limit != nil && *memory.Limit > 0 && memory.Swap != nil && *memory.Swap >= *memory.Limit {
+		swap := *memory.Swap - *memory.Limit
+		options.Swap = &swap
+	}
+
+	return options
+}
+
+func blockIOLimits(blockIO *specs.LinuxBlockIO) gardener.BlockIOLimits {
+	if blockIO == nil {
+		return gardener.BlockIOLimits{}
+	}
+
+	var limits gardener.BlockIOLimits
+	if blockIO.Weight != nil {
+		limits.Weight = *blockIO.Weight
+	}
+
+	limits.ReadBps = deviceRates(blockIO.ThrottleReadBpsDevice)
+	limits.WriteBps = deviceRates(blockIO.ThrottleWriteBpsDevice)
+	limits.ReadIOPS = deviceRates(blockIO.ThrottleReadIOPSDevice)
+	limits.WriteIOPS = deviceRates(blockIO.ThrottleWriteIOPSDevice)
+
+	return limits
+}
+
+func deviceRates(devices []specs.LinuxThrottleDevice) []gardener.DeviceRate {
+	var rates []gardener.DeviceRate
+	for _, device := range devices {
+		rates = append(rates, gardener.DeviceRate{
+			Device: fmt.Sprintf("%d:%d", device.Major, device.Minor),
+			Rate:   device.Rate,
+		})
+	}
+
+	return rates
+}
+
 func (c *Containerizer) Metrics(log lager.Logger, handle string) (gardener.ActualContainerMetrics, error) {
 	return c.runtime.Stats(log, handle)
 }
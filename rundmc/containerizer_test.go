@@ -2,7 +2,9 @@ package rundmc_test
 
 import (
 	"errors"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"time"
 
 	"code.cloudfoundry.org/garden"
@@ -29,6 +31,7 @@ var _ = Describe("Rundmc", func() {
 		fakeStopper      *fakes.FakeStopper
 		fakeEventStore   *fakes.FakeEventStore
 		fakeStateStore   *fakes.FakeStateStore
+		fakeBlockIO      *fakes.FakeBundlerRule
 
 		logger        lager.Logger
 		containerizer *rundmc.Containerizer
@@ -43,20 +46,21 @@ var _ = Describe("Rundmc", func() {
 		fakeStopper = new(fakes.FakeStopper)
 		fakeEventStore = new(fakes.FakeEventStore)
 		fakeStateStore = new(fakes.FakeStateStore)
+		fakeBlockIO = new(fakes.FakeBundlerRule)
 		logger = lagertest.NewTestLogger("test")
 
 		fakeDepot.LookupStub = func(_ lager.Logger, handle string) (string, error) {
 			return "/path/to/" + handle, nil
 		}
 
-		containerizer = rundmc.New(fakeDepot, fakeBundler, fakeOCIRuntime, fakeBundleLoader, fakeNstarRunner, fakeStopper, fakeEventStore, fakeStateStore)
+		containerizer = rundmc.New(fakeDepot, fakeBundler, fakeOCIRuntime, fakeBundleLoader, fakeNstarRunner, fakeStopper, fakeEventStore, fakeStateStore, fakeBlockIO)
 	})
 
 	Describe("Create", func() {
 		It("should ask the depot to create a container", func() {
 			var returnedBundle goci.Bndl
-			fakeBundler.GenerateStub = func(spec gardener.DesiredContainerSpec) goci.Bndl {
-				return returnedBundle
+			fakeBundler.GenerateStub = func(spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+				return returnedBundle, nil
 			}
 
 			containerizer.Create(logger, gardener.DesiredContainerSpec{
@@ -70,6 +74,23 @@ var _ = Describe("Rundmc", func() {
 			Expect(bundle).To(Equal(returnedBundle))
 		})
 
+		Context("when generating the bundle fails", func() {
+			BeforeEach(func() {
+				fakeBundler.GenerateReturns(goci.Bndl{}, errors.New("banana"))
+			})
+
+			It("returns the error", func() {
+				Expect(containerizer.Create(logger, gardener.DesiredContainerSpec{
+					Handle: "exuberant!",
+				})).To(MatchError("banana"))
+			})
+
+			It("does not create the container in the depot", func() {
+				containerizer.Create(logger, gardener.DesiredContainerSpec{Handle: "exuberant!"})
+				Expect(fakeDepot.CreateCallCount()).To(Equal(0))
+			})
+		})
+
 		Context("when creating the depot directory fails", func() {
 			It("returns an error", func() {
 				fakeDepot.CreateReturns(errors.New("blam"))
@@ -407,10 +428,14 @@ var _ = Describe("Rundmc", func() {
 	})
 
 	Describe("Info", func() {
-		var namespaces []specs.LinuxNamespace = []specs.LinuxNamespace{}
+		var (
+			namespaces []specs.LinuxNamespace = []specs.LinuxNamespace{}
+			blockIO    *specs.LinuxBlockIO
+		)
 
 		BeforeEach(func() {
 			fakeOCIRuntime.StateReturns(runrunc.State{Pid: 42}, nil)
+			blockIO = nil
 		})
 
 		JustBeforeEach(func() {
@@ -421,17 +446,28 @@ var _ = Describe("Rundmc", func() {
 
 				var limit uint64 = 10
 				var shares uint64 = 20
+				var reservation, swap, swappiness uint64 = 5, 30, 60
+				oomScoreAdj := 100
 				return goci.Bndl{
 					Spec: specs.Spec{
 						Linux: &specs.Linux{
-							Namespaces: namespaces,
+							Namespaces:  namespaces,
+							UIDMappings: []specs.LinuxIDMapping{{ContainerID: 0, HostID: 100000, Size: 65536}},
+							GIDMappings: []specs.LinuxIDMapping{{ContainerID: 0, HostID: 200000, Size: 65536}},
 							Resources: &specs.LinuxResources{
 								Memory: &specs.LinuxMemory{
-									Limit: &limit,
+									Limit:       &limit,
+									Reservation: &reservation,
+									Swap:        &swap,
+									Swappiness:  &swappiness,
 								},
+								OOMScoreAdj: &oomScoreAdj,
 								CPU: &specs.LinuxCPU{
 									Shares: &shares,
+									Cpus:   "2-3",
+									Mems:   "0",
 								},
+								BlockIO: blockIO,
 							},
 						},
 					},
@@ -451,12 +487,64 @@ var _ = Describe("Rundmc", func() {
 			Expect(actualSpec.Limits.CPU.LimitInShares).To(BeEquivalentTo(20))
 		})
 
+		It("should return the ActualContainerSpec with the memory options", func() {
+			actualSpec, err := containerizer.Info(logger, "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(actualSpec.Memory.Reservation).To(BeEquivalentTo(5))
+			Expect(*actualSpec.Memory.Swap).To(BeEquivalentTo(20))
+			Expect(*actualSpec.Memory.Swappiness).To(BeEquivalentTo(60))
+			Expect(actualSpec.Memory.Kernel).To(BeZero())
+			Expect(*actualSpec.Memory.OOMScoreAdj).To(Equal(100))
+		})
+
+		It("should return the ActualContainerSpec with the cpuset", func() {
+			actualSpec, err := containerizer.Info(logger, "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actualSpec.CPUSet).To(Equal(gardener.CPUSet{CPUs: "2-3", Mems: "0"}))
+		})
+
+		It("should return the ActualContainerSpec with the id mappings", func() {
+			actualSpec, err := containerizer.Info(logger, "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actualSpec.IDMappings.UIDMappings).To(ConsistOf(specs.LinuxIDMapping{ContainerID: 0, HostID: 100000, Size: 65536}))
+			Expect(actualSpec.IDMappings.GIDMappings).To(ConsistOf(specs.LinuxIDMapping{ContainerID: 0, HostID: 200000, Size: 65536}))
+		})
+
 		It("should return the ActualContainerSpec with the correct memory limits", func() {
 			actualSpec, err := containerizer.Info(logger, "some-handle")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(actualSpec.Limits.Memory.LimitInBytes).To(BeEquivalentTo(10))
 		})
 
+		It("should return the ActualContainerSpec without block I/O limits when none are set", func() {
+			actualSpec, err := containerizer.Info(logger, "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actualSpec.BlockIO).To(Equal(gardener.BlockIOLimits{}))
+		})
+
+		Context("when the bundle has block I/O limits", func() {
+			BeforeEach(func() {
+				var weight uint16 = 300
+				readBps := specs.LinuxThrottleDevice{Rate: 1024}
+				readBps.Major, readBps.Minor = 8, 16
+
+				blockIO = &specs.LinuxBlockIO{
+					Weight:                &weight,
+					ThrottleReadBpsDevice: []specs.LinuxThrottleDevice{readBps},
+				}
+			})
+
+			It("should return the ActualContainerSpec with the block I/O limits", func() {
+				actualSpec, err := containerizer.Info(logger, "some-handle")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(actualSpec.BlockIO).To(Equal(gardener.BlockIOLimits{
+					Weight:  300,
+					ReadBps: []gardener.DeviceRate{{Device: "8:16", Rate: 1024}},
+				}))
+			})
+		})
+
 		It("should return the ActualContainerSpec with the correct pid", func() {
 			actualSpec, err := containerizer.Info(logger, "some-handle")
 			Expect(err).NotTo(HaveOccurred())
@@ -531,6 +619,126 @@ var _ = Describe("Rundmc", func() {
 		})
 	})
 
+	Describe("UpdateBlockIO", func() {
+		var (
+			bundleDir string
+			weight    uint16 = 500
+			limits    gardener.BlockIOLimits
+		)
+
+		BeforeEach(func() {
+			var err error
+			bundleDir, err = ioutil.TempDir("", "bundle")
+			Expect(err).NotTo(HaveOccurred())
+
+			fakeDepot.LookupReturns(bundleDir, nil)
+			fakeBundleLoader.LoadReturns(goci.Bundle().WithHostname("some-hostname"), nil)
+			fakeBlockIO.ApplyStub = func(bndl goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
+				return bndl.WithBlockIO(specs.LinuxBlockIO{Weight: &spec.BlockIO.Weight}), nil
+			}
+
+			limits = gardener.BlockIOLimits{Weight: weight}
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(bundleDir)).To(Succeed())
+		})
+
+		It("applies the limits to the container's bundle", func() {
+			Expect(containerizer.UpdateBlockIO(logger, "some-handle", limits)).To(Succeed())
+
+			Expect(fakeBundleLoader.LoadArgsForCall(0)).To(Equal(bundleDir))
+			Expect(fakeBlockIO.ApplyCallCount()).To(Equal(1))
+			bndl, spec := fakeBlockIO.ApplyArgsForCall(0)
+			Expect(bndl.Hostname()).To(Equal("some-hostname"))
+			Expect(spec.Handle).To(Equal("some-handle"))
+			Expect(spec.BlockIO).To(Equal(limits))
+		})
+
+		It("updates the resources of the running container", func() {
+			Expect(containerizer.UpdateBlockIO(logger, "some-handle", limits)).To(Succeed())
+
+			Expect(fakeOCIRuntime.UpdateResourcesCallCount()).To(Equal(1))
+			_, id, resources := fakeOCIRuntime.UpdateResourcesArgsForCall(0)
+			Expect(id).To(Equal("some-handle"))
+			Expect(resources).To(Equal(specs.LinuxResources{BlockIO: &specs.LinuxBlockIO{Weight: &weight}}))
+		})
+
+		It("saves the updated bundle", func() {
+			Expect(containerizer.UpdateBlockIO(logger, "some-handle", limits)).To(Succeed())
+
+			saved, err := (&goci.BndlLoader{}).Load(bundleDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(saved.Hostname()).To(Equal("some-hostname"))
+			Expect(saved.BlockIO()).To(Equal(&specs.LinuxBlockIO{Weight: &weight}))
+		})
+
+		Context("when devices were throttled before", func() {
+			var throttle specs.LinuxThrottleDevice
+
+			BeforeEach(func() {
+				throttle = specs.LinuxThrottleDevice{Rate: 1048576}
+				throttle.Major, throttle.Minor = 8, 0
+
+				fakeBundleLoader.LoadReturns(goci.Bundle().WithBlockIO(specs.LinuxBlockIO{
+					ThrottleReadBpsDevice: []specs.LinuxThrottleDevice{throttle},
+				}), nil)
+			})
+
+			It("removes the throttles of devices which are no longer throttled", func() {
+				Expect(containerizer.UpdateBlockIO(logger, "some-handle", limits)).To(Succeed())
+
+				removed := throttle
+				removed.Rate = 0
+
+				_, _, resources := fakeOCIRuntime.UpdateResourcesArgsForCall(0)
+				Expect(resources.BlockIO.Weight).To(Equal(&weight))
+				Expect(resources.BlockIO.ThrottleReadBpsDevice).To(Equal([]specs.LinuxThrottleDevice{removed}))
+			})
+
+			It("does not save the removed throttles", func() {
+				Expect(containerizer.UpdateBlockIO(logger, "some-handle", limits)).To(Succeed())
+
+				saved, err := (&goci.BndlLoader{}).Load(bundleDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(saved.BlockIO()).To(Equal(&specs.LinuxBlockIO{Weight: &weight}))
+			})
+		})
+
+		Context("when applying the limits fails", func() {
+			It("returns the error without updating the container", func() {
+				fakeBlockIO.ApplyReturns(goci.Bndl{}, errors.New("banana"))
+
+				Expect(containerizer.UpdateBlockIO(logger, "some-handle", limits)).To(MatchError("banana"))
+				Expect(fakeOCIRuntime.UpdateResourcesCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when updating the container fails", func() {
+			It("returns the error without saving the bundle", func() {
+				fakeOCIRuntime.UpdateResourcesReturns(errors.New("banana"))
+
+				Expect(containerizer.UpdateBlockIO(logger, "some-handle", limits)).To(MatchError("banana"))
+				_, err := os.Stat(filepath.Join(bundleDir, "config.json"))
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+		})
+
+		Context("when looking up the bundle fails", func() {
+			It("returns the error", func() {
+				fakeDepot.LookupReturns("", errors.New("spiderman-error"))
+				Expect(containerizer.UpdateBlockIO(logger, "some-handle", limits)).To(MatchError("spiderman-error"))
+			})
+		})
+
+		Context("when loading the bundle fails", func() {
+			It("returns the error", func() {
+				fakeBundleLoader.LoadReturns(goci.Bndl{}, errors.New("batman-error"))
+				Expect(containerizer.UpdateBlockIO(logger, "some-handle", limits)).To(MatchError("batman-error"))
+			})
+		})
+	})
+
 	Describe("Metrics", func() {
 		It("returns the CPU metrics", func() {
 			metrics := gardener.ActualContainerMetrics{
@@ -0,0 +1,13 @@
+package cpuset_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestCpuset(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cpuset Suite")
+}
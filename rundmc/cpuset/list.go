@@ -0,0 +1,88 @@
+package cpuset
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Parse parses a list of CPUs or memory nodes in the kernel's list format,
+// e.g. "0-3,8", returning the sorted, distinct numbers in it
+func Parse(list string) ([]int, error) {
+	seen := map[int]bool{}
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		first, last := item, item
+		if sep := strings.Index(item, "-"); sep >= 0 {
+			first, last = item[:sep], item[sep+1:]
+		}
+
+		from, err := strconv.Atoi(first)
+		if err != nil || from < 0 {
+			return nil, fmt.Errorf("invalid cpu list: '%s'", list)
+		}
+
+		to, err := strconv.Atoi(last)
+		if err != nil || to < from {
+			return nil, fmt.Errorf("invalid cpu list: '%s'", list)
+		}
+
+		for n := from; n <= to; n++ {
+			seen[n] = true
+		}
+	}
+
+	var numbers []int
+	for n := range seen {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	return numbers, nil
+}
+
+// Format formats CPUs or memory nodes in the kernel's list format, collapsing
+// consecutive numbers into ranges
+func Format(numbers []int) string {
+	sorted := append([]int{}, numbers...)
+	sort.Ints(sorted)
+
+	var items []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] <= sorted[j]+1 {
+			j++
+		}
+
+		if sorted[i] == sorted[j] {
+			items = append(items, strconv.Itoa(sorted[i]))
+		} else {
+			items = append(items, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		}
+		i = j + 1
+	}
+
+	return strings.Join(items, ",")
+}
+
+// Subtract returns the numbers in a which are not in b
+func Subtract(a, b []int) []int {
+	exclude := map[int]bool{}
+	for _, n := range b {
+		exclude[n] = true
+	}
+
+	var remaining []int
+	for _, n := range a {
+		if !exclude[n] {
+			remaining = append(remaining, n)
+		}
+	}
+
+	return remaining
+}
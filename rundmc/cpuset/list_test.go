@@ -0,0 +1,52 @@
+package cpuset_test
+
+import (
+	"code.cloudfoundry.org/guardian/rundmc/cpuset"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	table.DescribeTable("parsing lists",
+		func(list string, expected []int) {
+			Expect(cpuset.Parse(list)).To(Equal(expected))
+		},
+		table.Entry("a single number", "3", []int{3}),
+		table.Entry("a range", "0-3", []int{0, 1, 2, 3}),
+		table.Entry("ranges and numbers", "8,0-2, 5", []int{0, 1, 2, 5, 8}),
+		table.Entry("overlapping ranges", "0-2,1-3", []int{0, 1, 2, 3}),
+		table.Entry("an empty list", "", []int(nil)),
+	)
+
+	table.DescribeTable("invalid lists",
+		func(list string) {
+			_, err := cpuset.Parse(list)
+			Expect(err).To(MatchError("invalid cpu list: '" + list + "'"))
+		},
+		table.Entry("a word", "all"),
+		table.Entry("a negative number", "-1"),
+		table.Entry("a backwards range", "3-1"),
+		table.Entry("an open range", "3-"),
+	)
+})
+
+var _ = Describe("Format", func() {
+	It("collapses consecutive numbers into ranges", func() {
+		Expect(cpuset.Format([]int{8, 0, 1, 2, 5, 6})).To(Equal("0-2,5-6,8"))
+	})
+
+	It("formats no numbers as an empty list", func() {
+		Expect(cpuset.Format(nil)).To(Equal(""))
+	})
+
+	It("round-trips with Parse", func() {
+		Expect(cpuset.Parse(cpuset.Format([]int{1, 3, 4, 5}))).To(Equal([]int{1, 3, 4, 5}))
+	})
+})
+
+var _ = Describe("Subtract", func() {
+	It("removes the numbers in the second list from the first", func() {
+		Expect(cpuset.Subtract([]int{0, 1, 2, 3}, []int{1, 3, 7})).To(Equal([]int{0, 2}))
+	})
+})
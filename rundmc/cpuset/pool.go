@@ -0,0 +1,184 @@
+package cpuset
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"code.cloudfoundry.org/guardian/gardener"
+)
+
+// Pool dedicates cores to containers, preferring to place all of a
+// container's cores on one NUMA node
+type Pool struct {
+	cpus     []int
+	topology Topology
+
+	mu          sync.Mutex
+	allocations map[string][]int
+}
+
+// NewPool returns a pool of the given CPUs, which are placed on NUMA nodes
+// according to the given topology
+func NewPool(cpus []int, topology Topology) *Pool {
+	return &Pool{
+		cpus:        cpus,
+		topology:    topology,
+		allocations: map[string][]int{},
+	}
+}
+
+func (p *Pool) Allocate(handle string, cores int) (gardener.CPUSet, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.allocations[handle]; ok {
+		return gardener.CPUSet{}, fmt.Errorf("cores are already dedicated to container %s", handle)
+	}
+
+	free := p.free()
+	total := 0
+	for _, cpus := range free {
+		total += len(cpus)
+	}
+
+	if total < cores {
+		return gardener.CPUSet{}, fmt.Errorf("not enough free cores: %d requested, %d free", cores, total)
+	}
+
+	// prefer the fullest node which can fit every core, leaving room on
+	// emptier nodes for larger requests, and otherwise spread the cores over
+	// as few nodes as possible
+	nodes := make([]int, 0, len(free))
+	for node := range free {
+		nodes = append(nodes, node)
+	}
+	sort.Sort(byFreeCores{nodes: nodes, free: free})
+
+	var allocated, mems []int
+	for _, node := range nodes {
+		if len(free[node]) >= cores {
+			allocated, mems = free[node][:cores], []int{node}
+			break
+		}
+	}
+
+	if allocated == nil {
+		for i := len(nodes) - 1; len(allocated) < cores; i-- {
+			node := nodes[i]
+			n := cores - len(allocated)
+			if n > len(free[node]) {
+				n = len(free[node])
+			}
+
+			allocated = append(allocated, free[node][:n]...)
+			mems = append(mems, node)
+		}
+	}
+
+	p.allocations[handle] = allocated
+
+	return gardener.CPUSet{CPUs: Format(allocated), Mems: Format(Subtract(mems, []int{-1}))}, nil
+}
+
+func (p *Pool) Reserve(handle string, cpus gardener.CPUSet) error {
+	requested, err := Parse(cpus.CPUs)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	owners := p.owners()
+	var reserved []int
+	for _, cpu := range requested {
+		if !p.contains(cpu) {
+			continue
+		}
+
+		if owner, ok := owners[cpu]; ok && owner != handle {
+			return fmt.Errorf("cpu %d is already dedicated to container %s", cpu, owner)
+		}
+
+		reserved = append(reserved, cpu)
+	}
+
+	p.allocations[handle] = reserved
+	return nil
+}
+
+func (p *Pool) Release(handle string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.allocations, handle)
+}
+
+func (p *Pool) Overlaps(cpus string) (bool, error) {
+	requested, err := Parse(cpus)
+	if err != nil {
+		return false, err
+	}
+
+	for _, cpu := range requested {
+		if p.contains(cpu) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// free returns the unallocated CPUs on each NUMA node, with -1 for CPUs on no
+// node
+func (p *Pool) free() map[int][]int {
+	owners := p.owners()
+
+	free := map[int][]int{}
+	for _, cpu := range p.cpus {
+		if _, ok := owners[cpu]; !ok {
+			node := p.topology.Node(cpu)
+			free[node] = append(free[node], cpu)
+		}
+	}
+
+	return free
+}
+
+func (p *Pool) owners() map[int]string {
+	owners := map[int]string{}
+	for handle, cpus := range p.allocations {
+		for _, cpu := range cpus {
+			owners[cpu] = handle
+		}
+	}
+
+	return owners
+}
+
+func (p *Pool) contains(cpu int) bool {
+	for _, c := range p.cpus {
+		if c == cpu {
+			return true
+		}
+	}
+
+	return false
+}
+
+type byFreeCores struct {
+	nodes []int
+	free  map[int][]int
+}
+
+func (s byFreeCores) Len() int      { return len(s.nodes) }
+func (s byFreeCores) Swap(i, j int) { s.nodes[i], s.nodes[j] = s.nodes[j], s.nodes[i] }
+func (s byFreeCores) Less(i, j int) bool {
+	fi, fj := len(s.free[s.nodes[i]]), len(s.free[s.nodes[j]])
+	if fi != fj {
+		return fi < fj
+	}
+
+	return s.nodes[i] < s.nodes[j]
+}
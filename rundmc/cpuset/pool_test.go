@@ -0,0 +1,118 @@
+package cpuset_test
+
+import (
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/cpuset"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pool", func() {
+	var pool *cpuset.Pool
+
+	BeforeEach(func() {
+		topology := cpuset.Topology{0: {0, 1, 2, 3}, 1: {4, 5, 6, 7}}
+		pool = cpuset.NewPool([]int{1, 2, 3, 4, 5, 6, 7}, topology)
+	})
+
+	Describe("Allocate", func() {
+		It("dedicates cores on the fullest node which fits them", func() {
+			Expect(pool.Allocate("a", 2)).To(Equal(gardener.CPUSet{CPUs: "1-2", Mems: "0"}))
+		})
+
+		It("moves on to another node when a node is full", func() {
+			Expect(pool.Allocate("a", 2)).To(Equal(gardener.CPUSet{CPUs: "1-2", Mems: "0"}))
+			Expect(pool.Allocate("b", 2)).To(Equal(gardener.CPUSet{CPUs: "4-5", Mems: "1"}))
+			Expect(pool.Allocate("c", 1)).To(Equal(gardener.CPUSet{CPUs: "3", Mems: "0"}))
+		})
+
+		It("spreads the cores over several nodes when no node fits them", func() {
+			Expect(pool.Allocate("a", 6)).To(Equal(gardener.CPUSet{CPUs: "1-2,4-7", Mems: "0-1"}))
+		})
+
+		Context("when there are not enough free cores", func() {
+			It("returns an error", func() {
+				_, err := pool.Allocate("a", 5)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = pool.Allocate("b", 3)
+				Expect(err).To(MatchError("not enough free cores: 3 requested, 2 free"))
+			})
+		})
+
+		Context("when the container already has dedicated cores", func() {
+			It("returns an error", func() {
+				_, err := pool.Allocate("a", 1)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = pool.Allocate("a", 1)
+				Expect(err).To(MatchError("cores are already dedicated to container a"))
+			})
+		})
+
+		Context("when CPUs are on no NUMA node", func() {
+			It("does not restrict the memory nodes", func() {
+				pool = cpuset.NewPool([]int{0, 1}, cpuset.Topology{})
+				Expect(pool.Allocate("a", 2)).To(Equal(gardener.CPUSet{CPUs: "0-1", Mems: ""}))
+			})
+		})
+	})
+
+	Describe("Release", func() {
+		It("makes the container's cores available again", func() {
+			_, err := pool.Allocate("a", 7)
+			Expect(err).NotTo(HaveOccurred())
+
+			pool.Release("a")
+			Expect(pool.Allocate("b", 7)).To(Equal(gardener.CPUSet{CPUs: "1-7", Mems: "0-1"}))
+		})
+
+		It("ignores containers without dedicated cores", func() {
+			pool.Release("a")
+		})
+	})
+
+	Describe("Reserve", func() {
+		It("stops the container's cores being dedicated to other containers", func() {
+			Expect(pool.Reserve("a", gardener.CPUSet{CPUs: "1-3"})).To(Succeed())
+			Expect(pool.Allocate("b", 2)).To(Equal(gardener.CPUSet{CPUs: "4-5", Mems: "1"}))
+		})
+
+		It("ignores CPUs outside the pool", func() {
+			Expect(pool.Reserve("a", gardener.CPUSet{CPUs: "0"})).To(Succeed())
+			Expect(pool.Allocate("b", 7)).To(Equal(gardener.CPUSet{CPUs: "1-7", Mems: "0-1"}))
+		})
+
+		Context("when a core is dedicated to another container", func() {
+			It("returns an error", func() {
+				Expect(pool.Reserve("a", gardener.CPUSet{CPUs: "1"})).To(Succeed())
+				Expect(pool.Reserve("b", gardener.CPUSet{CPUs: "1-2"})).To(MatchError("cpu 1 is already dedicated to container a"))
+			})
+		})
+
+		Context("when the CPU list is invalid", func() {
+			It("returns an error", func() {
+				Expect(pool.Reserve("a", gardener.CPUSet{CPUs: "banana"})).NotTo(Succeed())
+			})
+		})
+	})
+
+	Describe("Overlaps", func() {
+		It("reports whether a list includes any of the pool's CPUs", func() {
+			Expect(pool.Overlaps("0")).To(BeFalse())
+			Expect(pool.Overlaps("0-1")).To(BeTrue())
+		})
+
+		It("counts CPUs which are dedicated to containers", func() {
+			Expect(pool.Reserve("a", gardener.CPUSet{CPUs: "1-3"})).To(Succeed())
+			Expect(pool.Overlaps("2")).To(BeTrue())
+		})
+
+		Context("when the CPU list is invalid", func() {
+			It("returns an error", func() {
+				_, err := pool.Overlaps("banana")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
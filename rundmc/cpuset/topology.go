@@ -0,0 +1,86 @@
+package cpuset
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Topology maps NUMA nodes to the CPUs on them
+type Topology map[int][]int
+
+// LoadTopology reads the host's NUMA topology from sysfs, e.g. /sys. Hosts
+// without NUMA support are treated as having a single node 0 with all online
+// CPUs.
+func LoadTopology(sysRoot string) (Topology, error) {
+	nodeDirs, err := filepath.Glob(filepath.Join(sysRoot, "devices", "system", "node", "node*"))
+	if err != nil {
+		return nil, err
+	}
+
+	topology := Topology{}
+	for _, dir := range nodeDirs {
+		node, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "node"))
+		if err != nil {
+			continue
+		}
+
+		cpus, err := readList(filepath.Join(dir, "cpulist"))
+		if err != nil {
+			return nil, err
+		}
+
+		topology[node] = cpus
+	}
+
+	if len(topology) > 0 {
+		return topology, nil
+	}
+
+	cpus, err := OnlineCPUs(sysRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	return Topology{0: cpus}, nil
+}
+
+// OnlineCPUs returns the host's online CPUs, read from sysfs, e.g. /sys
+func OnlineCPUs(sysRoot string) ([]int, error) {
+	return readList(filepath.Join(sysRoot, "devices", "system", "cpu", "online"))
+}
+
+// Node returns the NUMA node of a CPU, or -1 if it is on none
+func (t Topology) Node(cpu int) int {
+	for node, cpus := range t {
+		for _, c := range cpus {
+			if c == cpu {
+				return node
+			}
+		}
+	}
+
+	return -1
+}
+
+// Nodes returns the NUMA nodes in ascending order
+func (t Topology) Nodes() []int {
+	var nodes []int
+	for node := range t {
+		nodes = append(nodes, node)
+	}
+	sort.Ints(nodes)
+
+	return nodes
+}
+
+func readList(path string) ([]int, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(strings.TrimSpace(string(contents)))
+}
@@ -0,0 +1,75 @@
+package cpuset_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/guardian/rundmc/cpuset"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Topology", func() {
+	var sysRoot string
+
+	writeFile := func(path, contents string) {
+		path = filepath.Join(sysRoot, path)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		sysRoot, err = ioutil.TempDir("", "sys")
+		Expect(err).NotTo(HaveOccurred())
+
+		writeFile("devices/system/cpu/online", "0-7\n")
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(sysRoot)).To(Succeed())
+	})
+
+	Describe("LoadTopology", func() {
+		It("places the CPUs on their NUMA nodes", func() {
+			writeFile("devices/system/node/node0/cpulist", "0-3\n")
+			writeFile("devices/system/node/node1/cpulist", "4-7\n")
+
+			topology, err := cpuset.LoadTopology(sysRoot)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(topology).To(Equal(cpuset.Topology{0: {0, 1, 2, 3}, 1: {4, 5, 6, 7}}))
+		})
+
+		Context("when the host has no NUMA nodes", func() {
+			It("places all online CPUs on node 0", func() {
+				topology, err := cpuset.LoadTopology(sysRoot)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(topology).To(Equal(cpuset.Topology{0: {0, 1, 2, 3, 4, 5, 6, 7}}))
+			})
+		})
+
+		Context("when a node's CPUs cannot be parsed", func() {
+			It("returns an error", func() {
+				writeFile("devices/system/node/node0/cpulist", "banana\n")
+
+				_, err := cpuset.LoadTopology(sysRoot)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("OnlineCPUs", func() {
+		It("returns the online CPUs", func() {
+			Expect(cpuset.OnlineCPUs(sysRoot)).To(Equal([]int{0, 1, 2, 3, 4, 5, 6, 7}))
+		})
+	})
+
+	Describe("Node", func() {
+		It("returns the node of a CPU, or -1 if it is on none", func() {
+			topology := cpuset.Topology{0: {0, 1}, 1: {2, 3}}
+			Expect(topology.Node(2)).To(Equal(1))
+			Expect(topology.Node(9)).To(Equal(-1))
+		})
+	})
+})
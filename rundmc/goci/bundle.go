@@ -73,6 +73,52 @@ func (b Bndl) Resources() *specs.LinuxResources {
 	return b.Spec.Linux.Resources
 }
 
+// WithDeviceCgroups returns a bundle with the given device cgroup rules added
+// after the existing rules. The original bundle is not modified.
+func (b Bndl) WithDeviceCgroups(rules ...specs.LinuxDeviceCgroup) Bndl {
+	resources := &specs.LinuxResources{}
+	if b.Resources() != nil {
+		*resources = *b.Resources()
+	}
+
+	resources.Devices = append(append([]specs.LinuxDeviceCgroup{}, resources.Devices...), rules...)
+	b.CloneLinux().Spec.Linux.Resources = resources
+
+	return b
+}
+
+func (b Bndl) DeviceCgroups() []specs.LinuxDeviceCgroup {
+	if b.Resources() == nil {
+		return nil
+	}
+
+	return b.Resources().Devices
+}
+
+// WithBlockIO returns a bundle with the block I/O resources replaced with the
+// given resources. The original bundle is not modified.
+func (b Bndl) WithBlockIO(blockIO specs.LinuxBlockIO) Bndl {
+	resources := &specs.LinuxResources{}
+	if b.Resources() != nil {
+		*resources = *b.Resources()
+	}
+
+	resources.BlockIO = &blockIO
+	b.CloneLinux().Spec.Linux.Resources = resources
+
+	return b
+}
+
+func (b Bndl) BlockIO() *specs.LinuxBlockIO {
+	if b.Resources() == nil {
+		return nil
+	}
+
+	return b.Resources().BlockIO
+}
+
+// WithCPUShares returns a bundle with the CPU resources, i.e. shares, quota
+// and cpuset, replaced with the given resources
 func (b Bndl) WithCPUShares(shares specs.LinuxCPU) Bndl {
 	resources := b.Resources()
 	if resources == nil {
@@ -97,6 +143,28 @@ func (b Bndl) WithMemoryLimit(limit specs.LinuxMemory) Bndl {
 	return b
 }
 
+// WithOOMScoreAdj returns a bundle whose processes have the given oom score
+// adjustment. The original bundle is not modified.
+func (b Bndl) WithOOMScoreAdj(score int) Bndl {
+	resources := &specs.LinuxResources{}
+	if b.Resources() != nil {
+		*resources = *b.Resources()
+	}
+
+	resources.OOMScoreAdj = &score
+	b.CloneLinux().Spec.Linux.Resources = resources
+
+	return b
+}
+
+func (b Bndl) OOMScoreAdj() *int {
+	if b.Resources() == nil {
+		return nil
+	}
+
+	return b.Resources().OOMScoreAdj
+}
+
 func (b Bndl) WithPidLimit(limit specs.LinuxPids) Bndl {
 	resources := b.Resources()
 	if resources == nil {
@@ -203,6 +271,45 @@ func (b Bndl) MaskedPaths() []string {
 	return b.Spec.Linux.MaskedPaths
 }
 
+// WithSeccomp returns a bundle with the given seccomp configuration. The original bundle is not modified.
+func (b Bndl) WithSeccomp(seccomp *specs.LinuxSeccomp) Bndl {
+	b.CloneLinux().Spec.Linux.Seccomp = seccomp
+	return b
+}
+
+func (b Bndl) Seccomp() *specs.LinuxSeccomp {
+	return b.Spec.Linux.Seccomp
+}
+
+// WithApparmorProfile returns a bundle whose process runs under the given AppArmor profile. The original bundle is not modified.
+func (b Bndl) WithApparmorProfile(profile string) Bndl {
+	b.Spec.Process.ApparmorProfile = profile
+	return b
+}
+
+func (b Bndl) ApparmorProfile() string {
+	return b.Spec.Process.ApparmorProfile
+}
+
+// WithSysctls returns a bundle with the given sysctls added, replacing the
+// values of any existing sysctls of the same names. The original bundle is not modified.
+func (b Bndl) WithSysctls(sysctls map[string]string) Bndl {
+	merged := map[string]string{}
+	for name, value := range b.Spec.Linux.Sysctl {
+		merged[name] = value
+	}
+	for name, value := range sysctls {
+		merged[name] = value
+	}
+
+	b.CloneLinux().Spec.Linux.Sysctl = merged
+	return b
+}
+
+func (b Bndl) Sysctls() map[string]string {
+	return b.Spec.Linux.Sysctl
+}
+
 type NamespaceSlice []specs.LinuxNamespace
 
 func (slice NamespaceSlice) Set(ns specs.LinuxNamespace) NamespaceSlice {
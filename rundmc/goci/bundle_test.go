@@ -166,6 +166,31 @@ var _ = Describe("Bundle", func() {
 		It("returns a bundle with the cpu shares added to the runtime spec", func() {
 			Expect(returnedBundle.Resources().CPU).To(Equal(&specs.LinuxCPU{Shares: &shares}))
 		})
+
+		Context("when the cpu limits pin the container to CPUs", func() {
+			BeforeEach(func() {
+				returnedBundle = initialBundle.WithCPUShares(specs.LinuxCPU{Shares: &shares, Cpus: "0-3", Mems: "0"})
+			})
+
+			It("returns a bundle with the cpuset added to the runtime spec", func() {
+				Expect(returnedBundle.Resources().CPU.Cpus).To(Equal("0-3"))
+				Expect(returnedBundle.Resources().CPU.Mems).To(Equal("0"))
+			})
+		})
+	})
+
+	Describe("WithOOMScoreAdj", func() {
+		BeforeEach(func() {
+			returnedBundle = initialBundle.WithOOMScoreAdj(-500)
+		})
+
+		It("returns a bundle with the oom score adjustment added to the runtime spec", func() {
+			Expect(*returnedBundle.OOMScoreAdj()).To(Equal(-500))
+		})
+
+		It("does not modify the original bundle", func() {
+			Expect(initialBundle.OOMScoreAdj()).To(BeNil())
+		})
 	})
 
 	Describe("WithPidLimit", func() {
@@ -282,6 +307,91 @@ var _ = Describe("Bundle", func() {
 		})
 	})
 
+	Describe("WithBlockIO", func() {
+		var weight uint16 = 500
+
+		It("sets the block I/O resources of the bundle", func() {
+			returnedBundle = initialBundle.WithBlockIO(specs.LinuxBlockIO{Weight: &weight})
+			Expect(returnedBundle.BlockIO()).To(Equal(&specs.LinuxBlockIO{Weight: &weight}))
+		})
+
+		It("keeps the other resources and does not modify the original bundle", func() {
+			initialBundle = initialBundle.WithResources(&specs.LinuxResources{Pids: &specs.LinuxPids{Limit: 10}})
+
+			returnedBundle = initialBundle.WithBlockIO(specs.LinuxBlockIO{Weight: &weight})
+			Expect(returnedBundle.Resources().Pids).To(Equal(&specs.LinuxPids{Limit: 10}))
+			Expect(initialBundle.BlockIO()).To(BeNil())
+		})
+
+		Context("when the bundle has no resources", func() {
+			It("has no block I/O resources", func() {
+				Expect(initialBundle.BlockIO()).To(BeNil())
+			})
+		})
+	})
+
+	Describe("WithDeviceCgroups", func() {
+		var (
+			access = "rwm"
+			kind   = "c"
+			major  = int64(10)
+			minor  = int64(200)
+			tun    = specs.LinuxDeviceCgroup{Allow: true, Type: &kind, Major: &major, Minor: &minor, Access: &access}
+		)
+
+		It("adds the rules to the bundle's resources", func() {
+			returnedBundle = initialBundle.WithDeviceCgroups(tun)
+			Expect(returnedBundle.DeviceCgroups()).To(Equal([]specs.LinuxDeviceCgroup{tun}))
+		})
+
+		Context("when the bundle already has rules and other resources", func() {
+			var denyAll specs.LinuxDeviceCgroup
+
+			BeforeEach(func() {
+				denyAll = specs.LinuxDeviceCgroup{Allow: false, Access: &access}
+				initialBundle = initialBundle.WithResources(&specs.LinuxResources{
+					Devices: []specs.LinuxDeviceCgroup{denyAll},
+					Pids:    &specs.LinuxPids{Limit: 10},
+				})
+			})
+
+			It("adds the rules after the existing rules", func() {
+				returnedBundle = initialBundle.WithDeviceCgroups(tun)
+				Expect(returnedBundle.DeviceCgroups()).To(Equal([]specs.LinuxDeviceCgroup{denyAll, tun}))
+				Expect(returnedBundle.Resources().Pids).To(Equal(&specs.LinuxPids{Limit: 10}))
+			})
+
+			It("does not modify the original bundle", func() {
+				initialBundle.WithDeviceCgroups(tun)
+				Expect(initialBundle.DeviceCgroups()).To(Equal([]specs.LinuxDeviceCgroup{denyAll}))
+			})
+		})
+	})
+
+	Describe("WithSysctls", func() {
+		BeforeEach(func() {
+			initialBundle = initialBundle.WithSysctls(map[string]string{"net.core.somaxconn": "128"})
+		})
+
+		It("adds the sysctls to the bundle", func() {
+			returnedBundle = initialBundle.WithSysctls(map[string]string{"kernel.shmmax": "4096"})
+			Expect(returnedBundle.Sysctls()).To(Equal(map[string]string{
+				"net.core.somaxconn": "128",
+				"kernel.shmmax":      "4096",
+			}))
+		})
+
+		It("replaces existing sysctls of the same names", func() {
+			returnedBundle = initialBundle.WithSysctls(map[string]string{"net.core.somaxconn": "1024"})
+			Expect(returnedBundle.Sysctls()).To(Equal(map[string]string{"net.core.somaxconn": "1024"}))
+		})
+
+		It("does not modify the original bundle", func() {
+			initialBundle.WithSysctls(map[string]string{"net.core.somaxconn": "1024"})
+			Expect(initialBundle.Sysctls()).To(Equal(map[string]string{"net.core.somaxconn": "128"}))
+		})
+	})
+
 	Describe("NamespaceSlice", func() {
 		Context("when the namespace isnt already in the slice", func() {
 			It("adds the namespace", func() {
@@ -312,4 +422,33 @@ var _ = Describe("Bundle", func() {
 		})
 	})
 
+	Describe("WithSeccomp", func() {
+		var seccomp *specs.LinuxSeccomp
+
+		BeforeEach(func() {
+			seccomp = &specs.LinuxSeccomp{DefaultAction: specs.ActErrno}
+			returnedBundle = initialBundle.WithSeccomp(seccomp)
+		})
+
+		It("sets the seccomp configuration in the bundle", func() {
+			Expect(returnedBundle.Seccomp()).To(Equal(seccomp))
+		})
+
+		It("does not modify the initial bundle", func() {
+			Expect(initialBundle.Seccomp()).To(BeNil())
+		})
+	})
+
+	Describe("WithApparmorProfile", func() {
+		It("sets the AppArmor profile of the bundle's process", func() {
+			returnedBundle := initialBundle.WithApparmorProfile("garden-default")
+			Expect(returnedBundle.ApparmorProfile()).To(Equal("garden-default"))
+		})
+
+		It("does not modify the initial bundle", func() {
+			initialBundle.WithApparmorProfile("garden-default")
+			Expect(initialBundle.ApparmorProfile()).To(BeEmpty())
+		})
+	})
+
 })
@@ -38,6 +38,11 @@ func DeleteCommand(id, logFile string) *exec.Cmd {
 	return DefaultRuncBinary.DeleteCommand(id, logFile)
 }
 
+// UpdateCommand creates a command that updates the resources of a container using the default runc binary name.
+func UpdateCommand(id, resourcesPath, logFile string) *exec.Cmd {
+	return DefaultRuncBinary.UpdateCommand(id, resourcesPath, logFile)
+}
+
 func EventsCommand(id string) *exec.Cmd {
 	return DefaultRuncBinary.EventsCommand(id)
 }
@@ -96,3 +101,9 @@ func (runc RuncBinary) StatsCommand(id, logFile string) *exec.Cmd {
 func (runc RuncBinary) DeleteCommand(id, logFile string) *exec.Cmd {
 	return exec.Command(string(runc), "--debug", "--log", logFile, "delete", id)
 }
+
+// UpdateCommand returns an *exec.Cmd that, when run, will update the cgroup
+// resources of the running container to those in the given JSON file.
+func (runc RuncBinary) UpdateCommand(id, resourcesPath, logFile string) *exec.Cmd {
+	return exec.Command(string(runc), "--debug", "--log", logFile, "update", "-r", resourcesPath, id)
+}
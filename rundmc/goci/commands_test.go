@@ -70,4 +70,11 @@ var _ = Describe("Commands", func() {
 			Expect(cmd.Args).To(Equal([]string{"funC", "--debug", "--log", "log.file", "delete", "my-bundle-id"}))
 		})
 	})
+
+	Describe("UpdateCommand", func() {
+		It("creates an *exec.Cmd to update the resources of the bundle", func() {
+			cmd := goci.UpdateCommand("my-bundle-id", "resources.json", "log.file")
+			Expect(cmd.Args).To(Equal([]string{"funC", "--debug", "--log", "log.file", "update", "-r", "resources.json", "my-bundle-id"}))
+		})
+	})
 })
@@ -46,6 +46,15 @@ var _ = Describe("Bundle Serialization", func() {
 			Expect(configJson).To(HaveKeyWithValue("ociVersion", Equal("abcd")))
 		})
 
+		It("replaces a previously saved spec", func() {
+			bndle.Spec.Version = "ab"
+			Expect(bndle.Save(tmp)).To(Succeed())
+
+			var configJson map[string]interface{}
+			Expect(json.NewDecoder(mustOpen(path.Join(tmp, "config.json"))).Decode(&configJson)).To(Succeed())
+			Expect(configJson).To(HaveKeyWithValue("ociVersion", Equal("ab")))
+		})
+
 		Context("when saving fails", func() {
 			It("returns an error", func() {
 				err := bndle.Save("non-existent-dir")
@@ -0,0 +1,13 @@
+package idmapping_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestIdmapping(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Idmapping Suite")
+}
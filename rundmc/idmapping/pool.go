@@ -0,0 +1,141 @@
+package idmapping
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"code.cloudfoundry.org/guardian/gardener"
+)
+
+// Pool gives containers distinct, equally sized ranges of host UIDs and GIDs,
+// carved in order from the server's UID and GID ranges
+type Pool struct {
+	uids Range
+	gids Range
+	size uint32
+
+	mu          sync.Mutex
+	allocations map[string]int
+}
+
+// NewPool returns a pool which gives each container the given number of UIDs
+// and GIDs
+func NewPool(uids, gids Range, size uint32) *Pool {
+	return &Pool{
+		uids:        uids,
+		gids:        gids,
+		size:        size,
+		allocations: map[string]int{},
+	}
+}
+
+// Capacity returns how many containers the pool can give ranges to
+func (p *Pool) Capacity() int {
+	if p.size == 0 {
+		return 0
+	}
+
+	length := p.uids.Length
+	if p.gids.Length < length {
+		length = p.gids.Length
+	}
+
+	return int(length / p.size)
+}
+
+func (p *Pool) Allocate(handle string) (gardener.IDMappings, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.allocations[handle]; ok {
+		return gardener.IDMappings{}, fmt.Errorf("ids are already allocated to container %s", handle)
+	}
+
+	owners := p.owners()
+	for block := 0; block < p.Capacity(); block++ {
+		if _, ok := owners[block]; !ok {
+			p.allocations[handle] = block
+			return p.mappings(block), nil
+		}
+	}
+
+	return gardener.IDMappings{}, fmt.Errorf("no free id ranges: all %d are in use", p.Capacity())
+}
+
+// Reserve marks the range of the given mappings as in use by the container.
+// Mappings which the pool did not allocate, e.g. the server's mappings, are
+// ignored.
+func (p *Pool) Reserve(handle string, mappings gardener.IDMappings) error {
+	block, ok := p.block(mappings)
+	if !ok {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if owner, ok := p.owners()[block]; ok && owner != handle {
+		start := mappings.UIDMappings[0].HostID
+		return fmt.Errorf("ids %d-%d are already allocated to container %s", start, start+p.size-1, owner)
+	}
+
+	p.allocations[handle] = block
+	return nil
+}
+
+func (p *Pool) Release(handle string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.allocations, handle)
+}
+
+// Mappings returns the mappings allocated to the container, if any
+func (p *Pool) Mappings(handle string) (gardener.IDMappings, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	block, ok := p.allocations[handle]
+	if !ok {
+		return gardener.IDMappings{}, false
+	}
+
+	return p.mappings(block), true
+}
+
+func (p *Pool) mappings(block int) gardener.IDMappings {
+	offset := uint32(block) * p.size
+	return gardener.IDMappings{
+		UIDMappings: Range{Start: p.uids.Start + offset, Length: p.size}.Mappings(),
+		GIDMappings: Range{Start: p.gids.Start + offset, Length: p.size}.Mappings(),
+	}
+}
+
+// block returns the block whose mappings are the given mappings, if any
+func (p *Pool) block(mappings gardener.IDMappings) (int, bool) {
+	if len(mappings.UIDMappings) != 1 || p.size == 0 {
+		return 0, false
+	}
+
+	hostID := mappings.UIDMappings[0].HostID
+	if hostID < p.uids.Start || (hostID-p.uids.Start)%p.size != 0 {
+		return 0, false
+	}
+
+	block := int((hostID - p.uids.Start) / p.size)
+	if block >= p.Capacity() || !reflect.DeepEqual(mappings, p.mappings(block)) {
+		return 0, false
+	}
+
+	return block, true
+}
+
+func (p *Pool) owners() map[int]string {
+	owners := map[int]string{}
+	for handle, block := range p.allocations {
+		owners[block] = handle
+	}
+
+	return owners
+}
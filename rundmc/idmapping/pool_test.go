@@ -0,0 +1,120 @@
+package idmapping_test
+
+import (
+	"code.cloudfoundry.org/garden-shed/rootfs_provider"
+	"code.cloudfoundry.org/guardian/gardener"
+	"code.cloudfoundry.org/guardian/rundmc/idmapping"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pool", func() {
+	var pool *idmapping.Pool
+
+	mappings := func(uid, gid uint32) gardener.IDMappings {
+		return gardener.IDMappings{
+			UIDMappings: rootfs_provider.MappingList{{ContainerID: 0, HostID: uid, Size: 1000}},
+			GIDMappings: rootfs_provider.MappingList{{ContainerID: 0, HostID: gid, Size: 1000}},
+		}
+	}
+
+	BeforeEach(func() {
+		pool = idmapping.NewPool(
+			idmapping.Range{Start: 100000, Length: 3000},
+			idmapping.Range{Start: 200000, Length: 2500},
+			1000,
+		)
+	})
+
+	Describe("Capacity", func() {
+		It("is the number of ranges which fit in both the uid and gid ranges", func() {
+			Expect(pool.Capacity()).To(Equal(2))
+		})
+	})
+
+	Describe("Allocate", func() {
+		It("gives each container its own range", func() {
+			Expect(pool.Allocate("a")).To(Equal(mappings(100000, 200000)))
+			Expect(pool.Allocate("b")).To(Equal(mappings(101000, 201000)))
+		})
+
+		Context("when every range is in use", func() {
+			It("returns an error", func() {
+				_, err := pool.Allocate("a")
+				Expect(err).NotTo(HaveOccurred())
+				_, err = pool.Allocate("b")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = pool.Allocate("c")
+				Expect(err).To(MatchError("no free id ranges: all 2 are in use"))
+			})
+		})
+
+		Context("when the container already has a range", func() {
+			It("returns an error", func() {
+				_, err := pool.Allocate("a")
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = pool.Allocate("a")
+				Expect(err).To(MatchError("ids are already allocated to container a"))
+			})
+		})
+	})
+
+	Describe("Release", func() {
+		It("makes the container's range available again", func() {
+			_, err := pool.Allocate("a")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = pool.Allocate("b")
+			Expect(err).NotTo(HaveOccurred())
+
+			pool.Release("a")
+			Expect(pool.Allocate("c")).To(Equal(mappings(100000, 200000)))
+		})
+
+		It("ignores containers without a range", func() {
+			pool.Release("a")
+		})
+	})
+
+	Describe("Reserve", func() {
+		It("stops the container's range being allocated to other containers", func() {
+			Expect(pool.Reserve("a", mappings(100000, 200000))).To(Succeed())
+			Expect(pool.Allocate("b")).To(Equal(mappings(101000, 201000)))
+		})
+
+		It("ignores mappings which are not the pool's", func() {
+			Expect(pool.Reserve("a", gardener.IDMappings{
+				UIDMappings: rootfs_provider.MappingList{{ContainerID: 0, HostID: 4294967294, Size: 1}, {ContainerID: 1, HostID: 1, Size: 4294967293}},
+				GIDMappings: rootfs_provider.MappingList{{ContainerID: 0, HostID: 4294967294, Size: 1}, {ContainerID: 1, HostID: 1, Size: 4294967293}},
+			})).To(Succeed())
+			Expect(pool.Reserve("b", mappings(100500, 200500))).To(Succeed())
+			Expect(pool.Reserve("c", mappings(100000, 201000))).To(Succeed())
+
+			Expect(pool.Allocate("d")).To(Equal(mappings(100000, 200000)))
+		})
+
+		Context("when the range is allocated to another container", func() {
+			It("returns an error", func() {
+				Expect(pool.Reserve("a", mappings(101000, 201000))).To(Succeed())
+				Expect(pool.Reserve("b", mappings(101000, 201000))).To(MatchError("ids 101000-101999 are already allocated to container a"))
+			})
+		})
+	})
+
+	Describe("Mappings", func() {
+		It("returns the mappings allocated to the container", func() {
+			_, err := pool.Allocate("a")
+			Expect(err).NotTo(HaveOccurred())
+
+			allocated, ok := pool.Mappings("a")
+			Expect(ok).To(BeTrue())
+			Expect(allocated).To(Equal(mappings(100000, 200000)))
+		})
+
+		It("returns false for containers without a range", func() {
+			_, ok := pool.Mappings("a")
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,86 @@
+package idmapping
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/garden-shed/rootfs_provider"
+)
+
+// Range is a range of host UIDs or GIDs
+type Range struct {
+	Start  uint32
+	Length uint32
+}
+
+// NewRange returns the range of the given length starting at the given host
+// ID, checking that it is not empty and ends before the largest ID
+func NewRange(start, length uint32) (Range, error) {
+	if length == 0 || uint64(start)+uint64(length)-1 > math.MaxUint32 {
+		return Range{}, fmt.Errorf("invalid id range: %d ids from %d", length, start)
+	}
+
+	return Range{Start: start, Length: length}, nil
+}
+
+// Mappings maps the container IDs from 0 onto the range
+func (r Range) Mappings() rootfs_provider.MappingList {
+	return rootfs_provider.MappingList{{ContainerID: 0, HostID: r.Start, Size: r.Length}}
+}
+
+// LoadSubIDRange returns the first range which a subordinate ID file, e.g.
+// /etc/subuid, grants to the given user, given by name or by ID
+func LoadSubIDRange(path, user string) (Range, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Range{}, err
+	}
+	defer file.Close()
+
+	r, err := ParseSubIDRange(file, user)
+	if err != nil {
+		return Range{}, fmt.Errorf("%s: %s", path, err)
+	}
+
+	return r, nil
+}
+
+// ParseSubIDRange returns the first range which the lines of a subordinate ID
+// file, in the form user:start:length, grant to the given user
+func ParseSubIDRange(subIDs io.Reader, user string) (Range, error) {
+	scanner := bufio.NewScanner(subIDs)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, ":")
+		if len(fields) != 3 || fields[0] != user {
+			continue
+		}
+
+		start, err := strconv.ParseUint(fields[1], 10, 32)
+		if err != nil {
+			return Range{}, fmt.Errorf("invalid subordinate id range: '%s'", line)
+		}
+
+		length, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			return Range{}, fmt.Errorf("invalid subordinate id range: '%s'", line)
+		}
+
+		return NewRange(uint32(start), uint32(length))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Range{}, err
+	}
+
+	return Range{}, fmt.Errorf("no subordinate ids for user %s", user)
+}
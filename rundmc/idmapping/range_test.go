@@ -0,0 +1,123 @@
+package idmapping_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code.cloudfoundry.org/garden-shed/rootfs_provider"
+	"code.cloudfoundry.org/guardian/rundmc/idmapping"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Range", func() {
+	Describe("NewRange", func() {
+		It("returns the range", func() {
+			Expect(idmapping.NewRange(100000, 65536)).To(Equal(idmapping.Range{Start: 100000, Length: 65536}))
+		})
+
+		It("allows a range ending at the largest id", func() {
+			Expect(idmapping.NewRange(4294967295, 1)).To(Equal(idmapping.Range{Start: 4294967295, Length: 1}))
+		})
+
+		Context("when the range is empty", func() {
+			It("returns an error", func() {
+				_, err := idmapping.NewRange(100000, 0)
+				Expect(err).To(MatchError("invalid id range: 0 ids from 100000"))
+			})
+		})
+
+		Context("when the range ends after the largest id", func() {
+			It("returns an error", func() {
+				_, err := idmapping.NewRange(4294967295, 2)
+				Expect(err).To(MatchError("invalid id range: 2 ids from 4294967295"))
+			})
+		})
+	})
+
+	Describe("Mappings", func() {
+		It("maps the container ids from 0 onto the range", func() {
+			Expect(idmapping.Range{Start: 100000, Length: 65536}.Mappings()).To(Equal(rootfs_provider.MappingList{
+				{ContainerID: 0, HostID: 100000, Size: 65536},
+			}))
+		})
+	})
+
+	Describe("ParseSubIDRange", func() {
+		const subIDs = `# subordinate ids
+alice:100000:65536
+garden:165536:1000000
+garden:2000000:65536
+1001:3000000:65536
+`
+
+		It("returns the first range of the user", func() {
+			Expect(idmapping.ParseSubIDRange(strings.NewReader(subIDs), "garden")).To(Equal(idmapping.Range{Start: 165536, Length: 1000000}))
+		})
+
+		It("finds users given by id", func() {
+			Expect(idmapping.ParseSubIDRange(strings.NewReader(subIDs), "1001")).To(Equal(idmapping.Range{Start: 3000000, Length: 65536}))
+		})
+
+		Context("when the user has no range", func() {
+			It("returns an error", func() {
+				_, err := idmapping.ParseSubIDRange(strings.NewReader(subIDs), "bob")
+				Expect(err).To(MatchError("no subordinate ids for user bob"))
+			})
+		})
+
+		Context("when the user's range is invalid", func() {
+			It("returns an error", func() {
+				_, err := idmapping.ParseSubIDRange(strings.NewReader("garden:lots:65536\n"), "garden")
+				Expect(err).To(MatchError("invalid subordinate id range: 'garden:lots:65536'"))
+			})
+		})
+
+		Context("when the user's range is empty", func() {
+			It("returns an error", func() {
+				_, err := idmapping.ParseSubIDRange(strings.NewReader("garden:100000:0\n"), "garden")
+				Expect(err).To(MatchError("invalid id range: 0 ids from 100000"))
+			})
+		})
+	})
+
+	Describe("LoadSubIDRange", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "subid")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("reads the range from the file", func() {
+			path := filepath.Join(dir, "subuid")
+			Expect(ioutil.WriteFile(path, []byte("garden:100000:65536\n"), 0644)).To(Succeed())
+
+			Expect(idmapping.LoadSubIDRange(path, "garden")).To(Equal(idmapping.Range{Start: 100000, Length: 65536}))
+		})
+
+		Context("when the user has no range", func() {
+			It("returns an error naming the file", func() {
+				path := filepath.Join(dir, "subuid")
+				Expect(ioutil.WriteFile(path, []byte("alice:100000:65536\n"), 0644)).To(Succeed())
+
+				_, err := idmapping.LoadSubIDRange(path, "garden")
+				Expect(err).To(MatchError(path + ": no subordinate ids for user garden"))
+			})
+		})
+
+		Context("when the file does not exist", func() {
+			It("returns an error", func() {
+				_, err := idmapping.LoadSubIDRange(filepath.Join(dir, "nope"), "garden")
+				Expect(err).To(MatchError(ContainSubstring("no such file")))
+			})
+		})
+	})
+})
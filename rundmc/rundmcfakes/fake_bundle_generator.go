@@ -10,22 +10,24 @@ import (
 )
 
 type FakeBundleGenerator struct {
-	GenerateStub        func(spec gardener.DesiredContainerSpec) goci.Bndl
+	GenerateStub        func(spec gardener.DesiredContainerSpec) (goci.Bndl, error)
 	generateMutex       sync.RWMutex
 	generateArgsForCall []struct {
 		spec gardener.DesiredContainerSpec
 	}
 	generateReturns struct {
 		result1 goci.Bndl
+		result2 error
 	}
 	generateReturnsOnCall map[int]struct {
 		result1 goci.Bndl
+		result2 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeBundleGenerator) Generate(spec gardener.DesiredContainerSpec) goci.Bndl {
+func (fake *FakeBundleGenerator) Generate(spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
 	fake.generateMutex.Lock()
 	ret, specificReturn := fake.generateReturnsOnCall[len(fake.generateArgsForCall)]
 	fake.generateArgsForCall = append(fake.generateArgsForCall, struct {
@@ -37,9 +39,9 @@ func (fake *FakeBundleGenerator) Generate(spec gardener.DesiredContainerSpec) go
 		return fake.GenerateStub(spec)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fake.generateReturns.result1
+	return fake.generateReturns.result1, fake.generateReturns.result2
 }
 
 func (fake *FakeBundleGenerator) GenerateCallCount() int {
@@ -54,23 +56,26 @@ func (fake *FakeBundleGenerator) GenerateArgsForCall(i int) gardener.DesiredCont
 	return fake.generateArgsForCall[i].spec
 }
 
-func (fake *FakeBundleGenerator) GenerateReturns(result1 goci.Bndl) {
+func (fake *FakeBundleGenerator) GenerateReturns(result1 goci.Bndl, result2 error) {
 	fake.GenerateStub = nil
 	fake.generateReturns = struct {
 		result1 goci.Bndl
-	}{result1}
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeBundleGenerator) GenerateReturnsOnCall(i int, result1 goci.Bndl) {
+func (fake *FakeBundleGenerator) GenerateReturnsOnCall(i int, result1 goci.Bndl, result2 error) {
 	fake.GenerateStub = nil
 	if fake.generateReturnsOnCall == nil {
 		fake.generateReturnsOnCall = make(map[int]struct {
 			result1 goci.Bndl
+			result2 error
 		})
 	}
 	fake.generateReturnsOnCall[i] = struct {
 		result1 goci.Bndl
-	}{result1}
+		result2 error
+	}{result1, result2}
 }
 
 func (fake *FakeBundleGenerator) Invocations() map[string][][]interface{} {
@@ -10,7 +10,7 @@ import (
 )
 
 type FakeBundlerRule struct {
-	ApplyStub        func(bndle goci.Bndl, spec gardener.DesiredContainerSpec) goci.Bndl
+	ApplyStub        func(bndle goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error)
 	applyMutex       sync.RWMutex
 	applyArgsForCall []struct {
 		bndle goci.Bndl
@@ -18,15 +18,17 @@ type FakeBundlerRule struct {
 	}
 	applyReturns struct {
 		result1 goci.Bndl
+		result2 error
 	}
 	applyReturnsOnCall map[int]struct {
 		result1 goci.Bndl
+		result2 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeBundlerRule) Apply(bndle goci.Bndl, spec gardener.DesiredContainerSpec) goci.Bndl {
+func (fake *FakeBundlerRule) Apply(bndle goci.Bndl, spec gardener.DesiredContainerSpec) (goci.Bndl, error) {
 	fake.applyMutex.Lock()
 	ret, specificReturn := fake.applyReturnsOnCall[len(fake.applyArgsForCall)]
 	fake.applyArgsForCall = append(fake.applyArgsForCall, struct {
@@ -39,9 +41,9 @@ func (fake *FakeBundlerRule) Apply(bndle goci.Bndl, spec gardener.DesiredContain
 		return fake.ApplyStub(bndle, spec)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fake.applyReturns.result1
+	return fake.applyReturns.result1, fake.applyReturns.result2
 }
 
 func (fake *FakeBundlerRule) ApplyCallCount() int {
@@ -56,23 +58,26 @@ func (fake *FakeBundlerRule) ApplyArgsForCall(i int) (goci.Bndl, gardener.Desire
 	return fake.applyArgsForCall[i].bndle, fake.applyArgsForCall[i].spec
 }
 
-func (fake *FakeBundlerRule) ApplyReturns(result1 goci.Bndl) {
+func (fake *FakeBundlerRule) ApplyReturns(result1 goci.Bndl, result2 error) {
 	fake.ApplyStub = nil
 	fake.applyReturns = struct {
 		result1 goci.Bndl
-	}{result1}
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeBundlerRule) ApplyReturnsOnCall(i int, result1 goci.Bndl) {
+func (fake *FakeBundlerRule) ApplyReturnsOnCall(i int, result1 goci.Bndl, result2 error) {
 	fake.ApplyStub = nil
 	if fake.applyReturnsOnCall == nil {
 		fake.applyReturnsOnCall = make(map[int]struct {
 			result1 goci.Bndl
+			result2 error
 		})
 	}
 	fake.applyReturnsOnCall[i] = struct {
 		result1 goci.Bndl
-	}{result1}
+		result2 error
+	}{result1, result2}
 }
 
 func (fake *FakeBundlerRule) Invocations() map[string][][]interface{} {
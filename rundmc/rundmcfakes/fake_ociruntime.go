@@ -9,6 +9,7 @@ import (
 	"code.cloudfoundry.org/guardian/rundmc"
 	"code.cloudfoundry.org/guardian/rundmc/runrunc"
 	"code.cloudfoundry.org/lager"
+	"github.com/opencontainers/runtime-spec/specs-go"
 )
 
 type FakeOCIRuntime struct {
@@ -125,6 +126,19 @@ type FakeOCIRuntime struct {
 	watchEventsReturnsOnCall map[int]struct {
 		result1 error
 	}
+	UpdateResourcesStub        func(log lager.Logger, id string, resources specs.LinuxResources) error
+	updateResourcesMutex       sync.RWMutex
+	updateResourcesArgsForCall []struct {
+		log       lager.Logger
+		id        string
+		resources specs.LinuxResources
+	}
+	updateResourcesReturns struct {
+		result1 error
+	}
+	updateResourcesReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -542,6 +556,56 @@ func (fake *FakeOCIRuntime) WatchEventsReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeOCIRuntime) UpdateResources(log lager.Logger, id string, resources specs.LinuxResources) error {
+	fake.updateResourcesMutex.Lock()
+	ret, specificReturn := fake.updateResourcesReturnsOnCall[len(fake.updateResourcesArgsForCall)]
+	fake.updateResourcesArgsForCall = append(fake.updateResourcesArgsForCall, struct {
+		log       lager.Logger
+		id        string
+		resources specs.LinuxResources
+	}{log, id, resources})
+	fake.recordInvocation("UpdateResources", []interface{}{log, id, resources})
+	fake.updateResourcesMutex.Unlock()
+	if fake.UpdateResourcesStub != nil {
+		return fake.UpdateResourcesStub(log, id, resources)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.updateResourcesReturns.result1
+}
+
+func (fake *FakeOCIRuntime) UpdateResourcesCallCount() int {
+	fake.updateResourcesMutex.RLock()
+	defer fake.updateResourcesMutex.RUnlock()
+	return len(fake.updateResourcesArgsForCall)
+}
+
+func (fake *FakeOCIRuntime) UpdateResourcesArgsForCall(i int) (lager.Logger, string, specs.LinuxResources) {
+	fake.updateResourcesMutex.RLock()
+	defer fake.updateResourcesMutex.RUnlock()
+	return fake.updateResourcesArgsForCall[i].log, fake.updateResourcesArgsForCall[i].id, fake.updateResourcesArgsForCall[i].resources
+}
+
+func (fake *FakeOCIRuntime) UpdateResourcesReturns(result1 error) {
+	fake.UpdateResourcesStub = nil
+	fake.updateResourcesReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeOCIRuntime) UpdateResourcesReturnsOnCall(i int, result1 error) {
+	fake.UpdateResourcesStub = nil
+	if fake.updateResourcesReturnsOnCall == nil {
+		fake.updateResourcesReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateResourcesReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeOCIRuntime) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -561,6 +625,8 @@ func (fake *FakeOCIRuntime) Invocations() map[string][][]interface{} {
 	defer fake.statsMutex.RUnlock()
 	fake.watchEventsMutex.RLock()
 	defer fake.watchEventsMutex.RUnlock()
+	fake.updateResourcesMutex.RLock()
+	defer fake.updateResourcesMutex.RUnlock()
 	return fake.invocations
 }
 
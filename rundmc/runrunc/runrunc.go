@@ -21,6 +21,7 @@ type RunRunc struct {
 	*Stater
 	*Killer
 	*Deleter
+	*Updater
 }
 
 //go:generate counterfeiter . RuncBinary
@@ -31,9 +32,10 @@ type RuncBinary interface {
 	StatsCommand(id, logFile string) *exec.Cmd
 	KillCommand(id, signal, logFile string) *exec.Cmd
 	DeleteCommand(id, logFile string) *exec.Cmd
+	UpdateCommand(id, resourcesPath, logFile string) *exec.Cmd
 }
 
-func New(runner command_runner.CommandRunner, runcCmdRunner RuncCmdRunner, runc RuncBinary, dadooPath, runcPath string, execPreparer ExecPreparer, execRunner ExecRunner) *RunRunc {
+func New(runner command_runner.CommandRunner, runcCmdRunner RuncCmdRunner, runc RuncBinary, dadooPath, runcPath string, execPreparer ExecPreparer, execRunner ExecRunner, cgroupPaths CgroupPathResolver) *RunRunc {
 	return &RunRunc{
 		Creator: NewCreator(runcPath, runner),
 		Execer:  NewExecer(execPreparer, execRunner),
@@ -43,5 +45,6 @@ func New(runner command_runner.CommandRunner, runcCmdRunner RuncCmdRunner, runc
 		Stater:     NewStater(runcCmdRunner, runc),
 		Killer:     NewKiller(runcCmdRunner, runc),
 		Deleter:    NewDeleter(runcCmdRunner, runc),
+		Updater:    NewUpdater(runcCmdRunner, runc, cgroupPaths),
 	}
 }
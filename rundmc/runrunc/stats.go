@@ -26,11 +26,18 @@ type runcStats struct {
 			} `json:"usage"`
 		} `json:"cpu"`
 		MemoryStats struct {
-			Stats garden.ContainerMemoryStat `json:"raw"`
+			Raw   json.RawMessage `json:"raw"`
+			Usage memoryEntry     `json:"usage"`
+			Swap  memoryEntry     `json:"swap"`
 		} `json:"memory"`
 	}
 }
 
+type memoryEntry struct {
+	Limit uint64 `json:"limit"`
+	Usage uint64 `json:"usage"`
+}
+
 type Statser struct {
 	runner RuncCmdRunner
 	runc   RuncBinary
@@ -58,8 +65,13 @@ func (r *Statser) Stats(log lager.Logger, id string) (gardener.ActualContainerMe
 		return gardener.ActualContainerMetrics{}, fmt.Errorf("decode stats: %s", err)
 	}
 
+	memory, err := memoryStat(data)
+	if err != nil {
+		return gardener.ActualContainerMetrics{}, fmt.Errorf("decode stats: %s", err)
+	}
+
 	stats := gardener.ActualContainerMetrics{
-		Memory: data.Data.MemoryStats.Stats,
+		Memory: memory,
 		CPU: garden.ContainerCPUStat{
 			Usage:  data.Data.CPUStats.CPUUsage.Usage,
 			System: data.Data.CPUStats.CPUUsage.System,
@@ -71,3 +83,45 @@ func (r *Statser) Stats(log lager.Logger, id string) (gardener.ActualContainerMe
 
 	return stats, nil
 }
+
+func memoryStat(data runcStats) (garden.ContainerMemoryStat, error) {
+	var stat garden.ContainerMemoryStat
+	if len(data.Data.MemoryStats.Raw) == 0 {
+		return stat, nil
+	}
+
+	var raw map[string]uint64
+	if err := json.Unmarshal(data.Data.MemoryStats.Raw, &raw); err != nil {
+		return stat, err
+	}
+
+	if _, ok := raw["anon"]; !ok {
+		err := json.Unmarshal(data.Data.MemoryStats.Raw, &stat)
+		return stat, err
+	}
+
+	// the unified (v2) hierarchy has no cgroup v1 memory.stat keys and counts
+	// nested cgroups in every value, so the totals equal the plain values
+	stat.Rss, stat.TotalRss = raw["anon"], raw["anon"]
+	stat.Cache, stat.TotalCache = raw["file"], raw["file"]
+	stat.ActiveAnon, stat.TotalActiveAnon = raw["active_anon"], raw["active_anon"]
+	stat.InactiveAnon, stat.TotalInactiveAnon = raw["inactive_anon"], raw["inactive_anon"]
+	stat.ActiveFile, stat.TotalActiveFile = raw["active_file"], raw["active_file"]
+	stat.InactiveFile, stat.TotalInactiveFile = raw["inactive_file"], raw["inactive_file"]
+	stat.MappedFile, stat.TotalMappedFile = raw["file_mapped"], raw["file_mapped"]
+	stat.Pgfault, stat.TotalPgfault = raw["pgfault"], raw["pgfault"]
+	stat.Pgmajfault, stat.TotalPgmajfault = raw["pgmajfault"], raw["pgmajfault"]
+	stat.Unevictable, stat.TotalUnevictable = raw["unevictable"], raw["unevictable"]
+
+	usage, swap := data.Data.MemoryStats.Usage, data.Data.MemoryStats.Swap
+	stat.HierarchicalMemoryLimit = usage.Limit
+	stat.HierarchicalMemswLimit = swap.Limit
+
+	// runc reports swap as memory plus swap, as on cgroup v1
+	if swap.Usage > usage.Usage {
+		stat.Swap = swap.Usage - usage.Usage
+		stat.TotalSwap = stat.Swap
+	}
+
+	return stat, nil
+}
@@ -160,6 +160,96 @@ var _ = Describe("Stats", func() {
 
 	})
 
+	Context("when runC reports stats from the unified cgroup hierarchy", func() {
+		BeforeEach(func() {
+			commandRunner.WhenRunning(fake_command_runner.CommandSpec{
+				Path: "funC-stats",
+			}, func(cmd *exec.Cmd) error {
+				cmd.Stdout.Write([]byte(`{
+					"type": "stats",
+					"data": {
+						"memory": {
+							"usage": {
+								"limit": 1000,
+								"usage": 100
+							},
+							"swap": {
+								"limit": 2000,
+								"usage": 130
+							},
+							"raw": {
+								"anon": 1,
+								"file": 20,
+								"active_anon": 3,
+								"inactive_anon": 4,
+								"active_file": 5,
+								"inactive_file": 6,
+								"file_mapped": 7,
+								"pgfault": 8,
+								"pgmajfault": 9,
+								"unevictable": 10,
+								"kernel_stack": 11
+							}
+						}
+					}
+				}`))
+
+				return nil
+			})
+		})
+
+		It("maps the memory stats onto their cgroup v1 equivalents", func() {
+			stats, err := statser.Stats(logger, "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(stats.Memory).To(Equal(garden.ContainerMemoryStat{
+				Rss:                     1,
+				TotalRss:                1,
+				Cache:                   20,
+				TotalCache:              20,
+				ActiveAnon:              3,
+				TotalActiveAnon:         3,
+				InactiveAnon:            4,
+				TotalInactiveAnon:       4,
+				ActiveFile:              5,
+				TotalActiveFile:         5,
+				InactiveFile:            6,
+				TotalInactiveFile:       6,
+				MappedFile:              7,
+				TotalMappedFile:         7,
+				Pgfault:                 8,
+				TotalPgfault:            8,
+				Pgmajfault:              9,
+				TotalPgmajfault:         9,
+				Unevictable:             10,
+				TotalUnevictable:        10,
+				HierarchicalMemoryLimit: 1000,
+				HierarchicalMemswLimit:  2000,
+				Swap:                    30,
+				TotalSwap:               30,
+				TotalUsageTowardLimit:   15,
+			}))
+		})
+	})
+
+	Context("when runC reports no memory stats", func() {
+		BeforeEach(func() {
+			commandRunner.WhenRunning(fake_command_runner.CommandSpec{
+				Path: "funC-stats",
+			}, func(cmd *exec.Cmd) error {
+				cmd.Stdout.Write([]byte(`{"type": "stats", "data": {}}`))
+
+				return nil
+			})
+		})
+
+		It("returns empty memory stats", func() {
+			stats, err := statser.Stats(logger, "some-handle")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats.Memory).To(Equal(garden.ContainerMemoryStat{}))
+		})
+	})
+
 	Context("when runC reports invalid JSON", func() {
 		BeforeEach(func() {
 			commandRunner.WhenRunning(fake_command_runner.CommandSpec{
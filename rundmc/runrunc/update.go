@@ -0,0 +1,139 @@
+package runrunc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"code.cloudfoundry.org/lager"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+//go:generate counterfeiter . CgroupPathResolver
+
+type CgroupPathResolver interface {
+	Resolve(cgroupName, subsystem string) (string, error)
+}
+
+type Updater struct {
+	runner      RuncCmdRunner
+	runc        RuncBinary
+	cgroupPaths CgroupPathResolver
+}
+
+func NewUpdater(runner RuncCmdRunner, runc RuncBinary, cgroupPaths CgroupPathResolver) *Updater {
+	return &Updater{
+		runner:      runner,
+		runc:        runc,
+		cgroupPaths: cgroupPaths,
+	}
+}
+
+// UpdateResources updates the cgroup resources of a running container using
+// 'runc update', which reads them from stdin. 'runc update' applies only the
+// weight of the block I/O resources, so their throttles are written to the
+// container's cgroup directly. A throttle with a rate of 0 removes the
+// device's throttle.
+func (u *Updater) UpdateResources(log lager.Logger, handle string, resources specs.LinuxResources) error {
+	log = log.Session("update", lager.Data{"handle": handle})
+
+	log.Info("started")
+	defer log.Info("finished")
+
+	resourcesJSON, err := json.Marshal(resources)
+	if err != nil {
+		return err
+	}
+
+	if err := u.runner.RunAndLog(log, func(logFile string) *exec.Cmd {
+		cmd := u.runc.UpdateCommand(handle, "-", logFile)
+		cmd.Stdin = bytes.NewReader(resourcesJSON)
+		return cmd
+	}); err != nil {
+		return err
+	}
+
+	if resources.BlockIO == nil {
+		return nil
+	}
+
+	return u.throttle(log, handle, *resources.BlockIO)
+}
+
+type blkioThrottle struct {
+	file    string // in the blkio hierarchy
+	key     string // of the io.max file in the unified hierarchy
+	devices []specs.LinuxThrottleDevice
+}
+
+func (u *Updater) throttle(log lager.Logger, handle string, blockIO specs.LinuxBlockIO) error {
+	throttles := []blkioThrottle{
+		{"blkio.throttle.read_bps_device", "rbps", blockIO.ThrottleReadBpsDevice},
+		{"blkio.throttle.write_bps_device", "wbps", blockIO.ThrottleWriteBpsDevice},
+		{"blkio.throttle.read_iops_device", "riops", blockIO.ThrottleReadIOPSDevice},
+		{"blkio.throttle.write_iops_device", "wiops", blockIO.ThrottleWriteIOPSDevice},
+	}
+
+	throttled := false
+	for _, throttle := range throttles {
+		throttled = throttled || len(throttle.devices) > 0
+	}
+
+	if !throttled {
+		return nil
+	}
+
+	cgroupPath, err := u.cgroupPaths.Resolve(handle, "blkio")
+	if err != nil {
+		log.Error("resolve-cgroup-failed", err)
+		return err
+	}
+
+	// the unified hierarchy has no blkio files, and takes every throttle in io.max
+	_, err = os.Stat(filepath.Join(cgroupPath, throttles[0].file))
+	unified := os.IsNotExist(err)
+
+	for _, throttle := range throttles {
+		for _, device := range throttle.devices {
+			path := filepath.Join(cgroupPath, throttle.file)
+			value := fmt.Sprintf("%d:%d %d", device.Major, device.Minor, device.Rate)
+
+			if unified {
+				rate := "max"
+				if device.Rate != 0 {
+					rate = strconv.FormatUint(device.Rate, 10)
+				}
+
+				path = filepath.Join(cgroupPath, "io.max")
+				value = fmt.Sprintf("%d:%d %s=%s", device.Major, device.Minor, throttle.key, rate)
+			}
+
+			if err := writeCgroupFile(path, value); err != nil {
+				log.Error("write-throttle-failed", err, lager.Data{"path": path, "value": value})
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeCgroupFile writes a single value to a cgroup file, which takes one
+// entry per write
+func writeCgroupFile(path, value string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(value); err != nil {
+		return fmt.Errorf("writing '%s' to %s: %s", value, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package runrunc_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"code.cloudfoundry.org/guardian/rundmc/runrunc"
+	fakes "code.cloudfoundry.org/guardian/rundmc/runrunc/runruncfakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+	"github.com/cloudfoundry/gunk/command_runner/fake_command_runner"
+	. "github.com/cloudfoundry/gunk/command_runner/fake_command_runner/matchers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var _ = Describe("Update", func() {
+	var (
+		commandRunner *fake_command_runner.FakeCommandRunner
+		runner        *fakes.FakeRuncCmdRunner
+		runcBinary    *fakes.FakeRuncBinary
+		cgroupPaths   *fakes.FakeCgroupPathResolver
+		logger        *lagertest.TestLogger
+		stdin         []byte
+
+		updater *runrunc.Updater
+	)
+
+	BeforeEach(func() {
+		runcBinary = new(fakes.FakeRuncBinary)
+		commandRunner = fake_command_runner.New()
+		runner = new(fakes.FakeRuncCmdRunner)
+		cgroupPaths = new(fakes.FakeCgroupPathResolver)
+		logger = lagertest.NewTestLogger("test")
+
+		updater = runrunc.NewUpdater(runner, runcBinary, cgroupPaths)
+
+		runcBinary.UpdateCommandStub = func(id, resourcesPath, logFile string) *exec.Cmd {
+			return exec.Command("funC", "--log", logFile, "update", "-r", resourcesPath, id)
+		}
+
+		runner.RunAndLogStub = func(_ lager.Logger, fn runrunc.LoggingCmd) error {
+			cmd := fn("potato.log")
+
+			var err error
+			stdin, err = ioutil.ReadAll(cmd.Stdin)
+			Expect(err).NotTo(HaveOccurred())
+
+			return commandRunner.Run(cmd)
+		}
+	})
+
+	It("runs 'runc update' using the logging runner", func() {
+		Expect(updater.UpdateResources(logger, "some-container", specs.LinuxResources{})).To(Succeed())
+		Expect(commandRunner).To(HaveExecutedSerially(fake_command_runner.CommandSpec{
+			Path: "funC",
+			Args: []string{"--log", "potato.log", "update", "-r", "-", "some-container"},
+		}))
+	})
+
+	It("passes the resources as JSON on stdin", func() {
+		var weight uint16 = 500
+		Expect(updater.UpdateResources(logger, "some-container", specs.LinuxResources{
+			BlockIO: &specs.LinuxBlockIO{Weight: &weight},
+		})).To(Succeed())
+
+		Expect(stdin).To(MatchJSON(`{"blockIO": {"weight": 500}}`))
+	})
+
+	Context("when runc update fails", func() {
+		It("returns the error", func() {
+			runner.RunAndLogStub = nil
+			runner.RunAndLogReturns(errors.New("banana"))
+			Expect(updater.UpdateResources(logger, "some-container", specs.LinuxResources{})).To(MatchError("banana"))
+		})
+	})
+
+	Describe("block I/O throttles", func() {
+		var (
+			cgroupPath string
+			resources  specs.LinuxResources
+		)
+
+		throttleDevice := func(major, minor int64, rate uint64) specs.LinuxThrottleDevice {
+			device := specs.LinuxThrottleDevice{Rate: rate}
+			device.Major, device.Minor = major, minor
+			return device
+		}
+
+		cgroupFile := func(name string) string {
+			contents, err := ioutil.ReadFile(filepath.Join(cgroupPath, name))
+			Expect(err).NotTo(HaveOccurred())
+			return string(contents)
+		}
+
+		BeforeEach(func() {
+			var err error
+			cgroupPath, err = ioutil.TempDir("", "cgroup")
+			Expect(err).NotTo(HaveOccurred())
+			cgroupPaths.ResolveReturns(cgroupPath, nil)
+
+			for _, name := range []string{"blkio.throttle.read_bps_device", "blkio.throttle.write_bps_device", "blkio.throttle.read_iops_device", "blkio.throttle.write_iops_device"} {
+				Expect(ioutil.WriteFile(filepath.Join(cgroupPath, name), nil, 0644)).To(Succeed())
+			}
+
+			resources = specs.LinuxResources{BlockIO: &specs.LinuxBlockIO{
+				ThrottleReadBpsDevice:   []specs.LinuxThrottleDevice{throttleDevice(8, 0, 1048576)},
+				ThrottleWriteBpsDevice:  []specs.LinuxThrottleDevice{throttleDevice(8, 16, 2097152)},
+				ThrottleReadIOPSDevice:  []specs.LinuxThrottleDevice{throttleDevice(8, 0, 100)},
+				ThrottleWriteIOPSDevice: []specs.LinuxThrottleDevice{throttleDevice(8, 0, 0)},
+			}}
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(cgroupPath)).To(Succeed())
+		})
+
+		It("writes them to the container's blkio cgroup", func() {
+			Expect(updater.UpdateResources(logger, "some-container", resources)).To(Succeed())
+
+			Expect(cgroupPaths.ResolveCallCount()).To(Equal(1))
+			name, subsystem := cgroupPaths.ResolveArgsForCall(0)
+			Expect(name).To(Equal("some-container"))
+			Expect(subsystem).To(Equal("blkio"))
+
+			Expect(cgroupFile("blkio.throttle.read_bps_device")).To(Equal("8:0 1048576"))
+			Expect(cgroupFile("blkio.throttle.write_bps_device")).To(Equal("8:16 2097152"))
+			Expect(cgroupFile("blkio.throttle.read_iops_device")).To(Equal("8:0 100"))
+		})
+
+		It("removes a throttle with a rate of 0", func() {
+			Expect(updater.UpdateResources(logger, "some-container", resources)).To(Succeed())
+			Expect(cgroupFile("blkio.throttle.write_iops_device")).To(Equal("8:0 0"))
+		})
+
+		Context("when the container is in the unified cgroup hierarchy", func() {
+			BeforeEach(func() {
+				Expect(os.RemoveAll(cgroupPath)).To(Succeed())
+				Expect(os.MkdirAll(cgroupPath, 0755)).To(Succeed())
+				Expect(ioutil.WriteFile(filepath.Join(cgroupPath, "io.max"), nil, 0644)).To(Succeed())
+
+				resources.BlockIO.ThrottleReadBpsDevice = nil
+				resources.BlockIO.ThrottleWriteBpsDevice = nil
+				resources.BlockIO.ThrottleReadIOPSDevice = nil
+			})
+
+			It("writes them to io.max", func() {
+				resources.BlockIO.ThrottleWriteIOPSDevice = []specs.LinuxThrottleDevice{throttleDevice(8, 0, 100)}
+
+				Expect(updater.UpdateResources(logger, "some-container", resources)).To(Succeed())
+				Expect(cgroupFile("io.max")).To(Equal("8:0 wiops=100"))
+			})
+
+			It("removes a throttle with a rate of 0", func() {
+				Expect(updater.UpdateResources(logger, "some-container", resources)).To(Succeed())
+				Expect(cgroupFile("io.max")).To(Equal("8:0 wiops=max"))
+			})
+		})
+
+		Context("when there are no throttles", func() {
+			It("does not look up the container's cgroup", func() {
+				var weight uint16 = 500
+				Expect(updater.UpdateResources(logger, "some-container", specs.LinuxResources{
+					BlockIO: &specs.LinuxBlockIO{Weight: &weight},
+				})).To(Succeed())
+
+				Expect(cgroupPaths.ResolveCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when runc update fails", func() {
+			It("does not write the throttles", func() {
+				runner.RunAndLogStub = nil
+				runner.RunAndLogReturns(errors.New("banana"))
+
+				Expect(updater.UpdateResources(logger, "some-container", resources)).To(MatchError("banana"))
+				Expect(cgroupFile("blkio.throttle.read_bps_device")).To(BeEmpty())
+			})
+		})
+
+		Context("when the cgroup cannot be resolved", func() {
+			It("returns the error", func() {
+				cgroupPaths.ResolveReturns("", errors.New("no-state"))
+				Expect(updater.UpdateResources(logger, "some-container", resources)).To(MatchError("no-state"))
+			})
+		})
+
+		Context("when a throttle cannot be written", func() {
+			It("returns the error", func() {
+				Expect(os.Remove(filepath.Join(cgroupPath, "blkio.throttle.write_bps_device"))).To(Succeed())
+				Expect(updater.UpdateResources(logger, "some-container", resources)).NotTo(Succeed())
+			})
+		})
+	})
+})
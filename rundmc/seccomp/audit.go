@@ -0,0 +1,139 @@
+package seccomp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// ActLog allows a syscall, but has the kernel write an audit record of it. It
+// needs libseccomp 2.4 and a runc which knows it.
+const ActLog specs.LinuxSeccompAction = "SCMP_ACT_LOG"
+
+// Audit returns a copy of a seccomp configuration in which every syscall
+// which would be denied is allowed and logged instead, so that the syscalls
+// a workload needs can be discovered
+func Audit(config *specs.LinuxSeccomp) *specs.LinuxSeccomp {
+	audited := *config
+	audited.DefaultAction = auditAction(config.DefaultAction)
+
+	audited.Syscalls = make([]specs.LinuxSyscall, len(config.Syscalls))
+	for i, syscall := range config.Syscalls {
+		syscall.Action = auditAction(syscall.Action)
+		audited.Syscalls[i] = syscall
+	}
+
+	return &audited
+}
+
+func auditAction(action specs.LinuxSeccompAction) specs.LinuxSeccompAction {
+	if action == specs.ActAllow {
+		return action
+	}
+
+	return ActLog
+}
+
+// AUDIT_SECCOMP, see linux/audit.h
+const auditSeccompType = 1326
+
+// audit architecture tokens, see linux/audit.h
+var auditArches = map[string]string{
+	"c000003e": "x86_64",
+	"40000003": "i386",
+	"c00000b7": "aarch64",
+	"40000028": "arm",
+	"c0000015": "ppc64le",
+	"80000015": "ppc64",
+	"80000016": "s390x",
+	"16":       "s390",
+}
+
+// AuditRecord describes a syscall which the kernel logged because of a
+// container's seccomp configuration
+type AuditRecord struct {
+	Pid     int
+	Comm    string
+	Exe     string
+	Arch    string
+	Syscall int
+}
+
+// ParseAuditRecord parses the text of an AUDIT_SECCOMP record, e.g.
+// audit(1490000000.123:42): auid=4294967295 uid=0 gid=0 ses=4294967295 pid=1234 comm="mkdir" exe="/bin/mkdir" sig=0 arch=c000003e syscall=83 compat=0 ip=0x7f2ab3d0 code=0x7ffc0000
+func ParseAuditRecord(text string) (AuditRecord, error) {
+	fields := auditFields(text)
+
+	pid, err := strconv.Atoi(fields["pid"])
+	if err != nil {
+		return AuditRecord{}, fmt.Errorf("audit: invalid pid: '%s'", fields["pid"])
+	}
+
+	syscall, err := strconv.Atoi(fields["syscall"])
+	if err != nil {
+		return AuditRecord{}, fmt.Errorf("audit: invalid syscall: '%s'", fields["syscall"])
+	}
+
+	if fields["arch"] == "" {
+		return AuditRecord{}, errors.New("audit: missing arch")
+	}
+
+	arch, ok := auditArches[fields["arch"]]
+	if !ok {
+		arch = fields["arch"]
+	}
+
+	return AuditRecord{
+		Pid:     pid,
+		Comm:    fields["comm"],
+		Exe:     fields["exe"],
+		Arch:    arch,
+		Syscall: syscall,
+	}, nil
+}
+
+// auditFields splits the key=value fields of an audit record, unquoting
+// quoted values
+func auditFields(text string) map[string]string {
+	fields := map[string]string{}
+
+	for len(text) > 0 {
+		text = strings.TrimLeft(text, " ")
+
+		eq := strings.IndexAny(text, "= ")
+		if eq < 0 {
+			break
+		}
+
+		if text[eq] == ' ' {
+			text = text[eq:]
+			continue
+		}
+
+		key := text[:eq]
+		text = text[eq+1:]
+
+		var value string
+		if strings.HasPrefix(text, `"`) {
+			end := strings.Index(text[1:], `"`)
+			if end < 0 {
+				value, text = text[1:], ""
+			} else {
+				value, text = text[1:end+1], text[end+2:]
+			}
+		} else {
+			end := strings.Index(text, " ")
+			if end < 0 {
+				end = len(text)
+			}
+			value, text = text[:end], text[end:]
+		}
+
+		fields[key] = value
+	}
+
+	return fields
+}
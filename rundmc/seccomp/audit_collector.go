@@ -0,0 +1,225 @@
+package seccomp
+
+import (
+	"sort"
+	"sync"
+
+	"code.cloudfoundry.org/lager"
+)
+
+//go:generate counterfeiter . AuditSource
+
+type AuditSource interface {
+	Next() (AuditRecord, error)
+	Close() error
+}
+
+//go:generate counterfeiter . HandleResolver
+
+// HandleResolver finds the container a process belongs to
+type HandleResolver interface {
+	Handle(pid int) (string, error)
+}
+
+// AuditedSyscall counts the times containers made a syscall which their
+// seccomp configuration would otherwise deny. Exe is the executable which
+// made it first.
+type AuditedSyscall struct {
+	Arch    string `json:"arch"`
+	Syscall int    `json:"syscall"`
+	Exe     string `json:"exe"`
+	Count   uint64 `json:"count"`
+}
+
+type auditKey struct {
+	arch    string
+	syscall int
+}
+
+// the number of processes whose containers are remembered, so that processes
+// making many syscalls are not resolved every time
+const maxCachedPids = 1024
+
+// a process whose container has been resolved. The executable tells when the
+// pid has been reused by another process, which may be in another container.
+type cachedPid struct {
+	handle string
+	exe    string
+}
+
+// AuditCollector reports the syscalls logged by containers in seccomp audit
+// mode, logging each distinct syscall of a container once and counting them
+type AuditCollector struct {
+	source   AuditSource
+	resolver HandleResolver
+	logger   lager.Logger
+
+	mu      sync.Mutex
+	audited map[string]map[auditKey]*AuditedSyscall
+	pids    map[int]cachedPid
+
+	// incremented whenever a container is forgotten, so that a handle resolved
+	// meanwhile is resolved again
+	forgotten uint64
+}
+
+func NewAuditCollector(logger lager.Logger, source AuditSource, resolver HandleResolver) *AuditCollector {
+	return &AuditCollector{
+		source:   source,
+		resolver: resolver,
+		logger:   logger.Session("seccomp-audit-collector"),
+		audited:  make(map[string]map[auditKey]*AuditedSyscall),
+		pids:     make(map[int]cachedPid),
+	}
+}
+
+func (c *AuditCollector) Start() {
+	c.logger.Info("starting")
+	go c.run()
+}
+
+func (c *AuditCollector) Stop() error {
+	return c.source.Close()
+}
+
+func (c *AuditCollector) run() {
+	defer c.logger.Info("finished")
+
+	for {
+		record, err := c.source.Next()
+		if err != nil {
+			c.logger.Error("reading-record-failed", err)
+			return
+		}
+
+		c.collect(record)
+	}
+}
+
+func (c *AuditCollector) collect(record AuditRecord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	handle, ok := c.cachedHandle(record)
+	for !ok {
+		// resolving reads /proc, so is done without holding the lock
+		forgotten := c.forgotten
+		c.mu.Unlock()
+		resolved, err := c.resolver.Handle(record.Pid)
+		c.mu.Lock()
+
+		if err != nil {
+			// the process may have exited, or not be in a container
+			c.logger.Info("resolving-handle-failed", lager.Data{"pid": record.Pid, "error": err.Error()})
+			return
+		}
+
+		if c.forgotten != forgotten {
+			// the container may have been destroyed while resolving
+			continue
+		}
+
+		if len(c.pids) >= maxCachedPids {
+			c.pids = make(map[int]cachedPid)
+		}
+		c.pids[record.Pid] = cachedPid{handle: resolved, exe: record.Exe}
+		handle, ok = resolved, true
+	}
+
+	syscalls, ok := c.audited[handle]
+	if !ok {
+		syscalls = make(map[auditKey]*AuditedSyscall)
+		c.audited[handle] = syscalls
+	}
+
+	key := auditKey{arch: record.Arch, syscall: record.Syscall}
+	if audited, ok := syscalls[key]; ok {
+		audited.Count++
+		return
+	}
+
+	syscalls[key] = &AuditedSyscall{Arch: record.Arch, Syscall: record.Syscall, Exe: record.Exe, Count: 1}
+	c.logger.Info("syscall-audited", lager.Data{
+		"handle":  handle,
+		"pid":     record.Pid,
+		"comm":    record.Comm,
+		"exe":     record.Exe,
+		"arch":    record.Arch,
+		"syscall": record.Syscall,
+	})
+}
+
+// cachedHandle returns the container of the process which made a syscall, if
+// it is known. A cached process running another executable may be a new
+// process with the same pid, so is forgotten. Must be called with the lock
+// held.
+func (c *AuditCollector) cachedHandle(record AuditRecord) (string, bool) {
+	cached, ok := c.pids[record.Pid]
+	if !ok {
+		return "", false
+	}
+
+	if cached.exe != record.Exe {
+		delete(c.pids, record.Pid)
+		return "", false
+	}
+
+	return cached.handle, true
+}
+
+// Forget discards the syscalls audited in a destroyed container, and the
+// container's processes
+func (c *AuditCollector) Forget(handle string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.audited, handle)
+	for pid, cached := range c.pids {
+		if cached.handle == handle {
+			delete(c.pids, pid)
+		}
+	}
+
+	c.forgotten++
+}
+
+// Audited returns the syscalls audited in the given container, ordered by
+// architecture and syscall number
+func (c *AuditCollector) Audited(handle string) []AuditedSyscall {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	audited := []AuditedSyscall{}
+	for _, syscall := range c.audited[handle] {
+		audited = append(audited, *syscall)
+	}
+
+	sort.Sort(auditedSyscalls(audited))
+
+	return audited
+}
+
+type auditedSyscalls []AuditedSyscall
+
+func (s auditedSyscalls) Len() int      { return len(s) }
+func (s auditedSyscalls) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s auditedSyscalls) Less(i, j int) bool {
+	if s[i].Arch != s[j].Arch {
+		return s[i].Arch < s[j].Arch
+	}
+	return s[i].Syscall < s[j].Syscall
+}
+
+// Handles returns the containers which have made audited syscalls
+func (c *AuditCollector) Handles() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	handles := []string{}
+	for handle := range c.audited {
+		handles = append(handles, handle)
+	}
+	sort.Strings(handles)
+
+	return handles
+}
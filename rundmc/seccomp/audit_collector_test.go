@@ -0,0 +1,183 @@
+package seccomp_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/guardian/rundmc/seccomp"
+	"code.cloudfoundry.org/guardian/rundmc/seccomp/seccompfakes"
+	"code.cloudfoundry.org/lager"
+	"code.cloudfoundry.org/lager/lagertest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuditCollector", func() {
+	var (
+		fakeSource   *seccompfakes.FakeAuditSource
+		fakeResolver *seccompfakes.FakeHandleResolver
+		logger       *lagertest.TestLogger
+		records      []seccomp.AuditRecord
+		collector    *seccomp.AuditCollector
+	)
+
+	BeforeEach(func() {
+		fakeSource = new(seccompfakes.FakeAuditSource)
+		fakeResolver = new(seccompfakes.FakeHandleResolver)
+		logger = lagertest.NewTestLogger("test")
+
+		records = []seccomp.AuditRecord{
+			{Pid: 10, Comm: "mkdir", Exe: "/bin/mkdir", Arch: "x86_64", Syscall: 83},
+			{Pid: 10, Comm: "mkdir", Exe: "/bin/mkdir", Arch: "x86_64", Syscall: 83},
+			{Pid: 11, Comm: "strace", Exe: "/usr/bin/strace", Arch: "x86_64", Syscall: 101},
+			{Pid: 20, Comm: "mkdir", Exe: "/bin/mkdir", Arch: "x86_64", Syscall: 83},
+		}
+
+		fakeResolver.HandleStub = func(pid int) (string, error) {
+			switch pid {
+			case 10, 11:
+				return "handle-a", nil
+			case 20:
+				return "handle-b", nil
+			}
+			return "", errors.New("not in a container")
+		}
+	})
+
+	JustBeforeEach(func() {
+		remaining := records
+		fakeSource.NextStub = func() (seccomp.AuditRecord, error) {
+			if len(remaining) == 0 {
+				return seccomp.AuditRecord{}, errors.New("closed")
+			}
+			record := remaining[0]
+			remaining = remaining[1:]
+			return record, nil
+		}
+
+		collector = seccomp.NewAuditCollector(logger, fakeSource, fakeResolver)
+		collector.Start()
+		Eventually(logger.LogMessages).Should(ContainElement("test.seccomp-audit-collector.finished"))
+	})
+
+	auditLogs := func() []lager.LogFormat {
+		var logs []lager.LogFormat
+		for _, log := range logger.Logs() {
+			if log.Message == "test.seccomp-audit-collector.syscall-audited" {
+				logs = append(logs, log)
+			}
+		}
+		return logs
+	}
+
+	It("logs each distinct syscall of a container once", func() {
+		Expect(auditLogs()).To(HaveLen(3))
+		Expect(auditLogs()[0].Data).To(Equal(lager.Data{
+			"session": "1",
+			"handle":  "handle-a",
+			"pid":     float64(10),
+			"comm":    "mkdir",
+			"exe":     "/bin/mkdir",
+			"arch":    "x86_64",
+			"syscall": float64(83),
+		}))
+	})
+
+	It("counts the audited syscalls of each container", func() {
+		Expect(collector.Audited("handle-a")).To(Equal([]seccomp.AuditedSyscall{
+			{Arch: "x86_64", Syscall: 83, Exe: "/bin/mkdir", Count: 2},
+			{Arch: "x86_64", Syscall: 101, Exe: "/usr/bin/strace", Count: 1},
+		}))
+		Expect(collector.Audited("handle-b")).To(Equal([]seccomp.AuditedSyscall{
+			{Arch: "x86_64", Syscall: 83, Exe: "/bin/mkdir", Count: 1},
+		}))
+	})
+
+	It("lists the containers with audited syscalls", func() {
+		Expect(collector.Handles()).To(Equal([]string{"handle-a", "handle-b"}))
+	})
+
+	It("returns no syscalls for other containers", func() {
+		Expect(collector.Audited("handle-c")).To(BeEmpty())
+	})
+
+	It("resolves the container of each process once", func() {
+		Expect(fakeResolver.HandleCallCount()).To(Equal(3))
+	})
+
+	It("logs the error when the source fails", func() {
+		Expect(logger.LogMessages()).To(ContainElement("test.seccomp-audit-collector.reading-record-failed"))
+	})
+
+	Context("when the container of a process cannot be found", func() {
+		BeforeEach(func() {
+			records = []seccomp.AuditRecord{{Pid: 30, Arch: "x86_64", Syscall: 83}}
+		})
+
+		It("skips the record", func() {
+			Expect(collector.Handles()).To(BeEmpty())
+			Expect(logger.LogMessages()).To(ContainElement("test.seccomp-audit-collector.resolving-handle-failed"))
+		})
+	})
+
+	Context("when a pid is reused by a process in another container", func() {
+		BeforeEach(func() {
+			records = []seccomp.AuditRecord{
+				{Pid: 10, Comm: "mkdir", Exe: "/bin/mkdir", Arch: "x86_64", Syscall: 83},
+				{Pid: 10, Comm: "ls", Exe: "/bin/ls", Arch: "x86_64", Syscall: 83},
+			}
+
+			resolved := []string{"handle-a", "handle-b"}
+			fakeResolver.HandleStub = func(pid int) (string, error) {
+				handle := resolved[0]
+				resolved = resolved[1:]
+				return handle, nil
+			}
+		})
+
+		It("resolves the container of the new process", func() {
+			Expect(fakeResolver.HandleCallCount()).To(Equal(2))
+			Expect(collector.Audited("handle-a")).To(Equal([]seccomp.AuditedSyscall{
+				{Arch: "x86_64", Syscall: 83, Exe: "/bin/mkdir", Count: 1},
+			}))
+			Expect(collector.Audited("handle-b")).To(Equal([]seccomp.AuditedSyscall{
+				{Arch: "x86_64", Syscall: 83, Exe: "/bin/ls", Count: 1},
+			}))
+		})
+	})
+
+	Context("while resolving the container of a process", func() {
+		BeforeEach(func() {
+			records = []seccomp.AuditRecord{{Pid: 10, Comm: "mkdir", Exe: "/bin/mkdir", Arch: "x86_64", Syscall: 83}}
+
+			fakeResolver.HandleStub = func(pid int) (string, error) {
+				if fakeResolver.HandleCallCount() == 1 {
+					// would deadlock if the collector held its lock
+					collector.Forget("handle-a")
+				}
+				return "handle-a", nil
+			}
+		})
+
+		It("resolves it again if a container is forgotten meanwhile", func() {
+			Expect(fakeResolver.HandleCallCount()).To(Equal(2))
+			Expect(collector.Audited("handle-a")).To(HaveLen(1))
+		})
+	})
+
+	Describe("Forget", func() {
+		It("discards the audited syscalls of the container", func() {
+			collector.Forget("handle-a")
+
+			Expect(collector.Audited("handle-a")).To(BeEmpty())
+			Expect(collector.Handles()).To(Equal([]string{"handle-b"}))
+		})
+	})
+
+	Describe("Stop", func() {
+		It("closes the source", func() {
+			Expect(collector.Stop()).To(Succeed())
+			Expect(fakeSource.CloseCallCount()).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,83 @@
+package seccomp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// AUDIT_NLGRP_READLOG, see linux/audit.h
+const auditNlgrpReadlog = 1
+
+// netlink headers are in host byte order
+var nativeEndian binary.ByteOrder = binary.LittleEndian
+
+func init() {
+	i := uint16(1)
+	if (*[2]byte)(unsafe.Pointer(&i))[0] == 0 {
+		nativeEndian = binary.BigEndian
+	}
+}
+
+// AuditSocket reads the seccomp records which the kernel multicasts to audit
+// log readers. This needs CAP_AUDIT_READ, and works whether or not auditd is
+// running.
+type AuditSocket struct {
+	fd  int
+	buf []byte
+}
+
+// ListenAudit joins the audit log multicast group
+func ListenAudit() (*AuditSocket, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_AUDIT)
+	if err != nil {
+		return nil, fmt.Errorf("audit: create socket: %s", err)
+	}
+
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: 1 << (auditNlgrpReadlog - 1),
+	}); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("audit: bind socket: %s", err)
+	}
+
+	return &AuditSocket{fd: fd, buf: make([]byte, syscall.Getpagesize()*2)}, nil
+}
+
+func (s *AuditSocket) Next() (AuditRecord, error) {
+	for {
+		n, _, err := syscall.Recvfrom(s.fd, s.buf, 0)
+		if err == syscall.ENOBUFS || err == syscall.EINTR {
+			// the kernel dropped records because we were too slow, carry on
+			continue
+		}
+		if err != nil {
+			return AuditRecord{}, fmt.Errorf("audit: receive: %s", err)
+		}
+
+		// each datagram holds a single record. Its length is not taken from
+		// the header, which some kernels fill in with the payload's length.
+		if n < syscall.NLMSG_HDRLEN {
+			continue
+		}
+
+		if nativeEndian.Uint16(s.buf[4:6]) != auditSeccompType {
+			continue
+		}
+
+		text := bytes.TrimRight(s.buf[syscall.NLMSG_HDRLEN:n], "\x00\n")
+		record, err := ParseAuditRecord(string(text))
+		if err != nil {
+			continue
+		}
+
+		return record, nil
+	}
+}
+
+func (s *AuditSocket) Close() error {
+	return syscall.Close(s.fd)
+}
@@ -0,0 +1,19 @@
+// +build !linux
+
+package seccomp
+
+import "errors"
+
+type AuditSocket struct{}
+
+func ListenAudit() (*AuditSocket, error) {
+	return nil, errors.New("audit: not supported on this platform")
+}
+
+func (s *AuditSocket) Next() (AuditRecord, error) {
+	return AuditRecord{}, errors.New("audit: not supported on this platform")
+}
+
+func (s *AuditSocket) Close() error {
+	return nil
+}
@@ -0,0 +1,98 @@
+package seccomp_test
+
+import (
+	"code.cloudfoundry.org/guardian/rundmc/seccomp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var _ = Describe("Audit", func() {
+	var config *specs.LinuxSeccomp
+
+	BeforeEach(func() {
+		config = &specs.LinuxSeccomp{
+			DefaultAction: specs.ActErrno,
+			Architectures: []specs.Arch{specs.ArchX86_64},
+			Syscalls: []specs.LinuxSyscall{
+				{Name: "read", Action: specs.ActAllow, Args: []specs.LinuxSeccompArg{}},
+				{Name: "mount", Action: specs.ActKill, Args: []specs.LinuxSeccompArg{}},
+				{Name: "ptrace", Action: specs.ActTrap, Args: []specs.LinuxSeccompArg{}},
+			},
+		}
+	})
+
+	It("logs syscalls which would be denied by default", func() {
+		Expect(seccomp.Audit(config).DefaultAction).To(Equal(seccomp.ActLog))
+	})
+
+	It("logs syscalls which would be denied by a rule", func() {
+		Expect(seccomp.Audit(config).Syscalls).To(Equal([]specs.LinuxSyscall{
+			{Name: "read", Action: specs.ActAllow, Args: []specs.LinuxSeccompArg{}},
+			{Name: "mount", Action: seccomp.ActLog, Args: []specs.LinuxSeccompArg{}},
+			{Name: "ptrace", Action: seccomp.ActLog, Args: []specs.LinuxSeccompArg{}},
+		}))
+	})
+
+	It("keeps the architectures", func() {
+		Expect(seccomp.Audit(config).Architectures).To(Equal([]specs.Arch{specs.ArchX86_64}))
+	})
+
+	It("does not modify the original configuration", func() {
+		seccomp.Audit(config)
+
+		Expect(config.DefaultAction).To(Equal(specs.ActErrno))
+		Expect(config.Syscalls[1].Action).To(Equal(specs.ActKill))
+	})
+})
+
+var _ = Describe("ParseAuditRecord", func() {
+	It("parses a seccomp audit record", func() {
+		record, err := seccomp.ParseAuditRecord(`audit(1490000000.123:42): auid=4294967295 uid=0 gid=0 ses=4294967295 pid=1234 comm="mkdir" exe="/bin/mkdir" sig=0 arch=c000003e syscall=83 compat=0 ip=0x7f2ab3d0 code=0x7ffc0000`)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(record).To(Equal(seccomp.AuditRecord{
+			Pid:     1234,
+			Comm:    "mkdir",
+			Exe:     "/bin/mkdir",
+			Arch:    "x86_64",
+			Syscall: 83,
+		}))
+	})
+
+	It("keeps quoted values containing spaces", func() {
+		record, err := seccomp.ParseAuditRecord(`audit(1490000000.123:42): pid=1 comm="my prog" exe="/bin/my prog" arch=c00000b7 syscall=220`)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(record.Comm).To(Equal("my prog"))
+		Expect(record.Exe).To(Equal("/bin/my prog"))
+		Expect(record.Arch).To(Equal("aarch64"))
+	})
+
+	It("keeps unknown architectures as they are", func() {
+		record, err := seccomp.ParseAuditRecord(`pid=1 arch=deadbeef syscall=1`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(record.Arch).To(Equal("deadbeef"))
+	})
+
+	Context("when the pid is missing", func() {
+		It("returns an error", func() {
+			_, err := seccomp.ParseAuditRecord(`arch=c000003e syscall=83`)
+			Expect(err).To(MatchError("audit: invalid pid: ''"))
+		})
+	})
+
+	Context("when the syscall is not a number", func() {
+		It("returns an error", func() {
+			_, err := seccomp.ParseAuditRecord(`pid=1 arch=c000003e syscall=mkdir`)
+			Expect(err).To(MatchError("audit: invalid syscall: 'mkdir'"))
+		})
+	})
+
+	Context("when the architecture is missing", func() {
+		It("returns an error", func() {
+			_, err := seccomp.ParseAuditRecord(`pid=1 syscall=83`)
+			Expect(err).To(MatchError("audit: missing arch"))
+		})
+	})
+})
@@ -0,0 +1,52 @@
+package seccomp
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CgroupHandleResolver finds the container of a process from its cgroups,
+// whose paths end with the handle of the container
+type CgroupHandleResolver struct {
+	ProcRoot string
+	Handles  func() ([]string, error)
+}
+
+func (r CgroupHandleResolver) Handle(pid int) (string, error) {
+	file, err := os.Open(filepath.Join(r.ProcRoot, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	handles, err := r.Handles()
+	if err != nil {
+		return "", err
+	}
+
+	// lines are hierarchy-ID:controller-list:cgroup-path
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		name := filepath.Base(parts[2])
+		for _, handle := range handles {
+			if handle == name {
+				return handle, nil
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", fmt.Errorf("process %d is not in a container", pid)
+}
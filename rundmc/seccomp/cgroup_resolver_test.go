@@ -0,0 +1,72 @@
+package seccomp_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/guardian/rundmc/seccomp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CgroupHandleResolver", func() {
+	var (
+		procRoot string
+		handles  []string
+		resolver seccomp.CgroupHandleResolver
+	)
+
+	BeforeEach(func() {
+		var err error
+		procRoot, err = ioutil.TempDir("", "proc")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.MkdirAll(filepath.Join(procRoot, "42"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(procRoot, "42", "cgroup"), []byte(
+			"11:memory:/garden/some-handle\n"+
+				"10:cpu,cpuacct:/garden/some-handle\n"+
+				"1:name=systemd:/system.slice/garden.service\n",
+		), 0644)).To(Succeed())
+
+		handles = []string{"other-handle", "some-handle"}
+		resolver = seccomp.CgroupHandleResolver{
+			ProcRoot: procRoot,
+			Handles:  func() ([]string, error) { return handles, nil },
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(procRoot)).To(Succeed())
+	})
+
+	It("returns the container whose handle ends a cgroup path of the process", func() {
+		Expect(resolver.Handle(42)).To(Equal("some-handle"))
+	})
+
+	Context("when no cgroup path ends with a handle", func() {
+		It("returns an error", func() {
+			handles = []string{"other-handle"}
+
+			_, err := resolver.Handle(42)
+			Expect(err).To(MatchError("process 42 is not in a container"))
+		})
+	})
+
+	Context("when the process does not exist", func() {
+		It("returns an error", func() {
+			_, err := resolver.Handle(43)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when the handles cannot be listed", func() {
+		It("returns the error", func() {
+			resolver.Handles = func() ([]string, error) { return nil, errors.New("banana") }
+
+			_, err := resolver.Handle(42)
+			Expect(err).To(MatchError("banana"))
+		})
+	})
+})
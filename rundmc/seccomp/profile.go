@@ -0,0 +1,277 @@
+package seccomp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+// the kernel passes syscalls at most 6 arguments
+const maxArgs = 6
+
+// Profile is a seccomp profile in the format used by Docker, which can apply
+// to several architectures and capability sets. Spec resolves it to the
+// OCI seccomp configuration of a container.
+type Profile struct {
+	DefaultAction specs.LinuxSeccompAction `json:"defaultAction"`
+	Architectures []specs.Arch             `json:"architectures,omitempty"`
+	ArchMap       []ArchMap                `json:"archMap,omitempty"`
+	Syscalls      []Syscall                `json:"syscalls"`
+}
+
+// ArchMap lists the architectures, besides itself, which a native
+// architecture can run syscalls of
+type ArchMap struct {
+	Arch      specs.Arch   `json:"architecture"`
+	SubArches []specs.Arch `json:"subArchitectures"`
+}
+
+// Syscall applies an action to one or more syscalls, optionally only on
+// certain Go architectures or when a container has certain capabilities
+type Syscall struct {
+	Name     string                   `json:"name,omitempty"`
+	Names    []string                 `json:"names,omitempty"`
+	Action   specs.LinuxSeccompAction `json:"action"`
+	Args     []specs.LinuxSeccompArg  `json:"args,omitempty"`
+	Includes Filter                   `json:"includes,omitempty"`
+	Excludes Filter                   `json:"excludes,omitempty"`
+}
+
+// Filter restricts a Syscall by Go architecture (e.g. "arm64") and
+// capability (e.g. "CAP_SYS_ADMIN")
+type Filter struct {
+	Arches []string `json:"arches,omitempty"`
+	Caps   []string `json:"caps,omitempty"`
+}
+
+// Load reads and validates a JSON profile
+func Load(path string) (Profile, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Profile{}, fmt.Errorf("reading seccomp profile: %s", err)
+	}
+
+	var profile Profile
+	if err := json.Unmarshal(contents, &profile); err != nil {
+		return Profile{}, fmt.Errorf("parsing seccomp profile %s: %s", path, err)
+	}
+
+	if err := profile.Validate(); err != nil {
+		return Profile{}, fmt.Errorf("invalid seccomp profile %s: %s", path, err)
+	}
+
+	return profile, nil
+}
+
+// Validate checks that the profile only uses actions, operators and
+// architectures which runc understands, so that a bad profile is rejected on
+// startup rather than when creating a container
+func (p Profile) Validate() error {
+	if err := validateAction(p.DefaultAction); err != nil {
+		return fmt.Errorf("defaultAction: %s", err)
+	}
+
+	for _, arch := range p.Architectures {
+		if err := validateArch(arch); err != nil {
+			return fmt.Errorf("architectures: %s", err)
+		}
+	}
+
+	for _, archMap := range p.ArchMap {
+		for _, arch := range append([]specs.Arch{archMap.Arch}, archMap.SubArches...) {
+			if err := validateArch(arch); err != nil {
+				return fmt.Errorf("archMap: %s", err)
+			}
+		}
+	}
+
+	for i, syscall := range p.Syscalls {
+		if err := syscall.validate(); err != nil {
+			return fmt.Errorf("syscalls[%d]: %s", i, err)
+		}
+	}
+
+	return nil
+}
+
+func (s Syscall) validate() error {
+	if len(s.names()) == 0 {
+		return errors.New("no syscall names")
+	}
+
+	for _, name := range s.names() {
+		if name == "" {
+			return errors.New("empty syscall name")
+		}
+	}
+
+	if err := validateAction(s.Action); err != nil {
+		return fmt.Errorf("action: %s", err)
+	}
+
+	for _, arg := range s.Args {
+		if arg.Index >= maxArgs {
+			return fmt.Errorf("args: index out of range: %d", arg.Index)
+		}
+
+		if err := validateOperator(arg.Op); err != nil {
+			return fmt.Errorf("args: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func validateAction(action specs.LinuxSeccompAction) error {
+	switch action {
+	case specs.ActKill, specs.ActTrap, specs.ActErrno, specs.ActTrace, specs.ActAllow, ActLog:
+		return nil
+	}
+
+	return fmt.Errorf("unknown action: '%s'", action)
+}
+
+func validateOperator(op specs.LinuxSeccompOperator) error {
+	switch op {
+	case specs.OpNotEqual, specs.OpLessThan, specs.OpLessEqual, specs.OpEqualTo,
+		specs.OpGreaterEqual, specs.OpGreaterThan, specs.OpMaskedEqual:
+		return nil
+	}
+
+	return fmt.Errorf("unknown operator: '%s'", op)
+}
+
+func validateArch(arch specs.Arch) error {
+	switch arch {
+	case specs.ArchX86_64, specs.ArchX86, specs.ArchX32,
+		specs.ArchARM, specs.ArchAARCH64,
+		specs.ArchPPC, specs.ArchPPC64, specs.ArchPPC64LE,
+		specs.ArchS390, specs.ArchS390X:
+		return nil
+	}
+
+	return fmt.Errorf("unknown architecture: '%s'", arch)
+}
+
+// Spec returns the seccomp configuration for a container with the given
+// capabilities, running on the given Go architecture
+func (p Profile) Spec(goarch string, caps []string) (*specs.LinuxSeccomp, error) {
+	architectures, err := p.architectures(goarch)
+	if err != nil {
+		return nil, err
+	}
+
+	seccomp := &specs.LinuxSeccomp{
+		DefaultAction: p.DefaultAction,
+		Architectures: architectures,
+		Syscalls:      []specs.LinuxSyscall{},
+	}
+
+	for _, syscall := range p.Syscalls {
+		if !syscall.appliesTo(goarch, caps) {
+			continue
+		}
+
+		for _, name := range syscall.names() {
+			args := syscall.Args
+			if args == nil {
+				args = []specs.LinuxSeccompArg{}
+			}
+
+			seccomp.Syscalls = append(seccomp.Syscalls, specs.LinuxSyscall{
+				Name:   name,
+				Action: syscall.Action,
+				Args:   args,
+			})
+		}
+	}
+
+	return seccomp, nil
+}
+
+func (p Profile) architectures(goarch string) ([]specs.Arch, error) {
+	if len(p.Architectures) > 0 {
+		return p.Architectures, nil
+	}
+
+	native, err := Architectures(goarch)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, archMap := range p.ArchMap {
+		if archMap.Arch == native[0] {
+			return append([]specs.Arch{archMap.Arch}, archMap.SubArches...), nil
+		}
+	}
+
+	return native, nil
+}
+
+// Architectures returns the seccomp architectures whose syscalls a process
+// may make on the given Go architecture, native architecture first
+func Architectures(goarch string) ([]specs.Arch, error) {
+	switch goarch {
+	case "amd64":
+		return []specs.Arch{specs.ArchX86_64, specs.ArchX86, specs.ArchX32}, nil
+	case "386":
+		return []specs.Arch{specs.ArchX86}, nil
+	case "arm64":
+		return []specs.Arch{specs.ArchAARCH64, specs.ArchARM}, nil
+	case "arm":
+		return []specs.Arch{specs.ArchARM}, nil
+	case "ppc64le":
+		return []specs.Arch{specs.ArchPPC64LE}, nil
+	case "ppc64":
+		return []specs.Arch{specs.ArchPPC64, specs.ArchPPC}, nil
+	case "s390x":
+		return []specs.Arch{specs.ArchS390X, specs.ArchS390}, nil
+	}
+
+	return nil, fmt.Errorf("seccomp is not supported on architecture: %s", goarch)
+}
+
+func (s Syscall) names() []string {
+	if s.Name != "" {
+		return append([]string{s.Name}, s.Names...)
+	}
+
+	return s.Names
+}
+
+func (s Syscall) appliesTo(goarch string, caps []string) bool {
+	if len(s.Includes.Arches) > 0 && !contains(s.Includes.Arches, goarch) {
+		return false
+	}
+
+	for _, c := range s.Includes.Caps {
+		if !contains(caps, c) {
+			return false
+		}
+	}
+
+	if contains(s.Excludes.Arches, goarch) {
+		return false
+	}
+
+	for _, c := range s.Excludes.Caps {
+		if contains(caps, c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
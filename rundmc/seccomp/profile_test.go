@@ -0,0 +1,339 @@
+package seccomp_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/guardian/rundmc/seccomp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/runtime-spec/specs-go"
+)
+
+var _ = Describe("Profile", func() {
+	Describe("Load", func() {
+		var (
+			tmpDir string
+			path   string
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "seccomp")
+			Expect(err).NotTo(HaveOccurred())
+
+			path = filepath.Join(tmpDir, "profile.json")
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(tmpDir)).To(Succeed())
+		})
+
+		It("parses a Docker format profile", func() {
+			Expect(ioutil.WriteFile(path, []byte(`{
+				"defaultAction": "SCMP_ACT_ERRNO",
+				"archMap": [
+					{"architecture": "SCMP_ARCH_AARCH64", "subArchitectures": ["SCMP_ARCH_ARM"]}
+				],
+				"syscalls": [
+					{"names": ["read", "write"], "action": "SCMP_ACT_ALLOW"},
+					{
+						"name": "personality",
+						"action": "SCMP_ACT_ALLOW",
+						"args": [{"index": 0, "value": 8, "valueTwo": 0, "op": "SCMP_CMP_EQ"}]
+					},
+					{"names": ["sync_file_range2"], "action": "SCMP_ACT_ALLOW", "includes": {"arches": ["ppc64le"]}},
+					{"names": ["mount"], "action": "SCMP_ACT_ALLOW", "excludes": {"caps": ["CAP_SYS_ADMIN"]}}
+				]
+			}`), 0600)).To(Succeed())
+
+			profile, err := seccomp.Load(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(profile).To(Equal(seccomp.Profile{
+				DefaultAction: specs.ActErrno,
+				ArchMap: []seccomp.ArchMap{
+					{Arch: specs.ArchAARCH64, SubArches: []specs.Arch{specs.ArchARM}},
+				},
+				Syscalls: []seccomp.Syscall{
+					{Names: []string{"read", "write"}, Action: specs.ActAllow},
+					{
+						Name:   "personality",
+						Action: specs.ActAllow,
+						Args:   []specs.LinuxSeccompArg{{Index: 0, Value: 8, Op: specs.OpEqualTo}},
+					},
+					{Names: []string{"sync_file_range2"}, Action: specs.ActAllow, Includes: seccomp.Filter{Arches: []string{"ppc64le"}}},
+					{Names: []string{"mount"}, Action: specs.ActAllow, Excludes: seccomp.Filter{Caps: []string{"CAP_SYS_ADMIN"}}},
+				},
+			}))
+		})
+
+		Context("when the file does not exist", func() {
+			It("returns an error", func() {
+				_, err := seccomp.Load(filepath.Join(tmpDir, "missing.json"))
+				Expect(err).To(MatchError(ContainSubstring("reading seccomp profile")))
+			})
+		})
+
+		Context("when the file is not valid JSON", func() {
+			It("returns an error", func() {
+				Expect(ioutil.WriteFile(path, []byte("{"), 0600)).To(Succeed())
+
+				_, err := seccomp.Load(path)
+				Expect(err).To(MatchError(ContainSubstring("parsing seccomp profile " + path)))
+			})
+		})
+
+		Context("when the profile is invalid", func() {
+			It("returns an error", func() {
+				Expect(ioutil.WriteFile(path, []byte(`{"defaultAction": "SCMP_ACT_BANANA"}`), 0600)).To(Succeed())
+
+				_, err := seccomp.Load(path)
+				Expect(err).To(MatchError("invalid seccomp profile " + path + ": defaultAction: unknown action: 'SCMP_ACT_BANANA'"))
+			})
+		})
+	})
+
+	Describe("Validate", func() {
+		var profile seccomp.Profile
+
+		BeforeEach(func() {
+			profile = seccomp.Profile{
+				DefaultAction: specs.ActErrno,
+				Architectures: []specs.Arch{specs.ArchX86_64},
+				ArchMap: []seccomp.ArchMap{
+					{Arch: specs.ArchS390X, SubArches: []specs.Arch{specs.ArchS390}},
+				},
+				Syscalls: []seccomp.Syscall{{
+					Names:  []string{"clone"},
+					Action: specs.ActAllow,
+					Args:   []specs.LinuxSeccompArg{{Index: 5, Value: 1, Op: specs.OpMaskedEqual}},
+				}},
+			}
+		})
+
+		It("accepts a valid profile", func() {
+			Expect(profile.Validate()).To(Succeed())
+		})
+
+		Context("when an architecture is unknown", func() {
+			It("returns an error", func() {
+				profile.Architectures = []specs.Arch{"SCMP_ARCH_Z80"}
+				Expect(profile.Validate()).To(MatchError("architectures: unknown architecture: 'SCMP_ARCH_Z80'"))
+			})
+		})
+
+		Context("when a sub-architecture is unknown", func() {
+			It("returns an error", func() {
+				profile.ArchMap[0].SubArches = []specs.Arch{"SCMP_ARCH_Z80"}
+				Expect(profile.Validate()).To(MatchError("archMap: unknown architecture: 'SCMP_ARCH_Z80'"))
+			})
+		})
+
+		Context("when a syscall has no names", func() {
+			It("returns an error", func() {
+				profile.Syscalls[0].Names = nil
+				Expect(profile.Validate()).To(MatchError("syscalls[0]: no syscall names"))
+			})
+		})
+
+		Context("when a syscall name is empty", func() {
+			It("returns an error", func() {
+				profile.Syscalls[0].Names = []string{"clone", ""}
+				Expect(profile.Validate()).To(MatchError("syscalls[0]: empty syscall name"))
+			})
+		})
+
+		Context("when a syscall action is unknown", func() {
+			It("returns an error", func() {
+				profile.Syscalls[0].Action = "SCMP_ACT_BANANA"
+				Expect(profile.Validate()).To(MatchError("syscalls[0]: action: unknown action: 'SCMP_ACT_BANANA'"))
+			})
+		})
+
+		Context("when an argument index is out of range", func() {
+			It("returns an error", func() {
+				profile.Syscalls[0].Args[0].Index = 6
+				Expect(profile.Validate()).To(MatchError("syscalls[0]: args: index out of range: 6"))
+			})
+		})
+
+		Context("when an argument operator is unknown", func() {
+			It("returns an error", func() {
+				profile.Syscalls[0].Args[0].Op = "SCMP_CMP_BANANA"
+				Expect(profile.Validate()).To(MatchError("syscalls[0]: args: unknown operator: 'SCMP_CMP_BANANA'"))
+			})
+		})
+	})
+
+	Describe("Spec", func() {
+		var profile seccomp.Profile
+
+		BeforeEach(func() {
+			profile = seccomp.Profile{
+				DefaultAction: specs.ActErrno,
+				Syscalls: []seccomp.Syscall{
+					{Names: []string{"read", "write"}, Action: specs.ActAllow},
+					{Name: "open", Action: specs.ActAllow},
+				},
+			}
+		})
+
+		It("returns one syscall for each name", func() {
+			spec, err := profile.Spec("amd64", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(spec.DefaultAction).To(Equal(specs.ActErrno))
+			Expect(spec.Syscalls).To(Equal([]specs.LinuxSyscall{
+				{Name: "read", Action: specs.ActAllow, Args: []specs.LinuxSeccompArg{}},
+				{Name: "write", Action: specs.ActAllow, Args: []specs.LinuxSeccompArg{}},
+				{Name: "open", Action: specs.ActAllow, Args: []specs.LinuxSeccompArg{}},
+			}))
+		})
+
+		It("keeps syscall arguments", func() {
+			args := []specs.LinuxSeccompArg{{Index: 1, Value: 2080505856, Op: specs.OpMaskedEqual}}
+			profile.Syscalls = []seccomp.Syscall{{Names: []string{"clone"}, Action: specs.ActAllow, Args: args}}
+
+			spec, err := profile.Spec("s390x", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spec.Syscalls).To(Equal([]specs.LinuxSyscall{
+				{Name: "clone", Action: specs.ActAllow, Args: args},
+			}))
+		})
+
+		DescribeTable("the architectures of the Go architecture, when none are given",
+			func(goarch string, expected []specs.Arch) {
+				spec, err := profile.Spec(goarch, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spec.Architectures).To(Equal(expected))
+			},
+			Entry("amd64", "amd64", []specs.Arch{specs.ArchX86_64, specs.ArchX86, specs.ArchX32}),
+			Entry("386", "386", []specs.Arch{specs.ArchX86}),
+			Entry("arm64", "arm64", []specs.Arch{specs.ArchAARCH64, specs.ArchARM}),
+			Entry("arm", "arm", []specs.Arch{specs.ArchARM}),
+			Entry("ppc64le", "ppc64le", []specs.Arch{specs.ArchPPC64LE}),
+			Entry("ppc64", "ppc64", []specs.Arch{specs.ArchPPC64, specs.ArchPPC}),
+			Entry("s390x", "s390x", []specs.Arch{specs.ArchS390X, specs.ArchS390}),
+		)
+
+		Context("when the Go architecture is not supported", func() {
+			It("returns an error", func() {
+				_, err := profile.Spec("mips", nil)
+				Expect(err).To(MatchError("seccomp is not supported on architecture: mips"))
+			})
+		})
+
+		Context("when the profile lists architectures", func() {
+			It("uses them", func() {
+				profile.Architectures = []specs.Arch{specs.ArchX86_64}
+
+				spec, err := profile.Spec("amd64", nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spec.Architectures).To(Equal([]specs.Arch{specs.ArchX86_64}))
+			})
+		})
+
+		Context("when the profile maps the native architecture", func() {
+			BeforeEach(func() {
+				profile.ArchMap = []seccomp.ArchMap{
+					{Arch: specs.ArchX86_64, SubArches: []specs.Arch{specs.ArchX86}},
+				}
+			})
+
+			It("uses the mapped architectures", func() {
+				spec, err := profile.Spec("amd64", nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spec.Architectures).To(Equal([]specs.Arch{specs.ArchX86_64, specs.ArchX86}))
+			})
+
+			It("uses the architectures of other Go architectures", func() {
+				spec, err := profile.Spec("arm64", nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spec.Architectures).To(Equal([]specs.Arch{specs.ArchAARCH64, specs.ArchARM}))
+			})
+		})
+
+		Context("when a syscall includes architectures", func() {
+			BeforeEach(func() {
+				profile.Syscalls = []seccomp.Syscall{
+					{Names: []string{"arch_prctl"}, Action: specs.ActAllow, Includes: seccomp.Filter{Arches: []string{"amd64", "386"}}},
+				}
+			})
+
+			It("includes it on those architectures", func() {
+				spec, err := profile.Spec("386", nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spec.Syscalls).To(HaveLen(1))
+			})
+
+			It("omits it on other architectures", func() {
+				spec, err := profile.Spec("arm64", nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spec.Syscalls).To(BeEmpty())
+			})
+		})
+
+		Context("when a syscall excludes architectures", func() {
+			BeforeEach(func() {
+				profile.Syscalls = []seccomp.Syscall{
+					{Names: []string{"clone"}, Action: specs.ActAllow, Excludes: seccomp.Filter{Arches: []string{"s390x"}}},
+				}
+			})
+
+			It("omits it on those architectures", func() {
+				spec, err := profile.Spec("s390x", nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spec.Syscalls).To(BeEmpty())
+			})
+
+			It("includes it on other architectures", func() {
+				spec, err := profile.Spec("amd64", nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spec.Syscalls).To(HaveLen(1))
+			})
+		})
+
+		Context("when a syscall includes capabilities", func() {
+			BeforeEach(func() {
+				profile.Syscalls = []seccomp.Syscall{
+					{Names: []string{"mount"}, Action: specs.ActAllow, Includes: seccomp.Filter{Caps: []string{"CAP_SYS_ADMIN", "CAP_CHOWN"}}},
+				}
+			})
+
+			It("includes it when the container has all of them", func() {
+				spec, err := profile.Spec("amd64", []string{"CAP_CHOWN", "CAP_SYS_ADMIN"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spec.Syscalls).To(HaveLen(1))
+			})
+
+			It("omits it when the container is missing any of them", func() {
+				spec, err := profile.Spec("amd64", []string{"CAP_SYS_ADMIN"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spec.Syscalls).To(BeEmpty())
+			})
+		})
+
+		Context("when a syscall excludes capabilities", func() {
+			BeforeEach(func() {
+				profile.Syscalls = []seccomp.Syscall{
+					{Names: []string{"mount"}, Action: specs.ActErrno, Excludes: seccomp.Filter{Caps: []string{"CAP_SYS_ADMIN", "CAP_CHOWN"}}},
+				}
+			})
+
+			It("omits it when the container has any of them", func() {
+				spec, err := profile.Spec("amd64", []string{"CAP_CHOWN"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spec.Syscalls).To(BeEmpty())
+			})
+
+			It("includes it when the container has none of them", func() {
+				spec, err := profile.Spec("amd64", []string{"CAP_KILL"})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(spec.Syscalls).To(HaveLen(1))
+			})
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package seccomp_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestSeccomp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Seccomp Suite")
+}
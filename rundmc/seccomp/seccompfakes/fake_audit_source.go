@@ -0,0 +1,140 @@
+// This file was generated by counterfeiter
+package seccompfakes
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/guardian/rundmc/seccomp"
+)
+
+type FakeAuditSource struct {
+	NextStub        func() (seccomp.AuditRecord, error)
+	nextMutex       sync.RWMutex
+	nextArgsForCall []struct{}
+	nextReturns     struct {
+		result1 seccomp.AuditRecord
+		result2 error
+	}
+	nextReturnsOnCall map[int]struct {
+		result1 seccomp.AuditRecord
+		result2 error
+	}
+	CloseStub        func() error
+	closeMutex       sync.RWMutex
+	closeArgsForCall []struct{}
+	closeReturns     struct {
+		result1 error
+	}
+	closeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeAuditSource) Next() (seccomp.AuditRecord, error) {
+	fake.nextMutex.Lock()
+	ret, specificReturn := fake.nextReturnsOnCall[len(fake.nextArgsForCall)]
+	fake.nextArgsForCall = append(fake.nextArgsForCall, struct{}{})
+	fake.recordInvocation("Next", []interface{}{})
+	fake.nextMutex.Unlock()
+	if fake.NextStub != nil {
+		return fake.NextStub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fake.nextReturns.result1, fake.nextReturns.result2
+}
+
+func (fake *FakeAuditSource) NextCallCount() int {
+	fake.nextMutex.RLock()
+	defer fake.nextMutex.RUnlock()
+	return len(fake.nextArgsForCall)
+}
+
+func (fake *FakeAuditSource) NextReturns(result1 seccomp.AuditRecord, result2 error) {
+	fake.NextStub = nil
+	fake.nextReturns = struct {
+		result1 seccomp.AuditRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAuditSource) NextReturnsOnCall(i int, result1 seccomp.AuditRecord, result2 error) {
+	fake.NextStub = nil
+	if fake.nextReturnsOnCall == nil {
+		fake.nextReturnsOnCall = make(map[int]struct {
+			result1 seccomp.AuditRecord
+			result2 error
+		})
+	}
+	fake.nextReturnsOnCall[i] = struct {
+		result1 seccomp.AuditRecord
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAuditSource) Close() error {
+	fake.closeMutex.Lock()
+	ret, specificReturn := fake.closeReturnsOnCall[len(fake.closeArgsForCall)]
+	fake.closeArgsForCall = append(fake.closeArgsForCall, struct{}{})
+	fake.recordInvocation("Close", []interface{}{})
+	fake.closeMutex.Unlock()
+	if fake.CloseStub != nil {
+		return fake.CloseStub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.closeReturns.result1
+}
+
+func (fake *FakeAuditSource) CloseCallCount() int {
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return len(fake.closeArgsForCall)
+}
+
+func (fake *FakeAuditSource) CloseReturns(result1 error) {
+	fake.CloseStub = nil
+	fake.closeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAuditSource) CloseReturnsOnCall(i int, result1 error) {
+	fake.CloseStub = nil
+	if fake.closeReturnsOnCall == nil {
+		fake.closeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.closeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAuditSource) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.nextMutex.RLock()
+	defer fake.nextMutex.RUnlock()
+	fake.closeMutex.RLock()
+	defer fake.closeMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeAuditSource) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ seccomp.AuditSource = new(FakeAuditSource)
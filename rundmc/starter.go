@@ -61,17 +61,21 @@ func (s *CgroupStarter) mountCgroupsIfNeeded(logger lager.Logger) error {
 		return err
 	}
 
+	subsystemGroupings, unified, err := subsystemGroupings(s.ProcSelfCgroups)
+	if err != nil {
+		return err
+	}
+
+	if unified {
+		return s.mountUnifiedCgroup(logger, s.CgroupPath)
+	}
+
 	if !s.isMountPoint(s.CgroupPath) {
 		s.mountTmpfsOnCgroupPath(logger, s.CgroupPath)
 	} else {
 		logger.Info("cgroups-tmpfs-already-mounted", lager.Data{"path": s.CgroupPath})
 	}
 
-	subsystemGroupings, err := s.subsystemGroupings()
-	if err != nil {
-		return err
-	}
-
 	scanner := bufio.NewScanner(s.ProcCgroups)
 
 	if !scanner.Scan() {
@@ -118,10 +122,13 @@ func (s *CgroupStarter) mountTmpfsOnCgroupPath(log lager.Logger, path string) {
 	}
 }
 
-func (s *CgroupStarter) subsystemGroupings() (map[string]string, error) {
+// subsystemGroupings returns the subsystems mounted together with each
+// subsystem, and whether the host uses the unified (v2) cgroup hierarchy alone
+func subsystemGroupings(procSelfCgroup io.Reader) (map[string]string, bool, error) {
 	groupings := map[string]string{}
+	unified := false
 
-	scanner := bufio.NewScanner(s.ProcSelfCgroups)
+	scanner := bufio.NewScanner(procSelfCgroup)
 
 	for scanner.Scan() {
 		segs := strings.Split(scanner.Text(), ":")
@@ -129,13 +136,46 @@ func (s *CgroupStarter) subsystemGroupings() (map[string]string, error) {
 			continue
 		}
 
+		if segs[0] == "0" && segs[1] == "" {
+			unified = true
+			continue
+		}
+
 		subsystems := strings.Split(segs[1], ",")
 		for _, subsystem := range subsystems {
 			groupings[subsystem] = segs[1]
 		}
 	}
 
-	return groupings, scanner.Err()
+	// hybrid hosts mount v1 hierarchies alongside the unified one
+	return groupings, unified && len(groupings) == 0, scanner.Err()
+}
+
+// IsUnifiedCgroupHierarchy returns true if the process whose
+// /proc/<pid>/cgroup is given is in the unified (v2) cgroup hierarchy alone
+func IsUnifiedCgroupHierarchy(procSelfCgroup io.Reader) (bool, error) {
+	_, unified, err := subsystemGroupings(procSelfCgroup)
+	return unified, err
+}
+
+func (s *CgroupStarter) mountUnifiedCgroup(logger lager.Logger, cgroupPath string) error {
+	logger = logger.Session("mount-unified-cgroup", lager.Data{"path": cgroupPath})
+	logger.Info("started")
+
+	if s.isMountPoint(cgroupPath) {
+		logger.Info("already-mounted")
+		return nil
+	}
+
+	cmd := exec.Command("mount", "-n", "-t", "cgroup2", "cgroup2", cgroupPath)
+	cmd.Stderr = logging.Writer(logger.Session("mount-cgroup-cmd"))
+	if err := s.CommandRunner.Run(cmd); err != nil {
+		return fmt.Errorf("mounting unified cgroup hierarchy in '%s': %s", cgroupPath, err)
+	}
+
+	logger.Info("finished")
+
+	return nil
 }
 
 func (s *CgroupStarter) mountCgroup(logger lager.Logger, cgroupPath, subsystems string) error {
@@ -192,6 +192,117 @@ var _ = Describe("CgroupStarter", func() {
 		})
 	})
 
+	Context("when the host uses the unified cgroup hierarchy", func() {
+		var mounted bool
+
+		BeforeEach(func() {
+			mounted = false
+
+			_, err := procCgroups.Write([]byte(
+				"#subsys_name\thierarchy\tnum_cgroups\tenabled\n" +
+					"cpu\t0\t1\t1\n" +
+					"memory\t0\t1\t1\n",
+			))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = procSelfCgroups.Write([]byte("0::/system.slice/garden.service\n"))
+			Expect(err).NotTo(HaveOccurred())
+
+			runner.WhenRunning(fake_command_runner.CommandSpec{
+				Path: "mountpoint",
+				Args: []string{"-q", path.Join(tmpDir, "cgroup") + "/"},
+			}, func(cmd *exec.Cmd) error {
+				if mounted {
+					return nil
+				}
+				return errors.New("not a mountpoint")
+			})
+		})
+
+		It("mounts the unified hierarchy on the cgroup path", func() {
+			Expect(starter.Start()).To(Succeed())
+
+			Expect(runner).To(HaveExecutedSerially(fake_command_runner.CommandSpec{
+				Path: "mount",
+				Args: []string{"-n", "-t", "cgroup2", "cgroup2", path.Join(tmpDir, "cgroup")},
+			}))
+		})
+
+		It("does not mount a tmpfs or any v1 hierarchies", func() {
+			Expect(starter.Start()).To(Succeed())
+
+			Expect(runner).NotTo(HaveExecutedSerially(fake_command_runner.CommandSpec{
+				Path: "mount",
+				Args: []string{"-t", "tmpfs", "-o", "uid=0,gid=0,mode=0755", "cgroup", path.Join(tmpDir, "cgroup")},
+			}))
+			Expect(runner).NotTo(HaveExecutedSerially(fake_command_runner.CommandSpec{
+				Path: "mount",
+				Args: []string{"-n", "-t", "cgroup", "-o", "memory", "cgroup", path.Join(tmpDir, "cgroup", "memory")},
+			}))
+		})
+
+		Context("when the unified hierarchy is already mounted", func() {
+			BeforeEach(func() {
+				mounted = true
+			})
+
+			It("does not mount it again", func() {
+				Expect(starter.Start()).To(Succeed())
+
+				Expect(runner).NotTo(HaveExecutedSerially(fake_command_runner.CommandSpec{
+					Path: "mount",
+					Args: []string{"-n", "-t", "cgroup2", "cgroup2", path.Join(tmpDir, "cgroup")},
+				}))
+			})
+		})
+
+		Context("when mounting fails", func() {
+			BeforeEach(func() {
+				runner.WhenRunning(fake_command_runner.CommandSpec{
+					Path: "mount",
+				}, func(cmd *exec.Cmd) error {
+					return errors.New("banana")
+				})
+			})
+
+			It("returns an error", func() {
+				Expect(starter.Start()).To(MatchError(ContainSubstring("banana")))
+			})
+		})
+	})
+
+	Context("when the host mounts v1 hierarchies alongside the unified hierarchy", func() {
+		BeforeEach(func() {
+			_, err := procCgroups.Write([]byte(
+				"#subsys_name\thierarchy\tnum_cgroups\tenabled\n" +
+					"memory\t2\t1\t1\n",
+			))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = procSelfCgroups.Write([]byte(
+				"2:memory:/\n" +
+					"0::/\n",
+			))
+			Expect(err).NotTo(HaveOccurred())
+
+			runner.WhenRunning(fake_command_runner.CommandSpec{
+				Path: "mountpoint",
+				Args: []string{"-q", path.Join(tmpDir, "cgroup", "memory") + "/"},
+			}, func(cmd *exec.Cmd) error {
+				return errors.New("not a mountpoint")
+			})
+		})
+
+		It("mounts the v1 hierarchies", func() {
+			Expect(starter.Start()).To(Succeed())
+
+			Expect(runner).To(HaveExecutedSerially(fake_command_runner.CommandSpec{
+				Path: "mount",
+				Args: []string{"-n", "-t", "cgroup", "-o", "memory", "cgroup", path.Join(tmpDir, "cgroup", "memory")},
+			}))
+		})
+	})
+
 	Context("when /proc/cgroups contains malformed entries", func() {
 		BeforeEach(func() {
 			_, err := procCgroups.Write([]byte(
@@ -281,6 +392,16 @@ var _ = Describe("CgroupStarter", func() {
 	})
 })
 
+var _ = Describe("IsUnifiedCgroupHierarchy", func() {
+	It("returns true when the process is in the unified hierarchy alone", func() {
+		Expect(rundmc.IsUnifiedCgroupHierarchy(bytes.NewBufferString("0::/user.slice\n"))).To(BeTrue())
+	})
+
+	It("returns false when the process is in v1 hierarchies", func() {
+		Expect(rundmc.IsUnifiedCgroupHierarchy(bytes.NewBufferString("4:memory:/\n0::/\n"))).To(BeFalse())
+	})
+})
+
 type FakeReadCloser struct {
 	closed bool
 	*bytes.Buffer
@@ -31,5 +31,11 @@ func (r resolver) Resolve(name, subsystem string) (string, error) {
 		return "", err
 	}
 
-	return s.CgroupPaths["devices"], nil
+	if path, ok := s.CgroupPaths[subsystem]; ok {
+		return path, nil
+	}
+
+	// on the unified (v2) hierarchy runc records a single cgroup path, with
+	// no per-subsystem entries
+	return s.CgroupPaths[""], nil
 }
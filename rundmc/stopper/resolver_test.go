@@ -36,6 +36,7 @@ var _ = Describe("Resolver", func() {
 			Expect(json.NewEncoder(stateJson).Encode(map[string]interface{}{
 				"cgroup_paths": map[string]string{
 					"devices": "i-am-the-devices-cgroup-path",
+					"blkio":   "i-am-the-blkio-cgroup-path",
 				},
 			})).To(Succeed())
 			Expect(stateJson.Close()).To(Succeed())
@@ -46,6 +47,32 @@ var _ = Describe("Resolver", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(path).To(Equal("i-am-the-devices-cgroup-path"))
 		})
+
+		It("resolves the cgroup of the given subsystem", func() {
+			path, err := stopper.NewRuncStateCgroupPathResolver(fakeStateDir).Resolve("some-handle", "blkio")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal("i-am-the-blkio-cgroup-path"))
+		})
+	})
+
+	Context("when the container is in the unified cgroup hierarchy", func() {
+		BeforeEach(func() {
+			stateJson, err := os.Create(filepath.Join(fakeStateDir, "some-handle", "state.json"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(json.NewEncoder(stateJson).Encode(map[string]interface{}{
+				"cgroup_paths": map[string]string{
+					"": "i-am-the-unified-cgroup-path",
+				},
+			})).To(Succeed())
+			Expect(stateJson.Close()).To(Succeed())
+		})
+
+		It("resolves the unified cgroup path", func() {
+			path, err := stopper.NewRuncStateCgroupPathResolver(fakeStateDir).Resolve("some-handle", "devices")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal("i-am-the-unified-cgroup-path"))
+		})
 	})
 
 	Context("with invalid state.json", func() {